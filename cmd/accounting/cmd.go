@@ -0,0 +1,117 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/objlayer/accounting"
+)
+
+var fjson bool
+
+var Cmd = &cobra.Command{
+	Use:   "accounting [bucket]",
+	Short: "Reports traffic and storage of buckets per backend",
+	Long: "Reports bytes uploaded to and downloaded from each bucket, and size and amount of objects " +
+		"stored in it, per backend. Admin API of running gateway is queried if admin address is configured, " +
+		"otherwise usage persisted by the gateway is read.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: exec,
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	cfg, err := config.ReadConfig(true)
+	if err != nil {
+		return err
+	}
+
+	var bucket string
+	if len(args) == 1 {
+		bucket = args[0]
+	}
+
+	report, err := load(cfg, bucket)
+	if err != nil {
+		return err
+	}
+
+	if fjson {
+		return json.NewEncoder(cmd.OutOrStdout()).Encode(report)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%-30s %-7s %15s %15s %15s %10s\n", "BUCKET", "BACKEND", "UPLOADED", "DOWNLOADED", "STORED", "OBJECTS")
+
+	for _, u := range report {
+		fmt.Fprintf(cmd.OutOrStdout(), "%-30s %-7s %15d %15d %15d %10d\n", u.Bucket, u.Backend, u.Uploaded, u.Downloaded, u.Stored, u.Objects)
+	}
+
+	return nil
+}
+
+func load(cfg *config.Config, bucket string) ([]accounting.Usage, error) {
+	if cfg.Admin != nil && cfg.Admin.Address != "" {
+		return fetch(adminURL(cfg.Admin.Address), cfg.Admin.Token, bucket)
+	}
+
+	if cfg.Accounting == nil || cfg.Accounting.Path == "" {
+		return nil, errors.New("accounting is not configured")
+	}
+
+	report, err := accounting.Load(cfg.Accounting.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	accounting.Sort(report)
+
+	return accounting.Filter(report, bucket), nil
+}
+
+// adminURL returns URL of accounting endpoint, address without host refers to local gateway.
+func adminURL(address string) string {
+	if strings.HasPrefix(address, ":") {
+		address = "localhost" + address
+	}
+
+	return "http://" + address + "/accounting"
+}
+
+// fetch queries accounting endpoint of admin API.
+func fetch(baseURL, token, bucket string) ([]accounting.Usage, error) {
+	req, err := http.NewRequest(http.MethodGet, baseURL+"?"+url.Values{"bucket": {bucket}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("admin API responded %s", resp.Status)
+	}
+
+	var report []accounting.Usage
+	err = json.NewDecoder(resp.Body).Decode(&report)
+
+	return report, err
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&fjson, "json", false, "print report as JSON")
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cat
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/ditto/cmd/diff"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/routing"
+)
+
+// Function listed as var for testing purposes only
+var objectLayer = utils.GetStreamingObjectLayer
+
+var (
+	fmode            string
+	foffset, flength int64
+)
+
+var Cmd = &cobra.Command{
+	Use:   "cat <bucket>/<key>",
+	Short: "Writes content of object to standard output",
+	Long: "Streams object to standard output, so it can be piped to other commands. Object is read by " +
+		"Read.Mode of config, or --read-mode, the same way gateway serves clients. " +
+		"With --offset and --length only given range of bytes is written.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	if fmode != "" {
+		viper.Set(config.READ_MODE, fmode)
+	}
+
+	ol, err := objectLayer()
+	if err != nil {
+		return err
+	}
+
+	bucket, key := diff.SplitPath(args[0])
+
+	out := bufio.NewWriter(os.Stdout)
+
+	if err = cat(context.Background(), ol, bucket, key, foffset, flength, out); err != nil {
+		return err
+	}
+
+	return out.Flush()
+}
+
+// cat writes length bytes of object from offset to out, negative length writes the rest of object.
+func cat(ctx context.Context, ol minio.ObjectLayer, bucket, key string, offset, length int64, out io.Writer) error {
+	oi, err := ol.GetObjectInfo(ctx, bucket, key, minio.ObjectOptions{})
+	if err != nil {
+		return err
+	}
+
+	if offset > oi.Size {
+		return fmt.Errorf("offset %d is beyond size %d of object", offset, oi.Size)
+	}
+
+	if length < 0 || offset+length > oi.Size {
+		length = oi.Size - offset
+	}
+
+	return ol.GetObject(ctx, bucket, key, offset, length, out, oi.ETag, minio.ObjectOptions{})
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	switch fmode {
+	case "", routing.PrimeMode, routing.AutoMode, routing.HedgedMode:
+	default:
+		return fmt.Errorf("read mode %q is unknown, expected prime, auto or hedged", fmode)
+	}
+
+	if foffset < 0 {
+		return errors.New("--offset must not be negative")
+	}
+
+	switch len(args) {
+	case 0:
+		return errors.New("bucket/key is required")
+	case 1:
+		bucket, key := diff.SplitPath(args[0])
+		if err := s3utils.CheckValidBucketName(bucket); err != nil {
+			return err
+		}
+
+		return s3utils.CheckValidObjectName(key)
+	default:
+		return errors.New("too many arguments")
+	}
+}
+
+func init() {
+	Cmd.Flags().StringVar(&fmode, "read-mode", "", "read mode overriding Read.Mode: prime, auto or hedged")
+	Cmd.Flags().Int64Var(&foffset, "offset", 0, "first byte of object to write")
+	Cmd.Flags().Int64Var(&flength, "length", -1, "amount of bytes to write, -1 writes the rest of object")
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cat
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestCat(t *testing.T) {
+	content := "0123456789"
+
+	ol := test.NewProxyObjectLayer()
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content)), ETag: "etag"}, nil
+	}
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		assert.Equal(t, "etag", etag)
+
+		_, err := io.WriteString(writer, content[startOffset:startOffset+length])
+		return err
+	}
+
+	cases := []struct {
+		testName       string
+		offset, length int64
+		expected       string
+		err            string
+	}{
+		{testName: "whole object", length: -1, expected: content},
+		{testName: "range", offset: 2, length: 3, expected: "234"},
+		{testName: "rest of object", offset: 7, length: -1, expected: "789"},
+		{testName: "length beyond size", offset: 8, length: 10, expected: "89"},
+		{testName: "offset beyond size", offset: 11, length: -1, err: "offset 11 is beyond size 10 of object"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			err := cat(context.Background(), ol, "bucket", "key", c.offset, c.length, out)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, out.String())
+		})
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	defer func() { fmode = "" }()
+
+	assert.NoError(t, validateArgs(nil, []string{"bucket/key"}))
+	assert.Error(t, validateArgs(nil, []string{"bucket"}))
+	assert.Error(t, validateArgs(nil, []string{"bucket/a", "bucket/b"}))
+
+	fmode = "fastest"
+	assert.EqualError(t, validateArgs(nil, []string{"bucket/key"}), `read mode "fastest" is unknown, expected prime, auto or hedged`)
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/ditto/pkg/config"
+)
+
+var fkeyFile string
+
+var encryptSubCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypts credentials stored in config file",
+	Long: "Encrypts keys of backends and Vault secrets stored in config file with passphrase " +
+		"from " + config.PassphraseEnv + " environment variable, or with content of key file.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeSecretsCmd(encryptSecret)
+	},
+}
+
+var decryptSubCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypts credentials stored in config file",
+	Long:  "Decrypts credentials encrypted by `ditto config encrypt` and stores them in config file in plain text.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeSecretsCmd(config.DecryptSecret)
+	},
+}
+
+// encryptSecret encrypts value unless it's encrypted already.
+func encryptSecret(value string, passphrase []byte) (string, error) {
+	if config.IsEncrypted(value) {
+		return value, nil
+	}
+
+	return config.EncryptSecret(value, passphrase)
+}
+
+// Method reference for unit testing
+var writeConfigFileMethod = viper.WriteConfig
+
+func executeSecretsCmd(transform func(value string, passphrase []byte) (string, error)) error {
+	// encrypted secrets can't be decrypted without passphrase yet, so only file is read here
+	config.ReadConfig(false)
+
+	keyFile := fkeyFile
+	if keyFile == "" {
+		keyFile = viper.GetString(config.SECRETS_KEY_FILE)
+	}
+
+	passphrase, err := config.Passphrase(keyFile)
+	if err != nil {
+		return err
+	}
+
+	if err = transformSecrets(func(value string) (string, error) { return transform(value, passphrase) }); err != nil {
+		return err
+	}
+
+	return writeConfigFileMethod()
+}
+
+// transformSecrets replaces secrets set in config with result of f, secrets already in the result form are kept.
+func transformSecrets(f func(value string) (string, error)) error {
+	for _, key := range config.SecretKeys() {
+		value := viper.GetString(key)
+		if value == "" {
+			continue
+		}
+
+		transformed, err := f(value)
+		if err != nil {
+			return err
+		}
+
+		viper.Set(key, transformed)
+	}
+
+	return nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{encryptSubCmd, decryptSubCmd} {
+		cmd.Flags().StringVar(&fkeyFile, "key-file", "", "file with passphrase, Secrets.KeyFile is used if not set")
+		Cmd.AddCommand(cmd)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+)
+
+func TestTransformSecrets(t *testing.T) {
+	passphrase := []byte("passphrase")
+
+	viper.Set(config.SERVER_1_SECRET_KEY, "secret")
+	viper.Set(config.SERVER_1_ENDPOINT, "endpoint")
+	defer viper.Reset()
+
+	encrypt := func(value string) (string, error) { return encryptSecret(value, passphrase) }
+	decrypt := func(value string) (string, error) { return config.DecryptSecret(value, passphrase) }
+
+	assert.NoError(t, transformSecrets(encrypt))
+
+	encrypted := viper.GetString(config.SERVER_1_SECRET_KEY)
+	assert.True(t, config.IsEncrypted(encrypted))
+	assert.Equal(t, "endpoint", viper.GetString(config.SERVER_1_ENDPOINT))
+
+	// encrypted secrets aren't encrypted twice
+	assert.NoError(t, transformSecrets(encrypt))
+	assert.Equal(t, encrypted, viper.GetString(config.SERVER_1_SECRET_KEY))
+
+	assert.NoError(t, transformSecrets(decrypt))
+	assert.Equal(t, "secret", viper.GetString(config.SERVER_1_SECRET_KEY))
+}
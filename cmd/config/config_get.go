@@ -4,17 +4,22 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"storj.io/ditto/cmd/utils"
 	"storj.io/ditto/pkg/config"
 )
 
 var getSubCmd = &cobra.Command{
 	Use:   "get [key]",
 	Short: "Displays value set for requested key",
-	Long:  "Displays value set for requested key",
+	Long: "Displays value set for requested key. Key is dotted path of any option, e.g. Log.Level, " +
+		"value of section is displayed as JSON.",
 	RunE:  executeGetCmd,
 	Args:  validateGetArgs,
 }
@@ -26,8 +31,12 @@ func executeGetCmd(cmd *cobra.Command, args []string) error {
 	arg := args[0]
 
 	readConfigMethod(false)
-	if containsKey(arg) {
-		fmt.Sprintf("\t%s\n", getValueFromConfigFile(arg))
+	if key, _, ok := config.CanonicalKey(arg); ok {
+		if utils.JSON {
+			return utils.PrintJSON(os.Stdout, map[string]interface{}{"key": key, "value": getRawValue(key)})
+		}
+
+		fmt.Printf("\t%s\n", getValueFromConfigFile(key))
 		return nil
 	} else {
 		return errors.New("Key unsupported")
@@ -43,13 +52,39 @@ func validateGetArgs(cmd *cobra.Command, args [] string) error {
 }
 
 func getValueFromConfigFile(key string) string {
-	if viper.IsSet(key) {
-		return viper.GetString(key)
+	if value := getRawValue(key); value != nil {
+		return formatValue(value)
 	} else {
 		return "Key is not set"
 	}
 }
 
+// getRawValue returns value of key, nil if it isn't set.
+func getRawValue(key string) interface{} {
+	// value of selected profile has priority
+	if profileKey := config.ProfileKey(key); viper.IsSet(profileKey) {
+		return viper.Get(profileKey)
+	}
+
+	if viper.IsSet(key) {
+		return viper.Get(key)
+	}
+
+	return nil
+}
+
+// formatValue formats sections and lists as indented JSON, other values as is.
+func formatValue(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, []string:
+		if b, err := json.MarshalIndent(value, "\t", "  "); err == nil {
+			return string(b)
+		}
+	}
+
+	return fmt.Sprint(value)
+}
+
 func init() {
 	Cmd.AddCommand(getSubCmd)
 }
@@ -5,7 +5,10 @@ package config
 
 import (
 	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/utils"
 	"storj.io/ditto/pkg/config"
 )
 
@@ -13,14 +16,20 @@ var listSubCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Displays list of possible to change options",
 	Long:  "Displays list of possible to change options",
-	Run:   executeListCmd,
+	RunE:  executeListCmd,
 }
 
-func executeListCmd(cmd *cobra.Command, args []string) {
+func executeListCmd(cmd *cobra.Command, args []string) error {
+	if utils.JSON {
+		return utils.PrintJSON(os.Stdout, config.GetKeysArray())
+	}
+
 	fmt.Println("Options, which can be set via `config set`:")
 	for _, value := range config.GetKeysArray() {
 		fmt.Printf("\t%s\n", value)
 	}
+
+	return nil
 }
 
 func init() {
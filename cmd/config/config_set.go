@@ -5,18 +5,21 @@ package config
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"storj.io/ditto/pkg/config"
 )
 
 var setSubCmd = &cobra.Command{
 	Use:   "set [key] [value]",
 	Short: "Change value at saves it to config file",
-	Long:  "Change value at saves it to config file",
-	RunE:  executeSetCmd,
-	Args:  validateSetArgs,
+	Long: "Change value at saves it to config file. Key is dotted path of any option, e.g. Log.Level or " +
+		"BucketMapping.Mapping.photos, lists are comma separated. Config file is edited in place, " +
+		"so its comments and order of keys are preserved.",
+	RunE: executeSetCmd,
+	Args: validateSetArgs,
 }
 
 var writeConfigMethod = writeConfig
@@ -25,16 +28,19 @@ func executeSetCmd(cmd *cobra.Command, args []string) error {
 	if len(args) != 2 {
 		return errors.New("Two arguments expected")
 	}
-	possibleValues, exist := argsMap[args[0]]
+	key, _, exist := config.CanonicalKey(args[0])
 	if !exist {
 
 		return errors.New("Key is not exist")
 	}
 
+	// values of options of profiles are restricted as well
+	possibleValues := argsMap[profileOption(key)]
+
 	if len(possibleValues) == 0 {
-		return writeConfigMethod(args[0], args[1])
+		return writeConfigMethod(key, args[1])
 	} else {
-		return setPredefinedOptions(args[0], args[1], possibleValues)
+		return setPredefinedOptions(key, args[1], possibleValues)
 	}
 }
 
@@ -54,18 +60,25 @@ func setPredefinedOptions(key string, value string, possibleValues []string) err
 	}
 }
 
-func writeConfig(key string, value string) error {
-	config.ReadConfig(false)
+// profileOption returns key of option set by key of profile, e.g. Log.Level for Profiles.prod.Log.Level.
+func profileOption(key string) string {
+	if segments := strings.SplitN(key, ".", 3); len(segments) == 3 && segments[0] == config.ProfilesKey {
+		return segments[2]
+	}
 
-	viper.Set(key, value)
+	return key
+}
 
-	err := viper.WriteConfig()
-	if err != nil {
-		return err
-	}
+func writeConfig(key string, value string) error {
+	// config file is located even if it's not valid yet
+	config.LoadConfig(false)
 
-	return nil
+	// value is written to selected profile, if any
+	if profileOption(key) == key {
+		key = config.ProfileKey(key)
+	}
 
+	return config.SetValue(config.Path(), key, value)
 }
 
 func init() {
@@ -81,6 +81,22 @@ func TestExecuteSetCmd(t *testing.T) {
 			},
 			expectedError: "",
 		},
+		{
+			name: "Valid case for nested key",
+			args: []string{
+				"bucketmapping.mapping.photos",
+				"photos-backup",
+			},
+			expectedError: "",
+		},
+		{
+			name: "Predefined options of profile",
+			args: []string{
+				"Profiles.prod.DefaultOptions.DefaultSource",
+				"server3",
+			},
+			expectedError: "Only these arguments accepted: [server1 server2]",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
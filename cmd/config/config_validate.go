@@ -0,0 +1,131 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/gateway"
+	"storj.io/ditto/pkg/health"
+)
+
+var fprobe bool
+
+var validateSubCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks config file and reports all problems found",
+	Long: "Checks that required options are set, endpoints are well formed and mutually exclusive options " +
+		"aren't set together. With --probe backends are contacted with configured credentials.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeValidateCmd(fprobe)
+	},
+}
+
+// Method references for unit testing
+var loadConfigMethod = config.LoadConfig
+var probeMethod = ProbeBackends
+
+// Validation is JSON output of validate.
+type Validation struct {
+	Valid    bool     `json:"valid"`
+	Problems []string `json:"problems"`
+}
+
+func executeValidateCmd(probe bool) error {
+	cfg, err := loadConfigMethod(true)
+	if err != nil {
+		return err
+	}
+
+	err = validateConfig(cfg, probe)
+
+	if utils.JSON {
+		validation := Validation{Valid: err == nil, Problems: []string{}}
+		if verr, ok := err.(*config.ValidationError); ok {
+			validation.Problems = verr.Problems
+		} else if err != nil {
+			validation.Problems = []string{err.Error()}
+		}
+
+		if jerr := utils.PrintJSON(os.Stdout, validation); jerr != nil {
+			return jerr
+		}
+
+		return err
+	}
+
+	if err == nil {
+		fmt.Println("Config is valid")
+	}
+
+	return err
+}
+
+// validateConfig validates cfg and, if probe is set, checks its backends are reachable.
+func validateConfig(cfg *config.Config, probe bool) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	// backends are probed only if config is valid, so their endpoints are known to be well formed
+	if probe {
+		if problems := probeMethod(cfg); len(problems) > 0 {
+			return &config.ValidationError{Problems: problems}
+		}
+	}
+
+	return nil
+}
+
+// ProbeBackends lists buckets of prime and alter of root and every tenant, returns a problem per unreachable backend.
+func ProbeBackends(cfg *config.Config) []string {
+	prefixes, configs := []string{""}, []*config.Config{cfg}
+	for _, t := range cfg.Tenants {
+		prefixes = append(prefixes, fmt.Sprintf("tenant %q: ", t.Name))
+		configs = append(configs, cfg.ForTenant(t))
+	}
+
+	var problems []string
+
+	for i, c := range configs {
+		prefix := prefixes[i]
+
+		prime, alter, err := (&gateway.Mirroring{Config: c}).NewBackends()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%sunable to create backends: %s", prefix, err))
+			continue
+		}
+
+		for _, backend := range []struct {
+			name     string
+			endpoint string
+			probe    health.Probe
+		}{
+			{"Server1", c.Server1.Endpoint, health.ListBucketsProbe(prime)},
+			{"Server2", c.Server2.Endpoint, health.ListBucketsProbe(alter)},
+		} {
+			ctx, cancel := context.WithTimeout(context.Background(), health.DefaultTimeout)
+			err := backend.probe(ctx)
+			cancel()
+
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s%s at %s is unreachable with configured credentials: %s",
+					prefix, backend.name, backend.endpoint, err))
+			}
+		}
+	}
+
+	return problems
+}
+
+func init() {
+	validateSubCmd.Flags().BoolVar(&fprobe, "probe", false, "list buckets of backends to check they are reachable with configured credentials")
+	Cmd.AddCommand(validateSubCmd)
+}
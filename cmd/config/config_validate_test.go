@@ -0,0 +1,46 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+)
+
+func TestExecuteValidateCmd(t *testing.T) {
+	defer func() {
+		loadConfigMethod = config.LoadConfig
+		probeMethod = ProbeBackends
+	}()
+
+	cfg := &config.Config{
+		Server1: &config.Credentials{Endpoint: "prime:9000", AccessKey: "a1", SecretKey: "s1"},
+		Server2: &config.Credentials{Endpoint: "alter:9000", AccessKey: "a2", SecretKey: "s2"},
+	}
+
+	loadConfigMethod = func(useDefaults bool) (*config.Config, error) { return cfg, nil }
+
+	probed := false
+	probeMethod = func(cfg *config.Config) []string {
+		probed = true
+		return []string{"Server2 at alter:9000 is unreachable with configured credentials: Access Denied"}
+	}
+
+	assert.NoError(t, executeValidateCmd(false))
+	assert.False(t, probed)
+
+	err := executeValidateCmd(true)
+	assert.True(t, probed)
+	assert.EqualError(t, err, "config is invalid:\n  - Server2 at alter:9000 is unreachable with configured credentials: Access Denied")
+
+	// invalid config isn't probed
+	probed = false
+	cfg.Server1.Endpoint = ""
+
+	err = executeValidateCmd(true)
+	assert.False(t, probed)
+	assert.IsType(t, &config.ValidationError{}, err)
+}
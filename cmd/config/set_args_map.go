@@ -20,10 +20,151 @@ var argsMap = map[string][]string{
 	config.PUT_DEFAULT_SOURCE:                {"server1", "server2"},
 	config.PUT_THROW_IMMEDIATELY:             {"true", "false"},
 	config.PUT_CREATE_BUCKET_IF_NOT_EXIST:    {"true", "false"},
+	config.PUT_ASYNC_SIZE_THRESHOLD:          {},
+	config.PUT_BUFFER_SIZE:                   {},
+	config.PUT_SPILL_DIR:                     {},
+	config.PUT_VERIFY_CHECKSUM:               {"true", "false"},
+	config.PUT_DETECT_CONTENT_TYPE:           {"true", "false"},
+	config.PUT_DEDUP:                         {"true", "false"},
 	config.GET_OBJECT_DEFAULT_SOURCE:         {"server1", "server2"},
 	config.GET_OBJECT_THROW_IMMEDIATELY:      {"true", "false"},
+	config.GET_OBJECT_VERIFY_READS:           {"true", "false"},
 	config.COPY_DEFAULT_SOURCE:               {"server1", "server2"},
 	config.COPY_THROW_IMMEDIATELY:            {"true", "false"},
 	config.DELETE_DEFAULT_SOURCE:             {"server1", "server2"},
 	config.DELETE_THROW_IMMEDIATELY:          {"true", "false"},
+	config.DELETE_SOFT_DELETE:                {"true", "false"},
+	config.DELETE_TRASH_PREFIX:               {},
+	config.DELETE_TRASH_RETENTION:            {},
+	config.FILTER_INCLUDE_PREFIXES:           {},
+	config.FILTER_EXCLUDE_PREFIXES:           {},
+	config.FILTER_INCLUDE_PATTERNS:           {},
+	config.FILTER_EXCLUDE_PATTERNS:           {},
+	config.BUCKET_MAPPING_ALTER_PREFIX:       {},
+	config.BUCKET_MAPPING_ALTER_SUFFIX:       {},
+	config.FAILOVER_ENABLED:                  {"true", "false"},
+	config.FAILOVER_FAILURE_THRESHOLD:        {},
+	config.FAILOVER_RECOVERY_THRESHOLD:       {},
+	config.FAILOVER_MIN_DURATION:             {},
+	config.FAILOVER_PROBE_INTERVAL:           {},
+	config.CIRCUIT_BREAKER_ENABLED:           {"true", "false"},
+	config.CIRCUIT_BREAKER_FAILURE_THRESHOLD: {},
+	config.CIRCUIT_BREAKER_COOLDOWN:          {},
+	config.HEALTH_CHECK_ENABLED:              {"true", "false"},
+	config.HEALTH_CHECK_INTERVAL:             {},
+	config.HEALTH_CHECK_TIMEOUT:              {},
+	config.HEALTH_CHECK_BUCKET:               {},
+	config.ADMIN_ADDRESS:                     {},
+	config.ADMIN_GRPC_ADDRESS:                {},
+	config.ADMIN_TOKEN:                       {},
+	config.TIMEOUTS_PUT:                      {},
+	config.TIMEOUTS_GET:                      {},
+	config.TIMEOUTS_LIST:                     {},
+	config.TIMEOUTS_DELETE:                   {},
+	config.JOURNAL_PATH:                      {},
+	config.JOURNAL_REPLAY_INTERVAL:           {},
+	config.SHADOW_ENABLED:                    {"true", "false"},
+	config.SHADOW_PERCENTAGE:                 {},
+	config.DRY_RUN_ENABLED:                   {"true", "false"},
+	config.RATE_LIMIT_PRIME_READ:             {},
+	config.RATE_LIMIT_PRIME_WRITE:            {},
+	config.RATE_LIMIT_ALTER_READ:             {},
+	config.RATE_LIMIT_ALTER_WRITE:            {},
+	config.RATE_LIMIT_BURST:                  {},
+	config.RATE_LIMIT_ALTER_BANDWIDTH:        {},
+	config.REPLICATION_WORKERS:               {},
+	config.REPLICATION_QUEUE_DEPTH:           {},
+	config.REPLICATION_CONCURRENCY:           {},
+	config.READ_ONLY_ENABLED:                 {"true", "false"},
+	config.READ_ONLY_MAX_JOURNAL_SIZE:        {},
+	config.READ_ONLY_MAX_ALTER_DOWNTIME:      {},
+	config.METADATA_NORMALIZE:                {"true", "false"},
+	config.METADATA_CONTENT_HASH:             {"true", "false"},
+	config.GC_ENABLED:                        {"true", "false"},
+	config.GC_INTERVAL:                       {},
+	config.GC_SCHEDULE:                       {},
+	config.GC_MIN_AGE:                        {},
+	config.GC_QUARANTINE:                     {"true", "false"},
+	config.GC_QUARANTINE_PREFIX:              {},
+	config.SYNC_ENABLED:                      {"true", "false"},
+	config.SYNC_INTERVAL:                     {},
+	config.SYNC_SCHEDULE:                     {},
+	config.SYNC_DELETE:                       {"true", "false"},
+	config.SYNC_CHECKPOINT_PATH:              {},
+	config.SEED_ENABLED:                      {"true", "false"},
+	config.SEED_WORKERS:                      {},
+	config.SEED_CHECKPOINT_PATH:              {},
+	config.STATE_PATH:                        {},
+	config.WEBHOOK_URL:                       {},
+	config.WEBHOOK_TIMEOUT:                   {},
+	config.WEBHOOK_RETRIES:                   {},
+	config.EVENTS_TYPE:                       {"nats", "kafka"},
+	config.EVENTS_ADDRESS:                    {},
+	config.EVENTS_TOPIC:                      {},
+	config.METRICS_ENABLED:                   {"true", "false"},
+	config.STATSD_HOST:                       {},
+	config.STATSD_PORT:                       {},
+	config.STATSD_PREFIX:                     {},
+	config.STATSD_DATADOG:                    {"true", "false"},
+	config.TRACING_ENDPOINT:                  {},
+	config.TRACING_SERVICE_NAME:              {},
+	config.TRACING_SAMPLE_RATE:               {},
+	config.DEBUG_ADDRESS:                     {},
+	config.READINESS_WAIT_FOR_SEED:           {"true", "false"},
+	config.READINESS_MAX_JOURNAL_LEN:         {},
+	config.LOG_LEVEL:                         {"debug", "info", "warn", "error"},
+	config.LOG_FORMAT:                        {"json", "console"},
+	config.LOG_MIRRORING_LEVEL:               {"debug", "info", "warn", "error"},
+	config.LOG_REPLICATION_LEVEL:             {"debug", "info", "warn", "error"},
+	config.LOG_SYNC_LEVEL:                    {"debug", "info", "warn", "error"},
+	config.LOG_CLI_LEVEL:                     {"debug", "info", "warn", "error"},
+	config.LOG_FILE:                          {},
+	config.LOG_MAX_SIZE:                      {},
+	config.LOG_MAX_AGE:                       {},
+	config.LOG_MAX_BACKUPS:                   {},
+	config.LOG_COMPRESS:                      {"true", "false"},
+	config.LOG_SYSLOG:                        {},
+	config.LOG_SYSLOG_TAG:                    {},
+	config.LOG_JOURNALD:                      {"true", "false"},
+	config.AUDIT_FILE:                        {},
+	config.ALERT_MAX_LAG:                     {},
+	config.ALERT_MAX_FAILURES:                {},
+	config.ALERT_SLACK_URL:                   {},
+	config.ALERT_SMTP_ADDRESS:                {},
+	config.ALERT_SMTP_USERNAME:               {},
+	config.ALERT_SMTP_PASSWORD:               {},
+	config.ALERT_EMAIL_FROM:                  {},
+	config.ALERT_EMAIL_TO:                    {},
+	config.READ_MODE:                         {"prime", "auto", "hedged"},
+	config.READ_PROBE_INTERVAL:               {},
+	config.CACHE_MISSING_TTL:                 {},
+	config.CACHE_MISSING_SIZE:                {},
+	config.CACHE_BUCKETS_TTL:                 {},
+	config.CACHE_MEMORY_SIZE:                 {},
+	config.CACHE_MEMORY_OBJECT_SIZE:          {},
+	config.CACHE_DISK_DIR:                    {},
+	config.CACHE_DISK_SIZE:                   {},
+	config.CACHE_DISK_OBJECT_SIZE:            {},
+	config.COMPRESSION_ALGORITHM:             {"none", "gzip", "zstd"},
+	config.COMPRESSION_MIN_SIZE:              {},
+	config.ENCRYPTION_KEY:                    {},
+	config.ENCRYPTION_KEY_FILE:               {},
+	config.QUOTA_MAX_SIZE:                    {},
+	config.QUOTA_MAX_OBJECTS:                 {},
+	config.ACCOUNTING_PATH:                   {},
+	config.ACCOUNTING_FLUSH_INTERVAL:         {},
+	config.ROTATION_ENABLED:                  {"true", "false"},
+	config.ROTATION_FILE:                     {},
+	config.ROTATION_WATCH_INTERVAL:           {},
+	config.VAULT_ADDRESS:                     {},
+	config.VAULT_TOKEN:                       {},
+	config.VAULT_ROLE_ID:                     {},
+	config.VAULT_SECRET_ID:                   {},
+	config.VAULT_SERVER_1_PATH:               {},
+	config.VAULT_SERVER_2_PATH:               {},
+	config.VAULT_REFRESH_INTERVAL:            {},
+	config.SECRETS_KEY_FILE:                  {},
+	config.RELOAD_ENABLED:                    {"true", "false"},
+	config.RELOAD_WATCH_INTERVAL:             {},
+	config.REPAIR_POLICY:                     {"prime", "alter", "merge"},
 }
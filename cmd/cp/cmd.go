@@ -7,23 +7,68 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/minio/minio-go/pkg/s3utils"
 	minio "github.com/minio/minio/cmd"
 	"github.com/spf13/cobra"
 	cmdUtils "storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/filter"
+	"storj.io/ditto/pkg/objlayer/checksum"
+	"storj.io/ditto/pkg/progress"
+	"storj.io/ditto/pkg/uploader"
 	"storj.io/ditto/pkg/utils"
 )
 
 var mirroring = cmdUtils.GetObjectLayer
 var missingArgsErrorMessage = "at least three arguments required."
 
+var (
+	fquiet, frecursive bool
+	fchecksum          bool
+	fconcurrency       int
+	ffromFile          string
+	fstateFile         string
+	fpartSize          int64
+	fbwlimit           string
+	ffilter            cmdUtils.FilterFlags
+)
+
+// Result is JSON output of copy.
+type Result struct {
+	SrcBucket string `json:"srcBucket"`
+	SrcObject string `json:"srcObject"`
+	DstBucket string `json:"dstBucket"`
+	DstObject string `json:"dstObject"`
+	Size      int64  `json:"size"`
+	ETag      string `json:"etag"`
+}
+
 var Cmd = &cobra.Command {
-	Use: "copy [cp] srcBucket, srcObj, dstBucket, dstObj(OPTIONAL).",
+	Use: "copy [cp] srcBucket, srcObj, dstBucket, dstObj(OPTIONAL). Or file, dstBucket/dstObj(OPTIONAL).",
 
 	Args: validateArgs,
 	Short: "Creates a cp of an object.",
 	Long:  "Creates a cp of an object that is already stored in a bucket. dstObj is optional. " +
-		   "If not specified, dstObj name will be as srcObj.",
+		   "If not specified, dstObj name will be as srcObj. Progress is rendered to terminal " +
+		   "and summary is printed once copied, unless --quiet is given. With --recursive srcObj is prefix, " +
+		   "every object under it is copied by --concurrency parallel workers and srcObj prefix of its name " +
+		   "is replaced with dstObj. srcObj can be pattern, e.g. logs/2024-*.gz, then every matching object " +
+		   "is copied, with directory of pattern replaced with dstObj if given. With --from-file arguments are " +
+		   "srcBucket, dstBucket and optional dstPrefix, keys listed in file, one per line, are copied " +
+		   "from srcBucket to dstBucket as they are, prefixed with dstPrefix. Given local file and dstBucket/dstObj, " +
+		   "file is uploaded, dstObj is file name if not given or ending with /. Files bigger than --part-size are " +
+		   "uploaded by --concurrency parallel parts recorded in --state-file, so interrupted upload continues " +
+		   "from the last uploaded part once the same command is run again. --bwlimit caps bandwidth of file uploads, " +
+		   "e.g. 10M, so they don't saturate the network. With --recursive or pattern --include, --exclude, " +
+		   "--min-size, --max-size, --newer-than and --older-than select objects which are copied. With --checksum " +
+		   "every copy and upload is verified against its source by ETag, or by reading it back if ETag isn't MD5 " +
+		   "of content, mismatch fails it.",
 	RunE: exec,
 }
 
@@ -35,30 +80,284 @@ func exec(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	objectInfo, _ := objectLayer.GetObjectInfo(ctx, args[0], args[1], minio.ObjectOptions{})
+
+	if objectLayer, err = cmdUtils.LimitBandwidth(objectLayer, fbwlimit); err != nil {
+		return err
+	}
+
+	if isUpload(args) {
+		// Waits for background mirroring of uploaded file to finish
+		defer objectLayer.Shutdown(ctx)
+
+		bucket, object := splitDestination(args[0], args[1])
+
+		return upload(ctx, objectLayer, args[0], bucket, object, statePath(args[0]), fpartSize<<20, fconcurrency, fchecksum, os.Stdout)
+	}
+
+	// uploads are verified by uploader, which knows hashes of parts uploaded by previous runs
+	if fchecksum {
+		objectLayer = checksum.NewVerifyingLayer(objectLayer)
+	}
+
+	if ffromFile != "" {
+		keys, err := cmdUtils.ReadKeys(ffromFile)
+		if err != nil {
+			return err
+		}
+
+		dstPrefix := ""
+		if len(args) == 3 {
+			dstPrefix = args[2]
+		}
+
+		return copyKeys(ctx, objectLayer, args[0], keys, args[1], dstPrefix, fconcurrency, os.Stdout)
+	}
 
 	dstObj := args[1]
 
+	f, err := ffilter.Filter(time.Now())
+	if err != nil {
+		return err
+	}
+
 	if len(args) == 4 {
 		dstObj = args[3]
 	}
 
+	if frecursive {
+		return copyPrefix(ctx, objectLayer, args[0], args[1], args[2], dstObj, f, fconcurrency, os.Stdout)
+	}
+
+	if cmdUtils.IsPattern(args[1]) {
+		if len(args) < 4 {
+			dstObj = cmdUtils.PatternDir(args[1])
+		}
+
+		return copyPattern(ctx, objectLayer, args[0], args[1], args[2], dstObj, f, fconcurrency, os.Stdout)
+	}
+
+	objectInfo, _ := objectLayer.GetObjectInfo(ctx, args[0], args[1], minio.ObjectOptions{})
+
+	tracker := progress.NewTracker(cmdUtils.ProgressOutput(fquiet))
+	tracker.SetTotal(objectInfo.Size)
+	tracker.Start()
+
 	//TODO: enable object options in future
-	_, err = objectLayer.CopyObject(ctx, args[0], args[1], args[2], dstObj, objectInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+	dstInfo, err := tracker.Layer(objectLayer).CopyObject(ctx, args[0], args[1], args[2], dstObj, objectInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+	summary := tracker.Stop()
+
+	if _, ok := err.(checksum.MismatchError); ok {
+		return cmdUtils.WithExitCode(cmdUtils.ExitMismatch, err)
+	}
 
 	if err != nil {
 		return err
 	}
 
+	if cmdUtils.JSON {
+		return cmdUtils.PrintJSON(os.Stdout, Result{
+			SrcBucket: args[0],
+			SrcObject: args[1],
+			DstBucket: args[2],
+			DstObject: dstObj,
+			Size:      dstInfo.Size,
+			ETag:      dstInfo.ETag,
+		})
+	}
+
 	fmt.Printf("Object %s/%s copied\n", args[0], args[1])
 
+	if !fquiet {
+		fmt.Println(summary)
+	}
+
 	return nil
 }
 
+// copyPrefix copies every object of srcBucket under srcPrefix selected by f to dstBucket,
+// srcPrefix of object name is replaced with dstPrefix.
+func copyPrefix(ctx context.Context, objectLayer minio.ObjectLayer, srcBucket, srcPrefix, dstBucket, dstPrefix string, f *filter.Filter, concurrency int, out io.Writer) error {
+	// objects copied inside source prefix would be listed and copied again
+	if srcBucket == dstBucket && strings.HasPrefix(dstPrefix, srcPrefix) {
+		return fmt.Errorf("destination %s/%s is inside source %s/%s", dstBucket, dstPrefix, srcBucket, srcPrefix)
+	}
+
+	list := func(fn func(oi minio.ObjectInfo) error) error {
+		return cmdUtils.ListObjects(ctx, objectLayer, srcBucket, srcPrefix, func(oi minio.ObjectInfo) error {
+			if !f.Match(oi) {
+				return nil
+			}
+
+			return fn(oi)
+		})
+	}
+
+	return copyObjects(ctx, objectLayer, srcBucket, srcPrefix, dstBucket, dstPrefix, list, false, concurrency, out)
+}
+
+// copyPattern copies every object of srcBucket matching pattern and selected by f to dstBucket,
+// directory of pattern in object name is replaced with dstPrefix.
+func copyPattern(ctx context.Context, objectLayer minio.ObjectLayer, srcBucket, pattern, dstBucket, dstPrefix string, f *filter.Filter, concurrency int, out io.Writer) error {
+	// pattern is expanded before copying, so copies matching it aren't copied again
+	matched, err := cmdUtils.ExpandPattern(ctx, objectLayer, srcBucket, pattern)
+	if err != nil {
+		return err
+	}
+
+	if len(matched) == 0 {
+		return fmt.Errorf("no objects of %s match %s", srcBucket, pattern)
+	}
+
+	list := func(fn func(oi minio.ObjectInfo) error) error {
+		for _, oi := range matched {
+			if !f.Match(oi) {
+				continue
+			}
+
+			if err := fn(oi); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return copyObjects(ctx, objectLayer, srcBucket, cmdUtils.PatternDir(pattern), dstBucket, dstPrefix, list, false, concurrency, out)
+}
+
+// copyKeys copies objects of srcBucket given by keys to dstBucket, names prefixed with dstPrefix.
+func copyKeys(ctx context.Context, objectLayer minio.ObjectLayer, srcBucket string, keys []string, dstBucket, dstPrefix string, concurrency int, out io.Writer) error {
+	list := func(fn func(oi minio.ObjectInfo) error) error {
+		for _, key := range keys {
+			if err := fn(minio.ObjectInfo{Bucket: srcBucket, Name: key}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return copyObjects(ctx, objectLayer, srcBucket, "", dstBucket, dstPrefix, list, true, concurrency, out)
+}
+
+// copyObjects copies objects of srcBucket given by list to dstBucket by concurrency parallel workers,
+// srcPrefix of object name is replaced with dstPrefix. With stat list gives only names of objects,
+// their info is read before copying. Failed object doesn't stop copying of others.
+func copyObjects(ctx context.Context, objectLayer minio.ObjectLayer, srcBucket, srcPrefix, dstBucket, dstPrefix string,
+	list func(fn func(oi minio.ObjectInfo) error) error, stat bool, concurrency int, out io.Writer) error {
+	tracker := progress.NewTracker(cmdUtils.ProgressOutput(fquiet))
+	tracked := tracker.Layer(objectLayer)
+
+	var (
+		mu       sync.Mutex
+		results  = []Result{}
+		failures []string
+		wg       sync.WaitGroup
+	)
+
+	objects := make(chan minio.ObjectInfo)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for oi := range objects {
+				dstObj := dstPrefix + strings.TrimPrefix(oi.Name, srcPrefix)
+
+				var err error
+				if stat {
+					var info minio.ObjectInfo
+					if info, err = objectLayer.GetObjectInfo(ctx, srcBucket, oi.Name, minio.ObjectOptions{}); err == nil {
+						oi = info
+					}
+				}
+
+				var dstInfo minio.ObjectInfo
+				if err == nil {
+					//TODO: enable object options in future
+					dstInfo, err = tracked.CopyObject(ctx, srcBucket, oi.Name, dstBucket, dstObj, oi, minio.ObjectOptions{}, minio.ObjectOptions{})
+				}
+
+				mu.Lock()
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("Object %s/%s failed to be copied: %s", srcBucket, oi.Name, err))
+					cmdUtils.ReportFailure("copy", srcBucket, oi.Name, "", err)
+				} else {
+					results = append(results, Result{
+						SrcBucket: srcBucket,
+						SrcObject: oi.Name,
+						DstBucket: dstBucket,
+						DstObject: dstObj,
+						Size:      dstInfo.Size,
+						ETag:      dstInfo.ETag,
+					})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	tracker.Start()
+	err := list(func(oi minio.ObjectInfo) error {
+		objects <- oi
+		return nil
+	})
+	close(objects)
+	wg.Wait()
+	summary := tracker.Stop()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].SrcObject < results[j].SrcObject })
+	sort.Strings(failures)
+
+	if cmdUtils.JSON {
+		if jerr := cmdUtils.PrintJSON(out, results); jerr != nil {
+			return jerr
+		}
+	} else {
+		for _, f := range failures {
+			fmt.Fprintln(out, f)
+		}
+
+		fmt.Fprintf(out, "Copied %d objects to %s/%s\n", len(results), dstBucket, dstPrefix)
+
+		if !fquiet {
+			fmt.Fprintln(out, summary)
+		}
+	}
+
+	if err == nil && len(failures) > 0 {
+		err = cmdUtils.WithExitCode(cmdUtils.ExitPartial, fmt.Errorf("%d objects failed to be copied", len(failures)))
+	}
+
+	return err
+}
+
 func validateArgs(cmd *cobra.Command, args []string) error {
 
+	if fconcurrency <= 0 {
+		return errors.New("--concurrency must be positive")
+	}
+
+	if _, err := cmdUtils.ParseBandwidth(fbwlimit); err != nil {
+		return err
+	}
+
+	if _, err := ffilter.Filter(time.Now()); err != nil {
+		return err
+	}
+
+	if ffromFile != "" {
+		return validateFromFileArgs(args)
+	}
+
+	if isUpload(args) {
+		return validateUploadArgs(args)
+	}
+
 	switch len(args) {
-		case 0, 1, 2:
+		case 0, 1:
 			return errors.New(missingArgsErrorMessage)
 		case 3, 4:
 			srcBucketNameErr := s3utils.CheckValidBucketName(args[0])
@@ -82,6 +381,14 @@ func validateArgs(cmd *cobra.Command, args []string) error {
 				return err
 			}
 
+			if frecursive && cmdUtils.IsPattern(args[1]) {
+				return errors.New("srcObj can't be pattern with --recursive")
+			}
+
+			if ffilter.Given() && !frecursive && !cmdUtils.IsPattern(args[1]) {
+				return errors.New("filters require --recursive or pattern srcObj")
+			}
+
 			return nil
 
 		default:
@@ -91,6 +398,33 @@ func validateArgs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func init() {
+// validateFromFileArgs checks srcBucket, dstBucket and optional dstPrefix given with --from-file.
+func validateFromFileArgs(args []string) error {
+	switch {
+	case len(args) < 2:
+		return errors.New("srcBucket and dstBucket are required with --from-file")
+	case len(args) > 3:
+		return errors.New("too many arguments, objects are read from --from-file")
+	case frecursive:
+		return errors.New("--recursive can't be given with --from-file")
+	case ffilter.Given():
+		return errors.New("filters can't be given with --from-file")
+	}
 
+	return utils.CombineErrors([]error{
+		utils.NewError(s3utils.CheckValidBucketName(args[0]), "srcBucket - "),
+		utils.NewError(s3utils.CheckValidBucketName(args[1]), "dstBucket - "),
+	})
+}
+
+func init() {
+	Cmd.Flags().BoolVarP(&fquiet, "quiet", "q", false, "don't render progress nor print summary")
+	Cmd.Flags().BoolVarP(&frecursive, "recursive", "r", false, "copy every object under srcObj prefix")
+	Cmd.Flags().IntVar(&fconcurrency, "concurrency", 4, "number of objects copied in parallel with --recursive, pattern or --from-file, or of parts of uploaded file")
+	Cmd.Flags().StringVar(&ffromFile, "from-file", "", "file listing keys of srcBucket to copy, one per line, - reads standard input")
+	Cmd.Flags().StringVar(&fstateFile, "state-file", "", "state file of resumable upload of file, file path with .ditto-upload suffix by default")
+	Cmd.Flags().Int64Var(&fpartSize, "part-size", uploader.DefaultPartSize>>20, "size of parts of uploaded file in MiB")
+	ffilter.Register(Cmd)
+	Cmd.Flags().BoolVar(&fchecksum, "checksum", false, "verify checksum of every copied object and uploaded file")
+	Cmd.Flags().StringVar(&fbwlimit, "bwlimit", "", "bytes per second of file upload, e.g. 512K or 10M, unlimited by default")
 }
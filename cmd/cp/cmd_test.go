@@ -4,12 +4,17 @@
 package cp
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
 	minio "github.com/minio/minio/cmd"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
-	"testing"
-
+	"storj.io/ditto/pkg/filter"
 	test "storj.io/ditto/pkg/utils/testing_utils"
 )
 
@@ -189,3 +194,107 @@ func TestExec(t *testing.T) {
 	}
 
 }
+
+func TestCopyPrefix(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+
+	// objects are listed in pages of two
+	names := []string{"photos/a", "photos/b", "photos/c/d", "photos/e"}
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		var loi minio.ListObjectsInfo
+		for _, name := range names {
+			if name > marker && len(loi.Objects) < 2 {
+				loi.Objects = append(loi.Objects, minio.ObjectInfo{Bucket: bucket, Name: name, Size: 1})
+			}
+		}
+
+		loi.IsTruncated = loi.Objects[len(loi.Objects)-1].Name != names[len(names)-1]
+
+		return loi, nil
+	}
+
+	var mu sync.Mutex
+	var copied []string
+
+	ol.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		if srcObject == "photos/b" {
+			return minio.ObjectInfo{}, errors.New("access denied")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		copied = append(copied, destBucket+"/"+destObject)
+
+		return minio.ObjectInfo{Bucket: destBucket, Name: destObject, Size: srcInfo.Size}, nil
+	}
+
+	out := &bytes.Buffer{}
+
+	err := copyPrefix(context.Background(), ol, "bucket", "photos/", "backup", "2018/", nil, 3, out)
+
+	sort.Strings(copied)
+
+	assert.EqualError(t, err, "1 objects failed to be copied")
+	assert.Equal(t, []string{"backup/2018/a", "backup/2018/c/d", "backup/2018/e"}, copied)
+	assert.Contains(t, out.String(), "Object bucket/photos/b failed to be copied: access denied\n"+
+		"Copied 3 objects to backup/2018/\n"+
+		"copied 3, skipped 0, failed 1, 3 B in ")
+
+	err = copyPrefix(context.Background(), ol, "bucket", "photos/", "bucket", "photos/backup/", nil, 3, out)
+	assert.EqualError(t, err, "destination bucket/photos/backup/ is inside source bucket/photos/")
+
+	// pattern doesn't match nested photos/c/d, its directory is replaced with destination
+	copied = nil
+
+	assert.NoError(t, copyPattern(context.Background(), ol, "bucket", "photos/[ace]*", "bucket", "photos/backup/", nil, 3, out))
+
+	sort.Strings(copied)
+	assert.Equal(t, []string{"bucket/photos/backup/a", "bucket/photos/backup/e"}, copied)
+
+	// only objects selected by filter are copied
+	copied = nil
+
+	err = copyPrefix(context.Background(), ol, "bucket", "photos/", "backup", "2018/", &filter.Filter{Exclude: []string{"b", "photos/c/*"}}, 3, out)
+	assert.NoError(t, err)
+
+	sort.Strings(copied)
+	assert.Equal(t, []string{"backup/2018/a", "backup/2018/e"}, copied)
+}
+
+func TestCopyKeys(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		if object == "missing" {
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: 2}, nil
+	}
+
+	var mu sync.Mutex
+	var copied []string
+
+	ol.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		copied = append(copied, fmt.Sprintf("%s/%s %d", destBucket, destObject, srcInfo.Size))
+
+		return minio.ObjectInfo{Bucket: destBucket, Name: destObject, Size: srcInfo.Size}, nil
+	}
+
+	out := &bytes.Buffer{}
+
+	// keys are copied literally, * isn't a pattern
+	err := copyKeys(context.Background(), ol, "bucket", []string{"logs/*.gz", "missing", "readme"}, "backup", "2018/", 2, out)
+
+	sort.Strings(copied)
+
+	assert.EqualError(t, err, "1 objects failed to be copied")
+	assert.Equal(t, []string{"backup/2018/logs/*.gz 2", "backup/2018/readme 2"}, copied)
+	assert.Contains(t, out.String(), "Object bucket/missing failed to be copied: Object not found: bucket#missing\n"+
+		"Copied 2 objects to backup/2018/\n")
+}
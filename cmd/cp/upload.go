@@ -0,0 +1,146 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	cmdUtils "storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/objlayer/checksum"
+	"storj.io/ditto/pkg/progress"
+	"storj.io/ditto/pkg/uploader"
+	"storj.io/ditto/pkg/utils"
+)
+
+// isUpload returns true if args are local file and bucket/[object] destination it's uploaded to.
+func isUpload(args []string) bool {
+	return ffromFile == "" && len(args) == 2
+}
+
+// splitDestination splits bucket/[object] destination of upload, object is name of file at lpath if not given.
+func splitDestination(lpath, dst string) (bucket, object string) {
+	bucket = dst
+	if i := strings.Index(dst, "/"); i >= 0 {
+		bucket, object = dst[:i], dst[i+1:]
+	}
+
+	if object == "" || strings.HasSuffix(object, "/") {
+		object += filepath.Base(lpath)
+	}
+
+	return bucket, object
+}
+
+// statePath returns path of state file of upload of file at lpath, --state-file if given.
+func statePath(lpath string) string {
+	if fstateFile != "" {
+		return fstateFile
+	}
+
+	return lpath + ".ditto-upload"
+}
+
+// upload uploads file at lpath to object of bucket by parts of partSize, concurrency parts at once,
+// resuming upload recorded in state file. With verify uploaded object is verified against the file.
+func upload(ctx context.Context, objectLayer minio.ObjectLayer, lpath, bucket, object, state string, partSize int64, concurrency int, verify bool, out io.Writer) error {
+	fi, err := os.Stat(lpath)
+	if err != nil {
+		return err
+	}
+
+	tracker := progress.NewTracker(cmdUtils.ProgressOutput(fquiet))
+	tracker.SetTotal(fi.Size())
+
+	u := uploader.NewResumableUploader(objectLayer, partSize, concurrency)
+	u.Checksum = verify
+	u.Resumed = func(parts int, bytes int64) {
+		tracker.SetTotal(fi.Size() - bytes)
+
+		if !cmdUtils.JSON {
+			fmt.Fprintf(out, "Resuming upload of %s, %d parts (%d bytes) already uploaded\n", lpath, parts, bytes)
+		}
+	}
+
+	tracker.Start()
+	tr := tracker.Begin(bucket+"/"+object, fi.Size())
+	u.Progress = tr.Add
+
+	oi, err := u.Upload(ctx, bucket, object, lpath, state)
+	tr.End(err)
+	summary := tracker.Stop()
+
+	if _, ok := err.(checksum.MismatchError); ok {
+		return cmdUtils.WithExitCode(cmdUtils.ExitMismatch, err)
+	}
+
+	if err != nil {
+		if _, serr := os.Stat(state); serr == nil {
+			return fmt.Errorf("%s, run the same command again to resume upload", err)
+		}
+
+		return err
+	}
+
+	if cmdUtils.JSON {
+		return cmdUtils.PrintJSON(out, Result{
+			SrcObject: lpath,
+			DstBucket: bucket,
+			DstObject: object,
+			Size:      oi.Size,
+			ETag:      oi.ETag,
+		})
+	}
+
+	fmt.Fprintf(out, "File %s uploaded to %s/%s\n", lpath, bucket, object)
+
+	if !fquiet {
+		fmt.Fprintln(out, summary)
+	}
+
+	return nil
+}
+
+// validateUploadArgs checks local file and bucket/[object] destination of upload.
+func validateUploadArgs(args []string) error {
+	// two arguments which aren't file upload are incomplete copy of an object
+	fi, err := os.Stat(args[0])
+	if os.IsNotExist(err) {
+		return errors.New(missingArgsErrorMessage)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if fi.IsDir() {
+		return fmt.Errorf("%s is a directory, use put to upload directories", args[0])
+	}
+
+	if frecursive {
+		return errors.New("--recursive can't be given with file upload")
+	}
+
+	if ffilter.Given() {
+		return errors.New("filters can't be given with file upload")
+	}
+
+	if fpartSize*(1<<20) < uploader.MinPartSize {
+		return fmt.Errorf("--part-size must be at least %d MiB", uploader.MinPartSize>>20)
+	}
+
+	bucket, object := splitDestination(args[0], args[1])
+
+	return utils.CombineErrors([]error{
+		utils.NewError(s3utils.CheckValidBucketName(bucket), "dstBucket - "),
+		utils.NewError(s3utils.CheckValidObjectName(object), "dstObject - "),
+	})
+}
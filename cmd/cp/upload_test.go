@@ -0,0 +1,115 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestUpload(t *testing.T) {
+	fquiet = true
+	defer func() { fquiet = false }()
+
+	dir, err := ioutil.TempDir("", "upload")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lpath := filepath.Join(dir, "backup.tar")
+	assert.NoError(t, ioutil.WriteFile(lpath, []byte("0123456789"), 0600))
+
+	var mu sync.Mutex
+
+	parts := map[int][]byte{}
+	failing := 2
+
+	var stored []byte
+
+	ol := test.NewProxyObjectLayer()
+	ol.NewMultipartUploadFunc = func(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+		return "upload", nil
+	}
+	ol.PutObjectPartFunc = func(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if partID == failing {
+			failing = 0
+			return minio.PartInfo{}, errors.New("connection reset")
+		}
+
+		content, err := ioutil.ReadAll(data)
+		parts[partID] = content
+
+		return minio.PartInfo{PartNumber: partID, ETag: fmt.Sprint(partID)}, err
+	}
+	ol.CompleteMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		var content []byte
+		for _, p := range uploadedParts {
+			content = append(content, parts[p.PartNumber]...)
+		}
+
+		stored = content
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content)), ETag: string(content)}, nil
+	}
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(stored))}, nil
+	}
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		_, err := writer.Write(stored)
+		return err
+	}
+
+	state := lpath + ".ditto-upload"
+
+	out := &bytes.Buffer{}
+	err = upload(context.Background(), ol, lpath, "bucket", "2018/backup.tar", state, 4, 1, false, out)
+	assert.EqualError(t, err, "connection reset, run the same command again to resume upload")
+
+	err = upload(context.Background(), ol, lpath, "bucket", "2018/backup.tar", state, 4, 1, false, out)
+	assert.NoError(t, err)
+	assert.Equal(t, "Resuming upload of "+lpath+", 1 parts (4 bytes) already uploaded\n"+
+		"File "+lpath+" uploaded to bucket/2018/backup.tar\n", out.String())
+
+	_, err = os.Stat(state)
+	assert.True(t, os.IsNotExist(err))
+
+	// parts are uploaded in parallel, object is read back to verify checksum as its ETag isn't computed by S3
+	out.Reset()
+	err = upload(context.Background(), ol, lpath, "bucket", "backup.tar", state, 2, 4, true, out)
+	assert.NoError(t, err)
+	assert.Equal(t, "File "+lpath+" uploaded to bucket/backup.tar\n", out.String())
+}
+
+func TestSplitDestination(t *testing.T) {
+	cases := []struct {
+		dst, bucket, object string
+	}{
+		{"bucket", "bucket", "backup.tar"},
+		{"bucket/", "bucket", "backup.tar"},
+		{"bucket/2018/", "bucket", "2018/backup.tar"},
+		{"bucket/2018/last.tar", "bucket", "2018/last.tar"},
+	}
+
+	for _, c := range cases {
+		bucket, object := splitDestination("/tmp/backup.tar", c.dst)
+
+		assert.Equal(t, c.bucket, bucket, c.dst)
+		assert.Equal(t, c.object, object, c.dst)
+	}
+}
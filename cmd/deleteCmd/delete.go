@@ -1 +1,210 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
 package deleteCmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/utils"
+)
+
+// Function listed as var for testing purposes only
+var objectLayer = utils.GetObjectLayer
+
+var fdryRun, frecursive, fyes, fforce bool
+
+var ffromFile string
+
+var Cmd = &cobra.Command{
+	Use:   "rm <bucket> [object]...",
+	Short: "Deletes objects from bucket",
+	Long: "Deletes objects from prime and alter. Object can be pattern, e.g. logs/2024-*.gz, " +
+		"then every matching object is deleted. * and ? of pattern don't match /. With --recursive object is prefix " +
+		"and every object under it is deleted. Amount of objects selected by patterns and prefixes is shown and " +
+		"has to be confirmed before anything is deleted, unless --yes is given. With --from-file objects are " +
+		"read from file, one key per line, and deleted as they are, without expanding patterns, once confirmed.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	ol, err := objectLayer()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	// Waits for background mirroring of deletes to finish
+	defer ol.Shutdown(ctx)
+
+	confirm := utils.NewConfirm(fyes || fforce)
+
+	if ffromFile != "" {
+		keys, err := utils.ReadKeys(ffromFile)
+		if err != nil {
+			return err
+		}
+
+		return remove(ctx, ol, args[0], keys, nil, true, fdryRun, confirm, os.Stdout)
+	}
+
+	return run(ctx, ol, args[0], args[1:], frecursive, fdryRun, confirm, os.Stdout)
+}
+
+// run deletes objects of bucket, prefixes if recursive is set. Patterns and prefixes are expanded
+// and their objects confirmed by confirm before anything is deleted, nil confirm doesn't ask.
+func run(ctx context.Context, ol minio.ObjectLayer, bucket string, objects []string, recursive, dryRun bool,
+	confirm utils.Confirm, out io.Writer) error {
+	var names []string
+
+	// sizes of listed objects, printed by dry run
+	sizes := make(map[string]int64)
+
+	expanded := false
+
+	for _, arg := range objects {
+		var matched []string
+		var err error
+
+		switch {
+		case recursive:
+			err = utils.ListObjects(ctx, ol, bucket, arg, func(oi minio.ObjectInfo) error {
+				matched = append(matched, oi.Name)
+				sizes[oi.Name] = oi.Size
+				return nil
+			})
+		case utils.IsPattern(arg):
+			var infos []minio.ObjectInfo
+			infos, err = utils.ExpandPattern(ctx, ol, bucket, arg)
+			for _, oi := range infos {
+				matched = append(matched, oi.Name)
+				sizes[oi.Name] = oi.Size
+			}
+		default:
+			names = append(names, arg)
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(matched) == 0 {
+			return fmt.Errorf("no objects of %s match %s", bucket, arg)
+		}
+
+		names = append(names, matched...)
+		expanded = true
+	}
+
+	return remove(ctx, ol, bucket, names, sizes, expanded, dryRun, confirm, out)
+}
+
+// remove deletes objects of bucket given by names, confirmed by confirm first if confirmed is set.
+// Dry run only prints objects with sizes, sizes of listed objects aren't looked up, and isn't confirmed.
+// Failed object doesn't stop deleting of others.
+func remove(ctx context.Context, ol minio.ObjectLayer, bucket string, names []string, sizes map[string]int64,
+	confirmed, dryRun bool, confirm utils.Confirm, out io.Writer) error {
+	if dryRun {
+		return plan(ctx, ol, bucket, names, sizes, out)
+	}
+
+	if confirmed {
+		summary := fmt.Sprintf("delete %s objects of %s from 2 backends", utils.FormatCount(len(names)), bucket)
+		if err := utils.Ask(confirm, summary); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+
+	for _, name := range names {
+		if err := ol.DeleteObject(ctx, bucket, name); err != nil {
+			failed++
+			fmt.Fprintf(out, "Object %s/%s failed to be deleted: %s\n", bucket, name, err)
+			utils.ReportFailure("delete", bucket, name, "", err)
+			continue
+		}
+
+		fmt.Fprintf(out, "Object %s/%s deleted\n", bucket, name)
+	}
+
+	if failed > 0 {
+		return utils.WithExitCode(utils.ExitPartial, fmt.Errorf("%d objects failed to be deleted", failed))
+	}
+
+	return nil
+}
+
+// plan prints objects which would be deleted with their sizes, sizes of objects given by name are read from ol.
+func plan(ctx context.Context, ol minio.ObjectLayer, bucket string, names []string, sizes map[string]int64, out io.Writer) error {
+	count, total := 0, int64(0)
+
+	for _, name := range names {
+		size, ok := sizes[name]
+		if !ok {
+			oi, err := ol.GetObjectInfo(ctx, bucket, name, minio.ObjectOptions{})
+			if err != nil {
+				fmt.Fprintf(out, "Object %s/%s can't be deleted: %s\n", bucket, name, err)
+				continue
+			}
+
+			size = oi.Size
+		}
+
+		count++
+		total += size
+
+		fmt.Fprintf(out, "Object %s/%s (%d bytes) would be deleted from prime and alter\n", bucket, name, size)
+	}
+
+	fmt.Fprintf(out, "%d objects (%d bytes) would be deleted from prime and alter\n", count, total)
+
+	return nil
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	if ffromFile != "" {
+		switch {
+		case len(args) != 1:
+			return errors.New("only bucket can be given with --from-file, objects are read from file")
+		case frecursive:
+			return errors.New("--recursive can't be given with --from-file")
+		}
+	} else if len(args) < 2 {
+		return errors.New("bucket and at least one object are required")
+	}
+
+	if err := s3utils.CheckValidBucketName(args[0]); err != nil {
+		return err
+	}
+
+	for _, object := range args[1:] {
+		if err := s3utils.CheckValidObjectName(object); err != nil {
+			return fmt.Errorf("object %q: %s", object, err)
+		}
+
+		if frecursive && utils.IsPattern(object) {
+			return fmt.Errorf("object %q: pattern can't be given with --recursive", object)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&fdryRun, "dry-run", false, "only print objects which would be deleted with their sizes")
+	Cmd.Flags().BoolVarP(&frecursive, "recursive", "r", false, "delete every object under given prefixes")
+	Cmd.Flags().StringVar(&ffromFile, "from-file", "", "file listing keys to delete, one per line, - reads standard input")
+	Cmd.Flags().BoolVarP(&fyes, "yes", "y", false, "don't ask for confirmation")
+	Cmd.Flags().BoolVarP(&fforce, "force", "f", false, "don't ask for confirmation")
+	Cmd.Flags().MarkDeprecated("force", "use --yes instead")
+}
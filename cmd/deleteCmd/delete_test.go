@@ -0,0 +1,155 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package deleteCmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/cmd/utils"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestRun(t *testing.T) {
+	names := []string{"logs/2018-01.gz", "logs/2018-02.gz", "logs/2018-02.txt", "logs/old/2018-03.gz", "readme"}
+
+	var listed []string
+	var deleted []string
+
+	ol := test.NewProxyObjectLayer()
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		listed = append(listed, prefix)
+
+		var loi minio.ListObjectsInfo
+		for _, name := range names {
+			if len(name) >= len(prefix) && name[:len(prefix)] == prefix {
+				loi.Objects = append(loi.Objects, minio.ObjectInfo{Bucket: bucket, Name: name, Size: int64(len(name))})
+			}
+		}
+
+		return loi, nil
+	}
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		for _, name := range names {
+			if name == object {
+				return minio.ObjectInfo{Bucket: bucket, Name: name, Size: int64(len(name))}, nil
+			}
+		}
+
+		return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+	}
+	ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		if object == "logs/2018-02.gz" {
+			return errors.New("access denied")
+		}
+
+		deleted = append(deleted, object)
+		return nil
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "pattern",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+
+				err := run(context.Background(), ol, "bucket", []string{"logs/2018-*.gz", "readme"}, false, false, nil, out)
+
+				assert.EqualError(t, err, "1 objects failed to be deleted")
+				assert.Equal(t, []string{"logs/2018-"}, listed)
+				assert.Equal(t, []string{"logs/2018-01.gz", "readme"}, deleted)
+				assert.Equal(t, "Object bucket/logs/2018-01.gz deleted\n"+
+					"Object bucket/logs/2018-02.gz failed to be deleted: access denied\n"+
+					"Object bucket/readme deleted\n", out.String())
+			},
+		},
+		{
+			testName: "dry run",
+			testFunc: func(t *testing.T) {
+				deleted = nil
+				out := &bytes.Buffer{}
+
+				err := run(context.Background(), ol, "bucket", []string{"logs/*/2018-0[1-3].gz", "readme", "missing"}, false, true, nil, out)
+				assert.NoError(t, err)
+				assert.Empty(t, deleted)
+				assert.Equal(t, "Object bucket/logs/old/2018-03.gz (19 bytes) would be deleted from prime and alter\n"+
+					"Object bucket/readme (6 bytes) would be deleted from prime and alter\n"+
+					"Object bucket/missing can't be deleted: Object not found: bucket#missing\n"+
+					"2 objects (25 bytes) would be deleted from prime and alter\n", out.String())
+			},
+		},
+		{
+			testName: "recursive",
+			testFunc: func(t *testing.T) {
+				deleted = nil
+				out := &bytes.Buffer{}
+
+				confirm := utils.Prompt(strings.NewReader("y\n"), out)
+
+				assert.NoError(t, run(context.Background(), ol, "bucket", []string{"logs/old/"}, true, false, confirm, out))
+				assert.Equal(t, []string{"logs/old/2018-03.gz"}, deleted)
+				assert.Equal(t, "delete 1 objects of bucket from 2 backends? [y/N] "+
+					"Object bucket/logs/old/2018-03.gz deleted\n", out.String())
+			},
+		},
+		{
+			testName: "not confirmed",
+			testFunc: func(t *testing.T) {
+				deleted = nil
+
+				for _, answer := range []string{"n\n", "\n", ""} {
+					confirm := utils.Prompt(strings.NewReader(answer), &bytes.Buffer{})
+
+					err := run(context.Background(), ol, "bucket", []string{"logs/"}, true, false, confirm, &bytes.Buffer{})
+					assert.Equal(t, utils.ErrNotConfirmed, err)
+				}
+
+				err := run(context.Background(), ol, "bucket", []string{"logs/*.gz"}, false, false, utils.Refuse, &bytes.Buffer{})
+				assert.EqualError(t, err, "delete 2 objects of bucket from 2 backends needs confirmation, confirm it with --yes")
+				assert.Empty(t, deleted)
+			},
+		},
+		{
+			testName: "keys",
+			testFunc: func(t *testing.T) {
+				deleted = nil
+				out := &bytes.Buffer{}
+
+				// keys read from file are confirmed and deleted literally
+				err := remove(context.Background(), ol, "bucket", []string{"readme", "logs/*.gz"}, nil, true, false, utils.Refuse, out)
+				assert.EqualError(t, err, "delete 2 objects of bucket from 2 backends needs confirmation, confirm it with --yes")
+				assert.Empty(t, deleted)
+
+				assert.NoError(t, remove(context.Background(), ol, "bucket", []string{"readme", "logs/*.gz"}, nil, true, false, nil, out))
+				assert.Equal(t, []string{"readme", "logs/*.gz"}, deleted)
+			},
+		},
+		{
+			testName: "no match",
+			testFunc: func(t *testing.T) {
+				err := run(context.Background(), ol, "bucket", []string{"logs/*.zip"}, false, false, nil, &bytes.Buffer{})
+				assert.EqualError(t, err, "no objects of bucket match logs/*.zip")
+			},
+		},
+		{
+			testName: "invalid pattern",
+			testFunc: func(t *testing.T) {
+				err := run(context.Background(), ol, "bucket", []string{"logs/[2018"}, false, false, nil, &bytes.Buffer{})
+				assert.EqualError(t, err, `pattern "logs/[2018" is invalid: syntax error in pattern`)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/delta"
+)
+
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+var Cmd = &cobra.Command{
+	Use:   "diff <bucket>[/prefix]",
+	Short: "Lists objects which differ between prime and alter",
+	Long: "Lists bucket, optionally only objects under prefix, on both backends and reports keys which exist " +
+		"only on prime, only on alter, or whose copies differ in size or ETag. " +
+		"Content of objects isn't compared, see `ditto verify`.",
+	Args: ValidateArgs,
+	RunE: exec,
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	bucket, prefix := SplitPath(args[0])
+
+	return run(context.Background(), prime, alter, bucket, prefix, os.Stdout, utils.JSON)
+}
+
+// run diffs bucket under prefix and prints report to out, as JSON if asJSON is set.
+func run(ctx context.Context, prime, alter minio.ObjectLayer, bucket, prefix string, out io.Writer, asJSON bool) error {
+	report := delta.NewReport(bucket, prefix)
+
+	if err := delta.Diff(ctx, prime, alter, bucket, prefix, report.Add); err != nil {
+		return err
+	}
+
+	if err := PrintReport(out, report, asJSON); err != nil {
+		return err
+	}
+
+	return ReportError(report)
+}
+
+// ReportError returns error making ditto exit with utils.ExitMismatch if report lists differences,
+// or with utils.ExitPartial if some objects failed to be compared. Nil is returned if backends are in sync.
+func ReportError(report *delta.Report) error {
+	switch {
+	case report.Len() > 0:
+		return utils.WithExitCode(utils.ExitMismatch, fmt.Errorf("backends differ: %s", report))
+	case len(report.Failed) > 0:
+		return utils.WithExitCode(utils.ExitPartial, fmt.Errorf("%d objects failed to be compared", len(report.Failed)))
+	}
+
+	return nil
+}
+
+// PrintReport prints report to out, as JSON if asJSON is set.
+func PrintReport(out io.Writer, report *delta.Report, asJSON bool) error {
+	if asJSON {
+		return utils.PrintJSON(out, report)
+	}
+
+	for _, section := range []struct {
+		title   string
+		entries []delta.Entry
+	}{
+		{"Only on prime", report.OnlyPrime},
+		{"Only on alter", report.OnlyAlter},
+		{"Differ", report.Differ},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(out, "%s (%d):\n", section.title, len(section.entries))
+
+		for _, e := range section.entries {
+			if e.Prime != nil && e.Alter != nil {
+				fmt.Fprintf(out, "  %s\t%s\n", e.Key, e.Reason())
+				continue
+			}
+
+			c := e.Prime
+			if c == nil {
+				c = e.Alter
+			}
+
+			fmt.Fprintf(out, "  %s\t%d bytes\n", e.Key, c.Size)
+		}
+	}
+
+	if len(report.Failed) > 0 {
+		fmt.Fprintf(out, "Failed (%d):\n", len(report.Failed))
+
+		for _, f := range report.Failed {
+			fmt.Fprintf(out, "  %s\t%s\n", f.Key, f.Error)
+		}
+	}
+
+	if report.Len() == 0 && len(report.Failed) == 0 {
+		fmt.Fprintln(out, "Backends are in sync")
+		return nil
+	}
+
+	fmt.Fprintln(out, report)
+
+	return nil
+}
+
+// SplitPath splits bucket[/prefix] argument.
+func SplitPath(path string) (bucket, prefix string) {
+	i := strings.Index(path, "/")
+	if i < 0 {
+		return path, ""
+	}
+
+	return path[:i], path[i+1:]
+}
+
+// ValidateArgs checks that single bucket[/prefix] argument is given.
+func ValidateArgs(cmd *cobra.Command, args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("bucket is required")
+	case 1:
+		bucket, _ := SplitPath(args[0])
+
+		return s3utils.CheckValidBucketName(bucket)
+	default:
+		return errors.New("too many arguments")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package diff
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/cmd/utils"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func newListedLayer(objects ...minio.ObjectInfo) minio.ObjectLayer {
+	ol := test.NewProxyObjectLayer()
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		return minio.ListObjectsInfo{Objects: objects}, nil
+	}
+
+	return ol
+}
+
+func TestRun(t *testing.T) {
+	prime := newListedLayer(
+		minio.ObjectInfo{Name: "a", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "b", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "c", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "e", Size: 1, ETag: "1"},
+	)
+
+	alter := newListedLayer(
+		minio.ObjectInfo{Name: "b", Size: 1, ETag: "\"1\""},
+		minio.ObjectInfo{Name: "c", Size: 2, ETag: "1"},
+		minio.ObjectInfo{Name: "d", Size: 3, ETag: "1"},
+		minio.ObjectInfo{Name: "e", Size: 1, ETag: "2"},
+	)
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "text",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+
+				err := run(context.Background(), prime, alter, "bucket", "", out, false)
+				assert.EqualError(t, err, "backends differ: 1 only on prime, 1 only on alter, 2 differ")
+				assert.Equal(t, utils.ExitMismatch, utils.ExitCode(err))
+				assert.Equal(t, "Only on prime (1):\n  a\t1 bytes\n"+
+					"Only on alter (1):\n  d\t3 bytes\n"+
+					"Differ (2):\n  c\tsize 1 != 2\n  e\tetag 1 != 2\n"+
+					"1 only on prime, 1 only on alter, 2 differ\n", out.String())
+			},
+		},
+		{
+			testName: "json",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+
+				err := run(context.Background(), prime, alter, "bucket", "p", out, true)
+				assert.Equal(t, utils.ExitMismatch, utils.ExitCode(err))
+				assert.JSONEq(t, `{
+					"bucket": "bucket",
+					"prefix": "p",
+					"onlyPrime": [{"key": "a", "prime": {"size": 1, "etag": "1"}}],
+					"onlyAlter": [{"key": "d", "alter": {"size": 3, "etag": "1"}}],
+					"differ": [
+						{"key": "c", "prime": {"size": 1, "etag": "1"}, "alter": {"size": 2, "etag": "1"}},
+						{"key": "e", "prime": {"size": 1, "etag": "1"}, "alter": {"size": 1, "etag": "2"}}
+					]
+				}`, out.String())
+			},
+		},
+		{
+			testName: "in sync",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+
+				assert.NoError(t, run(context.Background(), prime, prime, "bucket", "", out, false))
+				assert.Equal(t, "Backends are in sync\n", out.String())
+
+				out.Reset()
+
+				assert.NoError(t, run(context.Background(), prime, prime, "bucket", "", out, true))
+				assert.JSONEq(t, `{"bucket": "bucket", "prefix": "", "onlyPrime": [], "onlyAlter": [], "differ": []}`, out.String())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
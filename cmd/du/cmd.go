@@ -0,0 +1,225 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package du
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/diff"
+	"storj.io/ditto/cmd/utils"
+)
+
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+var fdepth int
+
+var Cmd = &cobra.Command{
+	Use:   "du [bucket[/prefix]]",
+	Short: "Shows amount of objects and bytes of buckets on prime and alter",
+	Long: "Lists bucket, optionally only objects under prefix, or all buckets of both backends, and sums " +
+		"objects and their sizes on prime and alter. With --depth usage is grouped by prefixes of keys " +
+		"up to given amount of / separated levels.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+// Usage is amount of objects and their bytes on single backend.
+type Usage struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+func (u *Usage) add(size int64) {
+	u.Objects++
+	u.Bytes += size
+}
+
+// Entry is usage of bucket or prefix on both backends.
+type Entry struct {
+	Path  string `json:"path"`
+	Prime Usage  `json:"prime"`
+	Alter Usage  `json:"alter"`
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	var buckets []string
+	var prefix string
+
+	if len(args) == 0 {
+		if buckets, err = listBuckets(ctx, prime, alter); err != nil {
+			return err
+		}
+	} else {
+		var bucket string
+		bucket, prefix = diff.SplitPath(args[0])
+		buckets = []string{bucket}
+	}
+
+	entries, err := du(ctx, prime, alter, buckets, prefix, fdepth)
+	if err != nil {
+		return err
+	}
+
+	if utils.JSON {
+		return utils.PrintJSON(os.Stdout, entries)
+	}
+
+	return printEntries(os.Stdout, entries)
+}
+
+// listBuckets returns sorted names of buckets of both backends.
+func listBuckets(ctx context.Context, prime, alter minio.ObjectLayer) ([]string, error) {
+	names := make(map[string]bool)
+
+	for _, ol := range []minio.ObjectLayer{prime, alter} {
+		buckets, err := ol.ListBuckets(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, b := range buckets {
+			names[b.Name] = true
+		}
+	}
+
+	var sorted []string
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	return sorted, nil
+}
+
+// du sums usage of buckets under prefix on both backends, grouped by key prefixes of depth levels.
+// Bucket missing on a backend has no usage there.
+func du(ctx context.Context, prime, alter minio.ObjectLayer, buckets []string, prefix string, depth int) ([]Entry, error) {
+	var entries []Entry
+
+	for _, bucket := range buckets {
+		groups := make(map[string]*Entry)
+
+		for _, backend := range []struct {
+			ol    minio.ObjectLayer
+			usage func(e *Entry) *Usage
+		}{
+			{prime, func(e *Entry) *Usage { return &e.Prime }},
+			{alter, func(e *Entry) *Usage { return &e.Alter }},
+		} {
+			err := utils.ListObjects(ctx, backend.ol, bucket, prefix, func(oi minio.ObjectInfo) error {
+				path := bucket + "/" + group(oi.Name, prefix, depth)
+
+				e, ok := groups[path]
+				if !ok {
+					e = &Entry{Path: path}
+					groups[path] = e
+				}
+
+				backend.usage(e).add(oi.Size)
+
+				return nil
+			})
+
+			if _, ok := err.(minio.BucketNotFound); err != nil && !ok {
+				return nil, err
+			}
+		}
+
+		// bucket without objects is still listed
+		if len(groups) == 0 {
+			groups[bucket+"/"+prefix] = &Entry{Path: bucket + "/" + prefix}
+		}
+
+		var paths []string
+		for path := range groups {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			entries = append(entries, *groups[path])
+		}
+	}
+
+	return entries, nil
+}
+
+// group returns prefix of key which is depth levels below prefix, e.g. a/b/ for key a/b/c/d
+// under prefix a/ at depth 1. Key above depth belongs to its own directory.
+func group(key, prefix string, depth int) string {
+	end := len(prefix)
+
+	for i := 0; i < depth; i++ {
+		j := strings.Index(key[end:], "/")
+		if j < 0 {
+			break
+		}
+
+		end += j + 1
+	}
+
+	return key[:end]
+}
+
+// printEntries prints usage as table, with total of all entries if there are more of them.
+func printEntries(out io.Writer, entries []Entry) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "PATH\tPRIME OBJECTS\tPRIME BYTES\tALTER OBJECTS\tALTER BYTES")
+
+	var total Entry
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", e.Path, e.Prime.Objects, e.Prime.Bytes, e.Alter.Objects, e.Alter.Bytes)
+
+		total.Prime.Objects += e.Prime.Objects
+		total.Prime.Bytes += e.Prime.Bytes
+		total.Alter.Objects += e.Alter.Objects
+		total.Alter.Bytes += e.Alter.Bytes
+	}
+
+	if len(entries) > 1 {
+		fmt.Fprintf(w, "total\t%d\t%d\t%d\t%d\n", total.Prime.Objects, total.Prime.Bytes, total.Alter.Objects, total.Alter.Bytes)
+	}
+
+	return w.Flush()
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	if fdepth < 0 {
+		return fmt.Errorf("depth %d is invalid, expected 0 or more levels", fdepth)
+	}
+
+	switch len(args) {
+	case 0:
+		return nil
+	case 1:
+		bucket, _ := diff.SplitPath(args[0])
+		return s3utils.CheckValidBucketName(bucket)
+	default:
+		return errors.New("too many arguments")
+	}
+}
+
+func init() {
+	Cmd.Flags().IntVar(&fdepth, "depth", 0, "group usage by prefixes of keys up to depth levels below bucket or prefix")
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package du
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestDu(t *testing.T) {
+	layer := func(buckets map[string][]minio.ObjectInfo) minio.ObjectLayer {
+		ol := test.NewProxyObjectLayer()
+		ol.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+			var infos []minio.BucketInfo
+			for name := range buckets {
+				infos = append(infos, minio.BucketInfo{Name: name})
+			}
+
+			return infos, nil
+		}
+		ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+			objects, ok := buckets[bucket]
+			if !ok {
+				return minio.ListObjectsInfo{}, minio.BucketNotFound{Bucket: bucket}
+			}
+
+			var loi minio.ListObjectsInfo
+			for _, oi := range objects {
+				if strings.HasPrefix(oi.Name, prefix) {
+					loi.Objects = append(loi.Objects, oi)
+				}
+			}
+
+			return loi, nil
+		}
+
+		return ol
+	}
+
+	prime := layer(map[string][]minio.ObjectInfo{
+		"photos": {{Name: "2018/01/a", Size: 1}, {Name: "2018/02/b", Size: 2}, {Name: "2018/c", Size: 4}, {Name: "d", Size: 8}},
+		"empty":  {},
+	})
+	alter := layer(map[string][]minio.ObjectInfo{
+		"photos": {{Name: "2018/01/a", Size: 1}, {Name: "d", Size: 8}},
+		"logs":   {{Name: "x", Size: 16}},
+	})
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "buckets",
+			testFunc: func(t *testing.T) {
+				buckets, err := listBuckets(context.Background(), prime, alter)
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"empty", "logs", "photos"}, buckets)
+
+				entries, err := du(context.Background(), prime, alter, buckets, "", 0)
+				assert.NoError(t, err)
+				assert.Equal(t, []Entry{
+					{Path: "empty/"},
+					{Path: "logs/", Alter: Usage{Objects: 1, Bytes: 16}},
+					{Path: "photos/", Prime: Usage{Objects: 4, Bytes: 15}, Alter: Usage{Objects: 2, Bytes: 9}},
+				}, entries)
+
+				out := &bytes.Buffer{}
+				assert.NoError(t, printEntries(out, entries))
+				assert.Equal(t, "PATH     PRIME OBJECTS  PRIME BYTES  ALTER OBJECTS  ALTER BYTES\n"+
+					"empty/   0              0            0              0\n"+
+					"logs/    0              0            1              16\n"+
+					"photos/  4              15           2              9\n"+
+					"total    4              15           3              25\n", out.String())
+			},
+		},
+		{
+			testName: "depth",
+			testFunc: func(t *testing.T) {
+				entries, err := du(context.Background(), prime, alter, []string{"photos"}, "2018/", 1)
+				assert.NoError(t, err)
+				assert.Equal(t, []Entry{
+					{Path: "photos/2018/", Prime: Usage{Objects: 1, Bytes: 4}},
+					{Path: "photos/2018/01/", Prime: Usage{Objects: 1, Bytes: 1}, Alter: Usage{Objects: 1, Bytes: 1}},
+					{Path: "photos/2018/02/", Prime: Usage{Objects: 1, Bytes: 2}},
+				}, entries)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	assert.Equal(t, "", group("a/b/c", "", 0))
+	assert.Equal(t, "a/", group("a/b/c", "", 1))
+	assert.Equal(t, "a/b/", group("a/b/c", "", 5))
+	assert.Equal(t, "a/b/", group("a/b/c", "a/", 1))
+	assert.Equal(t, "", group("c", "", 2))
+}
@@ -2,41 +2,205 @@ package get
 
 import (
 	"context"
-	"github.com/minio/minio/pkg/auth"
+	"errors"
+	"fmt"
 	"github.com/spf13/cobra"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"storj.io/ditto/cmd/utils"
 
-	l "storj.io/ditto/pkg/logger"
 	minio "github.com/minio/minio/cmd"
+	dcontext "storj.io/ditto/pkg/context"
 )
 
-func newGetExec(gw minio.Gateway, lg l.Logger) *getExec {
-	return &getExec{gw, lg}
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+func exec(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return errors.New("object name or pattern is required")
+	}
+
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	// Releases connections held by backends
+	defer prime.Shutdown(ctx)
+	defer alter.Shutdown(ctx)
+
+	src := &source{prime: prime, alter: alter}
+	if backendFlag != "" {
+		src.backend, _ = dcontext.ParseBackend(backendFlag)
+	}
+
+	if utils.IsPattern(args[1]) {
+		return getPattern(ctx, src, args[0], args[1], ".", os.Stdout)
+	}
+
+	file := args[1]
+	if nameFlag != "" {
+		file = nameFlag
+	}
+
+	return getObject(ctx, src, args[0], args[1], file, os.Stdout)
+}
+
+// source reads objects the way gateway does, from prime and from alter if prime fails,
+// or only from backend selected with --backend.
+type source struct {
+	prime, alter minio.ObjectLayer
+
+	// backend forces reads from single backend, empty reads prime first.
+	backend dcontext.Backend
 }
 
-type getExec struct {
-	minio.Gateway
-	l.Logger
+// read calls fn with backends in order they are read from until it succeeds. Backend which served fn
+// is returned with error of prime if alter served it, error of the last backend is returned if all failed.
+// Errors of local files aren't failures of backend, they are returned without trying the other one.
+func (s *source) read(fn func(ol minio.ObjectLayer) error) (served dcontext.Backend, primeErr error, err error) {
+	order := []dcontext.Backend{dcontext.PRIME, dcontext.ALTER}
+	if s.backend != "" {
+		order = []dcontext.Backend{s.backend}
+	}
+
+	for _, b := range order {
+		ol := s.prime
+		if b == dcontext.ALTER {
+			ol = s.alter
+		}
+
+		if err = fn(ol); err == nil {
+			return b, primeErr, nil
+		}
+
+		if _, ok := err.(*os.PathError); ok {
+			return "", primeErr, err
+		}
+
+		if b == dcontext.PRIME {
+			primeErr = err
+		}
+	}
+
+	return "", primeErr, err
 }
 
-func (e *getExec) runE(cmd *cobra.Command, args []string) (err error) {
-	mirr, err := e.NewGatewayLayer(auth.Credentials{})
+// getObject downloads object of bucket to file, which is overwritten, and reports backend which served it.
+func getObject(ctx context.Context, src *source, bucket, object, file string, out io.Writer) error {
+	served, primeErr, err := src.read(func(ol minio.ObjectLayer) error {
+		oi, err := ol.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+		if err != nil {
+			return err
+		}
+
+		return download(ctx, ol, bucket, oi, file, true)
+	})
 	if err != nil {
-		return
+		return err
 	}
 
-	ctx := context.Background()
+	fmt.Fprintf(out, "Object %s/%s downloaded to %s %s\n", bucket, object, file, servedBy(served, primeErr))
+
+	return nil
+}
+
+// getPattern downloads every object of bucket matching pattern to dir, path of file is name of object
+// relative to directory of pattern. Existing files aren't overwritten. Backend which served every object
+// is reported, pattern is expanded by listing of backend read first.
+func getPattern(ctx context.Context, src *source, bucket, pattern, dir string, out io.Writer) error {
+	var (
+		matched []minio.ObjectInfo
+		lister  minio.ObjectLayer
+	)
+
+	_, _, err := src.read(func(ol minio.ObjectLayer) error {
+		var err error
+		matched, err = utils.ExpandPattern(ctx, ol, bucket, pattern)
+		lister = ol
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(matched) == 0 {
+		return fmt.Errorf("no objects of %s match %s", bucket, pattern)
+	}
+
+	failed := 0
+
+	for _, oi := range matched {
+		file := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(oi.Name, utils.PatternDir(pattern))))
+
+		served, primeErr, err := src.read(func(ol minio.ObjectLayer) error {
+			// listing describes copy of backend listed, copy of the other one may differ
+			info := oi
+			if ol != lister {
+				var err error
+				if info, err = ol.GetObjectInfo(ctx, bucket, oi.Name, minio.ObjectOptions{}); err != nil {
+					return err
+				}
+			}
+
+			return download(ctx, ol, bucket, info, file, false)
+		})
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "Object %s/%s failed to be downloaded: %s\n", bucket, oi.Name, err)
+			utils.ReportFailure("download", bucket, oi.Name, "", err)
+			continue
+		}
+
+		fmt.Fprintf(out, "Object %s/%s downloaded to %s %s\n", bucket, oi.Name, file, servedBy(served, primeErr))
+	}
+
+	if failed > 0 {
+		return utils.WithExitCode(utils.ExitPartial, fmt.Errorf("%d objects failed to be downloaded", failed))
+	}
+
+	return nil
+}
+
+// servedBy describes backend which served object, with reason of falling back to alter.
+func servedBy(served dcontext.Backend, primeErr error) string {
+	if served == dcontext.ALTER && primeErr != nil {
+		return fmt.Sprintf("from alter, prime failed: %s", primeErr)
+	}
+
+	return fmt.Sprintf("from %s", served)
+}
 
-	file, err := os.Create(args[1])
+// download writes content of object to file, which is removed if download fails. Existing file
+// is replaced only if overwrite is set.
+func download(ctx context.Context, ol minio.ObjectLayer, bucket string, oi minio.ObjectInfo, file string, overwrite bool) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(file, flags, 0644)
 	if err != nil {
-		return
+		return err
+	}
+
+	err = ol.GetObject(ctx, bucket, oi.Name, 0, oi.Size, f, oi.ETag, minio.ObjectOptions{})
+	if cerr := f.Close(); err == nil {
+		err = cerr
 	}
 
-	_, err = file.Stat()
 	if err != nil {
-		return
+		os.Remove(file)
 	}
 
-	err = mirr.GetObject(ctx, args[0], args[1], 0, int64(3000000), file, "", minio.ObjectOptions{})
 	return err
-}
\ No newline at end of file
+}
@@ -0,0 +1,172 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package get
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// backend returns object layer holding objects in memory, every read fails with err if it's set.
+func backend(objects map[string]string, err error) minio.ObjectLayer {
+	ol := test.NewProxyObjectLayer()
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		if err != nil {
+			return minio.ObjectInfo{}, err
+		}
+
+		content, ok := objects[object]
+		if !ok {
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content))}, nil
+	}
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		if err != nil {
+			return err
+		}
+
+		_, werr := io.WriteString(writer, objects[object])
+		return werr
+	}
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		if err != nil {
+			return minio.ListObjectsInfo{}, err
+		}
+
+		var loi minio.ListObjectsInfo
+		for _, name := range []string{"logs/a.gz", "logs/b.gz", "readme"} {
+			if content, ok := objects[name]; ok {
+				loi.Objects = append(loi.Objects, minio.ObjectInfo{Bucket: bucket, Name: name, Size: int64(len(content))})
+			}
+		}
+
+		return loi, nil
+	}
+
+	return ol
+}
+
+// failingLayer fails reads of object.
+type failingLayer struct {
+	minio.ObjectLayer
+	object string
+}
+
+func (f failingLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	if object == f.object {
+		return errors.New("read failed")
+	}
+
+	return f.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+}
+
+func TestGetObject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "get")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "readme")
+
+	cases := []struct {
+		testName string
+		src      *source
+		content  string
+		output   string
+		err      string
+	}{
+		{
+			"Prime serves object",
+			&source{prime: backend(map[string]string{"readme": "prime"}, nil), alter: backend(map[string]string{"readme": "alter"}, nil)},
+			"prime",
+			"Object bucket/readme downloaded to " + file + " from prime\n",
+			"",
+		},
+		{
+			"Alter serves object prime fails to",
+			&source{prime: backend(nil, errors.New("prime is down")), alter: backend(map[string]string{"readme": "alter"}, nil)},
+			"alter",
+			"Object bucket/readme downloaded to " + file + " from alter, prime failed: prime is down\n",
+			"",
+		},
+		{
+			"Selected backend serves object",
+			&source{prime: backend(map[string]string{"readme": "prime"}, nil), alter: backend(map[string]string{"readme": "alter"}, nil), backend: dcontext.ALTER},
+			"alter",
+			"Object bucket/readme downloaded to " + file + " from alter\n",
+			"",
+		},
+		{
+			"Selected backend doesn't fall back",
+			&source{prime: backend(nil, errors.New("prime is down")), alter: backend(map[string]string{"readme": "alter"}, nil), backend: dcontext.PRIME},
+			"",
+			"",
+			"prime is down",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			os.Remove(file)
+			out := &bytes.Buffer{}
+
+			err := getObject(context.Background(), c.src, "bucket", "readme", file, out)
+
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				_, serr := os.Stat(file)
+				assert.True(t, os.IsNotExist(serr))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.output, out.String())
+
+			content, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, c.content, string(content))
+		})
+	}
+}
+
+func TestGetPattern(t *testing.T) {
+	dir, err := ioutil.TempDir("", "get")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// b.gz fails to be read from prime, it's read from alter, which holds different copy
+	src := &source{
+		prime: failingLayer{ObjectLayer: backend(map[string]string{"logs/a.gz": "a", "logs/b.gz": "b", "readme": "r"}, nil), object: "logs/b.gz"},
+		alter: backend(map[string]string{"logs/a.gz": "a", "logs/b.gz": "bb"}, nil),
+	}
+
+	out := &bytes.Buffer{}
+
+	assert.NoError(t, getPattern(context.Background(), src, "bucket", "logs/*.gz", dir, out))
+	assert.Equal(t, "Object bucket/logs/a.gz downloaded to "+filepath.Join(dir, "a.gz")+" from prime\n"+
+		"Object bucket/logs/b.gz downloaded to "+filepath.Join(dir, "b.gz")+" from alter, prime failed: read failed\n", out.String())
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "b.gz"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bb", string(content))
+
+	// existing files aren't overwritten nor read from alter
+	out.Reset()
+
+	err = getPattern(context.Background(), src, "bucket", "logs/*.gz", dir, out)
+	assert.EqualError(t, err, "2 objects failed to be downloaded")
+}
@@ -4,9 +4,9 @@
 package get
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"github.com/minio/minio/pkg/auth"
 	"github.com/spf13/cobra"
 	"storj.io/ditto/cmd/get/downloader"
 	"storj.io/ditto/global"
@@ -19,8 +19,11 @@ var Cmd = &cobra.Command{
 	Use:   "get [bucket name] [object name](opt) [OPTIONS]",
 	Args: validateArgs,
 	Short: "Download files and buckets",
-	Long: ``,
-	RunE: newGetExec(&gw.Mirroring{nil, &l.StdOutLogger}, &l.StdOutLogger).runE,
+	Long: "Downloads object to file named as object, or --name, object name can be pattern, e.g. logs/2024-*.gz, " +
+		"then every matching object is downloaded. Objects are read as gateway reads them, from prime and from " +
+		"alter if prime fails, backend which served every object is reported. --backend reads only from prime " +
+		"or alter.",
+	RunE: exec,
 }
 
 func runE(cmd *cobra.Command, args []string) error {
@@ -34,11 +37,14 @@ func runE(cmd *cobra.Command, args []string) error {
 
 	mirrGateway := gw.Mirroring{Logger: &l.StdOutLogger}
 
-	var mirr, err =  mirrGateway.NewGatewayLayer(auth.Credentials{})
+	var mirr, err = mirrGateway.NewObjectLayer()
 	if err != nil {
 		return errors.New("unable to start mirroring service")
 	}
 
+	// Waits for background mirroring to finish
+	defer mirr.Shutdown(context.Background())
+
 	params := downloader.NewDefaultParams()
 	params.SetPath(nameFlag)
 	params.SetPrefix(prefixFlag)
@@ -82,10 +88,14 @@ var (
 
 	recursiveFlag bool
 	recursiveUsage = ""
+
+	backendFlag string
+	backendUsage = "backend to read from, prime or alter, prime falling back to alter by default"
 )
 
 func init() {
 	Cmd.Flags().StringVarP(&nameFlag, "name", "n", "", nameUsage)
 	Cmd.Flags().StringVarP(&prefixFlag, "prefix", "p", "", prefixUsage)
 	Cmd.Flags().BoolVarP(&recursiveFlag, "recursive", "r", false, recursiveUsage)
+	Cmd.Flags().StringVar(&backendFlag, "backend", "", backendUsage)
 }
\ No newline at end of file
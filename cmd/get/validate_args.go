@@ -1,8 +1,17 @@
 package get
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+
+	dcontext "storj.io/ditto/pkg/context"
+)
 
 func validateArgs(cmd *cobra.Command, args []string) error {
+	if _, ok := dcontext.ParseBackend(backendFlag); backendFlag != "" && !ok {
+		return fmt.Errorf("backend %q is unknown, expected prime or alter", backendFlag)
+	}
+
 	argsLen := len(args)
 	if argsLen < minArg || argsLen > maxArg {
 		return NewArgsError(args)
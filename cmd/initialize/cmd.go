@@ -0,0 +1,293 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package initialize
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+	cmdconfig "storj.io/ditto/cmd/config"
+	"storj.io/ditto/pkg/config"
+)
+
+var (
+	fforce      bool
+	fskipVerify bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "init",
+	Short: "Creates config file interactively",
+	Long: "Prompts for endpoints and credentials of Prime (Server1) and Alter (Server2) and for basic policies, " +
+		"verifies that both backends are reachable and writes config file. Existing config file is overwritten " +
+		"only with --force.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exec(newPrompter(os.Stdin, os.Stdout), config.FindFile())
+	},
+}
+
+// Method reference for unit testing
+var probeMethod = cmdconfig.ProbeBackends
+
+// option is key of config file set to value answered by user.
+type option struct {
+	key   string
+	value string
+}
+
+func exec(p *prompter, file string) error {
+	if content, err := ioutil.ReadFile(file); err == nil && len(strings.TrimSpace(string(content))) > 0 && !fforce {
+		return fmt.Errorf("config file %s already exists, use --force to overwrite it", file)
+	}
+
+	cfg, options, err := ask(p)
+	if err != nil {
+		return err
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return err
+	}
+
+	if !fskipVerify {
+		fmt.Fprintln(p.out, "Verifying connectivity of backends...")
+
+		if problems := probeMethod(cfg); len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Fprintf(p.out, "  - %s\n", problem)
+			}
+
+			write, err := p.confirm("Write config file anyway?", false)
+			if err != nil {
+				return err
+			}
+
+			if !write {
+				return errors.New("config file is not written")
+			}
+		}
+	}
+
+	if err = writeConfig(file, options); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.out, "Config written to %s\n", file)
+	fmt.Fprintln(p.out, "Credentials are stored in plain text, run `ditto config encrypt` to encrypt them.")
+
+	return nil
+}
+
+// ask prompts for options of config, returns config they make up and options in order they are written.
+func ask(p *prompter) (*config.Config, []option, error) {
+	cfg := &config.Config{
+		DefaultOptions: &config.DefaultOptions{},
+		Read:           &config.ReadOptions{},
+	}
+
+	var options []option
+
+	for _, s := range []struct {
+		name, title string
+		creds       **config.Credentials
+	}{{"Server1", "Prime", &cfg.Server1}, {"Server2", "Alter", &cfg.Server2}} {
+		creds := &config.Credentials{}
+
+		var err error
+		if creds.Endpoint, err = p.ask(fmt.Sprintf("%s (%s) endpoint, host:port or URL", s.title, s.name), ""); err != nil {
+			return nil, nil, err
+		}
+
+		if creds.AccessKey, err = p.ask(fmt.Sprintf("%s (%s) access key", s.title, s.name), ""); err != nil {
+			return nil, nil, err
+		}
+
+		if creds.SecretKey, err = p.secret(fmt.Sprintf("%s (%s) secret key", s.title, s.name)); err != nil {
+			return nil, nil, err
+		}
+
+		*s.creds = creds
+		options = append(options,
+			option{s.name + ".Endpoint", creds.Endpoint},
+			option{s.name + ".AccessKey", creds.AccessKey},
+			option{s.name + ".SecretKey", creds.SecretKey})
+	}
+
+	source, err := p.choose("Backend to read from and list by default", "server1", "server1", "server2")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	throw, err := p.confirm("Fail requests as soon as any backend fails?", true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mode, err := p.choose("Read mode, auto fails over to Alter while Prime is down, hedged reads from faster backend",
+		"prime", "prime", "auto", "hedged")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg.DefaultOptions.DefaultSource = source
+	cfg.DefaultOptions.ThrowImmediately = throw
+	cfg.Read.Mode = mode
+
+	options = append(options,
+		option{config.DEFAULT_OPTIONS_DEFAULT_SOURCE, source},
+		option{config.DEFAULT_OPTIONS_THROW_IMMEDIATELY, strconv.FormatBool(throw)},
+		option{config.READ_MODE, mode})
+
+	return cfg, options, nil
+}
+
+// writeConfig writes options to file in format of its extension. File is readable only by its owner,
+// since it holds credentials.
+func writeConfig(file string, options []option) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+
+	content := ""
+	if filepath.Ext(file) == ".json" {
+		content = "{}\n"
+	}
+
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		return err
+	}
+
+	// file written with --force keeps its permissions
+	if err := os.Chmod(file, 0600); err != nil {
+		return err
+	}
+
+	for _, o := range options {
+		if err := config.SetValue(file, o.key, o.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prompter asks user questions, secrets aren't echoed when input is terminal.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// readSecret reads line without echo, nil if input isn't terminal.
+	readSecret func() ([]byte, error)
+}
+
+func newPrompter(in *os.File, out io.Writer) *prompter {
+	p := &prompter{in: bufio.NewReader(in), out: out}
+
+	if fd := int(in.Fd()); terminal.IsTerminal(fd) {
+		p.readSecret = func() ([]byte, error) {
+			defer fmt.Fprintln(out)
+			return terminal.ReadPassword(fd)
+		}
+	}
+
+	return p
+}
+
+func (p *prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+
+	return strings.TrimSpace(line), err
+}
+
+// ask prompts until answer is given, empty answer means def unless it's empty too.
+func (p *prompter) ask(question, def string) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+
+		if answer == "" {
+			answer = def
+		}
+
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
+
+// secret prompts for value which isn't echoed.
+func (p *prompter) secret(question string) (string, error) {
+	if p.readSecret == nil {
+		return p.ask(question, "")
+	}
+
+	for {
+		fmt.Fprintf(p.out, "%s: ", question)
+
+		answer, err := p.readSecret()
+		if err != nil {
+			return "", err
+		}
+
+		if s := strings.TrimSpace(string(answer)); s != "" {
+			return s, nil
+		}
+	}
+}
+
+// choose prompts until one of choices is answered.
+func (p *prompter) choose(question, def string, choices ...string) (string, error) {
+	for {
+		answer, err := p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(choices, "/")), def)
+		if err != nil {
+			return "", err
+		}
+
+		for _, c := range choices {
+			if strings.EqualFold(answer, c) {
+				return c, nil
+			}
+		}
+
+		fmt.Fprintf(p.out, "Answer one of %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// confirm prompts for yes or no answer.
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	defAnswer := "n"
+	if def {
+		defAnswer = "y"
+	}
+
+	answer, err := p.choose(question, defAnswer, "y", "n")
+
+	return answer == "y", err
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&fforce, "force", false, "overwrite existing config file")
+	Cmd.Flags().BoolVar(&fskipVerify, "skip-verify", false, "don't check that backends are reachable")
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package initialize
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	cmdconfig "storj.io/ditto/cmd/config"
+	"storj.io/ditto/pkg/config"
+)
+
+func TestExec(t *testing.T) {
+	defer func() {
+		probeMethod = cmdconfig.ProbeBackends
+		fforce = false
+	}()
+
+	dir, err := ioutil.TempDir("", "ditto-init")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	answers := strings.Join([]string{
+		"prime:9000", "a1", "s1",
+		"", "alter:9000", "a2", "s2",
+		"server3", "server2",
+		"n",
+		"",
+	}, "\n") + "\n"
+
+	newPrompter := func(input string) (*prompter, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &prompter{in: bufio.NewReader(strings.NewReader(input)), out: out}, out
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "written",
+			testFunc: func(t *testing.T) {
+				var probed *config.Config
+				probeMethod = func(cfg *config.Config) []string {
+					probed = cfg
+					return nil
+				}
+
+				file := filepath.Join(dir, "ditto", "config.yaml")
+				p, out := newPrompter(answers)
+
+				assert.NoError(t, exec(p, file))
+				assert.Equal(t, "alter:9000", probed.Server2.Endpoint)
+				assert.Contains(t, out.String(), "Answer one of server1, server2")
+				assert.Contains(t, out.String(), "Config written to "+file)
+
+				content, err := ioutil.ReadFile(file)
+				assert.NoError(t, err)
+				assert.Equal(t, "Server1:\n  Endpoint: prime:9000\n  AccessKey: a1\n  SecretKey: s1\n"+
+					"Server2:\n  Endpoint: alter:9000\n  AccessKey: a2\n  SecretKey: s2\n"+
+					"DefaultOptions:\n  DefaultSource: server2\n  ThrowImmediately: false\n"+
+					"Read:\n  Mode: prime\n", string(content))
+
+				info, err := os.Stat(file)
+				assert.NoError(t, err)
+				assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+			},
+		},
+		{
+			testName: "existing file is kept",
+			testFunc: func(t *testing.T) {
+				file := filepath.Join(dir, "existing.json")
+				assert.NoError(t, ioutil.WriteFile(file, []byte("{\"Log\": {}}"), 0600))
+
+				p, _ := newPrompter(answers)
+				assert.EqualError(t, exec(p, file), "config file "+file+" already exists, use --force to overwrite it")
+
+				fforce = true
+				defer func() { fforce = false }()
+
+				p, _ = newPrompter(answers)
+				assert.NoError(t, exec(p, file))
+
+				content, err := ioutil.ReadFile(file)
+				assert.NoError(t, err)
+				assert.Contains(t, string(content), "\"Endpoint\": \"prime:9000\"")
+			},
+		},
+		{
+			testName: "unreachable backends",
+			testFunc: func(t *testing.T) {
+				probeMethod = func(cfg *config.Config) []string {
+					return []string{"Server2 at alter:9000 is unreachable with configured credentials: Access Denied"}
+				}
+
+				file := filepath.Join(dir, "unreachable.json")
+				p, out := newPrompter(answers + "n\n")
+
+				assert.EqualError(t, exec(p, file), "config file is not written")
+				assert.Contains(t, out.String(), "  - Server2 at alter:9000 is unreachable")
+
+				_, err := os.Stat(file)
+				assert.True(t, os.IsNotExist(err))
+
+				p, _ = newPrompter(answers + "y\n")
+				assert.NoError(t, exec(p, file))
+			},
+		},
+		{
+			testName: "invalid endpoint",
+			testFunc: func(t *testing.T) {
+				p, _ := newPrompter(strings.Replace(answers, "prime:9000", "ftp://prime", 1))
+
+				err := exec(p, filepath.Join(dir, "invalid.json"))
+				assert.IsType(t, &config.ValidationError{}, err)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -7,24 +7,50 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
+	"strconv"
+	"time"
 
 	minio "github.com/minio/minio/cmd"
 	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/diff"
 	"storj.io/ditto/cmd/utils"
 	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/delta"
 )
 
 // Function listed as var for testing purposes only
 var mirroring = utils.GetObjectLayer
+var backends = utils.GetBackends
+
+var fpresence bool
+
+// Bucket is bucket in JSON output, Prime and Alter are set with --presence only.
+type Bucket struct {
+	Name  string `json:"name"`
+	Prime bool   `json:"prime,omitempty"`
+	Alter bool   `json:"alter,omitempty"`
+}
+
+// Object is object in JSON output, with --presence objects are output as delta.Entry.
+type Object struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+}
 
 var configuration *config.Config
 var Cmd = &cobra.Command{
 	Use:   "list",
 	Short: "Displays bucket list_cmd and all files at specified bucket",
-	Long:  `Displays bucket list_cmd and all files at specified bucket`,
-	Args:  validateArgs,
-	RunE:  exec,
+	Long: "Displays bucket list_cmd and all files at specified bucket. With --presence both backends are listed " +
+		"and every bucket or object is marked with backends holding it, [P] for prime, [A] for alter and [PA] for both, " +
+		"objects are listed with their sizes. Bucket may be followed by /prefix.",
+	Args: validateArgs,
+	RunE: exec,
 }
 
 func validateArgs(cmd *cobra.Command, args []string) error {
@@ -35,11 +61,29 @@ func validateArgs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 func exec(cmd *cobra.Command, args []string) error {
+	if fpresence {
+		prime, alter, err := backends()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			return listBucketsPresence(context.Background(), prime, alter, os.Stdout, utils.JSON)
+		}
+
+		bucket, prefix := diff.SplitPath(args[0])
+
+		return listObjectsPresence(context.Background(), prime, alter, bucket, prefix, os.Stdout, utils.JSON)
+	}
+
 	objLayer, err := mirroring()
 	if err != nil {
 		return err
 	}
 
+	// Releases files and connections held by layer
+	defer objLayer.Shutdown(context.Background())
+
 	switch len(args) {
 	case 0:
 		return listBuckets(objLayer)
@@ -51,8 +95,6 @@ func exec(cmd *cobra.Command, args []string) error {
 }
 
 func listBuckets(layer minio.ObjectLayer) error {
-	fmt.Println("list_cmd buckets:")
-
 	buckets, err := layer.ListBuckets(context.Background())
 
 	if err != nil {
@@ -60,6 +102,17 @@ func listBuckets(layer minio.ObjectLayer) error {
 		return errors.New(fmt.Sprintf("Error while creating Listing Buckets Layer: %s\n", err.Error()))
 	}
 
+	if utils.JSON {
+		output := []Bucket{}
+		for _, bucket := range buckets {
+			output = append(output, Bucket{Name: bucket.Name})
+		}
+
+		return utils.PrintJSON(os.Stdout, output)
+	}
+
+	fmt.Println("list_cmd buckets:")
+
 	if buckets != nil {
 		for _, bucket := range buckets {
 			fmt.Println(bucket.Name)
@@ -70,8 +123,6 @@ func listBuckets(layer minio.ObjectLayer) error {
 }
 
 func listObjects(layer minio.ObjectLayer, bucketName string) error {
-	fmt.Printf("list_cmd files at %s\n", bucketName)
-
 	u, err := url.Parse(bucketName)
 	if err != nil {
 
@@ -86,6 +137,17 @@ func listObjects(layer minio.ObjectLayer, bucketName string) error {
 		return errors.New(fmt.Sprintf("Error while creating List Objects Layer for bucket %s,\nError: %s\n", bucketName, err.Error()))
 	}
 
+	if utils.JSON {
+		output := []Object{}
+		for _, object := range result.Objects {
+			output = append(output, Object{Key: object.Name, Size: object.Size, ETag: object.ETag, LastModified: object.ModTime})
+		}
+
+		return utils.PrintJSON(os.Stdout, output)
+	}
+
+	fmt.Printf("list_cmd files at %s\n", bucketName)
+
 	for _, object := range result.Objects {
 		fmt.Println(object.Name)
 	}
@@ -96,7 +158,107 @@ func listObjects(layer minio.ObjectLayer, bucketName string) error {
 	return nil
 }
 
+// marker returns presence marker of item existing on prime, alter or both.
+func marker(onPrime, onAlter bool) string {
+	switch {
+	case onPrime && onAlter:
+		return "[PA]"
+	case onPrime:
+		return "[P]"
+	default:
+		return "[A]"
+	}
+}
+
+// listBucketsPresence prints buckets of both backends marked with backends holding them, as JSON if asJSON is set.
+func listBucketsPresence(ctx context.Context, prime, alter minio.ObjectLayer, out io.Writer, asJSON bool) error {
+	pbuckets, err := prime.ListBuckets(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list buckets of prime: %s", err)
+	}
+
+	abuckets, err := alter.ListBuckets(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list buckets of alter: %s", err)
+	}
+
+	onAlter := make(map[string]bool)
+	for _, b := range abuckets {
+		onAlter[b.Name] = true
+	}
+
+	output := []Bucket{}
+
+	for _, b := range pbuckets {
+		output = append(output, Bucket{Name: b.Name, Prime: true, Alter: onAlter[b.Name]})
+		delete(onAlter, b.Name)
+	}
+
+	for _, b := range abuckets {
+		if onAlter[b.Name] {
+			output = append(output, Bucket{Name: b.Name, Alter: true})
+		}
+	}
+
+	if asJSON {
+		return utils.PrintJSON(out, output)
+	}
+
+	for _, b := range output {
+		fmt.Fprintf(out, "%-4s %s\n", marker(b.Prime, b.Alter), b.Name)
+	}
+
+	return nil
+}
+
+// listObjectsPresence prints objects of bucket under prefix on both backends marked with backends holding them.
+// Size is printed as prime/alter if sizes of copies differ. With asJSON objects are printed as JSON list of delta.Entry.
+func listObjectsPresence(ctx context.Context, prime, alter minio.ObjectLayer, bucket, prefix string, out io.Writer, asJSON bool) error {
+	if asJSON {
+		output := []delta.Entry{}
+
+		err := delta.Walk(ctx, prime, alter, bucket, prefix, func(key string, poi, aoi *minio.ObjectInfo) error {
+			e := delta.Entry{Key: key}
+
+			if poi != nil {
+				e.Prime = &delta.Copy{Size: poi.Size, ETag: poi.ETag}
+			}
+
+			if aoi != nil {
+				e.Alter = &delta.Copy{Size: aoi.Size, ETag: aoi.ETag}
+			}
+
+			output = append(output, e)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return utils.PrintJSON(out, output)
+	}
+
+	return delta.Walk(ctx, prime, alter, bucket, prefix, func(key string, poi, aoi *minio.ObjectInfo) error {
+		var size string
+
+		switch {
+		case poi != nil && aoi != nil && poi.Size != aoi.Size:
+			size = fmt.Sprintf("%d/%d", poi.Size, aoi.Size)
+		case poi != nil:
+			size = strconv.FormatInt(poi.Size, 10)
+		default:
+			size = strconv.FormatInt(aoi.Size, 10)
+		}
+
+		_, err := fmt.Fprintf(out, "%-4s %12s %s\n", marker(poi != nil, aoi != nil), size, key)
+
+		return err
+	})
+}
+
 func init() {
+	Cmd.Flags().BoolVar(&fpresence, "presence", false, "list both backends and mark items with backends holding them")
 	//viper.Set("ListOptions.DefaultOptions.DefaultSource", "test_source")
 	//viper.Set("ListOptions.DefaultOptions.ThrowImmediately", true)
 	//viper.Set("ListOptions.Merge", false)
@@ -0,0 +1,80 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package list
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func newLayer(buckets []string, objects ...minio.ObjectInfo) minio.ObjectLayer {
+	ol := test.NewProxyObjectLayer()
+
+	ol.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		var infos []minio.BucketInfo
+		for _, b := range buckets {
+			infos = append(infos, minio.BucketInfo{Name: b})
+		}
+
+		return infos, nil
+	}
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		return minio.ListObjectsInfo{Objects: objects}, nil
+	}
+
+	return ol
+}
+
+func TestListPresence(t *testing.T) {
+	prime := newLayer([]string{"a", "b"},
+		minio.ObjectInfo{Name: "both", Size: 10},
+		minio.ObjectInfo{Name: "differ", Size: 1},
+		minio.ObjectInfo{Name: "prime", Size: 2},
+	)
+
+	alter := newLayer([]string{"b", "c"},
+		minio.ObjectInfo{Name: "alter", Size: 3},
+		minio.ObjectInfo{Name: "both", Size: 10},
+		minio.ObjectInfo{Name: "differ", Size: 100},
+	)
+
+	out := &bytes.Buffer{}
+
+	assert.NoError(t, listBucketsPresence(context.Background(), prime, alter, out, false))
+	assert.Equal(t, "[P]  a\n[PA] b\n[A]  c\n", out.String())
+
+	out.Reset()
+
+	assert.NoError(t, listObjectsPresence(context.Background(), prime, alter, "bucket", "", out, false))
+	assert.Equal(t, ""+
+		"[A]             3 alter\n"+
+		"[PA]           10 both\n"+
+		"[PA]        1/100 differ\n"+
+		"[P]             2 prime\n", out.String())
+
+	out.Reset()
+
+	assert.NoError(t, listBucketsPresence(context.Background(), prime, alter, out, true))
+	assert.JSONEq(t, `[
+		{"name": "a", "prime": true},
+		{"name": "b", "prime": true, "alter": true},
+		{"name": "c", "alter": true}
+	]`, out.String())
+
+	out.Reset()
+
+	assert.NoError(t, listObjectsPresence(context.Background(), prime, alter, "bucket", "", out, true))
+	assert.JSONEq(t, `[
+		{"key": "alter", "alter": {"size": 3, "etag": ""}},
+		{"key": "both", "prime": {"size": 10, "etag": ""}, "alter": {"size": 10, "etag": ""}},
+		{"key": "differ", "prime": {"size": 1, "etag": ""}, "alter": {"size": 100, "etag": ""}},
+		{"key": "prime", "prime": {"size": 2, "etag": ""}}
+	]`, out.String())
+}
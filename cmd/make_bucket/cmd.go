@@ -14,24 +14,30 @@ import (
 
 var mirroring = utils.GetObjectLayer
 
+var flocation string
+
 var Cmd = &cobra.Command {
 	Use: "make-bucket [mb].",
+	Aliases: []string{"mb"},
 
 	Args: validateArgs,
 	Short: "Creates bucket with desired name.",
-	Long:  "Creates bucket with desired name.",
+	Long:  "Creates bucket with desired name on prime and alter, in region given by --location.",
 	RunE: exec,
 }
 
 func exec(cmd *cobra.Command, args []string) error {
 
-	//TODO: add location flag in init func
 	objLayer, err := mirroring()
 	if err != nil {
 		return err
 	}
 
-	err = objLayer.MakeBucketWithLocation(context.Background(), args[0], "")
+	ctx := context.Background()
+	// Waits for background mirroring of bucket creation to finish
+	defer objLayer.Shutdown(ctx)
+
+	err = objLayer.MakeBucketWithLocation(ctx, args[0], flocation)
 
 	if err != nil {
 		return err
@@ -63,5 +69,5 @@ func validateArgs(cmd *cobra.Command, args []string) error {
 }
 
 func init() {
-
+	Cmd.Flags().StringVar(&flocation, "location", "", "region to create bucket in, default region of backend if empty")
 }
\ No newline at end of file
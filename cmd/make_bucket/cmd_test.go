@@ -125,6 +125,31 @@ func TestExec(t *testing.T) {
 				assert.NoError(t, err)
 			},
 		},
+		{
+			testName: "MakeBucketWithLocation passes location",
+
+			testFunc: func() {
+
+				var got string
+				mirroring = func() (cmd.ObjectLayer, error) {
+					proxyObj := prime()
+					proxyObj.MakeBucketWithLocationFunc = func(ctx context.Context, bucket string, location string) (err error) {
+						got = location
+						return nil
+					}
+
+					return proxyObj, nil
+				}
+
+				flocation = "eu-west-1"
+				defer func() { flocation = "" }()
+
+				err := exec(nil, []string{"bucket"})
+
+				assert.NoError(t, err)
+				assert.Equal(t, "eu-west-1", got)
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -0,0 +1,259 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/checkpoint"
+	"storj.io/ditto/pkg/delta"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/objlayer/checksum"
+	"storj.io/ditto/pkg/objlayer/mirroring"
+	"storj.io/ditto/pkg/objlayer/throttle"
+	"storj.io/ditto/pkg/ratelimit"
+	"storj.io/ditto/pkg/seed"
+)
+
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+var (
+	ffrom, fcheckpoint string
+	fbwlimit           string
+	fworkers           int
+	fbandwidth         int64
+	fskipVerify        bool
+	fchecksum          bool
+	fdryRun, fyes      bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "migrate <bucket>",
+	Short: "Copies whole bucket from one backend to the other",
+	Long: "Copies every object of bucket from backend given by --from, prime by default, to the other one " +
+		"with parallel workers, e.g. when switching providers. Objects the destination already holds with the same " +
+		"size and ETag are skipped. Progress is checkpointed to --checkpoint, so interrupted migration resumes " +
+		"where it stopped. --bwlimit caps bandwidth of uploads to destination, e.g. 10M. Once copied, content " +
+		"of all objects is compared, unless --skip-verify is given. With --checksum every object is also verified " +
+		"against content streamed from source as soon as it's written, mismatched objects fail to be copied. " +
+		"Amount of objects has to be confirmed before anything is copied, unless --yes is given. " +
+		"With --dry-run objects which would be copied are printed with their sizes and nothing is changed.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+// Options of single migration.
+type Options struct {
+	Workers    int
+	Bandwidth  int64
+	Checkpoint *checkpoint.Checkpoint
+	Verify     bool
+	Checksum   bool
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	logger, err := utils.GetLogger()
+	if err != nil {
+		return err
+	}
+
+	src, dst, to := prime, alter, "alter"
+	if ffrom == "alter" {
+		src, dst, to = alter, prime, "prime"
+	}
+
+	if fdryRun {
+		return plan(context.Background(), src, dst, args[0], to, os.Stdout)
+	}
+
+	if err = confirm(context.Background(), src, args[0], to, utils.NewConfirm(fyes)); err != nil {
+		return err
+	}
+
+	opts := Options{Workers: fworkers, Bandwidth: fbandwidth, Verify: !fskipVerify, Checksum: fchecksum}
+
+	if fbwlimit != "" {
+		if opts.Bandwidth, err = utils.ParseBandwidth(fbwlimit); err != nil {
+			return err
+		}
+	}
+
+	if fcheckpoint != "" {
+		if opts.Checkpoint, err = checkpoint.Open(fcheckpoint); err != nil {
+			return err
+		}
+		defer opts.Checkpoint.Close()
+	}
+
+	return migrate(context.Background(), src, dst, args[0], opts, logger, os.Stdout)
+}
+
+// migrate copies bucket from src to dst, which is created if it doesn't exist, and verifies the copy.
+func migrate(ctx context.Context, src, dst minio.ObjectLayer, bucket string, opts Options, logger l.Logger, out io.Writer) error {
+	if _, err := dst.GetBucketInfo(ctx, bucket); err != nil {
+		if _, ok := err.(minio.BucketNotFound); !ok {
+			return err
+		}
+
+		if err = dst.MakeBucketWithLocation(ctx, bucket, ""); err != nil {
+			return err
+		}
+	}
+
+	if opts.Bandwidth > 0 {
+		dst = throttle.NewBandwidthLayer(dst, ratelimit.NewLimiter(float64(opts.Bandwidth), 0))
+	}
+
+	if opts.Checksum {
+		dst = checksum.NewVerifyingLayer(dst)
+	}
+
+	seeder := seed.NewSeeder(src, dst, mirroring.NewReplicationHandler(src, dst), opts.Checkpoint, opts.Workers, logger)
+	if err := seeder.RunBucket(ctx, bucket); err != nil {
+		return err
+	}
+
+	progress := seeder.Progress()
+	fmt.Fprintln(out, progress)
+
+	if progress.Failed > 0 {
+		return utils.WithExitCode(utils.ExitPartial,
+			fmt.Errorf("%d objects failed to be copied, run migrate again to retry them", progress.Failed))
+	}
+
+	if !opts.Verify {
+		return nil
+	}
+
+	fmt.Fprintln(out, "Verifying content of copied objects...")
+
+	report := delta.NewReport(bucket, "")
+
+	stats, err := delta.Verify(ctx, src, dst, bucket, "", nil, report)
+	if err != nil {
+		return err
+	}
+
+	// objects which exist only on destination were there before, they don't affect migration
+	for _, e := range report.OnlyPrime {
+		fmt.Fprintf(out, "  %s: missing on destination\n", e.Key)
+	}
+
+	for _, e := range report.Differ {
+		fmt.Fprintf(out, "  %s: %s\n", e.Key, e.Reason())
+	}
+
+	for _, f := range report.Failed {
+		fmt.Fprintf(out, "  %s: %s\n", f.Key, f.Error)
+	}
+
+	if problems := len(report.OnlyPrime) + len(report.Differ) + len(report.Failed); problems > 0 {
+		return utils.WithExitCode(utils.ExitMismatch, fmt.Errorf("verification found %d objects not migrated correctly", problems))
+	}
+
+	fmt.Fprintf(out, "verified %d objects (%d bytes)\n", stats.Verified, stats.Bytes)
+
+	return nil
+}
+
+// confirm counts objects of bucket on src and asks c whether to copy them to backend named to.
+// Nothing is asked if c is nil.
+func confirm(ctx context.Context, src minio.ObjectLayer, bucket, to string, c utils.Confirm) error {
+	if c == nil {
+		return nil
+	}
+
+	count := 0
+
+	err := utils.ListObjects(ctx, src, bucket, "", func(oi minio.ObjectInfo) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return utils.Ask(c, fmt.Sprintf("copy %s objects of %s to %s, overwriting differing objects", utils.FormatCount(count), bucket, to))
+}
+
+// plan prints objects of bucket which migrate would copy from src to dst, backend named to.
+func plan(ctx context.Context, src, dst minio.ObjectLayer, bucket, to string, out io.Writer) error {
+	if _, err := dst.GetBucketInfo(ctx, bucket); err != nil {
+		if _, ok := err.(minio.BucketNotFound); !ok {
+			return err
+		}
+
+		fmt.Fprintf(out, "Bucket %s would be created on %s\n", bucket, to)
+	}
+
+	copied, skipped, total := 0, 0, int64(0)
+
+	err := utils.ListObjects(ctx, src, bucket, "", func(oi minio.ObjectInfo) error {
+		doi, err := dst.GetObjectInfo(ctx, bucket, oi.Name, minio.ObjectOptions{})
+		if err == nil && delta.Same(oi, doi) {
+			skipped++
+			return nil
+		}
+
+		copied++
+		total += oi.Size
+
+		fmt.Fprintf(out, "Object %s/%s (%d bytes) would be copied to %s\n", bucket, oi.Name, oi.Size, to)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%d objects (%d bytes) would be copied to %s, %d already there would be skipped\n", copied, total, to, skipped)
+
+	return nil
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	if ffrom != "prime" && ffrom != "alter" {
+		return fmt.Errorf("--from %q is unknown, expected prime or alter", ffrom)
+	}
+
+	if _, err := utils.ParseBandwidth(fbwlimit); err != nil {
+		return err
+	}
+
+	switch len(args) {
+	case 0:
+		return errors.New("bucket is required")
+	case 1:
+		return s3utils.CheckValidBucketName(args[0])
+	default:
+		return errors.New("too many arguments")
+	}
+}
+
+func init() {
+	Cmd.Flags().StringVar(&ffrom, "from", "prime", "backend to copy from, prime or alter")
+	Cmd.Flags().IntVar(&fworkers, "workers", seed.DefaultWorkers, "number of objects copied in parallel")
+	Cmd.Flags().StringVar(&fbwlimit, "bwlimit", "", "bytes per second uploaded to destination, e.g. 512K or 10M, unlimited by default")
+	Cmd.Flags().Int64Var(&fbandwidth, "bandwidth", 0, "bytes per second uploaded to destination, 0 is unlimited")
+	Cmd.Flags().MarkDeprecated("bandwidth", "use --bwlimit instead")
+	Cmd.Flags().StringVar(&fcheckpoint, "checkpoint", "", "file persisting progress, interrupted migration resumes from it")
+	Cmd.Flags().BoolVar(&fchecksum, "checksum", false, "verify checksum of every object once written to destination")
+	Cmd.Flags().BoolVar(&fskipVerify, "skip-verify", false, "don't compare content of objects once copied")
+	Cmd.Flags().BoolVarP(&fyes, "yes", "y", false, "don't ask for confirmation")
+	Cmd.Flags().BoolVar(&fdryRun, "dry-run", false, "only print objects which would be copied with their sizes")
+}
@@ -0,0 +1,258 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/cmd/utils"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// memoryLayer is object layer holding objects of buckets in memory.
+type memoryLayer struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]string
+}
+
+func newMemoryLayer(buckets map[string]map[string]string) (*memoryLayer, minio.ObjectLayer) {
+	m := &memoryLayer{buckets: buckets}
+	ol := test.NewProxyObjectLayer()
+
+	info := func(bucket, object, content string) minio.ObjectInfo {
+		sum := md5.Sum([]byte(content))
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content)), ETag: hex.EncodeToString(sum[:])}
+	}
+
+	ol.GetBucketInfoFunc = func(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if _, ok := m.buckets[bucket]; !ok {
+			return minio.BucketInfo{}, minio.BucketNotFound{Bucket: bucket}
+		}
+
+		return minio.BucketInfo{Name: bucket}, nil
+	}
+
+	ol.MakeBucketWithLocationFunc = func(ctx context.Context, bucket string, location string) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.buckets[bucket] = make(map[string]string)
+		return nil
+	}
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		var loi minio.ListObjectsInfo
+		for name, content := range m.buckets[bucket] {
+			if name > marker {
+				loi.Objects = append(loi.Objects, info(bucket, name, content))
+			}
+		}
+
+		sort.Slice(loi.Objects, func(i, j int) bool { return loi.Objects[i].Name < loi.Objects[j].Name })
+
+		return loi, nil
+	}
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		content, ok := m.buckets[bucket][object]
+		if !ok {
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		return info(bucket, object, content), nil
+	}
+
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		m.mu.Lock()
+		content := m.buckets[bucket][object]
+		m.mu.Unlock()
+
+		_, err := io.WriteString(writer, content)
+		return err
+	}
+
+	ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		b, err := ioutil.ReadAll(data)
+		if err != nil {
+			return minio.ObjectInfo{}, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.buckets[bucket][object] = string(b)
+
+		return info(bucket, object, string(b)), nil
+	}
+
+	return m, ol
+}
+
+// corruptingLayer returns wrong content of object.
+type corruptingLayer struct {
+	minio.ObjectLayer
+	object string
+}
+
+func (c corruptingLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	if object == c.object {
+		_, err := io.WriteString(writer, "XX")
+		return err
+	}
+
+	return c.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+}
+
+// truncatingLayer stores object without its last byte.
+type truncatingLayer struct {
+	minio.ObjectLayer
+	object string
+}
+
+func (t truncatingLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if object != t.object {
+		return t.ObjectLayer.PutObject(ctx, bucket, object, data, metadata, opts)
+	}
+
+	b, err := ioutil.ReadAll(data)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	truncated, err := hash.NewReader(bytes.NewReader(b[:len(b)-1]), int64(len(b)-1), "", "")
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	return t.ObjectLayer.PutObject(ctx, bucket, object, truncated, metadata, opts)
+}
+
+func TestMigrate(t *testing.T) {
+	_, src := newMemoryLayer(map[string]map[string]string{
+		"bucket": {"a": "1", "b": "22", "c": "333"},
+	})
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "new bucket",
+			testFunc: func(t *testing.T) {
+				m, dst := newMemoryLayer(map[string]map[string]string{})
+				out := &bytes.Buffer{}
+
+				err := migrate(context.Background(), src, dst, "bucket", Options{Workers: 2, Bandwidth: 1 << 20, Verify: true}, nil, out)
+
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]string{"a": "1", "b": "22", "c": "333"}, m.buckets["bucket"])
+				assert.Contains(t, out.String(), "bucket bucket: listed 3, copied 3 (6 bytes), skipped 0, failed 0\n")
+				assert.Contains(t, out.String(), "verified 3 objects (6 bytes)\n")
+			},
+		},
+		{
+			testName: "existing objects",
+			testFunc: func(t *testing.T) {
+				m, dst := newMemoryLayer(map[string]map[string]string{
+					"bucket": {"a": "1", "d": "4"},
+				})
+				out := &bytes.Buffer{}
+
+				err := migrate(context.Background(), src, dst, "bucket", Options{Verify: true}, nil, out)
+
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]string{"a": "1", "b": "22", "c": "333", "d": "4"}, m.buckets["bucket"])
+				assert.Contains(t, out.String(), "copied 2 (5 bytes), skipped 1")
+			},
+		},
+		{
+			testName: "dry run",
+			testFunc: func(t *testing.T) {
+				m, dst := newMemoryLayer(map[string]map[string]string{
+					"bucket": {"a": "1", "b": "2"},
+				})
+				out := &bytes.Buffer{}
+
+				assert.NoError(t, plan(context.Background(), src, dst, "bucket", "alter", out))
+				assert.Equal(t, map[string]string{"a": "1", "b": "2"}, m.buckets["bucket"])
+				assert.Equal(t, "Object bucket/b (2 bytes) would be copied to alter\n"+
+					"Object bucket/c (3 bytes) would be copied to alter\n"+
+					"2 objects (5 bytes) would be copied to alter, 1 already there would be skipped\n", out.String())
+
+				_, empty := newMemoryLayer(map[string]map[string]string{})
+				out.Reset()
+
+				assert.NoError(t, plan(context.Background(), src, empty, "bucket", "prime", out))
+				assert.Contains(t, out.String(), "Bucket bucket would be created on prime\n")
+			},
+		},
+		{
+			testName: "confirmation",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+
+				err := confirm(context.Background(), src, "bucket", "alter", utils.Prompt(strings.NewReader("n\n"), out))
+				assert.Equal(t, utils.ErrNotConfirmed, err)
+				assert.Equal(t, "copy 3 objects of bucket to alter, overwriting differing objects? [y/N] ", out.String())
+
+				assert.NoError(t, confirm(context.Background(), src, "bucket", "alter", nil))
+			},
+		},
+		{
+			testName: "verification failure",
+			testFunc: func(t *testing.T) {
+				m, dst := newMemoryLayer(map[string]map[string]string{})
+
+				// object is corrupted once copied, without changing its listing
+				corrupting := corruptingLayer{ObjectLayer: dst, object: "b"}
+
+				out := &bytes.Buffer{}
+
+				err := migrate(context.Background(), src, corrupting, "bucket", Options{Verify: true}, nil, out)
+
+				assert.EqualError(t, err, "verification found 1 objects not migrated correctly")
+				assert.Contains(t, out.String(), "  b: content sha256 ")
+				assert.Len(t, m.buckets["bucket"], 3)
+			},
+		},
+		{
+			testName: "checksum mismatch",
+			testFunc: func(t *testing.T) {
+				m, dst := newMemoryLayer(map[string]map[string]string{})
+				out := &bytes.Buffer{}
+
+				err := migrate(context.Background(), src, truncatingLayer{ObjectLayer: dst, object: "c"}, "bucket", Options{Checksum: true}, nil, out)
+
+				assert.EqualError(t, err, "1 objects failed to be copied, run migrate again to retry them")
+				assert.Equal(t, "33", m.buckets["bucket"]["c"])
+				assert.Contains(t, out.String(), "copied 2 (3 bytes), skipped 0, failed 1")
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
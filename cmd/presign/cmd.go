@@ -0,0 +1,146 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package presign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	miniogo "github.com/minio/minio-go"
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/diff"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/credentials"
+)
+
+// maxExpires is the longest validity of presigned URL allowed by signature V4.
+const maxExpires = 7 * 24 * time.Hour
+
+// Method reference for unit testing
+var readConfigMethod = config.ReadConfig
+
+var (
+	fmethod, fbackend, fregion string
+	fexpires                   time.Duration
+)
+
+var Cmd = &cobra.Command{
+	Use:   "presign <bucket>/<key>",
+	Short: "Generates temporary URL of object on prime or alter",
+	Long: "Signs URL which lets anyone holding it download object with GET, or upload it with PUT, " +
+		"directly from --backend until it expires, so data can be shared without handing out credentials. " +
+		"Backend is contacted only to look up region of bucket, unless --region is given.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+// Output is JSON output of presign.
+type Output struct {
+	URL     string    `json:"url"`
+	Method  string    `json:"method"`
+	Backend string    `json:"backend"`
+	Expires time.Time `json:"expires"`
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	cfg, err := readConfigMethod(true)
+	if err != nil {
+		return err
+	}
+
+	bucket, key := diff.SplitPath(args[0])
+
+	u, err := presign(context.Background(), cfg, fbackend, fmethod, bucket, key, fexpires, fregion)
+	if err != nil {
+		return err
+	}
+
+	if utils.JSON {
+		return utils.PrintJSON(os.Stdout, Output{
+			URL:     u.String(),
+			Method:  fmethod,
+			Backend: fbackend,
+			Expires: time.Now().Add(fexpires).UTC(),
+		})
+	}
+
+	fmt.Println(u)
+
+	return nil
+}
+
+// presign signs URL of object on backend with its keys, valid for method until expires elapses.
+// Empty region is looked up from backend.
+func presign(ctx context.Context, cfg *config.Config, backend, method, bucket, key string, expires time.Duration, region string) (*url.URL, error) {
+	creds := cfg.Server1
+	if backend == credentials.Alter {
+		creds = cfg.Server2
+	}
+
+	if creds == nil || creds.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint of %s is not configured", backend)
+	}
+
+	provider, err := credentials.NewProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := provider.Keys(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, secure, err := minio.ParseGatewayEndpoint(creds.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := miniogo.NewWithRegion(endpoint, keys.AccessKey, keys.SecretKey, secure, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Presign(method, bucket, key, expires, nil)
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	switch {
+	case fmethod != http.MethodGet && fmethod != http.MethodPut:
+		return fmt.Errorf("method %q is unsupported, expected GET or PUT", fmethod)
+	case fbackend != credentials.Prime && fbackend != credentials.Alter:
+		return fmt.Errorf("backend %q is unknown, expected prime or alter", fbackend)
+	case fexpires < time.Second || fexpires > maxExpires:
+		return fmt.Errorf("expiration %s is invalid, expected 1s to %s", fexpires, maxExpires)
+	}
+
+	switch len(args) {
+	case 0:
+		return errors.New("bucket/key is required")
+	case 1:
+		bucket, key := diff.SplitPath(args[0])
+		if err := s3utils.CheckValidBucketName(bucket); err != nil {
+			return err
+		}
+
+		return s3utils.CheckValidObjectName(key)
+	default:
+		return errors.New("too many arguments")
+	}
+}
+
+func init() {
+	Cmd.Flags().StringVar(&fmethod, "method", http.MethodGet, "HTTP method URL is valid for, GET or PUT")
+	Cmd.Flags().StringVar(&fbackend, "backend", credentials.Prime, "backend URL points to, prime or alter")
+	Cmd.Flags().DurationVar(&fexpires, "expires", time.Hour, "validity of URL, up to 168h")
+	Cmd.Flags().StringVar(&fregion, "region", "", "region of bucket, looked up from backend if empty")
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package presign
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+)
+
+func TestPresign(t *testing.T) {
+	cfg := &config.Config{
+		Server1: &config.Credentials{Endpoint: "prime:9000", AccessKey: "primekey", SecretKey: "primesecret"},
+		Server2: &config.Credentials{Endpoint: "alter.example.com", AccessKey: "alterkey", SecretKey: "altersecret"},
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "get from prime",
+			testFunc: func(t *testing.T) {
+				u, err := presign(context.Background(), cfg, "prime", "GET", "bucket", "dir/key", time.Hour, "us-east-1")
+				assert.NoError(t, err)
+
+				assert.Equal(t, "prime:9000", u.Host)
+				assert.Equal(t, "/bucket/dir/key", u.Path)
+				assert.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+				assert.True(t, strings.HasPrefix(u.Query().Get("X-Amz-Credential"), "primekey/"))
+				assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+			},
+		},
+		{
+			testName: "put to alter",
+			testFunc: func(t *testing.T) {
+				u, err := presign(context.Background(), cfg, "alter", "PUT", "bucket", "key", 10*time.Minute, "us-east-1")
+				assert.NoError(t, err)
+
+				assert.Contains(t, u.Host, "alter.example.com")
+				assert.Equal(t, "600", u.Query().Get("X-Amz-Expires"))
+				assert.True(t, strings.HasPrefix(u.Query().Get("X-Amz-Credential"), "alterkey/"))
+			},
+		},
+		{
+			testName: "backend not configured",
+			testFunc: func(t *testing.T) {
+				_, err := presign(context.Background(), &config.Config{}, "alter", "GET", "bucket", "key", time.Hour, "us-east-1")
+				assert.EqualError(t, err, "endpoint of alter is not configured")
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	defer func() { fmethod, fbackend, fexpires = "GET", "prime", time.Hour }()
+
+	assert.NoError(t, validateArgs(nil, []string{"bucket/key"}))
+	assert.Error(t, validateArgs(nil, []string{"bucket"}))
+
+	fexpires = 8 * 24 * time.Hour
+	assert.EqualError(t, validateArgs(nil, []string{"bucket/key"}), "expiration 192h0m0s is invalid, expected 1s to 168h0m0s")
+
+	fexpires, fmethod = time.Hour, "DELETE"
+	assert.EqualError(t, validateArgs(nil, []string{"bucket/key"}), `method "DELETE" is unsupported, expected GET or PUT`)
+
+	fmethod, fbackend = "GET", "server1"
+	assert.Error(t, validateArgs(nil, []string{"bucket/key"}))
+}
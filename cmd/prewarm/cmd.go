@@ -0,0 +1,159 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package prewarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/cache"
+	"storj.io/ditto/pkg/config"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/objlayer/mirroring"
+)
+
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+// listBatch is number of objects listed at once.
+const listBatch = 1000
+
+var Cmd = &cobra.Command{
+	Use:   "prewarm <bucket>[/prefix]",
+	Short: "Pulls objects into disk cache ahead of traffic",
+	Long: "Walks bucket, optionally only objects under prefix, and reads every object which fits into disk cache " +
+		"configured with Cache.DiskDir, so gateway sharing the directory serves them from cache. " +
+		"In-memory cache lives in gateway process and can't be warmed up.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+// Stats counts objects processed by prewarm.
+type Stats struct {
+	Cached  int
+	Bytes   int64
+	Skipped int
+	Failed  int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("cached %d objects (%d bytes), skipped %d too big, failed %d", s.Cached, s.Bytes, s.Skipped, s.Failed)
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	cfg, err := config.ReadConfig(true)
+	if err != nil {
+		return err
+	}
+
+	opts := cfg.Cache
+	if opts == nil || opts.DiskDir == "" {
+		return errors.New("disk cache is not configured, set Cache.DiskDir with `ditto config set`")
+	}
+
+	disk, err := cache.NewDisk(opts.DiskDir, opts.DiskSize, opts.DiskObjectSize)
+	if err != nil {
+		return err
+	}
+
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	logger, err := utils.GetLogger()
+	if err != nil {
+		return err
+	}
+
+	m := &mirroring.MirroringObjectLayer{
+		Prime:  prime,
+		Alter:  alter,
+		Logger: logger,
+		Config: cfg,
+		Disk:   disk,
+	}
+
+	bucket, prefix := splitPath(args[0])
+
+	stats, err := prewarm(context.Background(), m, disk.Fits, bucket, prefix, logger)
+
+	fmt.Println(stats)
+
+	if err == nil && stats.Failed > 0 {
+		err = utils.WithExitCode(utils.ExitPartial, fmt.Errorf("%d objects failed to be cached", stats.Failed))
+	}
+
+	return err
+}
+
+// prewarm reads every object under prefix which fits into cache through ol, which caches it.
+// Failed reads are logged and counted, listing failure stops the walk.
+func prewarm(ctx context.Context, ol minio.ObjectLayer, fits func(size int64) bool, bucket, prefix string, logger l.Logger) (stats Stats, err error) {
+	marker := ""
+
+	for {
+		loi, err := ol.ListObjects(ctx, bucket, prefix, marker, "", listBatch)
+		if err != nil {
+			return stats, err
+		}
+
+		for _, obj := range loi.Objects {
+			if !fits(obj.Size) {
+				stats.Skipped++
+				continue
+			}
+
+			err := ol.GetObject(ctx, bucket, obj.Name, 0, obj.Size, ioutil.Discard, obj.ETag, minio.ObjectOptions{})
+			if err != nil {
+				stats.Failed++
+				logger.LogE(fmt.Errorf("unable to cache %s/%s: %s", bucket, obj.Name, err))
+
+				continue
+			}
+
+			stats.Cached++
+			stats.Bytes += obj.Size
+		}
+
+		if !loi.IsTruncated || len(loi.Objects) == 0 {
+			return stats, nil
+		}
+
+		marker = loi.NextMarker
+		if marker == "" {
+			marker = loi.Objects[len(loi.Objects)-1].Name
+		}
+	}
+}
+
+// splitPath splits bucket[/prefix] argument.
+func splitPath(path string) (bucket, prefix string) {
+	i := strings.Index(path, "/")
+	if i < 0 {
+		return path, ""
+	}
+
+	return path[:i], path[i+1:]
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("bucket is required")
+	case 1:
+		bucket, _ := splitPath(args[0])
+
+		return s3utils.CheckValidBucketName(bucket)
+	default:
+		return errors.New("too many arguments")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package prewarm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestPrewarm(t *testing.T) {
+	objects := []minio.ObjectInfo{
+		{Name: "a", Size: 1, ETag: "ea"},
+		{Name: "b", Size: 100, ETag: "eb"},
+		{Name: "c", Size: 2, ETag: "ec"},
+		{Name: "d", Size: 3, ETag: "ed"},
+	}
+
+	ol := test.NewProxyObjectLayer()
+
+	// objects are listed two at a time
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		i := 0
+		for i < len(objects) && objects[i].Name <= marker {
+			i++
+		}
+
+		end := i + 2
+		if end > len(objects) {
+			end = len(objects)
+		}
+
+		return minio.ListObjectsInfo{Objects: objects[i:end], IsTruncated: end < len(objects)}, nil
+	}
+
+	read := map[string]string{}
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		if object == "d" {
+			return errors.New("backend is down")
+		}
+
+		read[object] = etag
+		return nil
+	}
+
+	logger := &test.MockLogger{}
+
+	stats, err := prewarm(context.Background(), ol, func(size int64) bool { return size < 10 }, "bucket", "", logger)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Stats{Cached: 2, Bytes: 3, Skipped: 1, Failed: 1}, stats)
+	assert.Equal(t, map[string]string{"a": "ea", "c": "ec"}, read)
+	assert.Equal(t, 1, logger.LogECount())
+}
+
+func TestValidateArgs(t *testing.T) {
+	assert.Error(t, validateArgs(nil, nil))
+	assert.Error(t, validateArgs(nil, []string{"bucket", "other"}))
+	assert.Error(t, validateArgs(nil, []string{"."}))
+	assert.NoError(t, validateArgs(nil, []string{"bucket/some/prefix"}))
+
+	bucket, prefix := splitPath("bucket/some/prefix")
+	assert.Equal(t, "bucket", bucket)
+	assert.Equal(t, "some/prefix", prefix)
+
+	bucket, prefix = splitPath("bucket")
+	assert.Equal(t, "bucket", bucket)
+	assert.Equal(t, "", prefix)
+}
@@ -6,7 +6,6 @@ import (
 	"os/signal"
 	"storj.io/ditto/cmd/utils"
 
-	l "storj.io/ditto/pkg/logger"
 )
 
 
@@ -16,7 +15,7 @@ var Cmd = &cobra.Command{
 	Args:    validateArgs,
 	Short:   "Upload files or file list_cmd to specified bucket",
 	Long:    `Upload files or file list_cmd to specified bucket`,
-	RunE:    NewPutExec(utils.GetGateway, &l.StdOutLogger).runE,
+	RunE:    runE,
 }
 
 var (
@@ -35,4 +34,13 @@ func init() {
 	Cmd.Flags().BoolVarP(&fforce, "force", "f", false, "force usage")
 	Cmd.Flags().StringVarP(&fprefix, "prefix", "p", "", "prefix usage")
 	Cmd.Flags().StringVarP(&fdelimiter, "delimiter", "d", "/", "delimiter usage")
+}
+
+func runE(cmd *cobra.Command, args []string) error {
+	logger, err := utils.GetLogger()
+	if err != nil {
+		return err
+	}
+
+	return NewPutExec(utils.GetGateway, logger).runE(cmd, args)
 }
\ No newline at end of file
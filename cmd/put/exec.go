@@ -48,6 +48,9 @@ func (e putExec) runE(cmd *cobra.Command, args []string) error {
 	e.SetObjLayer(mirr)
 
 	bctx := context.Background()
+	// Waits for background mirroring of big objects to finish
+	defer mirr.Shutdown(bctx)
+
 	_, err = mirr.GetBucketInfo(bctx, args[0])
 	if err != nil {
 		return err
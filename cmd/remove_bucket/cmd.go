@@ -0,0 +1,269 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package remove_bucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/utils"
+)
+
+// Functions listed as var for testing purposes only
+var (
+	mirroring = utils.GetObjectLayer
+	backends  = utils.GetBackends
+)
+
+var (
+	fforce, fdryRun, fyes bool
+	fconcurrency          int
+)
+
+var Cmd = &cobra.Command{
+	Use:     "remove-bucket [rb] <bucket>",
+	Aliases: []string{"rb"},
+	Short:   "Removes bucket from prime and alter",
+	Long: "Removes empty bucket from prime and alter. With --force every object of bucket is deleted " +
+		"from both backends first, backends are emptied in parallel. Amount of objects has to be confirmed " +
+		"before anything is deleted, unless --yes is given. With --dry-run objects which would be " +
+		"deleted are printed with their sizes and nothing is changed.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	bucket := args[0]
+
+	if fdryRun {
+		return dryRun(ctx, bucket, fforce, os.Stdout)
+	}
+
+	if fforce {
+		prime, alter, err := backends()
+		if err != nil {
+			return err
+		}
+
+		if err = confirmEmpty(ctx, prime, alter, bucket, utils.NewConfirm(fyes)); err != nil {
+			return err
+		}
+
+		if err = empty(ctx, prime, alter, bucket, fconcurrency, os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	ol, err := mirroring()
+	if err != nil {
+		return err
+	}
+
+	// Waits for background mirroring of bucket removal to finish
+	defer ol.Shutdown(ctx)
+
+	if err = ol.DeleteBucket(ctx, bucket); err != nil {
+		return err
+	}
+
+	fmt.Printf("Bucket %s removed\n", bucket)
+
+	return nil
+}
+
+// confirmEmpty counts objects of bucket on prime and alter and asks confirm whether to delete them.
+// Nothing is asked if bucket is empty or confirm is nil.
+func confirmEmpty(ctx context.Context, prime, alter minio.ObjectLayer, bucket string, confirm utils.Confirm) error {
+	if confirm == nil {
+		return nil
+	}
+
+	count := 0
+
+	for _, ol := range []minio.ObjectLayer{prime, alter} {
+		err := utils.ListObjects(ctx, ol, bucket, "", func(oi minio.ObjectInfo) error {
+			count++
+			return nil
+		})
+		if _, ok := err.(minio.BucketNotFound); !ok && err != nil {
+			return err
+		}
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	return utils.Ask(confirm, fmt.Sprintf("delete %s objects from 2 backends and remove bucket %s", utils.FormatCount(count), bucket))
+}
+
+// empty deletes every object of bucket from prime and alter at the same time, concurrency objects
+// of each backend at once. Backend without the bucket is skipped.
+func empty(ctx context.Context, prime, alter minio.ObjectLayer, bucket string, concurrency int, out io.Writer) error {
+	var wg sync.WaitGroup
+
+	var primeDeleted, alterDeleted int
+	var primeErr, alterErr error
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		primeDeleted, primeErr = emptyBackend(ctx, prime, "prime", bucket, concurrency)
+	}()
+	go func() {
+		defer wg.Done()
+		alterDeleted, alterErr = emptyBackend(ctx, alter, "alter", bucket, concurrency)
+	}()
+	wg.Wait()
+
+	fmt.Fprintf(out, "Deleted %d objects of %s from prime and %d from alter\n", primeDeleted, bucket, alterDeleted)
+
+	switch {
+	case primeErr != nil:
+		return fmt.Errorf("prime: %s", primeErr)
+	case alterErr != nil:
+		return fmt.Errorf("alter: %s", alterErr)
+	}
+
+	return nil
+}
+
+// dryRun prints what removal of bucket would do, objects of both backends are listed if force is set.
+func dryRun(ctx context.Context, bucket string, force bool, out io.Writer) error {
+	if force {
+		prime, alter, err := backends()
+		if err != nil {
+			return err
+		}
+
+		for _, b := range []struct {
+			name string
+			ol   minio.ObjectLayer
+		}{{"prime", prime}, {"alter", alter}} {
+			if err = planEmpty(ctx, b.ol, b.name, bucket, out); err != nil {
+				return fmt.Errorf("%s: %s", b.name, err)
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "Bucket %s would be removed from prime and alter\n", bucket)
+
+	return nil
+}
+
+// planEmpty prints objects of bucket which emptyBackend would delete from backend ol.
+func planEmpty(ctx context.Context, ol minio.ObjectLayer, backend, bucket string, out io.Writer) error {
+	count, total := 0, int64(0)
+
+	err := utils.ListObjects(ctx, ol, bucket, "", func(oi minio.ObjectInfo) error {
+		count++
+		total += oi.Size
+
+		fmt.Fprintf(out, "Object %s/%s (%d bytes) would be deleted from %s\n", bucket, oi.Name, oi.Size, backend)
+
+		return nil
+	})
+	if _, ok := err.(minio.BucketNotFound); ok {
+		err = nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%d objects (%d bytes) would be deleted from %s\n", count, total, backend)
+
+	return nil
+}
+
+// emptyBackend deletes every object of bucket from backend ol by concurrency workers and returns amount
+// of deleted objects. Objects already gone are not failures, first failure is returned once listing is done.
+func emptyBackend(ctx context.Context, ol minio.ObjectLayer, backend, bucket string, concurrency int) (int, error) {
+	names := make(chan string)
+
+	var (
+		mu      sync.Mutex
+		deleted int
+		failed  int
+		first   error
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for name := range names {
+				err := ol.DeleteObject(ctx, bucket, name)
+				if _, ok := err.(minio.ObjectNotFound); ok {
+					continue
+				}
+
+				mu.Lock()
+				if err != nil {
+					utils.ReportFailure("delete", bucket, name, backend, err)
+
+					failed++
+					if first == nil {
+						first = fmt.Errorf("object %s/%s: %s", bucket, name, err)
+					}
+				} else {
+					deleted++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := utils.ListObjects(ctx, ol, bucket, "", func(oi minio.ObjectInfo) error {
+		names <- oi.Name
+		return nil
+	})
+
+	close(names)
+	wg.Wait()
+
+	if _, ok := err.(minio.BucketNotFound); ok {
+		err = nil
+	}
+
+	if err != nil {
+		return deleted, err
+	}
+
+	if failed > 0 {
+		return deleted, fmt.Errorf("%d objects failed to be deleted, first %s", failed, first)
+	}
+
+	return deleted, nil
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	switch {
+	case len(args) == 0:
+		return errors.New("bucket is required")
+	case len(args) > 1:
+		return errors.New("too many arguments")
+	case fconcurrency <= 0:
+		return errors.New("--concurrency must be positive")
+	}
+
+	return s3utils.CheckValidBucketName(args[0])
+}
+
+func init() {
+	Cmd.Flags().BoolVarP(&fforce, "force", "f", false, "delete every object of bucket from both backends before removing it")
+	Cmd.Flags().BoolVarP(&fyes, "yes", "y", false, "don't ask for confirmation of --force")
+	Cmd.Flags().BoolVar(&fdryRun, "dry-run", false, "only print objects which would be deleted with their sizes")
+	Cmd.Flags().IntVar(&fconcurrency, "concurrency", 4, "amount of objects deleted at once from each backend with --force")
+}
@@ -0,0 +1,219 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package remove_bucket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/cmd/utils"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestEmpty(t *testing.T) {
+	// layer returns backend holding objects of bucket, nil objects mean bucket doesn't exist
+	layer := func(objects []string, failing string) (minio.ObjectLayer, func() []string) {
+		var mu sync.Mutex
+		var deleted []string
+
+		ol := test.NewProxyObjectLayer()
+		ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+			if objects == nil {
+				return minio.ListObjectsInfo{}, minio.BucketNotFound{Bucket: bucket}
+			}
+
+			var loi minio.ListObjectsInfo
+			for _, name := range objects {
+				loi.Objects = append(loi.Objects, minio.ObjectInfo{Bucket: bucket, Name: name})
+			}
+
+			return loi, nil
+		}
+		ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+			switch object {
+			case failing:
+				return errors.New("access denied")
+			case "gone":
+				return minio.ObjectNotFound{Bucket: bucket, Object: object}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			deleted = append(deleted, object)
+
+			return nil
+		}
+
+		return ol, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			sort.Strings(deleted)
+			return deleted
+		}
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "both backends emptied",
+			testFunc: func(t *testing.T) {
+				prime, primeDeleted := layer([]string{"a", "b/c", "gone"}, "")
+				alter, alterDeleted := layer([]string{"a"}, "")
+
+				out := &bytes.Buffer{}
+				err := empty(context.Background(), prime, alter, "bucket", 2, out)
+
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"a", "b/c"}, primeDeleted())
+				assert.Equal(t, []string{"a"}, alterDeleted())
+				assert.Equal(t, "Deleted 2 objects of bucket from prime and 1 from alter\n", out.String())
+			},
+		},
+		{
+			testName: "missing bucket skipped",
+			testFunc: func(t *testing.T) {
+				prime, primeDeleted := layer([]string{"a"}, "")
+				alter, _ := layer(nil, "")
+
+				err := empty(context.Background(), prime, alter, "bucket", 1, &bytes.Buffer{})
+
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"a"}, primeDeleted())
+			},
+		},
+		{
+			testName: "failed delete",
+			testFunc: func(t *testing.T) {
+				prime, _ := layer([]string{"a"}, "")
+				alter, alterDeleted := layer([]string{"a", "b"}, "b")
+
+				out := &bytes.Buffer{}
+				err := empty(context.Background(), prime, alter, "bucket", 4, out)
+
+				assert.EqualError(t, err, "alter: 1 objects failed to be deleted, first object bucket/b: access denied")
+				assert.Equal(t, []string{"a"}, alterDeleted())
+				assert.Equal(t, "Deleted 1 objects of bucket from prime and 1 from alter\n", out.String())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestExec(t *testing.T) {
+	defer func() { fforce = false }()
+
+	var calls []string
+
+	mirroring = func() (minio.ObjectLayer, error) {
+		ol := test.NewProxyObjectLayer()
+		ol.DeleteBucketFunc = func(ctx context.Context, bucket string) error {
+			calls = append(calls, "delete bucket "+bucket)
+			return nil
+		}
+		ol.ShutdownFunc = func(ctx context.Context) error {
+			calls = append(calls, "shutdown")
+			return nil
+		}
+
+		return ol, nil
+	}
+	backends = func() (minio.ObjectLayer, minio.ObjectLayer, error) {
+		calls = append(calls, "backends")
+
+		ol := test.NewProxyObjectLayer()
+		ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+			return minio.ListObjectsInfo{}, nil
+		}
+
+		return ol, ol, nil
+	}
+
+	fforce = false
+	assert.NoError(t, exec(nil, []string{"bucket"}))
+	assert.Equal(t, []string{"delete bucket bucket", "shutdown"}, calls)
+
+	calls = nil
+	fforce = true
+	assert.NoError(t, exec(nil, []string{"bucket"}))
+	assert.Equal(t, []string{"backends", "delete bucket bucket", "shutdown"}, calls)
+}
+
+func TestDryRun(t *testing.T) {
+	layer := func(objects ...minio.ObjectInfo) minio.ObjectLayer {
+		ol := test.NewProxyObjectLayer()
+		ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+			if objects == nil {
+				return minio.ListObjectsInfo{}, minio.BucketNotFound{Bucket: bucket}
+			}
+
+			return minio.ListObjectsInfo{Objects: objects}, nil
+		}
+		ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+			t.Fatalf("object %s deleted by dry run", object)
+			return nil
+		}
+
+		return ol
+	}
+
+	backends = func() (minio.ObjectLayer, minio.ObjectLayer, error) {
+		return layer(minio.ObjectInfo{Name: "a", Size: 1}, minio.ObjectInfo{Name: "b", Size: 2}), layer(), nil
+	}
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, dryRun(context.Background(), "bucket", true, out))
+	assert.Equal(t, "Object bucket/a (1 bytes) would be deleted from prime\n"+
+		"Object bucket/b (2 bytes) would be deleted from prime\n"+
+		"2 objects (3 bytes) would be deleted from prime\n"+
+		"0 objects (0 bytes) would be deleted from alter\n"+
+		"Bucket bucket would be removed from prime and alter\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, dryRun(context.Background(), "bucket", false, out))
+	assert.Equal(t, "Bucket bucket would be removed from prime and alter\n", out.String())
+}
+
+func TestConfirmEmpty(t *testing.T) {
+	layer := func(names ...string) minio.ObjectLayer {
+		ol := test.NewProxyObjectLayer()
+		ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+			var loi minio.ListObjectsInfo
+			for _, name := range names {
+				loi.Objects = append(loi.Objects, minio.ObjectInfo{Name: name})
+			}
+
+			return loi, nil
+		}
+
+		return ol
+	}
+
+	out := &bytes.Buffer{}
+	err := confirmEmpty(context.Background(), layer("a", "b"), layer("a"), "bucket", utils.Prompt(strings.NewReader("n\n"), out))
+
+	assert.Equal(t, utils.ErrNotConfirmed, err)
+	assert.Equal(t, "delete 3 objects from 2 backends and remove bucket bucket? [y/N] ", out.String())
+
+	assert.NoError(t, confirmEmpty(context.Background(), layer(), layer(), "bucket", utils.Refuse))
+	assert.NoError(t, confirmEmpty(context.Background(), layer("a"), layer(), "bucket", nil))
+}
+
+func TestValidateArgs(t *testing.T) {
+	assert.EqualError(t, validateArgs(nil, nil), "bucket is required")
+	assert.EqualError(t, validateArgs(nil, []string{"a", "b"}), "too many arguments")
+	assert.Error(t, validateArgs(nil, []string{"."}))
+	assert.NoError(t, validateArgs(nil, []string{"bucket"}))
+}
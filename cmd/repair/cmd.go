@@ -0,0 +1,232 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package repair
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/diff"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/delta"
+	"storj.io/ditto/pkg/objlayer/mirroring"
+)
+
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+var (
+	freport, fpolicy       string
+	ffromFile              string
+	fverify, fdryRun, fyes bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "repair [bucket[/prefix]]",
+	Short: "Resolves differences between prime and alter",
+	Long: "Copies and deletes objects so backends match, according to Repair.Policy of config or --policy. " +
+		"Differences are read from --report, which is JSON output of `ditto diff` or `ditto verify`, " +
+		"or are recomputed for bucket as by `ditto diff`, or `ditto verify` with --verify. " +
+		"With --from-file only keys listed in file, one per line, are compared and repaired. " +
+		"Amount of objects has to be confirmed before anything is changed, unless --yes is given.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+// Output is JSON output of repair.
+type Output struct {
+	DryRun   bool           `json:"dryRun"`
+	Repaired int            `json:"repaired"`
+	Failed   int            `json:"failed"`
+	Results  []delta.Result `json:"results"`
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	cfg, err := config.ReadConfig(true)
+	if err != nil {
+		return err
+	}
+
+	policy := fpolicy
+	if policy == "" && cfg.Repair != nil {
+		policy = cfg.Repair.Policy
+	}
+
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	repairer, err := delta.NewRepairer(policy, mirroring.NewReplicationHandler(prime, alter),
+		mirroring.NewReplicationHandler(alter, prime))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	report, err := loadReport(ctx, prime, alter, args)
+	if err != nil {
+		return err
+	}
+
+	if !fdryRun && report.Len() > 0 {
+		summary := fmt.Sprintf("repair %s objects of %s on %s", utils.FormatCount(report.Len()), report.Bucket, targets(policy))
+		if err = utils.Ask(utils.NewConfirm(fyes), summary); err != nil {
+			return err
+		}
+	}
+
+	return run(ctx, repairer.WithDryRun(fdryRun), report, os.Stdout, utils.JSON)
+}
+
+// targets names backends which repair policy changes.
+func targets(policy string) string {
+	switch policy {
+	case delta.PrimeWins:
+		return "alter"
+	case delta.AlterWins:
+		return "prime"
+	default:
+		return "2 backends"
+	}
+}
+
+// loadReport reads report given by --report, or computes report of bucket given by args.
+func loadReport(ctx context.Context, prime, alter minio.ObjectLayer, args []string) (*delta.Report, error) {
+	if freport != "" {
+		return readReport(freport)
+	}
+
+	bucket, prefix := diff.SplitPath(args[0])
+	report := delta.NewReport(bucket, prefix)
+
+	if ffromFile != "" {
+		keys, err := utils.ReadKeys(ffromFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return report, delta.DiffKeys(ctx, prime, alter, bucket, keys, report.Add)
+	}
+
+	if fverify {
+		_, err := delta.Verify(ctx, prime, alter, bucket, prefix, nil, report)
+		return report, err
+	}
+
+	return report, delta.Diff(ctx, prime, alter, bucket, prefix, report.Add)
+}
+
+// readReport reads JSON report from file, "-" reads standard input.
+func readReport(file string) (*delta.Report, error) {
+	in := os.Stdin
+
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	report := &delta.Report{}
+	if err := json.NewDecoder(in).Decode(report); err != nil {
+		return nil, fmt.Errorf("unable to read report %s: %s", file, err)
+	}
+
+	if err := s3utils.CheckValidBucketName(report.Bucket); err != nil {
+		return nil, fmt.Errorf("report %s has invalid bucket: %s", file, err)
+	}
+
+	return report, nil
+}
+
+// run repairs differences of report and prints result of every object to out, as JSON if asJSON is set.
+func run(ctx context.Context, repairer *delta.Repairer, report *delta.Report, out io.Writer, asJSON bool) error {
+	output := &Output{DryRun: fdryRun, Results: []delta.Result{}}
+
+	planned, plannedBytes := 0, int64(0)
+
+	stats, err := repairer.Repair(ctx, report, func(r delta.Result) {
+		if r.Error != "" {
+			utils.ReportFailureMessage(r.Action, report.Bucket, r.Key, r.Error)
+		}
+
+		if asJSON {
+			output.Results = append(output.Results, r)
+			return
+		}
+
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(out, "%s/%s: %s failed: %s\n", report.Bucket, r.Key, r.Action, r.Error)
+		case fdryRun:
+			planned++
+			plannedBytes += r.Bytes
+			fmt.Fprintf(out, "%s/%s: would %s (%d bytes)\n", report.Bucket, r.Key, r.Action, r.Bytes)
+		default:
+			fmt.Fprintf(out, "%s/%s: %s\n", report.Bucket, r.Key, r.Action)
+		}
+	})
+
+	if asJSON {
+		output.Repaired, output.Failed = stats.Repaired, stats.Failed
+
+		if jerr := utils.PrintJSON(out, output); jerr != nil {
+			return jerr
+		}
+	} else if fdryRun {
+		fmt.Fprintf(out, "would repair %d objects (%d bytes)\n", planned, plannedBytes)
+	} else {
+		fmt.Fprintln(out, stats)
+	}
+
+	if err == nil && stats.Failed > 0 {
+		err = utils.WithExitCode(utils.ExitPartial, fmt.Errorf("%d objects failed to be repaired", stats.Failed))
+	}
+
+	return err
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	switch {
+	case len(args) > 1:
+		return errors.New("too many arguments")
+	case freport != "" && len(args) > 0:
+		return errors.New("bucket is read from report, it can't be given with --report")
+	case freport == "" && len(args) == 0:
+		return errors.New("bucket or --report is required")
+	case freport != "" && fverify:
+		return errors.New("--verify recomputes report, it can't be given with --report")
+	case ffromFile != "" && (freport != "" || fverify):
+		return errors.New("--from-file can't be given with --report nor --verify")
+	case ffromFile != "" && strings.Contains(args[0], "/"):
+		return errors.New("keys of --from-file are full keys, bucket can't have prefix")
+	case freport != "":
+		return nil
+	default:
+		return diff.ValidateArgs(cmd, args)
+	}
+}
+
+func init() {
+	Cmd.Flags().StringVar(&freport, "report", "", "JSON report of ditto diff or ditto verify to repair, - reads standard input")
+	Cmd.Flags().StringVar(&ffromFile, "from-file", "", "file listing keys of bucket to repair, one per line, - reads standard input")
+	Cmd.Flags().StringVar(&fpolicy, "policy", "", "repair policy overriding Repair.Policy: prime, alter or merge")
+	Cmd.Flags().BoolVar(&fverify, "verify", false, "compare content of objects when recomputing report")
+	Cmd.Flags().BoolVarP(&fyes, "yes", "y", false, "don't ask for confirmation")
+	Cmd.Flags().BoolVar(&fdryRun, "dry-run", false, "only print actions and sizes of objects, nothing is changed")
+}
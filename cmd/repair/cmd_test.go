@@ -0,0 +1,117 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package repair
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/delta"
+	"storj.io/ditto/pkg/replication"
+)
+
+type handlerFunc func(ctx context.Context, task replication.Task) error
+
+func (f handlerFunc) Handle(ctx context.Context, task replication.Task) error {
+	return f(ctx, task)
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-repair")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// JSON output of `ditto diff`
+	file := filepath.Join(dir, "report.json")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(`{
+		"bucket": "bucket",
+		"prefix": "",
+		"onlyPrime": [{"key": "p", "prime": {"size": 1, "etag": "1"}}],
+		"onlyAlter": [{"key": "a", "alter": {"size": 1, "etag": "1"}}],
+		"differ": []
+	}`), 0644))
+
+	report, err := readReport(file)
+	assert.NoError(t, err)
+
+	handler := handlerFunc(func(ctx context.Context, task replication.Task) error {
+		if task.Object == "a" {
+			return errors.New("access denied")
+		}
+
+		return nil
+	})
+
+	repairer, err := delta.NewRepairer(delta.PrimeWins, handler, handler)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "text",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+
+				err := run(context.Background(), repairer, report, out, false)
+				assert.EqualError(t, err, "1 objects failed to be repaired")
+				assert.Equal(t, "bucket/p: copy to alter\n"+
+					"bucket/a: delete from alter failed: access denied\n"+
+					"repaired 1, failed 1\n", out.String())
+			},
+		},
+		{
+			testName: "json",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+
+				assert.Error(t, run(context.Background(), repairer, report, out, true))
+				assert.JSONEq(t, `{
+					"dryRun": false,
+					"repaired": 1,
+					"failed": 1,
+					"results": [
+						{"key": "p", "action": "copy to alter", "bytes": 1},
+						{"key": "a", "action": "delete from alter", "bytes": 1, "error": "access denied"}
+					]
+				}`, out.String())
+			},
+		},
+		{
+			testName: "dry run",
+			testFunc: func(t *testing.T) {
+				fdryRun = true
+				defer func() { fdryRun = false }()
+
+				out := &bytes.Buffer{}
+
+				assert.NoError(t, run(context.Background(), repairer.WithDryRun(true), report, out, false))
+				assert.Equal(t, "bucket/p: would copy to alter (1 bytes)\n"+
+					"bucket/a: would delete from alter (1 bytes)\n"+
+					"would repair 2 objects (2 bytes)\n", out.String())
+			},
+		},
+		{
+			testName: "invalid report",
+			testFunc: func(t *testing.T) {
+				invalid := filepath.Join(dir, "invalid.json")
+				assert.NoError(t, ioutil.WriteFile(invalid, []byte(`{"bucket": ""}`), 0644))
+
+				_, err := readReport(invalid)
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -4,55 +4,104 @@
 package cmd
 
 import (
+	"os"
+
+	"storj.io/ditto/cmd/accounting"
+	"storj.io/ditto/cmd/cat"
 	"storj.io/ditto/cmd/config"
 	"storj.io/ditto/cmd/cp"
+	"storj.io/ditto/cmd/deleteCmd"
+	"storj.io/ditto/cmd/diff"
+	"storj.io/ditto/cmd/du"
 	"storj.io/ditto/cmd/get"
+	"storj.io/ditto/cmd/initialize"
 	"storj.io/ditto/cmd/list"
 	"storj.io/ditto/cmd/make_bucket"
+	"storj.io/ditto/cmd/migrate"
+	"storj.io/ditto/cmd/presign"
+	"storj.io/ditto/cmd/prewarm"
 	"storj.io/ditto/cmd/put"
+	"storj.io/ditto/cmd/remove_bucket"
+	"storj.io/ditto/cmd/repair"
 	"storj.io/ditto/cmd/server"
+	"storj.io/ditto/cmd/stat"
+	"storj.io/ditto/cmd/state"
+	"storj.io/ditto/cmd/sync"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/cmd/verify"
 	"storj.io/ditto/cmd/version"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	dconfig "storj.io/ditto/pkg/config"
 	_ "storj.io/ditto/pkg/gateway"
 )
 
 var (
-	cfgFile string
+	cfgFile    string
+	cfgProfile string
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "ditto",
 	Short: "A backup mirroring util",
-	Long:  `A backup mirroring util`,
+	Long:  "A backup mirroring util\n\n" + utils.ExitCodes,
+	// usage is printed for invalid arguments and flags only, not when command fails
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return utils.ValidateErrorsFormat(utils.ErrorsFormat)
+	},
+	// error is printed by Execute, so it can be printed as JSON
+	SilenceErrors: true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the RootCmd.
+// Process exits with code of failed command, see utils.ExitCode.
 func Execute() {
 	addCommands()
-	rootCmd.Execute()
+
+	if cmd, err := rootCmd.ExecuteC(); err != nil {
+		utils.PrintError(cmd.Name(), err)
+		os.Exit(utils.ExitCode(err))
+	}
 }
 
 func addCommands() {
 	rootCmd.AddCommand(make_bucket.Cmd)
+	rootCmd.AddCommand(remove_bucket.Cmd)
 	rootCmd.AddCommand(cp.Cmd)
 	rootCmd.AddCommand(put.Cmd)
 	rootCmd.AddCommand(get.Cmd)
 	rootCmd.AddCommand(list.Cmd)
-	// rootCmd.AddCommand(delete.Cmd)
+	rootCmd.AddCommand(deleteCmd.Cmd)
 	rootCmd.AddCommand(version.Cmd)
+	rootCmd.AddCommand(initialize.Cmd)
 	rootCmd.AddCommand(config.Cmd)
 	rootCmd.AddCommand(server.Cmd)
+	rootCmd.AddCommand(sync.Cmd)
+	rootCmd.AddCommand(state.Cmd)
+	rootCmd.AddCommand(prewarm.Cmd)
+	rootCmd.AddCommand(accounting.Cmd)
+	rootCmd.AddCommand(diff.Cmd)
+	rootCmd.AddCommand(verify.Cmd)
+	rootCmd.AddCommand(repair.Cmd)
+	rootCmd.AddCommand(migrate.Cmd)
+	rootCmd.AddCommand(stat.Cmd)
+	rootCmd.AddCommand(du.Cmd)
+	rootCmd.AddCommand(cat.Cmd)
+	rootCmd.AddCommand(presign.Cmd)
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.mirroring/config.json)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file in JSON, YAML or TOML format (default is $HOME/.ditto/config.json)")
+	rootCmd.PersistentFlags().BoolVar(&utils.JSON, "json", false, "print output of ls, cp, diff, verify, repair, sync, stat, du, presign and config as JSON")
+	rootCmd.PersistentFlags().StringVar(&utils.ErrorsFormat, "errors", utils.ErrorsText, "format of errors printed to standard error: text or json, json emits a record per failure")
+	rootCmd.PersistentFlags().StringVar(&cfgProfile, "profile", "", "profile of config file to use, overrides "+dconfig.ProfileEnv)
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
@@ -61,4 +110,6 @@ func initConfig() {
 	if cfgFile != "" {
 		viper.Set("configPath", cfgFile)
 	}
+
+	dconfig.SetProfile(cfgProfile)
 }
@@ -0,0 +1,217 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package stat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/diff"
+	"storj.io/ditto/cmd/utils"
+)
+
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+var Cmd = &cobra.Command{
+	Use:   "stat <bucket>/<key>",
+	Short: "Shows info of object on prime and alter side by side",
+	Long: "Prints size, ETag, modification time, content type and metadata of object as seen by prime and alter, " +
+		"fields whose values differ are marked. Modification times aren't compared, alter is written after prime.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+// Info is info of object on single backend.
+type Info struct {
+	Size        int64             `json:"size"`
+	ETag        string            `json:"etag"`
+	ModTime     time.Time         `json:"modTime"`
+	ContentType string            `json:"contentType,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Stat is info of object on both backends, nil info means object is missing on backend.
+// Differ lists fields whose values differ.
+type Stat struct {
+	Bucket string   `json:"bucket"`
+	Key    string   `json:"key"`
+	Prime  *Info    `json:"prime"`
+	Alter  *Info    `json:"alter"`
+	Differ []string `json:"differ"`
+}
+
+// field is a compared line of stat.
+type field struct {
+	name         string
+	prime, alter string
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	bucket, key := diff.SplitPath(args[0])
+
+	s, err := stat(context.Background(), prime, alter, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if utils.JSON {
+		return utils.PrintJSON(os.Stdout, s)
+	}
+
+	return printStat(os.Stdout, s)
+}
+
+// stat gets info of object from both backends, object missing on both is an error.
+func stat(ctx context.Context, prime, alter minio.ObjectLayer, bucket, key string) (*Stat, error) {
+	s := &Stat{Bucket: bucket, Key: key}
+
+	for _, backend := range []struct {
+		name  string
+		ol    minio.ObjectLayer
+		field **Info
+	}{
+		{"prime", prime, &s.Prime},
+		{"alter", alter, &s.Alter},
+	} {
+		oi, err := backend.ol.GetObjectInfo(ctx, bucket, key, minio.ObjectOptions{})
+		if err != nil {
+			if _, ok := err.(minio.ObjectNotFound); ok {
+				continue
+			}
+
+			return nil, fmt.Errorf("%s: %s", backend.name, err)
+		}
+
+		*backend.field = &Info{
+			Size:        oi.Size,
+			ETag:        oi.ETag,
+			ModTime:     oi.ModTime,
+			ContentType: oi.ContentType,
+			Metadata:    oi.UserDefined,
+		}
+	}
+
+	if s.Prime == nil && s.Alter == nil {
+		return nil, minio.ObjectNotFound{Bucket: bucket, Object: key}
+	}
+
+	s.Differ = []string{}
+	for _, f := range fields(s) {
+		if f.prime != f.alter && f.name != "modified" {
+			s.Differ = append(s.Differ, f.name)
+		}
+	}
+
+	return s, nil
+}
+
+// fields returns lines of stat, values of missing object are empty.
+func fields(s *Stat) []field {
+	prime, alter := s.Prime, s.Alter
+	if prime == nil {
+		prime = &Info{}
+	}
+	if alter == nil {
+		alter = &Info{}
+	}
+
+	fields := []field{
+		{"exists", strconv.FormatBool(s.Prime != nil), strconv.FormatBool(s.Alter != nil)},
+		{"size", strconv.FormatInt(prime.Size, 10), strconv.FormatInt(alter.Size, 10)},
+		{"etag", prime.ETag, alter.ETag},
+		{"modified", formatTime(prime.ModTime), formatTime(alter.ModTime)},
+		{"content-type", prime.ContentType, alter.ContentType},
+	}
+
+	keys := make(map[string]bool)
+	for k := range prime.Metadata {
+		keys[k] = true
+	}
+	for k := range alter.Metadata {
+		keys[k] = true
+	}
+
+	var sorted []string
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		fields = append(fields, field{"meta " + k, prime.Metadata[k], alter.Metadata[k]})
+	}
+
+	return fields
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// printStat prints fields of stat as table, differing fields are marked.
+func printStat(out io.Writer, s *Stat) error {
+	differ := make(map[string]bool)
+	for _, name := range s.Differ {
+		differ[name] = true
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "%s/%s\tprime\talter\t\n", s.Bucket, s.Key)
+
+	for _, f := range fields(s) {
+		mark := ""
+		if differ[f.name] {
+			mark = "differs"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.name, value(f.prime), value(f.alter), mark)
+	}
+
+	return w.Flush()
+}
+
+func value(v string) string {
+	if v == "" {
+		return "-"
+	}
+
+	return v
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("bucket/key is required")
+	case 1:
+		bucket, key := diff.SplitPath(args[0])
+		if err := s3utils.CheckValidBucketName(bucket); err != nil {
+			return err
+		}
+
+		return s3utils.CheckValidObjectName(key)
+	default:
+		return errors.New("too many arguments")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package stat
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestStat(t *testing.T) {
+	modTime := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	layer := func(infos map[string]minio.ObjectInfo) minio.ObjectLayer {
+		ol := test.NewProxyObjectLayer()
+		ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			oi, ok := infos[object]
+			if !ok {
+				return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+			}
+
+			return oi, nil
+		}
+
+		return ol
+	}
+
+	prime := layer(map[string]minio.ObjectInfo{
+		"a": {Size: 3, ETag: "1", ModTime: modTime, ContentType: "text/plain", UserDefined: map[string]string{"X-Amz-Meta-Owner": "ann"}},
+		"b": {Size: 3, ETag: "1", ModTime: modTime},
+	})
+	alter := layer(map[string]minio.ObjectInfo{
+		"a": {Size: 3, ETag: "2", ModTime: modTime.Add(time.Second), ContentType: "text/plain"},
+	})
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "differ",
+			testFunc: func(t *testing.T) {
+				s, err := stat(context.Background(), prime, alter, "bucket", "a")
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"etag", "meta X-Amz-Meta-Owner"}, s.Differ)
+
+				out := &bytes.Buffer{}
+				assert.NoError(t, printStat(out, s))
+				assert.Equal(t, "bucket/a               prime                 alter                 \n"+
+					"exists                 true                  true                  \n"+
+					"size                   3                     3                     \n"+
+					"etag                   1                     2                     differs\n"+
+					"modified               2018-10-01T12:00:00Z  2018-10-01T12:00:01Z  \n"+
+					"content-type           text/plain            text/plain            \n"+
+					"meta X-Amz-Meta-Owner  ann                   -                     differs\n", out.String())
+			},
+		},
+		{
+			testName: "missing on alter",
+			testFunc: func(t *testing.T) {
+				s, err := stat(context.Background(), prime, alter, "bucket", "b")
+				assert.NoError(t, err)
+				assert.Nil(t, s.Alter)
+				assert.Equal(t, []string{"exists", "size", "etag"}, s.Differ)
+			},
+		},
+		{
+			testName: "missing on both",
+			testFunc: func(t *testing.T) {
+				_, err := stat(context.Background(), prime, alter, "bucket", "c")
+				assert.IsType(t, minio.ObjectNotFound{}, err)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	assert.NoError(t, validateArgs(nil, []string{"bucket/dir/key"}))
+	assert.Error(t, validateArgs(nil, []string{"bucket"}))
+	assert.Error(t, validateArgs(nil, []string{"bucket/"}))
+	assert.Error(t, validateArgs(nil, []string{"b/key"}))
+	assert.Error(t, validateArgs(nil, []string{"bucket/a", "bucket/b"}))
+}
@@ -0,0 +1,178 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/state"
+)
+
+// source is a state database, either opened directly or queried through admin API of running gateway.
+type source interface {
+	Counts() (map[state.Status]int, error)
+	List(status state.Status, limit int) ([]state.Record, error)
+	Get(bucket, object string) (state.Record, bool, error)
+}
+
+var flimit int
+
+var Cmd = &cobra.Command{
+	Use:   "state [status] | [bucket object]",
+	Short: "Displays replication status of objects",
+	Long: "Displays amount of objects per replication status, objects with status " +
+		"(in-sync, pending, failed, diverged, deleted) or status of a single object. " +
+		"Admin API of running gateway is queried if admin address is configured, " +
+		"otherwise state database is opened directly.",
+	Args: cobra.MaximumNArgs(2),
+	RunE: exec,
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	cfg, err := config.ReadConfig(true)
+	if err != nil {
+		return err
+	}
+
+	src, closeSrc, err := openSource(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
+
+	switch len(args) {
+	case 0:
+		counts, err := src.Counts()
+		if err != nil {
+			return err
+		}
+
+		for _, s := range []state.Status{state.IN_SYNC, state.PENDING, state.FAILED, state.DIVERGED, state.DELETED} {
+			fmt.Printf("%s: %d\n", s, counts[s])
+		}
+	case 1:
+		records, err := src.List(state.Status(args[0]), flimit)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range records {
+			printRecord(r)
+		}
+	default:
+		r, ok, err := src.Get(args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return fmt.Errorf("%s/%s is not tracked", args[0], args[1])
+		}
+
+		printRecord(r)
+	}
+
+	return nil
+}
+
+func printRecord(r state.Record) {
+	line := fmt.Sprintf("%-9s %s/%s %s", r.Status, r.Bucket, r.Object, r.Updated.Format("2006-01-02 15:04:05"))
+	if r.Error != "" {
+		line += " " + r.Error
+	}
+
+	fmt.Println(line)
+}
+
+func openSource(cfg *config.Config) (source, func(), error) {
+	if cfg.Admin != nil && cfg.Admin.Address != "" {
+		return &adminSource{baseURL: adminURL(cfg.Admin.Address), token: cfg.Admin.Token}, func() {}, nil
+	}
+
+	if cfg.State == nil || cfg.State.Path == "" {
+		return nil, nil, errors.New("state tracking is not configured")
+	}
+
+	db, err := state.OpenReadOnly(cfg.State.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db, func() { db.Close() }, nil
+}
+
+// adminURL returns URL of state endpoint, address without host refers to local gateway.
+func adminURL(address string) string {
+	if strings.HasPrefix(address, ":") {
+		address = "localhost" + address
+	}
+
+	return "http://" + address + "/state"
+}
+
+// adminSource queries state endpoint of admin API.
+type adminSource struct {
+	baseURL string
+	token   string
+}
+
+func (a *adminSource) Counts() (map[state.Status]int, error) {
+	counts := map[state.Status]int{}
+	_, err := a.get(url.Values{}, &counts)
+
+	return counts, err
+}
+
+func (a *adminSource) List(status state.Status, limit int) ([]state.Record, error) {
+	var records []state.Record
+	_, err := a.get(url.Values{"status": {string(status)}, "limit": {strconv.Itoa(limit)}}, &records)
+
+	return records, err
+}
+
+func (a *adminSource) Get(bucket, object string) (state.Record, bool, error) {
+	var r state.Record
+	ok, err := a.get(url.Values{"bucket": {bucket}, "object": {object}}, &r)
+
+	return r, ok, err
+}
+
+// get decodes response into v, returns false if object is not found.
+func (a *adminSource) get(query url.Values, v interface{}) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, a.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return false, err
+	}
+
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, json.NewDecoder(resp.Body).Decode(v)
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("admin API responded %s", resp.Status)
+	}
+}
+
+func init() {
+	Cmd.Flags().IntVarP(&flimit, "limit", "l", 100, "maximum amount of listed objects, 0 lists all")
+}
@@ -0,0 +1,287 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/checkpoint"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/delta"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/objlayer/mirroring"
+	"storj.io/ditto/pkg/progress"
+	"storj.io/ditto/pkg/replication"
+	"storj.io/ditto/pkg/schedule"
+)
+
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+// newLogger creates logger of sync engine with level configured for sync module.
+func newLogger(cfg *config.Config) (l.Logger, error) {
+	logger, err := l.New(cfg.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.ForModule(logger, cfg.Log, l.SyncModule)
+}
+
+var (
+	fprefix, fcheckpoint, fschedule  string
+	fbwlimit                         string
+	fdelete, fdryRun, fwatch, fquiet bool
+	finterval                        time.Duration
+	ffilter                          utils.FilterFlags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "sync [bucket(OPTIONAL)]",
+	Short: "Copies objects missing or changed on alter from prime",
+	Long: "Lists prime and alter, compares objects by key, size and ETag and copies only what changed. " +
+		"Syncs all prime buckets if bucket is not specified. With --watch sync is repeated every --interval, " +
+		"or on cron --schedule, until interrupted. Interval and schedule default to Sync.Interval and Sync.Schedule " +
+		"of config, so gateway doesn't need to run to keep backends in sync. Single sync renders progress of copied " +
+		"objects to terminal and prints summary once finished, unless --quiet is given. --bwlimit caps bandwidth " +
+		"of objects copied to alter, e.g. 10M, so sync doesn't saturate the network. --include, --exclude, " +
+		"--min-size, --max-size, --newer-than and --older-than select objects which are synced, objects " +
+		"filtered out are neither copied nor deleted.",
+	Args: validateArgs,
+	RunE: exec,
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	cfg, err := config.ReadConfig(true)
+	if err != nil {
+		return err
+	}
+
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	if alter, err = utils.LimitBandwidth(alter, fbwlimit); err != nil {
+		return err
+	}
+
+	logger, err := newLogger(cfg)
+	if err != nil {
+		return err
+	}
+
+	tracker := progress.NewTracker(utils.ProgressOutput(fquiet))
+
+	var handler replication.Handler = mirroring.NewReplicationHandler(prime, alter)
+	if !fwatch {
+		handler = tracker.Handler(mirroring.NewReplicationHandler(prime, tracker.Layer(alter)))
+	}
+
+	engine := delta.NewEngine(prime, alter, handler, &config.SyncOptions{Delete: fdelete}, logger)
+	engine.WithDryRun(fdryRun)
+
+	if fcheckpoint != "" {
+		cp, err := checkpoint.Open(fcheckpoint)
+		if err != nil {
+			return err
+		}
+		defer cp.Close()
+
+		engine.WithCheckpoint(cp)
+	}
+
+	round := func(ctx context.Context) (delta.Stats, error) {
+		// ages given by filter are relative to start of every round
+		f, err := ffilter.Filter(time.Now())
+		if err != nil {
+			return delta.Stats{}, err
+		}
+
+		engine.WithFilter(f)
+
+		if len(args) == 0 {
+			return engine.SyncAll(ctx)
+		}
+
+		return engine.Sync(ctx, args[0], fprefix)
+	}
+
+	if !fwatch {
+		tracker.Start()
+		stats, err := round(context.Background())
+		summary := tracker.Stop()
+
+		if utils.JSON {
+			if jerr := utils.PrintJSON(os.Stdout, stats); jerr != nil {
+				return jerr
+			}
+		} else {
+			fmt.Println(stats)
+
+			if !fquiet {
+				fmt.Println(summary)
+			}
+		}
+
+		if err == nil && stats.Failed > 0 {
+			err = utils.WithExitCode(utils.ExitPartial, fmt.Errorf("%d objects failed to sync", stats.Failed))
+		}
+
+		return err
+	}
+
+	next, err := nextRound(cfg.Sync)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigc
+		cancel()
+	}()
+
+	watch(ctx, round, next, os.Stdout, utils.JSON)
+
+	return nil
+}
+
+// nextRound returns function computing start of next round of watch mode from end of previous one,
+// on --schedule or every --interval, which default to schedule and interval of opts.
+func nextRound(opts *config.SyncOptions) (func(time.Time) time.Time, error) {
+	if opts == nil {
+		opts = &config.SyncOptions{}
+	}
+
+	spec, interval := fschedule, finterval
+
+	if spec == "" && interval == 0 {
+		spec, interval = opts.Schedule, opts.Interval
+	}
+
+	if spec != "" {
+		s, err := schedule.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.Next, nil
+	}
+
+	if interval <= 0 {
+		interval = delta.DefaultInterval
+	}
+
+	return func(t time.Time) time.Time { return t.Add(interval) }, nil
+}
+
+// Round is JSON output of single round of watch mode, printed as a line.
+type Round struct {
+	Time  time.Time    `json:"time"`
+	Stats *delta.Stats `json:"stats,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+// watch runs round immediately and then at times computed by next until ctx is done.
+// Stats of every round are printed to out, as JSON lines if asJSON is set. Failed round doesn't stop watching.
+func watch(ctx context.Context, round func(context.Context) (delta.Stats, error), next func(time.Time) time.Time, out io.Writer, asJSON bool) {
+	for {
+		stats, err := round(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := time.Now()
+
+		switch {
+		case asJSON && err != nil:
+			json.NewEncoder(out).Encode(Round{Time: now, Error: err.Error()})
+		case asJSON:
+			json.NewEncoder(out).Encode(Round{Time: now, Stats: &stats})
+		case err != nil:
+			fmt.Fprintf(out, "%s sync failed: %s\n", now.Format(time.RFC3339), err)
+		default:
+			fmt.Fprintf(out, "%s %s\n", now.Format(time.RFC3339), stats)
+		}
+
+		timer := time.NewTimer(next(now).Sub(now))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	if _, err := utils.ParseBandwidth(fbwlimit); err != nil {
+		return err
+	}
+
+	if _, err := ffilter.Filter(time.Now()); err != nil {
+		return err
+	}
+
+	switch len(args) {
+	case 0:
+		if fprefix != "" {
+			return errors.New("prefix requires bucket")
+		}
+
+		return validateWatchArgs()
+	case 1:
+		if err := s3utils.CheckValidBucketName(args[0]); err != nil {
+			return err
+		}
+
+		return validateWatchArgs()
+	default:
+		return errors.New("too many arguments")
+	}
+}
+
+func validateWatchArgs() error {
+	switch {
+	case !fwatch && (fschedule != "" || finterval != 0):
+		return errors.New("--interval and --schedule require --watch")
+	case fschedule != "" && finterval != 0:
+		return errors.New("--interval and --schedule are mutually exclusive")
+	case finterval < 0:
+		return errors.New("--interval must be positive")
+	}
+
+	return nil
+}
+
+func init() {
+	Cmd.Flags().StringVarP(&fprefix, "prefix", "p", "", "sync only objects under prefix")
+	Cmd.Flags().BoolVar(&fdelete, "delete", false, "delete objects which exist only on alter")
+	Cmd.Flags().BoolVar(&fdryRun, "dry-run", false, "only print differences")
+	Cmd.Flags().StringVar(&fcheckpoint, "checkpoint", "", "file persisting progress, interrupted sync resumes from it")
+	Cmd.Flags().BoolVar(&fwatch, "watch", false, "repeat sync until interrupted")
+	Cmd.Flags().BoolVarP(&fquiet, "quiet", "q", false, "don't render progress nor print summary")
+	Cmd.Flags().DurationVar(&finterval, "interval", 0, "time between syncs in watch mode, defaults to Sync.Interval")
+	Cmd.Flags().StringVar(&fschedule, "schedule", "", "cron schedule of syncs in watch mode, e.g. \"0 2 * * *\", defaults to Sync.Schedule")
+	ffilter.Register(Cmd)
+	Cmd.Flags().StringVar(&fbwlimit, "bwlimit", "", "bytes per second copied to alter, e.g. 512K or 10M, unlimited by default")
+}
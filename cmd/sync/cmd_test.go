@@ -0,0 +1,98 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/delta"
+)
+
+func TestWatch(t *testing.T) {
+	// watch runs three rounds, the first succeeds, the second fails and the third cancels watching
+	watchRounds := func(asJSON bool) []string {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rounds := 0
+		round := func(ctx context.Context) (delta.Stats, error) {
+			rounds++
+
+			switch rounds {
+			case 1:
+				return delta.Stats{Missing: 1, Copied: 1}, nil
+			case 2:
+				return delta.Stats{}, errors.New("prime is unreachable")
+			default:
+				cancel()
+				return delta.Stats{}, ctx.Err()
+			}
+		}
+
+		out := &bytes.Buffer{}
+		watch(ctx, round, func(t time.Time) time.Time { return t.Add(time.Millisecond) }, out, asJSON)
+
+		assert.Equal(t, 3, rounds)
+
+		return strings.Split(strings.TrimSpace(out.String()), "\n")
+	}
+
+	lines := watchRounds(false)
+
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], " missing 1, changed 0, extra 0, copied 1, deleted 0, failed 0")
+	assert.Contains(t, lines[1], " sync failed: prime is unreachable")
+
+	lines = watchRounds(true)
+
+	var r Round
+
+	assert.Len(t, lines, 2)
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &r))
+	assert.Equal(t, &delta.Stats{Missing: 1, Copied: 1}, r.Stats)
+	assert.Contains(t, lines[0], `"stats":{"missing":1,"changed":0,"extra":0,"copied":1,"deleted":0,"failed":0}`)
+	assert.Contains(t, lines[1], `"error":"prime is unreachable"`)
+}
+
+func TestNextRound(t *testing.T) {
+	defer func() {
+		finterval, fschedule = 0, ""
+	}()
+
+	now := time.Date(2018, 10, 1, 12, 30, 0, 0, time.UTC)
+	opts := &config.SyncOptions{Interval: time.Minute}
+
+	next, err := nextRound(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), next(now))
+
+	next, err = nextRound(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(delta.DefaultInterval), next(now))
+
+	fschedule = "0 2 * * *"
+
+	next, err = nextRound(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2018, 10, 2, 2, 0, 0, 0, time.UTC), next(now))
+
+	fschedule, finterval = "", 5*time.Second
+
+	next, err = nextRound(&config.SyncOptions{Schedule: "0 2 * * *"})
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(5*time.Second), next(now))
+
+	fschedule, finterval = "invalid", 0
+
+	_, err = nextRound(opts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"fmt"
+
+	minio "github.com/minio/minio/cmd"
+	"storj.io/ditto/pkg/objlayer/throttle"
+	"storj.io/ditto/pkg/ratelimit"
+)
+
+// ParseBandwidth parses bytes per second given by --bwlimit, e.g. 10M. Number may be followed by
+// binary unit as in ParseSize. Empty string and 0 mean unlimited.
+func ParseBandwidth(s string) (int64, error) {
+	bandwidth, ok := parseBytes(s)
+	if !ok {
+		return 0, fmt.Errorf("bandwidth %q is invalid, expected bytes per second, e.g. 512K or 10M", s)
+	}
+
+	return bandwidth, nil
+}
+
+// LimitBandwidth wraps ol so data uploaded through it is streamed no faster than bwlimit parsed by ParseBandwidth.
+// Limit is shared by all concurrent uploads, ol is returned as is if bwlimit is unlimited.
+func LimitBandwidth(ol minio.ObjectLayer, bwlimit string) (minio.ObjectLayer, error) {
+	bandwidth, err := ParseBandwidth(bwlimit)
+	if err != nil || bandwidth == 0 {
+		return ol, err
+	}
+
+	return throttle.NewBandwidthLayer(ol, ratelimit.NewLimiter(float64(bandwidth), 0)), nil
+}
@@ -0,0 +1,38 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	cases := []struct {
+		s         string
+		bandwidth int64
+	}{
+		{"", 0},
+		{"0", 0},
+		{"1000", 1000},
+		{"512K", 512 << 10},
+		{"10M", 10 << 20},
+		{"10mb", 10 << 20},
+		{"1.5M", 3 << 19},
+		{"1G", 1 << 30},
+	}
+
+	for _, c := range cases {
+		bandwidth, err := ParseBandwidth(c.s)
+
+		assert.NoError(t, err, c.s)
+		assert.Equal(t, c.bandwidth, bandwidth, c.s)
+	}
+
+	for _, s := range []string{"M", "10X", "-1M", "ten"} {
+		_, err := ParseBandwidth(s)
+		assert.EqualError(t, err, "bandwidth \""+s+"\" is invalid, expected bytes per second, e.g. 512K or 10M")
+	}
+}
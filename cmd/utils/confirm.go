@@ -0,0 +1,93 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Confirm asks whether destructive operation described by summary, e.g. "delete 12,431 objects of bucket
+// from 2 backends", should proceed.
+type Confirm func(summary string) (bool, error)
+
+// ErrNotConfirmed is returned by destructive commands if user declined the operation.
+var ErrNotConfirmed = errors.New("not confirmed, nothing changed")
+
+// NewConfirm returns confirmation of destructive commands, nil if yes is set by --yes, so nothing is asked.
+// User is asked on standard error unless standard input isn't a terminal, then nobody can answer
+// and operation is refused.
+func NewConfirm(yes bool) Confirm {
+	switch {
+	case yes:
+		return nil
+	case !terminal.IsTerminal(int(os.Stdin.Fd())):
+		return Refuse
+	default:
+		return Prompt(os.Stdin, os.Stderr)
+	}
+}
+
+// Prompt returns confirmation asking user on out and reading answer from in.
+func Prompt(in io.Reader, out io.Writer) Confirm {
+	reader := bufio.NewReader(in)
+
+	return func(summary string) (bool, error) {
+		fmt.Fprintf(out, "%s? [y/N] ", summary)
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+
+		answer = strings.ToLower(strings.TrimSpace(answer))
+
+		return answer == "y" || answer == "yes", nil
+	}
+}
+
+// Refuse is confirmation used when user can't be asked, e.g. standard input is redirected.
+func Refuse(summary string) (bool, error) {
+	return false, fmt.Errorf("%s needs confirmation, confirm it with --yes", summary)
+}
+
+// Ask asks confirm about summary, nil confirm confirms everything. ErrNotConfirmed is returned if user declines.
+func Ask(confirm Confirm, summary string) error {
+	if confirm == nil {
+		return nil
+	}
+
+	ok, err := confirm(summary)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrNotConfirmed
+	}
+
+	return nil
+}
+
+// FormatCount formats n with thousands separators, e.g. 12,431.
+func FormatCount(n int) string {
+	if n < 0 {
+		return "-" + FormatCount(-n)
+	}
+
+	s := strconv.Itoa(n)
+
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
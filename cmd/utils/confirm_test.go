@@ -0,0 +1,36 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsk(t *testing.T) {
+	for answer, confirmed := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		out := &bytes.Buffer{}
+
+		err := Ask(Prompt(strings.NewReader(answer), out), "delete 2 objects of bucket from 2 backends")
+		assert.Equal(t, "delete 2 objects of bucket from 2 backends? [y/N] ", out.String())
+
+		if confirmed {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, ErrNotConfirmed, err)
+		}
+	}
+
+	assert.EqualError(t, Ask(Refuse, "delete 2 objects"), "delete 2 objects needs confirmation, confirm it with --yes")
+	assert.NoError(t, Ask(nil, "delete 2 objects"))
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, expected := range map[int]string{0: "0", 999: "999", 1000: "1,000", 12431: "12,431", 1234567: "1,234,567", -1000: "-1,000"} {
+		assert.Equal(t, expected, FormatCount(n))
+	}
+}
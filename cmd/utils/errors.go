@@ -0,0 +1,111 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"storj.io/ditto/pkg/errclass"
+)
+
+// Formats of errors selected by global --errors flag.
+const (
+	ErrorsText = "text"
+	ErrorsJSON = "json"
+)
+
+// ErrorsFormat is set by global --errors flag. With ErrorsJSON failures are emitted to standard error
+// as JSON records, a line each, instead of text.
+var ErrorsFormat = ErrorsText
+
+// Output of failure records, listed as var for testing purposes only
+var errorsOutput io.Writer = os.Stderr
+
+var errorsMu sync.Mutex
+
+// Failure is record of failed operation emitted with --errors json, field names are stable.
+// Record of error command failed with is emitted last and has ExitCode set.
+type Failure struct {
+	Op        string `json:"op"`
+	Bucket    string `json:"bucket,omitempty"`
+	Key       string `json:"key,omitempty"`
+	Backend   string `json:"backend,omitempty"`
+	Class     string `json:"class"`
+	Retryable bool   `json:"retryable"`
+	Error     string `json:"error"`
+	ExitCode  int    `json:"exitCode,omitempty"`
+}
+
+// NewFailure creates record of op on key of bucket failed with err, backend is empty if it isn't known.
+func NewFailure(op, bucket, key, backend string, err error) Failure {
+	return Failure{
+		Op:        op,
+		Bucket:    bucket,
+		Key:       key,
+		Backend:   backend,
+		Class:     errclass.Classify(err).String(),
+		Retryable: errclass.IsRetryable(err),
+		Error:     err.Error(),
+	}
+}
+
+// ValidateErrorsFormat checks value of --errors flag.
+func ValidateErrorsFormat(format string) error {
+	if format != ErrorsText && format != ErrorsJSON {
+		return fmt.Errorf("--errors %q is unknown, expected %s or %s", format, ErrorsText, ErrorsJSON)
+	}
+
+	return nil
+}
+
+// ReportFailure emits record of failure of single object if --errors json is set, commands still print
+// failures as text to their output. Safe for concurrent use.
+func ReportFailure(op, bucket, key, backend string, err error) {
+	if ErrorsFormat != ErrorsJSON || err == nil {
+		return
+	}
+
+	emit(NewFailure(op, bucket, key, backend, err))
+}
+
+// ReportFailureMessage is ReportFailure for failure known only by its message, e.g. read from report.
+// Message prefixed by backend, e.g. "alter: read failed", sets backend of the record.
+func ReportFailureMessage(op, bucket, key, message string) {
+	backend := ""
+
+	for _, b := range []string{"prime", "alter"} {
+		if strings.HasPrefix(message, b+": ") {
+			backend, message = b, strings.TrimPrefix(message, b+": ")
+			break
+		}
+	}
+
+	ReportFailure(op, bucket, key, backend, errors.New(message))
+}
+
+// PrintError prints error command op failed with to standard error, as record with exit code if --errors json is set.
+func PrintError(op string, err error) {
+	if ErrorsFormat != ErrorsJSON {
+		fmt.Fprintln(errorsOutput, "Error:", err)
+		return
+	}
+
+	f := NewFailure(op, "", "", "", err)
+	f.ExitCode = ExitCode(err)
+
+	emit(f)
+}
+
+func emit(f Failure) {
+	errorsMu.Lock()
+	defer errorsMu.Unlock()
+
+	json.NewEncoder(errorsOutput).Encode(f)
+}
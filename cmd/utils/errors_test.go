@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailures(t *testing.T) {
+	out := &bytes.Buffer{}
+	errorsOutput = out
+
+	defer func() {
+		errorsOutput, ErrorsFormat = os.Stderr, ErrorsText
+	}()
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "text",
+			testFunc: func(t *testing.T) {
+				ErrorsFormat = ErrorsText
+
+				ReportFailure("delete", "bucket", "a", "", errors.New("access denied"))
+				PrintError("rm", errors.New("1 objects failed to be deleted"))
+
+				assert.Equal(t, "Error: 1 objects failed to be deleted\n", out.String())
+			},
+		},
+		{
+			testName: "json",
+			testFunc: func(t *testing.T) {
+				ErrorsFormat = ErrorsJSON
+
+				ReportFailure("delete", "bucket", "a", "prime", minio.BucketNotFound{Bucket: "bucket"})
+				ReportFailureMessage("verify", "bucket", "b", "alter: read failed")
+				PrintError("rm", WithExitCode(ExitPartial, fmt.Errorf("1 objects failed to be deleted")))
+
+				assert.Equal(t, `{"op":"delete","bucket":"bucket","key":"a","backend":"prime","class":"config",`+
+					`"retryable":false,"error":"Bucket not found: bucket"}`+"\n"+
+					`{"op":"verify","bucket":"bucket","key":"b","backend":"alter","class":"unknown",`+
+					`"retryable":true,"error":"read failed"}`+"\n"+
+					`{"op":"rm","class":"unknown","retryable":true,"error":"1 objects failed to be deleted","exitCode":2}`+"\n",
+					out.String())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out.Reset()
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestValidateErrorsFormat(t *testing.T) {
+	assert.NoError(t, ValidateErrorsFormat("text"))
+	assert.NoError(t, ValidateErrorsFormat("json"))
+	assert.EqualError(t, ValidateErrorsFormat("xml"), `--errors "xml" is unknown, expected text or json`)
+}
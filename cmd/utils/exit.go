@@ -0,0 +1,85 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/errclass"
+)
+
+// Exit codes of ditto. They are part of its interface, scripts and cron jobs branch on them,
+// so existing codes must not be renumbered.
+const (
+	// ExitOK means that command succeeded.
+	ExitOK = 0
+	// ExitFailure means failure not covered by other codes, e.g. invalid arguments.
+	ExitFailure = 1
+	// ExitPartial means that some objects of bulk operation failed while others succeeded.
+	ExitPartial = 2
+	// ExitConfig means that config can't be read or is invalid, or backend rejected it,
+	// e.g. credentials are denied or bucket doesn't exist.
+	ExitConfig = 3
+	// ExitUnreachable means that backend couldn't be reached.
+	ExitUnreachable = 4
+	// ExitMismatch means that diff or verification found differences between backends.
+	ExitMismatch = 5
+)
+
+// ExitCodes describes exit codes for help of root command.
+const ExitCodes = `Exit codes:
+  0  success
+  1  failure, e.g. invalid arguments
+  2  partial failure, some objects failed while others succeeded
+  3  config error, config is unreadable or invalid, or backend rejected credentials or bucket
+  4  backend unreachable
+  5  verification mismatch, backends differ`
+
+// ExitError is error of command carrying its exit code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying error.
+func (e *ExitError) Cause() error {
+	return e.Err
+}
+
+// WithExitCode returns err which makes ditto exit with code, nil if err is nil.
+func WithExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns exit code of command failed with err. Code given by WithExitCode takes precedence,
+// other errors are classified as config errors, unreachable backends or generic failures.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	switch e := err.(type) {
+	case *ExitError:
+		return e.Code
+	case *config.ReadError, *config.ValidationError:
+		return ExitConfig
+	}
+
+	if errclass.IsConnectionError(err) {
+		return ExitUnreachable
+	}
+
+	if errclass.Classify(err) == errclass.CONFIG {
+		return ExitConfig
+	}
+
+	return ExitFailure
+}
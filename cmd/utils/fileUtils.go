@@ -35,9 +35,13 @@ func GetObjectLayer() (minio.ObjectLayer, error) {
 		return nil, err
 	}
 
-	logger := l.StdOutLogger
-	mirroring := &gateway.Mirroring{Logger: &logger, Config: defaultConfig}
-	objLayer, err := mirroring.NewGatewayLayer(auth.Credentials{})
+	logger, err := l.New(defaultConfig.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	mirroring := &gateway.Mirroring{Logger: logger, Config: defaultConfig}
+	objLayer, err := mirroring.NewObjectLayer()
 
 	if err != nil {
 		return nil, err
@@ -46,6 +50,71 @@ func GetObjectLayer() (minio.ObjectLayer, error) {
 	return objLayer, nil
 }
 
+// GetStreamingObjectLayer returns object layer like GetObjectLayer for commands writing data to standard output.
+// Logs which would be written to standard output are written to standard error, so they don't mix with data.
+func GetStreamingObjectLayer() (minio.ObjectLayer, error) {
+	defaultConfig, err := config.ReadConfig(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var logger l.Logger
+
+	if opts := defaultConfig.Log; opts != nil && (opts.Journald || opts.Syslog != "" || opts.File != "") {
+		logger, err = l.New(opts)
+	} else {
+		level, format := l.InfoLevel, l.ConsoleFormat
+		if opts != nil {
+			if level, err = l.ParseLevel(opts.Level); err != nil {
+				return nil, err
+			}
+			format = opts.Format
+		}
+
+		logger, err = l.NewStructured(os.Stderr, level, format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	mirroring := &gateway.Mirroring{Logger: logger, Config: defaultConfig}
+
+	return mirroring.NewObjectLayer()
+}
+
+// GetBackends returns prime and alter object layers configured for direct use, bypassing mirroring.
+func GetBackends() (minio.ObjectLayer, minio.ObjectLayer, error) {
+	defaultConfig, err := config.ReadConfig(true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger, err := l.New(defaultConfig.Log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mirroring := &gateway.Mirroring{Logger: logger, Config: defaultConfig}
+
+	return mirroring.NewBackends()
+}
+
+// GetLogger returns logger of command line with level configured for it.
+func GetLogger() (l.Logger, error) {
+	defaultConfig, err := config.ReadConfig(true)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := l.New(defaultConfig.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.ForModule(logger, defaultConfig.Log, l.CLIModule)
+}
+
 type GetwayResolver func(l.Logger) (minio.Gateway, error)
 
 func GetGateway(logger l.Logger) (minio.Gateway, error) {
@@ -54,7 +123,17 @@ func GetGateway(logger l.Logger) (minio.Gateway, error) {
 		return nil, err
 	}
 
-	return &gateway.Mirroring{Logger: logger, Config: defaultConfig}, nil
+	return commandGateway{&gateway.Mirroring{Logger: logger, Config: defaultConfig}}, nil
+}
+
+// commandGateway creates layer of gateway for commands, which doesn't start services of server.
+type commandGateway struct {
+	*gateway.Mirroring
+}
+
+// NewGatewayLayer implements minio.Gateway interface
+func (gw commandGateway) NewGatewayLayer(creds auth.Credentials) (minio.ObjectLayer, error) {
+	return gw.NewObjectLayer()
 }
 
 func GetObjectName(fname, prefix, delimiter string) string {
@@ -0,0 +1,73 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"storj.io/ditto/pkg/filter"
+)
+
+// FilterFlags are flags selecting listed objects by key, size and modification time, shared by commands
+// copying many objects.
+type FilterFlags struct {
+	Include, Exclude     []string
+	MinSize, MaxSize     string
+	NewerThan, OlderThan string
+}
+
+// Register adds filter flags to cmd.
+func (f *FilterFlags) Register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.Include, "include", nil, "only objects matching pattern, e.g. *.log, can be repeated")
+	cmd.Flags().StringArrayVar(&f.Exclude, "exclude", nil, "skip objects matching pattern, can be repeated, wins over --include")
+	cmd.Flags().StringVar(&f.MinSize, "min-size", "", "only objects of at least given size, e.g. 512K or 10M")
+	cmd.Flags().StringVar(&f.MaxSize, "max-size", "", "only objects of at most given size, e.g. 512K or 10M")
+	cmd.Flags().StringVar(&f.NewerThan, "newer-than", "", "only objects modified after age or date, e.g. 7d or 2018-09-30")
+	cmd.Flags().StringVar(&f.OlderThan, "older-than", "", "only objects modified before age or date, e.g. 7d or 2018-09-30")
+}
+
+// Given reports whether any filter flag is given.
+func (f *FilterFlags) Given() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0 || f.MinSize != "" || f.MaxSize != "" ||
+		f.NewerThan != "" || f.OlderThan != ""
+}
+
+// Filter returns filter given by flags, ages are relative to now. Nil filter is returned if no flag is given.
+func (f *FilterFlags) Filter(now time.Time) (*filter.Filter, error) {
+	if !f.Given() {
+		return nil, nil
+	}
+
+	flt := &filter.Filter{Include: f.Include, Exclude: f.Exclude}
+
+	var err error
+
+	if flt.MinSize, err = ParseSize(f.MinSize); err != nil {
+		return nil, fmt.Errorf("--min-size: %s", err)
+	}
+
+	if flt.MaxSize, err = ParseSize(f.MaxSize); err != nil {
+		return nil, fmt.Errorf("--max-size: %s", err)
+	}
+
+	if f.NewerThan != "" {
+		if flt.NewerThan, err = filter.ParseTime(f.NewerThan, now); err != nil {
+			return nil, fmt.Errorf("--newer-than: %s", err)
+		}
+	}
+
+	if f.OlderThan != "" {
+		if flt.OlderThan, err = filter.ParseTime(f.OlderThan, now); err != nil {
+			return nil, fmt.Errorf("--older-than: %s", err)
+		}
+	}
+
+	if err := flt.Validate(); err != nil {
+		return nil, err
+	}
+
+	return flt, nil
+}
@@ -0,0 +1,33 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterFlags(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	f, err := (&FilterFlags{}).Filter(now)
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+
+	f, err = (&FilterFlags{Include: []string{"*.log"}, MinSize: "1K", MaxSize: "10M", NewerThan: "7d"}).Filter(now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*.log"}, f.Include)
+	assert.Equal(t, int64(1<<10), f.MinSize)
+	assert.Equal(t, int64(10<<20), f.MaxSize)
+	assert.Equal(t, now.Add(-7*24*time.Hour), f.NewerThan)
+	assert.True(t, f.OlderThan.IsZero())
+
+	_, err = (&FilterFlags{MaxSize: "huge"}).Filter(now)
+	assert.EqualError(t, err, `--max-size: size "huge" is invalid, expected bytes, e.g. 512K or 10M`)
+
+	_, err = (&FilterFlags{NewerThan: "1d", OlderThan: "2d"}).Filter(now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/s3utils"
+)
+
+// ReadKeys reads newline-delimited object keys from file given by --from-file, "-" reads standard input.
+// Keys are taken literally, wildcards aren't expanded. Empty lines are skipped and trailing \r is removed.
+func ReadKeys(file string) ([]string, error) {
+	if file == "-" {
+		return readKeys(os.Stdin, "standard input")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readKeys(f, file)
+}
+
+func readKeys(in io.Reader, name string) ([]string, error) {
+	var keys []string
+
+	scanner := bufio.NewScanner(in)
+	// keys are up to 1024 bytes, longer lines are reported as invalid keys
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		key := strings.TrimSuffix(scanner.Text(), "\r")
+		if key == "" {
+			continue
+		}
+
+		if err := s3utils.CheckValidObjectName(key); err != nil {
+			return nil, fmt.Errorf("%s, line %d: %s", name, line, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read keys from %s: %s", name, err)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys read from %s", name)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,24 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadKeys(t *testing.T) {
+	keys, err := readKeys(strings.NewReader("logs/a.gz\r\n\nlogs/*.gz\nreadme"), "keys.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logs/a.gz", "logs/*.gz", "readme"}, keys)
+
+	_, err = readKeys(strings.NewReader("\n\n"), "keys.txt")
+	assert.EqualError(t, err, "no keys read from keys.txt")
+
+	_, err = readKeys(strings.NewReader("a\n"+strings.Repeat("x", 1025)+"\n"), "keys.txt")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "keys.txt, line 2: ")
+}
@@ -0,0 +1,33 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// JSON is set by global --json flag, commands supporting it print JSON with stable field names instead of text.
+var JSON bool
+
+// PrintJSON prints v to out as indented JSON.
+func PrintJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// ProgressOutput returns writer progress of transfers is rendered to, standard error if it's a terminal.
+// Nil is returned if quiet is set or standard error is redirected, so logs aren't flooded with redrawn lines.
+func ProgressOutput(quiet bool) io.Writer {
+	if quiet || !terminal.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+
+	return os.Stderr
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+// listBatch is amount of objects listed at once.
+const listBatch = 1000
+
+// wildcards are characters of object argument which make it a pattern.
+const wildcards = `*?[\`
+
+// IsPattern reports whether object argument is a pattern selecting objects, e.g. logs/2024-*.gz.
+func IsPattern(arg string) bool {
+	return strings.ContainsAny(arg, wildcards)
+}
+
+// PatternDir returns directory of pattern up to its first wildcard, e.g. logs/ of logs/2024-*.gz.
+// Names of selected objects are relative to it when copied or downloaded.
+func PatternDir(pattern string) string {
+	prefix := patternPrefix(pattern)
+
+	return prefix[:strings.LastIndex(prefix, "/")+1]
+}
+
+// patternPrefix returns literal part of pattern before its first wildcard.
+func patternPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, wildcards); i >= 0 {
+		return pattern[:i]
+	}
+
+	return pattern
+}
+
+// ExpandPattern returns objects of bucket matching pattern as by path.Match, so * and ? don't match /.
+// Only objects under literal prefix of pattern are listed.
+func ExpandPattern(ctx context.Context, ol minio.ObjectLayer, bucket, pattern string) ([]minio.ObjectInfo, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("pattern %q is invalid: %s", pattern, err)
+	}
+
+	var matched []minio.ObjectInfo
+
+	err := ListObjects(ctx, ol, bucket, patternPrefix(pattern), func(oi minio.ObjectInfo) error {
+		if ok, _ := path.Match(pattern, oi.Name); ok {
+			matched = append(matched, oi)
+		}
+
+		return nil
+	})
+
+	return matched, err
+}
+
+// ListObjects calls fn for every object of bucket under prefix, listing stops at first error.
+func ListObjects(ctx context.Context, ol minio.ObjectLayer, bucket, prefix string, fn func(oi minio.ObjectInfo) error) error {
+	marker := ""
+
+	for {
+		loi, err := ol.ListObjects(ctx, bucket, prefix, marker, "", listBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, oi := range loi.Objects {
+			if err := fn(oi); err != nil {
+				return err
+			}
+		}
+
+		if !loi.IsTruncated || len(loi.Objects) == 0 {
+			return nil
+		}
+
+		marker = loi.NextMarker
+		if marker == "" {
+			marker = loi.Objects[len(loi.Objects)-1].Name
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var byteUnits = map[byte]float64{'K': 1 << 10, 'M': 1 << 20, 'G': 1 << 30, 'T': 1 << 40}
+
+// ParseSize parses size of objects given by flags, e.g. 10M. Number may be followed by K, M, G or T
+// binary unit, optionally followed by B. Empty string is 0.
+func ParseSize(s string) (int64, error) {
+	size, ok := parseBytes(s)
+	if !ok {
+		return 0, fmt.Errorf("size %q is invalid, expected bytes, e.g. 512K or 10M", s)
+	}
+
+	return size, nil
+}
+
+// parseBytes parses amount of bytes with optional unit, false is returned if s isn't one.
+func parseBytes(s string) (int64, bool) {
+	num := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	if num == "" {
+		return 0, true
+	}
+
+	unit := 1.0
+	if u, ok := byteUnits[num[len(num)-1]]; ok {
+		num, unit = num[:len(num)-1], u
+	}
+
+	value, err := strconv.ParseFloat(num, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+
+	return int64(value * unit), true
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/spf13/cobra"
+	"storj.io/ditto/cmd/diff"
+	"storj.io/ditto/cmd/utils"
+	"storj.io/ditto/pkg/delta"
+)
+
+// Function listed as var for testing purposes only
+var backends = utils.GetBackends
+
+var fsample float64
+
+var Cmd = &cobra.Command{
+	Use:   "verify <bucket>[/prefix]",
+	Short: "Compares content of objects on prime and alter",
+	Long: "Streams objects of bucket, optionally only objects under prefix, from both backends and compares " +
+		"SHA256 of their content, so corruption which listings don't reveal is detected. " +
+		"With --sample only given percentage of objects, picked at random, is compared. " +
+		"Objects whose listings differ are reported as by `ditto diff` without reading them.",
+	Args: diff.ValidateArgs,
+	RunE: exec,
+}
+
+func exec(cmd *cobra.Command, args []string) error {
+	if fsample <= 0 || fsample > 100 {
+		return fmt.Errorf("sample %v is invalid, expected percentage of objects in (0, 100]", fsample)
+	}
+
+	prime, alter, err := backends()
+	if err != nil {
+		return err
+	}
+
+	bucket, prefix := diff.SplitPath(args[0])
+
+	return run(context.Background(), prime, alter, bucket, prefix, sampler(fsample), os.Stdout, utils.JSON)
+}
+
+// sampler returns function picking percentage of keys at random, nil if all keys are picked.
+func sampler(percentage float64) func(key string) bool {
+	if percentage >= 100 {
+		return nil
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	return func(key string) bool {
+		return r.Float64()*100 < percentage
+	}
+}
+
+// run verifies bucket under prefix and prints report to out, as JSON if asJSON is set.
+func run(ctx context.Context, prime, alter minio.ObjectLayer, bucket, prefix string, sampled func(key string) bool, out io.Writer, asJSON bool) error {
+	report := delta.NewReport(bucket, prefix)
+
+	stats, err := delta.Verify(ctx, prime, alter, bucket, prefix, sampled, report)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range report.Failed {
+		utils.ReportFailureMessage("verify", bucket, f.Key, f.Error)
+	}
+
+	if err = diff.PrintReport(out, report, asJSON); err != nil {
+		return err
+	}
+
+	// JSON output is the report only, so it can be passed on as is
+	if !asJSON {
+		fmt.Fprintf(out, "verified %d objects (%d bytes), skipped %d not sampled\n", stats.Verified, stats.Bytes, stats.Skipped)
+	}
+
+	return diff.ReportError(report)
+}
+
+func init() {
+	Cmd.Flags().Float64Var(&fsample, "sample", 100, "percentage of objects to compare, picked at random")
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/cmd/utils"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// newLayer returns object layer which lists objects and serves their content, objects missing in content can't be read.
+func newLayer(content map[string]string, objects ...minio.ObjectInfo) minio.ObjectLayer {
+	ol := test.NewProxyObjectLayer()
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		return minio.ListObjectsInfo{Objects: objects}, nil
+	}
+
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		c, ok := content[object]
+		if !ok {
+			return errors.New("read failed")
+		}
+
+		_, err := io.WriteString(writer, c)
+		return err
+	}
+
+	return ol
+}
+
+func TestRun(t *testing.T) {
+	prime := newLayer(map[string]string{"a": "1", "b": "2", "c": "3"},
+		minio.ObjectInfo{Name: "a", Size: 1, ETag: "e"},
+		minio.ObjectInfo{Name: "b", Size: 1, ETag: "e"},
+		minio.ObjectInfo{Name: "c", Size: 1, ETag: "e"},
+		minio.ObjectInfo{Name: "d", Size: 1, ETag: "e"},
+	)
+
+	alter := newLayer(map[string]string{"a": "1", "b": "X"},
+		minio.ObjectInfo{Name: "a", Size: 1, ETag: "e"},
+		minio.ObjectInfo{Name: "b", Size: 1, ETag: "e"},
+		minio.ObjectInfo{Name: "c", Size: 1, ETag: "e"},
+		minio.ObjectInfo{Name: "e", Size: 1, ETag: "e"},
+	)
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "full",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+
+				err := run(context.Background(), prime, alter, "bucket", "", nil, out, false)
+				assert.Equal(t, utils.ExitMismatch, utils.ExitCode(err))
+				assert.Equal(t, "Only on prime (1):\n  d\t1 bytes\n"+
+					"Only on alter (1):\n  e\t1 bytes\n"+
+					"Differ (1):\n  b\tcontent sha256 "+
+					"d4735e3a265e16eee03f59718b9b5d03019c07d8b6c51f90da3a666eec13ab35 != "+
+					"4b68ab3847feda7d6c62c1fbcbeebfa35eab7351ed5e78f4ddadea5df64b8015\n"+
+					"Failed (1):\n  c\talter: read failed\n"+
+					"1 only on prime, 1 only on alter, 1 differ, 1 failed\n"+
+					"verified 2 objects (2 bytes), skipped 0 not sampled\n", out.String())
+			},
+		},
+		{
+			testName: "sampled",
+			testFunc: func(t *testing.T) {
+				out := &bytes.Buffer{}
+				sampled := func(key string) bool { return key == "a" }
+
+				err := run(context.Background(), prime, alter, "bucket", "", sampled, out, true)
+				assert.Equal(t, utils.ExitMismatch, utils.ExitCode(err))
+				assert.JSONEq(t, `{
+					"bucket": "bucket",
+					"prefix": "",
+					"onlyPrime": [{"key": "d", "prime": {"size": 1, "etag": "e"}}],
+					"onlyAlter": [{"key": "e", "alter": {"size": 1, "etag": "e"}}],
+					"differ": []
+				}`, out.String())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
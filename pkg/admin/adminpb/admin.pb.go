@@ -0,0 +1,652 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: admin.proto
+
+package adminpb
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type StatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatusRequest) Reset()         { *m = StatusRequest{} }
+func (m *StatusRequest) String() string { return proto.CompactTextString(m) }
+func (*StatusRequest) ProtoMessage()    {}
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_admin_b7ccc99f2898c4a7, []int{0}
+}
+func (m *StatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusRequest.Unmarshal(m, b)
+}
+func (m *StatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusRequest.Marshal(b, m, deterministic)
+}
+func (dst *StatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusRequest.Merge(dst, src)
+}
+func (m *StatusRequest) XXX_Size() int {
+	return xxx_messageInfo_StatusRequest.Size(m)
+}
+func (m *StatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusRequest proto.InternalMessageInfo
+
+type ActionRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ActionRequest) Reset()         { *m = ActionRequest{} }
+func (m *ActionRequest) String() string { return proto.CompactTextString(m) }
+func (*ActionRequest) ProtoMessage()    {}
+func (*ActionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_admin_b7ccc99f2898c4a7, []int{1}
+}
+func (m *ActionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ActionRequest.Unmarshal(m, b)
+}
+func (m *ActionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ActionRequest.Marshal(b, m, deterministic)
+}
+func (dst *ActionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActionRequest.Merge(dst, src)
+}
+func (m *ActionRequest) XXX_Size() int {
+	return xxx_messageInfo_ActionRequest.Size(m)
+}
+func (m *ActionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActionRequest proto.InternalMessageInfo
+
+type BackendStatus struct {
+	Backend              string   `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`
+	Healthy              bool     `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	LastCheckUnixNano    int64    `protobuf:"varint,3,opt,name=last_check_unix_nano,json=lastCheckUnixNano,proto3" json:"last_check_unix_nano,omitempty"`
+	LatencyNanos         int64    `protobuf:"varint,4,opt,name=latency_nanos,json=latencyNanos,proto3" json:"latency_nanos,omitempty"`
+	LastError            string   `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	ConsecutiveFailures  int32    `protobuf:"varint,6,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackendStatus) Reset()         { *m = BackendStatus{} }
+func (m *BackendStatus) String() string { return proto.CompactTextString(m) }
+func (*BackendStatus) ProtoMessage()    {}
+func (*BackendStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_admin_b7ccc99f2898c4a7, []int{2}
+}
+func (m *BackendStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackendStatus.Unmarshal(m, b)
+}
+func (m *BackendStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackendStatus.Marshal(b, m, deterministic)
+}
+func (dst *BackendStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackendStatus.Merge(dst, src)
+}
+func (m *BackendStatus) XXX_Size() int {
+	return xxx_messageInfo_BackendStatus.Size(m)
+}
+func (m *BackendStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackendStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackendStatus proto.InternalMessageInfo
+
+func (m *BackendStatus) GetBackend() string {
+	if m != nil {
+		return m.Backend
+	}
+	return ""
+}
+
+func (m *BackendStatus) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+func (m *BackendStatus) GetLastCheckUnixNano() int64 {
+	if m != nil {
+		return m.LastCheckUnixNano
+	}
+	return 0
+}
+
+func (m *BackendStatus) GetLatencyNanos() int64 {
+	if m != nil {
+		return m.LatencyNanos
+	}
+	return 0
+}
+
+func (m *BackendStatus) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+func (m *BackendStatus) GetConsecutiveFailures() int32 {
+	if m != nil {
+		return m.ConsecutiveFailures
+	}
+	return 0
+}
+
+type QueueStatus struct {
+	Paused               bool     `protobuf:"varint,1,opt,name=paused,proto3" json:"paused,omitempty"`
+	Queued               int32    `protobuf:"varint,2,opt,name=queued,proto3" json:"queued,omitempty"`
+	LagNanos             int64    `protobuf:"varint,3,opt,name=lag_nanos,json=lagNanos,proto3" json:"lag_nanos,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueueStatus) Reset()         { *m = QueueStatus{} }
+func (m *QueueStatus) String() string { return proto.CompactTextString(m) }
+func (*QueueStatus) ProtoMessage()    {}
+func (*QueueStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_admin_b7ccc99f2898c4a7, []int{3}
+}
+func (m *QueueStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueueStatus.Unmarshal(m, b)
+}
+func (m *QueueStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueueStatus.Marshal(b, m, deterministic)
+}
+func (dst *QueueStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueueStatus.Merge(dst, src)
+}
+func (m *QueueStatus) XXX_Size() int {
+	return xxx_messageInfo_QueueStatus.Size(m)
+}
+func (m *QueueStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueueStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueueStatus proto.InternalMessageInfo
+
+func (m *QueueStatus) GetPaused() bool {
+	if m != nil {
+		return m.Paused
+	}
+	return false
+}
+
+func (m *QueueStatus) GetQueued() int32 {
+	if m != nil {
+		return m.Queued
+	}
+	return 0
+}
+
+func (m *QueueStatus) GetLagNanos() int64 {
+	if m != nil {
+		return m.LagNanos
+	}
+	return 0
+}
+
+type GatewayStatus struct {
+	Backends             []*BackendStatus `protobuf:"bytes,1,rep,name=backends,proto3" json:"backends,omitempty"`
+	FailedOver           bool             `protobuf:"varint,2,opt,name=failed_over,json=failedOver,proto3" json:"failed_over,omitempty"`
+	FailoverOverridden   bool             `protobuf:"varint,3,opt,name=failover_overridden,json=failoverOverridden,proto3" json:"failover_overridden,omitempty"`
+	Queue                *QueueStatus     `protobuf:"bytes,4,opt,name=queue,proto3" json:"queue,omitempty"`
+	Backfill             *QueueStatus     `protobuf:"bytes,5,opt,name=backfill,proto3" json:"backfill,omitempty"`
+	Journal              int32            `protobuf:"varint,6,opt,name=journal,proto3" json:"journal,omitempty"`
+	Syncing              bool             `protobuf:"varint,7,opt,name=syncing,proto3" json:"syncing,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *GatewayStatus) Reset()         { *m = GatewayStatus{} }
+func (m *GatewayStatus) String() string { return proto.CompactTextString(m) }
+func (*GatewayStatus) ProtoMessage()    {}
+func (*GatewayStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_admin_b7ccc99f2898c4a7, []int{4}
+}
+func (m *GatewayStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GatewayStatus.Unmarshal(m, b)
+}
+func (m *GatewayStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GatewayStatus.Marshal(b, m, deterministic)
+}
+func (dst *GatewayStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GatewayStatus.Merge(dst, src)
+}
+func (m *GatewayStatus) XXX_Size() int {
+	return xxx_messageInfo_GatewayStatus.Size(m)
+}
+func (m *GatewayStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_GatewayStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GatewayStatus proto.InternalMessageInfo
+
+func (m *GatewayStatus) GetBackends() []*BackendStatus {
+	if m != nil {
+		return m.Backends
+	}
+	return nil
+}
+
+func (m *GatewayStatus) GetFailedOver() bool {
+	if m != nil {
+		return m.FailedOver
+	}
+	return false
+}
+
+func (m *GatewayStatus) GetFailoverOverridden() bool {
+	if m != nil {
+		return m.FailoverOverridden
+	}
+	return false
+}
+
+func (m *GatewayStatus) GetQueue() *QueueStatus {
+	if m != nil {
+		return m.Queue
+	}
+	return nil
+}
+
+func (m *GatewayStatus) GetBackfill() *QueueStatus {
+	if m != nil {
+		return m.Backfill
+	}
+	return nil
+}
+
+func (m *GatewayStatus) GetJournal() int32 {
+	if m != nil {
+		return m.Journal
+	}
+	return 0
+}
+
+func (m *GatewayStatus) GetSyncing() bool {
+	if m != nil {
+		return m.Syncing
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*StatusRequest)(nil), "adminpb.StatusRequest")
+	proto.RegisterType((*ActionRequest)(nil), "adminpb.ActionRequest")
+	proto.RegisterType((*BackendStatus)(nil), "adminpb.BackendStatus")
+	proto.RegisterType((*QueueStatus)(nil), "adminpb.QueueStatus")
+	proto.RegisterType((*GatewayStatus)(nil), "adminpb.GatewayStatus")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*GatewayStatus, error)
+	PauseMirroring(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error)
+	ResumeMirroring(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error)
+	TriggerSync(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error)
+	FlushJournal(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error)
+	Promote(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error)
+	Failback(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error)
+	AutoFailover(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*GatewayStatus, error) {
+	out := new(GatewayStatus)
+	err := c.cc.Invoke(ctx, "/adminpb.Admin/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) PauseMirroring(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error) {
+	out := new(GatewayStatus)
+	err := c.cc.Invoke(ctx, "/adminpb.Admin/PauseMirroring", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ResumeMirroring(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error) {
+	out := new(GatewayStatus)
+	err := c.cc.Invoke(ctx, "/adminpb.Admin/ResumeMirroring", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) TriggerSync(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error) {
+	out := new(GatewayStatus)
+	err := c.cc.Invoke(ctx, "/adminpb.Admin/TriggerSync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) FlushJournal(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error) {
+	out := new(GatewayStatus)
+	err := c.cc.Invoke(ctx, "/adminpb.Admin/FlushJournal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Promote(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error) {
+	out := new(GatewayStatus)
+	err := c.cc.Invoke(ctx, "/adminpb.Admin/Promote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Failback(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error) {
+	out := new(GatewayStatus)
+	err := c.cc.Invoke(ctx, "/adminpb.Admin/Failback", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) AutoFailover(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*GatewayStatus, error) {
+	out := new(GatewayStatus)
+	err := c.cc.Invoke(ctx, "/adminpb.Admin/AutoFailover", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	Status(context.Context, *StatusRequest) (*GatewayStatus, error)
+	PauseMirroring(context.Context, *ActionRequest) (*GatewayStatus, error)
+	ResumeMirroring(context.Context, *ActionRequest) (*GatewayStatus, error)
+	TriggerSync(context.Context, *ActionRequest) (*GatewayStatus, error)
+	FlushJournal(context.Context, *ActionRequest) (*GatewayStatus, error)
+	Promote(context.Context, *ActionRequest) (*GatewayStatus, error)
+	Failback(context.Context, *ActionRequest) (*GatewayStatus, error)
+	AutoFailover(context.Context, *ActionRequest) (*GatewayStatus, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/adminpb.Admin/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_PauseMirroring_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).PauseMirroring(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/adminpb.Admin/PauseMirroring",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).PauseMirroring(ctx, req.(*ActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ResumeMirroring_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ResumeMirroring(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/adminpb.Admin/ResumeMirroring",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ResumeMirroring(ctx, req.(*ActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_TriggerSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).TriggerSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/adminpb.Admin/TriggerSync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).TriggerSync(ctx, req.(*ActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_FlushJournal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).FlushJournal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/adminpb.Admin/FlushJournal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).FlushJournal(ctx, req.(*ActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Promote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Promote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/adminpb.Admin/Promote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Promote(ctx, req.(*ActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Failback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Failback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/adminpb.Admin/Failback",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Failback(ctx, req.(*ActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_AutoFailover_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).AutoFailover(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/adminpb.Admin/AutoFailover",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).AutoFailover(ctx, req.(*ActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "adminpb.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Status",
+			Handler:    _Admin_Status_Handler,
+		},
+		{
+			MethodName: "PauseMirroring",
+			Handler:    _Admin_PauseMirroring_Handler,
+		},
+		{
+			MethodName: "ResumeMirroring",
+			Handler:    _Admin_ResumeMirroring_Handler,
+		},
+		{
+			MethodName: "TriggerSync",
+			Handler:    _Admin_TriggerSync_Handler,
+		},
+		{
+			MethodName: "FlushJournal",
+			Handler:    _Admin_FlushJournal_Handler,
+		},
+		{
+			MethodName: "Promote",
+			Handler:    _Admin_Promote_Handler,
+		},
+		{
+			MethodName: "Failback",
+			Handler:    _Admin_Failback_Handler,
+		},
+		{
+			MethodName: "AutoFailover",
+			Handler:    _Admin_AutoFailover_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin.proto",
+}
+
+func init() { proto.RegisterFile("admin.proto", fileDescriptor_admin_b7ccc99f2898c4a7) }
+
+var fileDescriptor_admin_b7ccc99f2898c4a7 = []byte{
+	// 526 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0x95, 0x1b, 0x9c, 0x38, 0xe3, 0x86, 0x8a, 0x6d, 0x54, 0x59, 0x20, 0x44, 0x14, 0x2e, 0x11,
+	0x87, 0x14, 0xc2, 0x05, 0x90, 0x40, 0xa4, 0x88, 0x20, 0x21, 0x41, 0xcb, 0x16, 0x2e, 0xbd, 0x44,
+	0x1b, 0x7b, 0xeb, 0x2c, 0x75, 0x76, 0xd3, 0xfd, 0x08, 0xcd, 0x2f, 0xe2, 0xc7, 0xf1, 0x17, 0x38,
+	0xa0, 0x5d, 0xaf, 0x4d, 0x72, 0x00, 0x29, 0x70, 0x9c, 0xf7, 0xde, 0x4e, 0xde, 0xbc, 0x99, 0x18,
+	0x62, 0x92, 0x2d, 0x18, 0x1f, 0x2e, 0xa5, 0xd0, 0x02, 0xb5, 0x5c, 0xb1, 0x9c, 0xf5, 0x0f, 0xa0,
+	0x73, 0xae, 0x89, 0x36, 0x0a, 0xd3, 0x6b, 0x43, 0x95, 0xb6, 0xc0, 0x38, 0xd5, 0x4c, 0xf0, 0x0a,
+	0xf8, 0x11, 0x40, 0xe7, 0x84, 0xa4, 0x57, 0x94, 0x67, 0xa5, 0x12, 0x25, 0xd0, 0x9a, 0x95, 0x40,
+	0x12, 0xf4, 0x82, 0x41, 0x1b, 0x57, 0xa5, 0x65, 0xe6, 0x94, 0x14, 0x7a, 0xbe, 0x4e, 0xf6, 0x7a,
+	0xc1, 0x20, 0xc2, 0x55, 0x89, 0x8e, 0xa1, 0x5b, 0x10, 0xa5, 0xa7, 0xe9, 0x9c, 0xa6, 0x57, 0x53,
+	0xc3, 0xd9, 0xcd, 0x94, 0x13, 0x2e, 0x92, 0x46, 0x2f, 0x18, 0x34, 0xf0, 0x1d, 0xcb, 0xbd, 0xb1,
+	0xd4, 0x17, 0xce, 0x6e, 0x3e, 0x12, 0x2e, 0xd0, 0x43, 0xe8, 0x14, 0x44, 0x53, 0x9e, 0xae, 0x9d,
+	0x50, 0x25, 0xb7, 0x9c, 0x72, 0xdf, 0x83, 0x56, 0xa3, 0xd0, 0x7d, 0x00, 0xd7, 0x95, 0x4a, 0x29,
+	0x64, 0x12, 0x3a, 0x33, 0x6d, 0x8b, 0xbc, 0xb5, 0x00, 0x7a, 0x02, 0xdd, 0x54, 0x70, 0x45, 0x53,
+	0xa3, 0xd9, 0x8a, 0x4e, 0x2f, 0x09, 0x2b, 0x8c, 0xa4, 0x2a, 0x69, 0xf6, 0x82, 0x41, 0x88, 0x0f,
+	0x37, 0xb8, 0x89, 0xa7, 0xfa, 0x17, 0x10, 0x7f, 0x32, 0xd4, 0x50, 0x3f, 0xea, 0x11, 0x34, 0x97,
+	0xc4, 0x28, 0x5a, 0x4e, 0x1a, 0x61, 0x5f, 0x59, 0xfc, 0xda, 0xca, 0x32, 0x37, 0x67, 0x88, 0x7d,
+	0x85, 0xee, 0x41, 0xbb, 0x20, 0xb9, 0x77, 0x5c, 0xce, 0x16, 0x15, 0x24, 0x77, 0x6e, 0xfb, 0xdf,
+	0xf7, 0xa0, 0xf3, 0x8e, 0x68, 0xfa, 0x8d, 0xac, 0x7d, 0xfb, 0x11, 0x44, 0x3e, 0x3a, 0x95, 0x04,
+	0xbd, 0xc6, 0x20, 0x1e, 0x1d, 0x0d, 0xfd, 0x66, 0x86, 0x5b, 0x99, 0xe3, 0x5a, 0x87, 0x1e, 0x40,
+	0x6c, 0x07, 0xa1, 0xd9, 0x54, 0xac, 0xa8, 0xf4, 0x39, 0x43, 0x09, 0x9d, 0xae, 0xa8, 0x44, 0xc7,
+	0x70, 0x68, 0x2b, 0xcb, 0x3a, 0x89, 0x64, 0x59, 0x46, 0xb9, 0x73, 0x13, 0x61, 0x54, 0x51, 0xa7,
+	0x35, 0x83, 0x1e, 0x41, 0xe8, 0xec, 0xbb, 0x88, 0xe3, 0x51, 0xb7, 0xb6, 0xb0, 0x91, 0x04, 0x2e,
+	0x25, 0xe8, 0x71, 0xe9, 0xf8, 0x92, 0x15, 0x45, 0x12, 0xfe, 0x45, 0x5e, 0xab, 0xec, 0x4d, 0x7c,
+	0x15, 0x46, 0x72, 0x52, 0xf8, 0xdc, 0xab, 0xd2, 0x32, 0x6a, 0xcd, 0x53, 0xc6, 0xf3, 0xa4, 0x55,
+	0x5e, 0x8b, 0x2f, 0x47, 0x3f, 0x1b, 0x10, 0x8e, 0x6d, 0x57, 0xf4, 0x0c, 0x9a, 0xd5, 0x2a, 0xea,
+	0xdf, 0xd9, 0x3a, 0xd8, 0xbb, 0xbf, 0xf1, 0xed, 0x6c, 0x5f, 0xc3, 0xed, 0x33, 0xbb, 0xac, 0x0f,
+	0xcc, 0xde, 0x02, 0xe3, 0xf9, 0x46, 0x87, 0xad, 0x0b, 0xff, 0x63, 0x87, 0x31, 0x1c, 0x60, 0xaa,
+	0xcc, 0xe2, 0x3f, 0x5a, 0xbc, 0x84, 0xf8, 0xb3, 0x64, 0x79, 0x4e, 0xe5, 0xf9, 0x9a, 0xa7, 0x3b,
+	0x3f, 0x7f, 0x05, 0xfb, 0x93, 0xc2, 0xa8, 0xf9, 0x7b, 0x9f, 0xd8, 0xae, 0xef, 0x9f, 0x43, 0xeb,
+	0x4c, 0x8a, 0x85, 0xd0, 0x74, 0xe7, 0xa7, 0x2f, 0x20, 0xb2, 0x7f, 0x0a, 0xbb, 0xc6, 0x7f, 0xb1,
+	0x3d, 0x36, 0x5a, 0x4c, 0xfc, 0xa9, 0xed, 0xfa, 0xfe, 0xa4, 0x7d, 0x51, 0x7d, 0x9f, 0x66, 0x4d,
+	0xf7, 0xbd, 0x7a, 0xfa, 0x6b, 0x00, 0x7a, 0xfb, 0x5d, 0x1c, 0xbe, 0x04, 0x00, 0x00,
+}
@@ -0,0 +1,8 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package adminpb holds gRPC contract of admin API and its generated bindings, see admin.proto.
+// Gateway serves it at Admin.GRPCAddress, clients are created with admin.DialGRPC or NewAdminClient.
+package adminpb
+
+//go:generate protoc --go_out=plugins=grpc:. admin.proto
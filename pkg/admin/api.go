@@ -0,0 +1,193 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"storj.io/ditto/pkg/delta"
+	"storj.io/ditto/pkg/failover"
+	"storj.io/ditto/pkg/health"
+	"storj.io/ditto/pkg/journal"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/replication"
+)
+
+// API is runtime control of the gateway, so operations don't require restarts.
+// Components which are not configured are nil, their actions respond with 404.
+type API struct {
+	Queue    *replication.Queue
+	Backfill *replication.Queue
+	Checker  *health.Checker
+	Failover *failover.Controller
+	Journal  *journal.Journal
+	Replayer *journal.Replayer
+	Sync     *delta.Engine
+	Logger   l.Logger
+
+	// Context bounds actions run in background, e.g. sync, nil runs them until they complete.
+	Context context.Context
+}
+
+// QueueStatus describes replication queue.
+type QueueStatus struct {
+	Paused bool          `json:"paused"`
+	Queued int           `json:"queued"`
+	Lag    time.Duration `json:"lag"`
+}
+
+// Status is a state of the gateway reported by API.
+type Status struct {
+	Backends   []health.Status `json:"backends,omitempty"`
+	FailedOver bool            `json:"failedOver"`
+	Overridden bool            `json:"failoverOverridden"`
+	Queue      *QueueStatus    `json:"queue,omitempty"`
+	Backfill   *QueueStatus    `json:"backfill,omitempty"`
+	Journal    int             `json:"journal"`
+	Syncing    bool            `json:"syncing"`
+}
+
+// Status returns current state of the gateway.
+func (a *API) Status() Status {
+	var s Status
+
+	if a.Checker != nil {
+		s.Backends = a.Checker.Status()
+	}
+
+	if a.Failover != nil {
+		s.FailedOver = a.Failover.IsFailedOver()
+		s.Overridden = a.Failover.IsOverridden()
+	}
+
+	s.Queue = queueStatus(a.Queue)
+	s.Backfill = queueStatus(a.Backfill)
+
+	if a.Journal != nil {
+		s.Journal = a.Journal.Len()
+	}
+
+	if a.Sync != nil {
+		s.Syncing = a.Sync.IsRunning()
+	}
+
+	return s
+}
+
+func queueStatus(q *replication.Queue) *QueueStatus {
+	if q == nil {
+		return nil
+	}
+
+	return &QueueStatus{Paused: q.IsPaused(), Queued: q.Len(), Lag: q.Lag()}
+}
+
+// ServeHTTP serves API mounted at /api/:
+// GET status, POST mirroring/pause, mirroring/resume, sync, journal/flush,
+// failover/promote, failover/failback and failover/auto.
+// Every response carries status of the gateway after the action.
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api"), "/")
+
+	if action == "status" {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		a.respond(w, http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	code, err := a.act(r.Context(), action)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	a.log("admin: " + action)
+	a.respond(w, code)
+}
+
+func (a *API) act(ctx context.Context, action string) (int, error) {
+	notConfigured := func(component string) (int, error) {
+		return http.StatusNotFound, fmt.Errorf("%s is not configured", component)
+	}
+
+	switch action {
+	case "mirroring/pause", "mirroring/resume":
+		if a.Queue == nil {
+			return notConfigured("replication")
+		}
+
+		if action == "mirroring/pause" {
+			a.Queue.Pause()
+		} else {
+			a.Queue.Resume()
+		}
+	case "sync":
+		if a.Sync == nil {
+			return notConfigured("sync")
+		}
+
+		background := a.Context
+		if background == nil {
+			background = context.Background()
+		}
+
+		// sync outlives request, so it's bound to context of server, overlapping scheduled syncs are refused by engine
+		if !a.Sync.Start(background) {
+			return http.StatusConflict, delta.ErrRunning
+		}
+
+		return http.StatusAccepted, nil
+	case "journal/flush":
+		if a.Replayer == nil {
+			return notConfigured("journal")
+		}
+
+		if err := a.Replayer.Replay(ctx); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	case "failover/promote", "failover/failback", "failover/auto":
+		if a.Failover == nil {
+			return notConfigured("failover")
+		}
+
+		switch action {
+		case "failover/promote":
+			a.Failover.Override(true)
+		case "failover/failback":
+			a.Failover.Override(false)
+		default:
+			a.Failover.Release()
+		}
+	default:
+		return http.StatusNotFound, fmt.Errorf("unknown action %q", action)
+	}
+
+	return http.StatusOK, nil
+}
+
+func (a *API) respond(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(a.Status())
+}
+
+func (a *API) log(msg string) {
+	if a.Logger != nil {
+		a.Logger.Log(msg)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/failover"
+	"storj.io/ditto/pkg/replication"
+)
+
+type handlerFunc func(ctx context.Context, task replication.Task) error
+
+func (f handlerFunc) Handle(ctx context.Context, task replication.Task) error {
+	return f(ctx, task)
+}
+
+func request(h http.Handler, method, path string) (*httptest.ResponseRecorder, Status) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+	var s Status
+	json.NewDecoder(rec.Body).Decode(&s)
+
+	return rec, s
+}
+
+func TestAPI(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Mirroring paused and resumed",
+			testFunc: func(t *testing.T) {
+				q := replication.NewQueue(handlerFunc(func(ctx context.Context, task replication.Task) error { return nil }), nil, 1, 10)
+				defer q.Close()
+
+				api := &API{Queue: q}
+
+				rec, s := request(api, "POST", "/api/mirroring/pause")
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.True(t, s.Queue.Paused)
+				assert.True(t, q.IsPaused())
+
+				_, s = request(api, "POST", "/api/mirroring/resume")
+				assert.False(t, s.Queue.Paused)
+
+				rec, _ = request(api, "GET", "/api/mirroring/resume")
+				assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+			},
+		},
+		{
+			testName: "Failover overridden by operator",
+			testFunc: func(t *testing.T) {
+				ctrl := failover.NewController(&config.FailoverOptions{}, nil)
+				api := &API{Failover: ctrl}
+
+				_, s := request(api, "POST", "/api/failover/promote")
+				assert.True(t, s.FailedOver)
+				assert.True(t, s.Overridden)
+
+				// healthy prime doesn't fail back while overridden
+				for i := 0; i < 100; i++ {
+					ctrl.ReportPrime(nil)
+				}
+				assert.True(t, ctrl.IsFailedOver())
+
+				_, s = request(api, "POST", "/api/failover/failback")
+				assert.False(t, s.FailedOver)
+
+				_, s = request(api, "POST", "/api/failover/auto")
+				assert.False(t, s.Overridden)
+			},
+		},
+		{
+			testName: "Missing components reported",
+			testFunc: func(t *testing.T) {
+				api := &API{}
+
+				for _, action := range []string{"mirroring/pause", "sync", "journal/flush", "failover/promote", "unknown"} {
+					rec, _ := request(api, "POST", "/api/"+action)
+					assert.Equal(t, http.StatusNotFound, rec.Code, action)
+				}
+
+				rec, s := request(api, "GET", "/api/status")
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Nil(t, s.Queue)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestServerToken(t *testing.T) {
+	s := NewServer("127.0.0.1:0", nil).WithToken("secret")
+	s.Handle("/api/", &API{})
+	s.HandlePublic("/healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := []struct {
+		testName      string
+		path          string
+		authorization string
+		expectedCode  int
+	}{
+		{"Missing token rejected", "/api/status", "", http.StatusUnauthorized},
+		{"Wrong token rejected", "/api/status", "Bearer wrong", http.StatusUnauthorized},
+		{"Valid token accepted", "/api/status", "Bearer secret", http.StatusOK},
+		{"Public endpoint served without token", "/healthz", "", http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			r := httptest.NewRequest("GET", c.path, nil)
+			if c.authorization != "" {
+				r.Header.Set("Authorization", c.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			s.srv.Handler.ServeHTTP(rec, r)
+
+			assert.Equal(t, c.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestServerStart(t *testing.T) {
+	cases := []struct {
+		testName string
+		address  string
+		token    string
+		started  bool
+	}{
+		{"Loopback started without token", "127.0.0.1:0", "", true},
+		{"Localhost started without token", "localhost:0", "", true},
+		{"Every interface refused without token", ":0", "", false},
+		{"Public address refused without token", "0.0.0.0:0", "", false},
+		{"Every interface started with token", ":0", "secret", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			s := NewServer(c.address, nil).WithToken(c.token)
+
+			err := s.Start()
+			assert.Equal(t, c.started, err == nil)
+
+			if err == nil {
+				s.Shutdown(context.Background())
+			}
+		})
+	}
+}
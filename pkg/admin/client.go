@@ -0,0 +1,107 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const clientTimeout = 30 * time.Second
+
+// Client controls gateway through its admin API, e.g. from fleet orchestration.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// Creates new Client of administration endpoint at address, e.g. "localhost:8081" or "https://gw1:8081".
+// Empty token sends requests without authentication.
+func NewClient(address, token string) *Client {
+	if strings.HasPrefix(address, ":") {
+		address = "localhost" + address
+	}
+
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(address, "/") + "/api/",
+		token:   token,
+		client:  &http.Client{Timeout: clientTimeout},
+	}
+}
+
+// Status returns state of the gateway.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodGet, "status")
+}
+
+// PauseMirroring defers alter writes to replication queue and stops replicating them.
+func (c *Client) PauseMirroring(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodPost, "mirroring/pause")
+}
+
+// ResumeMirroring resumes mirroring paused by PauseMirroring.
+func (c *Client) ResumeMirroring(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodPost, "mirroring/resume")
+}
+
+// TriggerSync starts sync of all buckets in background.
+func (c *Client) TriggerSync(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodPost, "sync")
+}
+
+// FlushJournal replays journal and returns once it's empty or replay failed.
+func (c *Client) FlushJournal(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodPost, "journal/flush")
+}
+
+// Promote fails over to alter until Failback or AutoFailover.
+func (c *Client) Promote(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodPost, "failover/promote")
+}
+
+// Failback fails back to prime until Promote or AutoFailover.
+func (c *Client) Failback(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodPost, "failover/failback")
+}
+
+// AutoFailover resumes automatic failover and failback.
+func (c *Client) AutoFailover(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodPost, "failover/auto")
+}
+
+func (c *Client) do(ctx context.Context, method, action string) (Status, error) {
+	var s Status
+
+	req, err := http.NewRequest(method, c.baseURL+action, nil)
+	if err != nil {
+		return s, err
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return s, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return s, fmt.Errorf("%s: admin API responded %s: %s", action, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return s, json.NewDecoder(resp.Body).Decode(&s)
+}
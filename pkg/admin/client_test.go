@@ -0,0 +1,45 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/replication"
+)
+
+func TestClient(t *testing.T) {
+	q := replication.NewQueue(handlerFunc(func(ctx context.Context, task replication.Task) error { return nil }), nil, 1, 10)
+	defer q.Close()
+
+	s := NewServer("127.0.0.1:0", nil).WithToken("secret")
+	s.Handle("/api/", &API{Queue: q})
+
+	srv := httptest.NewServer(s.srv.Handler)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewClient(srv.URL, "secret")
+
+	status, err := c.PauseMirroring(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status.Queue.Paused)
+
+	status, err = c.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status.Queue.Paused)
+
+	status, err = c.ResumeMirroring(ctx)
+	assert.NoError(t, err)
+	assert.False(t, status.Queue.Paused)
+
+	_, err = c.Promote(ctx)
+	assert.Error(t, err)
+
+	_, err = NewClient(srv.URL, "wrong").Status(ctx)
+	assert.Error(t, err)
+}
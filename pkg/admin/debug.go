@@ -0,0 +1,27 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"expvar"
+	"net/http/pprof"
+
+	l "storj.io/ditto/pkg/logger"
+)
+
+// NewDebugServer creates Server exposing net/http/pprof profiles at /debug/pprof/
+// and expvar variables, including memstats, at /debug/vars.
+// Profiles reveal internals of the process, so address should never be public.
+func NewDebugServer(address string, logger l.Logger) *Server {
+	s := NewServer(address, logger)
+
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	s.mux.Handle("/debug/vars", expvar.Handler())
+
+	return s
+}
@@ -0,0 +1,36 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugServer(t *testing.T) {
+	cases := []struct {
+		testName string
+		path     string
+		expected string
+	}{
+		{"Heap profile served", "/debug/pprof/heap?debug=1", "heap profile"},
+		{"Profiles listed", "/debug/pprof/", "goroutine"},
+		{"Memstats served", "/debug/vars", "memstats"},
+	}
+
+	s := NewDebugServer("127.0.0.1:0", nil)
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.mux.ServeHTTP(rec, httptest.NewRequest("GET", c.path, nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), c.expected)
+		})
+	}
+}
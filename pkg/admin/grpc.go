@@ -0,0 +1,226 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"storj.io/ditto/pkg/admin/adminpb"
+	l "storj.io/ditto/pkg/logger"
+)
+
+// GRPCServer serves API over gRPC, see adminpb.Admin. Like Server it listens separately from S3 API
+// and requires token unless it listens on loopback address, clients pass it as "authorization: Bearer <token>" metadata.
+type GRPCServer struct {
+	api     *API
+	address string
+	token   string
+	logger  l.Logger
+	srv     *grpc.Server
+}
+
+// Creates new GRPCServer of api listening on address once started.
+func NewGRPCServer(address string, api *API, logger l.Logger) *GRPCServer {
+	s := &GRPCServer{api: api, address: address, logger: logger}
+
+	s.srv = grpc.NewServer(grpc.UnaryInterceptor(s.authorize))
+	adminpb.RegisterAdminServer(s.srv, s)
+
+	return s
+}
+
+// WithToken requires every call to carry token, empty token disables authentication.
+func (s *GRPCServer) WithToken(token string) *GRPCServer {
+	s.token = token
+	return s
+}
+
+// Start starts listening and serves calls in background, see Server.Start.
+func (s *GRPCServer) Start() error {
+	if s.token == "" && !isLoopback(s.address) {
+		return fmt.Errorf("admin gRPC server on %q requires token, set Admin.Token or listen on loopback address", s.address)
+	}
+
+	ln, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+
+	go s.serve(ln)
+
+	return nil
+}
+
+func (s *GRPCServer) serve(ln net.Listener) {
+	if err := s.srv.Serve(ln); err != nil && s.logger != nil {
+		s.logger.LogE(err)
+	}
+}
+
+// Shutdown gracefully stops the server, calls in flight are finished unless ctx is done first.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
+
+func (s *GRPCServer) authorize(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if s.token == "" {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, auth := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+s.token)) == 1 {
+			return handler(ctx, req)
+		}
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+func (s *GRPCServer) Status(ctx context.Context, req *adminpb.StatusRequest) (*adminpb.GatewayStatus, error) {
+	return toProto(s.api.Status()), nil
+}
+
+func (s *GRPCServer) PauseMirroring(ctx context.Context, req *adminpb.ActionRequest) (*adminpb.GatewayStatus, error) {
+	return s.act(ctx, "mirroring/pause")
+}
+
+func (s *GRPCServer) ResumeMirroring(ctx context.Context, req *adminpb.ActionRequest) (*adminpb.GatewayStatus, error) {
+	return s.act(ctx, "mirroring/resume")
+}
+
+func (s *GRPCServer) TriggerSync(ctx context.Context, req *adminpb.ActionRequest) (*adminpb.GatewayStatus, error) {
+	return s.act(ctx, "sync")
+}
+
+func (s *GRPCServer) FlushJournal(ctx context.Context, req *adminpb.ActionRequest) (*adminpb.GatewayStatus, error) {
+	return s.act(ctx, "journal/flush")
+}
+
+func (s *GRPCServer) Promote(ctx context.Context, req *adminpb.ActionRequest) (*adminpb.GatewayStatus, error) {
+	return s.act(ctx, "failover/promote")
+}
+
+func (s *GRPCServer) Failback(ctx context.Context, req *adminpb.ActionRequest) (*adminpb.GatewayStatus, error) {
+	return s.act(ctx, "failover/failback")
+}
+
+func (s *GRPCServer) AutoFailover(ctx context.Context, req *adminpb.ActionRequest) (*adminpb.GatewayStatus, error) {
+	return s.act(ctx, "failover/auto")
+}
+
+// act executes action of HTTP API, its status codes are translated to gRPC ones.
+func (s *GRPCServer) act(ctx context.Context, action string) (*adminpb.GatewayStatus, error) {
+	code, err := s.api.act(ctx, action)
+	if err != nil {
+		return nil, status.Error(grpcCode(code), err.Error())
+	}
+
+	s.api.log("admin: " + action)
+
+	return toProto(s.api.Status()), nil
+}
+
+func grpcCode(code int) codes.Code {
+	switch code {
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
+
+func toProto(s Status) *adminpb.GatewayStatus {
+	pb := &adminpb.GatewayStatus{
+		FailedOver:         s.FailedOver,
+		FailoverOverridden: s.Overridden,
+		Queue:              queueToProto(s.Queue),
+		Backfill:           queueToProto(s.Backfill),
+		Journal:            int32(s.Journal),
+		Syncing:            s.Syncing,
+	}
+
+	for _, b := range s.Backends {
+		pb.Backends = append(pb.Backends, &adminpb.BackendStatus{
+			Backend:             b.Backend,
+			Healthy:             b.Healthy,
+			LastCheckUnixNano:   b.LastCheck.UnixNano(),
+			LatencyNanos:        int64(b.Latency),
+			LastError:           b.LastError,
+			ConsecutiveFailures: int32(b.ConsecutiveFailures),
+		})
+	}
+
+	return pb
+}
+
+func queueToProto(q *QueueStatus) *adminpb.QueueStatus {
+	if q == nil {
+		return nil
+	}
+
+	return &adminpb.QueueStatus{Paused: q.Paused, Queued: int32(q.Queued), LagNanos: int64(q.Lag)}
+}
+
+// GRPCClient controls gateway through its gRPC admin API, its calls are those of generated adminpb.AdminClient.
+type GRPCClient struct {
+	adminpb.AdminClient
+	conn *grpc.ClientConn
+}
+
+// DialGRPC creates GRPCClient of gRPC admin endpoint at address, e.g. "gw1:8082".
+// Empty token calls endpoint without authentication.
+func DialGRPC(address, token string) (*GRPCClient, error) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+
+	conn, err := grpc.Dial(address, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GRPCClient{AdminClient: adminpb.NewAdminClient(conn), conn: conn}, nil
+}
+
+// Close closes connection of the client.
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+// bearerToken authenticates every call with token, like Client does with HTTP API.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"storj.io/ditto/pkg/admin/adminpb"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/failover"
+)
+
+// startGRPC serves api on random loopback port and returns its address.
+func startGRPC(t *testing.T, api *API, token string) (*GRPCServer, string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	s := NewGRPCServer(ln.Addr().String(), api, nil).WithToken(token)
+	go s.serve(ln)
+
+	return s, ln.Addr().String()
+}
+
+func TestGRPCServer(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := failover.NewController(&config.FailoverOptions{}, nil)
+
+	s, address := startGRPC(t, &API{Failover: ctrl}, "secret")
+	defer s.Shutdown(ctx)
+
+	client, err := DialGRPC(address, "secret")
+	assert.NoError(t, err)
+	defer client.Close()
+
+	st, err := client.Promote(ctx, &adminpb.ActionRequest{})
+	assert.NoError(t, err)
+	assert.True(t, st.FailedOver)
+	assert.True(t, st.FailoverOverridden)
+	assert.True(t, ctrl.IsFailedOver())
+
+	st, err = client.AutoFailover(ctx, &adminpb.ActionRequest{})
+	assert.NoError(t, err)
+	assert.False(t, st.FailoverOverridden)
+
+	st, err = client.Status(ctx, &adminpb.StatusRequest{})
+	assert.NoError(t, err)
+	assert.Nil(t, st.Queue)
+
+	_, err = client.PauseMirroring(ctx, &adminpb.ActionRequest{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	unauthorized, err := DialGRPC(address, "")
+	assert.NoError(t, err)
+	defer unauthorized.Close()
+
+	_, err = unauthorized.Status(ctx, &adminpb.StatusRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRPCServerRequiresToken(t *testing.T) {
+	assert.Error(t, NewGRPCServer(":0", &API{}, nil).Start())
+}
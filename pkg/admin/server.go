@@ -0,0 +1,115 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+
+	l "storj.io/ditto/pkg/logger"
+)
+
+// Server is an HTTP server for gateway administration endpoints.
+// It listens separately from S3 API, so it can be kept on a private interface.
+type Server struct {
+	mux    *http.ServeMux
+	srv    *http.Server
+	logger l.Logger
+	token  string
+	// public are patterns served without authentication
+	public map[string]bool
+}
+
+// Creates new Server listening on address once started.
+func NewServer(address string, logger l.Logger) *Server {
+	mux := http.NewServeMux()
+
+	s := &Server{
+		mux:    mux,
+		logger: logger,
+		public: make(map[string]bool),
+	}
+
+	s.srv = &http.Server{Addr: address, Handler: http.HandlerFunc(s.serve)}
+
+	return s
+}
+
+// WithToken requires every request to carry "Authorization: Bearer <token>", empty token disables authentication.
+func (s *Server) WithToken(token string) *Server {
+	s.token = token
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := s.mux.Handler(r); s.public[pattern] {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// Handle registers handler for pattern, should be called before Start.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// HandlePublic registers handler for pattern which is served without authentication, e.g. health probes.
+func (s *Server) HandlePublic(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+	s.public[pattern] = true
+}
+
+// Start starts listening and serves requests in background. Server without token is started on loopback
+// address only, so its control endpoints aren't exposed to network without authentication.
+func (s *Server) Start() error {
+	if s.token == "" && !isLoopback(s.srv.Addr) {
+		return fmt.Errorf("admin server on %q requires token, set Admin.Token or listen on loopback address", s.srv.Addr)
+	}
+
+	ln, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := s.srv.Serve(ln)
+		if err != nil && err != http.ErrServerClosed && s.logger != nil {
+			s.logger.LogE(err)
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown gracefully stops the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// isLoopback returns true if host of address is localhost or loopback IP, empty host listens on every interface.
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
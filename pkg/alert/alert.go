@@ -0,0 +1,170 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package alert
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/config"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/replication"
+)
+
+const bufferSize = 100
+
+// Alert is a notification about a condition which started (Firing) or stopped (resolved).
+type Alert struct {
+	Name    string
+	Firing  bool
+	Summary string
+	Time    time.Time
+}
+
+func (a Alert) String() string {
+	state := "RESOLVED"
+	if a.Firing {
+		state = "FIRING"
+	}
+
+	return fmt.Sprintf("[%s] %s: %s", state, a.Name, a.Summary)
+}
+
+// Notifier delivers alerts, e.g. to chat or mailbox.
+type Notifier interface {
+	Notify(a Alert) error
+}
+
+// Monitor fires alerts when replication lag or consecutive replication failures of watched queues exceed
+// thresholds and resolves them once back below. Each alert is sent once when it fires and once when it's
+// resolved, alerts are delivered to all notifiers in background.
+type Monitor struct {
+	maxLag      time.Duration
+	maxFailures int
+	notifiers   []Notifier
+	logger      l.Logger
+
+	mu       sync.Mutex
+	failures map[string]int
+	firing   map[string]bool
+
+	alerts chan Alert
+	wg     sync.WaitGroup
+
+	now func() time.Time
+}
+
+// NewMonitor creates monitor with thresholds of opts and starts delivery of alerts to notifiers.
+// Zero threshold disables respective alert.
+func NewMonitor(opts *config.AlertOptions, logger l.Logger, notifiers ...Notifier) *Monitor {
+	m := &Monitor{
+		maxLag:      opts.MaxLag,
+		maxFailures: opts.MaxFailures,
+		notifiers:   notifiers,
+		logger:      logger,
+		failures:    map[string]int{},
+		firing:      map[string]bool{},
+		alerts:      make(chan Alert, bufferSize),
+		now:         time.Now,
+	}
+
+	m.wg.Add(1)
+	go m.deliver()
+
+	return m
+}
+
+// WatchQueue checks results of replication queue q named name.
+func (m *Monitor) WatchQueue(name string, q *replication.Queue) {
+	q.AddObserver(func(task replication.Task, lag time.Duration, err error) {
+		m.Observe(name, lag, err)
+	})
+}
+
+// Observe checks result of task replicated by queue named name, err is nil for replicated task.
+func (m *Monitor) Observe(name string, lag time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.maxLag > 0 {
+		summary := fmt.Sprintf("task waited %s in %s queue, threshold is %s", lag, name, m.maxLag)
+		if lag <= m.maxLag {
+			summary = fmt.Sprintf("lag of %s queue is back below %s", name, m.maxLag)
+		}
+
+		m.set(name+" replication lag", lag > m.maxLag, summary)
+	}
+
+	if err != nil {
+		m.failures[name]++
+	} else {
+		m.failures[name] = 0
+	}
+
+	if m.maxFailures > 0 {
+		summary := fmt.Sprintf("%d consecutive tasks of %s queue failed, the last one with: %v", m.failures[name], name, err)
+		if err == nil {
+			summary = fmt.Sprintf("tasks of %s queue are replicated again", name)
+		}
+
+		m.set(name+" replication failures", m.failures[name] >= m.maxFailures, summary)
+	}
+}
+
+// set fires or resolves alert if its state changed.
+func (m *Monitor) set(name string, firing bool, summary string) {
+	if m.firing[name] == firing {
+		return
+	}
+
+	m.firing[name] = firing
+
+	a := Alert{Name: name, Firing: firing, Summary: summary, Time: m.now().UTC()}
+
+	select {
+	case m.alerts <- a:
+	default:
+		m.logE(fmt.Errorf("alert %s dropped, delivery is behind", a))
+	}
+}
+
+// Firing returns names of alerts which are firing.
+func (m *Monitor) Firing() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var names []string
+	for name, firing := range m.firing {
+		if firing {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Close delivers pending alerts and stops delivery.
+func (m *Monitor) Close() {
+	close(m.alerts)
+	m.wg.Wait()
+}
+
+func (m *Monitor) deliver() {
+	defer m.wg.Done()
+
+	for a := range m.alerts {
+		for _, n := range m.notifiers {
+			if err := n.Notify(a); err != nil {
+				m.logE(fmt.Errorf("alert %s not delivered: %s", a, err))
+			}
+		}
+	}
+}
+
+func (m *Monitor) logE(err error) {
+	if m.logger != nil {
+		m.logger.LogE(err)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+)
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recordingNotifier) Notify(a Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func TestMonitor(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "consecutive failures fire once and resolve",
+			testFunc: func(t *testing.T) {
+				n := &recordingNotifier{}
+				m := NewMonitor(&config.AlertOptions{MaxFailures: 2}, nil, n)
+
+				m.Observe("replication", time.Second, errors.New("alter failed"))
+				m.Observe("replication", time.Second, nil)
+				m.Observe("replication", time.Second, errors.New("alter failed"))
+				m.Observe("replication", time.Second, errors.New("alter failed"))
+				m.Observe("replication", time.Second, errors.New("alter failed"))
+				assert.Equal(t, []string{"replication replication failures"}, m.Firing())
+
+				m.Observe("replication", time.Second, nil)
+				m.Close()
+
+				assert.Equal(t, 2, len(n.alerts))
+				assert.True(t, n.alerts[0].Firing)
+				assert.Contains(t, n.alerts[0].Summary, "2 consecutive tasks of replication queue failed")
+				assert.False(t, n.alerts[1].Firing)
+				assert.Equal(t, 0, len(m.Firing()))
+			},
+		},
+		{
+			testName: "lag over threshold fires and resolves",
+			testFunc: func(t *testing.T) {
+				n := &recordingNotifier{}
+				m := NewMonitor(&config.AlertOptions{MaxLag: time.Minute}, nil, n)
+
+				m.Observe("backfill", time.Second, nil)
+				m.Observe("backfill", 2*time.Minute, nil)
+				m.Observe("backfill", 3*time.Minute, nil)
+				m.Observe("backfill", time.Second, nil)
+				m.Close()
+
+				assert.Equal(t, 2, len(n.alerts))
+				assert.Equal(t, "backfill replication lag", n.alerts[0].Name)
+				assert.True(t, n.alerts[0].Firing)
+				assert.Equal(t, "[RESOLVED] backfill replication lag: lag of backfill queue is back below 1m0s", n.alerts[1].String())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	var text string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		text = body["text"]
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	err := NewSlack(srv.URL).Notify(Alert{Name: "lag", Firing: true, Summary: "too slow"})
+	assert.NoError(t, err)
+	assert.Equal(t, ":rotating_light: [FIRING] lag: too slow", text)
+
+	status = http.StatusNotFound
+	assert.Error(t, NewSlack(srv.URL).Notify(Alert{Name: "lag"}))
+}
+
+func TestEmail(t *testing.T) {
+	e := NewEmail("smtp.example.com:587", "user", "secret", "ditto@example.com", []string{"ops@example.com"})
+
+	var sent string
+	e.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "smtp.example.com:587", addr)
+		assert.NotNil(t, a)
+		assert.Equal(t, "ditto@example.com", from)
+		assert.Equal(t, []string{"ops@example.com"}, to)
+		sent = string(msg)
+		return nil
+	}
+
+	assert.NoError(t, e.Notify(Alert{Name: "lag", Firing: true, Summary: "too slow", Time: time.Now()}))
+	assert.True(t, strings.Contains(sent, "Subject: ditto [FIRING] lag: too slow\r\n"), sent)
+	assert.True(t, strings.HasSuffix(sent, "\r\n\r\ntoo slow\r\n"), sent)
+}
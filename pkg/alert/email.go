@@ -0,0 +1,48 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Email sends alerts by mail through SMTP server.
+type Email struct {
+	address  string
+	auth     smtp.Auth
+	from     string
+	to       []string
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmail creates notifier sending mail from address to recipients through SMTP server at address, i.e. host:port.
+// Server is authenticated with PLAIN auth unless username is empty.
+func NewEmail(address, username, password, from string, to []string) *Email {
+	e := &Email{address: address, from: from, to: to, sendMail: smtp.SendMail}
+
+	if username != "" {
+		host, _, _ := net.SplitHostPort(address)
+		e.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return e
+}
+
+func (e *Email) Notify(a Alert) error {
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&msg, "Subject: ditto %s\r\n", a)
+	fmt.Fprintf(&msg, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n", a.Summary)
+
+	return e.sendMail(e.address, e.auth, e.from, e.to, msg.Bytes())
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Slack posts alerts to Slack incoming webhook.
+type Slack struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlack creates notifier posting to incoming webhook url.
+func NewSlack(url string) *Slack {
+	return &Slack{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *Slack) Notify(a Alert) error {
+	icon := ":white_check_mark:"
+	if a.Firing {
+		icon = ":rotating_light:"
+	}
+
+	body, err := json.Marshal(map[string]string{"text": icon + " " + a.String()})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("slack responded %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 30 * time.Second
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	CLOSED State = iota
+	OPEN
+	HALF_OPEN
+)
+
+func (s State) String() string {
+	switch s {
+	case CLOSED:
+		return "closed"
+	case OPEN:
+		return "open"
+	case HALF_OPEN:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker opens after threshold consecutive failures and rejects calls for cooldown period.
+// After cooldown single probe call is allowed, its success closes breaker and failure opens it again.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	isFailure func(error) bool
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+
+	now func() time.Time
+}
+
+// Creates new Breaker, zero values are replaced with defaults.
+// isFailure decides which errors are counted as failures, nil means every error.
+func NewBreaker(threshold int, cooldown time.Duration, isFailure func(error) bool) *Breaker {
+	if threshold <= 0 {
+		threshold = DefaultFailureThreshold
+	}
+
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+
+	if isFailure == nil {
+		isFailure = func(err error) bool { return err != nil }
+	}
+
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		isFailure: isFailure,
+		now:       time.Now,
+	}
+}
+
+// State returns current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// IsOpen returns true if calls would be rejected now.
+// Unlike Acquire it does not change the state, so it can be used to decide whether to try at all.
+func (b *Breaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case OPEN:
+		return b.now().Sub(b.openedAt) < b.cooldown
+	case HALF_OPEN:
+		return true
+	default:
+		return false
+	}
+}
+
+// Acquire returns ErrCircuitOpen if call is not allowed.
+// Once cooldown is over the first caller becomes a probe and moves breaker to half-open state.
+func (b *Breaker) Acquire() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case OPEN:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+
+		b.state = HALF_OPEN
+
+		return nil
+	case HALF_OPEN:
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+// Report records result of a call allowed by Acquire.
+func (b *Breaker) Report(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.isFailure(err) {
+		b.state = CLOSED
+		b.failures = 0
+
+		return
+	}
+
+	b.failures++
+
+	if b.state == HALF_OPEN || b.failures >= b.threshold {
+		b.state = OPEN
+		b.openedAt = b.now()
+	}
+}
+
+// Wait blocks while breaker is open or ctx is done.
+func (b *Breaker) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		wait := time.Duration(0)
+
+		switch b.state {
+		case OPEN:
+			wait = b.cooldown - b.now().Sub(b.openedAt)
+		case HALF_OPEN:
+			// probe is in flight, check again shortly
+			wait = b.cooldown / 10
+		}
+		b.mu.Unlock()
+
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	testErr := errors.New("test error")
+	now := time.Now()
+
+	newBreaker := func() *Breaker {
+		b := NewBreaker(2, time.Minute, nil)
+		b.now = func() time.Time { return now }
+
+		return b
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Opens after threshold of consecutive failures",
+			func(t *testing.T) {
+				b := newBreaker()
+
+				b.Report(testErr)
+				b.Report(nil)
+				b.Report(testErr)
+				assert.Equal(t, CLOSED, b.State())
+				assert.NoError(t, b.Acquire())
+
+				b.Report(testErr)
+				assert.Equal(t, OPEN, b.State())
+				assert.Equal(t, true, b.IsOpen())
+				assert.Equal(t, ErrCircuitOpen, b.Acquire())
+			},
+		},
+		{
+			"Single probe after cooldown, success closes",
+			func(t *testing.T) {
+				b := newBreaker()
+
+				b.Report(testErr)
+				b.Report(testErr)
+
+				now = now.Add(2 * time.Minute)
+				assert.Equal(t, false, b.IsOpen())
+
+				assert.NoError(t, b.Acquire())
+				assert.Equal(t, HALF_OPEN, b.State())
+				assert.Equal(t, ErrCircuitOpen, b.Acquire())
+
+				b.Report(nil)
+				assert.Equal(t, CLOSED, b.State())
+			},
+		},
+		{
+			"Failed probe opens again",
+			func(t *testing.T) {
+				b := newBreaker()
+
+				b.Report(testErr)
+				b.Report(testErr)
+
+				now = now.Add(2 * time.Minute)
+				assert.NoError(t, b.Acquire())
+
+				b.Report(testErr)
+				assert.Equal(t, OPEN, b.State())
+				assert.Equal(t, true, b.IsOpen())
+			},
+		},
+		{
+			"Wait returns when context is done",
+			func(t *testing.T) {
+				b := newBreaker()
+
+				b.Report(testErr)
+				b.Report(testErr)
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+
+				assert.Equal(t, context.DeadlineExceeded, b.Wait(ctx))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package breaker
+
+import (
+	"context"
+
+	"storj.io/ditto/pkg/replication"
+)
+
+// NewHandler wraps replication handler so tasks wait until breaker lets calls through,
+// instead of exhausting their retries against a dead backend.
+func NewHandler(h replication.Handler, b *Breaker) replication.Handler {
+	return &handler{h, b}
+}
+
+type handler struct {
+	h replication.Handler
+	b *Breaker
+}
+
+func (h *handler) Handle(ctx context.Context, task replication.Task) error {
+	for {
+		if err := h.b.Wait(ctx); err != nil {
+			return err
+		}
+
+		// another task became a probe in the meantime, wait for its result
+		err := h.h.Handle(ctx, task)
+		if err != ErrCircuitOpen {
+			return err
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package buffer
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// spill is a shared state of SpillReader and SpillWriter.
+// Data is kept in memory up to memLimit bytes, everything above is written to temp file.
+// Reader always drains memory before file, so ordering of written data is preserved.
+type spill struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	mem      bytes.Buffer
+	memLimit int
+	dir      string
+
+	file       *os.File
+	rOff, wOff int64
+
+	wclosed, rclosed bool
+	werr, rerr       error
+}
+
+// SpillReader is a read half of spill pipe.
+type SpillReader struct {
+	s *spill
+}
+
+// SpillWriter is a write half of spill pipe.
+type SpillWriter struct {
+	s *spill
+}
+
+// NewSpillPipe creates in-process pipe which never blocks writer.
+// Up to memLimit bytes are buffered in memory, the rest is spilled to temp file in dir.
+// Empty dir means os.TempDir(). Temp file is removed once both halves are closed.
+func NewSpillPipe(memLimit int, dir string) (*SpillReader, *SpillWriter) {
+	s := &spill{memLimit: memLimit, dir: dir}
+	s.cond = sync.NewCond(&s.mu)
+
+	return &SpillReader{s}, &SpillWriter{s}
+}
+
+// Write buffers p, returns io.ErrClosedPipe if reader was closed.
+func (w *SpillWriter) Write(p []byte) (int, error) {
+	s := w.s
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rclosed {
+		return 0, s.rerr
+	}
+
+	if s.wclosed {
+		return 0, io.ErrClosedPipe
+	}
+
+	defer s.cond.Broadcast()
+
+	if s.rOff == s.wOff {
+		// file is drained, start over from memory
+		s.rOff, s.wOff = 0, 0
+
+		if s.mem.Len()+len(p) <= s.memLimit {
+			return s.mem.Write(p)
+		}
+	}
+
+	if s.file == nil {
+		f, err := ioutil.TempFile(s.dir, "ditto-spill-")
+		if err != nil {
+			return 0, err
+		}
+
+		s.file = f
+	}
+
+	n, err := s.file.WriteAt(p, s.wOff)
+	s.wOff += int64(n)
+
+	return n, err
+}
+
+// Close closes writer, subsequent reads will return io.EOF once buffer is drained.
+func (w *SpillWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError closes writer, subsequent reads will return err once buffer is drained.
+// Nil err is the same as io.EOF.
+func (w *SpillWriter) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+
+	return w.s.closeWrite(err)
+}
+
+// Read blocks until data is available or writer is closed.
+func (r *SpillReader) Read(p []byte) (int, error) {
+	s := r.s
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for !s.rclosed && !s.wclosed && s.mem.Len() == 0 && s.rOff == s.wOff {
+		s.cond.Wait()
+	}
+
+	if s.rclosed {
+		return 0, io.ErrClosedPipe
+	}
+
+	if s.mem.Len() > 0 {
+		return s.mem.Read(p)
+	}
+
+	if s.rOff < s.wOff {
+		if rem := s.wOff - s.rOff; int64(len(p)) > rem {
+			p = p[:rem]
+		}
+
+		n, err := s.file.ReadAt(p, s.rOff)
+		s.rOff += int64(n)
+		if err == io.EOF && n > 0 {
+			err = nil
+		}
+
+		return n, err
+	}
+
+	return 0, s.werr
+}
+
+// Close closes reader, subsequent writes will return io.ErrClosedPipe.
+func (r *SpillReader) Close() error {
+	return r.CloseWithError(nil)
+}
+
+// CloseWithError closes reader, subsequent writes will return err.
+// Nil err is the same as io.ErrClosedPipe.
+func (r *SpillReader) CloseWithError(err error) error {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+
+	return r.s.closeRead(err)
+}
+
+func (s *spill) closeWrite(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.wclosed {
+		s.wclosed, s.werr = true, err
+		s.cond.Broadcast()
+	}
+
+	return s.release()
+}
+
+func (s *spill) closeRead(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.rclosed {
+		s.rclosed, s.rerr = true, err
+		s.mem.Reset()
+		s.cond.Broadcast()
+	}
+
+	return s.release()
+}
+
+// release removes temp file once both halves are closed, should be called under lock.
+func (s *spill) release() error {
+	if !s.wclosed || !s.rclosed || s.file == nil {
+		return nil
+	}
+
+	f := s.file
+	s.file = nil
+
+	err := f.Close()
+	if rmErr := os.Remove(f.Name()); err == nil {
+		err = rmErr
+	}
+
+	return err
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package buffer
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpillPipe(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Writes never block and are read in order",
+			func(t *testing.T) {
+				dir, err := ioutil.TempDir("", "spill-test")
+				assert.NoError(t, err)
+				defer os.RemoveAll(dir)
+
+				pr, pw := NewSpillPipe(4, dir)
+
+				for _, chunk := range []string{"abc", "defgh", "ij", "k"} {
+					_, err := pw.Write([]byte(chunk))
+					assert.NoError(t, err)
+				}
+
+				files, _ := ioutil.ReadDir(dir)
+				assert.Equal(t, 1, len(files))
+
+				assert.NoError(t, pw.Close())
+
+				data, err := ioutil.ReadAll(pr)
+				assert.NoError(t, err)
+				assert.Equal(t, "abcdefghijk", string(data))
+
+				assert.NoError(t, pr.Close())
+
+				files, _ = ioutil.ReadDir(dir)
+				assert.Equal(t, 0, len(files))
+			},
+		},
+		{
+			"Writer error is returned after data is drained",
+			func(t *testing.T) {
+				testErr := errors.New("test error")
+				pr, pw := NewSpillPipe(16, "")
+
+				_, err := pw.Write([]byte("data"))
+				assert.NoError(t, err)
+				assert.NoError(t, pw.CloseWithError(testErr))
+
+				buf := make([]byte, 16)
+				n, err := pr.Read(buf)
+				assert.NoError(t, err)
+				assert.Equal(t, "data", string(buf[:n]))
+
+				_, err = pr.Read(buf)
+				assert.Equal(t, testErr, err)
+			},
+		},
+		{
+			"Closed reader fails writes",
+			func(t *testing.T) {
+				pr, pw := NewSpillPipe(16, "")
+
+				assert.NoError(t, pr.Close())
+
+				_, err := pw.Write([]byte("data"))
+				assert.Equal(t, io.ErrClosedPipe, err)
+			},
+		},
+		{
+			"Read blocks until data is written",
+			func(t *testing.T) {
+				pr, pw := NewSpillPipe(1, "")
+
+				go func() {
+					pw.Write([]byte("late"))
+					pw.Close()
+				}()
+
+				data, err := ioutil.ReadAll(pr)
+				assert.NoError(t, err)
+				assert.Equal(t, "late", string(data))
+				pr.Close()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
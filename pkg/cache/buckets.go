@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+// Buckets caches list of buckets for ttl.
+type Buckets struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	buckets []minio.BucketInfo
+	expires time.Time
+	// generation is incremented by Invalidate, list fetched before invalidation is not cached
+	generation uint64
+
+	// now is overridden by tests
+	now func() time.Time
+}
+
+// NewBuckets creates cache keeping list of buckets for ttl.
+func NewBuckets(ttl time.Duration) *Buckets {
+	return &Buckets{ttl: ttl, now: time.Now}
+}
+
+// Get returns cached list of buckets, or list returned by fetch if cached one expired.
+// Errors of fetch are not cached.
+func (b *Buckets) Get(fetch func() ([]minio.BucketInfo, error)) ([]minio.BucketInfo, error) {
+	b.mu.Lock()
+	if b.buckets != nil && b.now().Before(b.expires) {
+		buckets := append([]minio.BucketInfo{}, b.buckets...)
+		b.mu.Unlock()
+
+		return buckets, nil
+	}
+
+	generation := b.generation
+	b.mu.Unlock()
+
+	buckets, err := fetch()
+	if err != nil {
+		return buckets, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation {
+		b.buckets = append([]minio.BucketInfo{}, buckets...)
+		b.expires = b.now().Add(b.ttl)
+	}
+
+	return buckets, nil
+}
+
+// Invalidate drops cached list, it should be called once bucket is created or deleted.
+func (b *Buckets) Invalidate() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buckets = nil
+	b.generation++
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuckets(t *testing.T) {
+	calls := 0
+	fetch := func() ([]minio.BucketInfo, error) {
+		calls++
+		return []minio.BucketInfo{{Name: "bucket"}}, nil
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "List is cached until ttl passes",
+			testFunc: func(t *testing.T) {
+				calls = 0
+				now := time.Now()
+
+				b := NewBuckets(time.Minute)
+				b.now = func() time.Time { return now }
+
+				for i := 0; i < 3; i++ {
+					buckets, err := b.Get(fetch)
+					assert.NoError(t, err)
+					assert.Equal(t, []minio.BucketInfo{{Name: "bucket"}}, buckets)
+				}
+
+				assert.Equal(t, 1, calls)
+
+				now = now.Add(time.Minute)
+				b.Get(fetch)
+				assert.Equal(t, 2, calls)
+			},
+		},
+		{
+			testName: "Invalidated list is fetched again",
+			testFunc: func(t *testing.T) {
+				calls = 0
+
+				b := NewBuckets(time.Minute)
+				b.Get(fetch)
+				b.Invalidate()
+				b.Get(fetch)
+
+				assert.Equal(t, 2, calls)
+			},
+		},
+		{
+			testName: "List fetched during invalidation is not cached",
+			testFunc: func(t *testing.T) {
+				calls = 0
+
+				b := NewBuckets(time.Minute)
+				b.Get(func() ([]minio.BucketInfo, error) {
+					b.Invalidate()
+					return fetch()
+				})
+				b.Get(fetch)
+
+				assert.Equal(t, 2, calls)
+			},
+		},
+		{
+			testName: "Errors are not cached",
+			testFunc: func(t *testing.T) {
+				b := NewBuckets(time.Minute)
+
+				_, err := b.Get(func() ([]minio.BucketInfo, error) {
+					return nil, errors.New("backend is down")
+				})
+				assert.Error(t, err)
+
+				calls = 0
+				b.Get(fetch)
+				assert.Equal(t, 1, calls)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	dataExt = ".data"
+	metaExt = ".meta"
+)
+
+// Disk keeps content of objects in files under directory, least recently used objects are evicted
+// once total size exceeds maxSize. Objects bigger than maxObjectSize are never cached.
+// Content is cached with ETag it was read with and served only to reads with the same ETag.
+// SHA-256 of content is verified before every read, corrupted objects are dropped.
+// Cached objects are loaded from directory on start, so cache survives restarts,
+// objects cached by other process sharing directory are found once they are read.
+type Disk struct {
+	dir           string
+	maxSize       int64
+	maxObjectSize int64
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	// lru holds entries most recently used first
+	lru *list.List
+}
+
+// diskMeta is stored next to cached content.
+type diskMeta struct {
+	Bucket string
+	Object string
+	ETag   string
+	Size   int64
+	SHA256 string
+}
+
+type diskEntry struct {
+	name string
+	meta diskMeta
+}
+
+// NewDisk creates cache in dir keeping up to maxSize bytes of objects not bigger than maxObjectSize.
+// Objects cached in dir before are loaded, leftovers of interrupted writes are removed.
+func NewDisk(dir string, maxSize, maxObjectSize int64) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	d := &Disk{
+		dir:           dir,
+		maxSize:       maxSize,
+		maxObjectSize: maxObjectSize,
+		entries:       make(map[string]*list.Element),
+		lru:           list.New(),
+	}
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// load indexes cached objects, most recently modified first.
+func (d *Disk) load() error {
+	infos, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().After(infos[j].ModTime()) })
+
+	for _, info := range infos {
+		name := info.Name()
+
+		switch {
+		case strings.HasSuffix(name, metaExt):
+			name = strings.TrimSuffix(name, metaExt)
+		case strings.HasSuffix(name, dataExt):
+			// data without meta is leftover of interrupted write
+			if _, err := os.Stat(d.path(strings.TrimSuffix(name, dataExt), metaExt)); err == nil {
+				continue
+			}
+
+			os.Remove(filepath.Join(d.dir, name))
+			continue
+		default:
+			os.Remove(filepath.Join(d.dir, name))
+			continue
+		}
+
+		meta, err := d.readMeta(name)
+		if err != nil {
+			d.removeFiles(name)
+			continue
+		}
+
+		entry := &diskEntry{name: name, meta: meta}
+		d.entries[key(meta.Bucket, meta.Object)] = d.lru.PushBack(entry)
+		d.size += meta.Size
+	}
+
+	d.evict()
+
+	return nil
+}
+
+func (d *Disk) readMeta(name string) (diskMeta, error) {
+	var meta diskMeta
+
+	b, err := ioutil.ReadFile(d.path(name, metaExt))
+	if err != nil {
+		return meta, err
+	}
+
+	if err = json.Unmarshal(b, &meta); err != nil {
+		return meta, err
+	}
+
+	info, err := os.Stat(d.path(name, dataExt))
+	if err != nil {
+		return meta, err
+	}
+
+	if info.Size() != meta.Size {
+		return meta, fmt.Errorf("size of cached %s/%s is %d, expected %d", meta.Bucket, meta.Object, info.Size(), meta.Size)
+	}
+
+	return meta, nil
+}
+
+func (d *Disk) path(name, ext string) string {
+	return filepath.Join(d.dir, name+ext)
+}
+
+// fileName is name of files of object, it is safe whatever bucket and object names are.
+func fileName(bucket, object string) string {
+	sum := sha256.Sum256([]byte(key(bucket, object)))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Fits returns true if object of size can be cached.
+func (d *Disk) Fits(size int64) bool {
+	return size >= 0 && size <= d.maxObjectSize && size <= d.maxSize
+}
+
+// Get writes length bytes of object starting at offset to w if they are cached with etag.
+// Returns false if they aren't cached or cached content is corrupted. Error is returned only if w failed.
+func (d *Disk) Get(bucket, object, etag string, offset, length int64, w io.Writer) (bool, error) {
+	d.mu.Lock()
+	e, ok := d.entries[key(bucket, object)]
+	if !ok {
+		// object may have been cached by another process sharing dir, e.g. by prewarm
+		if e, ok = d.lookup(bucket, object); !ok {
+			d.mu.Unlock()
+			return false, nil
+		}
+	}
+
+	entry := e.Value.(*diskEntry)
+	if entry.meta.ETag != etag || offset < 0 || length < 0 || offset+length > entry.meta.Size {
+		d.mu.Unlock()
+		return false, nil
+	}
+
+	d.lru.MoveToFront(e)
+	d.mu.Unlock()
+
+	f, err := os.Open(d.path(entry.name, dataExt))
+	if err != nil {
+		d.drop(bucket, object, entry)
+		return false, nil
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil || hex.EncodeToString(h.Sum(nil)) != entry.meta.SHA256 {
+		d.drop(bucket, object, entry)
+		return false, nil
+	}
+
+	_, err = io.Copy(w, io.NewSectionReader(f, offset, length))
+
+	return true, err
+}
+
+// lookup indexes object found in dir, but not in index.
+func (d *Disk) lookup(bucket, object string) (*list.Element, bool) {
+	name := fileName(bucket, object)
+
+	meta, err := d.readMeta(name)
+	if err != nil || meta.Bucket != bucket || meta.Object != object {
+		return nil, false
+	}
+
+	d.entries[key(bucket, object)] = d.lru.PushFront(&diskEntry{name: name, meta: meta})
+	d.size += meta.Size
+
+	d.evict()
+
+	e, ok := d.entries[key(bucket, object)]
+
+	return e, ok
+}
+
+// drop removes entry of object unless it was replaced meanwhile.
+func (d *Disk) drop(bucket, object string, entry *diskEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if e, ok := d.entries[key(bucket, object)]; ok && e.Value == entry {
+		d.remove(e)
+	}
+}
+
+// Invalidate drops cached content of object, it should be called once object is written or deleted.
+func (d *Disk) Invalidate(bucket, object string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if e, ok := d.entries[key(bucket, object)]; ok {
+		d.remove(e)
+	}
+}
+
+// Size returns total size of cached objects.
+func (d *Disk) Size() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.size
+}
+
+// NewWriter returns writer caching content of object read from its beginning with etag.
+// Content is cached only once Commit is called, Abort discards it.
+func (d *Disk) NewWriter(bucket, object, etag string) (*DiskWriter, error) {
+	f, err := ioutil.TempFile(d.dir, "tmp-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &DiskWriter{disk: d, file: f, hash: sha256.New(), meta: diskMeta{Bucket: bucket, Object: object, ETag: etag}}, nil
+}
+
+// DiskWriter writes content of object to temporary file of Disk cache.
+type DiskWriter struct {
+	disk *Disk
+	file *os.File
+	hash hash.Hash
+	meta diskMeta
+	err  error
+}
+
+// Write writes b to temporary file. Failure doesn't fail the read being cached,
+// it is remembered and returned by Commit instead.
+func (w *DiskWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return len(b), nil
+	}
+
+	if w.meta.Size+int64(len(b)) > w.disk.maxObjectSize {
+		w.err = fmt.Errorf("%s/%s is too big to be cached", w.meta.Bucket, w.meta.Object)
+		return len(b), nil
+	}
+
+	n, err := w.file.Write(b)
+	w.meta.Size += int64(n)
+	w.hash.Write(b[:n])
+
+	if err != nil {
+		w.err = err
+	}
+
+	return len(b), nil
+}
+
+// Size returns number of bytes written.
+func (w *DiskWriter) Size() int64 {
+	return w.meta.Size
+}
+
+// Commit caches written content, evicting least recently used objects if cache is full.
+func (w *DiskWriter) Commit() error {
+	err := w.err
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+
+	w.meta.SHA256 = hex.EncodeToString(w.hash.Sum(nil))
+
+	meta, err := json.Marshal(w.meta)
+	if err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+
+	d := w.disk
+	name := fileName(w.meta.Bucket, w.meta.Object)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	k := key(w.meta.Bucket, w.meta.Object)
+	if e, ok := d.entries[k]; ok {
+		d.remove(e)
+	}
+
+	if err = os.Rename(w.file.Name(), d.path(name, dataExt)); err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+
+	// meta is written last, data without meta is removed on start
+	if err = writeFile(d.path(name, metaExt), meta); err != nil {
+		d.removeFiles(name)
+		return err
+	}
+
+	d.entries[k] = d.lru.PushFront(&diskEntry{name: name, meta: w.meta})
+	d.size += w.meta.Size
+
+	d.evict()
+
+	return nil
+}
+
+// Abort discards written content.
+func (w *DiskWriter) Abort() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
+
+// writeFile writes b to path atomically.
+func writeFile(path string, b []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (d *Disk) evict() {
+	for d.size > d.maxSize && d.lru.Len() > 0 {
+		d.remove(d.lru.Back())
+	}
+}
+
+func (d *Disk) remove(e *list.Element) {
+	entry := e.Value.(*diskEntry)
+
+	d.lru.Remove(e)
+	delete(d.entries, key(entry.meta.Bucket, entry.meta.Object))
+	d.size -= entry.meta.Size
+
+	d.removeFiles(entry.name)
+}
+
+func (d *Disk) removeFiles(name string) {
+	os.Remove(d.path(name, metaExt))
+	os.Remove(d.path(name, dataExt))
+}
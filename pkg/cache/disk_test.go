@@ -0,0 +1,186 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisk(t *testing.T) {
+	add := func(t *testing.T, d *Disk, object string, data []byte) {
+		w, err := d.NewWriter("bucket", object, "etag")
+		assert.NoError(t, err)
+
+		_, err = w.Write(data)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Commit())
+	}
+
+	get := func(d *Disk, object string, offset, length int64) ([]byte, bool) {
+		var buf bytes.Buffer
+
+		ok, err := d.Get("bucket", object, "etag", offset, length, &buf)
+		if err != nil {
+			return nil, false
+		}
+
+		return buf.Bytes(), ok
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T, dir string)
+	}{
+		{
+			testName: "Ranges within cached content are served",
+			testFunc: func(t *testing.T, dir string) {
+				d, err := NewDisk(dir, 100, 10)
+				assert.NoError(t, err)
+
+				add(t, d, "object", []byte("0123456789"))
+
+				data, ok := get(d, "object", 2, 3)
+				assert.True(t, ok)
+				assert.Equal(t, []byte("234"), data)
+
+				_, ok = get(d, "object", 5, 10)
+				assert.False(t, ok)
+
+				ok, _ = d.Get("bucket", "object", "other", 0, 10, ioutil.Discard)
+				assert.False(t, ok)
+			},
+		},
+		{
+			testName: "Cache is loaded on start",
+			testFunc: func(t *testing.T, dir string) {
+				d, err := NewDisk(dir, 100, 10)
+				assert.NoError(t, err)
+
+				add(t, d, "object", []byte("abc"))
+
+				// leftover of interrupted write
+				assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "tmp-1"), []byte("x"), 0600))
+
+				d, err = NewDisk(dir, 100, 10)
+				assert.NoError(t, err)
+
+				data, ok := get(d, "object", 0, 3)
+				assert.True(t, ok)
+				assert.Equal(t, []byte("abc"), data)
+				assert.Equal(t, int64(3), d.Size())
+
+				_, err = os.Stat(filepath.Join(dir, "tmp-1"))
+				assert.True(t, os.IsNotExist(err))
+			},
+		},
+		{
+			testName: "Object cached by other process is found",
+			testFunc: func(t *testing.T, dir string) {
+				d, err := NewDisk(dir, 100, 10)
+				assert.NoError(t, err)
+
+				other, err := NewDisk(dir, 100, 10)
+				assert.NoError(t, err)
+
+				add(t, other, "object", []byte("abc"))
+
+				data, ok := get(d, "object", 0, 3)
+				assert.True(t, ok)
+				assert.Equal(t, []byte("abc"), data)
+				assert.Equal(t, int64(3), d.Size())
+			},
+		},
+		{
+			testName: "Corrupted content is dropped",
+			testFunc: func(t *testing.T, dir string) {
+				d, err := NewDisk(dir, 100, 10)
+				assert.NoError(t, err)
+
+				add(t, d, "object", []byte("abc"))
+
+				path := filepath.Join(dir, fileName("bucket", "object")+dataExt)
+				assert.NoError(t, ioutil.WriteFile(path, []byte("abd"), 0600))
+
+				_, ok := get(d, "object", 0, 3)
+				assert.False(t, ok)
+				assert.Equal(t, int64(0), d.Size())
+
+				_, err = os.Stat(path)
+				assert.True(t, os.IsNotExist(err))
+			},
+		},
+		{
+			testName: "Least recently used object is evicted",
+			testFunc: func(t *testing.T, dir string) {
+				d, err := NewDisk(dir, 6, 3)
+				assert.NoError(t, err)
+
+				add(t, d, "a", []byte("aaa"))
+				add(t, d, "b", []byte("bbb"))
+
+				_, ok := get(d, "a", 0, 3)
+				assert.True(t, ok)
+
+				add(t, d, "c", []byte("ccc"))
+
+				_, ok = get(d, "a", 0, 3)
+				assert.True(t, ok)
+				_, ok = get(d, "b", 0, 3)
+				assert.False(t, ok)
+				assert.Equal(t, int64(6), d.Size())
+			},
+		},
+		{
+			testName: "Big objects are not cached",
+			testFunc: func(t *testing.T, dir string) {
+				d, err := NewDisk(dir, 100, 2)
+				assert.NoError(t, err)
+
+				w, err := d.NewWriter("bucket", "object", "etag")
+				assert.NoError(t, err)
+
+				_, err = w.Write([]byte("abc"))
+				assert.NoError(t, err)
+				assert.Error(t, w.Commit())
+
+				files, err := ioutil.ReadDir(dir)
+				assert.NoError(t, err)
+				assert.Empty(t, files)
+			},
+		},
+		{
+			testName: "Invalidated object is dropped",
+			testFunc: func(t *testing.T, dir string) {
+				d, err := NewDisk(dir, 100, 10)
+				assert.NoError(t, err)
+
+				add(t, d, "object", []byte("abc"))
+				d.Invalidate("bucket", "object")
+
+				_, ok := get(d, "object", 0, 3)
+				assert.False(t, ok)
+
+				files, err := ioutil.ReadDir(dir)
+				assert.NoError(t, err)
+				assert.Empty(t, files)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "ditto-cache")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			c.testFunc(t, dir)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Memory keeps content of small objects in memory, least recently used objects are evicted
+// once total size exceeds maxSize. Objects bigger than maxObjectSize are never cached.
+// Content is cached with ETag it was read with, and served only to reads with the same ETag.
+type Memory struct {
+	maxSize       int64
+	maxObjectSize int64
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	// lru holds entries most recently used first
+	lru *list.List
+}
+
+type memoryEntry struct {
+	key  string
+	etag string
+	data []byte
+}
+
+// NewMemory creates cache keeping up to maxSize bytes of objects not bigger than maxObjectSize.
+func NewMemory(maxSize, maxObjectSize int64) *Memory {
+	return &Memory{
+		maxSize:       maxSize,
+		maxObjectSize: maxObjectSize,
+		entries:       make(map[string]*list.Element),
+		lru:           list.New(),
+	}
+}
+
+// Fits returns true if object of size can be cached.
+func (c *Memory) Fits(size int64) bool {
+	return size >= 0 && size <= c.maxObjectSize && size <= c.maxSize
+}
+
+// Get returns length bytes of object starting at offset if they are cached with etag.
+func (c *Memory) Get(bucket, object, etag string, offset, length int64) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key(bucket, object)]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*memoryEntry)
+	if entry.etag != etag || offset < 0 || length < 0 || offset+length > int64(len(entry.data)) {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(e)
+
+	return entry.data[offset : offset+length], true
+}
+
+// Add caches data read from the beginning of object with etag.
+// Data may be only a prefix of object, it then serves reads within the prefix.
+func (c *Memory) Add(bucket, object, etag string, data []byte) {
+	if !c.Fits(int64(len(data))) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := key(bucket, object)
+	if e, ok := c.entries[k]; ok {
+		c.remove(e)
+	}
+
+	c.entries[k] = c.lru.PushFront(&memoryEntry{key: k, etag: etag, data: data})
+	c.size += int64(len(data))
+
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Invalidate drops cached content of object, it should be called once object is written or deleted.
+func (c *Memory) Invalidate(bucket, object string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key(bucket, object)]; ok {
+		c.remove(e)
+	}
+}
+
+// Size returns total size of cached objects.
+func (c *Memory) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+func (c *Memory) remove(e *list.Element) {
+	entry := e.Value.(*memoryEntry)
+
+	c.lru.Remove(e)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.data))
+}
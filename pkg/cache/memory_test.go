@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Ranges within cached content are served",
+			testFunc: func(t *testing.T) {
+				c := NewMemory(100, 10)
+				c.Add("bucket", "object", "etag", []byte("0123456789"))
+
+				data, ok := c.Get("bucket", "object", "etag", 0, 10)
+				assert.True(t, ok)
+				assert.Equal(t, []byte("0123456789"), data)
+
+				data, ok = c.Get("bucket", "object", "etag", 2, 3)
+				assert.True(t, ok)
+				assert.Equal(t, []byte("234"), data)
+
+				_, ok = c.Get("bucket", "object", "etag", 5, 10)
+				assert.False(t, ok)
+			},
+		},
+		{
+			testName: "Content with different etag is not served",
+			testFunc: func(t *testing.T) {
+				c := NewMemory(100, 10)
+				c.Add("bucket", "object", "etag", []byte("abc"))
+
+				_, ok := c.Get("bucket", "object", "other", 0, 3)
+				assert.False(t, ok)
+			},
+		},
+		{
+			testName: "Big objects are not cached",
+			testFunc: func(t *testing.T) {
+				c := NewMemory(100, 2)
+				c.Add("bucket", "object", "etag", []byte("abc"))
+
+				_, ok := c.Get("bucket", "object", "etag", 0, 3)
+				assert.False(t, ok)
+				assert.Equal(t, int64(0), c.Size())
+			},
+		},
+		{
+			testName: "Least recently used object is evicted",
+			testFunc: func(t *testing.T) {
+				c := NewMemory(6, 3)
+				c.Add("bucket", "a", "etag", []byte("aaa"))
+				c.Add("bucket", "b", "etag", []byte("bbb"))
+
+				_, ok := c.Get("bucket", "a", "etag", 0, 3)
+				assert.True(t, ok)
+
+				c.Add("bucket", "c", "etag", []byte("ccc"))
+
+				_, ok = c.Get("bucket", "a", "etag", 0, 3)
+				assert.True(t, ok)
+				_, ok = c.Get("bucket", "b", "etag", 0, 3)
+				assert.False(t, ok)
+				assert.Equal(t, int64(6), c.Size())
+			},
+		},
+		{
+			testName: "Invalidated object is dropped",
+			testFunc: func(t *testing.T) {
+				c := NewMemory(100, 10)
+				c.Add("bucket", "object", "etag", []byte("abc"))
+				c.Invalidate("bucket", "object")
+
+				_, ok := c.Get("bucket", "object", "etag", 0, 3)
+				assert.False(t, ok)
+				assert.Equal(t, int64(0), c.Size())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
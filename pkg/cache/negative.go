@@ -0,0 +1,103 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Negative remembers objects which were found missing for ttl, so repeated lookups don't reach backends.
+// At most size objects are remembered, the oldest one is forgotten first.
+type Negative struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds entries oldest first, all entries have the same ttl so it is also order of expiration
+	order *list.List
+
+	// now is overridden by tests
+	now func() time.Time
+}
+
+type negativeEntry struct {
+	key     string
+	expires time.Time
+}
+
+// NewNegative creates negative cache remembering up to size missing objects for ttl.
+func NewNegative(ttl time.Duration, size int) *Negative {
+	return &Negative{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func key(bucket, object string) string {
+	return bucket + "/" + object
+}
+
+// Add remembers object as missing.
+func (n *Negative) Add(bucket, object string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	k := key(bucket, object)
+	if e, ok := n.entries[k]; ok {
+		n.order.Remove(e)
+	}
+
+	n.entries[k] = n.order.PushBack(&negativeEntry{key: k, expires: n.now().Add(n.ttl)})
+
+	for n.order.Len() > n.size {
+		n.remove(n.order.Front())
+	}
+}
+
+// Contains returns true if object was found missing less than ttl ago.
+func (n *Negative) Contains(bucket, object string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	e, ok := n.entries[key(bucket, object)]
+	if !ok {
+		return false
+	}
+
+	if n.now().After(e.Value.(*negativeEntry).expires) {
+		n.remove(e)
+		return false
+	}
+
+	return true
+}
+
+// Invalidate forgets object, it should be called once object is written.
+func (n *Negative) Invalidate(bucket, object string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if e, ok := n.entries[key(bucket, object)]; ok {
+		n.remove(e)
+	}
+}
+
+// Len returns number of remembered objects, including expired ones which weren't looked up yet.
+func (n *Negative) Len() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.order.Len()
+}
+
+func (n *Negative) remove(e *list.Element) {
+	n.order.Remove(e)
+	delete(n.entries, e.Value.(*negativeEntry).key)
+}
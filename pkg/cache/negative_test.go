@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegative(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Missing object is remembered until ttl passes",
+			testFunc: func(t *testing.T) {
+				now := time.Now()
+
+				n := NewNegative(time.Minute, 10)
+				n.now = func() time.Time { return now }
+
+				assert.False(t, n.Contains("bucket", "object"))
+
+				n.Add("bucket", "object")
+				assert.True(t, n.Contains("bucket", "object"))
+				assert.False(t, n.Contains("bucket", "other"))
+
+				now = now.Add(2 * time.Minute)
+				assert.False(t, n.Contains("bucket", "object"))
+				assert.Equal(t, 0, n.Len())
+			},
+		},
+		{
+			testName: "Invalidated object is forgotten",
+			testFunc: func(t *testing.T) {
+				n := NewNegative(time.Minute, 10)
+
+				n.Add("bucket", "object")
+				n.Invalidate("bucket", "object")
+
+				assert.False(t, n.Contains("bucket", "object"))
+			},
+		},
+		{
+			testName: "Oldest object is forgotten when full",
+			testFunc: func(t *testing.T) {
+				n := NewNegative(time.Minute, 2)
+
+				n.Add("bucket", "a")
+				n.Add("bucket", "b")
+				n.Add("bucket", "a")
+				n.Add("bucket", "c")
+
+				assert.Equal(t, 2, n.Len())
+				assert.True(t, n.Contains("bucket", "a"))
+				assert.False(t, n.Contains("bucket", "b"))
+				assert.True(t, n.Contains("bucket", "c"))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
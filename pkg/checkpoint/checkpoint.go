@@ -0,0 +1,147 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"storj.io/ditto/pkg/replication"
+)
+
+var (
+	markersBucket   = []byte("markers")
+	completedBucket = []byte("completed")
+	failedBucket    = []byte("failed")
+)
+
+// Checkpoint persists progress of long running jobs such as seeding and sync, so interrupted job
+// resumes after the last processed key instead of starting from scratch.
+// Progress is tracked per key, which is a bucket or a bucket and prefix.
+type Checkpoint struct {
+	db *bolt.DB
+}
+
+// Open opens checkpoint stored at path, file is created if it doesn't exist.
+func Open(path string) (*Checkpoint, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{markersBucket, completedBucket, failedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Checkpoint{db}, nil
+}
+
+// Marker returns object key after which processing of key resumes and whether key is already completed.
+func (c *Checkpoint) Marker(key string) (marker string, completed bool, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		marker = string(tx.Bucket(markersBucket).Get([]byte(key)))
+		completed = tx.Bucket(completedBucket).Get([]byte(key)) != nil
+
+		return nil
+	})
+
+	return
+}
+
+// Save records that all objects of key up to marker are processed.
+func (c *Checkpoint) Save(key, marker string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(markersBucket).Put([]byte(key), []byte(marker))
+	})
+}
+
+// Complete records that all objects of key are processed.
+func (c *Checkpoint) Complete(key string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(completedBucket).Put([]byte(key), []byte(time.Now().UTC().Format(time.RFC3339)))
+	})
+}
+
+// Reset forgets marker and completion of key, so it's processed from scratch next time.
+// Failed tasks are kept.
+func (c *Checkpoint) Reset(key string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(markersBucket).Delete([]byte(key)); err != nil {
+			return err
+		}
+
+		return tx.Bucket(completedBucket).Delete([]byte(key))
+	})
+}
+
+// Fail records task of key which failed, so it can be retried by next run.
+func (c *Checkpoint) Fail(key string, task replication.Task) error {
+	value, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(failedBucket).CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		return b.Put([]byte(task.Object), value)
+	})
+}
+
+// Failed returns failed tasks of key.
+func (c *Checkpoint) Failed(key string) ([]replication.Task, error) {
+	var tasks []replication.Task
+
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(failedBucket).Bucket([]byte(key))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			var task replication.Task
+			if err := json.Unmarshal(v, &task); err != nil {
+				return err
+			}
+
+			tasks = append(tasks, task)
+
+			return nil
+		})
+	})
+
+	return tasks, err
+}
+
+// Resolve removes failed task of object.
+func (c *Checkpoint) Resolve(key, object string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(failedBucket).Bucket([]byte(key))
+		if b == nil {
+			return nil
+		}
+
+		return b.Delete([]byte(object))
+	})
+}
+
+// Close closes underlying database.
+func (c *Checkpoint) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checkpoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/replication"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint.db")
+
+	c, err := Open(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Save("bucket/", "object1"))
+	assert.NoError(t, c.Fail("bucket/", replication.NewPutTask("bucket", "object0")))
+	assert.NoError(t, c.Fail("bucket/", replication.NewDeleteTask("bucket", "object1")))
+	assert.NoError(t, c.Close())
+
+	// progress survives reopening
+	c, err = Open(path)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	marker, completed, err := c.Marker("bucket/")
+	assert.NoError(t, err)
+	assert.Equal(t, "object1", marker)
+	assert.False(t, completed)
+
+	assert.NoError(t, c.Resolve("bucket/", "object0"))
+
+	failed, err := c.Failed("bucket/")
+	assert.NoError(t, err)
+	assert.Equal(t, []replication.Task{replication.NewDeleteTask("bucket", "object1")}, failed)
+
+	assert.NoError(t, c.Complete("bucket/"))
+
+	_, completed, err = c.Marker("bucket/")
+	assert.NoError(t, err)
+	assert.True(t, completed)
+
+	assert.NoError(t, c.Reset("bucket/"))
+
+	marker, completed, err = c.Marker("bucket/")
+	assert.NoError(t, err)
+	assert.Equal(t, "", marker)
+	assert.False(t, completed)
+
+	// failed tasks are kept by reset
+	failed, err = c.Failed("bucket/")
+	assert.NoError(t, err)
+	assert.Len(t, failed, 1)
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type Credentials struct {
 	Endpoint  string
 	AccessKey string
@@ -15,6 +17,46 @@ type Config struct {
 	GetObjectOptions *GetObjectOptions
 	CopyOptions      *CopyOptions
 	DeleteOptions    *DeleteOptions
+	FilterOptions    *FilterOptions
+	BucketMapping    *BucketMappingOptions
+	FailoverOptions  *FailoverOptions
+	CircuitBreaker   *CircuitBreakerOptions
+	HealthCheck      *HealthCheckOptions
+	Admin            *AdminOptions
+	Timeouts         *TimeoutOptions
+	Journal          *JournalOptions
+	Shadow           *ShadowOptions
+	DryRun           *DryRunOptions
+	RateLimit        *RateLimitOptions
+	Replication      *ReplicationOptions
+	ReadOnly         *ReadOnlyOptions
+	Metadata         *MetadataOptions
+	GC               *GCOptions
+	Sync             *SyncOptions
+	Seed             *SeedOptions
+	State            *StateOptions
+	Webhook          *WebhookOptions
+	Events           *EventsOptions
+	Metrics          *MetricsOptions
+	StatsD           *StatsDOptions
+	Tracing          *TracingOptions
+	Debug            *DebugOptions
+	Readiness        *ReadinessOptions
+	Log              *LogOptions
+	Audit            *AuditOptions
+	Alert            *AlertOptions
+	Read             *ReadOptions
+	Cache            *CacheOptions
+	Compression      *CompressionOptions
+	Encryption       *EncryptionOptions
+	Quota            *QuotaOptions
+	Accounting       *AccountingOptions
+	Rotation         *RotationOptions
+	Vault            *VaultOptions
+	Secrets          *SecretsOptions
+	Reload           *ReloadOptions
+	Repair           *RepairOptions
+	Tenants          []*TenantOptions
 }
 
 type DefaultOptions struct {
@@ -30,10 +72,29 @@ type ListOptions struct {
 type PutOptions struct {
 	DefaultOptions         *DefaultOptions
 	CreateBucketIfNotExist bool
+	// Objects bigger than AsyncSizeThreshold bytes are mirrored in background.
+	// Zero value disables background mirroring.
+	AsyncSizeThreshold int64
+	// BufferSize is a number of bytes buffered in memory for slow alter writes.
+	// Data above BufferSize is spilled to temp file in SpillDir, zero value disables buffering.
+	BufferSize int
+	SpillDir   string
+	// VerifyChecksum compares ETag and size of both copies after mirrored write.
+	// Mismatched objects are scheduled for re-replication.
+	VerifyChecksum bool
+	// DetectContentType sets Content-Type of objects uploaded without it,
+	// so backends don't apply different defaults.
+	DetectContentType bool
+	// Dedup skips writes to alter which already holds object with the same content hash and metadata.
+	// It requires Metadata.ContentHash.
+	Dedup bool
 }
 
 type GetObjectOptions struct {
 	DefaultOptions *DefaultOptions
+	// VerifyReads reads object from both backends and logs content divergence.
+	// Client is always served with prime copy.
+	VerifyReads bool
 }
 
 type CopyOptions struct {
@@ -42,6 +103,423 @@ type CopyOptions struct {
 
 type DeleteOptions struct {
 	DefaultOptions *DefaultOptions
+	// SoftDelete moves objects deleted on alter under TrashPrefix instead of removing them.
+	// Objects kept in trash longer than TrashRetention are purged, zero keeps them forever.
+	SoftDelete     bool
+	TrashPrefix    string
+	TrashRetention time.Duration
+}
+
+// FilterOptions decides which objects are mirrored to alter.
+// Objects that are filtered out are written only to prime.
+type FilterOptions struct {
+	IncludePrefixes []string
+	ExcludePrefixes []string
+	IncludePatterns []string
+	ExcludePatterns []string
+}
+
+// BucketMappingOptions translates prime bucket names to alter bucket names.
+// Explicit Mapping has priority, other buckets get AlterPrefix and AlterSuffix.
+type BucketMappingOptions struct {
+	Mapping     map[string]string
+	AlterPrefix string
+	AlterSuffix string
+}
+
+// FailoverOptions controls promotion of alter when prime becomes unreachable.
+// Failover happens after FailureThreshold consecutive connection errors from prime.
+// Prime is restored after RecoveryThreshold successful probes, but not sooner than MinDuration after failover.
+// Writes accepted by alter meanwhile are backfilled to prime, those which don't fit in backfill queue are journaled,
+// so failover requires Journal.Path.
+type FailoverOptions struct {
+	Enabled           bool
+	FailureThreshold  int
+	RecoveryThreshold int
+	MinDuration       time.Duration
+	ProbeInterval     time.Duration
+}
+
+// CircuitBreakerOptions controls per backend circuit breakers.
+// Breaker opens after FailureThreshold consecutive connection errors and probes backend again after Cooldown.
+type CircuitBreakerOptions struct {
+	Enabled          bool
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// HealthCheckOptions controls background probing of backends.
+// Backends are probed with HEAD Bucket if Bucket is set, otherwise with ListBuckets.
+type HealthCheckOptions struct {
+	Enabled  bool
+	Interval time.Duration
+	Timeout  time.Duration
+	Bucket   string
+}
+
+// AdminOptions controls administration HTTP endpoint, empty Address disables it.
+// If Token is set, requests other than health probes must carry "Authorization: Bearer <Token>".
+// Without Token the endpoint is served on loopback Address only, e.g. 127.0.0.1:8080.
+// GRPCAddress serves the same API over gRPC, see pkg/admin/adminpb, empty GRPCAddress disables it.
+// It's authenticated by Token the same way.
+type AdminOptions struct {
+	Address     string
+	GRPCAddress string
+	Token       string
+}
+
+// TimeoutOptions bounds every backend call by timeout of its operation type, zero value disables timeout.
+// Put covers PutObject, CopyObject and MakeBucket, Get covers GetObject, GetObjectInfo and GetBucketInfo.
+// GetObject and PutObject are bounded by time without data transferred, not by duration of the transfer.
+type TimeoutOptions struct {
+	Put    time.Duration
+	Get    time.Duration
+	List   time.Duration
+	Delete time.Duration
+}
+
+// JournalOptions controls persistent journal of failed and skipped alter operations.
+// Empty Path disables journal. Journal is replayed to alter every ReplayInterval.
+// Writes accepted by alter during failover which couldn't be backfilled are journaled to BackfillPath,
+// Path with ".backfill" suffix by default, and replayed to prime once it's back.
+type JournalOptions struct {
+	Path           string
+	BackfillPath   string
+	ReplayInterval time.Duration
+}
+
+// BackfillJournalPath returns path of journal of backfill.
+func (o *JournalOptions) BackfillJournalPath() string {
+	if o.BackfillPath != "" {
+		return o.BackfillPath
+	}
+
+	return o.Path + ".backfill"
+}
+
+// ShadowOptions controls shadow mode used to evaluate new alter backend.
+// Only Percentage of writes is sent to alter, results of both backends are compared and recorded.
+// Alter never affects client-visible result and failed shadow writes are not replicated.
+type ShadowOptions struct {
+	Enabled    bool
+	Percentage float64
+}
+
+// DryRunOptions controls dry run of mirroring.
+// When Enabled, alter write operations are logged but not executed.
+// It can't be combined with Shadow, FailoverOptions and PutOptions.VerifyChecksum, which rely on writes reaching alter.
+type DryRunOptions struct {
+	Enabled bool
+}
+
+// RateLimitOptions limits requests per second sent to each backend, zero value means unlimited.
+// Reads and writes are limited separately, Burst is a number of requests allowed at once.
+type RateLimitOptions struct {
+	PrimeRead  float64
+	PrimeWrite float64
+	AlterRead  float64
+	AlterWrite float64
+	Burst      int
+	// AlterBandwidth caps bytes per second uploaded to alter, zero value means unlimited.
+	AlterBandwidth int64
+}
+
+// ReplicationOptions tunes background replication queues.
+// Queue is processed by Workers, each running up to Concurrency tasks at once.
+// Tasks enqueued above QueueDepth are journaled or dropped.
+type ReplicationOptions struct {
+	Workers     int
+	QueueDepth  int
+	Concurrency int
+}
+
+// ReadOnlyOptions controls degraded mode, in which writes are rejected with 503 instead of diverging backends further.
+// Gateway becomes read-only when journal exceeds MaxJournalSize operations or alter is down for longer than MaxAlterDowntime.
+// Zero limits are not checked.
+type ReadOnlyOptions struct {
+	Enabled          bool
+	MaxJournalSize   int
+	MaxAlterDowntime time.Duration
+}
+
+// MetadataOptions controls handling of object metadata.
+// Normalize canonicalizes metadata keys and drops reserved headers before writing to and after reading from backends.
+// ContentHash stores sha256 of content computed by ditto with every written object, so copies are compared
+// by content instead of backend specific ETags. Content is buffered while it's hashed, see PutOptions.BufferSize.
+// Multipart uploads are hashed only if their parts are uploaded one by one in order and object is at most 5 GiB.
+type MetadataOptions struct {
+	Normalize   bool
+	ContentHash bool
+}
+
+// GCOptions controls garbage collection of orphans - objects which exist on alter but not on prime.
+// Collection runs every Interval, or on cron Schedule if it's set. Objects younger than MinAge are never collected.
+// Orphans are moved under QuarantinePrefix of the same bucket, ".orphans/" by default, they are removed only
+// if State records their deletion on prime. Quarantine is kept for compatibility, orphans are always quarantined.
+type GCOptions struct {
+	Enabled          bool
+	Interval         time.Duration
+	Schedule         string
+	MinAge           time.Duration
+	Quarantine       bool
+	QuarantinePrefix string
+}
+
+// SyncOptions controls periodic delta sync, which copies objects missing or changed on alter every Interval,
+// or on cron Schedule, e.g. "0 2 * * *" for nightly sync at 02:00, if it's set.
+// With Delete objects which exist only on alter are deleted.
+// Progress is checkpointed to CheckpointPath, so interrupted sync resumes where it stopped.
+// Empty CheckpointPath disables resuming.
+type SyncOptions struct {
+	Enabled        bool
+	Interval       time.Duration
+	Schedule       string
+	Delete         bool
+	CheckpointPath string
+}
+
+// SeedOptions controls initial seeding of a new alter with objects existing on prime.
+// Seeding runs on gateway start with Workers parallel copies, progress is checkpointed to CheckpointPath,
+// so restarted gateway resumes seeding and doesn't repeat it once finished. Empty CheckpointPath disables resuming.
+type SeedOptions struct {
+	Enabled        bool
+	Workers        int
+	CheckpointPath string
+}
+
+// StateOptions controls database of per-object replication status stored at Path.
+// Empty Path disables tracking.
+type StateOptions struct {
+	Path string
+}
+
+// WebhookOptions controls notifications about alter writes which failed permanently.
+// Events are POSTed as JSON to URL, failed deliveries are retried up to Retries times. Empty URL disables notifications.
+type WebhookOptions struct {
+	URL     string
+	Timeout time.Duration
+	Retries int
+}
+
+// EventsOptions controls export of events about every mirrored operation to message broker.
+// Type is "nats" or "kafka", Address is NATS server address or Kafka REST Proxy URL,
+// events are published to Topic. Empty Type disables export.
+type EventsOptions struct {
+	Type    string
+	Address string
+	Topic   string
+}
+
+// MetricsOptions controls Prometheus metrics served at /metrics of administration endpoint.
+type MetricsOptions struct {
+	Enabled bool
+}
+
+// StatsDOptions controls export of metrics to statsd at Host:Port, names are prefixed by Prefix.
+// Datadog enables DogStatsD tags instead of appending tags to names. Empty Host disables export.
+type StatsDOptions struct {
+	Host    string
+	Port    int
+	Prefix  string
+	Datadog bool
+}
+
+// TracingOptions controls tracing of requests. Spans are sent to Zipkin compatible Endpoint, e.g. Jaeger collector,
+// as ServiceName. New traces are sampled with probability SampleRate. Empty Endpoint disables tracing.
+type TracingOptions struct {
+	Endpoint    string
+	ServiceName string
+	SampleRate  float64
+}
+
+// DebugOptions controls debug HTTP endpoint serving pprof profiles and expvar variables.
+// Empty Address disables it.
+type DebugOptions struct {
+	Address string
+}
+
+// ReadinessOptions controls /readyz of administration endpoint. Gateway isn't ready until seeding of alter
+// is done if WaitForSeed is set, nor while journal holds more than MaxJournalLen tasks, zero disables the limit.
+type ReadinessOptions struct {
+	WaitForSeed   bool
+	MaxJournalLen int
+}
+
+// LogOptions controls logger of gateway and CLI. Entries below Level, one of debug, info, warn or error,
+// are discarded. Format is either json, for a JSON object per line, or console.
+// Log is written to stdout unless File is set. File is rotated once it grows over MaxSize megabytes
+// or gets older than MaxAge, rotated files are gzipped if Compress is set and only MaxBackups newest are kept.
+// Zero disables respective limit.
+// Log is sent to syslog instead if Syslog address is set, either "local" for local daemon or
+// network address such as udp://host:514, or to journald if Journald is set, both tagged with SyslogTag.
+// MirroringLevel, ReplicationLevel, SyncLevel and CLILevel override Level for mirroring handlers,
+// replication queue, sync engine and command line respectively, empty means Level.
+type LogOptions struct {
+	Level  string
+	Format string
+
+	MirroringLevel   string
+	ReplicationLevel string
+	SyncLevel        string
+	CLILevel         string
+
+	File       string
+	MaxSize    int
+	MaxAge     time.Duration
+	MaxBackups int
+	Compress   bool
+
+	Syslog    string
+	SyslogTag string
+	Journald  bool
+}
+
+// AuditOptions controls audit log recording every S3 operation with its outcome on both backends.
+// Records are appended to File, separately from the log. Empty File disables audit.
+type AuditOptions struct {
+	File string
+}
+
+// AlertOptions controls alerts fired when lag of replication exceeds MaxLag or MaxFailures consecutive
+// replication tasks fail, zero disables respective alert. Alerts are posted to Slack incoming webhook
+// SlackURL and mailed from EmailFrom to comma separated EmailTo through SMTP server at SMTPAddress,
+// authenticated as SMTPUsername if set. Alerting is disabled unless Slack or email is configured.
+type AlertOptions struct {
+	MaxLag      time.Duration
+	MaxFailures int
+
+	SlackURL string
+
+	SMTPAddress  string
+	SMTPUsername string
+	SMTPPassword string
+	EmailFrom    string
+	EmailTo      string
+}
+
+// ReadOptions controls which backend serves reads first. In "prime" Mode reads go to prime and fall back
+// to alter, in "auto" Mode GetObject and ListObjects go to backend which has been faster recently
+// and slower backend is probed once per ProbeInterval to keep its latency up to date.
+// In "hedged" Mode GetObject is sent to both backends, the one which responds first serves the client
+// and the other read is canceled. ETag checked by GetObject describes prime copy, so it is never checked against alter.
+type ReadOptions struct {
+	Mode          string
+	ProbeInterval time.Duration
+}
+
+// CacheOptions controls caching of reads. Objects found missing on both backends are remembered
+// for MissingTTL, up to MissingSize objects, and reported missing without reaching backends
+// until they are written. Zero MissingTTL disables negative caching.
+// List of buckets is cached for BucketsTTL until bucket is created or deleted, zero disables caching.
+// Up to MemorySize bytes of objects not bigger than MemoryObjectSize bytes are cached in memory
+// until they are written or deleted, zero MemorySize disables caching.
+// Objects missing in memory are cached in DiskDir, up to DiskSize bytes of objects not bigger than
+// DiskObjectSize bytes. Integrity of cached objects is verified on every read. Empty DiskDir disables disk cache.
+type CacheOptions struct {
+	MissingTTL       time.Duration
+	MissingSize      int
+	BucketsTTL       time.Duration
+	MemorySize       int64
+	MemoryObjectSize int64
+	DiskDir          string
+	DiskSize         int64
+	DiskObjectSize   int64
+}
+
+// CompressionOptions enables compression of objects written to alter with Algorithm, "gzip" or "zstd",
+// "none" disables it. Objects smaller than MinSize bytes are written as is.
+// Compressed objects are decompressed when read from alter only while compression is enabled.
+// Listings of alter report compressed sizes, sync compares copies by uncompressed ones.
+type CompressionOptions struct {
+	Algorithm string
+	MinSize   int64
+}
+
+// EncryptionOptions enables AES-256-GCM encryption of objects written to alter with Key, hex or base64 encoded,
+// or with key read from KeyFile if Key is empty. Encryption is disabled if neither is set.
+// Encrypted objects are decrypted when read from alter only while encryption is enabled.
+// Listings of alter report encrypted sizes, sync compares copies by plaintext ones.
+type EncryptionOptions struct {
+	Key     string
+	KeyFile string
+}
+
+// QuotaOptions limits total size in bytes and amount of objects of every bucket to MaxSize and MaxObjects,
+// Buckets overrides both limits for named buckets. Zero limit is unlimited.
+// Usage of buckets is tracked in State database, so quotas are enforced only while State.Path is set.
+type QuotaOptions struct {
+	MaxSize    int64
+	MaxObjects int64
+	Buckets    map[string]*BucketQuota
+}
+
+// AccountingOptions enables accounting of traffic and storage of buckets per backend persisted to Path,
+// usage is written to Path every FlushInterval and on shutdown. Empty Path disables accounting.
+type AccountingOptions struct {
+	Path          string
+	FlushInterval time.Duration
+}
+
+// RotationOptions enables replacing of backend keys at runtime. Keys of Server1 and Server2 are reloaded
+// from File, JSON of the same structure as config, or from config file if File is empty,
+// when the gateway receives SIGHUP or, if WatchInterval is positive, when the file is modified.
+type RotationOptions struct {
+	Enabled       bool
+	File          string
+	WatchInterval time.Duration
+}
+
+// VaultOptions makes keys of Server1 and Server2 read from secrets at Server1Path and Server2Path of Vault
+// at Address instead of config. Vault is authenticated with Token, VAULT_TOKEN environment variable
+// if Token is empty, or by AppRole login with RoleID and SecretID. Positive RefreshInterval re-reads keys
+// periodically, so keys rotated in Vault are used without restart. Empty Address disables Vault.
+type VaultOptions struct {
+	Address         string
+	Token           string
+	RoleID          string
+	SecretID        string
+	Server1Path     string
+	Server2Path     string
+	RefreshInterval time.Duration
+}
+
+// SecretsOptions locates passphrase of secrets encrypted by `ditto config encrypt` in KeyFile.
+// DITTO_CONFIG_PASSPHRASE environment variable has priority over KeyFile.
+type SecretsOptions struct {
+	KeyFile string
+}
+
+// ReloadOptions enables applying changes of config file without restart, when the gateway receives SIGHUP
+// or, if WatchInterval is positive, when the file is modified. Only Log levels, Read mode and probe interval,
+// RateLimit and FilterOptions are applied, changes of other sections take effect after restart.
+type ReloadOptions struct {
+	Enabled       bool
+	WatchInterval time.Duration
+}
+
+// RepairOptions controls how `ditto repair` resolves differences between backends. With Policy "prime"
+// alter is made identical to prime, objects existing only on alter are deleted, "alter" makes prime identical
+// to alter. Default "merge" copies missing objects in both directions and differing ones from prime to alter,
+// so nothing is deleted.
+type RepairOptions struct {
+	Policy string
+}
+
+// BucketQuota limits size and amount of objects of a single bucket, zero limit is unlimited.
+type BucketQuota struct {
+	MaxSize    int64
+	MaxObjects int64
+}
+
+// TenantOptions configures backend pair serving one tenant. Requests signed with one of AccessKeys,
+// or otherwise addressing bucket starting with BucketPrefix, are served by Server1 and Server2 of Config
+// instead of root ones. Sections set in Config replace respective root sections as a whole, unset sections
+// are inherited, see Config.ForTenant.
+type TenantOptions struct {
+	Name         string
+	AccessKeys   []string
+	BucketPrefix string
+	Config       *Config
 }
 
 // Creates new instance of Config
@@ -110,6 +588,88 @@ func (c *Config) WithCopyOptions(options *DefaultOptions) *Config {
 	return c
 }
 
+func (c *Config) WithFilterOptions(include, exclude []string, includePatterns, excludePatterns []string) *Config {
+	c.FilterOptions = &FilterOptions{
+		IncludePrefixes: include,
+		ExcludePrefixes: exclude,
+		IncludePatterns: includePatterns,
+		ExcludePatterns: excludePatterns,
+	}
+
+	return c
+}
+
+func (c *Config) WithBucketMapping(mapping map[string]string, alterPrefix, alterSuffix string) *Config {
+	c.BucketMapping = &BucketMappingOptions{
+		Mapping:     mapping,
+		AlterPrefix: alterPrefix,
+		AlterSuffix: alterSuffix,
+	}
+
+	return c
+}
+
+func (c *Config) WithFailoverOptions(failureThreshold, recoveryThreshold int, minDuration, probeInterval time.Duration) *Config {
+	c.FailoverOptions = &FailoverOptions{
+		Enabled:           true,
+		FailureThreshold:  failureThreshold,
+		RecoveryThreshold: recoveryThreshold,
+		MinDuration:       minDuration,
+		ProbeInterval:     probeInterval,
+	}
+
+	return c
+}
+
+func (c *Config) WithCircuitBreaker(failureThreshold int, cooldown time.Duration) *Config {
+	c.CircuitBreaker = &CircuitBreakerOptions{
+		Enabled:          true,
+		FailureThreshold: failureThreshold,
+		Cooldown:         cooldown,
+	}
+
+	return c
+}
+
+func (c *Config) WithHealthCheck(interval, timeout time.Duration, bucket string) *Config {
+	c.HealthCheck = &HealthCheckOptions{
+		Enabled:  true,
+		Interval: interval,
+		Timeout:  timeout,
+		Bucket:   bucket,
+	}
+
+	return c
+}
+
+func (c *Config) WithAdmin(address string) *Config {
+	c.Admin = &AdminOptions{
+		Address: address,
+	}
+
+	return c
+}
+
+func (c *Config) WithTimeouts(put, get, list, delete time.Duration) *Config {
+	c.Timeouts = &TimeoutOptions{
+		Put:    put,
+		Get:    get,
+		List:   list,
+		Delete: delete,
+	}
+
+	return c
+}
+
+func (c *Config) WithJournal(path string, replayInterval time.Duration) *Config {
+	c.Journal = &JournalOptions{
+		Path:           path,
+		ReplayInterval: replayInterval,
+	}
+
+	return c
+}
+
 func NewCredentials(endpoint string, accessKey string, secretKey string) *Credentials {
 
 	return &Credentials{
@@ -194,3 +754,32 @@ func (c *Config) GetMergedAlterCredentials(options *DefaultOptions) *Credentials
 func (c *Credentials) IsEmpty() bool {
 	return "" == c.Endpoint || "" == c.AccessKey || "" == c.SecretKey
 }
+
+// IsVaulted returns true if keys of backends are read from Vault.
+func (c *Config) IsVaulted() bool {
+	return c.Vault != nil && c.Vault.Address != ""
+}
+
+// For returns quota of bucket.
+func (q *QuotaOptions) For(bucket string) BucketQuota {
+	if b, ok := q.Buckets[bucket]; ok && b != nil {
+		return *b
+	}
+
+	return BucketQuota{MaxSize: q.MaxSize, MaxObjects: q.MaxObjects}
+}
+
+// IsEnabled returns true if any bucket is limited.
+func (q *QuotaOptions) IsEnabled() bool {
+	if q.MaxSize > 0 || q.MaxObjects > 0 {
+		return true
+	}
+
+	for _, b := range q.Buckets {
+		if b != nil && (b.MaxSize > 0 || b.MaxObjects > 0) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,55 +1,83 @@
 package config
 
 import (
-	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"os"
 	"os/user"
+	"path/filepath"
+	"strings"
 )
 
-// Reads config from default config location($HOME/.ditto/config.json or from user-defined location
-// Returns parsed config or error
-func ReadConfig(useDefaults bool) (config *Config, err error) {
-	config, err = parseConfig(useDefaults)
+// EnvPrefix prefixes names of environment variables overriding config keys, see EnvName.
+const EnvPrefix = "DITTO"
+
+// Extensions are supported formats of config file. Config file in $HOME/.ditto is looked up in this order,
+// format of user-defined config file is given by its extension.
+var Extensions = []string{"json", "yaml", "yml", "toml"}
 
+// Reads config from default config location ($HOME/.ditto/config.json, config.yaml, config.yml or config.toml,
+// the first one found) or from user-defined location, and validates it, see Config.Validate.
+// With useDefaults values are resolved in order of precedence: environment variables named by EnvName,
+// profile selected by SetProfile or ProfileEnv, config file, defaults. Without useDefaults only config file
+// is read, so commands writing config back to file don't persist environment, profile and defaults.
+// Returns parsed config, *ReadError if it can't be read or *ValidationError if it's invalid.
+func ReadConfig(useDefaults bool) (config *Config, err error) {
+	config, err = LoadConfig(useDefaults)
 	if err != nil {
+		return nil, &ReadError{Err: err}
+	}
+
+	if err = config.Validate(); err != nil {
 		return nil, err
 	}
 
-	if config.Server1 == nil || config.Server1.IsEmpty() ||
-		config.Server2 == nil || config.Server2.IsEmpty() {
+	return config, nil
+}
 
-		return nil, errors.New("Credentials are not set. Please define credentials with `ditto config set`")
+// ReadError is returned by ReadConfig if config file can't be read, parsed or decrypted.
+type ReadError struct {
+	Err error
+}
+
+func (e *ReadError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying error.
+func (e *ReadError) Cause() error {
+	return e.Err
+}
+
+// LoadConfig reads config like ReadConfig and decrypts its secrets, but doesn't validate it.
+func LoadConfig(useDefaults bool) (config *Config, err error) {
+	config, err = parseConfig(useDefaults)
+	if err != nil {
+		return nil, err
 	}
 
-	// bytes, _ := json.MarshalIndent(config, "", "\t")
-	// println(string(bytes))
+	if err = config.DecryptSecrets(); err != nil {
+		return nil, err
+	}
 
 	return config, nil
 }
 
+// Path returns path of config file read by ReadConfig, empty if no file was read yet.
+func Path() string {
+	return viper.ConfigFileUsed()
+}
+
 // Gather config values and applies default values
 func parseConfig(useDefaults bool) (config *Config, err error) {
-	if viper.IsSet("configPath") {
-		configPath := viper.GetString("configPath")
-
-		viper.SetConfigFile(configPath)
-		// Create empty file if not exist
-		os.OpenFile(configPath, os.O_RDONLY|os.O_CREATE, 0666)
-	} else {
-		viper.SetConfigName("config")
-		viper.AddConfigPath("$HOME/.ditto/")
-		viper.SetConfigType("json")
-
-		// Create empty file if not exist
-		user, err := user.Current()
-		if err == nil {
-			os.OpenFile(user.HomeDir+"/.ditto/config.json", os.O_RDONLY|os.O_CREATE, 0666)
-		}
-	}
+	configPath := FindFile()
+
+	viper.SetConfigFile(configPath)
+	// Create empty file if not exist
+	os.OpenFile(configPath, os.O_RDONLY|os.O_CREATE, 0666)
 
 	if useDefaults {
 		setDefaults()
+		bindEnv()
 	}
 
 	err = viper.ReadInConfig()
@@ -57,6 +85,12 @@ func parseConfig(useDefaults bool) (config *Config, err error) {
 		return nil, err
 	}
 
+	if name := Profile(); useDefaults && name != "" {
+		if err = applyProfile(name); err != nil {
+			return nil, err
+		}
+	}
+
 	err = viper.Unmarshal(&config)
 	if err != nil {
 		return nil, err
@@ -65,6 +99,47 @@ func parseConfig(useDefaults bool) (config *Config, err error) {
 	return config, nil
 }
 
+// FindFile returns path of config file read by ReadConfig, which is set with --config flag or is found
+// in $HOME/.ditto. File may not exist yet.
+func FindFile() string {
+	if viper.IsSet("configPath") {
+		return viper.GetString("configPath")
+	}
+
+	dir := os.ExpandEnv("$HOME/.ditto")
+	if user, err := user.Current(); err == nil {
+		dir = filepath.Join(user.HomeDir, ".ditto")
+	}
+
+	return defaultConfigFile(dir)
+}
+
+// defaultConfigFile returns the first config file of supported format found in dir, config.json if there is none.
+func defaultConfigFile(dir string) string {
+	for _, ext := range Extensions {
+		file := filepath.Join(dir, "config."+ext)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+
+	return filepath.Join(dir, "config.json")
+}
+
+// EnvName returns name of environment variable overriding config key, which is EnvPrefix and key in upper case
+// with dots replaced by underscores, e.g. DITTO_SERVER1_ACCESSKEY overrides Server1.AccessKey.
+// Lists are set as comma separated values.
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+// bindEnv makes environment variables override config keys.
+func bindEnv() {
+	for _, key := range GetKeysArray() {
+		viper.BindEnv(key, EnvName(key))
+	}
+}
+
 func setDefaults() {
 	// Root defaults
 	viper.SetDefault(DEFAULT_OPTIONS_DEFAULT_SOURCE, "server1")
@@ -79,10 +154,17 @@ func setDefaults() {
 	viper.SetDefault(PUT_DEFAULT_SOURCE, "server1")
 	viper.SetDefault(PUT_THROW_IMMEDIATELY, false)
 	viper.SetDefault(PUT_CREATE_BUCKET_IF_NOT_EXIST, true)
+	viper.SetDefault(PUT_ASYNC_SIZE_THRESHOLD, 0)
+	viper.SetDefault(PUT_BUFFER_SIZE, 0)
+	viper.SetDefault(PUT_SPILL_DIR, "")
+	viper.SetDefault(PUT_VERIFY_CHECKSUM, false)
+	viper.SetDefault(PUT_DETECT_CONTENT_TYPE, true)
+	viper.SetDefault(PUT_DEDUP, false)
 
 	// GetObjectOptions defaults
 	viper.SetDefault(GET_OBJECT_DEFAULT_SOURCE, "server2")
 	viper.SetDefault(GET_OBJECT_THROW_IMMEDIATELY, false)
+	viper.SetDefault(GET_OBJECT_VERIFY_READS, false)
 
 	// CopyOptions defaults
 	viper.SetDefault(COPY_DEFAULT_SOURCE, "server1")
@@ -91,4 +173,206 @@ func setDefaults() {
 	// DeleteOptions defaults
 	viper.SetDefault(DELETE_DEFAULT_SOURCE, "server1")
 	viper.SetDefault(DELETE_THROW_IMMEDIATELY, true)
+	viper.SetDefault(DELETE_SOFT_DELETE, false)
+	viper.SetDefault(DELETE_TRASH_PREFIX, ".trash/")
+	viper.SetDefault(DELETE_TRASH_RETENTION, "0s")
+
+	// FailoverOptions defaults
+	viper.SetDefault(FAILOVER_ENABLED, false)
+	viper.SetDefault(FAILOVER_FAILURE_THRESHOLD, 5)
+	viper.SetDefault(FAILOVER_RECOVERY_THRESHOLD, 3)
+	viper.SetDefault(FAILOVER_MIN_DURATION, "30s")
+	viper.SetDefault(FAILOVER_PROBE_INTERVAL, "5s")
+
+	// CircuitBreaker defaults
+	viper.SetDefault(CIRCUIT_BREAKER_ENABLED, false)
+	viper.SetDefault(CIRCUIT_BREAKER_FAILURE_THRESHOLD, 5)
+	viper.SetDefault(CIRCUIT_BREAKER_COOLDOWN, "30s")
+
+	// HealthCheck defaults
+	viper.SetDefault(HEALTH_CHECK_ENABLED, false)
+	viper.SetDefault(HEALTH_CHECK_INTERVAL, "10s")
+	viper.SetDefault(HEALTH_CHECK_TIMEOUT, "5s")
+	viper.SetDefault(HEALTH_CHECK_BUCKET, "")
+
+	// Admin defaults
+	viper.SetDefault(ADMIN_ADDRESS, "")
+	viper.SetDefault(ADMIN_GRPC_ADDRESS, "")
+	viper.SetDefault(ADMIN_TOKEN, "")
+
+	// Timeouts defaults, transfers are not bounded by default
+	viper.SetDefault(TIMEOUTS_PUT, "0s")
+	viper.SetDefault(TIMEOUTS_GET, "0s")
+	viper.SetDefault(TIMEOUTS_LIST, "30s")
+	viper.SetDefault(TIMEOUTS_DELETE, "30s")
+
+	// Journal defaults
+	viper.SetDefault(JOURNAL_PATH, "")
+	viper.SetDefault(JOURNAL_REPLAY_INTERVAL, "30s")
+
+	// Shadow defaults
+	viper.SetDefault(SHADOW_ENABLED, false)
+	viper.SetDefault(SHADOW_PERCENTAGE, 100)
+
+	// DryRun defaults
+	viper.SetDefault(DRY_RUN_ENABLED, false)
+
+	// RateLimit defaults, backends are not limited by default
+	viper.SetDefault(RATE_LIMIT_PRIME_READ, 0)
+	viper.SetDefault(RATE_LIMIT_PRIME_WRITE, 0)
+	viper.SetDefault(RATE_LIMIT_ALTER_READ, 0)
+	viper.SetDefault(RATE_LIMIT_ALTER_WRITE, 0)
+	viper.SetDefault(RATE_LIMIT_BURST, 0)
+	viper.SetDefault(RATE_LIMIT_ALTER_BANDWIDTH, 0)
+
+	// Replication defaults
+	viper.SetDefault(REPLICATION_WORKERS, 4)
+	viper.SetDefault(REPLICATION_QUEUE_DEPTH, 1000)
+	viper.SetDefault(REPLICATION_CONCURRENCY, 1)
+
+	// ReadOnly defaults
+	viper.SetDefault(READ_ONLY_ENABLED, false)
+	viper.SetDefault(READ_ONLY_MAX_JOURNAL_SIZE, 0)
+	viper.SetDefault(READ_ONLY_MAX_ALTER_DOWNTIME, "0s")
+
+	// Metadata defaults
+	viper.SetDefault(METADATA_NORMALIZE, true)
+	viper.SetDefault(METADATA_CONTENT_HASH, true)
+
+	// GC defaults, orphans are quarantined rather than removed
+	viper.SetDefault(GC_ENABLED, false)
+	viper.SetDefault(GC_INTERVAL, "24h")
+	viper.SetDefault(GC_SCHEDULE, "")
+	viper.SetDefault(GC_MIN_AGE, "1h")
+	viper.SetDefault(GC_QUARANTINE, true)
+	viper.SetDefault(GC_QUARANTINE_PREFIX, ".orphans/")
+
+	// Sync defaults
+	viper.SetDefault(SYNC_ENABLED, false)
+	viper.SetDefault(SYNC_INTERVAL, "1h")
+	viper.SetDefault(SYNC_SCHEDULE, "")
+	viper.SetDefault(SYNC_DELETE, false)
+	viper.SetDefault(SYNC_CHECKPOINT_PATH, "")
+
+	// Seed defaults
+	viper.SetDefault(SEED_ENABLED, false)
+	viper.SetDefault(SEED_WORKERS, 8)
+	viper.SetDefault(SEED_CHECKPOINT_PATH, "")
+
+	// State defaults, tracking is disabled
+	viper.SetDefault(STATE_PATH, "")
+
+	// Webhook defaults, notifications are disabled
+	viper.SetDefault(WEBHOOK_URL, "")
+	viper.SetDefault(WEBHOOK_TIMEOUT, "5s")
+	viper.SetDefault(WEBHOOK_RETRIES, 3)
+
+	// Events defaults, export is disabled
+	viper.SetDefault(EVENTS_TYPE, "")
+	viper.SetDefault(EVENTS_ADDRESS, "")
+	viper.SetDefault(EVENTS_TOPIC, "ditto.events")
+
+	// Metrics defaults
+	viper.SetDefault(METRICS_ENABLED, false)
+
+	// StatsD defaults, export is disabled
+	viper.SetDefault(STATSD_HOST, "")
+	viper.SetDefault(STATSD_PORT, 8125)
+	viper.SetDefault(STATSD_PREFIX, "ditto")
+	viper.SetDefault(STATSD_DATADOG, false)
+
+	// Tracing defaults, tracing is disabled
+	viper.SetDefault(TRACING_ENDPOINT, "")
+	viper.SetDefault(TRACING_SERVICE_NAME, "ditto")
+	viper.SetDefault(TRACING_SAMPLE_RATE, 0.01)
+
+	// Debug defaults, debug endpoint is disabled
+	viper.SetDefault(DEBUG_ADDRESS, "")
+
+	// Readiness defaults
+	viper.SetDefault(READINESS_WAIT_FOR_SEED, true)
+	viper.SetDefault(READINESS_MAX_JOURNAL_LEN, 0)
+
+	// Log defaults
+	viper.SetDefault(LOG_LEVEL, "info")
+	viper.SetDefault(LOG_FORMAT, "console")
+	viper.SetDefault(LOG_MIRRORING_LEVEL, "")
+	viper.SetDefault(LOG_REPLICATION_LEVEL, "")
+	viper.SetDefault(LOG_SYNC_LEVEL, "")
+	viper.SetDefault(LOG_CLI_LEVEL, "")
+	viper.SetDefault(LOG_FILE, "")
+	viper.SetDefault(LOG_MAX_SIZE, 100)
+	viper.SetDefault(LOG_MAX_AGE, "168h")
+	viper.SetDefault(LOG_MAX_BACKUPS, 7)
+	viper.SetDefault(LOG_COMPRESS, true)
+	viper.SetDefault(LOG_SYSLOG, "")
+	viper.SetDefault(LOG_SYSLOG_TAG, "ditto")
+	viper.SetDefault(LOG_JOURNALD, false)
+
+	// Audit defaults, audit is disabled
+	viper.SetDefault(AUDIT_FILE, "")
+
+	// Alert defaults, alerting is disabled
+	viper.SetDefault(ALERT_MAX_LAG, "5m")
+	viper.SetDefault(ALERT_MAX_FAILURES, 10)
+	viper.SetDefault(ALERT_SLACK_URL, "")
+	viper.SetDefault(ALERT_SMTP_ADDRESS, "")
+	viper.SetDefault(ALERT_SMTP_USERNAME, "")
+	viper.SetDefault(ALERT_SMTP_PASSWORD, "")
+	viper.SetDefault(ALERT_EMAIL_FROM, "")
+	viper.SetDefault(ALERT_EMAIL_TO, "")
+
+	// Read defaults, reads go to prime first
+	viper.SetDefault(READ_MODE, "prime")
+	viper.SetDefault(READ_PROBE_INTERVAL, "10s")
+
+	// Cache defaults, caching is disabled
+	viper.SetDefault(CACHE_MISSING_TTL, "0s")
+	viper.SetDefault(CACHE_MISSING_SIZE, 10000)
+	viper.SetDefault(CACHE_BUCKETS_TTL, "0s")
+	viper.SetDefault(CACHE_MEMORY_SIZE, 0)
+	viper.SetDefault(CACHE_MEMORY_OBJECT_SIZE, 1048576)
+	viper.SetDefault(CACHE_DISK_DIR, "")
+	viper.SetDefault(CACHE_DISK_SIZE, 10737418240)
+	viper.SetDefault(CACHE_DISK_OBJECT_SIZE, 104857600)
+
+	// Compression defaults, alter copies are stored as is
+	viper.SetDefault(COMPRESSION_ALGORITHM, "none")
+	viper.SetDefault(COMPRESSION_MIN_SIZE, 1024)
+
+	// Encryption defaults, alter copies are stored as is
+	viper.SetDefault(ENCRYPTION_KEY, "")
+	viper.SetDefault(ENCRYPTION_KEY_FILE, "")
+
+	// Quota defaults, buckets are unlimited
+	viper.SetDefault(QUOTA_MAX_SIZE, 0)
+	viper.SetDefault(QUOTA_MAX_OBJECTS, 0)
+
+	// Accounting defaults, usage isn't recorded
+	viper.SetDefault(ACCOUNTING_PATH, "")
+	viper.SetDefault(ACCOUNTING_FLUSH_INTERVAL, "1m")
+
+	// Rotation defaults, keys are reloaded only on SIGHUP once enabled
+	viper.SetDefault(ROTATION_ENABLED, false)
+	viper.SetDefault(ROTATION_FILE, "")
+	viper.SetDefault(ROTATION_WATCH_INTERVAL, "0s")
+
+	// Vault defaults, keys are read from config
+	viper.SetDefault(VAULT_ADDRESS, "")
+	viper.SetDefault(VAULT_TOKEN, "")
+	viper.SetDefault(VAULT_ROLE_ID, "")
+	viper.SetDefault(VAULT_SECRET_ID, "")
+	viper.SetDefault(VAULT_SERVER_1_PATH, "")
+	viper.SetDefault(VAULT_SERVER_2_PATH, "")
+	viper.SetDefault(VAULT_REFRESH_INTERVAL, "0s")
+
+	// Secrets defaults, passphrase is taken from environment only
+	viper.SetDefault(SECRETS_KEY_FILE, "")
+
+	// Reload defaults, config is read only on start
+	viper.SetDefault(RELOAD_ENABLED, false)
+	viper.SetDefault(RELOAD_WATCH_INTERVAL, "0s")
+
+	// Repair defaults, nothing is deleted
+	viper.SetDefault(REPAIR_POLICY, "merge")
 }
@@ -21,15 +21,195 @@ const LIST_MERGE = "ListOptions.Merge"
 const PUT_DEFAULT_SOURCE = "PutOptions." + DEFAULT_OPTIONS_DEFAULT_SOURCE
 const PUT_THROW_IMMEDIATELY = "PutOptions." + DEFAULT_OPTIONS_THROW_IMMEDIATELY
 const PUT_CREATE_BUCKET_IF_NOT_EXIST = "PutOptions.CreateBucketIfNotExist"
+const PUT_ASYNC_SIZE_THRESHOLD = "PutOptions.AsyncSizeThreshold"
+const PUT_BUFFER_SIZE = "PutOptions.BufferSize"
+const PUT_SPILL_DIR = "PutOptions.SpillDir"
+const PUT_VERIFY_CHECKSUM = "PutOptions.VerifyChecksum"
+const PUT_DETECT_CONTENT_TYPE = "PutOptions.DetectContentType"
+const PUT_DEDUP = "PutOptions.Dedup"
 
 const GET_OBJECT_DEFAULT_SOURCE = "GetObjectOptions." + DEFAULT_OPTIONS_DEFAULT_SOURCE
 const GET_OBJECT_THROW_IMMEDIATELY = "GetObjectOptions." + DEFAULT_OPTIONS_THROW_IMMEDIATELY
+const GET_OBJECT_VERIFY_READS = "GetObjectOptions.VerifyReads"
 
 const COPY_DEFAULT_SOURCE = "CopyOptions." + DEFAULT_OPTIONS_DEFAULT_SOURCE
 const COPY_THROW_IMMEDIATELY = "CopyOptions." + DEFAULT_OPTIONS_THROW_IMMEDIATELY
 
 const DELETE_DEFAULT_SOURCE = "DeleteOptions." + DEFAULT_OPTIONS_DEFAULT_SOURCE
 const DELETE_THROW_IMMEDIATELY = "DeleteOptions." + DEFAULT_OPTIONS_THROW_IMMEDIATELY
+const DELETE_SOFT_DELETE = "DeleteOptions.SoftDelete"
+const DELETE_TRASH_PREFIX = "DeleteOptions.TrashPrefix"
+const DELETE_TRASH_RETENTION = "DeleteOptions.TrashRetention"
+
+const FILTER_INCLUDE_PREFIXES = "FilterOptions.IncludePrefixes"
+const FILTER_EXCLUDE_PREFIXES = "FilterOptions.ExcludePrefixes"
+const FILTER_INCLUDE_PATTERNS = "FilterOptions.IncludePatterns"
+const FILTER_EXCLUDE_PATTERNS = "FilterOptions.ExcludePatterns"
+
+const BUCKET_MAPPING_ALTER_PREFIX = "BucketMapping.AlterPrefix"
+const BUCKET_MAPPING_ALTER_SUFFIX = "BucketMapping.AlterSuffix"
+
+const FAILOVER_ENABLED = "FailoverOptions.Enabled"
+const FAILOVER_FAILURE_THRESHOLD = "FailoverOptions.FailureThreshold"
+const FAILOVER_RECOVERY_THRESHOLD = "FailoverOptions.RecoveryThreshold"
+const FAILOVER_MIN_DURATION = "FailoverOptions.MinDuration"
+const FAILOVER_PROBE_INTERVAL = "FailoverOptions.ProbeInterval"
+
+const CIRCUIT_BREAKER_ENABLED = "CircuitBreaker.Enabled"
+const CIRCUIT_BREAKER_FAILURE_THRESHOLD = "CircuitBreaker.FailureThreshold"
+const CIRCUIT_BREAKER_COOLDOWN = "CircuitBreaker.Cooldown"
+
+const HEALTH_CHECK_ENABLED = "HealthCheck.Enabled"
+const HEALTH_CHECK_INTERVAL = "HealthCheck.Interval"
+const HEALTH_CHECK_TIMEOUT = "HealthCheck.Timeout"
+const HEALTH_CHECK_BUCKET = "HealthCheck.Bucket"
+
+const ADMIN_ADDRESS = "Admin.Address"
+const ADMIN_GRPC_ADDRESS = "Admin.GRPCAddress"
+const ADMIN_TOKEN = "Admin.Token"
+
+const TIMEOUTS_PUT = "Timeouts.Put"
+const TIMEOUTS_GET = "Timeouts.Get"
+const TIMEOUTS_LIST = "Timeouts.List"
+const TIMEOUTS_DELETE = "Timeouts.Delete"
+
+const JOURNAL_PATH = "Journal.Path"
+const JOURNAL_REPLAY_INTERVAL = "Journal.ReplayInterval"
+
+const SHADOW_ENABLED = "Shadow.Enabled"
+const SHADOW_PERCENTAGE = "Shadow.Percentage"
+
+const DRY_RUN_ENABLED = "DryRun.Enabled"
+
+const RATE_LIMIT_PRIME_READ = "RateLimit.PrimeRead"
+const RATE_LIMIT_PRIME_WRITE = "RateLimit.PrimeWrite"
+const RATE_LIMIT_ALTER_READ = "RateLimit.AlterRead"
+const RATE_LIMIT_ALTER_WRITE = "RateLimit.AlterWrite"
+const RATE_LIMIT_BURST = "RateLimit.Burst"
+const RATE_LIMIT_ALTER_BANDWIDTH = "RateLimit.AlterBandwidth"
+
+const REPLICATION_WORKERS = "Replication.Workers"
+const REPLICATION_QUEUE_DEPTH = "Replication.QueueDepth"
+const REPLICATION_CONCURRENCY = "Replication.Concurrency"
+
+const READ_ONLY_ENABLED = "ReadOnly.Enabled"
+const READ_ONLY_MAX_JOURNAL_SIZE = "ReadOnly.MaxJournalSize"
+const READ_ONLY_MAX_ALTER_DOWNTIME = "ReadOnly.MaxAlterDowntime"
+
+const METADATA_NORMALIZE = "Metadata.Normalize"
+const METADATA_CONTENT_HASH = "Metadata.ContentHash"
+
+const GC_ENABLED = "GC.Enabled"
+const GC_INTERVAL = "GC.Interval"
+const GC_SCHEDULE = "GC.Schedule"
+const GC_MIN_AGE = "GC.MinAge"
+const GC_QUARANTINE = "GC.Quarantine"
+const GC_QUARANTINE_PREFIX = "GC.QuarantinePrefix"
+
+const SYNC_ENABLED = "Sync.Enabled"
+const SYNC_INTERVAL = "Sync.Interval"
+const SYNC_SCHEDULE = "Sync.Schedule"
+const SYNC_DELETE = "Sync.Delete"
+const SYNC_CHECKPOINT_PATH = "Sync.CheckpointPath"
+
+const SEED_ENABLED = "Seed.Enabled"
+const SEED_WORKERS = "Seed.Workers"
+const SEED_CHECKPOINT_PATH = "Seed.CheckpointPath"
+
+const STATE_PATH = "State.Path"
+
+const WEBHOOK_URL = "Webhook.URL"
+const WEBHOOK_TIMEOUT = "Webhook.Timeout"
+const WEBHOOK_RETRIES = "Webhook.Retries"
+
+const EVENTS_TYPE = "Events.Type"
+const EVENTS_ADDRESS = "Events.Address"
+const EVENTS_TOPIC = "Events.Topic"
+
+const METRICS_ENABLED = "Metrics.Enabled"
+
+const STATSD_HOST = "StatsD.Host"
+const STATSD_PORT = "StatsD.Port"
+const STATSD_PREFIX = "StatsD.Prefix"
+const STATSD_DATADOG = "StatsD.Datadog"
+
+const TRACING_ENDPOINT = "Tracing.Endpoint"
+const TRACING_SERVICE_NAME = "Tracing.ServiceName"
+const TRACING_SAMPLE_RATE = "Tracing.SampleRate"
+
+const DEBUG_ADDRESS = "Debug.Address"
+
+const READINESS_WAIT_FOR_SEED = "Readiness.WaitForSeed"
+const READINESS_MAX_JOURNAL_LEN = "Readiness.MaxJournalLen"
+
+const LOG_LEVEL = "Log.Level"
+const LOG_FORMAT = "Log.Format"
+const LOG_MIRRORING_LEVEL = "Log.MirroringLevel"
+const LOG_REPLICATION_LEVEL = "Log.ReplicationLevel"
+const LOG_SYNC_LEVEL = "Log.SyncLevel"
+const LOG_CLI_LEVEL = "Log.CLILevel"
+const LOG_FILE = "Log.File"
+const LOG_MAX_SIZE = "Log.MaxSize"
+const LOG_MAX_AGE = "Log.MaxAge"
+const LOG_MAX_BACKUPS = "Log.MaxBackups"
+const LOG_COMPRESS = "Log.Compress"
+const LOG_SYSLOG = "Log.Syslog"
+const LOG_SYSLOG_TAG = "Log.SyslogTag"
+const LOG_JOURNALD = "Log.Journald"
+
+const AUDIT_FILE = "Audit.File"
+
+const ALERT_MAX_LAG = "Alert.MaxLag"
+const ALERT_MAX_FAILURES = "Alert.MaxFailures"
+const ALERT_SLACK_URL = "Alert.SlackURL"
+const ALERT_SMTP_ADDRESS = "Alert.SMTPAddress"
+const ALERT_SMTP_USERNAME = "Alert.SMTPUsername"
+const ALERT_SMTP_PASSWORD = "Alert.SMTPPassword"
+const ALERT_EMAIL_FROM = "Alert.EmailFrom"
+const ALERT_EMAIL_TO = "Alert.EmailTo"
+
+const READ_MODE = "Read.Mode"
+const READ_PROBE_INTERVAL = "Read.ProbeInterval"
+
+const CACHE_MISSING_TTL = "Cache.MissingTTL"
+const CACHE_MISSING_SIZE = "Cache.MissingSize"
+const CACHE_BUCKETS_TTL = "Cache.BucketsTTL"
+const CACHE_MEMORY_SIZE = "Cache.MemorySize"
+const CACHE_MEMORY_OBJECT_SIZE = "Cache.MemoryObjectSize"
+const CACHE_DISK_DIR = "Cache.DiskDir"
+const CACHE_DISK_SIZE = "Cache.DiskSize"
+const CACHE_DISK_OBJECT_SIZE = "Cache.DiskObjectSize"
+
+const COMPRESSION_ALGORITHM = "Compression.Algorithm"
+const COMPRESSION_MIN_SIZE = "Compression.MinSize"
+
+const ENCRYPTION_KEY = "Encryption.Key"
+const ENCRYPTION_KEY_FILE = "Encryption.KeyFile"
+
+const QUOTA_MAX_SIZE = "Quota.MaxSize"
+const QUOTA_MAX_OBJECTS = "Quota.MaxObjects"
+
+const ACCOUNTING_PATH = "Accounting.Path"
+const ACCOUNTING_FLUSH_INTERVAL = "Accounting.FlushInterval"
+
+const ROTATION_ENABLED = "Rotation.Enabled"
+const ROTATION_FILE = "Rotation.File"
+const ROTATION_WATCH_INTERVAL = "Rotation.WatchInterval"
+
+const VAULT_ADDRESS = "Vault.Address"
+const VAULT_TOKEN = "Vault.Token"
+const VAULT_ROLE_ID = "Vault.RoleID"
+const VAULT_SECRET_ID = "Vault.SecretID"
+const VAULT_SERVER_1_PATH = "Vault.Server1Path"
+const VAULT_SERVER_2_PATH = "Vault.Server2Path"
+const VAULT_REFRESH_INTERVAL = "Vault.RefreshInterval"
+
+const SECRETS_KEY_FILE = "Secrets.KeyFile"
+
+const RELOAD_ENABLED = "Reload.Enabled"
+const RELOAD_WATCH_INTERVAL = "Reload.WatchInterval"
+
+const REPAIR_POLICY = "Repair.Policy"
 
 // const ConfigKeys:= make(string, 20){"",""}
 func GetKeysArray() []string {
@@ -48,11 +228,152 @@ func GetKeysArray() []string {
 		PUT_DEFAULT_SOURCE,
 		PUT_THROW_IMMEDIATELY,
 		PUT_CREATE_BUCKET_IF_NOT_EXIST,
+		PUT_ASYNC_SIZE_THRESHOLD,
+		PUT_BUFFER_SIZE,
+		PUT_SPILL_DIR,
+		PUT_VERIFY_CHECKSUM,
+		PUT_DETECT_CONTENT_TYPE,
+		PUT_DEDUP,
 		GET_OBJECT_DEFAULT_SOURCE,
 		GET_OBJECT_THROW_IMMEDIATELY,
+		GET_OBJECT_VERIFY_READS,
 		COPY_DEFAULT_SOURCE,
 		COPY_THROW_IMMEDIATELY,
 		DELETE_DEFAULT_SOURCE,
 		DELETE_THROW_IMMEDIATELY,
+		DELETE_SOFT_DELETE,
+		DELETE_TRASH_PREFIX,
+		DELETE_TRASH_RETENTION,
+		FILTER_INCLUDE_PREFIXES,
+		FILTER_EXCLUDE_PREFIXES,
+		FILTER_INCLUDE_PATTERNS,
+		FILTER_EXCLUDE_PATTERNS,
+		BUCKET_MAPPING_ALTER_PREFIX,
+		BUCKET_MAPPING_ALTER_SUFFIX,
+		FAILOVER_ENABLED,
+		FAILOVER_FAILURE_THRESHOLD,
+		FAILOVER_RECOVERY_THRESHOLD,
+		FAILOVER_MIN_DURATION,
+		FAILOVER_PROBE_INTERVAL,
+		CIRCUIT_BREAKER_ENABLED,
+		CIRCUIT_BREAKER_FAILURE_THRESHOLD,
+		CIRCUIT_BREAKER_COOLDOWN,
+		HEALTH_CHECK_ENABLED,
+		HEALTH_CHECK_INTERVAL,
+		HEALTH_CHECK_TIMEOUT,
+		HEALTH_CHECK_BUCKET,
+		ADMIN_ADDRESS,
+		ADMIN_GRPC_ADDRESS,
+		ADMIN_TOKEN,
+		TIMEOUTS_PUT,
+		TIMEOUTS_GET,
+		TIMEOUTS_LIST,
+		TIMEOUTS_DELETE,
+		JOURNAL_PATH,
+		JOURNAL_REPLAY_INTERVAL,
+		SHADOW_ENABLED,
+		SHADOW_PERCENTAGE,
+		DRY_RUN_ENABLED,
+		RATE_LIMIT_PRIME_READ,
+		RATE_LIMIT_PRIME_WRITE,
+		RATE_LIMIT_ALTER_READ,
+		RATE_LIMIT_ALTER_WRITE,
+		RATE_LIMIT_BURST,
+		RATE_LIMIT_ALTER_BANDWIDTH,
+		REPLICATION_WORKERS,
+		REPLICATION_QUEUE_DEPTH,
+		REPLICATION_CONCURRENCY,
+		READ_ONLY_ENABLED,
+		READ_ONLY_MAX_JOURNAL_SIZE,
+		READ_ONLY_MAX_ALTER_DOWNTIME,
+		METADATA_NORMALIZE,
+		METADATA_CONTENT_HASH,
+		GC_ENABLED,
+		GC_INTERVAL,
+		GC_SCHEDULE,
+		GC_MIN_AGE,
+		GC_QUARANTINE,
+		GC_QUARANTINE_PREFIX,
+		SYNC_ENABLED,
+		SYNC_INTERVAL,
+		SYNC_SCHEDULE,
+		SYNC_DELETE,
+		SYNC_CHECKPOINT_PATH,
+		SEED_ENABLED,
+		SEED_WORKERS,
+		SEED_CHECKPOINT_PATH,
+		STATE_PATH,
+		WEBHOOK_URL,
+		WEBHOOK_TIMEOUT,
+		WEBHOOK_RETRIES,
+		EVENTS_TYPE,
+		EVENTS_ADDRESS,
+		EVENTS_TOPIC,
+		METRICS_ENABLED,
+		STATSD_HOST,
+		STATSD_PORT,
+		STATSD_PREFIX,
+		STATSD_DATADOG,
+		TRACING_ENDPOINT,
+		TRACING_SERVICE_NAME,
+		TRACING_SAMPLE_RATE,
+		DEBUG_ADDRESS,
+		READINESS_WAIT_FOR_SEED,
+		READINESS_MAX_JOURNAL_LEN,
+		LOG_LEVEL,
+		LOG_FORMAT,
+		LOG_MIRRORING_LEVEL,
+		LOG_REPLICATION_LEVEL,
+		LOG_SYNC_LEVEL,
+		LOG_CLI_LEVEL,
+		LOG_FILE,
+		LOG_MAX_SIZE,
+		LOG_MAX_AGE,
+		LOG_MAX_BACKUPS,
+		LOG_COMPRESS,
+		LOG_SYSLOG,
+		LOG_SYSLOG_TAG,
+		LOG_JOURNALD,
+		AUDIT_FILE,
+		ALERT_MAX_LAG,
+		ALERT_MAX_FAILURES,
+		ALERT_SLACK_URL,
+		ALERT_SMTP_ADDRESS,
+		ALERT_SMTP_USERNAME,
+		ALERT_SMTP_PASSWORD,
+		ALERT_EMAIL_FROM,
+		ALERT_EMAIL_TO,
+		READ_MODE,
+		READ_PROBE_INTERVAL,
+		CACHE_MISSING_TTL,
+		CACHE_MISSING_SIZE,
+		CACHE_BUCKETS_TTL,
+		CACHE_MEMORY_SIZE,
+		CACHE_MEMORY_OBJECT_SIZE,
+		CACHE_DISK_DIR,
+		CACHE_DISK_SIZE,
+		CACHE_DISK_OBJECT_SIZE,
+		COMPRESSION_ALGORITHM,
+		COMPRESSION_MIN_SIZE,
+		ENCRYPTION_KEY,
+		ENCRYPTION_KEY_FILE,
+		QUOTA_MAX_SIZE,
+		QUOTA_MAX_OBJECTS,
+		ACCOUNTING_PATH,
+		ACCOUNTING_FLUSH_INTERVAL,
+		ROTATION_ENABLED,
+		ROTATION_FILE,
+		ROTATION_WATCH_INTERVAL,
+		VAULT_ADDRESS,
+		VAULT_TOKEN,
+		VAULT_ROLE_ID,
+		VAULT_SECRET_ID,
+		VAULT_SERVER_1_PATH,
+		VAULT_SERVER_2_PATH,
+		VAULT_REFRESH_INTERVAL,
+		SECRETS_KEY_FILE,
+		RELOAD_ENABLED,
+		RELOAD_WATCH_INTERVAL,
+		REPAIR_POLICY,
 	}
 }
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/magiconair/properties/assert"
+	"github.com/spf13/viper"
 	"go.etcd.io/etcd/pkg/testutil"
 )
 
@@ -418,3 +423,317 @@ func Test(t *testing.T) {
 		})
 	}
 }
+
+func TestForTenant(t *testing.T) {
+	root := NewConfig().
+		WithServer1Credentials(NewCredentials("root1", "a", "a")).
+		WithServer2Credentials(NewCredentials("root2", "a", "a")).
+		WithPutOptions(nil, true)
+	root.Admin = &AdminOptions{Address: ":8080"}
+	root.State = &StateOptions{Path: "/var/lib/ditto/state.db"}
+
+	tenant := &TenantOptions{
+		Name:       "team",
+		AccessKeys: []string{"team-key"},
+		Config: &Config{
+			Server1: NewCredentials("team1", "b", "b"),
+			Server2: NewCredentials("team2", "b", "b"),
+			State:   &StateOptions{Path: "/var/lib/ditto/team.db"},
+		},
+	}
+	root.Tenants = []*TenantOptions{tenant}
+
+	cfg := root.ForTenant(tenant)
+
+	assert.Equal(t, cfg.Server1.Endpoint, "team1")
+	assert.Equal(t, cfg.Server2.Endpoint, "team2")
+	assert.Equal(t, cfg.State.Path, "/var/lib/ditto/team.db")
+
+	// policies are inherited, process wide sections are not
+	assert.Equal(t, cfg.PutOptions, root.PutOptions)
+	assert.Equal(t, cfg.Admin == nil, true)
+	assert.Equal(t, len(cfg.Tenants), 0)
+
+	// root is untouched
+	assert.Equal(t, root.Server1.Endpoint, "root1")
+	assert.Equal(t, root.State.Path, "/var/lib/ditto/state.db")
+}
+
+func TestSecrets(t *testing.T) {
+	passphrase := []byte("passphrase")
+
+	encrypted, err := EncryptSecret("secret key", passphrase)
+	testutil.AssertNil(t, err)
+	assert.Equal(t, IsEncrypted(encrypted), true)
+
+	decrypted, err := DecryptSecret(encrypted, passphrase)
+	testutil.AssertNil(t, err)
+	assert.Equal(t, decrypted, "secret key")
+
+	_, err = DecryptSecret(encrypted, []byte("wrong"))
+	assert.Equal(t, err != nil, true)
+
+	plain, err := DecryptSecret("plain key", passphrase)
+	testutil.AssertNil(t, err)
+	assert.Equal(t, plain, "plain key")
+
+	os.Setenv(PassphraseEnv, string(passphrase))
+	defer os.Unsetenv(PassphraseEnv)
+
+	config := &Config{
+		Server1: &Credentials{AccessKey: "access", SecretKey: encrypted},
+		Tenants: []*TenantOptions{{Name: "t", Config: &Config{Server2: &Credentials{SecretKey: encrypted}}}},
+	}
+
+	testutil.AssertNil(t, config.DecryptSecrets())
+	assert.Equal(t, config.Server1.AccessKey, "access")
+	assert.Equal(t, config.Server1.SecretKey, "secret key")
+	assert.Equal(t, config.Tenants[0].Config.Server2.SecretKey, "secret key")
+}
+
+func TestReadConfigFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-config")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+	defer viper.Reset()
+
+	files := map[string]string{
+		"config.yaml": "Server1:\n  Endpoint: prime:9000\n  AccessKey: a1\n  SecretKey: s1\n" +
+			"Server2:\n  Endpoint: alter:9000\n  AccessKey: a2\n  SecretKey: s2\n" +
+			"Read:\n  ProbeInterval: 30s\n",
+		"config.toml": "[Server1]\nEndpoint = \"prime:9000\"\nAccessKey = \"a1\"\nSecretKey = \"s1\"\n" +
+			"[Server2]\nEndpoint = \"alter:9000\"\nAccessKey = \"a2\"\nSecretKey = \"s2\"\n" +
+			"[Read]\nProbeInterval = \"30s\"\n",
+	}
+
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		testutil.AssertNil(t, ioutil.WriteFile(file, []byte(content), 0644))
+
+		viper.Reset()
+		viper.Set("configPath", file)
+
+		config, err := ReadConfig(true)
+		testutil.AssertNil(t, err)
+		assert.Equal(t, config.Server1.AccessKey, "a1")
+		assert.Equal(t, config.Server2.Endpoint, "alter:9000")
+		assert.Equal(t, config.Read.ProbeInterval, 30*time.Second)
+		// defaults apply to keys missing in file
+		assert.Equal(t, config.Read.Mode, "prime")
+	}
+
+	// environment has priority over file
+	os.Setenv("DITTO_SERVER1_ACCESSKEY", "env")
+	os.Setenv("DITTO_FILTEROPTIONS_EXCLUDEPREFIXES", "tmp/,cache/")
+	defer os.Unsetenv("DITTO_SERVER1_ACCESSKEY")
+	defer os.Unsetenv("DITTO_FILTEROPTIONS_EXCLUDEPREFIXES")
+
+	viper.Reset()
+	viper.Set("configPath", filepath.Join(dir, "config.yaml"))
+
+	config, err := ReadConfig(true)
+	testutil.AssertNil(t, err)
+	assert.Equal(t, config.Server1.AccessKey, "env")
+	assert.Equal(t, config.Server1.SecretKey, "s1")
+	assert.Equal(t, config.FilterOptions.ExcludePrefixes, []string{"tmp/", "cache/"})
+
+	assert.Equal(t, EnvName(SERVER_1_ACCESS_KEY), "DITTO_SERVER1_ACCESSKEY")
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			Server1: &Credentials{Endpoint: "prime:9000", AccessKey: "a1", SecretKey: "s1"},
+			Server2: &Credentials{Endpoint: "https://alter.example.com", AccessKey: "a2", SecretKey: "s2"},
+			Log:     &LogOptions{Level: "info", Format: "json"},
+		}
+	}
+
+	testutil.AssertNil(t, valid().Validate())
+
+	config := valid()
+	config.Server1.Endpoint = "ftp://prime"
+	config.Server2.SecretKey = ""
+	config.Log.Level = "verbose"
+	config.Log.File = "/var/log/ditto.log"
+	config.Log.Syslog = "local"
+	config.Encryption = &EncryptionOptions{Key: "key", KeyFile: "/etc/ditto/key"}
+	config.FailoverOptions = &FailoverOptions{Enabled: true, FailureThreshold: 3, RecoveryThreshold: 3}
+	config.PutOptions = &PutOptions{VerifyChecksum: true}
+	config.DryRun = &DryRunOptions{Enabled: true}
+	config.Quota = &QuotaOptions{MaxObjects: 10}
+	config.Tenants = []*TenantOptions{
+		{Name: "t", BucketPrefix: "t-", Config: &Config{Server1: &Credentials{Endpoint: "prime:99999", AccessKey: "a", SecretKey: "s"}}},
+		{Name: "t"},
+	}
+
+	err, ok := config.Validate().(*ValidationError)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, err.Problems, []string{
+		`Server1.Endpoint "ftp://prime" is invalid: scheme "ftp" is not http or https, expected host:port or http(s)://host:port`,
+		"Server2.SecretKey is not set, define it with `ditto config set Server2.SecretKey <key>`",
+		`Log.Level "verbose" is unknown, expected one of debug, info, warn, error`,
+		"Log.File and Log.Syslog are mutually exclusive, set only one of them",
+		"Encryption.Key and Encryption.KeyFile are mutually exclusive, set only one of them",
+		"FailoverOptions.Enabled requires Journal.Path to keep writes accepted by alter until prime is back",
+		"FailoverOptions.Enabled and DryRun.Enabled are mutually exclusive, set only one of them",
+		"PutOptions.VerifyChecksum and DryRun.Enabled are mutually exclusive, set only one of them",
+		"Quota requires State.Path to track usage of buckets",
+		`tenant "t": Server1.Endpoint "prime:99999" is invalid: port "99999" is invalid, expected host:port or http(s)://host:port`,
+		`tenant "t" is configured more than once`,
+		`tenant "t" has neither AccessKeys nor BucketPrefix, so no request is routed to it`,
+	})
+}
+
+func TestProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-config")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+	defer viper.Reset()
+	defer SetProfile("")
+
+	file := filepath.Join(dir, "config.yaml")
+	content := "Server1:\n  Endpoint: prime:9000\n  AccessKey: a1\n  SecretKey: s1\n" +
+		"Server2:\n  Endpoint: alter:9000\n  AccessKey: a2\n  SecretKey: s2\n" +
+		"Profiles:\n  prod:\n    Server2:\n      Endpoint: https://alter.example.com\n      SecretKey: prod\n" +
+		"    Log:\n      Level: warn\n"
+	testutil.AssertNil(t, ioutil.WriteFile(file, []byte(content), 0644))
+
+	read := func() (*Config, error) {
+		viper.Reset()
+		viper.Set("configPath", file)
+
+		return ReadConfig(true)
+	}
+
+	SetProfile("prod")
+
+	config, err := read()
+	testutil.AssertNil(t, err)
+	assert.Equal(t, config.Server2.Endpoint, "https://alter.example.com")
+	assert.Equal(t, config.Server2.SecretKey, "prod")
+	assert.Equal(t, config.Server2.AccessKey, "a2")
+	assert.Equal(t, config.Server1.Endpoint, "prime:9000")
+	assert.Equal(t, config.Log.Level, "warn")
+	assert.Equal(t, ProfileKey(SERVER_2_ENDPOINT), "Profiles.prod.Server2.Endpoint")
+
+	// environment has priority over profile
+	os.Setenv("DITTO_SERVER2_SECRETKEY", "env")
+	defer os.Unsetenv("DITTO_SERVER2_SECRETKEY")
+
+	config, err = read()
+	testutil.AssertNil(t, err)
+	assert.Equal(t, config.Server2.SecretKey, "env")
+
+	// profile is selected by environment unless set
+	SetProfile("")
+	os.Setenv(ProfileEnv, "prod")
+	defer os.Unsetenv(ProfileEnv)
+	assert.Equal(t, Profile(), "prod")
+
+	SetProfile("staging")
+
+	_, err = read()
+	assert.Equal(t, err != nil, true)
+	_, ok := err.(*ReadError)
+	assert.Equal(t, ok, true)
+}
+
+func TestSetValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-config")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name     string
+		content  string
+		key      string
+		value    string
+		expected string
+	}{
+		{
+			name:     "config.json",
+			content:  "{\n  \"server1\": {\n    \"endpoint\": \"prime:9000\"\n  },\n  \"Log\": {\n    \"Level\": \"info\"\n  }\n}\n",
+			key:      "Server1.AccessKey",
+			value:    "access",
+			expected: "{\n  \"server1\": {\n    \"endpoint\": \"prime:9000\",\n    \"AccessKey\": \"access\"\n  },\n  \"Log\": {\n    \"Level\": \"info\"\n  }\n}\n",
+		},
+		{
+			name:     "empty.json",
+			key:      "Profiles.prod.RateLimit.Burst",
+			value:    "10",
+			expected: "{\n  \"Profiles\": {\n    \"prod\": {\n      \"RateLimit\": {\n        \"Burst\": 10\n      }\n    }\n  }\n}\n",
+		},
+		{
+			name:     "replace.yaml",
+			content:  "# backends\nServer1:\n  Endpoint: prime:9000 # local minio\n  AccessKey: a1\n\nLog:\n  Level: info\n",
+			key:      "server1.endpoint",
+			value:    "https://prime.example.com",
+			expected: "# backends\nServer1:\n  Endpoint: https://prime.example.com # local minio\n  AccessKey: a1\n\nLog:\n  Level: info\n",
+		},
+		{
+			name:     "insert.yaml",
+			content:  "Server1:\n    Endpoint: prime:9000\n\n# logging\nLog:\n  Level: info\n",
+			key:      "Server1.SecretKey",
+			value:    "12345",
+			expected: "Server1:\n    Endpoint: prime:9000\n    SecretKey: \"12345\"\n\n# logging\nLog:\n  Level: info\n",
+		},
+		{
+			name:     "nested.yaml",
+			content:  "Log:\n  Level: info\n",
+			key:      "BucketMapping.Mapping.photos",
+			value:    "photos-backup",
+			expected: "Log:\n  Level: info\nBucketMapping:\n  Mapping:\n    photos: photos-backup\n",
+		},
+		{
+			name:     "list.yaml",
+			content:  "FilterOptions:\n  ExcludePrefixes:\n    - tmp/\n    - cache/\n  # patterns\n  IncludePatterns: []\n",
+			key:      "FilterOptions.ExcludePrefixes",
+			value:    "tmp/, logs/",
+			expected: "FilterOptions:\n  ExcludePrefixes: [tmp/, logs/]\n  # patterns\n  IncludePatterns: []\n",
+		},
+		{
+			name:     "replace.toml",
+			content:  "# backends\n[Server1]\nEndpoint = \"prime:9000\" # local minio\n\n[Log]\nLevel = \"info\"\n",
+			key:      "Server1.Endpoint",
+			value:    "alter:9000",
+			expected: "# backends\n[Server1]\nEndpoint = \"alter:9000\" # local minio\n\n[Log]\nLevel = \"info\"\n",
+		},
+		{
+			name:     "insert.toml",
+			content:  "Log.Level = \"info\"\n\n[Server1]\nEndpoint = \"prime:9000\"\n\n[Log]\nFormat = \"json\"\n",
+			key:      "Log.Level",
+			value:    "debug",
+			expected: "Log.Level = \"debug\"\n\n[Server1]\nEndpoint = \"prime:9000\"\n\n[Log]\nFormat = \"json\"\n",
+		},
+		{
+			name:     "table.toml",
+			content:  "[Server1]\nEndpoint = \"prime:9000\"\n",
+			key:      "Shadow.Enabled",
+			value:    "true",
+			expected: "[Server1]\nEndpoint = \"prime:9000\"\n\n[Shadow]\nEnabled = true\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			file := filepath.Join(dir, c.name)
+			testutil.AssertNil(t, ioutil.WriteFile(file, []byte(c.content), 0644))
+
+			testutil.AssertNil(t, SetValue(file, c.key, c.value))
+
+			b, err := ioutil.ReadFile(file)
+			testutil.AssertNil(t, err)
+			assert.Equal(t, string(b), c.expected)
+		})
+	}
+
+	file := filepath.Join(dir, "invalid.yaml")
+	testutil.AssertNil(t, ioutil.WriteFile(file, []byte("Log:\n  Level: info\n"), 0644))
+
+	assert.Equal(t, SetValue(file, "Log.Unknown", "a") != nil, true)
+	assert.Equal(t, SetValue(file, "Log", "a") != nil, true)
+	assert.Equal(t, SetValue(file, "Shadow.Enabled", "maybe") != nil, true)
+	assert.Equal(t, SetValue(file, "Timeouts.Put", "soon") != nil, true)
+	assert.Equal(t, SetValue(file, "FilterOptions.ExcludePatterns", "^tmp/,[a-") != nil, true)
+}
@@ -0,0 +1,618 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CanonicalKey resolves case insensitive dotted key against Config, e.g. "server1.endpoint" is "Server1.Endpoint".
+// Keys of maps, e.g. "BucketMapping.Mapping.photos", and keys of profiles, e.g. "Profiles.prod.Log.Level",
+// are resolved as well. Returns canonical key, type of its value and false if key doesn't exist.
+func CanonicalKey(key string) (string, reflect.Type, bool) {
+	segments := strings.Split(key, ".")
+
+	var canonical []string
+	if len(segments) > 2 && strings.EqualFold(segments[0], ProfilesKey) {
+		canonical = append(canonical, ProfilesKey, segments[1])
+		segments = segments[2:]
+	}
+
+	t := reflect.TypeOf(Config{})
+
+	for _, s := range segments {
+		if s == "" {
+			return "", nil, false
+		}
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := t.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, s) })
+			if !ok {
+				return "", nil, false
+			}
+
+			canonical = append(canonical, f.Name)
+			t = f.Type
+		case reflect.Map:
+			canonical = append(canonical, s)
+			t = t.Elem()
+		default:
+			return "", nil, false
+		}
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return strings.Join(canonical, "."), t, true
+}
+
+// parseValue converts value given on command line to type of key t, lists are comma separated.
+func parseValue(t reflect.Type, key, value string) (interface{}, error) {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("%s expects duration such as 30s or 5m, got %q", key, value)
+		}
+
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects true or false, got %q", key, value)
+		}
+
+		return b, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects integer, got %q", key, value)
+		}
+
+		return n, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects number, got %q", key, value)
+		}
+
+		return f, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			list := []string{}
+
+			for _, v := range strings.Split(value, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					list = append(list, v)
+				}
+			}
+
+			// filter patterns are compiled here, so invalid one never reaches config file
+			if strings.HasSuffix(key, FILTER_INCLUDE_PATTERNS) || strings.HasSuffix(key, FILTER_EXCLUDE_PATTERNS) {
+				for _, p := range list {
+					if _, err := regexp.Compile(p); err != nil {
+						return nil, fmt.Errorf("%s expects regular expressions, %q is invalid: %s", key, p, err)
+					}
+				}
+			}
+
+			return list, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s is a section, set its keys instead", key)
+}
+
+// SetValue sets dotted key in config file to value, converted to type of key. JSON, YAML and TOML files
+// are edited in place, so comments, order and formatting of other keys are preserved.
+// Missing sections are created.
+func SetValue(file, key, value string) error {
+	canonical, t, ok := CanonicalKey(key)
+	if !ok {
+		return fmt.Errorf("key %s doesn't exist", key)
+	}
+
+	v, err := parseValue(t, canonical, value)
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	path := strings.Split(canonical, ".")
+
+	switch ext := strings.TrimPrefix(filepath.Ext(file), "."); ext {
+	case "json":
+		content, err = setJSON(content, path, v)
+	case "yaml", "yml":
+		content, err = setYAML(content, path, v)
+	case "toml":
+		content, err = setTOML(content, path, v)
+	default:
+		return fmt.Errorf("config file format %q is not supported, expected one of %s", ext, strings.Join(Extensions, ", "))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, content, 0666)
+}
+
+// jsonField is a field of JSON object, objects are kept as ordered fields so order of keys is preserved.
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+func parseJSONObject(raw []byte) ([]jsonField, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("JSON object expected")
+	}
+
+	var fields []jsonField
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, jsonField{key: tok.(string), value: value})
+	}
+
+	return fields, nil
+}
+
+func marshalJSONObject(fields []jsonField) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(f.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(f.value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes()
+}
+
+// setJSONField sets path of object raw to value, keys are matched case insensitively.
+func setJSONField(raw []byte, path []string, value json.RawMessage) ([]byte, error) {
+	fields, err := parseJSONObject(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	i := 0
+	for i < len(fields) && !strings.EqualFold(fields[i].key, path[0]) {
+		i++
+	}
+
+	if i == len(fields) {
+		fields = append(fields, jsonField{key: path[0], value: json.RawMessage("{}")})
+	}
+
+	if len(path) == 1 {
+		fields[i].value = value
+	} else {
+		if fields[i].value, err = setJSONField(fields[i].value, path[1:], value); err != nil {
+			return nil, fmt.Errorf("%s: %s", fields[i].key, err)
+		}
+	}
+
+	return marshalJSONObject(fields), nil
+}
+
+func setJSON(content []byte, path []string, value interface{}) ([]byte, error) {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := setJSONField(content, path, v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+var (
+	yamlKeyLine  = regexp.MustCompile(`^(\s*)("[^"]*"|'[^']*'|[^\s#'"-][^:#]*?)\s*:(\s+|$)(.*)$`)
+	yamlPlain    = regexp.MustCompile(`^[A-Za-z0-9_./@+-][A-Za-z0-9_./:@+ -]*$`)
+	yamlReserved = regexp.MustCompile(`^(?i:true|false|yes|no|on|off|null|~|y|n)$`)
+)
+
+// yamlLine is a line of YAML document with its indentation, key and value if it's a key line.
+type yamlLine struct {
+	text    string
+	indent  int
+	content bool // not blank nor comment
+	key     string
+	value   string // value with trailing comment
+}
+
+func parseYAMLLines(content []byte) []yamlLine {
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return nil
+	}
+
+	var lines []yamlLine
+
+	for _, t := range strings.Split(text, "\n") {
+		l := yamlLine{text: t}
+		trimmed := strings.TrimSpace(t)
+		l.indent = len(t) - len(strings.TrimLeft(t, " "))
+		l.content = trimmed != "" && !strings.HasPrefix(trimmed, "#") && trimmed != "---"
+
+		if m := yamlKeyLine.FindStringSubmatch(t); m != nil && l.content {
+			l.key = strings.Trim(m[2], `"'`)
+			l.value = m[4]
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines
+}
+
+// splitComment splits value of line from its trailing comment, quoted # doesn't start comment.
+func splitComment(s string) (value, comment string) {
+	var quote rune
+
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return strings.TrimRight(s[:i], " \t"), s[i:]
+		}
+	}
+
+	return strings.TrimRight(s, " \t"), ""
+}
+
+func yamlScalar(s string) string {
+	if yamlPlain.MatchString(s) && !yamlReserved.MatchString(s) && !strings.HasSuffix(s, " ") && !strings.Contains(s, ": ") {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return s
+		}
+	}
+
+	return strconv.Quote(s)
+}
+
+func formatYAML(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return yamlScalar(v)
+	case []string:
+		items := make([]string, len(v))
+		for i, s := range v {
+			items[i] = yamlScalar(s)
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// setYAML sets path in block style YAML document, value of existing key is replaced keeping its comment,
+// missing keys are inserted at the end of their parent section.
+func setYAML(content []byte, path []string, value interface{}) ([]byte, error) {
+	lines := parseYAMLLines(content)
+	formatted := formatYAML(value)
+
+	// start and end bound lines of current section, parentIndent is indentation of its key
+	start, end, parentIndent := 0, len(lines), -1
+
+	for depth, segment := range path {
+		childIndent, found := -1, -1
+
+		for i := start; i < end; i++ {
+			if !lines[i].content {
+				continue
+			}
+
+			if childIndent == -1 {
+				childIndent = lines[i].indent
+			}
+
+			if lines[i].indent == childIndent && lines[i].key != "" && strings.EqualFold(lines[i].key, segment) {
+				found = i
+				break
+			}
+		}
+
+		if found == -1 {
+			if childIndent == -1 {
+				childIndent = parentIndent + 2
+				if parentIndent == -1 {
+					childIndent = 0
+				}
+			}
+
+			return joinYAML(insertYAML(lines, start, end, childIndent, path[depth:], formatted)), nil
+		}
+
+		line := lines[found]
+		inline, comment := splitComment(line.value)
+
+		// section ends before the next line indented at most as its key
+		sectionEnd := found + 1
+		for sectionEnd < end && (!lines[sectionEnd].content || lines[sectionEnd].indent > line.indent) {
+			sectionEnd++
+		}
+
+		if depth < len(path)-1 {
+			if inline != "" {
+				return nil, fmt.Errorf("%s is not a section", strings.Join(path[:depth+1], "."))
+			}
+
+			start, end, parentIndent = found+1, sectionEnd, line.indent
+			continue
+		}
+
+		// block list is replaced by inline list, nested section can't be replaced by value
+		for i := found + 1; i < sectionEnd; i++ {
+			if !lines[i].content {
+				continue
+			}
+
+			if !strings.HasPrefix(strings.TrimSpace(lines[i].text), "-") {
+				return nil, fmt.Errorf("%s is a section, set its keys instead", strings.Join(path, "."))
+			}
+		}
+
+		if inline == "" {
+			// trailing blank lines and comments belong to the next section
+			last := sectionEnd
+			for last > found+1 && !lines[last-1].content {
+				last--
+			}
+
+			lines = append(append([]yamlLine{}, lines[:found+1]...), lines[last:]...)
+		}
+
+		prefix := line.text[:len(line.text)-len(line.value)]
+		if line.value == "" {
+			prefix = strings.TrimRight(line.text, " ") + " "
+		}
+
+		if comment != "" {
+			comment = " " + comment
+		}
+
+		lines[found].text = prefix + formatted + comment
+
+		return joinYAML(lines), nil
+	}
+
+	return joinYAML(lines), nil
+}
+
+// insertYAML inserts path with value after the last line of section bounded by start and end.
+func insertYAML(lines []yamlLine, start, end, indent int, path []string, value string) []yamlLine {
+	at := start
+	for i := start; i < end; i++ {
+		if lines[i].content {
+			at = i + 1
+		}
+	}
+
+	var inserted []yamlLine
+	for i, segment := range path {
+		text := strings.Repeat(" ", indent+2*i) + segment + ":"
+		if i == len(path)-1 {
+			text += " " + value
+		}
+
+		inserted = append(inserted, yamlLine{text: text})
+	}
+
+	result := append([]yamlLine{}, lines[:at]...)
+	result = append(result, inserted...)
+
+	return append(result, lines[at:]...)
+}
+
+func joinYAML(lines []yamlLine) []byte {
+	var buf bytes.Buffer
+
+	for _, l := range lines {
+		buf.WriteString(l.text)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+var (
+	tomlTable   = regexp.MustCompile(`^\s*\[([^\[\]]+)\]\s*(#.*)?$`)
+	tomlKeyLine = regexp.MustCompile(`^(\s*)("[^"]*"|[A-Za-z0-9_.-]+)\s*=\s*(.*)$`)
+)
+
+func formatTOML(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []string:
+		items := make([]string, len(v))
+		for i, s := range v {
+			items[i] = strconv.Quote(s)
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// tomlSection is a table of TOML document, lines from start up to end, root table has empty name.
+type tomlSection struct {
+	name       string
+	start, end int
+}
+
+func tomlSections(lines []string) []tomlSection {
+	sections := []tomlSection{{start: 0}}
+
+	for i, l := range lines {
+		m := tomlTable.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+
+		sections[len(sections)-1].end = i
+		sections = append(sections, tomlSection{name: strings.TrimSpace(m[1]), start: i + 1})
+	}
+
+	sections[len(sections)-1].end = len(lines)
+
+	return sections
+}
+
+// setTOML sets path in TOML document. Key is looked up in table named by any prefix of path, with the rest
+// of path as dotted key, value is replaced keeping its comment. Missing key is inserted into table named by
+// path without its last element, table is appended if it doesn't exist.
+func setTOML(content []byte, path []string, value interface{}) ([]byte, error) {
+	text := strings.TrimSuffix(string(content), "\n")
+
+	var lines []string
+	if text != "" {
+		lines = strings.Split(text, "\n")
+	}
+
+	formatted := formatTOML(value)
+	sections := tomlSections(lines)
+
+	for _, s := range sections {
+		table := strings.Split(s.name, ".")
+		if s.name == "" {
+			table = nil
+		}
+
+		if len(table) >= len(path) || !strings.EqualFold(strings.Join(table, "."), strings.Join(path[:len(table)], ".")) {
+			continue
+		}
+
+		key := strings.Join(path[len(table):], ".")
+
+		for i := s.start; i < s.end; i++ {
+			m := tomlKeyLine.FindStringSubmatch(lines[i])
+			if m == nil || !strings.EqualFold(strings.Trim(m[2], `"`), key) {
+				continue
+			}
+
+			_, comment := splitComment(m[3])
+
+			// multi-line array continues until its brackets are balanced
+			last := i
+			for depth := strings.Count(m[3], "[") - strings.Count(m[3], "]"); depth > 0 && last+1 < s.end; {
+				last++
+				depth += strings.Count(lines[last], "[") - strings.Count(lines[last], "]")
+				comment = ""
+			}
+
+			if comment != "" {
+				comment = " " + comment
+			}
+
+			replaced := lines[i][:len(lines[i])-len(m[3])] + formatted + comment
+
+			result := append(append([]string{}, lines[:i]...), replaced)
+			lines = append(result, lines[last+1:]...)
+
+			return []byte(strings.Join(lines, "\n") + "\n"), nil
+		}
+	}
+
+	table := strings.Join(path[:len(path)-1], ".")
+	line := path[len(path)-1] + " = " + formatted
+
+	for _, s := range sections {
+		if !strings.EqualFold(s.name, table) {
+			continue
+		}
+
+		at := s.start
+		for i := s.start; i < s.end; i++ {
+			if tomlKeyLine.MatchString(lines[i]) {
+				at = i + 1
+			}
+		}
+
+		result := append(append([]string{}, lines[:at]...), line)
+		lines = append(result, lines[at:]...)
+
+		return []byte(strings.Join(lines, "\n") + "\n"), nil
+	}
+
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "["+table+"]", line)
+
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
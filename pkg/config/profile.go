@@ -0,0 +1,63 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// ProfilesKey is key of section of config file holding named profiles, e.g. Profiles.prod.Server1.Endpoint.
+// Keys set by selected profile override keys of config file, keys profile doesn't set are inherited.
+const ProfilesKey = "Profiles"
+
+// ProfileEnv is environment variable selecting profile, --profile flag has priority over it.
+const ProfileEnv = "DITTO_PROFILE"
+
+// profile is name of profile selected by SetProfile.
+var profile string
+
+// SetProfile selects profile applied by ReadConfig, empty name selects profile named by ProfileEnv.
+func SetProfile(name string) {
+	profile = name
+}
+
+// Profile returns name of selected profile, empty if none is selected.
+func Profile() string {
+	if profile != "" {
+		return profile
+	}
+
+	return os.Getenv(ProfileEnv)
+}
+
+// ProfileKey returns key of selected profile overriding key, or key itself if no profile is selected.
+func ProfileKey(key string) string {
+	if name := Profile(); name != "" {
+		return ProfilesKey + "." + name + "." + key
+	}
+
+	return key
+}
+
+// applyProfile overrides keys of config file with keys set by profile name.
+// Keys overridden by environment variables are kept.
+func applyProfile(name string) error {
+	p := viper.Sub(ProfilesKey + "." + name)
+	if p == nil {
+		return fmt.Errorf("profile %q is not defined in %s section of config file", name, ProfilesKey)
+	}
+
+	for _, key := range p.AllKeys() {
+		if _, ok := os.LookupEnv(EnvName(key)); ok {
+			continue
+		}
+
+		viper.Set(key, p.Get(key))
+	}
+
+	return nil
+}
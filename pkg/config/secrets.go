@@ -0,0 +1,192 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// SecretPrefix marks encrypted secret values in config.
+const SecretPrefix = "enc:"
+
+// PassphraseEnv is environment variable with passphrase of encrypted secrets, it has priority over key file.
+const PassphraseEnv = "DITTO_CONFIG_PASSPHRASE"
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// SecretKeys are config keys of secrets which are encrypted by `ditto config encrypt`.
+func SecretKeys() []string {
+	return []string{
+		SERVER_1_ACCESS_KEY,
+		SERVER_1_SECRET_KEY,
+		SERVER_2_ACCESS_KEY,
+		SERVER_2_SECRET_KEY,
+		VAULT_TOKEN,
+		VAULT_SECRET_ID,
+	}
+}
+
+// IsEncrypted returns true if value is encrypted secret.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, SecretPrefix)
+}
+
+// EncryptSecret encrypts value with AES-256-GCM with key derived from passphrase by scrypt.
+// Encrypted value is SecretPrefix followed by base64 of salt, nonce and sealed value.
+func EncryptSecret(value string, passphrase []byte) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	aead, err := secretCipher(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(append(salt, nonce...), nonce, []byte(value), nil)
+
+	return SecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts value encrypted by EncryptSecret, values which aren't encrypted are returned as is.
+func DecryptSecret(value string, passphrase []byte) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, SecretPrefix))
+	if err != nil || len(b) < saltSize {
+		return "", errors.New("encrypted secret is malformed")
+	}
+
+	aead, err := secretCipher(passphrase, b[:saltSize])
+	if err != nil {
+		return "", err
+	}
+
+	b = b[saltSize:]
+	if len(b) < aead.NonceSize() {
+		return "", errors.New("encrypted secret is malformed")
+	}
+
+	plain, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("unable to decrypt secret, passphrase is wrong or secret is corrupted")
+	}
+
+	return string(plain), nil
+}
+
+func secretCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase of secrets is empty")
+	}
+
+	key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Passphrase returns passphrase of encrypted secrets from PassphraseEnv, or content of keyFile
+// with trailing newline removed.
+func Passphrase(keyFile string) ([]byte, error) {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return []byte(p), nil
+	}
+
+	if keyFile == "" {
+		return nil, fmt.Errorf("config has encrypted secrets, set %s or Secrets.KeyFile", PassphraseEnv)
+	}
+
+	b, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.TrimRight(string(b), "\r\n")), nil
+}
+
+// secrets returns secret fields of c, including credentials of tenants.
+func (c *Config) secrets() []*string {
+	var secrets []*string
+
+	for _, creds := range []*Credentials{c.Server1, c.Server2} {
+		if creds != nil {
+			secrets = append(secrets, &creds.AccessKey, &creds.SecretKey)
+		}
+	}
+
+	if c.Vault != nil {
+		secrets = append(secrets, &c.Vault.Token, &c.Vault.SecretID)
+	}
+
+	for _, t := range c.Tenants {
+		if t != nil && t.Config != nil {
+			secrets = append(secrets, t.Config.secrets()...)
+		}
+	}
+
+	return secrets
+}
+
+// DecryptSecrets decrypts encrypted secrets of c in place. Passphrase is read only if there are encrypted secrets.
+func (c *Config) DecryptSecrets() error {
+	var passphrase []byte
+
+	for _, s := range c.secrets() {
+		if !IsEncrypted(*s) {
+			continue
+		}
+
+		if passphrase == nil {
+			var keyFile string
+			if c.Secrets != nil {
+				keyFile = c.Secrets.KeyFile
+			}
+
+			p, err := Passphrase(keyFile)
+			if err != nil {
+				return err
+			}
+
+			passphrase = p
+		}
+
+		plain, err := DecryptSecret(*s, passphrase)
+		if err != nil {
+			return err
+		}
+
+		*s = plain
+	}
+
+	return nil
+}
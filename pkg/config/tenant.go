@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import "reflect"
+
+// processWide are sections which configure the process or its local files rather than policies,
+// so they are never inherited by tenants. Tenant may set its own, e.g. its own State path.
+var processWide = map[string]bool{
+	"Admin":      true,
+	"Debug":      true,
+	"Metrics":    true,
+	"StatsD":     true,
+	"Audit":      true,
+	"Journal":    true,
+	"State":      true,
+	"Sync":       true,
+	"Seed":       true,
+	"Cache":      true,
+	"Accounting": true,
+	"Tenants":    true,
+}
+
+// ForTenant returns configuration of tenant: sections set in tenant's Config replace sections of c,
+// other sections are inherited from c, except for process wide ones.
+func (c *Config) ForTenant(tenant *TenantOptions) *Config {
+	cfg := &Config{}
+
+	root, dst := reflect.ValueOf(c).Elem(), reflect.ValueOf(cfg).Elem()
+
+	var own reflect.Value
+	if tenant.Config != nil {
+		own = reflect.ValueOf(tenant.Config).Elem()
+	}
+
+	for i := 0; i < dst.NumField(); i++ {
+		if own.IsValid() && !own.Field(i).IsNil() {
+			dst.Field(i).Set(own.Field(i))
+			continue
+		}
+
+		if !processWide[dst.Type().Field(i).Name] {
+			dst.Field(i).Set(root.Field(i))
+		}
+	}
+
+	cfg.Tenants = nil
+
+	return cfg
+}
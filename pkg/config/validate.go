@@ -0,0 +1,307 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidationError lists every problem found in config by Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("config is invalid:\n  - %s", strings.Join(e.Problems, "\n  - "))
+}
+
+// validator collects problems of config, prefix locates validated config, e.g. tenant.
+type validator struct {
+	prefix   string
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, v.prefix+fmt.Sprintf(format, args...))
+}
+
+// oneOf reports value of key which isn't one of values, empty value means default and is always valid.
+func (v *validator) oneOf(key, value string, values ...string) {
+	if value == "" {
+		return
+	}
+
+	for _, allowed := range values {
+		if strings.EqualFold(value, allowed) {
+			return
+		}
+	}
+
+	v.addf("%s %q is unknown, expected one of %s", key, value, strings.Join(values, ", "))
+}
+
+// exclusive reports keys which are set together although at most one of them may be set.
+func (v *validator) exclusive(set map[string]bool, keys ...string) {
+	var conflicting []string
+
+	for _, key := range keys {
+		if set[key] {
+			conflicting = append(conflicting, key)
+		}
+	}
+
+	if len(conflicting) > 1 {
+		v.addf("%s are mutually exclusive, set only one of them", strings.Join(conflicting, " and "))
+	}
+}
+
+// Validate checks that required keys are set, endpoints and URLs are well formed, enumerated options have
+// known values and mutually exclusive options aren't set together. Config of every tenant is validated
+// with sections it inherits. Returns *ValidationError listing all problems found.
+func (c *Config) Validate() error {
+	v := &validator{}
+	c.validate(v)
+
+	names := make(map[string]bool)
+
+	for i, t := range c.Tenants {
+		if t == nil {
+			continue
+		}
+
+		if t.Name == "" {
+			v.addf("Tenants[%d].Name is not set, every tenant must be named", i)
+			continue
+		}
+
+		if names[t.Name] {
+			v.addf("tenant %q is configured more than once", t.Name)
+		}
+
+		names[t.Name] = true
+
+		if len(t.AccessKeys) == 0 && t.BucketPrefix == "" {
+			v.addf("tenant %q has neither AccessKeys nor BucketPrefix, so no request is routed to it", t.Name)
+		}
+
+		tv := &validator{prefix: fmt.Sprintf("tenant %q: ", t.Name)}
+		c.ForTenant(t).validate(tv)
+
+		// problems inherited from root are reported once
+		for _, p := range tv.problems {
+			if !contains(v.problems, strings.TrimPrefix(p, tv.prefix)) {
+				v.problems = append(v.problems, p)
+			}
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+func (c *Config) validate(v *validator) {
+	for _, s := range []struct {
+		name  string
+		creds *Credentials
+	}{{"Server1", c.Server1}, {"Server2", c.Server2}} {
+		creds := s.creds
+		if creds == nil {
+			creds = &Credentials{}
+		}
+
+		if creds.Endpoint == "" {
+			v.addf("%s.Endpoint is not set, define it with `ditto config set %s.Endpoint <host:port>`", s.name, s.name)
+		} else if err := validateEndpoint(creds.Endpoint); err != nil {
+			v.addf("%s.Endpoint %q is invalid: %s, expected host:port or http(s)://host:port", s.name, creds.Endpoint, err)
+		}
+
+		// keys read from Vault aren't part of config
+		if c.IsVaulted() {
+			continue
+		}
+
+		if creds.AccessKey == "" {
+			v.addf("%s.AccessKey is not set, define it with `ditto config set %s.AccessKey <key>`", s.name, s.name)
+		}
+
+		if creds.SecretKey == "" {
+			v.addf("%s.SecretKey is not set, define it with `ditto config set %s.SecretKey <key>`", s.name, s.name)
+		}
+	}
+
+	if opts := c.Vault; c.IsVaulted() {
+		if err := validateURL(opts.Address); err != nil {
+			v.addf("Vault.Address %q is invalid: %s", opts.Address, err)
+		}
+
+		if opts.Server1Path == "" || opts.Server2Path == "" {
+			v.addf("Vault.Server1Path and Vault.Server2Path must be set to read keys from Vault")
+		}
+
+		if opts.Token == "" && os.Getenv("VAULT_TOKEN") == "" && (opts.RoleID == "" || opts.SecretID == "") {
+			v.addf("Vault.Token, VAULT_TOKEN environment variable or Vault.RoleID with Vault.SecretID must be set")
+		}
+	}
+
+	if opts := c.Log; opts != nil {
+		levels := []string{"debug", "info", "warn", "error"}
+
+		v.oneOf("Log.Level", opts.Level, levels...)
+		v.oneOf("Log.MirroringLevel", opts.MirroringLevel, levels...)
+		v.oneOf("Log.ReplicationLevel", opts.ReplicationLevel, levels...)
+		v.oneOf("Log.SyncLevel", opts.SyncLevel, levels...)
+		v.oneOf("Log.CLILevel", opts.CLILevel, levels...)
+		v.oneOf("Log.Format", opts.Format, "json", "console")
+
+		v.exclusive(map[string]bool{"Log.File": opts.File != "", "Log.Syslog": opts.Syslog != "", "Log.Journald": opts.Journald},
+			"Log.File", "Log.Syslog", "Log.Journald")
+	}
+
+	if opts := c.Read; opts != nil {
+		v.oneOf("Read.Mode", opts.Mode, "prime", "auto", "hedged")
+	}
+
+	if opts := c.Repair; opts != nil {
+		v.oneOf("Repair.Policy", opts.Policy, "prime", "alter", "merge")
+	}
+
+	if opts := c.Compression; opts != nil {
+		v.oneOf("Compression.Algorithm", opts.Algorithm, "none", "gzip", "zstd")
+	}
+
+	if opts := c.Encryption; opts != nil {
+		v.exclusive(map[string]bool{"Encryption.Key": opts.Key != "", "Encryption.KeyFile": opts.KeyFile != ""},
+			"Encryption.Key", "Encryption.KeyFile")
+	}
+
+	if opts := c.Events; opts != nil && opts.Type != "" {
+		v.oneOf("Events.Type", opts.Type, "nats", "kafka")
+
+		if opts.Address == "" {
+			v.addf("Events.Address must be set to export events to %s", opts.Type)
+		}
+	}
+
+	if opts := c.Webhook; opts != nil && opts.URL != "" {
+		if err := validateURL(opts.URL); err != nil {
+			v.addf("Webhook.URL %q is invalid: %s", opts.URL, err)
+		}
+	}
+
+	if opts := c.StatsD; opts != nil && opts.Host != "" && (opts.Port <= 0 || opts.Port > 65535) {
+		v.addf("StatsD.Port %d is invalid, expected port number of statsd at StatsD.Host", opts.Port)
+	}
+
+	if opts := c.FilterOptions; opts != nil {
+		for _, p := range opts.IncludePatterns {
+			if _, err := regexp.Compile(p); err != nil {
+				v.addf("FilterOptions.IncludePatterns %q is not valid regular expression: %s", p, err)
+			}
+		}
+
+		for _, p := range opts.ExcludePatterns {
+			if _, err := regexp.Compile(p); err != nil {
+				v.addf("FilterOptions.ExcludePatterns %q is not valid regular expression: %s", p, err)
+			}
+		}
+	}
+
+	if opts := c.FailoverOptions; opts != nil && opts.Enabled {
+		if opts.FailureThreshold <= 0 || opts.RecoveryThreshold <= 0 {
+			v.addf("FailoverOptions.FailureThreshold and FailoverOptions.RecoveryThreshold must be positive")
+		}
+
+		if c.Journal == nil || c.Journal.Path == "" {
+			v.addf("FailoverOptions.Enabled requires Journal.Path to keep writes accepted by alter until prime is back")
+		}
+	}
+
+	if opts := c.Shadow; opts != nil && opts.Enabled {
+		if opts.Percentage <= 0 || opts.Percentage > 100 {
+			v.addf("Shadow.Percentage %v is invalid, expected percentage of writes in (0, 100]", opts.Percentage)
+		}
+
+		if c.DryRun != nil && c.DryRun.Enabled {
+			v.addf("Shadow.Enabled and DryRun.Enabled are mutually exclusive, set only one of them")
+		}
+	}
+
+	// dry run discards alter writes, so writes accepted only by alter would be lost and every verified write diverged
+	if c.DryRun != nil && c.DryRun.Enabled {
+		if opts := c.FailoverOptions; opts != nil && opts.Enabled {
+			v.addf("FailoverOptions.Enabled and DryRun.Enabled are mutually exclusive, set only one of them")
+		}
+
+		if opts := c.PutOptions; opts != nil && opts.VerifyChecksum {
+			v.addf("PutOptions.VerifyChecksum and DryRun.Enabled are mutually exclusive, set only one of them")
+		}
+	}
+
+	if opts := c.Quota; opts != nil && opts.IsEnabled() && (c.State == nil || c.State.Path == "") {
+		v.addf("Quota requires State.Path to track usage of buckets")
+	}
+
+	if opts := c.PutOptions; opts != nil && opts.Dedup && (c.Metadata == nil || !c.Metadata.ContentHash) {
+		v.addf("PutOptions.Dedup requires Metadata.ContentHash to compare content of objects")
+	}
+}
+
+// validateEndpoint checks endpoint of backend, host:port or URL without path.
+func validateEndpoint(endpoint string) error {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	if err := validateURL(endpoint); err != nil {
+		return err
+	}
+
+	if u, _ := url.Parse(endpoint); u.Path != "" && u.Path != "/" {
+		return fmt.Errorf("path %q is not allowed", u.Path)
+	}
+
+	return nil
+}
+
+// validateURL checks that rawurl is absolute http or https URL with valid port, if any.
+func validateURL(rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme %q is not http or https", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return fmt.Errorf("host is missing")
+	}
+
+	if port := u.Port(); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+			return fmt.Errorf("port %q is invalid", port)
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package context
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// BackendHeader is a request header which forces reads to be served by particular backend.
+const BackendHeader = "x-ditto-backend"
+
+// Backend identifies one of the mirrored object layers.
+type Backend string
+
+const (
+	PRIME Backend = "prime"
+	ALTER Backend = "alter"
+)
+
+type backendKey struct{}
+
+// ParseBackend converts string to Backend, returns false for unknown values.
+func ParseBackend(s string) (Backend, bool) {
+	b := Backend(strings.ToLower(strings.TrimSpace(s)))
+
+	switch b {
+	case PRIME, ALTER:
+		return b, true
+	default:
+		return "", false
+	}
+}
+
+// WithBackend returns copy of ctx which forces reads from backend.
+func WithBackend(ctx context.Context, backend Backend) context.Context {
+	return context.WithValue(ctx, backendKey{}, backend)
+}
+
+// BackendFromContext returns backend forced for ctx, if any.
+func BackendFromContext(ctx context.Context) (Backend, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	b, ok := ctx.Value(backendKey{}).(Backend)
+
+	return b, ok
+}
+
+// BackendHandler stores backend requested with BackendHeader in request context.
+// Requests with unknown header values are passed through unchanged.
+func BackendHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if b, ok := ParseBackend(r.Header.Get(BackendHeader)); ok {
+			r = r.WithContext(WithBackend(r.Context(), b))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,28 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package context
+
+import (
+	"context"
+)
+
+type exactListingKey struct{}
+
+// WithExactListing returns copy of ctx asking layers which transform content, e.g. compress it, to list objects
+// with sizes and ETags of their content, which costs them a request per listed object. Without it listed objects
+// are reported as stored by backend.
+func WithExactListing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exactListingKey{}, true)
+}
+
+// IsExactListing returns true if ctx asks for sizes and ETags of content of listed objects.
+func IsExactListing(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	exact, _ := ctx.Value(exactListingKey{}).(bool)
+
+	return exact
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package context
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Preconditions are conditional request headers of S3 request.
+type Preconditions struct {
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+}
+
+type preconditionsKey struct{}
+
+// ParsePreconditions reads conditional headers of request, returns false if there are none.
+// Dates which can't be parsed are ignored, as required by RFC 7232.
+func ParsePreconditions(h http.Header) (Preconditions, bool) {
+	p := Preconditions{
+		IfMatch:     strings.TrimSpace(h.Get("If-Match")),
+		IfNoneMatch: strings.TrimSpace(h.Get("If-None-Match")),
+	}
+
+	if t, err := http.ParseTime(h.Get("If-Modified-Since")); err == nil {
+		p.IfModifiedSince = t
+	}
+
+	if t, err := http.ParseTime(h.Get("If-Unmodified-Since")); err == nil {
+		p.IfUnmodifiedSince = t
+	}
+
+	return p, !p.IsEmpty()
+}
+
+// IsEmpty returns true if no precondition is set.
+func (p Preconditions) IsEmpty() bool {
+	return p.IfMatch == "" && p.IfNoneMatch == "" && p.IfModifiedSince.IsZero() && p.IfUnmodifiedSince.IsZero()
+}
+
+// CheckWrite returns true if write of object is allowed.
+// exists tells whether object currently exists, etag and modTime describe it if it does.
+// Unlike reads, every failed write precondition results in 412 Precondition Failed.
+func (p Preconditions) CheckWrite(exists bool, etag string, modTime time.Time) bool {
+	if p.IfMatch != "" && (!exists || !MatchETag(p.IfMatch, etag)) {
+		return false
+	}
+
+	if p.IfNoneMatch != "" && exists && MatchETag(p.IfNoneMatch, etag) {
+		return false
+	}
+
+	if !p.IfUnmodifiedSince.IsZero() && exists && modTime.After(p.IfUnmodifiedSince) {
+		return false
+	}
+
+	return true
+}
+
+// MatchETag returns true if etag matches list of ETags in If-Match or If-None-Match header.
+// "*" matches any ETag, comparison ignores quotes and weak validator prefix.
+func MatchETag(header, etag string) bool {
+	etag = trimETag(etag)
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || trimETag(candidate) == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchTransformed checks etag of read and If-Match of request in ctx against etag of object as clients see it,
+// for layers storing transformed content, e.g. compressed or encrypted, whose backend knows only ETag of stored content.
+// Returns ctx without If-Match, which such backend can't evaluate, and false if any of them doesn't match.
+func MatchTransformed(ctx context.Context, etag, plainETag string) (context.Context, bool) {
+	if etag != "" && !MatchETag(etag, plainETag) {
+		return ctx, false
+	}
+
+	p, ok := PreconditionsFromContext(ctx)
+	if !ok || p.IfMatch == "" {
+		return ctx, true
+	}
+
+	if !MatchETag(p.IfMatch, plainETag) {
+		return ctx, false
+	}
+
+	p.IfMatch = ""
+
+	return WithPreconditions(ctx, p), true
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(strings.TrimPrefix(strings.TrimSpace(etag), "W/"), "\"")
+}
+
+// WithPreconditions returns copy of ctx carrying preconditions of the request.
+func WithPreconditions(ctx context.Context, p Preconditions) context.Context {
+	return context.WithValue(ctx, preconditionsKey{}, p)
+}
+
+// PreconditionsFromContext returns preconditions of the request, if any.
+func PreconditionsFromContext(ctx context.Context) (Preconditions, bool) {
+	if ctx == nil {
+		return Preconditions{}, false
+	}
+
+	p, ok := ctx.Value(preconditionsKey{}).(Preconditions)
+
+	return p, ok
+}
+
+// PreconditionsHandler stores conditional headers of request in request context,
+// so object layers can pass them to backends.
+func PreconditionsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := ParsePreconditions(r.Header); ok {
+			r = r.WithContext(WithPreconditions(r.Context(), p))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
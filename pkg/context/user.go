@@ -0,0 +1,75 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package context
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type userKey struct{}
+
+// WithUser returns copy of ctx carrying access key of user issuing the request.
+func WithUser(ctx context.Context, accessKey string) context.Context {
+	return context.WithValue(ctx, userKey{}, accessKey)
+}
+
+// UserFromContext returns access key of user issuing the request, if known.
+func UserFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	user, ok := ctx.Value(userKey{}).(string)
+
+	return user, ok
+}
+
+// AccessKey extracts access key from signature of r, either signature V4 or V2, in header or presigned URL.
+// Signature itself is verified by the gateway, so access key is only informative until then.
+func AccessKey(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+
+	switch {
+	case strings.HasPrefix(auth, "AWS4-HMAC-SHA256 "):
+		for _, part := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ",") {
+			part = strings.TrimSpace(part)
+			if strings.HasPrefix(part, "Credential=") {
+				return credentialAccessKey(strings.TrimPrefix(part, "Credential="))
+			}
+		}
+	case strings.HasPrefix(auth, "AWS "):
+		if i := strings.LastIndex(auth, ":"); i > len("AWS ") {
+			return auth[len("AWS "):i]
+		}
+	}
+
+	query := r.URL.Query()
+	if cred := query.Get("X-Amz-Credential"); cred != "" {
+		return credentialAccessKey(cred)
+	}
+
+	return query.Get("AWSAccessKeyId")
+}
+
+// credentialAccessKey returns access key of credential scope, i.e. <access key>/<date>/<region>/s3/aws4_request.
+func credentialAccessKey(credential string) string {
+	if i := strings.Index(credential, "/"); i >= 0 {
+		return credential[:i]
+	}
+
+	return credential
+}
+
+// UserHandler stores access key of user issuing every request in request context.
+func UserHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user := AccessKey(r); user != "" {
+			r = r.WithContext(WithUser(r.Context(), user))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package credentials
+
+import (
+	"context"
+
+	"storj.io/ditto/pkg/config"
+)
+
+// Backends whose keys are provided.
+const (
+	Prime = "prime"
+	Alter = "alter"
+)
+
+// Keys are access and secret key of a backend.
+type Keys struct {
+	AccessKey string
+	SecretKey string
+}
+
+// Provider resolves keys of backends, so they don't have to be stored in config.
+type Provider interface {
+	// Keys returns keys of backend, Prime or Alter.
+	Keys(ctx context.Context, backend string) (Keys, error)
+}
+
+// NewProvider returns provider configured by cfg: Vault if Vault.Address is set, otherwise keys of cfg.
+func NewProvider(cfg *config.Config) (Provider, error) {
+	if opts := cfg.Vault; opts != nil && opts.Address != "" {
+		return NewVault(opts)
+	}
+
+	return &static{cfg}, nil
+}
+
+// static provides keys stored in config.
+type static struct {
+	cfg *config.Config
+}
+
+func (s *static) Keys(ctx context.Context, backend string) (Keys, error) {
+	c := s.cfg.Server1
+	if backend == Alter {
+		c = s.cfg.Server2
+	}
+
+	if c == nil {
+		return Keys{}, nil
+	}
+
+	return Keys{AccessKey: c.AccessKey, SecretKey: c.SecretKey}, nil
+}
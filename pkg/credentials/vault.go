@@ -0,0 +1,192 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/config"
+)
+
+// DefaultTimeout is timeout of requests to Vault.
+const DefaultTimeout = 10 * time.Second
+
+// Vault reads keys of backends from secrets of Vault KV engine, version 1 or 2.
+// Secret must have access_key and secret_key fields, AccessKey and SecretKey are accepted as well.
+// Vault is authenticated with token, VAULT_TOKEN environment variable if token isn't configured,
+// or by AppRole login with role and secret ID, which is repeated when token expires.
+type Vault struct {
+	address  string
+	roleID   string
+	secretID string
+	paths    map[string]string
+	client   *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewVault creates provider reading keys of prime from Server1Path and keys of alter from Server2Path.
+func NewVault(opts *config.VaultOptions) (*Vault, error) {
+	if opts.Server1Path == "" || opts.Server2Path == "" {
+		return nil, errors.New("vault paths of Server1 and Server2 credentials must be set")
+	}
+
+	token := opts.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+
+	if token == "" && (opts.RoleID == "" || opts.SecretID == "") {
+		return nil, errors.New("vault token or AppRole role and secret ID must be set")
+	}
+
+	return &Vault{
+		address:  strings.TrimSuffix(opts.Address, "/"),
+		roleID:   opts.RoleID,
+		secretID: opts.SecretID,
+		paths:    map[string]string{Prime: opts.Server1Path, Alter: opts.Server2Path},
+		client:   &http.Client{Timeout: DefaultTimeout},
+		token:    token,
+	}, nil
+}
+
+func (v *Vault) Keys(ctx context.Context, backend string) (Keys, error) {
+	path, ok := v.paths[backend]
+	if !ok {
+		return Keys{}, fmt.Errorf("unknown backend %q", backend)
+	}
+
+	data, err := v.read(ctx, path)
+	if err != nil {
+		return Keys{}, err
+	}
+
+	keys := Keys{AccessKey: field(data, "access_key", "AccessKey"), SecretKey: field(data, "secret_key", "SecretKey")}
+	if keys.AccessKey == "" || keys.SecretKey == "" {
+		return Keys{}, fmt.Errorf("vault secret %s has no access_key or secret_key", path)
+	}
+
+	return keys, nil
+}
+
+// read returns data of secret at path, token obtained by AppRole login is renewed once if it's refused.
+func (v *Vault) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	token, err := v.currentToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	status, err := v.do(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), token, nil, &secret)
+	if status == http.StatusForbidden && v.roleID != "" {
+		if token, err = v.login(ctx); err != nil {
+			return nil, err
+		}
+
+		status, err = v.do(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), token, nil, &secret)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vault secret %s: %s", path, err)
+	}
+
+	// KV version 2 nests secret in data with its metadata
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			return nested, nil
+		}
+	}
+
+	return secret.Data, nil
+}
+
+func (v *Vault) currentToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	token := v.token
+	v.mu.Unlock()
+
+	if token != "" {
+		return token, nil
+	}
+
+	return v.login(ctx)
+}
+
+// login obtains token by AppRole login.
+func (v *Vault) login(ctx context.Context) (string, error) {
+	body, err := json.Marshal(map[string]string{"role_id": v.roleID, "secret_id": v.secretID})
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+
+	if _, err = v.do(ctx, http.MethodPost, "/v1/auth/approle/login", "", body, &resp); err != nil {
+		return "", fmt.Errorf("vault AppRole login failed: %s", err)
+	}
+
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("vault AppRole login returned no token")
+	}
+
+	v.mu.Lock()
+	v.token = resp.Auth.ClientToken
+	v.mu.Unlock()
+
+	return resp.Auth.ClientToken, nil
+}
+
+// do sends request to Vault and decodes successful response into v, status of response is returned with error.
+func (v *Vault) do(ctx context.Context, method, path, token string, body []byte, out interface{}) (int, error) {
+	req, err := http.NewRequest(method, v.address+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req = req.WithContext(ctx)
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("vault responded %s", resp.Status)
+	}
+
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// field returns the first string field of data found by names.
+func field(data map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		if s, ok := data[name].(string); ok && s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
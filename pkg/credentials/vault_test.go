@@ -0,0 +1,141 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+)
+
+// newVault starts fake Vault serving KV v1 secret at secret/prime and KV v2 secret at kv/data/alter,
+// AppRole login issues token "issued".
+func newVault(t *testing.T, token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/approle/login" {
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+			if body["role_id"] != "role" || body["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.Write([]byte(`{"auth": {"client_token": "issued"}}`))
+			return
+		}
+
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/prime":
+			w.Write([]byte(`{"data": {"access_key": "prime-access", "secret_key": "prime-secret"}}`))
+		case "/v1/kv/data/alter":
+			w.Write([]byte(`{"data": {"data": {"AccessKey": "alter-access", "SecretKey": "alter-secret"}, "metadata": {"version": 1}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestVault(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Keys are read with token",
+			func(t *testing.T) {
+				srv := newVault(t, "token")
+				defer srv.Close()
+
+				v, err := NewVault(&config.VaultOptions{Address: srv.URL, Token: "token", Server1Path: "secret/prime", Server2Path: "kv/data/alter"})
+				assert.NoError(t, err)
+
+				keys, err := v.Keys(context.Background(), Prime)
+				assert.NoError(t, err)
+				assert.Equal(t, Keys{AccessKey: "prime-access", SecretKey: "prime-secret"}, keys)
+
+				keys, err = v.Keys(context.Background(), Alter)
+				assert.NoError(t, err)
+				assert.Equal(t, Keys{AccessKey: "alter-access", SecretKey: "alter-secret"}, keys)
+			},
+		},
+		{
+			"Keys are read after AppRole login",
+			func(t *testing.T) {
+				srv := newVault(t, "issued")
+				defer srv.Close()
+
+				v, err := NewVault(&config.VaultOptions{Address: srv.URL, RoleID: "role", SecretID: "secret", Server1Path: "secret/prime", Server2Path: "kv/data/alter"})
+				assert.NoError(t, err)
+
+				keys, err := v.Keys(context.Background(), Prime)
+				assert.NoError(t, err)
+				assert.Equal(t, "prime-access", keys.AccessKey)
+
+				// expired token is replaced by new login
+				v.token = "expired"
+
+				keys, err = v.Keys(context.Background(), Alter)
+				assert.NoError(t, err)
+				assert.Equal(t, "alter-access", keys.AccessKey)
+			},
+		},
+		{
+			"Refused token and missing secret fail",
+			func(t *testing.T) {
+				srv := newVault(t, "token")
+				defer srv.Close()
+
+				v, err := NewVault(&config.VaultOptions{Address: srv.URL, Token: "wrong", Server1Path: "secret/prime", Server2Path: "secret/missing"})
+				assert.NoError(t, err)
+
+				_, err = v.Keys(context.Background(), Prime)
+				assert.Error(t, err)
+
+				v.token = "token"
+
+				_, err = v.Keys(context.Background(), Alter)
+				assert.Error(t, err)
+			},
+		},
+		{
+			"Incomplete options are refused",
+			func(t *testing.T) {
+				_, err := NewVault(&config.VaultOptions{Address: "http://vault", Token: "token", Server1Path: "secret/prime"})
+				assert.Error(t, err)
+
+				_, err = NewVault(&config.VaultOptions{Address: "http://vault", RoleID: "role", Server1Path: "a", Server2Path: "b"})
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestStaticProvider(t *testing.T) {
+	cfg := &config.Config{
+		Server1: &config.Credentials{AccessKey: "a1", SecretKey: "s1"},
+		Server2: &config.Credentials{AccessKey: "a2", SecretKey: "s2"},
+	}
+
+	p, err := NewProvider(cfg)
+	assert.NoError(t, err)
+
+	keys, err := p.Keys(context.Background(), Alter)
+	assert.NoError(t, err)
+	assert.Equal(t, Keys{AccessKey: "a2", SecretKey: "s2"}, keys)
+}
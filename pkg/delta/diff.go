@@ -0,0 +1,249 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package delta
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	minio "github.com/minio/minio/cmd"
+	dcontext "storj.io/ditto/pkg/context"
+)
+
+const listBatch = 1000
+
+type Kind string
+
+const (
+	// MISSING object exists only on prime.
+	MISSING Kind = "missing"
+	// CHANGED object exists on both backends, but copies differ.
+	CHANGED Kind = "changed"
+	// EXTRA object exists only on alter.
+	EXTRA Kind = "extra"
+)
+
+// Change is a difference between prime and alter copy of an object.
+// Prime or Alter is empty if object doesn't exist on that backend.
+type Change struct {
+	Kind   Kind
+	Bucket string
+	Object string
+	Prime  minio.ObjectInfo
+	Alter  minio.ObjectInfo
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s/%s", c.Kind, c.Bucket, c.Object)
+}
+
+// Same compares copies of an object by size and ETag as returned by listings.
+// ETags of multipart uploads, which contain "-", depend on part size of particular upload,
+// so such copies are compared only by size.
+func Same(prime, alter minio.ObjectInfo) bool {
+	if prime.Size != alter.Size {
+		return false
+	}
+
+	petag, aetag := normalizeETag(prime.ETag), normalizeETag(alter.ETag)
+	if petag == "" || aetag == "" || strings.Contains(petag, "-") || strings.Contains(aetag, "-") {
+		return true
+	}
+
+	return petag == aetag
+}
+
+// Diff lists objects of bucket under prefix on both backends and calls fn for every difference.
+// Listings are merged in key order, so backends are listed only once. Diff stops at first error returned by fn.
+func Diff(ctx context.Context, prime, alter minio.ObjectLayer, bucket, prefix string, fn func(Change) error) error {
+	return diff(ctx, prime, alter, bucket, prefix, "", fn, nil)
+}
+
+// Walk lists objects of bucket under prefix on both backends and calls fn for every key with its prime
+// and alter copy, nil if copy doesn't exist. Listings are merged in key order. Walk stops at first error returned by fn.
+func Walk(ctx context.Context, prime, alter minio.ObjectLayer, bucket, prefix string, fn func(key string, poi, aoi *minio.ObjectInfo) error) error {
+	return walk(ctx, prime, alter, bucket, prefix, "", fn)
+}
+
+// diff compares objects listed after marker. Every compared key is passed to progress, if set,
+// once its difference is handled.
+func diff(ctx context.Context, prime, alter minio.ObjectLayer, bucket, prefix, marker string, fn func(Change) error, progress func(key string) error) error {
+	return walk(ctx, prime, alter, bucket, prefix, marker, func(key string, poi, aoi *minio.ObjectInfo) error {
+		if change := compare(bucket, key, poi, aoi); change != nil {
+			if err := fn(*change); err != nil {
+				return err
+			}
+		}
+
+		if progress != nil {
+			return progress(key)
+		}
+
+		return nil
+	})
+}
+
+// DiffKeys is Diff of given keys of bucket, which are looked up on both backends instead of listing them.
+// Key which exists on neither backend isn't a difference. DiffKeys stops at first error.
+func DiffKeys(ctx context.Context, prime, alter minio.ObjectLayer, bucket string, keys []string, fn func(Change) error) error {
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		poi, err := stat(ctx, prime, bucket, key)
+		if err != nil {
+			return fmt.Errorf("prime: %s", err)
+		}
+
+		aoi, err := stat(ctx, alter, bucket, key)
+		if err != nil {
+			return fmt.Errorf("alter: %s", err)
+		}
+
+		if change := compare(bucket, key, poi, aoi); change != nil {
+			if err := fn(*change); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// compare returns change of key, nil if copies are the same or key exists on neither backend.
+func compare(bucket, key string, poi, aoi *minio.ObjectInfo) *Change {
+	switch {
+	case poi == nil && aoi == nil:
+		return nil
+	case aoi == nil:
+		return &Change{Kind: MISSING, Bucket: bucket, Object: key, Prime: *poi}
+	case poi == nil:
+		return &Change{Kind: EXTRA, Bucket: bucket, Object: key, Alter: *aoi}
+	case !Same(*poi, *aoi):
+		return &Change{Kind: CHANGED, Bucket: bucket, Object: key, Prime: *poi, Alter: *aoi}
+	}
+
+	return nil
+}
+
+// stat returns info of object, nil if it doesn't exist.
+func stat(ctx context.Context, ol minio.ObjectLayer, bucket, key string) (*minio.ObjectInfo, error) {
+	oi, err := ol.GetObjectInfo(ctx, bucket, key, minio.ObjectOptions{})
+	switch err.(type) {
+	case nil:
+		return &oi, nil
+	case minio.ObjectNotFound, minio.BucketNotFound:
+		return nil, nil
+	}
+
+	return nil, err
+}
+
+// walk is Walk of objects listed after marker.
+func walk(ctx context.Context, prime, alter minio.ObjectLayer, bucket, prefix, marker string, fn func(key string, poi, aoi *minio.ObjectInfo) error) error {
+	// copies are compared by sizes and ETags of their content, even if alter stores it transformed
+	ctx = dcontext.WithExactListing(ctx)
+
+	pl := &lister{ol: prime, bucket: bucket, prefix: prefix, marker: marker}
+	al := &lister{ol: alter, bucket: bucket, prefix: prefix, marker: marker}
+
+	poi, pok, err := pl.next(ctx)
+	if err != nil {
+		return err
+	}
+
+	aoi, aok, err := al.next(ctx)
+	if err != nil {
+		return err
+	}
+
+	for pok || aok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var p, a *minio.ObjectInfo
+
+		switch {
+		case !aok || (pok && poi.Name < aoi.Name):
+			p = copyOf(poi)
+			poi, pok, err = pl.next(ctx)
+		case !pok || aoi.Name < poi.Name:
+			a = copyOf(aoi)
+			aoi, aok, err = al.next(ctx)
+		default:
+			p, a = copyOf(poi), copyOf(aoi)
+
+			if poi, pok, err = pl.next(ctx); err == nil {
+				aoi, aok, err = al.next(ctx)
+			}
+		}
+
+		key := a
+		if p != nil {
+			key = p
+		}
+
+		if ferr := fn(key.Name, p, a); ferr != nil {
+			return ferr
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyOf(oi minio.ObjectInfo) *minio.ObjectInfo {
+	return &oi
+}
+
+// lister iterates objects of bucket page by page. Missing bucket is treated as empty.
+type lister struct {
+	ol             minio.ObjectLayer
+	bucket, prefix string
+
+	page   []minio.ObjectInfo
+	marker string
+	done   bool
+}
+
+func (l *lister) next(ctx context.Context) (minio.ObjectInfo, bool, error) {
+	for len(l.page) == 0 {
+		if l.done {
+			return minio.ObjectInfo{}, false, nil
+		}
+
+		loi, err := l.ol.ListObjects(ctx, l.bucket, l.prefix, l.marker, "", listBatch)
+		if err != nil {
+			if _, ok := err.(minio.BucketNotFound); ok {
+				l.done = true
+				continue
+			}
+
+			return minio.ObjectInfo{}, false, err
+		}
+
+		l.page = loi.Objects
+		l.done = !loi.IsTruncated || len(loi.Objects) == 0
+
+		l.marker = loi.NextMarker
+		if l.marker == "" && len(loi.Objects) > 0 {
+			l.marker = loi.Objects[len(loi.Objects)-1].Name
+		}
+	}
+
+	oi := l.page[0]
+	l.page = l.page[1:]
+
+	return oi, true, nil
+}
+
+func normalizeETag(etag string) string {
+	return strings.ToLower(strings.Trim(etag, "\""))
+}
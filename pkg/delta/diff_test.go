@@ -0,0 +1,111 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package delta
+
+import (
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// newListedLayer returns object layer which lists objects in pages of pageSize.
+func newListedLayer(pageSize int, objects ...minio.ObjectInfo) minio.ObjectLayer {
+	ol := test.NewProxyObjectLayer()
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		var page []minio.ObjectInfo
+
+		for _, o := range objects {
+			if o.Name > marker && len(page) < pageSize {
+				page = append(page, o)
+			}
+		}
+
+		truncated := len(page) > 0 && page[len(page)-1].Name != objects[len(objects)-1].Name
+
+		return minio.ListObjectsInfo{Objects: page, IsTruncated: truncated}, nil
+	}
+
+	return ol
+}
+
+func TestDiff(t *testing.T) {
+	prime := newListedLayer(2,
+		minio.ObjectInfo{Name: "a", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "b", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "c", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "e", Size: 1, ETag: "1-2"},
+	)
+
+	alter := newListedLayer(3,
+		minio.ObjectInfo{Name: "b", Size: 1, ETag: "\"1\""},
+		minio.ObjectInfo{Name: "c", Size: 1, ETag: "2"},
+		minio.ObjectInfo{Name: "d", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "e", Size: 1, ETag: "3"},
+	)
+
+	var changes []string
+
+	err := Diff(context.Background(), prime, alter, "bucket", "", func(c Change) error {
+		changes = append(changes, c.String())
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"missing bucket/a", "changed bucket/c", "extra bucket/d"}, changes)
+}
+
+func TestDiffKeys(t *testing.T) {
+	layer := func(objects ...minio.ObjectInfo) minio.ObjectLayer {
+		ol := test.NewProxyObjectLayer()
+		ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			for _, o := range objects {
+				if o.Name == object {
+					return o, nil
+				}
+			}
+
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		return ol
+	}
+
+	prime := layer(minio.ObjectInfo{Name: "a", Size: 1, ETag: "1"}, minio.ObjectInfo{Name: "b", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "c", Size: 1, ETag: "1"})
+	alter := layer(minio.ObjectInfo{Name: "b", Size: 1, ETag: "1"}, minio.ObjectInfo{Name: "c", Size: 2, ETag: "1"},
+		minio.ObjectInfo{Name: "d", Size: 1, ETag: "1"})
+
+	var changes []string
+
+	err := DiffKeys(context.Background(), prime, alter, "bucket", []string{"d", "c", "b", "a", "x"}, func(c Change) error {
+		changes = append(changes, c.String())
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"extra bucket/d", "changed bucket/c", "missing bucket/a"}, changes)
+}
+
+func TestSame(t *testing.T) {
+	cases := []struct {
+		testName     string
+		prime, alter minio.ObjectInfo
+		expected     bool
+	}{
+		{"Same ETag", minio.ObjectInfo{Size: 1, ETag: "A"}, minio.ObjectInfo{Size: 1, ETag: "\"a\""}, true},
+		{"Different ETag", minio.ObjectInfo{Size: 1, ETag: "a"}, minio.ObjectInfo{Size: 1, ETag: "b"}, false},
+		{"Different size", minio.ObjectInfo{Size: 1, ETag: "a"}, minio.ObjectInfo{Size: 2, ETag: "a"}, false},
+		{"Multipart ETag ignored", minio.ObjectInfo{Size: 1, ETag: "a-2"}, minio.ObjectInfo{Size: 1, ETag: "b"}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			assert.Equal(t, c.expected, Same(c.prime, c.alter))
+		})
+	}
+}
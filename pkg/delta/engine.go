@@ -0,0 +1,425 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"storj.io/ditto/pkg/checkpoint"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/filter"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/replication"
+)
+
+const DefaultInterval = time.Hour
+
+// ErrRunning is returned when sync is requested while another sync of the engine is in progress.
+var ErrRunning = errors.New("sync is already running")
+
+// checkpointEvery is amount of compared keys after which progress is checkpointed.
+const checkpointEvery = 1000
+
+// Stats describes single sync run.
+type Stats struct {
+	Missing int `json:"missing"`
+	Changed int `json:"changed"`
+	Extra   int `json:"extra"`
+	Copied  int `json:"copied"`
+	Deleted int `json:"deleted"`
+	Failed  int `json:"failed"`
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("missing %d, changed %d, extra %d, copied %d, deleted %d, failed %d",
+		s.Missing, s.Changed, s.Extra, s.Copied, s.Deleted, s.Failed)
+}
+
+// Engine brings alter in sync with prime by copying only objects which are missing or changed on alter.
+// Objects which exist only on alter are deleted if delete is enabled.
+type Engine struct {
+	prime, alter minio.ObjectLayer
+	handler      replication.Handler
+	logger       l.Logger
+
+	interval time.Duration
+	delete   bool
+	dryRun   bool
+
+	// checkpoint persists progress of sync, nil disables resuming.
+	checkpoint *checkpoint.Checkpoint
+
+	// filter selects objects which are synced, nil syncs all.
+	filter *filter.Filter
+
+	// mirrored selects keys which are mirrored to alter at all, nil selects every key.
+	mirrored func(object string) bool
+
+	// ready reports whether backends are expected to be in sync, nil means always.
+	ready func() bool
+
+	// running guards against overlapping syncs, whether started by interval, schedule or admin.
+	mu      sync.Mutex
+	running bool
+}
+
+// Creates new Engine, differences are applied by handler, nil opts creates engine with defaults.
+func NewEngine(prime, alter minio.ObjectLayer, handler replication.Handler, opts *config.SyncOptions, logger l.Logger) *Engine {
+	e := &Engine{
+		prime:    prime,
+		alter:    alter,
+		handler:  handler,
+		logger:   logger,
+		interval: DefaultInterval,
+	}
+
+	if opts == nil {
+		return e
+	}
+
+	if opts.Interval > 0 {
+		e.interval = opts.Interval
+	}
+
+	e.delete = opts.Delete
+
+	return e
+}
+
+// WithDryRun makes engine only log differences instead of applying them.
+func (e *Engine) WithDryRun(dryRun bool) *Engine {
+	e.dryRun = dryRun
+
+	return e
+}
+
+// WithCheckpoint makes interrupted sync resume after the last checkpointed key.
+// Objects which failed to sync are recorded in checkpoint and retried by next sync.
+func (e *Engine) WithCheckpoint(c *checkpoint.Checkpoint) *Engine {
+	e.checkpoint = c
+
+	return e
+}
+
+// WithFilter makes engine sync only objects selected by f. Missing and changed objects are matched
+// by their prime copy, extra objects by alter copy, so objects filtered out are neither copied nor deleted.
+func (e *Engine) WithFilter(f *filter.Filter) *Engine {
+	e.filter = f
+
+	return e
+}
+
+// WithMirrored makes engine sync only keys for which mirrored returns true, e.g. keys passing filter
+// of mirroring layer. Other keys are neither copied nor deleted.
+func (e *Engine) WithMirrored(mirrored func(object string) bool) *Engine {
+	e.mirrored = mirrored
+
+	return e
+}
+
+// WithReadyCheck makes engine skip rounds while ready returns false, e.g. while failed over to alter.
+func (e *Engine) WithReadyCheck(ready func() bool) *Engine {
+	e.ready = ready
+
+	return e
+}
+
+// Run syncs all buckets every interval until ctx is done.
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.RunOnce(ctx); err != nil {
+				e.logE(err)
+			}
+		}
+	}
+}
+
+// RunOnce syncs all buckets once and logs stats, run is skipped if ready check fails
+// or another sync is still in progress.
+func (e *Engine) RunOnce(ctx context.Context) error {
+	if e.ready != nil && !e.ready() {
+		e.log("sync: skipped, backends are not expected to be in sync")
+		return nil
+	}
+
+	stats, err := e.SyncAll(ctx)
+	if err == ErrRunning {
+		e.log("sync: skipped, previous sync is still in progress")
+		return nil
+	}
+
+	e.log("sync: " + stats.String())
+
+	return err
+}
+
+// Start runs SyncAll in background until it completes or ctx is done and logs its outcome.
+// It returns false without starting if another sync is in progress.
+func (e *Engine) Start(ctx context.Context) bool {
+	if !e.acquire() {
+		return false
+	}
+
+	go func() {
+		defer e.release()
+
+		stats, err := e.syncAll(ctx)
+		if err != nil {
+			e.logE(err)
+			return
+		}
+
+		e.log("sync: " + stats.String())
+	}()
+
+	return true
+}
+
+// IsRunning reports whether a sync is in progress.
+func (e *Engine) IsRunning() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.running
+}
+
+func (e *Engine) acquire() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return false
+	}
+
+	e.running = true
+
+	return true
+}
+
+func (e *Engine) release() {
+	e.mu.Lock()
+	e.running = false
+	e.mu.Unlock()
+}
+
+// SyncAll syncs every bucket of prime. Buckets completed by interrupted SyncAll are skipped.
+// ErrRunning is returned if another sync is in progress.
+func (e *Engine) SyncAll(ctx context.Context) (Stats, error) {
+	if !e.acquire() {
+		return Stats{}, ErrRunning
+	}
+	defer e.release()
+
+	return e.syncAll(ctx)
+}
+
+func (e *Engine) syncAll(ctx context.Context) (Stats, error) {
+	var stats Stats
+
+	buckets, err := e.prime.ListBuckets(ctx)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, b := range buckets {
+		if err := e.sync(ctx, b.Name, "", &stats); err != nil {
+			return stats, err
+		}
+	}
+
+	for _, b := range buckets {
+		if err := e.reset(b.Name, ""); err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+// Sync syncs objects of bucket under prefix. Failure to apply a difference is logged and counted,
+// sync continues with next object. Listing errors stop sync. ErrRunning is returned if another sync is in progress.
+func (e *Engine) Sync(ctx context.Context, bucket, prefix string) (Stats, error) {
+	var stats Stats
+
+	if !e.acquire() {
+		return stats, ErrRunning
+	}
+	defer e.release()
+
+	if err := e.sync(ctx, bucket, prefix, &stats); err != nil {
+		return stats, err
+	}
+
+	return stats, e.reset(bucket, prefix)
+}
+
+func (e *Engine) sync(ctx context.Context, bucket, prefix string, stats *Stats) error {
+	key := checkpointKey(bucket, prefix)
+	marker := ""
+
+	if e.checkpoint != nil {
+		var completed bool
+		var err error
+
+		if marker, completed, err = e.checkpoint.Marker(key); err != nil || completed {
+			return err
+		}
+
+		if marker != "" {
+			e.log(fmt.Sprintf("sync: resuming %s after %s", key, marker))
+		}
+
+		if err := e.retryFailed(ctx, key, stats); err != nil {
+			return err
+		}
+	}
+
+	compared := 0
+
+	err := diff(ctx, e.prime, e.alter, bucket, prefix, marker, func(c Change) error {
+		if !e.selected(c) {
+			return nil
+		}
+
+		return e.apply(ctx, key, c, stats)
+	}, func(object string) error {
+		compared++
+		if e.checkpoint == nil || compared%checkpointEvery != 0 {
+			return nil
+		}
+
+		return e.checkpoint.Save(key, object)
+	})
+
+	if err != nil || e.checkpoint == nil {
+		return err
+	}
+
+	return e.checkpoint.Complete(key)
+}
+
+// apply applies single difference, failure is recorded in checkpoint.
+func (e *Engine) apply(ctx context.Context, key string, c Change, stats *Stats) error {
+	var task replication.Task
+
+	switch c.Kind {
+	case MISSING:
+		stats.Missing++
+		task = replication.NewPutTask(c.Bucket, c.Object)
+	case CHANGED:
+		stats.Changed++
+		task = replication.NewPutTask(c.Bucket, c.Object)
+	case EXTRA:
+		stats.Extra++
+		if !e.delete {
+			return nil
+		}
+
+		task = replication.NewDeleteTask(c.Bucket, c.Object)
+	}
+
+	if e.dryRun {
+		e.log(fmt.Sprintf("sync: dry run: %s, would %s", c, task))
+		return nil
+	}
+
+	return e.handle(ctx, key, task, stats)
+}
+
+// selected reports whether change is of object selected by filter.
+func (e *Engine) selected(c Change) bool {
+	if e.mirrored != nil && !e.mirrored(c.Object) {
+		return false
+	}
+
+	if c.Kind == EXTRA {
+		return e.filter.Match(c.Alter)
+	}
+
+	return e.filter.Match(c.Prime)
+}
+
+// retryFailed retries tasks which failed in previous syncs of key.
+func (e *Engine) retryFailed(ctx context.Context, key string, stats *Stats) error {
+	if e.dryRun {
+		return nil
+	}
+
+	tasks, err := e.checkpoint.Failed(key)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := e.handle(ctx, key, task, stats); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handle applies task, failed task is logged, counted and recorded in checkpoint.
+// Returned error means checkpoint couldn't be updated.
+func (e *Engine) handle(ctx context.Context, key string, task replication.Task, stats *Stats) error {
+	if err := e.handler.Handle(ctx, task); err != nil {
+		stats.Failed++
+		e.logE(fmt.Errorf("sync: %s failed: %s", task, err))
+
+		if e.checkpoint != nil {
+			return e.checkpoint.Fail(key, task)
+		}
+
+		return nil
+	}
+
+	if task.Operation == replication.DELETE {
+		stats.Deleted++
+	} else {
+		stats.Copied++
+	}
+
+	if e.checkpoint != nil {
+		return e.checkpoint.Resolve(key, task.Object)
+	}
+
+	return nil
+}
+
+// reset forgets progress of completed sync, so next sync compares everything again.
+func (e *Engine) reset(bucket, prefix string) error {
+	if e.checkpoint == nil {
+		return nil
+	}
+
+	return e.checkpoint.Reset(checkpointKey(bucket, prefix))
+}
+
+func checkpointKey(bucket, prefix string) string {
+	return bucket + "/" + prefix
+}
+
+func (e *Engine) log(msg string) {
+	if e.logger != nil {
+		e.logger.Log(msg)
+	}
+}
+
+func (e *Engine) logE(err error) {
+	if e.logger != nil {
+		e.logger.LogE(err)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/checkpoint"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/filter"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+type handlerFunc func(ctx context.Context, task replication.Task) error
+
+func (f handlerFunc) Handle(ctx context.Context, task replication.Task) error {
+	return f(ctx, task)
+}
+
+func TestEngineSync(t *testing.T) {
+	prime := newListedLayer(10,
+		minio.ObjectInfo{Name: "changed", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "failed", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "missing", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "same", Size: 1, ETag: "1"},
+	)
+
+	alter := newListedLayer(10,
+		minio.ObjectInfo{Name: "changed", Size: 2, ETag: "1"},
+		minio.ObjectInfo{Name: "extra", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "same", Size: 1, ETag: "1"},
+	)
+
+	cases := []struct {
+		testName      string
+		opts          *config.SyncOptions
+		filter        *filter.Filter
+		dryRun        bool
+		expectedTasks []string
+		expectedStats Stats
+	}{
+		{
+			"Missing and changed objects copied",
+			&config.SyncOptions{},
+			nil,
+			false,
+			[]string{"put bucket/changed", "put bucket/failed", "put bucket/missing"},
+			Stats{Missing: 2, Changed: 1, Extra: 1, Copied: 2, Failed: 1},
+		},
+		{
+			"Extra objects deleted",
+			&config.SyncOptions{Delete: true},
+			nil,
+			false,
+			[]string{"put bucket/changed", "delete bucket/extra", "put bucket/failed", "put bucket/missing"},
+			Stats{Missing: 2, Changed: 1, Extra: 1, Copied: 2, Deleted: 1, Failed: 1},
+		},
+		{
+			"Dry run applies nothing",
+			&config.SyncOptions{Delete: true},
+			nil,
+			true,
+			nil,
+			Stats{Missing: 2, Changed: 1, Extra: 1},
+		},
+		{
+			"Filtered out objects neither copied nor deleted",
+			&config.SyncOptions{Delete: true},
+			&filter.Filter{Exclude: []string{"extra", "failed"}},
+			false,
+			[]string{"put bucket/changed", "put bucket/missing"},
+			Stats{Missing: 1, Changed: 1, Copied: 2},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			var tasks []string
+
+			handler := handlerFunc(func(ctx context.Context, task replication.Task) error {
+				tasks = append(tasks, task.String())
+
+				if task.Object == "failed" {
+					return errors.New("failed")
+				}
+
+				return nil
+			})
+
+			logger := &test.MockLogger{}
+
+			e := NewEngine(prime, alter, handler, c.opts, logger).WithDryRun(c.dryRun).WithFilter(c.filter)
+
+			stats, err := e.Sync(context.Background(), "bucket", "")
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedTasks, tasks)
+			assert.Equal(t, c.expectedStats, stats)
+			assert.Equal(t, c.expectedStats.Failed, logger.LogECount())
+		})
+	}
+}
+
+func TestEngineSyncResumed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delta-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cp, err := checkpoint.Open(filepath.Join(dir, "sync.db"))
+	assert.NoError(t, err)
+	defer cp.Close()
+
+	// interrupted sync compared keys up to b, copy of a failed
+	assert.NoError(t, cp.Save("bucket/", "b"))
+	assert.NoError(t, cp.Fail("bucket/", replication.NewPutTask("bucket", "a")))
+
+	prime := newListedLayer(10,
+		minio.ObjectInfo{Name: "a", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "b", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "c", Size: 1, ETag: "1"},
+	)
+
+	alter := newListedLayer(10)
+
+	var tasks []string
+
+	handler := handlerFunc(func(ctx context.Context, task replication.Task) error {
+		tasks = append(tasks, task.String())
+		return nil
+	})
+
+	e := NewEngine(prime, alter, handler, nil, nil).WithCheckpoint(cp)
+
+	stats, err := e.Sync(context.Background(), "bucket", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"put bucket/a", "put bucket/c"}, tasks)
+	assert.Equal(t, Stats{Missing: 1, Copied: 2}, stats)
+
+	failed, err := cp.Failed("bucket/")
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+
+	// completed sync starts from scratch next time
+	marker, completed, err := cp.Marker("bucket/")
+	assert.NoError(t, err)
+	assert.Equal(t, "", marker)
+	assert.False(t, completed)
+}
+
+func TestEngineSyncMirrored(t *testing.T) {
+	prime := newListedLayer(10,
+		minio.ObjectInfo{Name: "logs/missing", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "missing", Size: 1, ETag: "1"},
+	)
+
+	alter := newListedLayer(10,
+		minio.ObjectInfo{Name: ".orphans/extra", Size: 1, ETag: "1"},
+		minio.ObjectInfo{Name: "extra", Size: 1, ETag: "1"},
+	)
+
+	var tasks []string
+
+	handler := handlerFunc(func(ctx context.Context, task replication.Task) error {
+		tasks = append(tasks, task.String())
+		return nil
+	})
+
+	e := NewEngine(prime, alter, handler, &config.SyncOptions{Delete: true}, nil)
+	e.WithMirrored(func(object string) bool {
+		return !strings.HasPrefix(object, "logs/") && !strings.HasPrefix(object, ".orphans/")
+	})
+
+	stats, err := e.Sync(context.Background(), "bucket", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"delete bucket/extra", "put bucket/missing"}, tasks)
+	assert.Equal(t, Stats{Missing: 1, Extra: 1, Copied: 1, Deleted: 1}, stats)
+}
+
+func TestEngineSyncOverlapRefused(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	prime.ListObjectsFunc = newListedLayer(10, minio.ObjectInfo{Name: "missing", Size: 1, ETag: "1"}).ListObjects
+	prime.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		return []minio.BucketInfo{{Name: "bucket"}}, nil
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+
+	handler := handlerFunc(func(ctx context.Context, task replication.Task) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	e := NewEngine(prime, newListedLayer(10), handler, nil, nil)
+
+	assert.True(t, e.Start(context.Background()))
+	<-started
+
+	// syncs started by admin, schedule or interval don't overlap
+	assert.True(t, e.IsRunning())
+	assert.False(t, e.Start(context.Background()))
+
+	_, err := e.SyncAll(context.Background())
+	assert.Equal(t, ErrRunning, err)
+
+	_, err = e.Sync(context.Background(), "bucket", "")
+	assert.Equal(t, ErrRunning, err)
+
+	assert.NoError(t, e.RunOnce(context.Background()))
+
+	close(release)
+
+	// completed sync releases engine
+	for e.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package delta
+
+import (
+	"context"
+	"fmt"
+
+	"storj.io/ditto/pkg/replication"
+)
+
+// Policies of repair, see config.RepairOptions.
+const (
+	PrimeWins = "prime"
+	AlterWins = "alter"
+	Merge     = "merge"
+)
+
+// Repair actions applied to an object.
+const (
+	CopyToAlter     = "copy to alter"
+	CopyToPrime     = "copy to prime"
+	DeleteFromAlter = "delete from alter"
+	DeleteFromPrime = "delete from prime"
+)
+
+// Result is repair of single object, Error is set if it failed.
+// Bytes is size of the copy which is replicated or deleted.
+type Result struct {
+	Key    string `json:"key"`
+	Action string `json:"action"`
+	Bytes  int64  `json:"bytes"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RepairStats counts objects processed by Repair.
+type RepairStats struct {
+	Repaired int
+	Failed   int
+}
+
+func (s RepairStats) String() string {
+	return fmt.Sprintf("repaired %d, failed %d", s.Repaired, s.Failed)
+}
+
+// Repairer resolves differences listed in report according to policy. Objects are replicated from prime
+// to alter by toAlter and from alter to prime by toPrime, which means its prime is alter.
+type Repairer struct {
+	policy           string
+	toAlter, toPrime replication.Handler
+	dryRun           bool
+}
+
+// NewRepairer creates repairer, empty policy is Merge.
+func NewRepairer(policy string, toAlter, toPrime replication.Handler) (*Repairer, error) {
+	switch policy {
+	case "":
+		policy = Merge
+	case PrimeWins, AlterWins, Merge:
+	default:
+		return nil, fmt.Errorf("repair policy %q is unknown, expected one of %s, %s, %s", policy, PrimeWins, AlterWins, Merge)
+	}
+
+	return &Repairer{policy: policy, toAlter: toAlter, toPrime: toPrime}, nil
+}
+
+// WithDryRun makes repairer only report actions instead of applying them.
+func (r *Repairer) WithDryRun(dryRun bool) *Repairer {
+	r.dryRun = dryRun
+
+	return r
+}
+
+// Repair applies action of every difference of report and passes its result to fn.
+// Objects which failed to be verified are skipped. Failed action doesn't stop repair.
+func (r *Repairer) Repair(ctx context.Context, report *Report, fn func(Result)) (RepairStats, error) {
+	var stats RepairStats
+
+	for _, section := range []struct {
+		entries []Entry
+		action  string
+	}{
+		{report.OnlyPrime, r.pick(CopyToAlter, DeleteFromPrime, CopyToAlter)},
+		{report.OnlyAlter, r.pick(DeleteFromAlter, CopyToPrime, CopyToPrime)},
+		{report.Differ, r.pick(CopyToAlter, CopyToPrime, CopyToAlter)},
+	} {
+		for _, e := range section.entries {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+
+			result := Result{Key: e.Key, Action: section.action, Bytes: actionBytes(e, section.action)}
+
+			if !r.dryRun {
+				if err := r.apply(ctx, report.Bucket, result); err != nil {
+					result.Error = err.Error()
+					stats.Failed++
+				} else {
+					stats.Repaired++
+				}
+			}
+
+			fn(result)
+		}
+	}
+
+	return stats, nil
+}
+
+// pick returns action of policy, actions are given in order of PrimeWins, AlterWins and Merge.
+func (r *Repairer) pick(prime, alter, merge string) string {
+	switch r.policy {
+	case PrimeWins:
+		return prime
+	case AlterWins:
+		return alter
+	default:
+		return merge
+	}
+}
+
+// actionBytes returns size of copy of entry which action replicates or deletes.
+func actionBytes(e Entry, action string) int64 {
+	c := e.Alter
+	if action == CopyToAlter || action == DeleteFromPrime {
+		c = e.Prime
+	}
+
+	if c == nil {
+		return 0
+	}
+
+	return c.Size
+}
+
+func (r *Repairer) apply(ctx context.Context, bucket string, result Result) error {
+	switch result.Action {
+	case CopyToAlter:
+		return r.toAlter.Handle(ctx, replication.NewPutTask(bucket, result.Key))
+	case CopyToPrime:
+		return r.toPrime.Handle(ctx, replication.NewPutTask(bucket, result.Key))
+	case DeleteFromAlter:
+		return r.toAlter.Handle(ctx, replication.NewDeleteTask(bucket, result.Key))
+	default:
+		return r.toPrime.Handle(ctx, replication.NewDeleteTask(bucket, result.Key))
+	}
+}
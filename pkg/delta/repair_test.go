@@ -0,0 +1,95 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/replication"
+)
+
+func TestRepair(t *testing.T) {
+	report := NewReport("bucket", "")
+	report.OnlyPrime = []Entry{{Key: "p", Prime: &Copy{Size: 1}}}
+	report.OnlyAlter = []Entry{{Key: "a", Alter: &Copy{Size: 1}}}
+	report.Differ = []Entry{{Key: "d", Prime: &Copy{Size: 1}, Alter: &Copy{Size: 2}}}
+	report.Failed = []Failure{{Key: "f", Error: "read failed"}}
+
+	cases := []struct {
+		policy   string
+		dryRun   bool
+		expected []string
+		tasks    []string
+	}{
+		{
+			policy:   "",
+			expected: []string{"p: copy to alter", "a: copy to prime", "d: copy to alter"},
+			tasks:    []string{"alter: put bucket/p", "prime: put bucket/a", "alter: put bucket/d"},
+		},
+		{
+			policy:   PrimeWins,
+			expected: []string{"p: copy to alter", "a: delete from alter", "d: copy to alter"},
+			tasks:    []string{"alter: put bucket/p", "alter: delete bucket/a", "alter: put bucket/d"},
+		},
+		{
+			policy:   AlterWins,
+			expected: []string{"p: delete from prime", "a: copy to prime", "d: copy to prime"},
+			tasks:    []string{"prime: delete bucket/p", "prime: put bucket/a", "prime: put bucket/d"},
+		},
+		{
+			policy:   PrimeWins,
+			dryRun:   true,
+			expected: []string{"p: copy to alter", "a: delete from alter", "d: copy to alter"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.policy, func(t *testing.T) {
+			var tasks []string
+
+			handler := func(target string) replication.Handler {
+				return handlerFunc(func(ctx context.Context, task replication.Task) error {
+					tasks = append(tasks, target+": "+task.String())
+					return nil
+				})
+			}
+
+			r, err := NewRepairer(c.policy, handler("alter"), handler("prime"))
+			assert.NoError(t, err)
+
+			var results []string
+
+			stats, err := r.WithDryRun(c.dryRun).Repair(context.Background(), report, func(r Result) {
+				results = append(results, r.Key+": "+r.Action)
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, results)
+			assert.Equal(t, c.tasks, tasks)
+			assert.Equal(t, len(c.tasks), stats.Repaired)
+		})
+	}
+
+	_, err := NewRepairer("newest", nil, nil)
+	assert.EqualError(t, err, `repair policy "newest" is unknown, expected one of prime, alter, merge`)
+
+	failing := handlerFunc(func(ctx context.Context, task replication.Task) error {
+		return errors.New("access denied")
+	})
+
+	r, err := NewRepairer(Merge, failing, failing)
+	assert.NoError(t, err)
+
+	var failed []Result
+
+	stats, err := r.Repair(context.Background(), report, func(r Result) { failed = append(failed, r) })
+
+	assert.NoError(t, err)
+	assert.Equal(t, RepairStats{Failed: 3}, stats)
+	assert.Equal(t, Result{Key: "p", Action: CopyToAlter, Bytes: 1, Error: "access denied"}, failed[0])
+	assert.Equal(t, Result{Key: "a", Action: CopyToPrime, Bytes: 1, Error: "access denied"}, failed[1])
+}
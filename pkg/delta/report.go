@@ -0,0 +1,94 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package delta
+
+import (
+	"fmt"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+// Copy is size and ETag of copy of an object on one backend, SHA256 of its content is set if it was verified.
+type Copy struct {
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+func newCopy(oi minio.ObjectInfo) *Copy {
+	return &Copy{Size: oi.Size, ETag: normalizeETag(oi.ETag)}
+}
+
+// Entry is an object which differs between backends. Prime or Alter is nil if object doesn't exist on that backend.
+type Entry struct {
+	Key   string `json:"key"`
+	Prime *Copy  `json:"prime,omitempty"`
+	Alter *Copy  `json:"alter,omitempty"`
+}
+
+// Reason describes how copies of entry differ.
+func (e Entry) Reason() string {
+	switch {
+	case e.Alter == nil:
+		return "only on prime"
+	case e.Prime == nil:
+		return "only on alter"
+	case e.Prime.Size != e.Alter.Size:
+		return fmt.Sprintf("size %d != %d", e.Prime.Size, e.Alter.Size)
+	case e.Prime.SHA256 != e.Alter.SHA256:
+		return fmt.Sprintf("content sha256 %s != %s", e.Prime.SHA256, e.Alter.SHA256)
+	default:
+		return fmt.Sprintf("etag %s != %s", e.Prime.ETag, e.Alter.ETag)
+	}
+}
+
+// Failure is an object which couldn't be verified.
+type Failure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// Report collects differences of bucket between backends, field names of its JSON form are stable.
+// Failed lists objects Verify couldn't read.
+type Report struct {
+	Bucket    string    `json:"bucket"`
+	Prefix    string    `json:"prefix"`
+	OnlyPrime []Entry   `json:"onlyPrime"`
+	OnlyAlter []Entry   `json:"onlyAlter"`
+	Differ    []Entry   `json:"differ"`
+	Failed    []Failure `json:"failed,omitempty"`
+}
+
+// NewReport creates empty report of bucket under prefix.
+func NewReport(bucket, prefix string) *Report {
+	return &Report{Bucket: bucket, Prefix: prefix, OnlyPrime: []Entry{}, OnlyAlter: []Entry{}, Differ: []Entry{}}
+}
+
+// Add adds change to report, it's Diff callback.
+func (r *Report) Add(c Change) error {
+	switch c.Kind {
+	case MISSING:
+		r.OnlyPrime = append(r.OnlyPrime, Entry{Key: c.Object, Prime: newCopy(c.Prime)})
+	case EXTRA:
+		r.OnlyAlter = append(r.OnlyAlter, Entry{Key: c.Object, Alter: newCopy(c.Alter)})
+	case CHANGED:
+		r.Differ = append(r.Differ, Entry{Key: c.Object, Prime: newCopy(c.Prime), Alter: newCopy(c.Alter)})
+	}
+
+	return nil
+}
+
+// Len returns number of differences in report.
+func (r *Report) Len() int {
+	return len(r.OnlyPrime) + len(r.OnlyAlter) + len(r.Differ)
+}
+
+func (r *Report) String() string {
+	s := fmt.Sprintf("%d only on prime, %d only on alter, %d differ", len(r.OnlyPrime), len(r.OnlyAlter), len(r.Differ))
+	if len(r.Failed) > 0 {
+		s += fmt.Sprintf(", %d failed", len(r.Failed))
+	}
+
+	return s
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package delta
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+// VerifyStats counts objects processed by Verify.
+type VerifyStats struct {
+	Verified int
+	Bytes    int64
+	Skipped  int
+}
+
+// Verify lists objects of bucket under prefix on both backends, adds differences of listings to report
+// and compares content of objects whose listings match. Only objects for which sampled returns true
+// are compared, nil sampled compares all of them. Content is streamed from both backends and hashed,
+// objects whose hashes differ are reported as differing, objects which can't be read as failed.
+func Verify(ctx context.Context, prime, alter minio.ObjectLayer, bucket, prefix string, sampled func(key string) bool, report *Report) (VerifyStats, error) {
+	var stats VerifyStats
+
+	err := walk(ctx, prime, alter, bucket, prefix, "", func(key string, poi, aoi *minio.ObjectInfo) error {
+		switch {
+		case aoi == nil:
+			return report.Add(Change{Kind: MISSING, Bucket: bucket, Object: key, Prime: *poi})
+		case poi == nil:
+			return report.Add(Change{Kind: EXTRA, Bucket: bucket, Object: key, Alter: *aoi})
+		case !Same(*poi, *aoi):
+			return report.Add(Change{Kind: CHANGED, Bucket: bucket, Object: key, Prime: *poi, Alter: *aoi})
+		}
+
+		if sampled != nil && !sampled(key) {
+			stats.Skipped++
+			return nil
+		}
+
+		phash, err := hashObject(ctx, prime, bucket, *poi)
+		if err != nil {
+			report.Failed = append(report.Failed, Failure{Key: key, Error: "prime: " + err.Error()})
+			return nil
+		}
+
+		ahash, err := hashObject(ctx, alter, bucket, *aoi)
+		if err != nil {
+			report.Failed = append(report.Failed, Failure{Key: key, Error: "alter: " + err.Error()})
+			return nil
+		}
+
+		stats.Verified++
+		stats.Bytes += poi.Size
+
+		if phash != ahash {
+			p, a := newCopy(*poi), newCopy(*aoi)
+			p.SHA256, a.SHA256 = phash, ahash
+
+			report.Differ = append(report.Differ, Entry{Key: key, Prime: p, Alter: a})
+		}
+
+		return nil
+	})
+
+	return stats, err
+}
+
+// hashObject streams object from ol and returns hex encoded SHA256 of its content.
+func hashObject(ctx context.Context, ol minio.ObjectLayer, bucket string, oi minio.ObjectInfo) (string, error) {
+	h := sha256.New()
+
+	if err := ol.GetObject(ctx, bucket, oi.Name, 0, oi.Size, h, "", minio.ObjectOptions{}); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package errclass classifies errors returned by backends, so callers can decide whether
+// retrying makes sense, whether backend is down, or whether operator has to fix configuration.
+package errclass
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+
+	miniogo "github.com/minio/minio-go"
+	minio "github.com/minio/minio/cmd"
+	"github.com/pkg/errors"
+	"storj.io/ditto/pkg/breaker"
+)
+
+// Class of an error.
+type Class int
+
+const (
+	// UNKNOWN errors are treated as retryable, as before they were classified.
+	UNKNOWN Class = iota
+	// TRANSIENT errors, e.g. network failures, timeouts or throttling, may succeed on retry.
+	TRANSIENT
+	// CONFIG errors, e.g. missing bucket or denied access, persist until configuration is fixed.
+	CONFIG
+	// PERMANENT errors, e.g. missing object or failed precondition, won't change on retry.
+	PERMANENT
+)
+
+func (c Class) String() string {
+	switch c {
+	case TRANSIENT:
+		return "transient"
+	case CONFIG:
+		return "config"
+	case PERMANENT:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// S3 error codes of backend responses by class, codes not listed are classified by status code.
+var codes = map[string]Class{
+	"InternalError":      TRANSIENT,
+	"ServiceUnavailable": TRANSIENT,
+	"SlowDown":           TRANSIENT,
+	"RequestTimeout":     TRANSIENT,
+
+	"AccessDenied":                 CONFIG,
+	"AllAccessDisabled":            CONFIG,
+	"AuthorizationHeaderMalformed": CONFIG,
+	"InvalidAccessKeyId":           CONFIG,
+	"NoSuchBucket":                 CONFIG,
+	"RequestTimeTooSkewed":         CONFIG,
+	"SignatureDoesNotMatch":        CONFIG,
+}
+
+// Classify returns class of err, nil err is UNKNOWN.
+func Classify(err error) Class {
+	if err == nil {
+		return UNKNOWN
+	}
+
+	if IsConnectionError(err) {
+		return TRANSIENT
+	}
+
+	switch e := errors.Cause(err).(type) {
+	case minio.BucketNotFound, minio.PrefixAccessDenied:
+		return CONFIG
+	case minio.ObjectNotFound, minio.ObjectNameInvalid, minio.BucketNameInvalid, minio.BucketExists,
+		minio.BucketNotEmpty, minio.InvalidRange, minio.PreConditionFailed, minio.NotImplemented,
+		minio.BadDigest, minio.IncompleteBody, minio.StorageFull:
+		return PERMANENT
+	case miniogo.ErrorResponse:
+		return classifyResponse(e)
+	case *miniogo.ErrorResponse:
+		return classifyResponse(*e)
+	}
+
+	if errors.Cause(err) == context.Canceled {
+		return PERMANENT
+	}
+
+	return UNKNOWN
+}
+
+func classifyResponse(e miniogo.ErrorResponse) Class {
+	if c, ok := codes[e.Code]; ok {
+		return c
+	}
+
+	switch {
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return CONFIG
+	case e.StatusCode == http.StatusTooManyRequests, e.StatusCode >= 500:
+		return TRANSIENT
+	case e.StatusCode >= 400:
+		return PERMANENT
+	}
+
+	return UNKNOWN
+}
+
+// IsTransient returns true if err means that backend is unavailable at the moment.
+func IsTransient(err error) bool {
+	return Classify(err) == TRANSIENT
+}
+
+// IsRetryable returns true if retrying operation failed with err may succeed, i.e. unless err is
+// known to be permanent or caused by configuration.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	c := Classify(err)
+
+	return c == TRANSIENT || c == UNKNOWN
+}
+
+// IsPermanent returns true if err is known not to change on retry. Errors caused by configuration
+// aren't permanent, as they go away once configuration is fixed.
+func IsPermanent(err error) bool {
+	return Classify(err) == PERMANENT
+}
+
+// IsConnectionError returns true if err means that backend could not be reached.
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch e := errors.Cause(err).(type) {
+	case minio.OperationTimedOut:
+		return true
+	case *url.Error:
+		return IsConnectionError(e.Err)
+	case *net.OpError, *net.DNSError:
+		return true
+	case syscall.Errno:
+		return e == syscall.ECONNREFUSED || e == syscall.ECONNRESET || e == syscall.EHOSTUNREACH
+	case net.Error:
+		return e.Timeout()
+	}
+
+	cause := errors.Cause(err)
+
+	return cause == context.DeadlineExceeded || cause == breaker.ErrCircuitOpen
+}
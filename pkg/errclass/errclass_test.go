@@ -0,0 +1,52 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package errclass
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	miniogo "github.com/minio/minio-go"
+	minio "github.com/minio/minio/cmd"
+	perrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/breaker"
+)
+
+func TestClassify(t *testing.T) {
+	connErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	cases := []struct {
+		testName string
+		err      error
+		class    Class
+	}{
+		{"nil", nil, UNKNOWN},
+		{"unrecognized", errors.New("test error"), UNKNOWN},
+		{"connection refused", connErr, TRANSIENT},
+		{"wrapped connection error", perrors.Wrap(connErr, "put"), TRANSIENT},
+		{"deadline exceeded", context.DeadlineExceeded, TRANSIENT},
+		{"circuit open", breaker.ErrCircuitOpen, TRANSIENT},
+		{"operation timed out", minio.OperationTimedOut{}, TRANSIENT},
+		{"slow down", miniogo.ErrorResponse{Code: "SlowDown", StatusCode: 503}, TRANSIENT},
+		{"server error", miniogo.ErrorResponse{Code: "Unexpected", StatusCode: 502}, TRANSIENT},
+		{"missing bucket", minio.BucketNotFound{Bucket: "bucket"}, CONFIG},
+		{"access denied", miniogo.ErrorResponse{Code: "AccessDenied", StatusCode: 403}, CONFIG},
+		{"forbidden", miniogo.ErrorResponse{StatusCode: 403}, CONFIG},
+		{"missing object", minio.ObjectNotFound{Bucket: "bucket", Object: "object"}, PERMANENT},
+		{"bad request", &miniogo.ErrorResponse{Code: "InvalidArgument", StatusCode: 400}, PERMANENT},
+		{"canceled", context.Canceled, PERMANENT},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			assert.Equal(t, c.class, Classify(c.err))
+			assert.Equal(t, c.err != nil && (c.class == TRANSIENT || c.class == UNKNOWN), IsRetryable(c.err))
+			assert.Equal(t, c.class == PERMANENT, IsPermanent(c.err))
+			assert.Equal(t, c.class == TRANSIENT, IsTransient(c.err))
+		})
+	}
+}
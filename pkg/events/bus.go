@@ -0,0 +1,84 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	l "storj.io/ditto/pkg/logger"
+)
+
+const bufferSize = 1000
+
+// Publisher sends encoded event to topic of a message broker.
+type Publisher interface {
+	Publish(topic, key string, data []byte) error
+	Close() error
+}
+
+// Bus publishes events in background, so brokers never slow down S3 requests.
+// Events are dropped if publishing falls too far behind, failures are only logged.
+type Bus struct {
+	publisher Publisher
+	topic     string
+	logger    l.Logger
+
+	events chan Event
+	wg     sync.WaitGroup
+}
+
+// Creates new Bus publishing events to topic and starts publishing.
+func NewBus(publisher Publisher, topic string, logger l.Logger) *Bus {
+	b := &Bus{
+		publisher: publisher,
+		topic:     topic,
+		logger:    logger,
+		events:    make(chan Event, bufferSize),
+	}
+
+	b.wg.Add(1)
+	go b.publish()
+
+	return b
+}
+
+// Emit schedules event for publishing without blocking.
+func (b *Bus) Emit(e Event) {
+	select {
+	case b.events <- e:
+	default:
+		b.logE(fmt.Errorf("events: %s %s/%s dropped, publishing is behind", e.Operation, e.Bucket, e.Object))
+	}
+}
+
+// Close publishes pending events and closes publisher.
+func (b *Bus) Close() error {
+	close(b.events)
+	b.wg.Wait()
+
+	return b.publisher.Close()
+}
+
+func (b *Bus) publish() {
+	defer b.wg.Done()
+
+	for e := range b.events {
+		data, err := json.Marshal(e)
+		if err == nil {
+			err = b.publisher.Publish(b.topic, e.Bucket+"/"+e.Object, data)
+		}
+
+		if err != nil {
+			b.logE(fmt.Errorf("events: unable to publish %s %s/%s: %s", e.Operation, e.Bucket, e.Object, err))
+		}
+	}
+}
+
+func (b *Bus) logE(err error) {
+	if b.logger != nil {
+		b.logger.LogE(err)
+	}
+}
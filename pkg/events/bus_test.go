@@ -0,0 +1,84 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+type published struct {
+	topic, key string
+	data       []byte
+}
+
+type recordingPublisher struct {
+	err       error
+	published []published
+	closed    bool
+}
+
+func (p *recordingPublisher) Publish(topic, key string, data []byte) error {
+	p.published = append(p.published, published{topic, key, data})
+	return p.err
+}
+
+func (p *recordingPublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestBus(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Events are published as JSON",
+			testFunc: func(t *testing.T) {
+				p := &recordingPublisher{}
+				b := NewBus(p, "topic", &test.MockLogger{})
+
+				b.Emit(Event{Operation: replication.PUT, Bucket: "bucket", Object: "object", Prime: Result(nil), Alter: Result(errors.New("test error"))})
+				assert.NoError(t, b.Close())
+
+				assert.True(t, p.closed)
+				assert.Equal(t, 1, len(p.published))
+				assert.Equal(t, "topic", p.published[0].topic)
+				assert.Equal(t, "bucket/object", p.published[0].key)
+
+				var e Event
+				assert.NoError(t, json.Unmarshal(p.published[0].data, &e))
+				assert.Equal(t, replication.PUT, e.Operation)
+				assert.Equal(t, OK, e.Prime.Status)
+				assert.Equal(t, FAILED, e.Alter.Status)
+				assert.Equal(t, "test error", e.Alter.Error)
+			},
+		},
+		{
+			testName: "Failed publishing is logged",
+			testFunc: func(t *testing.T) {
+				logger := &test.MockLogger{}
+				p := &recordingPublisher{err: errors.New("test error")}
+				b := NewBus(p, "topic", logger)
+
+				b.Emit(Event{Operation: replication.DELETE, Bucket: "bucket", Object: "object"})
+				b.Emit(Event{Operation: replication.DELETE, Bucket: "bucket", Object: "object"})
+				assert.NoError(t, b.Close())
+
+				assert.Equal(t, 2, len(p.published))
+				assert.Equal(t, 2, logger.LogECount())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
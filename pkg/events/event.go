@@ -0,0 +1,53 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"time"
+
+	"storj.io/ditto/pkg/replication"
+)
+
+// Outcome statuses of an operation on a backend.
+const (
+	OK       = "ok"
+	FAILED   = "failed"
+	DEFERRED = "deferred"
+	SKIPPED  = "skipped"
+)
+
+// Outcome is a result of an operation on a single backend.
+// Deferred operations are scheduled for background replication, skipped ones are not executed,
+// e.g. for objects which are not mirrored.
+type Outcome struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Result returns OK outcome for nil err, FAILED otherwise.
+func Result(err error) Outcome {
+	if err != nil {
+		return Outcome{Status: FAILED, Error: err.Error()}
+	}
+
+	return Outcome{Status: OK}
+}
+
+var (
+	Deferred = Outcome{Status: DEFERRED}
+	Skipped  = Outcome{Status: SKIPPED}
+)
+
+// Event describes mirrored operation and its outcome on both backends.
+// SrcBucket and SrcObject are set only for copy operation.
+type Event struct {
+	Time      time.Time             `json:"time"`
+	Operation replication.Operation `json:"operation"`
+	Bucket    string                `json:"bucket"`
+	Object    string                `json:"object"`
+	SrcBucket string                `json:"srcBucket,omitempty"`
+	SrcObject string                `json:"srcObject,omitempty"`
+	Prime     Outcome               `json:"prime"`
+	Alter     Outcome               `json:"alter"`
+}
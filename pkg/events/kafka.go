@@ -0,0 +1,65 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const kafkaTimeout = 10 * time.Second
+
+// KafkaRESTPublisher publishes events to Kafka topics through Confluent REST Proxy,
+// so no native Kafka client is required. Object key is used as message key,
+// so events of an object stay ordered within a partition.
+type KafkaRESTPublisher struct {
+	baseURL string
+	client  *http.Client
+}
+
+// Creates new KafkaRESTPublisher of REST Proxy at baseURL, e.g. "http://localhost:8082".
+func NewKafkaRESTPublisher(baseURL string) *KafkaRESTPublisher {
+	return &KafkaRESTPublisher{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: kafkaTimeout},
+	}
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+// Publish produces single record with data as JSON value.
+func (k *KafkaRESTPublisher) Publish(topic, key string, data []byte) error {
+	body, err := json.Marshal(kafkaRecords{Records: []kafkaRecord{{Key: key, Value: data}}})
+	if err != nil {
+		return err
+	}
+
+	resp, err := k.client.Post(k.baseURL+"/topics/"+topic, "application/vnd.kafka.json.v2+json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("kafka rest proxy responded %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Close is a no-op, REST Proxy connections are managed by HTTP client.
+func (k *KafkaRESTPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafkaRESTPublisher(t *testing.T) {
+	cases := []struct {
+		testName    string
+		status      int
+		expectedErr bool
+	}{
+		{"Record produced", http.StatusOK, false},
+		{"Proxy error returned", http.StatusNotFound, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			var path, contentType string
+			var records kafkaRecords
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				contentType = r.Header.Get("Content-Type")
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&records))
+
+				w.WriteHeader(c.status)
+			}))
+			defer srv.Close()
+
+			k := NewKafkaRESTPublisher(srv.URL + "/")
+
+			err := k.Publish("ditto.events", "bucket/object", []byte(`{"bucket":"bucket"}`))
+
+			assert.Equal(t, c.expectedErr, err != nil)
+			assert.Equal(t, "/topics/ditto.events", path)
+			assert.Equal(t, "application/vnd.kafka.json.v2+json", contentType)
+			assert.Equal(t, 1, len(records.Records))
+			assert.Equal(t, "bucket/object", records.Records[0].Key)
+			assert.JSONEq(t, `{"bucket":"bucket"}`, string(records.Records[0].Value))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	natsDialTimeout  = 5 * time.Second
+	natsReplyTimeout = 5 * time.Second
+)
+
+var errNATSDisconnected = errors.New("nats: connection closed before reply")
+
+// NATSPublisher publishes events to NATS subjects using core NATS text protocol.
+// Connection is established lazily and re-established after failure. Connection is verbose,
+// so every publish waits until server acknowledges it with +OK or rejects it with -ERR.
+type NATSPublisher struct {
+	address string
+
+	// mu serializes publishes, so replies of server are matched with them in order
+	mu      sync.Mutex
+	conn    net.Conn
+	replies chan error
+
+	// wmu serializes writes of publishes and answers to server PINGs
+	wmu sync.Mutex
+}
+
+// Creates new NATSPublisher of server at address, e.g. "localhost:4222".
+func NewNATSPublisher(address string) *NATSPublisher {
+	return &NATSPublisher{address: strings.TrimPrefix(address, "nats://")}
+}
+
+// Publish publishes data to subject topic. NATS messages have no key, key is ignored.
+func (n *NATSPublisher) Publish(topic, key string, data []byte) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	msg := append([]byte(fmt.Sprintf("PUB %s %d\r\n", topic, len(data))), data...)
+	msg = append(msg, '\r', '\n')
+
+	// single reconnect covers connection closed by server since last publish
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if n.conn == nil {
+			if err = n.connect(); err != nil {
+				return err
+			}
+		}
+
+		if err = n.send(msg); err == nil {
+			return nil
+		}
+
+		n.disconnect()
+
+		// message rejected by server isn't sent again
+		if err != errNATSDisconnected && !isNetError(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// send writes msg and waits for reply of server.
+func (n *NATSPublisher) send(msg []byte) error {
+	n.wmu.Lock()
+	_, err := n.conn.Write(msg)
+	n.wmu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err, ok := <-n.replies:
+		if !ok {
+			return errNATSDisconnected
+		}
+
+		return err
+	case <-time.After(natsReplyTimeout):
+		return errors.New("nats: server didn't acknowledge message")
+	}
+}
+
+// connect dials server, reads its INFO, sends CONNECT and waits until server accepts it.
+// Server PINGs and replies are read in background.
+func (n *NATSPublisher) connect() error {
+	conn, err := net.DialTimeout("tcp", n.address, natsDialTimeout)
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+
+	conn.SetReadDeadline(time.Now().Add(natsDialTimeout))
+
+	info, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	if !strings.HasPrefix(info, "INFO") {
+		conn.Close()
+		return errors.New("nats: unexpected greeting " + strings.TrimSpace(info))
+	}
+
+	conn.SetReadDeadline(time.Time{})
+
+	if _, err := conn.Write([]byte("CONNECT {\"verbose\":true,\"pedantic\":false,\"name\":\"ditto\"}\r\n")); err != nil {
+		conn.Close()
+		return err
+	}
+
+	n.conn = conn
+	n.replies = make(chan error, 1)
+
+	go n.serve(conn, r, n.replies)
+
+	select {
+	case err, ok := <-n.replies:
+		if !ok {
+			err = errNATSDisconnected
+		}
+
+		if err != nil {
+			n.disconnect()
+		}
+
+		return err
+	case <-time.After(natsReplyTimeout):
+		n.disconnect()
+		return errors.New("nats: server didn't accept connection")
+	}
+}
+
+// serve answers server PINGs and passes replies of server to replies until connection is closed.
+func (n *NATSPublisher) serve(conn net.Conn, r *bufio.Reader, replies chan<- error) {
+	defer close(replies)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(line, "PING"):
+			n.wmu.Lock()
+			_, err = conn.Write([]byte("PONG\r\n"))
+			n.wmu.Unlock()
+
+			if err != nil {
+				return
+			}
+		case strings.HasPrefix(line, "+OK"):
+			replies <- nil
+		case strings.HasPrefix(line, "-ERR"):
+			replies <- errors.New("nats: " + strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		}
+	}
+}
+
+// disconnect closes connection, its reader stops once it's closed.
+func (n *NATSPublisher) disconnect() error {
+	if n.conn == nil {
+		return nil
+	}
+
+	err := n.conn.Close()
+	n.conn = nil
+
+	return err
+}
+
+// Close closes connection to server.
+func (n *NATSPublisher) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.disconnect()
+}
+
+func isNetError(err error) bool {
+	_, ok := err.(net.Error)
+	return ok
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serveNATS accepts single connection, greets it and sends received protocol lines to lines.
+// CONNECT is acknowledged, published messages are answered with pubReply.
+func serveNATS(t *testing.T, ln net.Listener, lines chan<- string, pubReply string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	_, err = io.WriteString(conn, "INFO {\"server_id\":\"test\"}\r\nPING\r\n")
+	assert.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	payload := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			close(lines)
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(line, "CONNECT"):
+			io.WriteString(conn, "+OK\r\n")
+		case strings.HasPrefix(line, "PUB"):
+			payload = true
+		case payload:
+			payload = false
+			io.WriteString(conn, pubReply+"\r\n")
+		}
+
+		lines <- strings.TrimSpace(line)
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	lines := make(chan string, 10)
+	go serveNATS(t, ln, lines, "+OK")
+
+	n := NewNATSPublisher("nats://" + ln.Addr().String())
+
+	assert.NoError(t, n.Publish("ditto.events", "bucket/object", []byte("{}")))
+
+	// PING is answered in background, so wait for both PONG and the message before closing
+	var received []string
+	for len(received) < 4 {
+		select {
+		case line := <-lines:
+			received = append(received, line)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received only %v", received)
+		}
+	}
+
+	assert.NoError(t, n.Close())
+
+	assert.True(t, strings.HasPrefix(received[0], "CONNECT "))
+	assert.Contains(t, received, "PONG")
+	assert.Contains(t, received, "PUB ditto.events 2")
+	assert.Contains(t, received, "{}")
+}
+
+func TestNATSPublisherUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	address := ln.Addr().String()
+	ln.Close()
+
+	n := NewNATSPublisher(address)
+
+	assert.Error(t, n.Publish("ditto.events", "bucket/object", []byte("{}")))
+	assert.NoError(t, n.Close())
+}
+
+func TestNATSPublisherRejected(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	lines := make(chan string, 10)
+	go serveNATS(t, ln, lines, "-ERR 'Permissions Violation for Publish to ditto.events'")
+
+	n := NewNATSPublisher(ln.Addr().String())
+
+	err = n.Publish("ditto.events", "bucket/object", []byte("{}"))
+	assert.EqualError(t, err, "nats: Permissions Violation for Publish to ditto.events")
+	assert.NoError(t, n.Close())
+}
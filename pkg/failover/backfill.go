@@ -0,0 +1,29 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package failover
+
+import (
+	"context"
+
+	"storj.io/ditto/pkg/replication"
+)
+
+// NewBackfillHandler wraps handler which replays writes accepted during failover back to prime.
+// Tasks are held until controller fails back, so they are not wasted on unreachable prime.
+func NewBackfillHandler(h replication.Handler, c *Controller) replication.Handler {
+	return &backfillHandler{h, c}
+}
+
+type backfillHandler struct {
+	h replication.Handler
+	c *Controller
+}
+
+func (b *backfillHandler) Handle(ctx context.Context, task replication.Task) error {
+	if err := b.c.WaitRecovered(ctx); err != nil {
+		return err
+	}
+
+	return b.h.Handle(ctx, task)
+}
@@ -0,0 +1,202 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package failover
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/config"
+	l "storj.io/ditto/pkg/logger"
+)
+
+const (
+	DefaultFailureThreshold  = 5
+	DefaultRecoveryThreshold = 3
+	DefaultMinDuration       = 30 * time.Second
+	DefaultProbeInterval     = 5 * time.Second
+)
+
+// Controller tracks prime health and decides whether alter should be promoted.
+// Hysteresis is provided by separate failure and recovery thresholds
+// and minimal duration of failover, so flapping prime does not cause flapping gateway.
+type Controller struct {
+	failureThreshold  int
+	recoveryThreshold int
+	minDuration       time.Duration
+	probeInterval     time.Duration
+	logger            l.Logger
+
+	mu         sync.Mutex
+	failedOver bool
+	since      time.Time
+	failures   int
+	successes  int
+	recovered  chan struct{}
+	// overridden suspends automatic failover and failback
+	overridden bool
+
+	now func() time.Time
+}
+
+// Creates new Controller, zero options are replaced with defaults.
+func NewController(opts *config.FailoverOptions, logger l.Logger) *Controller {
+	c := &Controller{
+		failureThreshold:  DefaultFailureThreshold,
+		recoveryThreshold: DefaultRecoveryThreshold,
+		minDuration:       DefaultMinDuration,
+		probeInterval:     DefaultProbeInterval,
+		logger:            logger,
+		recovered:         make(chan struct{}),
+		now:               time.Now,
+	}
+
+	close(c.recovered)
+
+	if opts == nil {
+		return c
+	}
+
+	if opts.FailureThreshold > 0 {
+		c.failureThreshold = opts.FailureThreshold
+	}
+
+	if opts.RecoveryThreshold > 0 {
+		c.recoveryThreshold = opts.RecoveryThreshold
+	}
+
+	if opts.MinDuration > 0 {
+		c.minDuration = opts.MinDuration
+	}
+
+	if opts.ProbeInterval > 0 {
+		c.probeInterval = opts.ProbeInterval
+	}
+
+	return c
+}
+
+// IsFailedOver returns true if alter is promoted to serve requests.
+func (c *Controller) IsFailedOver() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.failedOver
+}
+
+// Override fails over to alter or fails back to prime regardless of prime health.
+// Automatic failover and failback are suspended until Release.
+func (c *Controller) Override(failedOver bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.overridden = true
+	c.failures, c.successes = 0, 0
+
+	if failedOver && !c.failedOver {
+		c.failedOver = true
+		c.since = c.now()
+		c.recovered = make(chan struct{})
+		c.log("failing over to alter on operator request")
+	} else if !failedOver && c.failedOver {
+		c.failedOver = false
+		close(c.recovered)
+		c.log("failing back to prime on operator request")
+	}
+}
+
+// Release resumes automatic failover and failback after Override.
+func (c *Controller) Release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.overridden = false
+}
+
+// IsOverridden returns true if failover state is pinned by Override.
+func (c *Controller) IsOverridden() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.overridden
+}
+
+// ReportPrime records result of an operation executed against prime.
+// Only connection errors count as failures, other errors mean prime is reachable.
+func (c *Controller) ReportPrime(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.overridden {
+		return
+	}
+
+	if IsConnectionError(err) {
+		c.successes = 0
+		c.failures++
+
+		if !c.failedOver && c.failures >= c.failureThreshold {
+			c.failedOver = true
+			c.since = c.now()
+			c.recovered = make(chan struct{})
+			c.log("prime is unreachable, failing over to alter")
+		}
+
+		return
+	}
+
+	c.failures = 0
+
+	if !c.failedOver {
+		return
+	}
+
+	c.successes++
+
+	if c.successes >= c.recoveryThreshold && c.now().Sub(c.since) >= c.minDuration {
+		c.failedOver = false
+		c.successes = 0
+		close(c.recovered)
+		c.log("prime is reachable again, failing back")
+	}
+}
+
+// WaitRecovered blocks until prime is active or ctx is done.
+func (c *Controller) WaitRecovered(ctx context.Context) error {
+	c.mu.Lock()
+	recovered := c.recovered
+	c.mu.Unlock()
+
+	select {
+	case <-recovered:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run probes prime while failed over until ctx is done.
+// Probe should call prime directly, its result is reported to controller.
+func (c *Controller) Run(ctx context.Context, probe func(ctx context.Context) error) {
+	ticker := time.NewTicker(c.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if c.IsFailedOver() {
+				c.ReportPrime(probe(ctx))
+			}
+		}
+	}
+}
+
+func (c *Controller) log(msg string) {
+	if c.logger != nil {
+		c.logger.Log(msg)
+	}
+}
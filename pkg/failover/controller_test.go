@@ -0,0 +1,107 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+)
+
+var connErr = &url.Error{Op: "Get", URL: "http://prime", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+
+func TestController(t *testing.T) {
+	now := time.Now()
+
+	newController := func() *Controller {
+		c := NewController(&config.FailoverOptions{
+			Enabled:           true,
+			FailureThreshold:  2,
+			RecoveryThreshold: 2,
+			MinDuration:       time.Minute,
+		}, nil)
+		c.now = func() time.Time { return now }
+
+		return c
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Fails over after threshold of connection errors",
+			func(t *testing.T) {
+				c := newController()
+
+				c.ReportPrime(connErr)
+				assert.Equal(t, false, c.IsFailedOver())
+
+				c.ReportPrime(connErr)
+				assert.Equal(t, true, c.IsFailedOver())
+			},
+		},
+		{
+			"Non connection errors reset failures",
+			func(t *testing.T) {
+				c := newController()
+
+				c.ReportPrime(connErr)
+				c.ReportPrime(errors.New("object not found"))
+				c.ReportPrime(connErr)
+				assert.Equal(t, false, c.IsFailedOver())
+			},
+		},
+		{
+			"Fails back only after min duration and recovery threshold",
+			func(t *testing.T) {
+				c := newController()
+
+				c.ReportPrime(connErr)
+				c.ReportPrime(connErr)
+
+				c.ReportPrime(nil)
+				c.ReportPrime(nil)
+				assert.Equal(t, true, c.IsFailedOver())
+
+				now = now.Add(2 * time.Minute)
+
+				c.ReportPrime(nil)
+				assert.Equal(t, false, c.IsFailedOver())
+				assert.NoError(t, c.WaitRecovered(context.Background()))
+			},
+		},
+		{
+			"WaitRecovered blocks while failed over",
+			func(t *testing.T) {
+				c := newController()
+
+				c.ReportPrime(connErr)
+				c.ReportPrime(connErr)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				assert.Equal(t, context.Canceled, c.WaitRecovered(ctx))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	assert.Equal(t, false, IsConnectionError(nil))
+	assert.Equal(t, false, IsConnectionError(errors.New("test error")))
+	assert.Equal(t, true, IsConnectionError(connErr))
+	assert.Equal(t, true, IsConnectionError(context.DeadlineExceeded))
+}
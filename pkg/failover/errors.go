@@ -0,0 +1,11 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package failover
+
+import "storj.io/ditto/pkg/errclass"
+
+// IsConnectionError returns true if err means that backend could not be reached.
+func IsConnectionError(err error) bool {
+	return errclass.IsConnectionError(err)
+}
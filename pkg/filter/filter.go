@@ -0,0 +1,137 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package filter
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+// Filter selects objects by key, size and modification time as returned by listings.
+// Zero value, as well as nil filter, selects every object.
+type Filter struct {
+	// Include selects only keys matching any of patterns, every key if empty.
+	Include []string
+	// Exclude skips keys matching any of patterns, even if they are included.
+	Exclude []string
+
+	// MinSize and MaxSize bound size of objects, zero MaxSize is unbounded.
+	MinSize, MaxSize int64
+
+	// NewerThan and OlderThan bound modification time of objects, zero time is unbounded.
+	NewerThan, OlderThan time.Time
+}
+
+// Validate checks patterns and bounds of filter.
+func (f *Filter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("pattern %q is invalid: %s", pattern, err)
+		}
+	}
+
+	if f.MaxSize > 0 && f.MinSize > f.MaxSize {
+		return fmt.Errorf("min size %d is bigger than max size %d", f.MinSize, f.MaxSize)
+	}
+
+	if !f.NewerThan.IsZero() && !f.OlderThan.IsZero() && !f.NewerThan.Before(f.OlderThan) {
+		return fmt.Errorf("no object can be newer than %s and older than %s",
+			f.NewerThan.Format(time.RFC3339), f.OlderThan.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// Match reports whether object is selected by filter.
+func (f *Filter) Match(oi minio.ObjectInfo) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.Include) > 0 && !matchAny(f.Include, oi.Name) {
+		return false
+	}
+
+	if matchAny(f.Exclude, oi.Name) {
+		return false
+	}
+
+	if oi.Size < f.MinSize || (f.MaxSize > 0 && oi.Size > f.MaxSize) {
+		return false
+	}
+
+	if !f.NewerThan.IsZero() && !oi.ModTime.After(f.NewerThan) {
+		return false
+	}
+
+	if !f.OlderThan.IsZero() && !oi.ModTime.Before(f.OlderThan) {
+		return false
+	}
+
+	return true
+}
+
+// matchAny reports whether key matches any of patterns as by path.Match, so * and ? don't match /.
+// Pattern without / is matched against the last element of key, e.g. *.log matches logs/2018/app.log.
+func matchAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		name := key
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(key)
+		}
+
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseTime parses time bound given as age relative to now, e.g. 36h, 7d or 2w, or as date 2006-01-02
+// or time in RFC 3339 format.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+
+	age, err := parseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither age, e.g. 36h or 7d, nor date, e.g. 2018-09-30", s)
+	}
+
+	return now.Add(-age), nil
+}
+
+// parseAge parses duration which besides units of time.ParseDuration accepts days d and weeks w.
+func parseAge(s string) (time.Duration, error) {
+	days := map[string]int{"d": 1, "w": 7}
+
+	if n := len(s); n > 0 {
+		if mult, ok := days[s[n-1:]]; ok {
+			count, err := strconv.Atoi(s[:n-1])
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("age %q is invalid", s)
+			}
+
+			return time.Duration(count*mult) * 24 * time.Hour, nil
+		}
+	}
+
+	age, err := time.ParseDuration(s)
+	if err == nil && age < 0 {
+		err = fmt.Errorf("age %q is negative", s)
+	}
+
+	return age, err
+}
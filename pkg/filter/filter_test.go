@@ -0,0 +1,89 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package filter
+
+import (
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	object := func(name string, size int64, age time.Duration) minio.ObjectInfo {
+		return minio.ObjectInfo{Name: name, Size: size, ModTime: now.Add(-age)}
+	}
+
+	cases := []struct {
+		testName string
+		filter   *Filter
+		selected []string
+	}{
+		{"Nil filter selects everything", nil, []string{"logs/app.log", "logs/app.log.gz", "photos/a.jpg", "readme"}},
+		{"Pattern without / matches base name", &Filter{Include: []string{"*.log"}}, []string{"logs/app.log"}},
+		{"Pattern with / matches whole key", &Filter{Include: []string{"photos/*"}}, []string{"photos/a.jpg"}},
+		{"Exclude wins over include", &Filter{Include: []string{"logs/*"}, Exclude: []string{"*.gz"}}, []string{"logs/app.log"}},
+		{"Size bounds", &Filter{MinSize: 10, MaxSize: 100}, []string{"logs/app.log", "photos/a.jpg"}},
+		{"Time bounds", &Filter{NewerThan: now.Add(-48 * time.Hour), OlderThan: now.Add(-time.Hour)}, []string{"logs/app.log.gz"}},
+	}
+
+	objects := []minio.ObjectInfo{
+		object("logs/app.log", 10, 0),
+		object("logs/app.log.gz", 1000, 24*time.Hour),
+		object("photos/a.jpg", 100, 72*time.Hour),
+		object("readme", 1, 72*time.Hour),
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			selected := []string{}
+			for _, oi := range objects {
+				if c.filter.Match(oi) {
+					selected = append(selected, oi.Name)
+				}
+			}
+
+			assert.Equal(t, c.selected, selected)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, (&Filter{Include: []string{"*.log"}, MinSize: 1}).Validate())
+	assert.EqualError(t, (&Filter{Exclude: []string{"[a"}}).Validate(), `pattern "[a" is invalid: syntax error in pattern`)
+	assert.EqualError(t, (&Filter{MinSize: 10, MaxSize: 5}).Validate(), "min size 10 is bigger than max size 5")
+	assert.Error(t, (&Filter{NewerThan: now, OlderThan: now.Add(-time.Hour)}).Validate())
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		s string
+		t time.Time
+	}{
+		{"36h", now.Add(-36 * time.Hour)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+		{"2018-09-30", time.Date(2018, 9, 30, 0, 0, 0, 0, time.UTC)},
+		{"2018-09-30T10:00:00Z", time.Date(2018, 9, 30, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		parsed, err := ParseTime(c.s, now)
+
+		assert.NoError(t, err, c.s)
+		assert.True(t, c.t.Equal(parsed), "%s: %s", c.s, parsed)
+	}
+
+	for _, s := range []string{"", "d", "-1d", "-2h", "yesterday"} {
+		_, err := ParseTime(s, now)
+		assert.Error(t, err, s)
+	}
+}
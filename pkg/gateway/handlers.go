@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gateway
+
+import (
+	"net/http"
+	"sync/atomic"
+	_ "unsafe" // for go:linkname
+
+	minio "github.com/minio/minio/cmd"
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/trace"
+)
+
+// minioHandlers are handlers minio wraps its API router with when gateway server starts.
+// minio doesn't export them, so handlers of ditto are appended to them directly.
+//
+//go:linkname minioHandlers github.com/minio/minio/cmd.globalHandlers
+var minioHandlers []minio.HandlerFunc
+
+func init() {
+	minioHandlers = append(minioHandlers, requestHandler)
+}
+
+// requestHandler stores values of every request served by gateway in its context, before it reaches
+// minio API handlers, which pass the context to object layer.
+func requestHandler(next http.Handler) http.Handler {
+	next = dcontext.BackendHandler(next)
+	next = dcontext.PreconditionsHandler(next)
+	next = dcontext.UserHandler(next)
+	next = traceHandler(next)
+
+	return dcontext.RequestIDHandler(next)
+}
+
+// requestTracer holds *trace.Tracer of server gateway. Handlers are registered before server gateway
+// is created, so requests aren't traced until it's created with tracing enabled.
+var requestTracer atomic.Value
+
+// traceHandler traces every request with tracer of server gateway, if any.
+func traceHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tracer, _ := requestTracer.Load().(*trace.Tracer); tracer != nil {
+			trace.Handler(tracer, next).ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,197 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gateway
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/cli"
+	miniogo "github.com/minio/minio-go"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/trace"
+
+	minio "github.com/minio/minio/cmd"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// newTestServer serves requests with handler wrapped by every handler registered with minio,
+// the way minio wraps its API router.
+func newTestServer(handler func(ctx context.Context)) *httptest.Server {
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(r.Context())
+	})
+
+	for _, wrap := range minioHandlers {
+		h = wrap(h)
+	}
+
+	return httptest.NewServer(h)
+}
+
+func TestRequestHandler(t *testing.T) {
+	var ctx context.Context
+
+	srv := newTestServer(func(c context.Context) { ctx = c })
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/bucket/object", nil)
+	assert.NoError(t, err)
+	req.Header.Set(dcontext.RequestIDHeader, "request-id")
+	req.Header.Set(dcontext.BackendHeader, "alter")
+	req.Header.Set("If-Match", "\"a\", \"b\"")
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=user/20180912/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=signature")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	id, ok := dcontext.RequestIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "request-id", id)
+	assert.Equal(t, "request-id", resp.Header.Get(dcontext.RequestIDHeader))
+
+	user, ok := dcontext.UserFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "user", user)
+
+	p, ok := dcontext.PreconditionsFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "\"a\", \"b\"", p.IfMatch)
+
+	backend, ok := dcontext.BackendFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, dcontext.ALTER, backend)
+}
+
+// serveGateway serves layer by minio gateway server on random loopback port, the way cmd/server serves
+// mirroring gateway, and returns its address. Server isn't stopped, it runs until tests exit.
+func serveGateway(t *testing.T, accessKey, secretKey string, layer minio.ObjectLayer) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := ln.Addr().String()
+	ln.Close()
+
+	dir, err := ioutil.TempDir("", "ditto-gateway")
+	assert.NoError(t, err)
+
+	os.Setenv("MINIO_ACCESS_KEY", accessKey)
+	os.Setenv("MINIO_SECRET_KEY", secretKey)
+	os.Setenv("MINIO_BROWSER", "off")
+
+	err = minio.RegisterGatewayCommand(cli.Command{
+		Name: "recording",
+		Action: func(ctx *cli.Context) {
+			minio.StartGateway(ctx, &test.MockGateway{Ol: layer})
+		},
+		HideHelpCommand: true,
+	})
+	assert.NoError(t, err)
+
+	go minio.Main([]string{"ditto", "--config-dir", dir, "gateway", "recording", "--address", address})
+
+	return address
+}
+
+// TestRequestHandlerGateway checks values of requests served by minio gateway server reach object layer.
+func TestRequestHandlerGateway(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		ctx context.Context
+	)
+
+	layer := test.NewProxyObjectLayer()
+	layer.GetObjectInfoFunc = func(c context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		mu.Lock()
+		ctx = c
+		mu.Unlock()
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, ETag: "a", ModTime: time.Now()}, nil
+	}
+
+	address := serveGateway(t, "gateway-user", "gateway-secret", layer)
+
+	client, err := miniogo.New(address, "gateway-user", "gateway-secret", false)
+	assert.NoError(t, err)
+
+	opts := miniogo.StatObjectOptions{}
+	opts.Set(dcontext.RequestIDHeader, "request-id")
+	opts.Set(dcontext.BackendHeader, "alter")
+	opts.Set("If-Match", "\"a\"")
+
+	// server accepts requests before its layer is created
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(50 * time.Millisecond) {
+		if _, err = client.StatObject("bucket", "object", opts); err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !assert.NotNil(t, ctx) {
+		return
+	}
+
+	id, ok := dcontext.RequestIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "request-id", id)
+
+	user, ok := dcontext.UserFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "gateway-user", user)
+
+	p, ok := dcontext.PreconditionsFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "\"a\"", p.IfMatch)
+
+	backend, ok := dcontext.BackendFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, dcontext.ALTER, backend)
+}
+
+type exporterFunc func(span *trace.Span)
+
+func (f exporterFunc) Export(span *trace.Span) {
+	f(span)
+}
+
+func TestRequestHandlerTraces(t *testing.T) {
+	var exported []*trace.Span
+
+	requestTracer.Store(trace.NewTracer(exporterFunc(func(span *trace.Span) {
+		exported = append(exported, span)
+	}), 1))
+	defer requestTracer.Store((*trace.Tracer)(nil))
+
+	var span *trace.Span
+
+	srv := newTestServer(func(ctx context.Context) { span, _ = trace.SpanFromContext(ctx) })
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/bucket/object", nil)
+	assert.NoError(t, err)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	req.Header.Set(dcontext.RequestIDHeader, "request-id")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	if assert.NotNil(t, span) && assert.Equal(t, 1, len(exported)) {
+		assert.Equal(t, span, exported[0])
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.TraceID)
+		assert.Equal(t, "request-id", span.Tags()["request_id"])
+	}
+}
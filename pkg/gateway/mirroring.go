@@ -1,11 +1,52 @@
 package gateway
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/minio/cli"
 	"github.com/minio/minio/pkg/auth"
+	"storj.io/ditto/pkg/admin"
+	"storj.io/ditto/pkg/alert"
+	"storj.io/ditto/pkg/breaker"
+	"storj.io/ditto/pkg/cache"
+	"storj.io/ditto/pkg/checkpoint"
 	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/delta"
+	"storj.io/ditto/pkg/errclass"
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/failover"
+	"storj.io/ditto/pkg/gc"
+	"storj.io/ditto/pkg/health"
+	"storj.io/ditto/pkg/journal"
+	"storj.io/ditto/pkg/metrics"
+	"storj.io/ditto/pkg/notify"
+	"storj.io/ditto/pkg/objlayer/accounting"
+	"storj.io/ditto/pkg/objlayer/audit"
+	"storj.io/ditto/pkg/objlayer/bucketmap"
+	"storj.io/ditto/pkg/objlayer/compress"
+	"storj.io/ditto/pkg/objlayer/dryrun"
+	"storj.io/ditto/pkg/objlayer/encrypt"
 	"storj.io/ditto/pkg/objlayer/mirroring"
+	"storj.io/ditto/pkg/objlayer/monitor"
+	"storj.io/ditto/pkg/objlayer/normalize"
+	"storj.io/ditto/pkg/objlayer/readonly"
+	"storj.io/ditto/pkg/objlayer/softdelete"
+	"storj.io/ditto/pkg/objlayer/tenant"
+	"storj.io/ditto/pkg/objlayer/throttle"
+	"storj.io/ditto/pkg/objlayer/timeout"
+	"storj.io/ditto/pkg/replication"
+	"storj.io/ditto/pkg/routing"
+	"storj.io/ditto/pkg/schedule"
+	"storj.io/ditto/pkg/seed"
+	"storj.io/ditto/pkg/shadow"
+	"storj.io/ditto/pkg/state"
+	"storj.io/ditto/pkg/trace"
 
 	minio "github.com/minio/minio/cmd"
 	l "storj.io/ditto/pkg/logger"
@@ -32,6 +73,21 @@ func mirroringGatewayMain(ctx *cli.Context) {
 type Mirroring struct {
 	Config *config.Config
 	Logger l.Logger
+
+	// tenant is name of tenant served by child gateway, empty for root gateway
+	tenant string
+	// primeCreds and alterCreds are keys of backends created by NewBackends, replaced when keys are rotated
+	primeCreds *s3.Credentials
+	alterCreds *s3.Credentials
+	// reloader applies changes of config file, nil if reloading is disabled
+	reloader *configReloader
+
+	// server is set for layer created by NewGatewayLayer, databases of gateway are opened for server only
+	server bool
+	// services are listeners and background loops started by Start
+	services []func(ctx context.Context) error
+	// children are gateways of tenants, started with root gateway
+	children []*Mirroring
 }
 
 // Name implements minio.Gateway interface
@@ -39,36 +95,955 @@ func (gw *Mirroring) Name() string {
 	return ""
 }
 
-// NewGatewayLayer implements minio.Gateway interface
-func (gw *Mirroring) NewGatewayLayer(creds auth.Credentials) (objLayer minio.ObjectLayer, err error) {
+// NewGatewayLayer implements minio.Gateway interface, it's called by server only. Journal, state, checkpoint
+// and accounting files are opened and listeners and background services of gateway are started with the layer.
+func (gw *Mirroring) NewGatewayLayer(creds auth.Credentials) (minio.ObjectLayer, error) {
+	gw.server = true
+
+	objLayer, err := gw.newLayer(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = gw.Start(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return objLayer, nil
+}
+
+// NewObjectLayer creates mirroring layer for commands, which exit once their operation is done.
+// Files of server aren't opened and its services aren't started, so commands run while server holds them.
+func (gw *Mirroring) NewObjectLayer() (minio.ObjectLayer, error) {
+	return gw.newLayer(auth.Credentials{})
+}
+
+// Start starts listeners and background services of layer created by NewGatewayLayer: admin and debug
+// servers, health checks, failover, journal replay, collection of orphans, sync, seeding, watchers
+// of config and credentials, metrics and accounting. Services of tenants are started with root gateway.
+func (gw *Mirroring) Start(ctx context.Context) error {
+	for _, start := range gw.services {
+		if err := start(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range gw.children {
+		if err := child.Start(ctx); err != nil {
+			return fmt.Errorf("tenant %q: %s", child.tenant, err)
+		}
+	}
+
+	return nil
+}
+
+// onStart registers service started by Start.
+func (gw *Mirroring) onStart(start func(ctx context.Context) error) {
+	gw.services = append(gw.services, start)
+}
+
+// goOnStart registers background loop run by Start until its context is done.
+func (gw *Mirroring) goOnStart(run func(ctx context.Context)) {
+	gw.onStart(func(ctx context.Context) error {
+		go run(ctx)
+		return nil
+	})
+}
+
+// newLayer creates mirroring layer with components enabled by config, services of server are registered for Start.
+func (gw *Mirroring) newLayer(creds auth.Credentials) (objLayer minio.ObjectLayer, err error) {
 	if gw.Config == nil {
 		return nil, errors.New("configuration is not set")
 	}
 
-	s1Credentials := gw.Config.Server1
-	prime, err := s3.NewS3Compat(s1Credentials.Endpoint, s1Credentials.AccessKey, s1Credentials.SecretKey)
+	// config of tenants is validated with root config
+	if gw.tenant == "" {
+		if err := gw.Config.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// reloader wraps logger before it's passed to any component
+	if opts := gw.Config.Reload; gw.server && opts != nil && opts.Enabled {
+		gw.reloader = gw.newConfigReloader()
+	}
 
+	prime, alter, err := gw.NewBackends()
 	if err != nil {
 		return nil, err
 	}
 
-	s2Credentials := gw.Config.Server2
-	alter, err := s3.NewS3Compat(s2Credentials.Endpoint, s2Credentials.AccessKey, s2Credentials.SecretKey)
+	if opts := gw.Config.Rotation; opts != nil && opts.Enabled {
+		gw.goOnStart(func(ctx context.Context) {
+			gw.watchCredentials(ctx, opts)
+		})
+	}
+
+	if opts := gw.Config.Vault; gw.Config.IsVaulted() && opts.RefreshInterval > 0 {
+		gw.goOnStart(func(ctx context.Context) {
+			gw.refreshCredentials(ctx, opts.RefreshInterval)
+		})
+	}
+
+	mirrLogger, replLogger, syncLogger, err := gw.moduleLoggers()
+	if err != nil {
+		return nil, err
+	}
 
+	prom, metered, err := newMetrics(gw.Config)
 	if err != nil {
 		return nil, err
 	}
 
-	objLayer = &mirroring.MirroringObjectLayer{
-		Prime:  prime,
-		Alter:  alter,
-		Logger: gw.Logger,
-		Config: gw.Config,
+	var ledger *accounting.Ledger
+
+	// ledger is flushed by server only, commands would overwrite usage it counts
+	if opts := gw.Config.Accounting; gw.server && opts != nil && opts.Path != "" {
+		if ledger, err = accounting.Open(opts.Path); err != nil {
+			return nil, err
+		}
+
+		prime = accounting.NewAccountingLayer(prime, ledger, "prime")
+		alter = accounting.NewAccountingLayer(alter, ledger, "alter")
+
+		interval := opts.FlushInterval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+
+		gw.goOnStart(func(ctx context.Context) {
+			ledger.Run(ctx, interval, func(err error) {
+				if gw.Logger != nil {
+					gw.Logger.LogE(err)
+				}
+			})
+		})
+
+		if metered != nil {
+			metered.WatchLedger(ledger)
+		}
+	}
+
+	if metered != nil {
+		prime = monitor.NewMonitoredLayer(prime, monitor.Hooks{Observe: metered.Observer("prime")})
+		alter = monitor.NewMonitoredLayer(alter, monitor.Hooks{Observe: metered.Observer("alter")})
+
+		gw.goOnStart(func(ctx context.Context) {
+			metered.Run(ctx, metrics.DefaultInterval)
+		})
+	}
+
+	if opts := gw.Config.Tracing; opts != nil && opts.Endpoint != "" {
+		tracer := trace.NewTracer(trace.NewZipkinExporter(opts.Endpoint, opts.ServiceName, gw.Logger), opts.SampleRate)
+
+		prime = monitor.NewMonitoredLayer(prime, monitor.Hooks{Observe: tracer.Observer("prime")})
+		alter = monitor.NewMonitoredLayer(alter, monitor.Hooks{Observe: tracer.Observer("alter")})
+
+		// calls of backends become children of spans of requests served by server
+		if gw.server {
+			requestTracer.Store(tracer)
+		}
+	}
+
+	var auditSink audit.Sink
+	if opts := gw.Config.Audit; opts != nil && opts.File != "" {
+		if auditSink, err = audit.NewFileSink(opts.File); err != nil {
+			return nil, err
+		}
+
+		prime = monitor.NewMonitoredLayer(prime, monitor.Hooks{Observe: audit.Observer(audit.Prime)})
+		alter = monitor.NewMonitoredLayer(alter, monitor.Hooks{Observe: audit.Observer(audit.Alter)})
+	}
+
+	// router of reloaded gateway tracks latency in every mode, so reads can be switched to auto mode
+	var router *routing.Router
+	if opts := gw.Config.Read; opts != nil && (opts.Mode == routing.AutoMode || gw.reloader != nil) {
+		router = routing.NewRouter(opts.ProbeInterval)
+
+		prime = monitor.NewMonitoredLayer(prime, monitor.Hooks{Observe: router.Observer(routing.Prime)})
+		alter = monitor.NewMonitoredLayer(alter, monitor.Hooks{Observe: router.Observer(routing.Alter)})
+	}
+
+	// health probes bypass breakers and failover monitoring
+	rawPrime, rawAlter := prime, alter
+
+	var guard *readonly.Guard
+
+	if opts := gw.Config.ReadOnly; opts != nil && opts.Enabled {
+		guard = readonly.NewGuard(opts, failover.IsConnectionError, gw.Logger)
+		alter = monitor.NewMonitoredLayer(alter, monitor.Hooks{After: guard.ReportAlter})
+	}
+
+	var primeBreaker, alterBreaker *breaker.Breaker
+
+	if opts := gw.Config.CircuitBreaker; opts != nil && opts.Enabled {
+		primeBreaker = breaker.NewBreaker(opts.FailureThreshold, opts.Cooldown, errclass.IsTransient)
+		alterBreaker = breaker.NewBreaker(opts.FailureThreshold, opts.Cooldown, errclass.IsTransient)
+
+		prime = monitor.NewMonitoredLayer(prime, monitor.Hooks{Before: primeBreaker.Acquire, After: primeBreaker.Report})
+		alter = monitor.NewMonitoredLayer(alter, monitor.Hooks{Before: alterBreaker.Acquire, After: alterBreaker.Report})
+	}
+
+	var webhook *notify.Webhook
+
+	if opts := gw.Config.Webhook; opts != nil && opts.URL != "" {
+		webhook = notify.NewWebhook(opts, gw.Logger)
+	}
+
+	bus, err := newEventBus(gw.Config.Events, gw.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := newAlertMonitor(gw.Config.Alert, gw.Logger)
+
+	newReplicationHandler := mirroring.NewReplicationHandler
+	if mirroring.IsDeduplicated(gw.Config) {
+		newReplicationHandler = mirroring.NewDedupReplicationHandler
+	}
+
+	var ctrl *failover.Controller
+	var backfill *replication.Queue
+
+	if opts := gw.Config.FailoverOptions; opts != nil && opts.Enabled {
+		ctrl = failover.NewController(opts, gw.Logger)
+		prime = monitor.NewMonitoredLayer(prime, monitor.Hooks{After: ctrl.ReportPrime})
+
+		// backfill streams objects accepted during failover from alter to prime
+		backfill = newQueue(
+			failover.NewBackfillHandler(newReplicationHandler(alter, prime), ctrl),
+			replLogger,
+			gw.Config.Replication)
+
+		if metered != nil {
+			metered.WatchQueue("backfill", backfill)
+		}
+
+		if alerts != nil {
+			alerts.WatchQueue("backfill", backfill)
+		}
+	}
+
+	var checker *health.Checker
+
+	if opts := gw.Config.HealthCheck; opts != nil && opts.Enabled {
+		checker = health.NewChecker(opts, gw.Logger)
+
+		var primeReports, alterReports []func(error)
+
+		if primeBreaker != nil {
+			primeReports = append(primeReports, primeBreaker.Report)
+			alterReports = append(alterReports, alterBreaker.Report)
+		}
+
+		if ctrl != nil {
+			primeReports = append(primeReports, ctrl.ReportPrime)
+		}
+
+		if guard != nil {
+			alterReports = append(alterReports, guard.ReportAlter)
+		}
+
+		checker.AddTarget("prime", newProbe(rawPrime, opts.Bucket), primeReports...)
+		checker.AddTarget("alter", newProbe(rawAlter, opts.Bucket), alterReports...)
+
+		if metered != nil {
+			metered.WatchChecker(checker)
+		}
+
+		gw.goOnStart(checker.Run)
+	} else {
+		if ctrl != nil {
+			gw.goOnStart(func(ctx context.Context) {
+				ctrl.Run(ctx, health.ListBucketsProbe(rawPrime))
+			})
+		}
+
+		// guard learns that alter is back only from operations reaching it, but they stop while it's read-only
+		if guard != nil {
+			gw.goOnStart(func(ctx context.Context) {
+				guard.Run(ctx, health.ListBucketsProbe(rawAlter), health.DefaultInterval)
+			})
+		}
+	}
+
+	var shadowRecorder *shadow.Recorder
+
+	if opts := gw.Config.Shadow; opts != nil && opts.Enabled {
+		shadowRecorder = shadow.NewRecorder(opts.Percentage, gw.Logger)
+	}
+
+	var db *state.DB
+
+	if opts := gw.Config.State; gw.server && opts != nil && opts.Path != "" {
+		if db, err = state.Open(opts.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	// usage of buckets is tracked in state database of server only
+	if opts := gw.Config.Quota; opts != nil && opts.IsEnabled() && (gw.Config.State == nil || gw.Config.State.Path == "") {
+		return nil, errors.New("bucket quotas require State.Path to be set")
+	}
+
+	handler := newReplicationHandler(prime, alter)
+	if alterBreaker != nil {
+		handler = breaker.NewHandler(handler, alterBreaker)
+	}
+
+	if db != nil {
+		handler = state.NewHandler(handler, db)
+	}
+
+	queue := newQueue(handler, replLogger, gw.Config.Replication)
+
+	if metered != nil {
+		metered.WatchQueue("replication", queue)
+	}
+
+	if alerts != nil {
+		alerts.WatchQueue("replication", queue)
+	}
+
+	var jrnl *journal.Journal
+
+	if opts := gw.Config.Journal; gw.server && opts != nil && opts.Path != "" {
+		if jrnl, err = journal.Open(opts.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	var backfillJrnl *journal.Journal
+
+	// writes accepted during failover are journaled if they can't be backfilled, and replayed once prime is back
+	if opts := gw.Config.Journal; gw.server && backfill != nil && opts != nil && opts.Path != "" {
+		if backfillJrnl, err = journal.Open(opts.BackfillJournalPath()); err != nil {
+			return nil, err
+		}
+	}
+
+	if backfill != nil && (backfillJrnl != nil || webhook != nil) {
+		backfill.SetDropHandler(newDropHandler(backfillJrnl, webhook, "prime", gw.Logger))
+	}
+
+	if jrnl != nil || webhook != nil {
+		queue.SetDropHandler(newDropHandler(jrnl, webhook, "alter", gw.Logger))
+	}
+
+	// orphans are collected and backends synced only while they are expected to converge,
+	// objects written to alter during failover are not orphans until backfilled to prime
+	converging := func() bool {
+		return (ctrl == nil || !ctrl.IsFailedOver()) &&
+			(backfill == nil || backfill.Len() == 0) &&
+			(backfillJrnl == nil || backfillJrnl.Len() == 0) &&
+			(alterBreaker == nil || !alterBreaker.IsOpen())
+	}
+
+	scheduler := schedule.NewScheduler(gw.Logger)
+
+	if opts := gw.Config.GC; opts != nil && opts.Enabled {
+		collector := gc.NewCollector(rawPrime, rawAlter, opts, gw.Logger)
+		collector.WithReadyCheck(converging)
+
+		if jrnl != nil {
+			collector.WithJournal(jrnl)
+		}
+
+		if backfillJrnl != nil {
+			collector.WithJournal(backfillJrnl)
+		}
+
+		if db != nil {
+			collector.WithState(db)
+		}
+
+		if opts.Schedule != "" {
+			if err = scheduler.Add("gc", opts.Schedule, collector.RunOnce); err != nil {
+				return nil, err
+			}
+		} else {
+			gw.goOnStart(collector.Run)
+		}
+	}
+
+	var missing *cache.Negative
+	if opts := gw.Config.Cache; opts != nil && opts.MissingTTL > 0 {
+		missing = cache.NewNegative(opts.MissingTTL, opts.MissingSize)
+	}
+
+	var buckets *cache.Buckets
+	if opts := gw.Config.Cache; opts != nil && opts.BucketsTTL > 0 {
+		buckets = cache.NewBuckets(opts.BucketsTTL)
+	}
+
+	var memory *cache.Memory
+	if opts := gw.Config.Cache; opts != nil && opts.MemorySize > 0 {
+		memory = cache.NewMemory(opts.MemorySize, opts.MemoryObjectSize)
+	}
+
+	var disk *cache.Disk
+	if opts := gw.Config.Cache; opts != nil && opts.DiskDir != "" {
+		if disk, err = cache.NewDisk(opts.DiskDir, opts.DiskSize, opts.DiskObjectSize); err != nil {
+			return nil, err
+		}
+	}
+
+	mirr := &mirroring.MirroringObjectLayer{
+		Prime:       prime,
+		Alter:       alter,
+		Logger:      mirrLogger,
+		Config:      gw.Config,
+		Replication: queue,
+		Failover:    ctrl,
+		Backfill:    backfill,
+
+		BackfillJournal: backfillJrnl,
+		AlterBreaker:    alterBreaker,
+		Journal:         jrnl,
+		Shadow:          shadowRecorder,
+		State:           db,
+		Notifier:        webhook,
+		Events:          bus,
+		Alerts:          alerts,
+		Router:          router,
+		Missing:         missing,
+		Buckets:         buckets,
+		Memory:          memory,
+		Disk:            disk,
+		Ledger:          ledger,
+	}
+
+	mirr.EmitDeferredOutcomes()
+
+	// queued tasks hold lock of their object like client writes, so a task which read prime before
+	// a newer write reached it can't overwrite the newer object in alter, or prime when backfilling
+	queue.SetHandler(mirr.LockedHandler(handler))
+
+	if backfill != nil {
+		backfill.SetHandler(failover.NewBackfillHandler(mirr.LockedHandler(newReplicationHandler(alter, prime)), ctrl))
+	}
+
+	if gw.reloader != nil {
+		if err = mirr.Reconfigure(gw.Config); err != nil {
+			return nil, err
+		}
+
+		gw.reloader.mirr = mirr
+		gw.reloader.router = router
+
+		gw.goOnStart(func(ctx context.Context) {
+			gw.reloader.watch(ctx, gw.Config.Reload)
+		})
+	}
+
+	var replayer *journal.Replayer
+
+	// journaled tasks hold lock of their object like mirrored operations, so they don't race with writes of clients
+	if jrnl != nil {
+		replayHandler := newReplicationHandler(prime, alter)
+		if db != nil {
+			replayHandler = state.NewHandler(replayHandler, db)
+		}
+
+		replayer = journal.NewReplayer(jrnl, mirr.LockedHandler(replayHandler), gw.Logger, gw.Config.Journal.ReplayInterval)
+		replayer.WithReadyCheck(func() bool {
+			return !queue.IsPaused() && (alterBreaker == nil || !alterBreaker.IsOpen())
+		})
+		replayer.WithPermanent(errclass.IsPermanent, newDropHandler(nil, webhook, "alter", gw.Logger))
+
+		gw.goOnStart(replayer.Run)
+	}
+
+	if backfillJrnl != nil {
+		backfillReplayer := journal.NewReplayer(backfillJrnl, mirr.LockedHandler(newReplicationHandler(alter, prime)), gw.Logger, gw.Config.Journal.ReplayInterval)
+		backfillReplayer.WithReadyCheck(func() bool {
+			return !ctrl.IsFailedOver()
+		})
+		backfillReplayer.WithPermanent(errclass.IsPermanent, newDropHandler(nil, webhook, "prime", gw.Logger))
+
+		gw.goOnStart(backfillReplayer.Run)
+	}
+
+	var engine *delta.Engine
+
+	if opts := gw.Config.Sync; opts != nil && opts.Enabled {
+		engine = delta.NewEngine(rawPrime, rawAlter, mirr.LockedHandler(handler), opts, syncLogger)
+
+		// objects which aren't mirrored and orphans quarantined by gc aren't differences
+		quarantine := gc.QuarantinePrefix(gw.Config.GC)
+		engine.WithMirrored(func(object string) bool {
+			return mirr.IsMirrored(object) && !strings.HasPrefix(object, quarantine)
+		})
+
+		if opts.CheckpointPath != "" && gw.server {
+			cp, err := checkpoint.Open(opts.CheckpointPath)
+			if err != nil {
+				return nil, err
+			}
+
+			engine.WithCheckpoint(cp)
+		}
+
+		engine.WithReadyCheck(func() bool {
+			return converging() && !queue.IsPaused()
+		})
+
+		if opts.Schedule != "" {
+			if err = scheduler.Add("sync", opts.Schedule, engine.RunOnce); err != nil {
+				return nil, err
+			}
+		} else {
+			gw.goOnStart(engine.Run)
+		}
+	}
+
+	gw.goOnStart(scheduler.Run)
+
+	var seeder *seed.Seeder
+
+	// alter is seeded by server only, so commands don't copy whole prime when they start
+	if opts := gw.Config.Seed; gw.server && opts != nil && opts.Enabled {
+		if seeder, err = newSeeder(rawPrime, rawAlter, mirr.LockedHandler(handler), jrnl, webhook, opts, gw.Logger); err != nil {
+			return nil, err
+		}
+
+		seeder.WithMirrored(mirr.IsMirrored)
+
+		gw.goOnStart(func(ctx context.Context) {
+			if err := seeder.Run(ctx); err != nil && gw.Logger != nil {
+				gw.Logger.LogE(err)
+			}
+		})
+	}
+
+	api := &admin.API{
+		Queue:    queue,
+		Backfill: backfill,
+		Checker:  checker,
+		Failover: ctrl,
+		Journal:  jrnl,
+		Replayer: replayer,
+		Sync:     engine,
+		Logger:   gw.Logger,
+	}
+
+	// actions of api run in background are cancelled with the server, registered before its listeners start
+	gw.onStart(func(ctx context.Context) error {
+		api.Context = ctx
+		return nil
+	})
+
+	if opts := gw.Config.Admin; opts != nil && opts.GRPCAddress != "" {
+		grpcSrv := admin.NewGRPCServer(opts.GRPCAddress, api, gw.Logger).WithToken(opts.Token)
+
+		gw.onStart(func(ctx context.Context) error {
+			return grpcSrv.Start()
+		})
+	}
+
+	if opts := gw.Config.Admin; opts != nil && opts.Address != "" {
+		srv := admin.NewServer(opts.Address, gw.Logger).WithToken(opts.Token)
+
+		srv.HandlePublic("/healthz", health.Liveness())
+		srv.HandlePublic("/readyz", newReadiness(checker, ctrl, jrnl, seeder, gw.Config.Readiness))
+
+		srv.Handle("/api/", api)
+
+		if checker != nil {
+			srv.Handle("/health", checker)
+		}
+
+		if shadowRecorder != nil {
+			srv.Handle("/shadow", shadowRecorder)
+		}
+
+		srv.Handle("/replication/", queue)
+
+		if seeder != nil {
+			srv.Handle("/seed", seeder)
+		}
+
+		if db != nil {
+			srv.Handle("/state", db)
+		}
+
+		if ledger != nil {
+			srv.Handle("/accounting", ledger)
+		}
+
+		if prom != nil {
+			srv.Handle("/metrics", prom)
+		}
+
+		gw.onStart(func(ctx context.Context) error {
+			return srv.Start()
+		})
+	}
+
+	if opts := gw.Config.Debug; opts != nil && opts.Address != "" {
+		gw.onStart(func(ctx context.Context) error {
+			return admin.NewDebugServer(opts.Address, gw.Logger).Start()
+		})
+	}
+
+	objLayer = mirr
+
+	if auditSink != nil {
+		objLayer = audit.NewAuditedLayer(objLayer, auditSink, gw.Logger)
+	}
+
+	if guard != nil {
+		if jrnl != nil {
+			guard.WithJournalLen(jrnl.Len)
+		}
+
+		objLayer = readonly.NewReadOnlyLayer(objLayer, guard)
+	}
+
+	if len(gw.Config.Tenants) > 0 {
+		return gw.withTenants(objLayer, creds)
 	}
 
 	return objLayer, nil
 }
 
+// withTenants creates mirroring layer of every tenant and routes requests of tenants to them,
+// other requests are served by root.
+func (gw *Mirroring) withTenants(root minio.ObjectLayer, creds auth.Credentials) (minio.ObjectLayer, error) {
+	var tenants []tenant.Tenant
+
+	for _, opts := range gw.Config.Tenants {
+		var logger l.Logger = l.WithPrefix(gw.Logger, "["+opts.Name+"] ")
+		if s, ok := gw.Logger.(l.Structured); ok {
+			logger = s.With(l.F("tenant", opts.Name))
+		}
+
+		child := &Mirroring{Logger: logger, Config: gw.Config.ForTenant(opts), tenant: opts.Name, server: gw.server}
+
+		ol, err := child.newLayer(creds)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %q: %s", opts.Name, err)
+		}
+
+		gw.children = append(gw.children, child)
+
+		tenants = append(tenants, tenant.Tenant{
+			Name:         opts.Name,
+			AccessKeys:   opts.AccessKeys,
+			BucketPrefix: opts.BucketPrefix,
+			Layer:        ol,
+		})
+	}
+
+	return tenant.NewTenantLayer(root, tenants)
+}
+
+// NewBackends creates prime and alter object layers with rate limits, metadata normalization, encryption,
+// compression, dry run, bucket mapping, soft delete and timeouts applied, but without breakers and failover monitoring.
+// Tools which operate on backends directly, e.g. sync, use them instead of the mirroring layer.
+func (gw *Mirroring) NewBackends() (prime, alter minio.ObjectLayer, err error) {
+	if gw.Config == nil {
+		return nil, nil, errors.New("configuration is not set")
+	}
+
+	primeKeys, alterKeys, err := backendKeys(gw.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s1Credentials := gw.Config.Server1
+	gw.primeCreds = s3.NewCredentials(primeKeys.AccessKey, primeKeys.SecretKey)
+	s1, err := s3.NewS3CompatWithCredentials(s1Credentials.Endpoint, gw.primeCreds)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prime = s1
+
+	s2Credentials := gw.Config.Server2
+	gw.alterCreds = s3.NewCredentials(alterKeys.AccessKey, alterKeys.SecretKey)
+	s2, err := s3.NewS3CompatWithCredentials(s2Credentials.Endpoint, gw.alterCreds)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	alter = s2
+
+	// rate limits are innermost, so every request sent to backend takes a token
+	if opts := gw.Config.RateLimit; opts != nil || gw.reloader != nil {
+		lm := newLimits(opts, gw.reloader != nil)
+
+		prime = throttle.NewRateLimitLayer(prime, lm.primeRead, lm.primeWrite)
+		alter = throttle.NewRateLimitLayer(alter, lm.alterRead, lm.alterWrite)
+
+		if lm.alterBandwidth != nil {
+			alter = throttle.NewBandwidthLayer(alter, lm.alterBandwidth)
+		}
+
+		if gw.reloader != nil {
+			gw.reloader.limits = lm
+		}
+	}
+
+	if opts := gw.Config.Metadata; opts != nil && opts.Normalize {
+		prime = normalize.NewNormalizingLayer(prime)
+		alter = normalize.NewNormalizingLayer(alter)
+	}
+
+	// encryption is below compression, so compressed content is encrypted
+	if opts := gw.Config.Encryption; opts != nil && (opts.Key != "" || opts.KeyFile != "") {
+		key, err := encrypt.LoadKey(opts.Key, opts.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if alter, err = encrypt.NewEncryptingLayer(alter, key); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// compression is below dry run, bucket mapping and soft delete, so objects they write or move are compressed
+	if opts := gw.Config.Compression; opts != nil && opts.Algorithm != "" && opts.Algorithm != compress.None {
+		if alter, err = compress.NewCompressingLayer(alter, opts.Algorithm, opts.MinSize); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// dry run is above encryption and compression, which never see writes nor multipart uploads it intercepts,
+	// and below bucket mapping and soft delete, so logged operations reflect them, reads still reach alter
+	if opts := gw.Config.DryRun; opts != nil && opts.Enabled {
+		alter = dryrun.NewDryRunLayer(alter, gw.Logger)
+	}
+
+	mapper := bucketmap.NewMapper(gw.Config.BucketMapping)
+	if !mapper.IsEmpty() {
+		alter = bucketmap.NewBucketMappingLayer(alter, mapper)
+	}
+
+	if opts := gw.Config.DeleteOptions; opts != nil && opts.SoftDelete {
+		// trash is hidden by soft delete layer, so it's purged below it
+		if opts.TrashRetention > 0 {
+			gw.goOnStart(softdelete.NewPurger(alter, opts.TrashPrefix, opts.TrashRetention, gw.Logger).Run)
+		}
+
+		alter = softdelete.NewSoftDeleteLayer(alter, opts.TrashPrefix)
+	}
+
+	if gw.Config.Timeouts != nil {
+		prime = timeout.NewTimeoutLayer(prime, gw.Config.Timeouts)
+		alter = timeout.NewTimeoutLayer(alter, gw.Config.Timeouts)
+	}
+
+	return prime, alter, nil
+}
+
+// newSeeder creates seeder journaling and notifying about objects which failed to copy, if journal or webhook is set.
+func newSeeder(prime, alter minio.ObjectLayer, handler replication.Handler, jrnl *journal.Journal, webhook *notify.Webhook, opts *config.SeedOptions, logger l.Logger) (*seed.Seeder, error) {
+	var cp *checkpoint.Checkpoint
+
+	if opts.CheckpointPath != "" {
+		var err error
+		if cp, err = checkpoint.Open(opts.CheckpointPath); err != nil {
+			return nil, err
+		}
+	}
+
+	seeder := seed.NewSeeder(prime, alter, handler, cp, opts.Workers, logger)
+
+	if jrnl != nil || webhook != nil {
+		seeder.SetDropHandler(newDropHandler(jrnl, webhook, "alter", logger))
+	}
+
+	return seeder, nil
+}
+
+// newDropHandler creates handler of tasks given up on backend, which appends them to journal
+// and notifies webhook. Nil journal or webhook is skipped.
+func newDropHandler(jrnl *journal.Journal, webhook *notify.Webhook, backend string, logger l.Logger) func(task replication.Task, err error) {
+	return func(task replication.Task, err error) {
+		if jrnl != nil {
+			if err := jrnl.Append(task); err != nil && logger != nil {
+				logger.LogE(err)
+			}
+		}
+
+		if webhook != nil {
+			webhook.Notify(task, backend, err, jrnl != nil)
+		}
+	}
+}
+
+// moduleLoggers returns loggers of mirroring handlers, replication queues and sync engine
+// with levels configured for them.
+func (gw *Mirroring) moduleLoggers() (mirr, repl, sync l.Logger, err error) {
+	forModule := func(module string) (l.Logger, error) {
+		if gw.reloader != nil {
+			return gw.reloader.moduleLogger(gw.Config.Log, module)
+		}
+
+		return l.ForModule(gw.Logger, gw.Config.Log, module)
+	}
+
+	if mirr, err = forModule(l.MirroringModule); err != nil {
+		return
+	}
+
+	if repl, err = forModule(l.ReplicationModule); err != nil {
+		return
+	}
+
+	sync, err = forModule(l.SyncModule)
+
+	return
+}
+
+// newEventBus creates bus exporting mirrored operations to broker configured by opts.
+// Returns nil bus if export is disabled.
+func newEventBus(opts *config.EventsOptions, logger l.Logger) (*events.Bus, error) {
+	if opts == nil || opts.Type == "" {
+		return nil, nil
+	}
+
+	var publisher events.Publisher
+
+	switch opts.Type {
+	case "nats":
+		publisher = events.NewNATSPublisher(opts.Address)
+	case "kafka":
+		publisher = events.NewKafkaRESTPublisher(opts.Address)
+	default:
+		return nil, errors.New("unknown events type " + opts.Type)
+	}
+
+	return events.NewBus(publisher, opts.Topic, logger), nil
+}
+
+// newAlertMonitor creates monitor alerting through Slack and email configured by opts.
+// Returns nil if neither is configured.
+func newAlertMonitor(opts *config.AlertOptions, logger l.Logger) *alert.Monitor {
+	if opts == nil {
+		return nil
+	}
+
+	var notifiers []alert.Notifier
+
+	if opts.SlackURL != "" {
+		notifiers = append(notifiers, alert.NewSlack(opts.SlackURL))
+	}
+
+	if opts.SMTPAddress != "" && opts.EmailTo != "" {
+		to := strings.Split(opts.EmailTo, ",")
+		for i := range to {
+			to[i] = strings.TrimSpace(to[i])
+		}
+
+		notifiers = append(notifiers, alert.NewEmail(opts.SMTPAddress, opts.SMTPUsername, opts.SMTPPassword, opts.EmailFrom, to))
+	}
+
+	if len(notifiers) == 0 {
+		return nil
+	}
+
+	return alert.NewMonitor(opts, logger, notifiers...)
+}
+
+// newMetrics creates metrics reporting to Prometheus and statsd sinks enabled by cfg.
+// Returns nil metrics if no sink is enabled, Prometheus sink is nil if it's disabled.
+func newMetrics(cfg *config.Config) (*metrics.Prometheus, *metrics.Metrics, error) {
+	var prom *metrics.Prometheus
+	var sinks []metrics.Sink
+
+	if opts := cfg.Metrics; opts != nil && opts.Enabled {
+		prom = metrics.NewPrometheus()
+		sinks = append(sinks, prom)
+	}
+
+	if opts := cfg.StatsD; opts != nil && opts.Host != "" {
+		statsd, err := metrics.NewStatsD(net.JoinHostPort(opts.Host, strconv.Itoa(opts.Port)), opts.Prefix, opts.Datadog)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sinks = append(sinks, statsd)
+	}
+
+	if len(sinks) == 0 {
+		return nil, nil, nil
+	}
+
+	return prom, metrics.New(sinks...), nil
+}
+
+// newReadiness creates readiness checks of enabled components: health of backend serving requests,
+// length of journal and progress of seeding.
+func newReadiness(checker *health.Checker, ctrl *failover.Controller, jrnl *journal.Journal, seeder *seed.Seeder, opts *config.ReadinessOptions) *health.Readiness {
+	if opts == nil {
+		opts = &config.ReadinessOptions{}
+	}
+
+	readiness := health.NewReadiness()
+
+	if checker != nil {
+		readiness.AddCheck("backend", func() error {
+			serving := "prime"
+			if ctrl != nil && ctrl.IsFailedOver() {
+				serving = "alter"
+			}
+
+			if s, ok := checker.Lookup(serving); ok && !s.Healthy {
+				return fmt.Errorf("%s is unhealthy: %s", serving, s.LastError)
+			}
+
+			return nil
+		})
+	}
+
+	if jrnl != nil && opts.MaxJournalLen > 0 {
+		readiness.AddCheck("journal", func() error {
+			if n := jrnl.Len(); n > opts.MaxJournalLen {
+				return fmt.Errorf("%d tasks pending in journal", n)
+			}
+
+			return nil
+		})
+	}
+
+	if seeder != nil && opts.WaitForSeed {
+		readiness.AddCheck("seed", func() error {
+			if p := seeder.Progress(); !p.Done {
+				return fmt.Errorf("seeding in progress, %s", p)
+			}
+
+			return nil
+		})
+	}
+
+	return readiness
+}
+
+// newQueue creates replication queue tuned with opts, nil opts creates queue with defaults.
+func newQueue(handler replication.Handler, logger l.Logger, opts *config.ReplicationOptions) *replication.Queue {
+	if opts == nil {
+		opts = &config.ReplicationOptions{}
+	}
+
+	q := replication.NewConcurrentQueue(handler, logger, opts.Workers, opts.Concurrency, opts.QueueDepth)
+	q.SetRetryable(errclass.IsRetryable)
+
+	return q
+}
+
+// newProbe creates HEAD Bucket probe if bucket is set, otherwise ListBuckets probe.
+func newProbe(ol minio.ObjectLayer, bucket string) health.Probe {
+	if bucket != "" {
+		return health.BucketProbe(ol, bucket)
+	}
+
+	return health.ListBucketsProbe(ol)
+}
+
 // Production - both gateways are production ready.
 func (gw *Mirroring) Production() bool {
 	return false
@@ -0,0 +1,184 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"storj.io/ditto/pkg/config"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/objlayer/mirroring"
+	"storj.io/ditto/pkg/ratelimit"
+	"storj.io/ditto/pkg/reload"
+	"storj.io/ditto/pkg/routing"
+)
+
+// configReloader applies changes of config file to running gateway, see config.ReloadOptions.
+// Every option is replaced in place, so requests in flight are not interrupted.
+type configReloader struct {
+	gw *Mirroring
+
+	// base is logger of gateway as created, loggers with reloaded levels are derived from it.
+	// It's nil if logger isn't Structured, so levels can't be reloaded.
+	base    l.Structured
+	root    *l.Swappable
+	modules map[string]*l.Swappable
+
+	mirr   *mirroring.MirroringObjectLayer
+	router *routing.Router
+	limits *limits
+}
+
+// newConfigReloader creates reloader of gw, logger of gw is replaced by one whose level can be reloaded.
+func (gw *Mirroring) newConfigReloader() *configReloader {
+	r := &configReloader{gw: gw, modules: make(map[string]*l.Swappable)}
+
+	if s, ok := gw.Logger.(l.Structured); ok {
+		r.base = s
+		r.root = l.NewSwappable(s)
+		gw.Logger = r.root
+	}
+
+	return r
+}
+
+// moduleLogger returns logger of module whose level can be reloaded.
+func (r *configReloader) moduleLogger(opts *config.LogOptions, module string) (l.Logger, error) {
+	if r.base == nil {
+		return l.ForModule(r.gw.Logger, opts, module)
+	}
+
+	lg, err := l.ForModule(r.base, opts, module)
+	if err != nil {
+		return nil, err
+	}
+
+	s := l.NewSwappable(lg.(l.Structured))
+	r.modules[module] = s
+
+	return s, nil
+}
+
+// watch reloads config when SIGHUP is received or when config file is modified.
+func (r *configReloader) watch(ctx context.Context, opts *config.ReloadOptions) {
+	reload.NewWatcher(opts.WatchInterval, config.Path()).Run(ctx, func() {
+		if err := r.reload(); err != nil {
+			r.gw.Logger.LogE(fmt.Errorf("unable to reload configuration: %s", err))
+			return
+		}
+
+		r.gw.Logger.Log("configuration reloaded")
+	})
+}
+
+// reload reads config file and applies log levels, read mode, rate limits and filters of gateway.
+// Nothing is applied if any of them is invalid.
+func (r *configReloader) reload() error {
+	cfg, err := config.ReadConfig(true)
+	if err != nil {
+		return err
+	}
+
+	if cfg, err = r.gw.tenantConfig(cfg); err != nil {
+		return err
+	}
+
+	root, modules, err := r.loggers(cfg.Log)
+	if err != nil {
+		return err
+	}
+
+	if r.mirr != nil {
+		if err = r.mirr.Reconfigure(cfg); err != nil {
+			return err
+		}
+	}
+
+	if root != nil {
+		r.root.Swap(root)
+
+		for module, lg := range modules {
+			r.modules[module].Swap(lg)
+		}
+	}
+
+	if r.router != nil && cfg.Read != nil {
+		r.router.SetProbeInterval(cfg.Read.ProbeInterval)
+	}
+
+	if r.limits != nil {
+		r.limits.set(cfg.RateLimit)
+	}
+
+	return nil
+}
+
+// loggers returns root and module loggers with levels configured by opts.
+// Nil loggers are returned if levels can't be reloaded or opts aren't set.
+func (r *configReloader) loggers(opts *config.LogOptions) (l.Structured, map[string]l.Structured, error) {
+	if r.base == nil || opts == nil {
+		return nil, nil, nil
+	}
+
+	level, err := l.ParseLevel(opts.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := r.base.WithLevel(level)
+	modules := make(map[string]l.Structured)
+
+	for module := range r.modules {
+		lg, err := l.ForModule(root, opts, module)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		modules[module] = lg.(l.Structured)
+	}
+
+	return root, modules, nil
+}
+
+// limits are rate limiters of backends, see config.RateLimitOptions.
+type limits struct {
+	primeRead, primeWrite *ratelimit.Limiter
+	alterRead, alterWrite *ratelimit.Limiter
+	alterBandwidth        *ratelimit.Limiter
+}
+
+// newLimits creates limiters configured by opts, nil opts means unlimited. Adjustable limiters are created
+// even for unlimited rates, so they can be limited when config is reloaded.
+func newLimits(opts *config.RateLimitOptions, adjustable bool) *limits {
+	if opts == nil {
+		opts = &config.RateLimitOptions{}
+	}
+
+	newLimiter := ratelimit.NewLimiter
+	if adjustable {
+		newLimiter = ratelimit.NewAdjustableLimiter
+	}
+
+	return &limits{
+		primeRead:      newLimiter(opts.PrimeRead, opts.Burst),
+		primeWrite:     newLimiter(opts.PrimeWrite, opts.Burst),
+		alterRead:      newLimiter(opts.AlterRead, opts.Burst),
+		alterWrite:     newLimiter(opts.AlterWrite, opts.Burst),
+		alterBandwidth: newLimiter(float64(opts.AlterBandwidth), 0),
+	}
+}
+
+// set replaces rates of adjustable limiters with rates of opts, nil opts removes limits.
+func (lm *limits) set(opts *config.RateLimitOptions) {
+	if opts == nil {
+		opts = &config.RateLimitOptions{}
+	}
+
+	lm.primeRead.SetRate(opts.PrimeRead, opts.Burst)
+	lm.primeWrite.SetRate(opts.PrimeWrite, opts.Burst)
+	lm.alterRead.SetRate(opts.AlterRead, opts.Burst)
+	lm.alterWrite.SetRate(opts.AlterWrite, opts.Burst)
+	lm.alterBandwidth.SetRate(float64(opts.AlterBandwidth), 0)
+}
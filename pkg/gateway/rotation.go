@@ -0,0 +1,147 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/credentials"
+	s3 "storj.io/ditto/pkg/objlayer/s3compat"
+	"storj.io/ditto/pkg/reload"
+)
+
+// watchCredentials replaces keys of backends when SIGHUP is received or when file with keys is modified.
+func (gw *Mirroring) watchCredentials(ctx context.Context, opts *config.RotationOptions) {
+	file := opts.File
+	if file == "" {
+		file = config.Path()
+	}
+
+	reload.NewWatcher(opts.WatchInterval, file).Run(ctx, func() {
+		if err := gw.reloadCredentials(opts.File); err != nil && gw.Logger != nil {
+			gw.Logger.LogE(fmt.Errorf("unable to reload backend credentials: %s", err))
+		}
+	})
+}
+
+// refreshCredentials re-reads keys of backends from their provider every interval, e.g. keys rotated in Vault.
+func (gw *Mirroring) refreshCredentials(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := gw.rotateCredentials(gw.Config); err != nil && gw.Logger != nil {
+			gw.Logger.LogE(fmt.Errorf("unable to refresh backend credentials: %s", err))
+		}
+	}
+}
+
+// reloadCredentials reads config from file, or from config file if file is empty, and replaces keys of backends.
+// Child gateway of tenant takes keys of its tenant, inheriting keys of root config unless tenant sets its own.
+func (gw *Mirroring) reloadCredentials(file string) error {
+	cfg, err := readCredentials(file, gw.Config.Secrets)
+	if err != nil {
+		return err
+	}
+
+	if cfg, err = gw.tenantConfig(cfg); err != nil {
+		return err
+	}
+
+	return gw.rotateCredentials(cfg)
+}
+
+// tenantConfig returns config of tenant served by gw taken from root cfg, or cfg itself for root gateway.
+func (gw *Mirroring) tenantConfig(cfg *config.Config) (*config.Config, error) {
+	if gw.tenant == "" {
+		return cfg, nil
+	}
+
+	for _, opts := range cfg.Tenants {
+		if opts != nil && opts.Name == gw.tenant {
+			return cfg.ForTenant(opts), nil
+		}
+	}
+
+	return nil, fmt.Errorf("tenant %q is not configured", gw.tenant)
+}
+
+// rotateCredentials replaces keys of backends with keys provided by cfg.
+func (gw *Mirroring) rotateCredentials(cfg *config.Config) error {
+	prime, alter, err := backendKeys(cfg)
+	if err != nil {
+		return err
+	}
+
+	gw.rotate(credentials.Prime, gw.primeCreds, prime)
+	gw.rotate(credentials.Alter, gw.alterCreds, alter)
+
+	return nil
+}
+
+// rotate replaces keys of backend named name, unset keys are kept.
+func (gw *Mirroring) rotate(name string, creds *s3.Credentials, keys credentials.Keys) {
+	if creds == nil || keys.AccessKey == "" || keys.SecretKey == "" {
+		return
+	}
+
+	if creds.Set(keys.AccessKey, keys.SecretKey) && gw.Logger != nil {
+		gw.Logger.Log(fmt.Sprintf("credentials of %s replaced, access key %s", name, keys.AccessKey))
+	}
+}
+
+// backendKeys returns keys of prime and alter provided by cfg, either stored in it or read from Vault.
+func backendKeys(cfg *config.Config) (prime, alter credentials.Keys, err error) {
+	provider, err := credentials.NewProvider(cfg)
+	if err != nil {
+		return prime, alter, err
+	}
+
+	if prime, err = provider.Keys(context.Background(), credentials.Prime); err != nil {
+		return prime, alter, err
+	}
+
+	alter, err = provider.Keys(context.Background(), credentials.Alter)
+
+	return prime, alter, err
+}
+
+// readCredentials reads JSON file of config structure, or config file if file is empty.
+// Secrets encrypted in file are decrypted with passphrase located by its own Secrets section or by secrets.
+func readCredentials(file string, secrets *config.SecretsOptions) (*config.Config, error) {
+	if file == "" {
+		return config.ReadConfig(true)
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config.Config{}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Secrets == nil {
+		cfg.Secrets = secrets
+	}
+
+	if err := cfg.DecryptSecrets(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
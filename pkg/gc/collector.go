@@ -0,0 +1,312 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/journal"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/objlayer/softdelete"
+	"storj.io/ditto/pkg/state"
+)
+
+const (
+	DefaultInterval         = 24 * time.Hour
+	DefaultMinAge           = time.Hour
+	DefaultQuarantinePrefix = ".orphans/"
+	listBatch               = 1000
+)
+
+// Stats describes single collection round.
+type Stats struct {
+	Scanned int
+	Orphans int
+	Removed int
+	Skipped int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("scanned %d, orphans %d, removed %d, skipped %d", s.Scanned, s.Orphans, s.Removed, s.Skipped)
+}
+
+// Collector periodically removes orphans from alter - objects which don't exist on prime anymore,
+// e.g. because they were deleted on prime while alter was unreachable.
+// Only orphans whose deletion on prime is recorded in state are removed, others are moved under quarantine
+// prefix, as object missing on prime may be the only copy, e.g. written during failover and not backfilled yet.
+type Collector struct {
+	prime, alter minio.ObjectLayer
+	logger       l.Logger
+
+	interval, minAge time.Duration
+	quarantinePrefix string
+
+	journals []*journal.Journal
+	state    *state.DB
+
+	// ready reports whether backends are expected to be in sync, nil means always.
+	ready func() bool
+
+	now func() time.Time
+}
+
+// Creates new Collector, zero options are replaced with defaults.
+func NewCollector(prime, alter minio.ObjectLayer, opts *config.GCOptions, logger l.Logger) *Collector {
+	c := &Collector{
+		prime:            prime,
+		alter:            alter,
+		logger:           logger,
+		interval:         DefaultInterval,
+		minAge:           DefaultMinAge,
+		quarantinePrefix: QuarantinePrefix(opts),
+		now:              time.Now,
+	}
+
+	if opts == nil {
+		return c
+	}
+
+	if opts.Interval > 0 {
+		c.interval = opts.Interval
+	}
+
+	if opts.MinAge > 0 {
+		c.minAge = opts.MinAge
+	}
+
+	return c
+}
+
+// QuarantinePrefix returns prefix orphans are moved under by collector configured with opts.
+func QuarantinePrefix(opts *config.GCOptions) string {
+	if opts == nil || opts.QuarantinePrefix == "" {
+		return DefaultQuarantinePrefix
+	}
+
+	return opts.QuarantinePrefix
+}
+
+// WithJournal makes collector skip objects with operations pending in journal, their state on alter
+// is going to change once journal is replayed. Objects pending in every journal passed are skipped.
+func (c *Collector) WithJournal(j *journal.Journal) *Collector {
+	c.journals = append(c.journals, j)
+
+	return c
+}
+
+// WithState makes collector remove orphans whose deletion on prime is recorded in db, see state.DELETED.
+// Without state every orphan is quarantined.
+func (c *Collector) WithState(db *state.DB) *Collector {
+	c.state = db
+
+	return c
+}
+
+// WithReadyCheck makes collector skip rounds while ready returns false, e.g. while failed over to alter.
+func (c *Collector) WithReadyCheck(ready func() bool) *Collector {
+	c.ready = ready
+
+	return c
+}
+
+// Run collects orphans every interval until ctx is done.
+func (c *Collector) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.RunOnce(ctx); err != nil {
+				c.logE(err)
+			}
+		}
+	}
+}
+
+// RunOnce runs single collection round and logs its stats, round is skipped if ready check fails.
+func (c *Collector) RunOnce(ctx context.Context) error {
+	if c.ready != nil && !c.ready() {
+		c.log("gc: skipped, backends are not expected to be in sync")
+		return nil
+	}
+
+	stats, err := c.Collect(ctx)
+	c.log("gc: " + stats.String())
+
+	return err
+}
+
+// Collect lists alter copies of all prime buckets and removes or quarantines objects missing on prime.
+// Objects younger than min age and objects with operations pending in journal are skipped.
+func (c *Collector) Collect(ctx context.Context) (Stats, error) {
+	var stats Stats
+
+	pending, err := c.pending()
+	if err != nil {
+		return stats, err
+	}
+
+	buckets, err := c.prime.ListBuckets(ctx)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, b := range buckets {
+		if err := c.collectBucket(ctx, b.Name, pending, &stats); err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+func (c *Collector) collectBucket(ctx context.Context, bucket string, pending map[string]bool, stats *Stats) error {
+	marker := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		loi, err := c.alter.ListObjects(ctx, bucket, "", marker, "", listBatch)
+		if err != nil {
+			if _, ok := err.(minio.BucketNotFound); ok {
+				return nil
+			}
+
+			return err
+		}
+
+		for _, oi := range loi.Objects {
+			if strings.HasPrefix(oi.Name, c.quarantinePrefix) {
+				continue
+			}
+
+			stats.Scanned++
+
+			if err := c.collectObject(ctx, oi, pending, stats); err != nil {
+				return err
+			}
+		}
+
+		if !loi.IsTruncated || len(loi.Objects) == 0 {
+			return nil
+		}
+
+		marker = loi.NextMarker
+		if marker == "" {
+			marker = loi.Objects[len(loi.Objects)-1].Name
+		}
+	}
+}
+
+func (c *Collector) collectObject(ctx context.Context, oi minio.ObjectInfo, pending map[string]bool, stats *Stats) error {
+	_, err := c.prime.GetObjectInfo(ctx, oi.Bucket, oi.Name, minio.ObjectOptions{})
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(minio.ObjectNotFound); !ok {
+		return err
+	}
+
+	stats.Orphans++
+
+	if pending[key(oi.Bucket, oi.Name)] || c.now().Sub(oi.ModTime) < c.minAge {
+		stats.Skipped++
+		return nil
+	}
+
+	if err := c.remove(ctx, oi); err != nil {
+		return err
+	}
+
+	stats.Removed++
+
+	return nil
+}
+
+// remove deletes orphan from alter, orphan is copied under quarantine prefix first unless its deletion
+// on prime is recorded. Tombstone of removed orphan is dropped.
+func (c *Collector) remove(ctx context.Context, oi minio.ObjectInfo) error {
+	deleted, err := c.isDeleted(oi)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		quarantineKey := softdelete.TrashKey(c.quarantinePrefix, oi.Name, c.now())
+
+		if _, err := c.alter.CopyObject(ctx, oi.Bucket, oi.Name, oi.Bucket, quarantineKey, oi, minio.ObjectOptions{}, minio.ObjectOptions{}); err != nil {
+			return err
+		}
+
+		c.log(fmt.Sprintf("gc: orphan %s/%s quarantined as %s", oi.Bucket, oi.Name, quarantineKey))
+	} else {
+		c.log(fmt.Sprintf("gc: orphan %s/%s deleted on prime removed", oi.Bucket, oi.Name))
+	}
+
+	if err := c.alter.DeleteObject(ctx, oi.Bucket, oi.Name); err != nil {
+		return err
+	}
+
+	if deleted {
+		return c.state.Delete(oi.Bucket, oi.Name)
+	}
+
+	return nil
+}
+
+// isDeleted returns true if state records deletion of object on prime.
+func (c *Collector) isDeleted(oi minio.ObjectInfo) (bool, error) {
+	if c.state == nil {
+		return false, nil
+	}
+
+	r, ok, err := c.state.Get(oi.Bucket, oi.Name)
+
+	return ok && r.Status == state.DELETED, err
+}
+
+// pending returns set of objects with operations pending in journals.
+func (c *Collector) pending() (map[string]bool, error) {
+	pending := map[string]bool{}
+
+	for _, j := range c.journals {
+		entries, err := j.Entries(0)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			pending[key(e.Task.Bucket, e.Task.Object)] = true
+		}
+	}
+
+	return pending, nil
+}
+
+func key(bucket, object string) string {
+	return bucket + "/" + object
+}
+
+func (c *Collector) log(msg string) {
+	if c.logger != nil {
+		c.logger.Log(msg)
+	}
+}
+
+func (c *Collector) logE(err error) {
+	if c.logger != nil {
+		c.logger.LogE(err)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/state"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestCollector(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-2 * time.Hour)
+
+	// primeErr is returned by prime for objects which exist only on alter
+	newBackends := func(primeObjects []string, primeErr error, alterObjects []minio.ObjectInfo) (minio.ObjectLayer, minio.ObjectLayer, *[]string, *[]string) {
+		prime := test.NewProxyObjectLayer()
+		alter := test.NewProxyObjectLayer()
+
+		var copied, deleted []string
+
+		prime.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+			return []minio.BucketInfo{{Name: "bucket"}}, nil
+		}
+
+		prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			for _, o := range primeObjects {
+				if o == object {
+					return minio.ObjectInfo{Bucket: bucket, Name: object}, nil
+				}
+			}
+
+			if primeErr != nil {
+				return minio.ObjectInfo{}, primeErr
+			}
+
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		alter.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+			return minio.ListObjectsInfo{Objects: alterObjects}, nil
+		}
+
+		alter.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			copied = append(copied, destObject)
+			return minio.ObjectInfo{}, nil
+		}
+
+		alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+			deleted = append(deleted, object)
+			return nil
+		}
+
+		return prime, alter, &copied, &deleted
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Orphan quarantined by default, mirrored object kept",
+			func(t *testing.T) {
+				prime, alter, copied, deleted := newBackends([]string{"kept"}, nil, []minio.ObjectInfo{
+					{Bucket: "bucket", Name: "kept", ModTime: old},
+					{Bucket: "bucket", Name: "orphan", ModTime: old},
+				})
+
+				c := NewCollector(prime, alter, &config.GCOptions{}, nil)
+				c.now = func() time.Time { return now }
+
+				stats, err := c.Collect(context.Background())
+
+				assert.NoError(t, err)
+				assert.Equal(t, Stats{Scanned: 2, Orphans: 1, Removed: 1}, stats)
+				assert.Equal(t, []string{".orphans/20181001T120000Z/orphan"}, *copied)
+				assert.Equal(t, []string{"orphan"}, *deleted)
+			},
+		},
+		{
+			"Orphan deleted on prime removed, others quarantined",
+			func(t *testing.T) {
+				prime, alter, copied, deleted := newBackends(nil, nil, []minio.ObjectInfo{
+					{Bucket: "bucket", Name: "deleted", ModTime: old},
+					{Bucket: "bucket", Name: "failed", ModTime: old},
+				})
+
+				db, cleanup := openTestDB(t)
+				defer cleanup()
+
+				assert.NoError(t, db.Set("bucket", "deleted", state.DELETED, errors.New("alter is down")))
+				assert.NoError(t, db.Set("bucket", "failed", state.FAILED, errors.New("alter is down")))
+
+				c := NewCollector(prime, alter, &config.GCOptions{}, nil).WithState(db)
+				c.now = func() time.Time { return now }
+
+				stats, err := c.Collect(context.Background())
+
+				assert.NoError(t, err)
+				assert.Equal(t, Stats{Scanned: 2, Orphans: 2, Removed: 2}, stats)
+				assert.Equal(t, []string{".orphans/20181001T120000Z/failed"}, *copied)
+				assert.Equal(t, []string{"deleted", "failed"}, *deleted)
+
+				_, tracked, err := db.Get("bucket", "deleted")
+				assert.NoError(t, err)
+				assert.False(t, tracked)
+			},
+		},
+		{
+			"Orphan quarantined",
+			func(t *testing.T) {
+				prime, alter, copied, deleted := newBackends(nil, nil, []minio.ObjectInfo{
+					{Bucket: "bucket", Name: "orphan", ModTime: old},
+					{Bucket: "bucket", Name: ".orphans/20180101T000000Z/quarantined", ModTime: old},
+				})
+
+				c := NewCollector(prime, alter, &config.GCOptions{Quarantine: true}, nil)
+				c.now = func() time.Time { return now }
+
+				stats, err := c.Collect(context.Background())
+
+				assert.NoError(t, err)
+				assert.Equal(t, Stats{Scanned: 1, Orphans: 1, Removed: 1}, stats)
+				assert.Equal(t, []string{".orphans/20181001T120000Z/orphan"}, *copied)
+				assert.Equal(t, []string{"orphan"}, *deleted)
+			},
+		},
+		{
+			"Recent orphan skipped",
+			func(t *testing.T) {
+				prime, alter, _, deleted := newBackends(nil, nil, []minio.ObjectInfo{
+					{Bucket: "bucket", Name: "orphan", ModTime: now.Add(-time.Minute)},
+				})
+
+				c := NewCollector(prime, alter, &config.GCOptions{}, nil)
+				c.now = func() time.Time { return now }
+
+				stats, err := c.Collect(context.Background())
+
+				assert.NoError(t, err)
+				assert.Equal(t, Stats{Scanned: 1, Orphans: 1, Skipped: 1}, stats)
+				assert.Empty(t, *deleted)
+			},
+		},
+		{
+			"Prime error aborts collection",
+			func(t *testing.T) {
+				prime, alter, _, deleted := newBackends(nil, minio.OperationTimedOut{}, []minio.ObjectInfo{
+					{Bucket: "bucket", Name: "object", ModTime: old},
+				})
+
+				c := NewCollector(prime, alter, &config.GCOptions{}, nil)
+				c.now = func() time.Time { return now }
+
+				_, err := c.Collect(context.Background())
+
+				assert.Error(t, err)
+				assert.Empty(t, *deleted)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func openTestDB(t *testing.T) (*state.DB, func()) {
+	dir, err := ioutil.TempDir("", "gc-test")
+	assert.NoError(t, err)
+
+	db, err := state.Open(filepath.Join(dir, "state.db"))
+	assert.NoError(t, err)
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
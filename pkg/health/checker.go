@@ -0,0 +1,226 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"storj.io/ditto/pkg/config"
+	l "storj.io/ditto/pkg/logger"
+)
+
+const (
+	DefaultInterval = 10 * time.Second
+	DefaultTimeout  = 5 * time.Second
+)
+
+// Probe checks whether backend is reachable.
+type Probe func(ctx context.Context) error
+
+// ListBucketsProbe checks backend by listing buckets.
+func ListBucketsProbe(ol minio.ObjectLayer) Probe {
+	return func(ctx context.Context) error {
+		_, err := ol.ListBuckets(ctx)
+		return err
+	}
+}
+
+// BucketProbe checks backend by requesting bucket info, which is a HEAD bucket request for s3 backends.
+func BucketProbe(ol minio.ObjectLayer, bucket string) Probe {
+	return func(ctx context.Context) error {
+		_, err := ol.GetBucketInfo(ctx, bucket)
+		return err
+	}
+}
+
+// Status is a result of the last health check of a backend.
+type Status struct {
+	Backend             string        `json:"backend"`
+	Healthy             bool          `json:"healthy"`
+	LastCheck           time.Time     `json:"lastCheck"`
+	Latency             time.Duration `json:"latency"`
+	LastError           string        `json:"lastError,omitempty"`
+	ConsecutiveFailures int           `json:"consecutiveFailures"`
+}
+
+type target struct {
+	name   string
+	probe  Probe
+	report []func(error)
+}
+
+// Checker periodically probes registered backends.
+// Probe results are reported to subscribers such as failover controller or circuit breaker.
+type Checker struct {
+	interval, timeout time.Duration
+	logger            l.Logger
+
+	mu       sync.RWMutex
+	targets  []*target
+	statuses map[string]*Status
+}
+
+// Creates new Checker, zero options are replaced with defaults.
+func NewChecker(opts *config.HealthCheckOptions, logger l.Logger) *Checker {
+	c := &Checker{
+		interval: DefaultInterval,
+		timeout:  DefaultTimeout,
+		logger:   logger,
+		statuses: map[string]*Status{},
+	}
+
+	if opts != nil && opts.Interval > 0 {
+		c.interval = opts.Interval
+	}
+
+	if opts != nil && opts.Timeout > 0 {
+		c.timeout = opts.Timeout
+	}
+
+	return c
+}
+
+// AddTarget registers backend probe, every result is passed to report functions.
+// Backend is considered healthy until first check.
+func (c *Checker) AddTarget(name string, probe Probe, report ...func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.targets = append(c.targets, &target{name, probe, report})
+	c.statuses[name] = &Status{Backend: name, Healthy: true}
+}
+
+// Run checks all targets every interval until ctx is done.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.CheckAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll probes all targets concurrently and waits for results.
+func (c *Checker) CheckAll(ctx context.Context) {
+	c.mu.RLock()
+	targets := c.targets
+	c.mu.RUnlock()
+
+	var wg sync.WaitGroup
+
+	for _, t := range targets {
+		wg.Add(1)
+
+		go func(t *target) {
+			defer wg.Done()
+			c.check(ctx, t)
+		}(t)
+	}
+
+	wg.Wait()
+}
+
+func (c *Checker) check(ctx context.Context, t *target) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := t.probe(ctx)
+	latency := time.Since(start)
+
+	c.mu.Lock()
+	s := c.statuses[t.name]
+	wasHealthy := s.Healthy
+
+	s.LastCheck = start
+	s.Latency = latency
+	s.Healthy = err == nil
+	s.LastError = ""
+
+	if err != nil {
+		s.LastError = err.Error()
+		s.ConsecutiveFailures++
+	} else {
+		s.ConsecutiveFailures = 0
+	}
+	c.mu.Unlock()
+
+	if wasHealthy && err != nil {
+		c.logE(fmt.Errorf("%s is unhealthy: %s", t.name, err))
+	}
+
+	if !wasHealthy && err == nil {
+		c.log(fmt.Sprintf("%s is healthy again", t.name))
+	}
+
+	for _, report := range t.report {
+		report(err)
+	}
+}
+
+// Status returns copy of statuses of all targets in registration order.
+func (c *Checker) Status() []Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(c.targets))
+	for _, t := range c.targets {
+		statuses = append(statuses, *c.statuses[t.name])
+	}
+
+	return statuses
+}
+
+// Lookup returns status of target name, false if there is no such target.
+func (c *Checker) Lookup(name string) (Status, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s, ok := c.statuses[name]
+	if !ok {
+		return Status{}, false
+	}
+
+	return *s, true
+}
+
+// ServeHTTP writes statuses as JSON, responds with 503 if any backend is unhealthy.
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	statuses := c.Status()
+
+	code := http.StatusOK
+	for _, s := range statuses {
+		if !s.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(statuses)
+}
+
+func (c *Checker) log(msg string) {
+	if c.logger != nil {
+		c.logger.Log(msg)
+	}
+}
+
+func (c *Checker) logE(err error) {
+	if c.logger != nil {
+		c.logger.LogE(err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestChecker(t *testing.T) {
+	testErr := errors.New("test error")
+
+	lg := &test.MockLogger{}
+	c := NewChecker(nil, lg)
+
+	var primeErr error
+	var reported []error
+
+	c.AddTarget("prime", func(ctx context.Context) error { return primeErr }, func(err error) { reported = append(reported, err) })
+	c.AddTarget("alter", func(ctx context.Context) error { return nil })
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Healthy backends",
+			func(t *testing.T) {
+				c.CheckAll(context.Background())
+
+				statuses := c.Status()
+				assert.Equal(t, 2, len(statuses))
+				assert.Equal(t, true, statuses[0].Healthy)
+				assert.Equal(t, true, statuses[1].Healthy)
+				assert.Equal(t, []error{nil}, reported)
+				assert.Equal(t, 0, lg.LogECount())
+			},
+		},
+		{
+			"Unhealthy prime logged and reported",
+			func(t *testing.T) {
+				primeErr = testErr
+				c.CheckAll(context.Background())
+				c.CheckAll(context.Background())
+
+				s := c.Status()[0]
+				assert.Equal(t, false, s.Healthy)
+				assert.Equal(t, 2, s.ConsecutiveFailures)
+				assert.Equal(t, testErr.Error(), s.LastError)
+				assert.Equal(t, testErr, reported[len(reported)-1])
+				assert.Equal(t, 1, lg.LogECount())
+
+				rec := httptest.NewRecorder()
+				c.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+				assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+				var statuses []Status
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statuses))
+				assert.Equal(t, "prime", statuses[0].Backend)
+			},
+		},
+		{
+			"Recovered prime logged",
+			func(t *testing.T) {
+				primeErr = nil
+				c.CheckAll(context.Background())
+
+				assert.Equal(t, true, c.Status()[0].Healthy)
+				assert.Equal(t, 1, lg.LogCount())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Condition is a result of single readiness check, Reason explains why gateway isn't ready.
+type Condition struct {
+	Name   string `json:"name"`
+	Ready  bool   `json:"ready"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type readinessCheck struct {
+	name  string
+	check func() error
+}
+
+// Readiness tells whether gateway should receive traffic, e.g. as Kubernetes readiness probe.
+// Gateway is ready when all registered checks pass.
+type Readiness struct {
+	mu     sync.RWMutex
+	checks []readinessCheck
+}
+
+// Creates new Readiness without checks, which is always ready.
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// AddCheck registers check name, check returns error describing why gateway isn't ready.
+func (r *Readiness) AddCheck(name string, check func() error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, readinessCheck{name, check})
+}
+
+// Conditions runs all checks and returns their results in registration order.
+func (r *Readiness) Conditions() []Condition {
+	r.mu.RLock()
+	checks := r.checks
+	r.mu.RUnlock()
+
+	conditions := make([]Condition, 0, len(checks))
+	for _, c := range checks {
+		condition := Condition{Name: c.name, Ready: true}
+
+		if err := c.check(); err != nil {
+			condition.Ready = false
+			condition.Reason = err.Error()
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return conditions
+}
+
+// ServeHTTP writes conditions as JSON, responds with 503 unless all of them are ready.
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	conditions := r.Conditions()
+
+	code := http.StatusOK
+	for _, c := range conditions {
+		if !c.Ready {
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(conditions)
+}
+
+// Liveness responds with 200 as long as process serves HTTP, e.g. as Kubernetes liveness probe.
+// It deliberately ignores backends, so unavailable backend never gets gateway restarted.
+func Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok\n"))
+	})
+}
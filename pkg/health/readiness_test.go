@@ -0,0 +1,55 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	cases := []struct {
+		testName     string
+		seedErr      error
+		expectedCode int
+	}{
+		{"All checks pass", nil, http.StatusOK},
+		{"Failed check makes gateway unready", errors.New("seeding in progress"), http.StatusServiceUnavailable},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			r := NewReadiness()
+			r.AddCheck("backend", func() error { return nil })
+			r.AddCheck("seed", func() error { return c.seedErr })
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+
+			assert.Equal(t, c.expectedCode, rec.Code)
+
+			var conditions []Condition
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&conditions))
+			assert.Equal(t, 2, len(conditions))
+			assert.Equal(t, "seed", conditions[1].Name)
+			assert.Equal(t, c.seedErr == nil, conditions[1].Ready)
+
+			if c.seedErr != nil {
+				assert.Equal(t, c.seedErr.Error(), conditions[1].Reason)
+			}
+		})
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Liveness().ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
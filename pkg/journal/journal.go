@@ -0,0 +1,151 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"storj.io/ditto/pkg/replication"
+)
+
+var tasksBucket = []byte("tasks")
+
+// Entry is a task persisted in the journal.
+type Entry struct {
+	ID   uint64
+	Task replication.Task
+}
+
+// Journal is a persistent ordered log of alter operations which are yet to be applied.
+type Journal struct {
+	// n counts entries, so Len doesn't walk pages of the database on every status and readiness check.
+	// It's first, so it's aligned for atomic access on 32-bit platforms.
+	n int64
+
+	db *bolt.DB
+}
+
+// Open opens journal stored at path, file is created if it doesn't exist.
+func Open(path string) (*Journal, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{db: db}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(tasksBucket)
+		if err != nil {
+			return err
+		}
+
+		j.n = int64(b.Stats().KeyN)
+		return nil
+	})
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Append persists task at the end of the journal.
+func (j *Journal) Append(task replication.Task) error {
+	task.Attempts = 0
+
+	value, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	err = j.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tasksBucket)
+
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return b.Put(itob(id), value)
+	})
+
+	if err == nil {
+		atomic.AddInt64(&j.n, 1)
+	}
+
+	return err
+}
+
+// Remove deletes entry with id from the journal, removing entry which doesn't exist is a no-op.
+func (j *Journal) Remove(id uint64) error {
+	removed := false
+
+	err := j.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tasksBucket)
+
+		if b.Get(itob(id)) == nil {
+			return nil
+		}
+
+		removed = true
+		return b.Delete(itob(id))
+	})
+
+	if err == nil && removed {
+		atomic.AddInt64(&j.n, -1)
+	}
+
+	return err
+}
+
+// Len returns amount of entries in the journal.
+func (j *Journal) Len() int {
+	return int(atomic.LoadInt64(&j.n))
+}
+
+// Entries returns up to limit oldest entries, non-positive limit returns all entries.
+func (j *Journal) Entries(limit int) ([]Entry, error) {
+	var entries []Entry
+
+	err := j.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(tasksBucket).Cursor()
+
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if limit > 0 && len(entries) >= limit {
+				break
+			}
+
+			var task replication.Task
+			if err := json.Unmarshal(v, &task); err != nil {
+				return err
+			}
+
+			entries = append(entries, Entry{ID: binary.BigEndian.Uint64(k), Task: task})
+		}
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// Close closes underlying database.
+func (j *Journal) Close() error {
+	return j.db.Close()
+}
+
+// itob encodes id as big endian, so entries are iterated in order they were appended.
+func itob(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+
+	return b
+}
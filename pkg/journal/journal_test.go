@@ -0,0 +1,136 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package journal
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/replication"
+)
+
+func openTestJournal(t *testing.T) (*Journal, string, func()) {
+	dir, err := ioutil.TempDir("", "journal-test")
+	assert.NoError(t, err)
+
+	path := filepath.Join(dir, "journal.db")
+
+	j, err := Open(path)
+	assert.NoError(t, err)
+
+	return j, path, func() {
+		j.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	j, path, cleanup := openTestJournal(t)
+	defer cleanup()
+
+	assert.NoError(t, j.Append(replication.NewPutTask("bucket", "object1")))
+	assert.NoError(t, j.Append(replication.NewDeleteTask("bucket", "object2")))
+	assert.NoError(t, j.Append(replication.NewCopyTask("bucket", "object1", "bucket", "object3")))
+	assert.Equal(t, 3, j.Len())
+
+	entries, err := j.Entries(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "object1", entries[0].Task.Object)
+	assert.Equal(t, replication.DELETE, entries[1].Task.Operation)
+
+	assert.NoError(t, j.Remove(entries[0].ID))
+	assert.Equal(t, 2, j.Len())
+
+	// entry removed twice is counted once
+	assert.NoError(t, j.Remove(entries[0].ID))
+	assert.Equal(t, 2, j.Len())
+
+	// entries survive reopening
+	assert.NoError(t, j.Close())
+	j, err = Open(path)
+	assert.NoError(t, err)
+	defer j.Close()
+
+	assert.Equal(t, 2, j.Len())
+
+	entries, err = j.Entries(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "object2", entries[0].Task.Object)
+	assert.Equal(t, "object3", entries[1].Task.Object)
+}
+
+type handlerFunc func(ctx context.Context, task replication.Task) error
+
+func (f handlerFunc) Handle(ctx context.Context, task replication.Task) error {
+	return f(ctx, task)
+}
+
+func TestReplayer(t *testing.T) {
+	j, _, cleanup := openTestJournal(t)
+	defer cleanup()
+
+	for _, object := range []string{"object1", "object2", "object3"} {
+		assert.NoError(t, j.Append(replication.NewPutTask("bucket", object)))
+	}
+
+	var replayed []string
+	failOn := "object2"
+
+	r := NewReplayer(j, handlerFunc(func(ctx context.Context, task replication.Task) error {
+		if task.Object == failOn {
+			return errors.New("alter is down")
+		}
+
+		replayed = append(replayed, task.Object)
+		return nil
+	}), nil, 0)
+
+	assert.Error(t, r.Replay(context.Background()))
+	assert.Equal(t, []string{"object1"}, replayed)
+	assert.Equal(t, 2, j.Len())
+
+	failOn = ""
+
+	assert.NoError(t, r.Replay(context.Background()))
+	assert.Equal(t, []string{"object1", "object2", "object3"}, replayed)
+	assert.Equal(t, 0, j.Len())
+}
+
+func TestReplayerDropsPermanentFailures(t *testing.T) {
+	j, _, cleanup := openTestJournal(t)
+	defer cleanup()
+
+	for _, object := range []string{"object1", "object2", "object3"} {
+		assert.NoError(t, j.Append(replication.NewPutTask("bucket", object)))
+	}
+
+	permanent := errors.New("access denied")
+
+	var replayed, dropped []string
+
+	r := NewReplayer(j, handlerFunc(func(ctx context.Context, task replication.Task) error {
+		if task.Object == "object2" {
+			return permanent
+		}
+
+		replayed = append(replayed, task.Object)
+		return nil
+	}), nil, 0)
+
+	r.WithPermanent(func(err error) bool { return err == permanent }, func(task replication.Task, err error) {
+		dropped = append(dropped, task.Object)
+	})
+
+	assert.NoError(t, r.Replay(context.Background()))
+	assert.Equal(t, []string{"object1", "object3"}, replayed)
+	assert.Equal(t, []string{"object2"}, dropped)
+	assert.Equal(t, 0, j.Len())
+}
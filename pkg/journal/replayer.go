@@ -0,0 +1,125 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package journal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/replication"
+)
+
+const (
+	DefaultReplayInterval = 30 * time.Second
+	replayBatch           = 100
+)
+
+// Replayer periodically drains journal into alter.
+type Replayer struct {
+	journal  *Journal
+	handler  replication.Handler
+	logger   l.Logger
+	interval time.Duration
+
+	// ready reports whether alter is expected to accept operations, nil means always.
+	ready func() bool
+
+	// isPermanent decides which failures won't change on retry, nil means none
+	isPermanent func(err error) bool
+
+	// onDrop is called with entries removed from journal because they failed permanently
+	onDrop func(task replication.Task, err error)
+}
+
+// Creates new Replayer, zero interval is replaced with default.
+func NewReplayer(j *Journal, handler replication.Handler, logger l.Logger, interval time.Duration) *Replayer {
+	if interval <= 0 {
+		interval = DefaultReplayInterval
+	}
+
+	return &Replayer{journal: j, handler: handler, logger: logger, interval: interval}
+}
+
+// WithReadyCheck makes replayer skip rounds while ready returns false, e.g. while alter breaker is open.
+func (r *Replayer) WithReadyCheck(ready func() bool) *Replayer {
+	r.ready = ready
+
+	return r
+}
+
+// WithPermanent makes replayer drop entries failed with errors for which isPermanent returns true
+// and continue with the next ones, instead of stopping at them. Dropped entries are passed to onDrop.
+func (r *Replayer) WithPermanent(isPermanent func(err error) bool, onDrop func(task replication.Task, err error)) *Replayer {
+	r.isPermanent = isPermanent
+	r.onDrop = onDrop
+
+	return r
+}
+
+// Run replays journal every interval until ctx is done.
+func (r *Replayer) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.ready != nil && !r.ready() {
+				continue
+			}
+
+			if err := r.Replay(ctx); err != nil {
+				r.logE(err)
+			}
+		}
+	}
+}
+
+// Replay applies journal entries in order and removes applied ones.
+// Replay stops at first failed entry to preserve order of operations, failed entry is kept for next round,
+// unless it failed permanently, see WithPermanent.
+func (r *Replayer) Replay(ctx context.Context) error {
+	for {
+		entries, err := r.journal.Entries(replayBatch)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			return nil
+		}
+
+		for _, e := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := r.handler.Handle(ctx, e.Task); err != nil {
+				if ctx.Err() != nil || r.isPermanent == nil || !r.isPermanent(err) {
+					return fmt.Errorf("replay of %s failed: %s", e.Task, err)
+				}
+
+				r.logE(fmt.Errorf("replay of %s failed permanently, dropped: %s", e.Task, err))
+
+				if r.onDrop != nil {
+					r.onDrop(e.Task, err)
+				}
+			}
+
+			if err := r.journal.Remove(e.ID); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (r *Replayer) logE(err error) {
+	if r.logger != nil {
+		r.logger.LogE(err)
+	}
+}
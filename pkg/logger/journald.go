@@ -0,0 +1,85 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// JournaldSocket is socket of systemd journal accepting native protocol.
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// journaldPriority maps levels to syslog severities used by journald.
+var journaldPriority = map[Level]int{
+	DebugLevel: 7,
+	InfoLevel:  6,
+	WarnLevel:  4,
+	ErrorLevel: 3,
+}
+
+// Journald is a log sink sending entries to systemd journal with priority of their level.
+type Journald struct {
+	conn *net.UnixConn
+	addr *net.UnixAddr
+	tag  string
+}
+
+// NewJournald creates sink sending entries tagged with tag to journal listening on socket, usually JournaldSocket.
+func NewJournald(socket, tag string) (*Journald, error) {
+	// fail early if journal isn't running
+	if _, err := os.Stat(socket); err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Journald{conn: conn, addr: &net.UnixAddr{Name: socket, Net: "unixgram"}, tag: tag}, nil
+}
+
+// Write sends b at info priority.
+func (j *Journald) Write(b []byte) (int, error) {
+	return j.WriteLevel(InfoLevel, b)
+}
+
+// WriteLevel sends b at priority matching level.
+func (j *Journald) WriteLevel(level Level, b []byte) (int, error) {
+	if _, err := j.conn.WriteToUnix(journaldEntry(level, j.tag, strings.TrimSuffix(string(b), "\n")), j.addr); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+func (j *Journald) Close() error {
+	return j.conn.Close()
+}
+
+// journaldEntry encodes entry in native journal protocol, multi-line message is length prefixed.
+func journaldEntry(level Level, tag, msg string) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("PRIORITY=" + strconv.Itoa(journaldPriority[level]) + "\n")
+	if tag != "" {
+		buf.WriteString("SYSLOG_IDENTIFIER=" + tag + "\n")
+	}
+
+	if !strings.Contains(msg, "\n") {
+		buf.WriteString("MESSAGE=" + msg + "\n")
+		return buf.Bytes()
+	}
+
+	buf.WriteString("MESSAGE\n")
+	binary.Write(&buf, binary.LittleEndian, uint64(len(msg)))
+	buf.WriteString(msg + "\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import "storj.io/ditto/pkg/config"
+
+// Modules which level can be configured separately, see config.LogOptions.
+const (
+	MirroringModule   = "mirroring"
+	ReplicationModule = "replication"
+	SyncModule        = "sync"
+	CLIModule         = "cli"
+)
+
+// ForModule returns logger of module, which tags entries with module field and logs at level configured
+// for module by opts. Loggers which aren't Structured are returned unchanged.
+func ForModule(lg Logger, opts *config.LogOptions, module string) (Logger, error) {
+	s, ok := lg.(Structured)
+	if !ok {
+		return lg, nil
+	}
+
+	s = s.With(F("module", module))
+
+	if opts == nil {
+		return s, nil
+	}
+
+	var name string
+	switch module {
+	case MirroringModule:
+		name = opts.MirroringLevel
+	case ReplicationModule:
+		name = opts.ReplicationLevel
+	case SyncModule:
+		name = opts.SyncLevel
+	case CLIModule:
+		name = opts.CLILevel
+	}
+
+	if name == "" {
+		return s, nil
+	}
+
+	level, err := ParseLevel(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.WithLevel(level), nil
+}
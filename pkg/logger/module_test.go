@@ -0,0 +1,41 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+)
+
+func TestForModule(t *testing.T) {
+	buf := &bytes.Buffer{}
+	base, err := NewStructured(buf, WarnLevel, JSONFormat)
+	assert.NoError(t, err)
+
+	opts := &config.LogOptions{Level: "warn", ReplicationLevel: "debug"}
+
+	repl, err := ForModule(base, opts, ReplicationModule)
+	assert.NoError(t, err)
+	mirr, err := ForModule(base, opts, MirroringModule)
+	assert.NoError(t, err)
+
+	repl.(Structured).Debug("queued")
+	mirr.(Structured).Info("discarded")
+	mirr.(Structured).Warn("slow")
+
+	assert.Contains(t, buf.String(), `"msg":"queued","module":"replication"`)
+	assert.NotContains(t, buf.String(), "discarded")
+	assert.Contains(t, buf.String(), `"msg":"slow","module":"mirroring"`)
+
+	_, err = ForModule(base, &config.LogOptions{SyncLevel: "verbose"}, SyncModule)
+	assert.Error(t, err)
+
+	plain := &recordingLogger{}
+	lg, err := ForModule(plain, opts, CLIModule)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, lg)
+}
@@ -0,0 +1,37 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import "fmt"
+
+// WithPrefix returns logger prepending prefix to every message and error logged to l,
+// e.g. ID of request being processed. Nil l stays nil.
+func WithPrefix(l Logger, prefix string) Logger {
+	if l == nil {
+		return nil
+	}
+
+	return &prefixLogger{l, prefix}
+}
+
+type prefixLogger struct {
+	l      Logger
+	prefix string
+}
+
+func (p *prefixLogger) Log(msg string) {
+	if msg == "" {
+		return
+	}
+
+	p.l.Log(p.prefix + msg)
+}
+
+func (p *prefixLogger) LogE(err error) {
+	if err == nil {
+		return
+	}
+
+	p.l.LogE(fmt.Errorf("%s%s", p.prefix, err))
+}
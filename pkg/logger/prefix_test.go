@@ -0,0 +1,40 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	msgs []string
+	errs []error
+}
+
+func (r *recordingLogger) Log(msg string) {
+	r.msgs = append(r.msgs, msg)
+}
+
+func (r *recordingLogger) LogE(err error) {
+	r.errs = append(r.errs, err)
+}
+
+func TestWithPrefix(t *testing.T) {
+	r := &recordingLogger{}
+	l := WithPrefix(r, "[id] ")
+
+	l.Log("message")
+	l.LogE(errors.New("error"))
+	l.Log("")
+	l.LogE(nil)
+
+	assert.Equal(t, []string{"[id] message"}, r.msgs)
+	assert.Equal(t, 1, len(r.errs))
+	assert.Equal(t, "[id] error", r.errs[0].Error())
+
+	assert.Nil(t, WithPrefix(nil, "[id] "))
+}
@@ -0,0 +1,239 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is appended to name of rotated file, it sorts chronologically.
+const backupTimeFormat = "20060102T150405.000"
+
+// RotatingFile is a log file sink rotated once it grows over maxSize bytes or gets older than maxAge.
+// Rotated files are renamed to <path>.<time>, optionally gzipped, and only maxBackups newest are kept.
+// Zero maxSize, maxAge or maxBackups disables respective limit.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	// compressing tracks background compression of rotated files
+	compressing sync.WaitGroup
+
+	// now is overridden by tests
+	now func() time.Time
+}
+
+// NewRotatingFile opens or creates log file at path, appending to existing content.
+func NewRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int, compress bool) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+		compress:   compress,
+		now:        time.Now,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	r.file, r.size, r.opened = f, info.Size(), r.now()
+	if info.Size() > 0 {
+		r.opened = info.ModTime()
+	}
+
+	return nil
+}
+
+// Write appends b to current file, rotating it first if b would exceed size limit or file is too old.
+func (r *RotatingFile) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.size > 0 && r.shouldRotate(int64(len(b))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(b)
+	r.size += int64(n)
+
+	return n, err
+}
+
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.maxSize > 0 && r.size+n > r.maxSize {
+		return true
+	}
+
+	return r.maxAge > 0 && r.now().Sub(r.opened) >= r.maxAge
+}
+
+// Rotate closes current file, renames it to backup and opens new one.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return os.ErrClosed
+	}
+
+	return r.rotate()
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	backup := r.path + "." + r.now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(r.path, backup); err != nil {
+		return err
+	}
+
+	if err := r.open(); err != nil {
+		r.file = nil
+		return err
+	}
+
+	if r.compress {
+		r.compressing.Add(1)
+		go func() {
+			defer r.compressing.Done()
+
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "unable to compress %s: %s\n", backup, err)
+			}
+
+			r.removeOldBackups()
+		}()
+
+		return nil
+	}
+
+	r.removeOldBackups()
+
+	return nil
+}
+
+// backups returns rotated files, newest first.
+func (r *RotatingFile) backups() []string {
+	matches, err := filepath.Glob(r.path + ".*")
+	if err != nil {
+		return nil
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+
+	return matches
+}
+
+func (r *RotatingFile) removeOldBackups() {
+	if r.maxBackups <= 0 {
+		return
+	}
+
+	kept := 0
+	for _, b := range r.backups() {
+		// file being compressed is counted once, by its compressed copy
+		if !strings.HasSuffix(b, ".gz") {
+			if _, err := os.Stat(b + ".gz"); err == nil {
+				continue
+			}
+		}
+
+		kept++
+		if kept > r.maxBackups {
+			os.Remove(b)
+		}
+	}
+}
+
+// compressFile gzips path to path.gz and removes original.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// Close closes current file and waits for compression of rotated files.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.compressing.Wait()
+
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+
+	return err
+}
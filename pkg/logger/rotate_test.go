@@ -0,0 +1,131 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T, dir string)
+	}{
+		{
+			testName: "rotates by size and keeps max backups",
+			testFunc: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "ditto.log")
+				r, err := NewRotatingFile(path, 10, 0, 2, false)
+				assert.NoError(t, err)
+
+				now := time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)
+				r.now = func() time.Time { now = now.Add(time.Second); return now }
+
+				for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+					_, err = r.Write([]byte(line))
+					assert.NoError(t, err)
+				}
+				assert.NoError(t, r.Close())
+
+				b, err := ioutil.ReadFile(path)
+				assert.NoError(t, err)
+				assert.Equal(t, "fourth\n", string(b))
+
+				backups := r.backups()
+				assert.Equal(t, 2, len(backups))
+
+				b, err = ioutil.ReadFile(backups[0])
+				assert.NoError(t, err)
+				assert.Equal(t, "third\n", string(b))
+			},
+		},
+		{
+			testName: "rotates by age",
+			testFunc: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "ditto.log")
+				r, err := NewRotatingFile(path, 0, time.Hour, 0, false)
+				assert.NoError(t, err)
+
+				now := time.Now()
+				r.now = func() time.Time { return now }
+				r.opened = now
+
+				r.Write([]byte("old\n"))
+				now = now.Add(30 * time.Minute)
+				r.Write([]byte("still\n"))
+				assert.Equal(t, 0, len(r.backups()))
+
+				now = now.Add(time.Hour)
+				r.Write([]byte("new\n"))
+				assert.NoError(t, r.Close())
+
+				assert.Equal(t, 1, len(r.backups()))
+				b, _ := ioutil.ReadFile(path)
+				assert.Equal(t, "new\n", string(b))
+			},
+		},
+		{
+			testName: "compresses rotated files",
+			testFunc: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "ditto.log")
+				r, err := NewRotatingFile(path, 0, 0, 0, true)
+				assert.NoError(t, err)
+
+				r.Write([]byte("rotated\n"))
+				assert.NoError(t, r.Rotate())
+				assert.NoError(t, r.Close())
+
+				backups := r.backups()
+				assert.Equal(t, 1, len(backups))
+				assert.Equal(t, ".gz", filepath.Ext(backups[0]))
+
+				f, err := os.Open(backups[0])
+				assert.NoError(t, err)
+				defer f.Close()
+
+				gz, err := gzip.NewReader(f)
+				assert.NoError(t, err)
+				b, err := ioutil.ReadAll(gz)
+				assert.NoError(t, err)
+				assert.Equal(t, "rotated\n", string(b))
+			},
+		},
+		{
+			testName: "appends to existing file and fails after close",
+			testFunc: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "logs", "ditto.log")
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				assert.NoError(t, ioutil.WriteFile(path, []byte("existing\n"), 0644))
+
+				r, err := NewRotatingFile(path, 1<<20, 0, 0, false)
+				assert.NoError(t, err)
+				r.Write([]byte("appended\n"))
+				assert.NoError(t, r.Close())
+
+				_, err = r.Write([]byte("closed\n"))
+				assert.Error(t, err)
+
+				b, _ := ioutil.ReadFile(path)
+				assert.Equal(t, "existing\nappended\n", string(b))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "ditto-log")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			c.testFunc(t, dir)
+		})
+	}
+}
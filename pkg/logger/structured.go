@@ -0,0 +1,289 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/config"
+)
+
+// Level is severity of logged entry, entries below level of Structured logger are discarded.
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (lv Level) String() string {
+	if lv < DebugLevel || lv > ErrorLevel {
+		return fmt.Sprintf("level(%d)", int(lv))
+	}
+
+	return levelNames[lv]
+}
+
+// ParseLevel parses case insensitive name of level, e.g. "info".
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return Level(i), nil
+		}
+	}
+
+	return InfoLevel, fmt.Errorf("unknown log level %q, expected one of %s", name, strings.Join(levelNames, ", "))
+}
+
+// Encodings supported by NewStructured.
+const (
+	JSONFormat    = "json"
+	ConsoleFormat = "console"
+)
+
+// Field is a key-value pair attached to logged entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F is shorthand for Field{key, value}.
+func F(key string, value interface{}) Field {
+	return Field{key, value}
+}
+
+// Structured is a leveled Logger attaching fields to entries.
+// Log of plain Logger interface is logged at info and LogE at error level.
+type Structured interface {
+	Logger
+
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+
+	// With returns logger attaching fields to every entry in addition to fields of the receiver.
+	With(fields ...Field) Structured
+	// WithLevel returns logger discarding entries below level instead of level of the receiver.
+	WithLevel(level Level) Structured
+	// Enabled reports whether entries of level are logged.
+	Enabled(level Level) bool
+}
+
+// NewStructured creates logger writing entries of level and above to w,
+// either as JSON object per line or as human readable line if format is ConsoleFormat.
+func NewStructured(w io.Writer, level Level, format string) (Structured, error) {
+	switch format {
+	case JSONFormat, ConsoleFormat:
+	case "":
+		format = ConsoleFormat
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected %s or %s", format, JSONFormat, ConsoleFormat)
+	}
+
+	return &structured{out: &syncWriter{w: w}, level: level, json: format == JSONFormat}, nil
+}
+
+// New creates structured logger writing to stdout, journald, syslog or rotated file, as configured by opts.
+// Nil opts means defaults.
+func New(opts *config.LogOptions) (Structured, error) {
+	if opts == nil {
+		return NewStructured(os.Stdout, InfoLevel, ConsoleFormat)
+	}
+
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var w io.Writer = os.Stdout
+	switch {
+	case opts.Journald:
+		w, err = NewJournald(JournaldSocket, opts.SyslogTag)
+	case opts.Syslog != "":
+		w, err = NewSyslog(opts.Syslog, opts.SyslogTag)
+	case opts.File != "":
+		w, err = NewRotatingFile(opts.File, int64(opts.MaxSize)<<20, opts.MaxAge, opts.MaxBackups, opts.Compress)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStructured(w, level, opts.Format)
+}
+
+// syncWriter serializes writes of loggers derived with With.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Write(b)
+}
+
+func (s *syncWriter) WriteLevel(level Level, b []byte) (int, error) {
+	lw, ok := s.w.(LevelWriter)
+	if !ok {
+		return s.Write(b)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return lw.WriteLevel(level, b)
+}
+
+// LevelWriter is implemented by sinks which keep severity of entries, e.g. syslog.
+// Structured logger writes entries through WriteLevel if its writer implements it.
+type LevelWriter interface {
+	WriteLevel(level Level, b []byte) (int, error)
+}
+
+type structured struct {
+	out    io.Writer
+	level  Level
+	json   bool
+	fields []Field
+
+	// now is overridden by tests
+	now func() time.Time
+}
+
+func (s *structured) Log(msg string) {
+	if msg == "" {
+		return
+	}
+
+	s.Info(msg)
+}
+
+func (s *structured) LogE(err error) {
+	if err == nil {
+		return
+	}
+
+	s.Error(err.Error())
+}
+
+func (s *structured) Debug(msg string, fields ...Field) { s.log(DebugLevel, msg, fields) }
+func (s *structured) Info(msg string, fields ...Field)  { s.log(InfoLevel, msg, fields) }
+func (s *structured) Warn(msg string, fields ...Field)  { s.log(WarnLevel, msg, fields) }
+func (s *structured) Error(msg string, fields ...Field) { s.log(ErrorLevel, msg, fields) }
+
+func (s *structured) With(fields ...Field) Structured {
+	c := *s
+	c.fields = append(append([]Field{}, s.fields...), fields...)
+
+	return &c
+}
+
+func (s *structured) WithLevel(level Level) Structured {
+	c := *s
+	c.level = level
+
+	return &c
+}
+
+func (s *structured) Enabled(level Level) bool {
+	return level >= s.level
+}
+
+func (s *structured) log(level Level, msg string, fields []Field) {
+	if !s.Enabled(level) {
+		return
+	}
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+
+	all := append(append([]Field{}, s.fields...), fields...)
+
+	var buf bytes.Buffer
+	if s.json {
+		encodeJSON(&buf, now(), level, msg, all)
+	} else {
+		encodeConsole(&buf, now(), level, msg, all)
+	}
+
+	if lw, ok := s.out.(LevelWriter); ok {
+		lw.WriteLevel(level, buf.Bytes())
+		return
+	}
+
+	s.out.Write(buf.Bytes())
+}
+
+func encodeJSON(buf *bytes.Buffer, t time.Time, level Level, msg string, fields []Field) {
+	buf.WriteString(`{"time":`)
+	writeJSON(buf, t.UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSON(buf, level.String())
+	buf.WriteString(`,"msg":`)
+	writeJSON(buf, msg)
+
+	for _, f := range fields {
+		buf.WriteByte(',')
+		writeJSON(buf, f.Key)
+		buf.WriteByte(':')
+		writeJSON(buf, fieldValue(f.Value))
+	}
+
+	buf.WriteString("}\n")
+}
+
+func encodeConsole(buf *bytes.Buffer, t time.Time, level Level, msg string, fields []Field) {
+	fmt.Fprintf(buf, "%s %-5s %s", t.Format("2006-01-02T15:04:05.000Z07:00"), strings.ToUpper(level.String()), msg)
+
+	for _, f := range fields {
+		v := fieldValue(f.Value)
+		if s, ok := v.(string); ok && strings.ContainsAny(s, " \t\"=") {
+			v = fmt.Sprintf("%q", s)
+		}
+
+		fmt.Fprintf(buf, " %s=%v", f.Key, v)
+	}
+
+	buf.WriteByte('\n')
+}
+
+// fieldValue converts values which don't encode meaningfully to JSON, i.e. errors and durations, to strings.
+func fieldValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return v
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+
+	buf.Write(b)
+}
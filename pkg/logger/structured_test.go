@@ -0,0 +1,108 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStructured(t *testing.T, level Level, format string) (*structured, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	s, err := NewStructured(buf, level, format)
+	assert.NoError(t, err)
+
+	st := s.(*structured)
+	st.now = func() time.Time { return time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC) }
+
+	return st, buf
+}
+
+func TestStructured(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "JSON encodes fields",
+			testFunc: func(t *testing.T) {
+				s, buf := newTestStructured(t, InfoLevel, JSONFormat)
+
+				s.With(F("request_id", "abc")).Error("PutObject failed",
+					F("bucket", "b"), F("duration", 1500*time.Millisecond), F("error", errors.New("boom")), F("bytes", 10))
+
+				var entry map[string]interface{}
+				assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+				assert.Equal(t, "2018-10-01T12:00:00Z", entry["time"])
+				assert.Equal(t, "error", entry["level"])
+				assert.Equal(t, "PutObject failed", entry["msg"])
+				assert.Equal(t, "abc", entry["request_id"])
+				assert.Equal(t, "b", entry["bucket"])
+				assert.Equal(t, "1.5s", entry["duration"])
+				assert.Equal(t, "boom", entry["error"])
+				assert.Equal(t, float64(10), entry["bytes"])
+			},
+		},
+		{
+			testName: "console encodes fields",
+			testFunc: func(t *testing.T) {
+				s, buf := newTestStructured(t, InfoLevel, ConsoleFormat)
+
+				s.Warn("slow", F("key", "a b"), F("backend", "prime"))
+
+				assert.Equal(t, "2018-10-01T12:00:00.000Z WARN  slow key=\"a b\" backend=prime\n", buf.String())
+			},
+		},
+		{
+			testName: "entries below level are discarded",
+			testFunc: func(t *testing.T) {
+				s, buf := newTestStructured(t, WarnLevel, JSONFormat)
+
+				s.Debug("debug")
+				s.Info("info")
+				s.Log("log")
+				assert.Equal(t, 0, buf.Len())
+				assert.False(t, s.Enabled(InfoLevel))
+				assert.True(t, s.Enabled(ErrorLevel))
+
+				s.LogE(errors.New("err"))
+				assert.Contains(t, buf.String(), `"level":"error","msg":"err"`)
+			},
+		},
+		{
+			testName: "With doesn't modify receiver",
+			testFunc: func(t *testing.T) {
+				s, buf := newTestStructured(t, InfoLevel, ConsoleFormat)
+
+				s.With(F("a", 1))
+				s.Info("msg")
+
+				assert.Equal(t, "2018-10-01T12:00:00.000Z INFO  msg\n", buf.String())
+			},
+		},
+		{
+			testName: "invalid level and format",
+			testFunc: func(t *testing.T) {
+				_, err := ParseLevel("verbose")
+				assert.Error(t, err)
+
+				lv, err := ParseLevel("DEBUG")
+				assert.NoError(t, err)
+				assert.Equal(t, DebugLevel, lv)
+
+				_, err = NewStructured(&bytes.Buffer{}, InfoLevel, "xml")
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import "sync"
+
+// Swappable is Structured logger delegating to logger which can be replaced at runtime,
+// e.g. when level is reloaded from config. Loggers derived by With and WithLevel delegate
+// to logger current at the time they were derived.
+type Swappable struct {
+	mu sync.RWMutex
+	lg Structured
+}
+
+// NewSwappable creates logger delegating to lg until it's swapped.
+func NewSwappable(lg Structured) *Swappable {
+	return &Swappable{lg: lg}
+}
+
+// Swap replaces logger entries are delegated to.
+func (s *Swappable) Swap(lg Structured) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lg = lg
+}
+
+func (s *Swappable) current() Structured {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lg
+}
+
+func (s *Swappable) Log(msg string)                    { s.current().Log(msg) }
+func (s *Swappable) LogE(err error)                    { s.current().LogE(err) }
+func (s *Swappable) Debug(msg string, fields ...Field) { s.current().Debug(msg, fields...) }
+func (s *Swappable) Info(msg string, fields ...Field)  { s.current().Info(msg, fields...) }
+func (s *Swappable) Warn(msg string, fields ...Field)  { s.current().Warn(msg, fields...) }
+func (s *Swappable) Error(msg string, fields ...Field) { s.current().Error(msg, fields...) }
+
+func (s *Swappable) With(fields ...Field) Structured  { return s.current().With(fields...) }
+func (s *Swappable) WithLevel(level Level) Structured { return s.current().WithLevel(level) }
+func (s *Swappable) Enabled(level Level) bool         { return s.current().Enabled(level) }
@@ -0,0 +1,33 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwappable(t *testing.T) {
+	base, buf := newTestStructured(t, InfoLevel, ConsoleFormat)
+
+	s := NewSwappable(base)
+	s.Debug("hidden")
+	assert.Equal(t, "", buf.String())
+	assert.False(t, s.Enabled(DebugLevel))
+
+	s.Swap(base.WithLevel(DebugLevel))
+	s.Debug("shown")
+	assert.Contains(t, buf.String(), "DEBUG shown")
+	assert.True(t, s.Enabled(DebugLevel))
+
+	// derived logger isn't affected by later swaps
+	derived := s.With(F("module", "sync"))
+	s.Swap(base.WithLevel(ErrorLevel))
+	buf.Reset()
+
+	s.Info("hidden")
+	derived.Debug("shown")
+	assert.Equal(t, "2018-10-01T12:00:00.000Z DEBUG shown module=sync\n", buf.String())
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+// Syslog is a log sink sending entries to syslog with severity of their level.
+type Syslog struct {
+	w *syslog.Writer
+}
+
+// NewSyslog connects to syslog at address, either "local" for local daemon or network address
+// as <network>://<host>:<port>, e.g. udp://localhost:514. Entries are tagged with tag.
+func NewSyslog(address, tag string) (*Syslog, error) {
+	network, raddr := "", ""
+	if address != "local" {
+		i := strings.Index(address, "://")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid syslog address %q, expected local or <network>://<host>:<port>", address)
+		}
+
+		network, raddr = address[:i], address[i+3:]
+	}
+
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Syslog{w}, nil
+}
+
+// Write sends b at info severity.
+func (s *Syslog) Write(b []byte) (int, error) {
+	return s.WriteLevel(InfoLevel, b)
+}
+
+// WriteLevel sends b at severity matching level.
+func (s *Syslog) WriteLevel(level Level, b []byte) (int, error) {
+	msg := strings.TrimSuffix(string(b), "\n")
+
+	var err error
+	switch level {
+	case DebugLevel:
+		err = s.w.Debug(msg)
+	case WarnLevel:
+		err = s.w.Warning(msg)
+	case ErrorLevel:
+		err = s.w.Err(msg)
+	default:
+		err = s.w.Info(msg)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+func (s *Syslog) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package logger
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	s, err := NewSyslog("udp://"+conn.LocalAddr().String(), "ditto")
+	assert.NoError(t, err)
+	defer s.Close()
+
+	l, err := NewStructured(s, DebugLevel, ConsoleFormat)
+	assert.NoError(t, err)
+
+	l.Error("failed", F("bucket", "b"))
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+
+	msg := string(buf[:n])
+	// daemon facility (3) * 8 + err severity (3)
+	assert.True(t, strings.HasPrefix(msg, "<27>"), msg)
+	assert.Contains(t, msg, "ditto[")
+	assert.Contains(t, msg, "ERROR failed bucket=b")
+
+	_, err = NewSyslog("localhost:514", "ditto")
+	assert.Error(t, err)
+}
+
+func TestJournald(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-journald")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	j, err := NewJournald(socket, "ditto")
+	assert.NoError(t, err)
+	defer j.Close()
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	j.WriteLevel(WarnLevel, []byte("slow\n"))
+	n, err := conn.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "PRIORITY=4\nSYSLOG_IDENTIFIER=ditto\nMESSAGE=slow\n", string(buf[:n]))
+
+	j.Write([]byte("two\nlines\n"))
+	n, err = conn.Read(buf)
+	assert.NoError(t, err)
+
+	prefix := "PRIORITY=6\nSYSLOG_IDENTIFIER=ditto\nMESSAGE\n"
+	assert.Equal(t, prefix, string(buf[:len(prefix)]))
+	assert.Equal(t, uint64(len("two\nlines")), binary.LittleEndian.Uint64(buf[len(prefix):]))
+	assert.Equal(t, "two\nlines\n", string(buf[len(prefix)+8:n]))
+
+	_, err = NewJournald(filepath.Join(dir, "missing"), "ditto")
+	assert.Error(t, err)
+}
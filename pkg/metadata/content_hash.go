@@ -0,0 +1,27 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metadata
+
+import (
+	"encoding/hex"
+)
+
+// ContentHashKey is a metadata key of content hash stored by ditto with every mirrored object.
+// Unlike ETag, it doesn't depend on multipart layout nor encryption of the backend.
+const ContentHashKey = UserPrefix + "Ditto-Content-Hash"
+
+// ContentHash returns content hash of content with given sha256 sum, as "sha256:<hex>".
+func ContentHash(sum []byte) string {
+	return "sha256:" + hex.EncodeToString(sum)
+}
+
+// WithContentHash returns copy of metadata with content hash set.
+func WithContentHash(metadata map[string]string, contentHash string) map[string]string {
+	return Set(metadata, ContentHashKey, contentHash)
+}
+
+// GetContentHash returns content hash stored in metadata, or empty string.
+func GetContentHash(metadata map[string]string) string {
+	return Get(metadata, ContentHashKey)
+}
@@ -0,0 +1,23 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metadata
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentHash(t *testing.T) {
+	sum := sha256.Sum256([]byte("test"))
+	contentHash := "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	assert.Equal(t, contentHash, ContentHash(sum[:]))
+
+	md := WithContentHash(map[string]string{"x-amz-meta-ditto-content-hash": "stale", "Content-Type": "text/plain"}, contentHash)
+	assert.Equal(t, map[string]string{ContentHashKey: contentHash, "Content-Type": "text/plain"}, md)
+	assert.Equal(t, contentHash, GetContentHash(md))
+	assert.Equal(t, "", GetContentHash(nil))
+}
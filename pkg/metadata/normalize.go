@@ -0,0 +1,132 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metadata
+
+import (
+	"net/http"
+	"strings"
+)
+
+const UserPrefix = "X-Amz-Meta-"
+
+// reserved are response and request headers which some backends report as object metadata.
+// They describe a particular copy of an object, so they are never written nor compared.
+var reserved = map[string]bool{
+	"Accept-Ranges":              true,
+	"Connection":                 true,
+	"Content-Length":             true,
+	"Content-Security-Policy":    true,
+	"Date":                       true,
+	"Etag":                       true,
+	"Last-Modified":              true,
+	"Server":                     true,
+	"Strict-Transport-Security":  true,
+	"Vary":                       true,
+	"X-Amz-Bucket-Region":        true,
+	"X-Amz-Copy-Source-If-Match": true,
+	"X-Amz-Delete-Marker":        true,
+	"X-Amz-Expiration":           true,
+	"X-Amz-Id-2":                 true,
+	"X-Amz-Metadata-Directive":   true,
+	"X-Amz-Mp-Parts-Count":       true,
+	"X-Amz-Replication-Status":   true,
+	"X-Amz-Request-Id":           true,
+	"X-Amz-Restore":              true,
+	"X-Amz-Version-Id":           true,
+	"X-Minio-Deployment-Id":      true,
+	"X-Xss-Protection":           true,
+}
+
+// internalPrefixes are backend specific keys which are never written nor compared.
+var internalPrefixes = []string{
+	"X-Minio-Internal-",
+}
+
+// Normalize returns canonical copy of metadata: keys are canonical header keys,
+// values are trimmed, reserved and backend internal keys are removed.
+func Normalize(metadata map[string]string) map[string]string {
+	normalized := make(map[string]string, len(metadata))
+
+	for k, v := range metadata {
+		key := http.CanonicalHeaderKey(strings.TrimSpace(k))
+
+		if key == "" || key == UserPrefix || reserved[key] || isInternal(key) {
+			continue
+		}
+
+		normalized[key] = strings.TrimSpace(v)
+	}
+
+	return normalized
+}
+
+// Equal compares metadata after normalization.
+func Equal(a, b map[string]string) bool {
+	na, nb := Normalize(a), Normalize(b)
+
+	if len(na) != len(nb) {
+		return false
+	}
+
+	for k, v := range na {
+		if bv, ok := nb[k]; !ok || bv != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Get returns value of key regardless of its case, or empty string.
+func Get(metadata map[string]string, key string) string {
+	key = http.CanonicalHeaderKey(key)
+
+	for k, v := range metadata {
+		if http.CanonicalHeaderKey(k) == key {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// Set returns copy of metadata with key set to value, replacing key in any case.
+func Set(metadata map[string]string, key, value string) map[string]string {
+	key = http.CanonicalHeaderKey(key)
+
+	set := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		if http.CanonicalHeaderKey(k) != key {
+			set[k] = v
+		}
+	}
+
+	set[key] = value
+
+	return set
+}
+
+// Delete returns copy of metadata without key in any case.
+func Delete(metadata map[string]string, key string) map[string]string {
+	key = http.CanonicalHeaderKey(key)
+
+	deleted := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if http.CanonicalHeaderKey(k) != key {
+			deleted[k] = v
+		}
+	}
+
+	return deleted
+}
+
+func isInternal(key string) bool {
+	for _, prefix := range internalPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	md := map[string]string{
+		"x-amz-meta-owner":      " alice ",
+		"content-type":          "text/plain",
+		"ETag":                  "\"abc\"",
+		"X-Amz-Request-Id":      "123",
+		"X-Minio-Internal-Info": "internal",
+		"x-amz-meta-":           "empty",
+	}
+
+	assert.Equal(t, map[string]string{
+		"X-Amz-Meta-Owner": "alice",
+		"Content-Type":     "text/plain",
+	}, Normalize(md))
+
+	assert.Equal(t, map[string]string{}, Normalize(nil))
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal(
+		map[string]string{"x-amz-meta-owner": "alice", "Last-Modified": "yesterday"},
+		map[string]string{"X-Amz-Meta-Owner": "alice", "Last-Modified": "today"}))
+
+	assert.True(t, Equal(nil, map[string]string{"Etag": "abc"}))
+
+	assert.False(t, Equal(
+		map[string]string{"x-amz-meta-owner": "alice"},
+		map[string]string{"x-amz-meta-owner": "bob"}))
+
+	assert.False(t, Equal(
+		map[string]string{"x-amz-meta-owner": "alice"},
+		map[string]string{}))
+}
+
+func TestGetSet(t *testing.T) {
+	md := map[string]string{"content-type": "text/plain"}
+
+	assert.Equal(t, "text/plain", Get(md, "Content-Type"))
+	assert.Equal(t, "", Get(md, "Cache-Control"))
+
+	set := Set(md, "Content-Type", "application/json")
+	assert.Equal(t, map[string]string{"Content-Type": "application/json"}, set)
+	assert.Equal(t, map[string]string{"content-type": "text/plain"}, md)
+
+	assert.Equal(t, map[string]string{}, Delete(md, "Content-Type"))
+	assert.Equal(t, map[string]string{"content-type": "text/plain"}, Delete(md, "Cache-Control"))
+	assert.Equal(t, map[string]string{"content-type": "text/plain"}, md)
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/health"
+	"storj.io/ditto/pkg/objlayer/accounting"
+	"storj.io/ditto/pkg/objlayer/monitor"
+	"storj.io/ditto/pkg/replication"
+)
+
+// DefaultInterval is interval of sampling depth of replication queues.
+const DefaultInterval = 10 * time.Second
+
+// descriptions documents metrics reported by the gateway.
+var descriptions = map[string]string{
+	"backend_requests":         "Requests to backends.",
+	"backend_errors":           "Failed requests to backends.",
+	"backend_request_duration": "Latency of requests to backends.",
+	"backend_bytes":            "Object data written to or read from backends.",
+	"replication_queue_depth":  "Tasks waiting in replication queue.",
+	"replication_tasks":        "Replication tasks by result.",
+	"replication_lag":          "Time from enqueueing replication task until it finished.",
+	"bucket_uploaded_bytes":    "Object data uploaded to bucket on backend since accounting started.",
+	"bucket_downloaded_bytes":  "Object data downloaded from bucket on backend since accounting started.",
+	"bucket_stored_bytes":      "Size of objects stored in bucket on backend.",
+	"bucket_objects":           "Objects stored in bucket on backend.",
+	"backend_healthy":          "Whether last health check of backend succeeded, 1 if it did.",
+	"backend_probe_latency":    "Latency of last health check of backend in seconds.",
+}
+
+// Metrics reports gateway metrics to sinks:
+// requests, errors, latency and bytes per backend and operation,
+// depth of replication queues, replicated tasks and replication lag,
+// traffic and storage of buckets per backend and health of backends.
+type Metrics struct {
+	sinks []Sink
+
+	mu      sync.Mutex
+	queues  map[string]*replication.Queue
+	ledger  *accounting.Ledger
+	checker *health.Checker
+}
+
+// Creates new Metrics reporting to sinks.
+func New(sinks ...Sink) *Metrics {
+	for _, s := range sinks {
+		if d, ok := s.(Describer); ok {
+			for name, help := range descriptions {
+				d.Describe(name, help)
+			}
+		}
+	}
+
+	return &Metrics{sinks: sinks, queues: make(map[string]*replication.Queue)}
+}
+
+func (m *Metrics) count(name string, value int64, tags ...Tag) {
+	for _, s := range m.sinks {
+		s.Count(name, value, tags...)
+	}
+}
+
+func (m *Metrics) timing(name string, d time.Duration, tags ...Tag) {
+	for _, s := range m.sinks {
+		s.Timing(name, d, tags...)
+	}
+}
+
+func (m *Metrics) gauge(name string, value float64, tags ...Tag) {
+	for _, s := range m.sinks {
+		s.Gauge(name, value, tags...)
+	}
+}
+
+// Observer returns monitor hook recording operations of backend.
+func (m *Metrics) Observer(backend string) func(call monitor.Call) {
+	return func(call monitor.Call) {
+		tags := []Tag{{"backend", backend}, {"operation", call.Operation}}
+
+		m.count("backend_requests", 1, tags...)
+		m.timing("backend_request_duration", call.Duration, tags...)
+
+		if call.Err != nil {
+			m.count("backend_errors", 1, tags...)
+		}
+
+		if call.Bytes > 0 {
+			m.count("backend_bytes", call.Bytes, tags...)
+		}
+	}
+}
+
+// WatchQueue records results and lag of replication queue q named name.
+// Depth of queue is sampled by Run.
+func (m *Metrics) WatchQueue(name string, q *replication.Queue) {
+	m.mu.Lock()
+	m.queues[name] = q
+	m.mu.Unlock()
+
+	q.AddObserver(func(task replication.Task, lag time.Duration, err error) {
+		result := "replicated"
+		if err != nil {
+			result = "dropped"
+		}
+
+		m.count("replication_tasks", 1, Tag{"queue", name}, Tag{"operation", string(task.Operation)}, Tag{"result", result})
+		m.timing("replication_lag", lag, Tag{"queue", name})
+	})
+}
+
+// WatchLedger reports usage of buckets recorded by ledger, it's sampled by Run.
+func (m *Metrics) WatchLedger(ledger *accounting.Ledger) {
+	m.mu.Lock()
+	m.ledger = ledger
+	m.mu.Unlock()
+}
+
+// WatchChecker reports health and probe latency of backends checked by checker, they're sampled by Run.
+func (m *Metrics) WatchChecker(checker *health.Checker) {
+	m.mu.Lock()
+	m.checker = checker
+	m.mu.Unlock()
+}
+
+// Run samples depth of watched queues, usage of watched ledger and health of backends every interval until ctx is done.
+func (m *Metrics) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.sample()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Metrics) sample() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, q := range m.queues {
+		m.gauge("replication_queue_depth", float64(q.Len()), Tag{"queue", name})
+	}
+
+	if m.checker != nil {
+		for _, s := range m.checker.Status() {
+			healthy := 0.0
+			if s.Healthy {
+				healthy = 1
+			}
+
+			m.gauge("backend_healthy", healthy, Tag{"backend", s.Backend})
+
+			// backend isn't probed yet, it has no latency
+			if !s.LastCheck.IsZero() {
+				m.gauge("backend_probe_latency", s.Latency.Seconds(), Tag{"backend", s.Backend})
+			}
+		}
+	}
+
+	if m.ledger == nil {
+		return
+	}
+
+	// usage is persisted across restarts, so it's reported as gauges rather than counters starting from zero
+	for _, u := range m.ledger.Report() {
+		tags := []Tag{{"bucket", u.Bucket}, {"backend", u.Backend}}
+
+		m.gauge("bucket_uploaded_bytes", float64(u.Uploaded), tags...)
+		m.gauge("bucket_downloaded_bytes", float64(u.Downloaded), tags...)
+		m.gauge("bucket_stored_bytes", float64(u.Stored), tags...)
+		m.gauge("bucket_objects", float64(u.Objects), tags...)
+	}
+}
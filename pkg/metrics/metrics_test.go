@@ -0,0 +1,126 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/health"
+	"storj.io/ditto/pkg/objlayer/accounting"
+	"storj.io/ditto/pkg/objlayer/monitor"
+	"storj.io/ditto/pkg/replication"
+)
+
+type handlerFunc func(ctx context.Context, task replication.Task) error
+
+func (f handlerFunc) Handle(ctx context.Context, task replication.Task) error {
+	return f(ctx, task)
+}
+
+func TestMetrics(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Backend operations recorded",
+			testFunc: func(t *testing.T) {
+				p := NewPrometheus()
+				m := New(p)
+				observe := m.Observer("alter")
+
+				observe(monitor.Call{Operation: "PutObject", Duration: time.Millisecond, Bytes: 10})
+				observe(monitor.Call{Operation: "PutObject", Duration: time.Millisecond, Err: errors.New("test error")})
+
+				assert.Equal(t, float64(2), p.counters["backend_requests"].Value("alter", "PutObject"))
+				assert.Equal(t, float64(1), p.counters["backend_errors"].Value("alter", "PutObject"))
+				assert.Equal(t, float64(10), p.counters["backend_bytes"].Value("alter", "PutObject"))
+				assert.Equal(t, uint64(2), p.histograms["backend_request_duration"].Count("alter", "PutObject"))
+				assert.Equal(t, descriptions["backend_requests"], p.counters["backend_requests"].help)
+			},
+		},
+		{
+			testName: "Replication results recorded",
+			testFunc: func(t *testing.T) {
+				p := NewPrometheus()
+				m := New(p)
+
+				q := replication.NewQueue(handlerFunc(func(ctx context.Context, task replication.Task) error {
+					if task.Operation == replication.DELETE {
+						return errors.New("test error")
+					}
+					return nil
+				}), nil, 1, 10)
+
+				m.WatchQueue("replication", q)
+
+				assert.NoError(t, q.Enqueue(replication.NewPutTask("bucket", "object")))
+				assert.NoError(t, q.Enqueue(replication.NewDeleteTask("bucket", "object")))
+				q.Close()
+
+				m.sample()
+
+				assert.Equal(t, float64(1), p.counters["replication_tasks"].Value("replication", "put", "replicated"))
+				assert.Equal(t, float64(1), p.counters["replication_tasks"].Value("replication", "delete", "dropped"))
+				assert.Equal(t, uint64(2), p.histograms["replication_lag"].Count("replication"))
+				assert.Equal(t, float64(0), p.gauges["replication_queue_depth"].Value("replication"))
+			},
+		},
+		{
+			testName: "Bucket usage sampled",
+			testFunc: func(t *testing.T) {
+				p := NewPrometheus()
+				m := New(p)
+
+				ledger, err := accounting.Open(filepath.Join(os.TempDir(), "ditto-metrics-test-missing.json"))
+				assert.NoError(t, err)
+
+				ledger.Upload("bucket", "prime", 10)
+				ledger.Store("bucket", "prime", 10, 1)
+
+				m.WatchLedger(ledger)
+				m.sample()
+
+				assert.Equal(t, float64(10), p.gauges["bucket_uploaded_bytes"].Value("bucket", "prime"))
+				assert.Equal(t, float64(10), p.gauges["bucket_stored_bytes"].Value("bucket", "prime"))
+				assert.Equal(t, float64(1), p.gauges["bucket_objects"].Value("bucket", "prime"))
+			},
+		},
+		{
+			testName: "Backend health sampled",
+			testFunc: func(t *testing.T) {
+				p := NewPrometheus()
+				m := New(p)
+
+				checker := health.NewChecker(nil, nil)
+				checker.AddTarget("prime", func(ctx context.Context) error { return nil })
+				checker.AddTarget("alter", func(ctx context.Context) error { return errors.New("test error") })
+
+				m.WatchChecker(checker)
+
+				// backends are healthy until first check, latency isn't known yet
+				m.sample()
+				assert.Equal(t, float64(1), p.gauges["backend_healthy"].Value("alter"))
+				assert.Nil(t, p.gauges["backend_probe_latency"])
+
+				checker.CheckAll(context.Background())
+				m.sample()
+
+				assert.Equal(t, float64(1), p.gauges["backend_healthy"].Value("prime"))
+				assert.Equal(t, float64(0), p.gauges["backend_healthy"].Value("alter"))
+				assert.NotNil(t, p.gauges["backend_probe_latency"])
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Prometheus is a sink exposing metrics in Prometheus text format at ServeHTTP.
+// Metrics are named ditto_<name>, counters get _total suffix and timings become histograms in seconds.
+type Prometheus struct {
+	registry *Registry
+
+	mu         sync.Mutex
+	help       map[string]string
+	counters   map[string]*Counter
+	histograms map[string]*Histogram
+	gauges     map[string]*Gauge
+}
+
+// Creates new Prometheus sink with empty registry.
+func NewPrometheus() *Prometheus {
+	return &Prometheus{
+		registry:   NewRegistry(),
+		help:       make(map[string]string),
+		counters:   make(map[string]*Counter),
+		histograms: make(map[string]*Histogram),
+		gauges:     make(map[string]*Gauge),
+	}
+}
+
+// Describe sets help of metric name, should be called before it's reported first time.
+func (p *Prometheus) Describe(name, help string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.help[name] = help
+}
+
+func (p *Prometheus) Count(name string, value int64, tags ...Tag) {
+	p.mu.Lock()
+	c, ok := p.counters[name]
+	if !ok {
+		c = p.registry.NewCounter("ditto_"+name+"_total", p.helpOf(name), keys(tags)...)
+		p.counters[name] = c
+	}
+	p.mu.Unlock()
+
+	c.Add(float64(value), values(tags)...)
+}
+
+func (p *Prometheus) Timing(name string, d time.Duration, tags ...Tag) {
+	p.mu.Lock()
+	h, ok := p.histograms[name]
+	if !ok {
+		h = p.registry.NewHistogram("ditto_"+name+"_seconds", p.helpOf(name), DefaultBuckets, keys(tags)...)
+		p.histograms[name] = h
+	}
+	p.mu.Unlock()
+
+	h.Observe(d.Seconds(), values(tags)...)
+}
+
+func (p *Prometheus) Gauge(name string, value float64, tags ...Tag) {
+	p.mu.Lock()
+	g, ok := p.gauges[name]
+	if !ok {
+		g = p.registry.NewGauge("ditto_"+name, p.helpOf(name), keys(tags)...)
+		p.gauges[name] = g
+	}
+	p.mu.Unlock()
+
+	g.Set(value, values(tags)...)
+}
+
+// ServeHTTP writes all reported metrics.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.registry.ServeHTTP(w, r)
+}
+
+func (p *Prometheus) helpOf(name string) string {
+	if help, ok := p.help[name]; ok {
+		return help
+	}
+
+	return name
+}
+
+func keys(tags []Tag) []string {
+	k := make([]string, len(tags))
+	for i, t := range tags {
+		k[i] = t.Key
+	}
+
+	return k
+}
+
+func values(tags []Tag) []string {
+	v := make([]string, len(tags))
+	for i, t := range tags {
+		v[i] = t.Value
+	}
+
+	return v
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.NewCounter("test_total", "Test counter.", "backend")
+	c.Inc("prime")
+	c.Add(2, "alter")
+
+	g := r.NewGauge("test_depth", "Test gauge.")
+	g.Set(3)
+
+	h := r.NewHistogram("test_seconds", "Test histogram.", []float64{0.1, 1}, "backend")
+	h.Observe(0.05, "prime")
+	h.Observe(0.5, "prime")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	expected := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{backend="alter"} 2
+test_total{backend="prime"} 1
+# HELP test_depth Test gauge.
+# TYPE test_depth gauge
+test_depth 3
+# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{backend="prime",le="0.1"} 1
+test_seconds_bucket{backend="prime",le="1"} 2
+test_seconds_bucket{backend="prime",le="+Inf"} 2
+test_seconds_sum{backend="prime"} 0.55
+test_seconds_count{backend="prime"} 2
+`
+
+	assert.Equal(t, expected, rec.Body.String())
+	assert.Equal(t, "text/plain; version=0.0.4", rec.Header().Get("Content-Type"))
+}
@@ -0,0 +1,28 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metrics
+
+import "time"
+
+// Tag is a dimension of a metric, e.g. backend or operation.
+// Metrics of the same name are always reported with the same tag keys in the same order.
+type Tag struct {
+	Key, Value string
+}
+
+// Sink receives metrics of the gateway, e.g. Prometheus registry or statsd client.
+// Names are lowercase words separated by underscores, sinks add their own prefixes and units.
+type Sink interface {
+	// Count increases counter name by value.
+	Count(name string, value int64, tags ...Tag)
+	// Timing records single observed duration.
+	Timing(name string, d time.Duration, tags ...Tag)
+	// Gauge sets current value of name.
+	Gauge(name string, value float64, tags ...Tag)
+}
+
+// Describer is implemented by sinks which document metrics, e.g. Prometheus HELP lines.
+type Describer interface {
+	Describe(name, help string)
+}
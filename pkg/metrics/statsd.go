@@ -0,0 +1,99 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsD is a sink sending metrics to statsd over UDP, e.g. to Datadog agent.
+// Plain statsd has no tags, so tag values are appended to metric names, e.g. ditto.backend_requests.prime.PutObject.
+// With datadog enabled tags are sent in DogStatsD format instead.
+// Sending is fire-and-forget, errors are ignored, as usual for statsd.
+type StatsD struct {
+	prefix  string
+	datadog bool
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Creates new StatsD sink sending metrics to address with names prefixed by prefix.
+func NewStatsD(address, prefix string, datadog bool) (*StatsD, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+
+	return &StatsD{prefix: prefix, datadog: datadog, conn: conn}, nil
+}
+
+func (s *StatsD) Count(name string, value int64, tags ...Tag) {
+	s.send(name, fmt.Sprintf("%d|c", value), tags)
+}
+
+func (s *StatsD) Timing(name string, d time.Duration, tags ...Tag) {
+	s.send(name, fmt.Sprintf("%d|ms", d/time.Millisecond), tags)
+}
+
+func (s *StatsD) Gauge(name string, value float64, tags ...Tag) {
+	s.send(name, formatFloat(value)+"|g", tags)
+}
+
+// Close closes connection.
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsD) send(name, value string, tags []Tag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conn.Write([]byte(s.format(name, value, tags)))
+}
+
+func (s *StatsD) format(name, value string, tags []Tag) string {
+	name = s.prefix + sanitize(name)
+
+	if !s.datadog {
+		for _, t := range tags {
+			name += "." + sanitize(t.Value)
+		}
+
+		return name + ":" + value
+	}
+
+	line := name + ":" + value
+	for i, t := range tags {
+		if i == 0 {
+			line += "|#"
+		} else {
+			line += ","
+		}
+
+		line += sanitize(t.Key) + ":" + sanitize(t.Value)
+	}
+
+	return line
+}
+
+// sanitize replaces characters with special meaning in statsd protocol.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', '#', ',', ' ', '\n':
+			return '_'
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsD(t *testing.T) {
+	cases := []struct {
+		testName string
+		datadog  bool
+		send     func(s *StatsD)
+		expected string
+	}{
+		{
+			"Counter with tags in name",
+			false,
+			func(s *StatsD) {
+				s.Count("backend_requests", 1, Tag{"backend", "prime"}, Tag{"operation", "PutObject"})
+			},
+			"ditto.backend_requests.prime.PutObject:1|c",
+		},
+		{
+			"Timing in milliseconds",
+			false,
+			func(s *StatsD) { s.Timing("replication_lag", 1500*time.Millisecond) },
+			"ditto.replication_lag:1500|ms",
+		},
+		{
+			"Gauge with DogStatsD tags",
+			true,
+			func(s *StatsD) { s.Gauge("replication_queue_depth", 3, Tag{"queue", "replication"}) },
+			"ditto.replication_queue_depth:3|g|#queue:replication",
+		},
+		{
+			"Special characters sanitized",
+			true,
+			func(s *StatsD) { s.Count("backend_errors", 2, Tag{"backend", "a:b|c"}) },
+			"ditto.backend_errors:2|c|#backend:a_b_c",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			assert.NoError(t, err)
+			defer conn.Close()
+
+			s, err := NewStatsD(conn.LocalAddr().String(), "ditto", c.datadog)
+			assert.NoError(t, err)
+			defer s.Close()
+
+			c.send(s)
+
+			buf := make([]byte, 512)
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := conn.ReadFrom(buf)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, string(buf[:n]))
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/errclass"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/replication"
+)
+
+const (
+	DefaultTimeout = 5 * time.Second
+	DefaultRetries = 3
+	bufferSize     = 1000
+)
+
+// Event describes replication operation which was given up on.
+// Journaled operations are replayed later, others are lost until the next sync.
+// Class is class of Error, see errclass, e.g. config errors need attention of operator.
+type Event struct {
+	Time      time.Time             `json:"time"`
+	Bucket    string                `json:"bucket"`
+	Object    string                `json:"object"`
+	Operation replication.Operation `json:"operation"`
+	Backend   string                `json:"backend"`
+	Error     string                `json:"error"`
+	Class     string                `json:"class"`
+	Attempts  int                   `json:"attempts"`
+	Journaled bool                  `json:"journaled"`
+}
+
+// Webhook POSTs events as JSON to configured URL. Events are delivered in background,
+// failed deliveries are retried. Events are dropped if delivery falls too far behind.
+type Webhook struct {
+	url     string
+	client  *http.Client
+	retries int
+	logger  l.Logger
+
+	events chan Event
+	wg     sync.WaitGroup
+
+	sleep func(d time.Duration)
+}
+
+// Creates new Webhook and starts delivery, zero options are replaced with defaults.
+func NewWebhook(opts *config.WebhookOptions, logger l.Logger) *Webhook {
+	w := &Webhook{
+		url:     opts.URL,
+		client:  &http.Client{Timeout: DefaultTimeout},
+		retries: DefaultRetries,
+		logger:  logger,
+		events:  make(chan Event, bufferSize),
+		sleep:   time.Sleep,
+	}
+
+	if opts.Timeout > 0 {
+		w.client.Timeout = opts.Timeout
+	}
+
+	if opts.Retries > 0 {
+		w.retries = opts.Retries
+	}
+
+	w.wg.Add(1)
+	go w.deliver()
+
+	return w
+}
+
+// Notify sends event about task which failed on backend without blocking.
+func (w *Webhook) Notify(task replication.Task, backend string, err error, journaled bool) {
+	e := Event{
+		Time:      time.Now().UTC(),
+		Bucket:    task.Bucket,
+		Object:    task.Object,
+		Operation: task.Operation,
+		Backend:   backend,
+		Attempts:  task.Attempts,
+		Journaled: journaled,
+	}
+
+	if err != nil {
+		e.Error = err.Error()
+		e.Class = errclass.Classify(err).String()
+	}
+
+	select {
+	case w.events <- e:
+	default:
+		w.logE(fmt.Errorf("webhook: event %s %s/%s dropped, delivery is behind", e.Operation, e.Bucket, e.Object))
+	}
+}
+
+// Close delivers pending events and stops delivery.
+func (w *Webhook) Close() {
+	close(w.events)
+	w.wg.Wait()
+}
+
+func (w *Webhook) deliver() {
+	defer w.wg.Done()
+
+	for e := range w.events {
+		var err error
+
+		for attempt := 1; attempt <= w.retries; attempt++ {
+			if err = w.post(e); err == nil {
+				break
+			}
+
+			if attempt < w.retries {
+				w.sleep(time.Duration(attempt) * time.Second)
+			}
+		}
+
+		if err != nil {
+			w.logE(fmt.Errorf("webhook: event %s %s/%s not delivered: %s", e.Operation, e.Bucket, e.Object, err))
+		}
+	}
+}
+
+func (w *Webhook) post(e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (w *Webhook) logE(err error) {
+	if w.logger != nil {
+		w.logger.LogE(err)
+	}
+}
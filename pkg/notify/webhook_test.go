@@ -0,0 +1,79 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestWebhook(t *testing.T) {
+	cases := []struct {
+		testName          string
+		failures          int
+		expectedRequests  int
+		expectedDelivered bool
+	}{
+		{"Event delivered", 0, 1, true},
+		{"Failed delivery retried", 2, 3, true},
+		{"Undelivered event logged", 3, 3, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			requests := 0
+			var delivered *Event
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if requests <= c.failures {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				var e Event
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+				delivered = &e
+			}))
+			defer srv.Close()
+
+			logger := &test.MockLogger{}
+
+			w := NewWebhook(&config.WebhookOptions{URL: srv.URL, Retries: 3}, logger)
+			w.sleep = func(time.Duration) {}
+
+			task := replication.NewPutTask("bucket", "object")
+			task.Attempts = 3
+
+			w.Notify(task, "alter", errors.New("test error"), true)
+			w.Close()
+
+			assert.Equal(t, c.expectedRequests, requests)
+
+			if !c.expectedDelivered {
+				assert.Nil(t, delivered)
+				assert.Equal(t, 1, logger.LogECount())
+				return
+			}
+
+			assert.Equal(t, "bucket", delivered.Bucket)
+			assert.Equal(t, "object", delivered.Object)
+			assert.Equal(t, replication.PUT, delivered.Operation)
+			assert.Equal(t, "alter", delivered.Backend)
+			assert.Equal(t, "test error", delivered.Error)
+			assert.Equal(t, 3, delivered.Attempts)
+			assert.True(t, delivered.Journaled)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"context"
+	"io"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// NewAccountingLayer wraps backend named backend and records its traffic and storage per bucket to ledger.
+// Replaced and deleted objects are looked up before they are written, so their size is subtracted from stored size.
+// Server side copies are accounted as stored data, but not as traffic.
+func NewAccountingLayer(ol minio.ObjectLayer, ledger *Ledger, backend string) minio.ObjectLayer {
+	return &accountingLayer{ObjectLayer: ol, ledger: ledger, backend: backend}
+}
+
+type accountingLayer struct {
+	minio.ObjectLayer
+	ledger  *Ledger
+	backend string
+}
+
+// existing returns size of object, false if it doesn't exist or it can't be looked up.
+func (a *accountingLayer) existing(ctx context.Context, bucket, object string) (int64, bool) {
+	oi, err := a.ObjectLayer.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+	if err != nil {
+		return 0, false
+	}
+
+	return oi.Size, true
+}
+
+// stored records object of size replacing object of old size, if it existed.
+func (a *accountingLayer) stored(bucket string, size, old int64, existed bool) {
+	if existed {
+		a.ledger.Store(bucket, a.backend, size-old, 0)
+	} else {
+		a.ledger.Store(bucket, a.backend, size, 1)
+	}
+}
+
+func (a *accountingLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	counter := &countingWriter{w: writer}
+	err := a.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, counter, etag, opts)
+
+	// partially transferred data is traffic as well
+	if counter.n > 0 {
+		a.ledger.Download(bucket, a.backend, counter.n)
+	}
+
+	return err
+}
+
+func (a *accountingLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	old, existed := a.existing(ctx, bucket, object)
+
+	oi, err := a.ObjectLayer.PutObject(ctx, bucket, object, data, metadata, opts)
+	if err != nil {
+		return oi, err
+	}
+
+	size := data.Size()
+	if size < 0 {
+		size = oi.Size
+	}
+
+	a.ledger.Upload(bucket, a.backend, size)
+	a.stored(bucket, size, old, existed)
+
+	return oi, nil
+}
+
+func (a *accountingLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	old, existed := a.existing(ctx, destBucket, destObject)
+
+	oi, err := a.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+	if err != nil {
+		return oi, err
+	}
+
+	a.stored(destBucket, srcInfo.Size, old, existed)
+
+	return oi, nil
+}
+
+func (a *accountingLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	old, existed := a.existing(ctx, bucket, object)
+
+	if err := a.ObjectLayer.DeleteObject(ctx, bucket, object); err != nil {
+		return err
+	}
+
+	if existed {
+		a.ledger.Store(bucket, a.backend, -old, -1)
+	}
+
+	return nil
+}
+
+// countingWriter counts bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
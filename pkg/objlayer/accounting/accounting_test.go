@@ -0,0 +1,95 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// newStore creates object layer keeping content of objects of a single bucket in memory.
+func newStore() (minio.ObjectLayer, map[string][]byte) {
+	ol := test.NewProxyObjectLayer()
+	objects := map[string][]byte{}
+
+	ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, md map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		content, err := ioutil.ReadAll(data)
+		objects[object] = content
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content))}, err
+	}
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		content, ok := objects[object]
+		if !ok {
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content))}, nil
+	}
+
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		_, err := writer.Write(objects[object][startOffset : startOffset+length])
+		return err
+	}
+
+	ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		delete(objects, object)
+		return nil
+	}
+
+	return ol, objects
+}
+
+func put(t *testing.T, ol minio.ObjectLayer, object string, size int) {
+	data, err := hash.NewReader(bytes.NewReader(make([]byte, size)), int64(size), "", "")
+	assert.NoError(t, err)
+
+	_, err = ol.PutObject(context.Background(), "bucket", object, data, nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+}
+
+func TestAccountingLayer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-accounting")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "usage.json")
+
+	ledger, err := Open(path)
+	assert.NoError(t, err)
+
+	store, _ := newStore()
+	ol := NewAccountingLayer(store, ledger, "prime")
+
+	put(t, ol, "a", 10)
+	put(t, ol, "b", 20)
+	put(t, ol, "a", 5)
+
+	var buf bytes.Buffer
+	assert.NoError(t, ol.GetObject(context.Background(), "bucket", "b", 0, 15, &buf, "", minio.ObjectOptions{}))
+
+	assert.NoError(t, ol.DeleteObject(context.Background(), "bucket", "b"))
+
+	expected := []Usage{{Bucket: "bucket", Backend: "prime", Uploaded: 35, Downloaded: 15, Stored: 5, Objects: 1}}
+	assert.Equal(t, expected, ledger.Report())
+
+	assert.NoError(t, ledger.Flush())
+
+	reopened, err := Open(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, reopened.Report())
+
+	assert.Equal(t, 0, len(Filter(reopened.Report(), "other")))
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Usage is traffic and storage of a bucket on a backend.
+// Uploaded and Downloaded are bytes of object data transferred to and from backend,
+// Stored and Objects are total size and amount of objects written to backend through the gateway.
+type Usage struct {
+	Bucket     string `json:"bucket"`
+	Backend    string `json:"backend"`
+	Uploaded   int64  `json:"uploaded"`
+	Downloaded int64  `json:"downloaded"`
+	Stored     int64  `json:"stored"`
+	Objects    int64  `json:"objects"`
+}
+
+type usageKey struct {
+	bucket, backend string
+}
+
+// Ledger keeps usage of buckets per backend in memory and persists it to a JSON file,
+// so counters survive restarts of the gateway.
+type Ledger struct {
+	path string
+
+	mu    sync.Mutex
+	usage map[usageKey]*Usage
+	dirty bool
+}
+
+// Open opens ledger persisted at path, empty ledger is created if file doesn't exist.
+func Open(path string) (*Ledger, error) {
+	l := &Ledger{path: path, usage: make(map[usageKey]*Usage)}
+
+	report, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report {
+		u := report[i]
+		l.usage[usageKey{u.Bucket, u.Backend}] = &u
+	}
+
+	return l, nil
+}
+
+// Load reads usage persisted at path, missing file is an empty report.
+func Load(path string) ([]Usage, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var report []Usage
+	if err := json.Unmarshal(b, &report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// add applies f to usage of bucket on backend.
+func (l *Ledger) add(bucket, backend string, f func(u *Usage)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	k := usageKey{bucket, backend}
+
+	u, ok := l.usage[k]
+	if !ok {
+		u = &Usage{Bucket: bucket, Backend: backend}
+		l.usage[k] = u
+	}
+
+	f(u)
+	l.dirty = true
+}
+
+// Upload records n bytes transferred to bucket on backend.
+func (l *Ledger) Upload(bucket, backend string, n int64) {
+	l.add(bucket, backend, func(u *Usage) { u.Uploaded += n })
+}
+
+// Download records n bytes transferred from bucket on backend.
+func (l *Ledger) Download(bucket, backend string, n int64) {
+	l.add(bucket, backend, func(u *Usage) { u.Downloaded += n })
+}
+
+// Store records change of stored size and amount of objects of bucket on backend.
+func (l *Ledger) Store(bucket, backend string, bytes, objects int64) {
+	l.add(bucket, backend, func(u *Usage) {
+		u.Stored += bytes
+		u.Objects += objects
+	})
+}
+
+// Report returns usage of all buckets sorted by bucket and backend.
+func (l *Ledger) Report() []Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	report := make([]Usage, 0, len(l.usage))
+	for _, u := range l.usage {
+		report = append(report, *u)
+	}
+
+	Sort(report)
+
+	return report
+}
+
+// Sort sorts report by bucket and backend.
+func Sort(report []Usage) {
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Bucket != report[j].Bucket {
+			return report[i].Bucket < report[j].Bucket
+		}
+
+		return report[i].Backend < report[j].Backend
+	})
+}
+
+// Flush persists usage if it changed since last flush, file is replaced atomically.
+func (l *Ledger) Flush() error {
+	l.mu.Lock()
+	dirty := l.dirty
+	l.dirty = false
+	l.mu.Unlock()
+
+	if !dirty {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(l.Report(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := l.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, b, 0600); err == nil {
+		err = os.Rename(tmp, l.path)
+	}
+
+	if err != nil {
+		l.mu.Lock()
+		l.dirty = true
+		l.mu.Unlock()
+	}
+
+	return err
+}
+
+// Run flushes usage every interval until ctx is done, errors are passed to onError.
+func (l *Ledger) Run(ctx context.Context, interval time.Duration, onError func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := l.Flush(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// ServeHTTP responds with JSON report of usage, ?bucket=b limits it to a single bucket.
+func (l *Ledger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := Filter(l.Report(), r.URL.Query().Get("bucket"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// Filter returns usage of bucket from report, empty bucket returns whole report.
+func Filter(report []Usage, bucket string) []Usage {
+	if bucket == "" {
+		return report
+	}
+
+	filtered := []Usage{}
+	for _, u := range report {
+		if u.Bucket == bucket {
+			filtered = append(filtered, u)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,176 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	dcontext "storj.io/ditto/pkg/context"
+	l "storj.io/ditto/pkg/logger"
+)
+
+// NewAuditedLayer wraps object layer and writes record of every bucket and object operation to sink.
+// Backends of wrapped layer should be monitored with Observer, so records carry outcome on each of them.
+// Failures to write records are logged to logger.
+func NewAuditedLayer(ol minio.ObjectLayer, sink Sink, logger l.Logger) minio.ObjectLayer {
+	return &auditedLayer{ObjectLayer: ol, sink: sink, logger: logger}
+}
+
+type auditedLayer struct {
+	minio.ObjectLayer
+	sink   Sink
+	logger l.Logger
+}
+
+func (a *auditedLayer) record(ctx context.Context, o *outcomes, operation, bucket, object string, start time.Time, bytes int64, err error) {
+	r := Record{
+		Time:      start.UTC(),
+		Operation: operation,
+		Bucket:    bucket,
+		Object:    object,
+		Bytes:     bytes,
+		Duration:  time.Since(start),
+	}
+
+	r.User, _ = dcontext.UserFromContext(ctx)
+	r.RequestID, _ = dcontext.RequestIDFromContext(ctx)
+	r.Prime, r.Alter = o.get()
+
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	if werr := a.sink.Write(r); werr != nil && a.logger != nil {
+		a.logger.LogE(fmt.Errorf("unable to audit %s %s/%s: %s", operation, bucket, object, werr))
+	}
+}
+
+// countingWriter counts bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+func (a *auditedLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	err := a.ObjectLayer.MakeBucketWithLocation(ctx, bucket, location)
+	a.record(ctx, o, "MakeBucketWithLocation", bucket, "", start, 0, err)
+
+	return err
+}
+
+func (a *auditedLayer) GetBucketInfo(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	bi, err := a.ObjectLayer.GetBucketInfo(ctx, bucket)
+	a.record(ctx, o, "GetBucketInfo", bucket, "", start, 0, err)
+
+	return bi, err
+}
+
+func (a *auditedLayer) ListBuckets(ctx context.Context) ([]minio.BucketInfo, error) {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	buckets, err := a.ObjectLayer.ListBuckets(ctx)
+	a.record(ctx, o, "ListBuckets", "", "", start, 0, err)
+
+	return buckets, err
+}
+
+func (a *auditedLayer) DeleteBucket(ctx context.Context, bucket string) error {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	err := a.ObjectLayer.DeleteBucket(ctx, bucket)
+	a.record(ctx, o, "DeleteBucket", bucket, "", start, 0, err)
+
+	return err
+}
+
+func (a *auditedLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	loi, err := a.ObjectLayer.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	a.record(ctx, o, "ListObjects", bucket, prefix, start, 0, err)
+
+	return loi, err
+}
+
+func (a *auditedLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	loi, err := a.ObjectLayer.ListObjectsV2(ctx, bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+	a.record(ctx, o, "ListObjectsV2", bucket, prefix, start, 0, err)
+
+	return loi, err
+}
+
+func (a *auditedLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	ctx, o := withOutcomes(ctx)
+	cw := &countingWriter{w: writer}
+	start := time.Now()
+	err := a.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, cw, etag, opts)
+	a.record(ctx, o, "GetObject", bucket, object, start, cw.n, err)
+
+	return err
+}
+
+func (a *auditedLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	oi, err := a.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+	a.record(ctx, o, "GetObjectInfo", bucket, object, start, 0, err)
+
+	return oi, err
+}
+
+func (a *auditedLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	oi, err := a.ObjectLayer.PutObject(ctx, bucket, object, data, metadata, opts)
+	a.record(ctx, o, "PutObject", bucket, object, start, data.Size(), err)
+
+	return oi, err
+}
+
+func (a *auditedLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	oi, err := a.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+	a.record(ctx, o, "CopyObject", destBucket, destObject, start, srcInfo.Size, err)
+
+	return oi, err
+}
+
+func (a *auditedLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	ctx, o := withOutcomes(ctx)
+	start := time.Now()
+	err := a.ObjectLayer.DeleteObject(ctx, bucket, object)
+	a.record(ctx, o, "DeleteObject", bucket, object, start, 0, err)
+
+	return err
+}
+
+// Shutdown closes sink after wrapped layer is shut down.
+func (a *auditedLayer) Shutdown(ctx context.Context) error {
+	err := a.ObjectLayer.Shutdown(ctx)
+
+	if serr := a.sink.Close(); err == nil {
+		err = serr
+	}
+
+	return err
+}
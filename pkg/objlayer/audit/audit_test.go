@@ -0,0 +1,178 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/objlayer/monitor"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+type memorySink struct {
+	records []Record
+	err     error
+	closed  bool
+}
+
+func (s *memorySink) Write(r Record) error {
+	s.records = append(s.records, r)
+	return s.err
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+// newMirror returns layer calling prime and alter monitored with Observer, as mirroring does.
+func newMirror(prime, alter minio.ObjectLayer) minio.ObjectLayer {
+	p := monitor.NewMonitoredLayer(prime, monitor.Hooks{Observe: Observer(Prime)})
+	a := monitor.NewMonitoredLayer(alter, monitor.Hooks{Observe: Observer(Alter)})
+
+	mirror := test.NewProxyObjectLayer()
+	mirror.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		if err := p.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts); err == nil {
+			return nil
+		}
+
+		return a.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+	}
+	mirror.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		err := p.DeleteObject(ctx, bucket, object)
+		Report(ctx, events.Result(err), events.Deferred)
+
+		return err
+	}
+
+	return mirror
+}
+
+func TestAuditedLayer(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "read served by alter after prime failure",
+			testFunc: func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				alter := test.NewProxyObjectLayer()
+
+				prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					return errors.New("prime error")
+				}
+				alter.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					_, err := writer.Write([]byte("data"))
+					return err
+				}
+
+				sink := &memorySink{}
+				ol := NewAuditedLayer(newMirror(prime, alter), sink, &test.MockLogger{})
+
+				ctx := dcontext.WithUser(dcontext.WithRequestID(context.Background(), "id"), "AKID")
+				err := ol.GetObject(ctx, "bucket", "object", 0, -1, ioutil.Discard, "", minio.ObjectOptions{})
+				assert.NoError(t, err)
+
+				assert.Equal(t, 1, len(sink.records))
+				r := sink.records[0]
+				assert.Equal(t, "AKID", r.User)
+				assert.Equal(t, "id", r.RequestID)
+				assert.Equal(t, "GetObject", r.Operation)
+				assert.Equal(t, "bucket", r.Bucket)
+				assert.Equal(t, "object", r.Object)
+				assert.Equal(t, int64(4), r.Bytes)
+				assert.Equal(t, "", r.Error)
+				assert.Equal(t, events.Outcome{Status: events.FAILED, Error: "prime error"}, r.Prime)
+				assert.Equal(t, events.Outcome{Status: events.OK}, r.Alter)
+			},
+		},
+		{
+			testName: "reported outcomes take precedence",
+			testFunc: func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				prime.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					return nil
+				}
+
+				sink := &memorySink{}
+				ol := NewAuditedLayer(newMirror(prime, test.NewProxyObjectLayer()), sink, nil)
+
+				assert.NoError(t, ol.DeleteObject(context.Background(), "bucket", "object"))
+
+				assert.Equal(t, 1, len(sink.records))
+				assert.Equal(t, events.OK, sink.records[0].Prime.Status)
+				assert.Equal(t, events.DEFERRED, sink.records[0].Alter.Status)
+			},
+		},
+		{
+			testName: "backends not called are skipped and sink failure is logged",
+			testFunc: func(t *testing.T) {
+				mirror := test.NewProxyObjectLayer()
+				mirror.GetBucketInfoFunc = func(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+					return minio.BucketInfo{}, errors.New("refused")
+				}
+
+				sink := &memorySink{err: errors.New("disk full")}
+				logger := &test.MockLogger{}
+				ol := NewAuditedLayer(mirror, sink, logger)
+
+				_, err := ol.GetBucketInfo(context.Background(), "bucket")
+				assert.Error(t, err)
+
+				assert.Equal(t, 1, len(sink.records))
+				assert.Equal(t, "refused", sink.records[0].Error)
+				assert.Equal(t, events.Skipped, sink.records[0].Prime)
+				assert.Equal(t, events.Skipped, sink.records[0].Alter)
+				assert.Equal(t, 1, logger.LogECount())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	for _, op := range []string{"PutObject", "DeleteObject"} {
+		sink, err := NewFileSink(path)
+		assert.NoError(t, err)
+		assert.NoError(t, sink.Write(Record{Operation: op, Bucket: "bucket"}))
+		assert.NoError(t, sink.Close())
+		assert.Error(t, sink.Write(Record{}))
+	}
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var ops []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		ops = append(ops, r.Operation)
+	}
+
+	assert.Equal(t, "PutObject,DeleteObject", strings.Join(ops, ","))
+}
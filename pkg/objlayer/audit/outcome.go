@@ -0,0 +1,91 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/objlayer/monitor"
+)
+
+// Backends as named by Observer.
+const (
+	Prime = "prime"
+	Alter = "alter"
+)
+
+type outcomesKey struct{}
+
+// outcomes collects results of backend calls made while serving single audited operation.
+type outcomes struct {
+	mu           sync.Mutex
+	prime, alter events.Outcome
+	reported     bool
+}
+
+func withOutcomes(ctx context.Context) (context.Context, *outcomes) {
+	o := &outcomes{prime: events.Skipped, alter: events.Skipped}
+
+	return context.WithValue(ctx, outcomesKey{}, o), o
+}
+
+func outcomesFromContext(ctx context.Context) *outcomes {
+	if ctx == nil {
+		return nil
+	}
+
+	o, _ := ctx.Value(outcomesKey{}).(*outcomes)
+
+	return o
+}
+
+func (o *outcomes) get() (prime, alter events.Outcome) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.prime, o.alter
+}
+
+// Report sets outcomes of operation executed with ctx on both backends, e.g. to tell deferred write from
+// skipped one. Reported outcomes take precedence over those observed by Observer.
+func Report(ctx context.Context, prime, alter events.Outcome) {
+	o := outcomesFromContext(ctx)
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.prime, o.alter, o.reported = prime, alter, true
+}
+
+// Observer returns monitor hook recording outcomes of calls to backend, which is either Prime or Alter.
+// Operation failed on backend if any of its calls failed, backends which weren't called are skipped.
+func Observer(backend string) func(monitor.Call) {
+	return func(call monitor.Call) {
+		o := outcomesFromContext(call.Context)
+		if o == nil {
+			return
+		}
+
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		if o.reported {
+			return
+		}
+
+		outcome := &o.prime
+		if backend == Alter {
+			outcome = &o.alter
+		}
+
+		if outcome.Status != events.FAILED {
+			*outcome = events.Result(call.Err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/events"
+)
+
+// Record describes single S3 operation: who issued it, when, what it touched and its outcome on both backends.
+// User is access key of the caller, if known. Object is empty for bucket operations and holds prefix for listings.
+type Record struct {
+	Time      time.Time      `json:"time"`
+	User      string         `json:"user,omitempty"`
+	RequestID string         `json:"requestId,omitempty"`
+	Operation string         `json:"operation"`
+	Bucket    string         `json:"bucket,omitempty"`
+	Object    string         `json:"object,omitempty"`
+	Bytes     int64          `json:"bytes"`
+	Duration  time.Duration  `json:"duration"`
+	Error     string         `json:"error,omitempty"`
+	Prime     events.Outcome `json:"prime"`
+	Alter     events.Outcome `json:"alter"`
+}
+
+// Sink stores audit records.
+type Sink interface {
+	Write(r Record) error
+	Close() error
+}
+
+// FileSink appends records to a file as JSON object per line. File is never truncated nor rotated
+// and every record is synced to disk before Write returns.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileSink opens or creates audit log at path, readable only by its owner.
+func NewFileSink(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (s *FileSink) Write(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return os.ErrClosed
+	}
+
+	if err := s.enc.Encode(r); err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package bucketmap
+
+import (
+	"context"
+	"io"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// NewBucketMappingLayer wraps object layer so that all bucket names are
+// translated with mapper before the call and translated back in results.
+func NewBucketMappingLayer(ol minio.ObjectLayer, mapper *Mapper) minio.ObjectLayer {
+	return &bucketMappingLayer{ol: ol, mapper: mapper}
+}
+
+type bucketMappingLayer struct {
+	minio.GatewayUnsupported
+	ol     minio.ObjectLayer
+	mapper *Mapper
+}
+
+func (b *bucketMappingLayer) Shutdown(ctx context.Context) error {
+	return b.ol.Shutdown(ctx)
+}
+
+func (b *bucketMappingLayer) StorageInfo(ctx context.Context) minio.StorageInfo {
+	return b.ol.StorageInfo(ctx)
+}
+
+func (b *bucketMappingLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	return b.ol.MakeBucketWithLocation(ctx, b.mapper.ToAlter(bucket), location)
+}
+
+func (b *bucketMappingLayer) GetBucketInfo(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+	bi, err := b.ol.GetBucketInfo(ctx, b.mapper.ToAlter(bucket))
+	if err == nil {
+		bi.Name = b.mapper.ToPrime(bi.Name)
+	}
+
+	return bi, err
+}
+
+func (b *bucketMappingLayer) ListBuckets(ctx context.Context) ([]minio.BucketInfo, error) {
+	buckets, err := b.ol.ListBuckets(ctx)
+
+	for i := range buckets {
+		buckets[i].Name = b.mapper.ToPrime(buckets[i].Name)
+	}
+
+	return buckets, err
+}
+
+func (b *bucketMappingLayer) DeleteBucket(ctx context.Context, bucket string) error {
+	return b.ol.DeleteBucket(ctx, b.mapper.ToAlter(bucket))
+}
+
+func (b *bucketMappingLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	loi, err := b.ol.ListObjects(ctx, b.mapper.ToAlter(bucket), prefix, marker, delimiter, maxKeys)
+	b.unmapObjects(loi.Objects)
+
+	return loi, err
+}
+
+func (b *bucketMappingLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	loi, err := b.ol.ListObjectsV2(ctx, b.mapper.ToAlter(bucket), prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+	b.unmapObjects(loi.Objects)
+
+	return loi, err
+}
+
+func (b *bucketMappingLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	return b.ol.GetObject(ctx, b.mapper.ToAlter(bucket), object, startOffset, length, writer, etag, opts)
+}
+
+func (b *bucketMappingLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	oi, err := b.ol.GetObjectInfo(ctx, b.mapper.ToAlter(bucket), object, opts)
+	if err == nil {
+		oi.Bucket = bucket
+	}
+
+	return oi, err
+}
+
+func (b *bucketMappingLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	oi, err := b.ol.PutObject(ctx, b.mapper.ToAlter(bucket), object, data, metadata, opts)
+	if err == nil {
+		oi.Bucket = bucket
+	}
+
+	return oi, err
+}
+
+func (b *bucketMappingLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	srcInfo.Bucket = b.mapper.ToAlter(srcBucket)
+
+	oi, err := b.ol.CopyObject(ctx, srcInfo.Bucket, srcObject, b.mapper.ToAlter(destBucket), destObject, srcInfo, srcOpts, dstOpts)
+	if err == nil {
+		oi.Bucket = destBucket
+	}
+
+	return oi, err
+}
+
+func (b *bucketMappingLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	return b.ol.DeleteObject(ctx, b.mapper.ToAlter(bucket), object)
+}
+
+func (b *bucketMappingLayer) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+	return b.ol.NewMultipartUpload(ctx, b.mapper.ToAlter(bucket), object, metadata, opts)
+}
+
+func (b *bucketMappingLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+	return b.ol.PutObjectPart(ctx, b.mapper.ToAlter(bucket), object, uploadID, partID, data, opts)
+}
+
+func (b *bucketMappingLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListPartsInfo, error) {
+	result, err := b.ol.ListObjectParts(ctx, b.mapper.ToAlter(bucket), object, uploadID, partNumberMarker, maxParts)
+	if err == nil {
+		result.Bucket = bucket
+	}
+
+	return result, err
+}
+
+func (b *bucketMappingLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error {
+	return b.ol.AbortMultipartUpload(ctx, b.mapper.ToAlter(bucket), object, uploadID)
+}
+
+func (b *bucketMappingLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	oi, err := b.ol.CompleteMultipartUpload(ctx, b.mapper.ToAlter(bucket), object, uploadID, uploadedParts, opts)
+	if err == nil {
+		oi.Bucket = bucket
+	}
+
+	return oi, err
+}
+
+func (b *bucketMappingLayer) unmapObjects(objects []minio.ObjectInfo) {
+	for i := range objects {
+		objects[i].Bucket = b.mapper.ToPrime(objects[i].Bucket)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package bucketmap
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestBucketMappingLayer(t *testing.T) {
+	alter := test.NewProxyObjectLayer()
+	mapper := NewMapper(&config.BucketMappingOptions{Mapping: map[string]string{"photos": "acme-photos-mirror"}})
+	ol := NewBucketMappingLayer(alter, mapper)
+
+	var calledWith string
+
+	alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		calledWith = bucket
+		return nil
+	}
+
+	alter.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		return []minio.BucketInfo{{Name: "acme-photos-mirror"}, {Name: "other"}}, nil
+	}
+
+	alter.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		calledWith = srcBucket + ":" + destBucket
+		return minio.ObjectInfo{Bucket: destBucket, Name: destObject}, nil
+	}
+
+	err := ol.DeleteObject(context.Background(), "photos", "object")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme-photos-mirror", calledWith)
+
+	buckets, err := ol.ListBuckets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "photos", buckets[0].Name)
+	assert.Equal(t, "other", buckets[1].Name)
+
+	oi, err := ol.CopyObject(context.Background(), "photos", "a", "other", "b", minio.ObjectInfo{}, minio.ObjectOptions{}, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme-photos-mirror:other", calledWith)
+	assert.Equal(t, "other", oi.Bucket)
+}
+
+func TestBucketMappingLayerMultipart(t *testing.T) {
+	alter := test.NewProxyObjectLayer()
+	mapper := NewMapper(&config.BucketMappingOptions{Mapping: map[string]string{"photos": "acme-photos-mirror"}})
+	ol := NewBucketMappingLayer(alter, mapper)
+
+	var calledWith []string
+
+	alter.NewMultipartUploadFunc = func(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+		calledWith = append(calledWith, bucket)
+		return "upload", nil
+	}
+
+	alter.PutObjectPartFunc = func(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+		calledWith = append(calledWith, bucket)
+		return minio.PartInfo{PartNumber: partID}, nil
+	}
+
+	alter.CompleteMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		calledWith = append(calledWith, bucket)
+		return minio.ObjectInfo{Bucket: bucket, Name: object}, nil
+	}
+
+	ctx := context.Background()
+
+	uploadID, err := ol.NewMultipartUpload(ctx, "photos", "object", nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	data, err := hash.NewReader(bytes.NewReader([]byte("part")), 4, "", "")
+	assert.NoError(t, err)
+
+	_, err = ol.PutObjectPart(ctx, "photos", "object", uploadID, 1, data, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	oi, err := ol.CompleteMultipartUpload(ctx, "photos", "object", uploadID, []minio.CompletePart{{PartNumber: 1}}, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "photos", oi.Bucket)
+
+	assert.Equal(t, []string{"acme-photos-mirror", "acme-photos-mirror", "acme-photos-mirror"}, calledWith)
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package bucketmap
+
+import (
+	"strings"
+
+	"storj.io/ditto/pkg/config"
+)
+
+// Mapper translates bucket names between prime and alter.
+// Explicit mapping has priority over prefix and suffix.
+type Mapper struct {
+	toAlter map[string]string
+	toPrime map[string]string
+	prefix  string
+	suffix  string
+}
+
+// Creates new Mapper from BucketMappingOptions.
+func NewMapper(opts *config.BucketMappingOptions) *Mapper {
+	m := &Mapper{
+		toAlter: make(map[string]string),
+		toPrime: make(map[string]string),
+	}
+
+	if opts == nil {
+		return m
+	}
+
+	for prime, alter := range opts.Mapping {
+		m.toAlter[prime] = alter
+		m.toPrime[alter] = prime
+	}
+
+	m.prefix = opts.AlterPrefix
+	m.suffix = opts.AlterSuffix
+
+	return m
+}
+
+// IsEmpty returns true if mapper does not change any bucket name.
+func (m *Mapper) IsEmpty() bool {
+	return len(m.toAlter) == 0 && m.prefix == "" && m.suffix == ""
+}
+
+// ToAlter returns name of alter bucket for prime bucket.
+func (m *Mapper) ToAlter(bucket string) string {
+	if bucket == "" {
+		return bucket
+	}
+
+	if alter, ok := m.toAlter[bucket]; ok {
+		return alter
+	}
+
+	return m.prefix + bucket + m.suffix
+}
+
+// ToPrime returns name of prime bucket for alter bucket.
+// Buckets which do not follow mapping rules are returned as is.
+func (m *Mapper) ToPrime(bucket string) string {
+	if prime, ok := m.toPrime[bucket]; ok {
+		return prime
+	}
+
+	if len(bucket) <= len(m.prefix)+len(m.suffix) {
+		return bucket
+	}
+
+	if !strings.HasPrefix(bucket, m.prefix) || !strings.HasSuffix(bucket, m.suffix) {
+		return bucket
+	}
+
+	return bucket[len(m.prefix) : len(bucket)-len(m.suffix)]
+}
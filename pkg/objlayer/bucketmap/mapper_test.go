@@ -0,0 +1,56 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package bucketmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+)
+
+func TestMapper(t *testing.T) {
+	cases := []struct {
+		testName     string
+		opts         *config.BucketMappingOptions
+		prime, alter string
+	}{
+		{
+			testName: "Nil options, name unchanged",
+			opts:     nil,
+			prime:    "photos",
+			alter:    "photos",
+		},
+		{
+			testName: "Explicit mapping",
+			opts:     &config.BucketMappingOptions{Mapping: map[string]string{"photos": "acme-photos-mirror"}, AlterPrefix: "x-"},
+			prime:    "photos",
+			alter:    "acme-photos-mirror",
+		},
+		{
+			testName: "Prefix and suffix",
+			opts:     &config.BucketMappingOptions{AlterPrefix: "acme-", AlterSuffix: "-mirror"},
+			prime:    "docs",
+			alter:    "acme-docs-mirror",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			m := NewMapper(c.opts)
+
+			assert.Equal(t, c.alter, m.ToAlter(c.prime))
+			assert.Equal(t, c.prime, m.ToPrime(c.alter))
+		})
+	}
+}
+
+func TestMapperUnknownAlterBucket(t *testing.T) {
+	m := NewMapper(&config.BucketMappingOptions{AlterPrefix: "acme-", AlterSuffix: "-mirror"})
+
+	assert.Equal(t, "other-bucket", m.ToPrime("other-bucket"))
+	assert.Equal(t, "acme--mirror", m.ToPrime("acme--mirror"))
+	assert.Equal(t, false, m.IsEmpty())
+	assert.Equal(t, true, NewMapper(nil).IsEmpty())
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checksum
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	gohash "hash"
+	"io"
+	"strings"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// MismatchError is returned when content of stored object differs from content sent to backend.
+type MismatchError struct {
+	Bucket string
+	Object string
+	Sent   string
+	Stored string
+}
+
+func (e MismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch of %s/%s: sent %s, stored %s", e.Bucket, e.Object, e.Sent, e.Stored)
+}
+
+// NewVerifyingLayer wraps object layer so content of every put object is hashed while it's uploaded
+// and verified against stored object, copies are verified against their source. Stored object is verified
+// by its ETag if it's MD5 of content, otherwise, e.g. if backend encrypts, it's read back and hashed.
+// Mismatch fails the write with MismatchError. Multipart uploads are verified by their uploader,
+// parts of single upload come by separate calls.
+func NewVerifyingLayer(ol minio.ObjectLayer) minio.ObjectLayer {
+	return &verifyingLayer{ol}
+}
+
+type verifyingLayer struct {
+	minio.ObjectLayer
+}
+
+func (v *verifyingLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	r := NewReader(data)
+
+	hashed, err := hash.NewReader(r, data.Size(), data.MD5HexString(), data.SHA256HexString())
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	oi, err := v.ObjectLayer.PutObject(ctx, bucket, object, hashed, metadata, opts)
+	if err != nil {
+		return oi, err
+	}
+
+	return oi, Verify(ctx, v.ObjectLayer, bucket, object, oi.ETag, r.MD5(), r.SHA256())
+}
+
+func (v *verifyingLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	oi, err := v.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+	if err != nil {
+		return oi, err
+	}
+
+	// backend computes ETags of both copies from content it stores
+	if etag := ETag(srcInfo.ETag); etag != "" && etag == ETag(oi.ETag) {
+		return oi, nil
+	}
+
+	sent, err := ReadSHA256(ctx, v.ObjectLayer, srcBucket, srcObject)
+	if err != nil {
+		return oi, fmt.Errorf("source %s/%s can't be read to verify checksum: %s", srcBucket, srcObject, err)
+	}
+
+	return oi, Verify(ctx, v.ObjectLayer, destBucket, destObject, oi.ETag, "", sent)
+}
+
+// Verify checks object of bucket stored with etag against hex encoded MD5 and SHA256 of content sent.
+// ETag is trusted if it equals MD5, otherwise object is read back and its SHA256 compared.
+// Empty MD5 always reads object back.
+func Verify(ctx context.Context, ol minio.ObjectLayer, bucket, object, etag, md5Hex, sha256Hex string) error {
+	if md5Hex != "" && ETag(etag) == md5Hex {
+		return nil
+	}
+
+	stored, err := ReadSHA256(ctx, ol, bucket, object)
+	if err != nil {
+		return fmt.Errorf("object %s/%s can't be read back to verify checksum: %s", bucket, object, err)
+	}
+
+	if stored != sha256Hex {
+		return MismatchError{Bucket: bucket, Object: object, Sent: "sha256:" + sha256Hex, Stored: "sha256:" + stored}
+	}
+
+	return nil
+}
+
+// ReadSHA256 streams object of bucket from ol and returns hex encoded SHA256 of its content.
+func ReadSHA256(ctx context.Context, ol minio.ObjectLayer, bucket, object string) (string, error) {
+	oi, err := ol.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if err := ol.GetObject(ctx, bucket, object, 0, oi.Size, h, "", minio.ObjectOptions{}); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ETag returns etag without quotes in lower case, as it's compared with hex encoded MD5.
+func ETag(etag string) string {
+	return strings.ToLower(strings.Trim(etag, "\""))
+}
+
+// Reader hashes content read through it by MD5 and SHA256.
+type Reader struct {
+	r           io.Reader
+	md5, sha256 gohash.Hash
+}
+
+// NewReader returns reader hashing content of r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r, md5: md5.New(), sha256: sha256.New()}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.md5.Write(p[:n])
+		r.sha256.Write(p[:n])
+	}
+
+	return n, err
+}
+
+// MD5 returns hex encoded MD5 of content read so far.
+func (r *Reader) MD5() string {
+	return hex.EncodeToString(r.md5.Sum(nil))
+}
+
+// SHA256 returns hex encoded SHA256 of content read so far.
+func (r *Reader) SHA256() string {
+	return hex.EncodeToString(r.sha256.Sum(nil))
+}
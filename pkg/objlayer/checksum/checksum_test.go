@@ -0,0 +1,151 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checksum
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// memoryBackend stores objects in memory, stored content is altered by corrupt and ETag by etag.
+type memoryBackend struct {
+	objects map[string][]byte
+	corrupt func(content []byte) []byte
+	etag    func(content []byte) string
+	reads   int
+}
+
+func (b *memoryBackend) layer() minio.ObjectLayer {
+	ol := test.NewProxyObjectLayer()
+
+	ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		content, err := ioutil.ReadAll(data)
+		if err != nil {
+			return minio.ObjectInfo{}, err
+		}
+
+		if b.corrupt != nil {
+			content = b.corrupt(content)
+		}
+
+		b.objects[object] = content
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content)), ETag: b.etag(content)}, nil
+	}
+	ol.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		content := b.objects[srcObject]
+		if b.corrupt != nil {
+			content = b.corrupt(content)
+		}
+
+		b.objects[destObject] = content
+
+		return minio.ObjectInfo{Bucket: destBucket, Name: destObject, Size: int64(len(content)), ETag: b.etag(content)}, nil
+	}
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(b.objects[object]))}, nil
+	}
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		b.reads++
+		_, err := writer.Write(b.objects[object])
+		return err
+	}
+
+	return ol
+}
+
+func md5ETag(content []byte) string {
+	sum := md5.Sum(content)
+	return "\"" + hex.EncodeToString(sum[:]) + "\""
+}
+
+func opaqueETag(content []byte) string {
+	return "encrypted"
+}
+
+func TestVerifyingLayer(t *testing.T) {
+	content := []byte("content")
+	flip := func(content []byte) []byte { return append([]byte{'x'}, content[1:]...) }
+
+	put := func(ol minio.ObjectLayer) error {
+		data, err := hash.NewReader(bytes.NewReader(content), int64(len(content)), "", "")
+		assert.NoError(t, err)
+
+		_, err = NewVerifyingLayer(ol).PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+		return err
+	}
+
+	copyObject := func(ol minio.ObjectLayer, etag string) error {
+		srcInfo := minio.ObjectInfo{Bucket: "bucket", Name: "object", ETag: etag}
+
+		_, err := NewVerifyingLayer(ol).CopyObject(context.Background(), "bucket", "object", "bucket", "copy", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+		return err
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Put verified by ETag",
+			func(t *testing.T) {
+				b := &memoryBackend{objects: map[string][]byte{}, etag: md5ETag}
+
+				assert.NoError(t, put(b.layer()))
+				assert.Equal(t, 0, b.reads)
+			},
+		},
+		{
+			"Put verified by reading object back if ETag isn't MD5",
+			func(t *testing.T) {
+				b := &memoryBackend{objects: map[string][]byte{}, etag: opaqueETag}
+
+				assert.NoError(t, put(b.layer()))
+				assert.Equal(t, 1, b.reads)
+			},
+		},
+		{
+			"Corrupted put fails",
+			func(t *testing.T) {
+				b := &memoryBackend{objects: map[string][]byte{}, etag: md5ETag, corrupt: flip}
+
+				err := put(b.layer())
+				assert.IsType(t, MismatchError{}, err)
+				assert.Contains(t, err.Error(), "checksum mismatch of bucket/object: sent sha256:")
+			},
+		},
+		{
+			"Copy verified by ETag of source",
+			func(t *testing.T) {
+				b := &memoryBackend{objects: map[string][]byte{"object": content}, etag: md5ETag}
+
+				assert.NoError(t, copyObject(b.layer(), md5ETag(content)))
+				assert.Equal(t, 0, b.reads)
+			},
+		},
+		{
+			"Corrupted copy fails",
+			func(t *testing.T) {
+				b := &memoryBackend{objects: map[string][]byte{"object": content}, etag: opaqueETag, corrupt: flip}
+
+				assert.IsType(t, MismatchError{}, copyObject(b.layer(), "source"))
+				assert.Equal(t, 2, b.reads)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
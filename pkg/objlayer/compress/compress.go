@@ -0,0 +1,346 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package compress
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/klauspost/compress/zstd"
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/buffer"
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/metadata"
+)
+
+// Algorithms supported by NewCompressingLayer.
+const (
+	None = "none"
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+// AlgorithmKey, SizeKey and ETagKey mark compressed object with algorithm, size and ETag of uncompressed content.
+const (
+	AlgorithmKey = metadata.UserPrefix + "Ditto-Compression"
+	SizeKey      = metadata.UserPrefix + "Ditto-Uncompressed-Size"
+	ETagKey      = metadata.UserPrefix + "Ditto-Uncompressed-Etag"
+)
+
+// memLimit is size of compressed content kept in memory while it's measured, the rest is spilled to temp file.
+const memLimit = 1 << 20
+
+// NewCompressingLayer wraps object layer so objects of known size not smaller than minSize are compressed
+// with algorithm before they are written. Compressed objects are marked in metadata, so they are decompressed
+// when read whatever algorithm is configured, and reported with uncompressed size and ETag and without markers,
+// so they compare equal to their uncompressed copies. Listed objects are looked up one by one to report them so.
+func NewCompressingLayer(ol minio.ObjectLayer, algorithm string, minSize int64) (minio.ObjectLayer, error) {
+	switch algorithm {
+	case None, Gzip, Zstd:
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q, expected one of %s, %s, %s", algorithm, None, Gzip, Zstd)
+	}
+
+	return &compressingLayer{ObjectLayer: ol, algorithm: algorithm, minSize: minSize}, nil
+}
+
+type compressingLayer struct {
+	minio.ObjectLayer
+	algorithm string
+	minSize   int64
+}
+
+// compressed returns algorithm and uncompressed size of object described by md, or false if it isn't compressed.
+func compressed(md map[string]string) (string, int64, bool) {
+	algorithm := metadata.Get(md, AlgorithmKey)
+	if algorithm == "" {
+		return "", 0, false
+	}
+
+	size, err := strconv.ParseInt(metadata.Get(md, SizeKey), 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return algorithm, size, true
+}
+
+// withMarkers returns copy of md marking content compressed with algorithm.
+func withMarkers(md map[string]string, algorithm string, size int64, etag string) map[string]string {
+	md = metadata.Set(md, AlgorithmKey, algorithm)
+	md = metadata.Set(md, SizeKey, strconv.FormatInt(size, 10))
+
+	return metadata.Set(md, ETagKey, etag)
+}
+
+// withoutMarkers returns copy of md without compression markers.
+func withoutMarkers(md map[string]string) map[string]string {
+	return metadata.Delete(metadata.Delete(metadata.Delete(md, AlgorithmKey), SizeKey), ETagKey)
+}
+
+// uncompressed reports object described by raw, as stored by backend, with uncompressed size and ETag
+// if it's compressed. ETag is kept if object was compressed before its uncompressed ETag was recorded.
+func uncompressed(oi, raw minio.ObjectInfo) minio.ObjectInfo {
+	if _, size, ok := compressed(raw.UserDefined); ok {
+		oi.Size = size
+
+		if etag := metadata.Get(raw.UserDefined, ETagKey); etag != "" {
+			oi.ETag = etag
+		}
+	}
+
+	return oi
+}
+
+func newWriter(algorithm string, w io.Writer) (io.WriteCloser, error) {
+	switch algorithm {
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+}
+
+func newReader(algorithm string, r io.Reader) (io.ReadCloser, error) {
+	switch algorithm {
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return ioutil.NopCloser(dec), nil
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+}
+
+// isCompressed returns true if object of size should be compressed.
+// Objects of unknown size are stored as is, since their size can't be recorded before they are written.
+func (c *compressingLayer) isCompressed(size int64) bool {
+	return c.algorithm != None && size >= 0 && size >= c.minSize
+}
+
+func (c *compressingLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, md map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	md = withoutMarkers(md)
+
+	if !c.isCompressed(data.Size()) {
+		return c.ObjectLayer.PutObject(ctx, bucket, object, data, md, opts)
+	}
+
+	size := data.Size()
+
+	// backends require size of content, so compressed content is buffered until it's known
+	pr, pw := buffer.NewSpillPipe(memLimit, "")
+	defer pr.Close()
+
+	counter := &countingWriter{w: pw}
+
+	zw, err := newWriter(c.algorithm, counter)
+	if err != nil {
+		pw.Close()
+		return minio.ObjectInfo{}, err
+	}
+
+	md5sum := md5.New()
+
+	_, err = io.Copy(zw, io.TeeReader(data, md5sum))
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+
+	pw.CloseWithError(err)
+
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	compressedData, err := hash.NewReader(pr, counter.n, "", "")
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	md = withMarkers(md, c.algorithm, size, hex.EncodeToString(md5sum.Sum(nil)))
+
+	oi, err := c.ObjectLayer.PutObject(ctx, bucket, object, compressedData, md, opts)
+	if err != nil {
+		return oi, err
+	}
+
+	// backends don't necessarily return metadata of written object, so markers just written are used
+	return uncompressedInfo(uncompressed(oi, minio.ObjectInfo{UserDefined: md})), nil
+}
+
+// NewMultipartUpload is refused, parts uploaded separately can't be compressed as one stream.
+func (c *compressingLayer) NewMultipartUpload(ctx context.Context, bucket, object string, md map[string]string, opts minio.ObjectOptions) (string, error) {
+	return "", minio.NotImplemented{}
+}
+
+// uncompressedInfo reports compressed object with its uncompressed size and ETag and metadata without markers.
+func uncompressedInfo(oi minio.ObjectInfo) minio.ObjectInfo {
+	oi = uncompressed(oi, oi)
+
+	if oi.UserDefined != nil {
+		oi.UserDefined = withoutMarkers(oi.UserDefined)
+	}
+
+	return oi
+}
+
+func (c *compressingLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	oi, err := c.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+	if err != nil {
+		return oi, err
+	}
+
+	return uncompressedInfo(oi), nil
+}
+
+// GetObject decompresses compressed objects, ranges are served by decompressing content from its beginning.
+// Negative length reads until the end of object.
+func (c *compressingLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	oi, err := c.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+	if err != nil {
+		return err
+	}
+
+	algorithm, _, ok := compressed(oi.UserDefined)
+	if !ok {
+		return c.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+	}
+
+	// backend matches only ETag of compressed content, the one read is pinned by it
+	ctx, ok = dcontext.MatchTransformed(ctx, etag, uncompressedInfo(oi).ETag)
+	if !ok {
+		return minio.PreConditionFailed{}
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		pw.CloseWithError(c.ObjectLayer.GetObject(ctx, bucket, object, 0, oi.Size, pw, oi.ETag, opts))
+	}()
+
+	// failed read of compressed content fails decompression, so only its error is returned
+	err = decompress(algorithm, pr, startOffset, length, writer)
+
+	// stops read of compressed content if requested range ended before its end
+	pr.CloseWithError(io.ErrClosedPipe)
+	<-done
+
+	return err
+}
+
+// decompress writes length bytes of content decompressed from r starting at offset to w.
+func decompress(algorithm string, r io.Reader, offset, length int64, w io.Writer) error {
+	zr, err := newReader(algorithm, r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	if _, err = io.CopyN(ioutil.Discard, zr, offset); err != nil {
+		return err
+	}
+
+	if length < 0 {
+		_, err = io.Copy(w, zr)
+		return err
+	}
+
+	_, err = io.CopyN(w, zr, length)
+
+	return err
+}
+
+// CopyObject keeps compression markers of source, because srcInfo read through this layer has them removed.
+func (c *compressingLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	raw, err := c.ObjectLayer.GetObjectInfo(ctx, srcBucket, srcObject, srcOpts)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	srcInfo.UserDefined = withoutMarkers(srcInfo.UserDefined)
+	if algorithm, size, ok := compressed(raw.UserDefined); ok {
+		srcInfo.UserDefined = withMarkers(srcInfo.UserDefined, algorithm, size, metadata.Get(raw.UserDefined, ETagKey))
+	}
+
+	oi, err := c.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+	if err != nil {
+		return oi, err
+	}
+
+	return uncompressedInfo(oi), nil
+}
+
+func (c *compressingLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	loi, err := c.ObjectLayer.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return loi, err
+	}
+
+	err = c.resolve(ctx, bucket, loi.Objects)
+
+	return loi, err
+}
+
+func (c *compressingLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	loi, err := c.ObjectLayer.ListObjectsV2(ctx, bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+	if err != nil {
+		return loi, err
+	}
+
+	err = c.resolve(ctx, bucket, loi.Objects)
+
+	return loi, err
+}
+
+// resolve replaces sizes and ETags of listed compressed objects with their uncompressed ones, if ctx asks for them,
+// see dcontext.WithExactListing. Object deleted since it was listed is kept as listed.
+func (c *compressingLayer) resolve(ctx context.Context, bucket string, objects []minio.ObjectInfo) error {
+	if !dcontext.IsExactListing(ctx) {
+		return nil
+	}
+
+	for i := range objects {
+		oi, err := c.ObjectLayer.GetObjectInfo(ctx, bucket, objects[i].Name, minio.ObjectOptions{})
+		if err != nil {
+			if _, ok := err.(minio.ObjectNotFound); ok {
+				continue
+			}
+
+			return err
+		}
+
+		objects[i] = uncompressed(objects[i], oi)
+	}
+
+	return nil
+}
+
+// countingWriter counts bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,270 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package compress
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/metadata"
+	"storj.io/ditto/pkg/objlayer/s3compat"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+type stored struct {
+	content  []byte
+	metadata map[string]string
+}
+
+// newStore creates object layer keeping objects of a single bucket in memory.
+func newStore() (minio.ObjectLayer, map[string]*stored) {
+	ol := test.NewProxyObjectLayer()
+	objects := map[string]*stored{}
+
+	info := func(bucket, object string) (minio.ObjectInfo, error) {
+		s, ok := objects[object]
+		if !ok {
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(s.content)), ETag: etag(s.content), UserDefined: s.metadata}, nil
+	}
+
+	ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, md map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		content, err := ioutil.ReadAll(data)
+		if err != nil {
+			return minio.ObjectInfo{}, err
+		}
+
+		objects[object] = &stored{content, md}
+
+		return info(bucket, object)
+	}
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return info(bucket, object)
+	}
+
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		s, ok := objects[object]
+		if !ok {
+			return minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		_, err := writer.Write(s.content[startOffset : startOffset+length])
+		return err
+	}
+
+	ol.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		objects[destObject] = &stored{objects[srcObject].content, srcInfo.UserDefined}
+		return info(destBucket, destObject)
+	}
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		var loi minio.ListObjectsInfo
+		for object, s := range objects {
+			loi.Objects = append(loi.Objects, minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(s.content)), ETag: etag(s.content)})
+		}
+
+		return loi, nil
+	}
+
+	return ol, objects
+}
+
+func etag(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func put(t *testing.T, ol minio.ObjectLayer, object string, content []byte, md map[string]string) {
+	data, err := hash.NewReader(bytes.NewReader(content), int64(len(content)), "", "")
+	assert.NoError(t, err)
+
+	oi, err := ol.PutObject(context.Background(), "bucket", object, data, md, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), oi.Size)
+}
+
+func get(t *testing.T, ol minio.ObjectLayer, object string, offset, length int64) string {
+	var buf bytes.Buffer
+	err := ol.GetObject(context.Background(), "bucket", object, offset, length, &buf, "", minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	return buf.String()
+}
+
+func TestCompressingLayer(t *testing.T) {
+	content := strings.Repeat("compressible content ", 100)
+	md := map[string]string{"X-Amz-Meta-Owner": "someone"}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Compressed object is read back",
+			func(t *testing.T) {
+				for _, algorithm := range []string{Gzip, Zstd} {
+					store, objects := newStore()
+					ol, err := NewCompressingLayer(store, algorithm, 0)
+					assert.NoError(t, err)
+
+					put(t, ol, "object", []byte(content), md)
+
+					assert.True(t, len(objects["object"].content) < len(content), algorithm)
+					assert.Equal(t, algorithm, metadata.Get(objects["object"].metadata, AlgorithmKey))
+
+					assert.Equal(t, content, get(t, ol, "object", 0, int64(len(content))))
+					assert.Equal(t, content[30:60], get(t, ol, "object", 30, 30))
+					assert.Equal(t, content[100:], get(t, ol, "object", 100, -1))
+
+					oi, err := ol.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+					assert.NoError(t, err)
+					assert.Equal(t, int64(len(content)), oi.Size)
+					assert.Equal(t, etag([]byte(content)), oi.ETag)
+					assert.Equal(t, md, oi.UserDefined)
+				}
+			},
+		},
+		{
+			"Small object is stored as is",
+			func(t *testing.T) {
+				store, objects := newStore()
+				ol, err := NewCompressingLayer(store, Gzip, 1024)
+				assert.NoError(t, err)
+
+				put(t, ol, "object", []byte("small"), md)
+
+				assert.Equal(t, "small", string(objects["object"].content))
+				assert.Equal(t, "small", get(t, ol, "object", 0, 5))
+			},
+		},
+		{
+			"Compressed object is read with compression disabled",
+			func(t *testing.T) {
+				store, _ := newStore()
+				ol, err := NewCompressingLayer(store, Zstd, 0)
+				assert.NoError(t, err)
+
+				put(t, ol, "object", []byte(content), md)
+
+				ol, err = NewCompressingLayer(store, None, 0)
+				assert.NoError(t, err)
+
+				assert.Equal(t, content, get(t, ol, "object", 0, int64(len(content))))
+			},
+		},
+		{
+			"Copy keeps compression markers",
+			func(t *testing.T) {
+				store, objects := newStore()
+				ol, err := NewCompressingLayer(store, Gzip, 0)
+				assert.NoError(t, err)
+
+				put(t, ol, "object", []byte(content), md)
+
+				srcInfo, err := ol.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+				assert.NoError(t, err)
+
+				_, err = ol.CopyObject(context.Background(), "bucket", "object", "bucket", "copy", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+				assert.NoError(t, err)
+
+				assert.Equal(t, Gzip, metadata.Get(objects["copy"].metadata, AlgorithmKey))
+				assert.Equal(t, content, get(t, ol, "copy", 0, int64(len(content))))
+
+				copyInfo, err := ol.GetObjectInfo(context.Background(), "bucket", "copy", minio.ObjectOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, etag([]byte(content)), copyInfo.ETag)
+			},
+		},
+		{
+			"Exact listing reports uncompressed sizes and ETags",
+			func(t *testing.T) {
+				store, _ := newStore()
+				ol, err := NewCompressingLayer(store, Gzip, 0)
+				assert.NoError(t, err)
+
+				put(t, ol, "object", []byte(content), md)
+
+				loi, err := ol.ListObjects(dcontext.WithExactListing(context.Background()), "bucket", "", "", "", 1000)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, len(loi.Objects))
+				assert.Equal(t, int64(len(content)), loi.Objects[0].Size)
+				assert.Equal(t, etag([]byte(content)), loi.Objects[0].ETag)
+
+				// objects are listed as stored unless exact listing is asked for
+				loi, err = ol.ListObjects(context.Background(), "bucket", "", "", "", 1000)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, len(loi.Objects))
+				assert.NotEqual(t, int64(len(content)), loi.Objects[0].Size)
+			},
+		},
+		{
+			"Unknown algorithm is refused",
+			func(t *testing.T) {
+				_, err := NewCompressingLayer(test.NewProxyObjectLayer(), "lzma", 0)
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+// ETags of reads and If-Match of requests describe uncompressed content, backend is asked only for the stored one.
+func TestCompressingLayerPreconditions(t *testing.T) {
+	srv := test.NewMockS3Server()
+	defer srv.Close()
+
+	backend, err := s3compat.NewS3Compat(srv.URL, "access", "secret")
+	assert.NoError(t, err)
+
+	ol, err := NewCompressingLayer(backend, Gzip, 0)
+	assert.NoError(t, err)
+
+	content := []byte(strings.Repeat("compressible content ", 100))
+	put(t, ol, "object", content, nil)
+
+	oi, err := ol.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		testName string
+		etag     string
+		ifMatch  string
+		expected error
+	}{
+		{"ETag of read", oi.ETag, "", nil},
+		{"If-Match of request", "", oi.ETag, nil},
+		{"Other ETag of read", "other", "", minio.PreConditionFailed{}},
+		{"Other If-Match of request", "", "other", minio.PreConditionFailed{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			ctx := dcontext.WithPreconditions(context.Background(), dcontext.Preconditions{IfMatch: c.ifMatch})
+
+			var buf bytes.Buffer
+			err := ol.GetObject(ctx, "bucket", "object", 0, int64(len(content)), &buf, c.etag, minio.ObjectOptions{})
+			assert.Equal(t, c.expected, err)
+
+			if c.expected == nil {
+				assert.Equal(t, content, buf.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package dryrun
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	l "storj.io/ditto/pkg/logger"
+)
+
+// NewDryRunLayer wraps object layer so write operations are only logged and reported as succeeded.
+// Read operations are passed to the wrapped layer. Uploaded data is consumed and discarded,
+// multipart uploads are known only to dry run layer.
+func NewDryRunLayer(ol minio.ObjectLayer, logger l.Logger) minio.ObjectLayer {
+	return &dryRunLayer{ObjectLayer: ol, logger: logger, uploads: map[string]map[int]minio.PartInfo{}}
+}
+
+type dryRunLayer struct {
+	minio.ObjectLayer
+	logger l.Logger
+
+	// uploads holds parts of multipart uploads by upload ID
+	mu      sync.Mutex
+	uploads map[string]map[int]minio.PartInfo
+}
+
+func (d *dryRunLayer) log(format string, args ...interface{}) {
+	if d.logger != nil {
+		d.logger.Log("dry run: would " + fmt.Sprintf(format, args...))
+	}
+}
+
+func (d *dryRunLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	d.log("MAKE BUCKET %s", bucket)
+
+	return nil
+}
+
+func (d *dryRunLayer) DeleteBucket(ctx context.Context, bucket string) error {
+	d.log("DELETE BUCKET %s", bucket)
+
+	return nil
+}
+
+func (d *dryRunLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	size, err := io.Copy(ioutil.Discard, data)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	d.log("PUT %s/%s, %s", bucket, object, formatSize(size))
+
+	return minio.ObjectInfo{
+		Bucket:      bucket,
+		Name:        object,
+		Size:        size,
+		ETag:        data.MD5HexString(),
+		UserDefined: metadata,
+	}, nil
+}
+
+func (d *dryRunLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	d.log("COPY %s/%s to %s/%s", srcBucket, srcObject, destBucket, destObject)
+
+	srcInfo.Bucket = destBucket
+	srcInfo.Name = destObject
+
+	return srcInfo, nil
+}
+
+func (d *dryRunLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	d.log("DELETE %s/%s", bucket, object)
+
+	return nil
+}
+
+// NewMultipartUpload starts upload known only to dry run layer, its parts are discarded.
+func (d *dryRunLayer) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	uploadID := "dryrun-" + hex.EncodeToString(b)
+
+	d.mu.Lock()
+	d.uploads[uploadID] = map[int]minio.PartInfo{}
+	d.mu.Unlock()
+
+	d.log("START MULTIPART UPLOAD %s/%s", bucket, object)
+
+	return uploadID, nil
+}
+
+// parts returns parts of upload started by dry run layer.
+func (d *dryRunLayer) parts(bucket, object, uploadID string) (map[int]minio.PartInfo, error) {
+	parts, ok := d.uploads[uploadID]
+	if !ok {
+		return nil, minio.InvalidUploadID{Bucket: bucket, Object: object, UploadID: uploadID}
+	}
+
+	return parts, nil
+}
+
+func (d *dryRunLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+	md5sum := md5.New()
+
+	size, err := io.Copy(ioutil.Discard, io.TeeReader(data, md5sum))
+	if err != nil {
+		return minio.PartInfo{}, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	parts, err := d.parts(bucket, object, uploadID)
+	if err != nil {
+		return minio.PartInfo{}, err
+	}
+
+	part := minio.PartInfo{PartNumber: partID, LastModified: time.Now(), ETag: hex.EncodeToString(md5sum.Sum(nil)), Size: size}
+	parts[partID] = part
+
+	d.log("PUT PART %d of %s/%s, %s", partID, bucket, object, formatSize(size))
+
+	return part, nil
+}
+
+func (d *dryRunLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListPartsInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	parts, err := d.parts(bucket, object, uploadID)
+	if err != nil {
+		return minio.ListPartsInfo{}, err
+	}
+
+	result := minio.ListPartsInfo{Bucket: bucket, Object: object, UploadID: uploadID, PartNumberMarker: partNumberMarker, MaxParts: maxParts}
+
+	for _, part := range parts {
+		if part.PartNumber > partNumberMarker {
+			result.Parts = append(result.Parts, part)
+		}
+	}
+
+	sort.Slice(result.Parts, func(i, j int) bool { return result.Parts[i].PartNumber < result.Parts[j].PartNumber })
+
+	if maxParts >= 0 && len(result.Parts) > maxParts {
+		result.Parts = result.Parts[:maxParts]
+		result.IsTruncated = true
+	}
+
+	if n := len(result.Parts); n > 0 {
+		result.NextPartNumberMarker = result.Parts[n-1].PartNumber
+	}
+
+	return result, nil
+}
+
+func (d *dryRunLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, err := d.parts(bucket, object, uploadID); err != nil {
+		return err
+	}
+
+	delete(d.uploads, uploadID)
+
+	d.log("ABORT MULTIPART UPLOAD %s/%s", bucket, object)
+
+	return nil
+}
+
+func (d *dryRunLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	parts, err := d.parts(bucket, object, uploadID)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	size := int64(0)
+	for _, p := range uploadedParts {
+		part, ok := parts[p.PartNumber]
+		if !ok || (p.ETag != "" && strings.Trim(p.ETag, "\"") != part.ETag) {
+			return minio.ObjectInfo{}, minio.InvalidPart{}
+		}
+
+		size += part.Size
+	}
+
+	delete(d.uploads, uploadID)
+
+	d.log("COMPLETE MULTIPART UPLOAD %s/%s, %s", bucket, object, formatSize(size))
+
+	return minio.ObjectInfo{Bucket: bucket, Name: object, Size: size, ModTime: time.Now()}, nil
+}
+
+// formatSize formats size in bytes with the largest fitting binary unit, e.g. 12MB.
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.0f%cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
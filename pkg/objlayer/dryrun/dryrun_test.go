@@ -0,0 +1,144 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestDryRunLayer(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+	logger := &test.MockLogger{}
+
+	isCalled := false
+
+	ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		isCalled = true
+		return minio.ObjectInfo{}, nil
+	}
+
+	ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		isCalled = true
+		return nil
+	}
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{ETag: "etag"}, nil
+	}
+
+	d := NewDryRunLayer(ol, logger)
+
+	buff := make([]byte, 12*1024*1024)
+	data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+	assert.NoError(t, err)
+
+	oi, err := d.PutObject(context.Background(), "bucket", "key", data, nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(buff)), oi.Size)
+
+	msg, err := logger.GetLastLogParam()
+	assert.NoError(t, err)
+	assert.Equal(t, "dry run: would PUT bucket/key, 12MB", msg)
+
+	assert.NoError(t, d.DeleteObject(context.Background(), "bucket", "key"))
+
+	msg, err = logger.GetLastLogParam()
+	assert.NoError(t, err)
+	assert.Equal(t, "dry run: would DELETE bucket/key", msg)
+	assert.Equal(t, false, isCalled)
+
+	// reads are passed through
+	oi, err = d.GetObjectInfo(context.Background(), "bucket", "key", minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "etag", oi.ETag)
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512B", formatSize(512))
+	assert.Equal(t, "2KB", formatSize(2048))
+	assert.Equal(t, "12MB", formatSize(12*1024*1024))
+	assert.Equal(t, "3GB", formatSize(3*1024*1024*1024))
+}
+
+func TestDryRunLayerMultipart(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+	logger := &test.MockLogger{}
+
+	isCalled := false
+
+	ol.NewMultipartUploadFunc = func(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+		isCalled = true
+		return "upload", nil
+	}
+
+	ol.PutObjectPartFunc = func(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+		isCalled = true
+		return minio.PartInfo{}, nil
+	}
+
+	ol.CompleteMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		isCalled = true
+		return minio.ObjectInfo{}, nil
+	}
+
+	ol.AbortMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string) error {
+		isCalled = true
+		return nil
+	}
+
+	d := NewDryRunLayer(ol, logger)
+	ctx := context.Background()
+
+	uploadID, err := d.NewMultipartUpload(ctx, "bucket", "key", nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	var completed []minio.CompletePart
+	for _, partID := range []int{2, 1} {
+		buff := make([]byte, 1024)
+		data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+		assert.NoError(t, err)
+
+		part, err := d.PutObjectPart(ctx, "bucket", "key", uploadID, partID, data, minio.ObjectOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "0f343b0931126a20f133d67c2b018a3b", part.ETag)
+
+		completed = append([]minio.CompletePart{{PartNumber: partID, ETag: "\"" + part.ETag + "\""}}, completed...)
+	}
+
+	msg, err := logger.GetLastLogParam()
+	assert.NoError(t, err)
+	assert.Equal(t, "dry run: would PUT PART 1 of bucket/key, 1KB", msg)
+
+	lpi, err := d.ListObjectParts(ctx, "bucket", "key", uploadID, 0, 1000)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(lpi.Parts)) {
+		assert.Equal(t, 1, lpi.Parts[0].PartNumber)
+		assert.Equal(t, 2, lpi.Parts[1].PartNumber)
+	}
+
+	oi, err := d.CompleteMultipartUpload(ctx, "bucket", "key", uploadID, completed, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2048), oi.Size)
+
+	msg, err = logger.GetLastLogParam()
+	assert.NoError(t, err)
+	assert.Equal(t, "dry run: would COMPLETE MULTIPART UPLOAD bucket/key, 2KB", msg)
+
+	// completed upload is gone
+	err = d.AbortMultipartUpload(ctx, "bucket", "key", uploadID)
+	assert.Equal(t, minio.InvalidUploadID{Bucket: "bucket", Object: "key", UploadID: uploadID}, err)
+
+	uploadID, err = d.NewMultipartUpload(ctx, "bucket", "key", nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, d.AbortMultipartUpload(ctx, "bucket", "key", uploadID))
+
+	assert.Equal(t, false, isCalled)
+}
@@ -0,0 +1,381 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package encrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/buffer"
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/metadata"
+)
+
+// Algorithm is the only encryption algorithm, content is sealed with AES-256-GCM in segments.
+const Algorithm = "AES-256-GCM"
+
+// AlgorithmKey, NonceKey, SizeKey and ETagKey mark encrypted object with algorithm, random nonce prefix
+// and size and ETag of plaintext content.
+const (
+	AlgorithmKey = metadata.UserPrefix + "Ditto-Encryption"
+	NonceKey     = metadata.UserPrefix + "Ditto-Encryption-Nonce"
+	SizeKey      = metadata.UserPrefix + "Ditto-Plaintext-Size"
+	ETagKey      = metadata.UserPrefix + "Ditto-Plaintext-Etag"
+)
+
+const (
+	// segmentSize is size of plaintext sealed at once, ranges are read by whole segments.
+	segmentSize = 64 << 10
+	// prefixSize is size of random part of nonce, the rest is segment index and final segment flag.
+	prefixSize = 7
+	// memLimit is size of plaintext kept in memory while it's measured, the rest is spilled to temp file.
+	memLimit = 1 << 20
+)
+
+// NewEncryptingLayer wraps object layer so content of written objects is encrypted with key of KeySize bytes.
+// Encrypted objects are marked in metadata, so they are decrypted when read, and reported with plaintext size
+// and ETag and without markers, so they compare equal to their plaintext copies. Objects written before encryption
+// was enabled are read as is. Metadata isn't encrypted. Listed objects are looked up one by one to report them so.
+func NewEncryptingLayer(ol minio.ObjectLayer, key []byte) (minio.ObjectLayer, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptingLayer{ObjectLayer: ol, aead: aead}, nil
+}
+
+type encryptingLayer struct {
+	minio.ObjectLayer
+	aead cipher.AEAD
+}
+
+// encrypted returns nonce prefix and plaintext size of object described by md, or false if it isn't encrypted.
+func encrypted(md map[string]string) ([]byte, int64, bool) {
+	if metadata.Get(md, AlgorithmKey) != Algorithm {
+		return nil, 0, false
+	}
+
+	prefix, err := hex.DecodeString(metadata.Get(md, NonceKey))
+	if err != nil || len(prefix) != prefixSize {
+		return nil, 0, false
+	}
+
+	size, err := strconv.ParseInt(metadata.Get(md, SizeKey), 10, 64)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	return prefix, size, true
+}
+
+// withMarkers returns copy of md marking content encrypted with nonce prefix.
+func withMarkers(md map[string]string, prefix []byte, size int64, etag string) map[string]string {
+	md = metadata.Set(md, AlgorithmKey, Algorithm)
+	md = metadata.Set(md, NonceKey, hex.EncodeToString(prefix))
+	md = metadata.Set(md, SizeKey, strconv.FormatInt(size, 10))
+
+	return metadata.Set(md, ETagKey, etag)
+}
+
+// withoutMarkers returns copy of md without encryption markers.
+func withoutMarkers(md map[string]string) map[string]string {
+	for _, key := range []string{AlgorithmKey, NonceKey, SizeKey, ETagKey} {
+		md = metadata.Delete(md, key)
+	}
+
+	return md
+}
+
+// plaintext reports object described by raw, as stored by backend, with plaintext size and ETag
+// if it's encrypted. ETag is kept if object was encrypted before its plaintext ETag was recorded.
+func plaintext(oi, raw minio.ObjectInfo) minio.ObjectInfo {
+	if _, size, ok := encrypted(raw.UserDefined); ok {
+		oi.Size = size
+
+		if etag := metadata.Get(raw.UserDefined, ETagKey); etag != "" {
+			oi.ETag = etag
+		}
+	}
+
+	return oi
+}
+
+// segments returns number of segments of plaintext of size, empty plaintext is a single empty segment.
+func segments(size int64) int64 {
+	if size == 0 {
+		return 1
+	}
+
+	return (size + segmentSize - 1) / segmentSize
+}
+
+// encryptedSize returns size of content of size once encrypted.
+func (e *encryptingLayer) encryptedSize(size int64) int64 {
+	return size + segments(size)*int64(e.aead.Overhead())
+}
+
+// nonce returns nonce of segment, final segment has distinct nonce, so truncated content can't be decrypted.
+func nonce(prefix []byte, index int64, final bool) []byte {
+	n := make([]byte, prefixSize+5)
+	copy(n, prefix)
+	binary.BigEndian.PutUint32(n[prefixSize:], uint32(index))
+
+	if final {
+		n[len(n)-1] = 1
+	}
+
+	return n
+}
+
+// PutObject buffers plaintext before it's encrypted, since its size and ETag are recorded in metadata,
+// which is written before content.
+func (e *encryptingLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, md map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	src, pw := buffer.NewSpillPipe(memLimit, "")
+	defer src.Close()
+
+	md5sum := md5.New()
+
+	size, err := io.Copy(pw, io.TeeReader(data, md5sum))
+	pw.CloseWithError(err)
+
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	prefix := make([]byte, prefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	sealed, err := hash.NewReader(e.newEncryptingReader(src, prefix, size), e.encryptedSize(size), "", "")
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	md = withMarkers(withoutMarkers(md), prefix, size, hex.EncodeToString(md5sum.Sum(nil)))
+
+	oi, err := e.ObjectLayer.PutObject(ctx, bucket, object, sealed, md, opts)
+	if err != nil {
+		return oi, err
+	}
+
+	// backends don't necessarily return metadata of written object, so markers just written are used
+	return plaintextInfo(plaintext(oi, minio.ObjectInfo{UserDefined: md})), nil
+}
+
+// NewMultipartUpload is refused, parts uploaded separately can't be sealed as one stream of segments.
+// Mirroring layer writes multipart uploads to prime and mirrors completed objects with PutObject.
+func (e *encryptingLayer) NewMultipartUpload(ctx context.Context, bucket, object string, md map[string]string, opts minio.ObjectOptions) (string, error) {
+	return "", minio.NotImplemented{}
+}
+
+// plaintextInfo reports encrypted object with its plaintext size and ETag and metadata without markers.
+func plaintextInfo(oi minio.ObjectInfo) minio.ObjectInfo {
+	oi = plaintext(oi, oi)
+
+	if oi.UserDefined != nil {
+		oi.UserDefined = withoutMarkers(oi.UserDefined)
+	}
+
+	return oi
+}
+
+func (e *encryptingLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	oi, err := e.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+	if err != nil {
+		return oi, err
+	}
+
+	return plaintextInfo(oi), nil
+}
+
+// GetObject decrypts encrypted objects, only segments covering requested range are read.
+// Negative length reads until the end of object.
+func (e *encryptingLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	oi, err := e.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+	if err != nil {
+		return err
+	}
+
+	prefix, size, ok := encrypted(oi.UserDefined)
+	if !ok {
+		return e.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+	}
+
+	// backend matches only ETag of ciphertext, the one read is pinned by it
+	ctx, ok = dcontext.MatchTransformed(ctx, etag, plaintextInfo(oi).ETag)
+	if !ok {
+		return minio.PreConditionFailed{}
+	}
+
+	if oi.Size != e.encryptedSize(size) {
+		return fmt.Errorf("encrypted %s/%s is %d bytes, expected %d", bucket, object, oi.Size, e.encryptedSize(size))
+	}
+
+	if length < 0 {
+		length = size - startOffset
+	}
+
+	if startOffset < 0 || startOffset+length > size {
+		return minio.InvalidRange{OffsetBegin: startOffset, OffsetEnd: startOffset + length, ResourceSize: size}
+	}
+
+	if length == 0 {
+		return nil
+	}
+
+	sealedSize := int64(segmentSize + e.aead.Overhead())
+	first, last := startOffset/segmentSize, (startOffset+length-1)/segmentSize
+
+	start, end := first*sealedSize, (last+1)*sealedSize
+	if end > oi.Size {
+		end = oi.Size
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		pw.CloseWithError(e.ObjectLayer.GetObject(ctx, bucket, object, start, end-start, pw, oi.ETag, opts))
+	}()
+
+	// failed read of encrypted content fails decryption, so only its error is returned
+	err = e.decrypt(pr, prefix, size, first, last, startOffset, length, writer)
+
+	pr.CloseWithError(io.ErrClosedPipe)
+	<-done
+
+	return err
+}
+
+// decrypt opens segments first to last read from r and writes length bytes of plaintext starting at offset to w.
+func (e *encryptingLayer) decrypt(r io.Reader, prefix []byte, size, first, last, offset, length int64, w io.Writer) error {
+	final := segments(size) - 1
+	buf := make([]byte, segmentSize+e.aead.Overhead())
+	plain := make([]byte, 0, segmentSize)
+
+	for i := first; i <= last; i++ {
+		n := int64(segmentSize)
+		if i == final {
+			n = size - i*segmentSize
+		}
+
+		sealed := buf[:n+int64(e.aead.Overhead())]
+		if _, err := io.ReadFull(r, sealed); err != nil {
+			return err
+		}
+
+		opened, err := e.aead.Open(plain[:0], nonce(prefix, i, i == final), sealed, nil)
+		if err != nil {
+			return errors.New("unable to decrypt object, key is wrong or content is corrupted")
+		}
+
+		// skip part of segment before offset and after requested range
+		skip := offset - i*segmentSize
+		if skip < 0 {
+			skip = 0
+		}
+
+		opened = opened[skip:]
+		if int64(len(opened)) > length {
+			opened = opened[:length]
+		}
+
+		if _, err = w.Write(opened); err != nil {
+			return err
+		}
+
+		offset += int64(len(opened))
+		length -= int64(len(opened))
+	}
+
+	return nil
+}
+
+// CopyObject keeps encryption markers of source, because srcInfo read through this layer has them removed.
+func (e *encryptingLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	raw, err := e.ObjectLayer.GetObjectInfo(ctx, srcBucket, srcObject, srcOpts)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	srcInfo.UserDefined = withoutMarkers(srcInfo.UserDefined)
+	if prefix, size, ok := encrypted(raw.UserDefined); ok {
+		srcInfo.UserDefined = withMarkers(srcInfo.UserDefined, prefix, size, metadata.Get(raw.UserDefined, ETagKey))
+	}
+
+	oi, err := e.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+	if err != nil {
+		return oi, err
+	}
+
+	return plaintextInfo(oi), nil
+}
+
+func (e *encryptingLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	loi, err := e.ObjectLayer.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return loi, err
+	}
+
+	err = e.resolve(ctx, bucket, loi.Objects)
+
+	return loi, err
+}
+
+func (e *encryptingLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	loi, err := e.ObjectLayer.ListObjectsV2(ctx, bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+	if err != nil {
+		return loi, err
+	}
+
+	err = e.resolve(ctx, bucket, loi.Objects)
+
+	return loi, err
+}
+
+// resolve replaces sizes and ETags of listed encrypted objects with their plaintext ones, if ctx asks for them,
+// see dcontext.WithExactListing. Object deleted since it was listed is kept as listed.
+func (e *encryptingLayer) resolve(ctx context.Context, bucket string, objects []minio.ObjectInfo) error {
+	if !dcontext.IsExactListing(ctx) {
+		return nil
+	}
+
+	for i := range objects {
+		oi, err := e.ObjectLayer.GetObjectInfo(ctx, bucket, objects[i].Name, minio.ObjectOptions{})
+		if err != nil {
+			if _, ok := err.(minio.ObjectNotFound); ok {
+				continue
+			}
+
+			return err
+		}
+
+		objects[i] = plaintext(objects[i], oi)
+	}
+
+	return nil
+}
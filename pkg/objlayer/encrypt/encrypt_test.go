@@ -0,0 +1,358 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/metadata"
+	"storj.io/ditto/pkg/objlayer/s3compat"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+type stored struct {
+	content  []byte
+	metadata map[string]string
+}
+
+// newStore creates object layer keeping objects of a single bucket in memory.
+func newStore() (minio.ObjectLayer, map[string]*stored) {
+	ol := test.NewProxyObjectLayer()
+	objects := map[string]*stored{}
+
+	info := func(bucket, object string) (minio.ObjectInfo, error) {
+		s, ok := objects[object]
+		if !ok {
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(s.content)), ETag: etag(s.content), UserDefined: s.metadata}, nil
+	}
+
+	ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, md map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		content, err := ioutil.ReadAll(data)
+		if err != nil {
+			return minio.ObjectInfo{}, err
+		}
+
+		objects[object] = &stored{content, md}
+
+		return info(bucket, object)
+	}
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return info(bucket, object)
+	}
+
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		s, ok := objects[object]
+		if !ok {
+			return minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		_, err := writer.Write(s.content[startOffset : startOffset+length])
+		return err
+	}
+
+	ol.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		objects[destObject] = &stored{objects[srcObject].content, srcInfo.UserDefined}
+		return info(destBucket, destObject)
+	}
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		var loi minio.ListObjectsInfo
+		for object, s := range objects {
+			loi.Objects = append(loi.Objects, minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(s.content)), ETag: etag(s.content)})
+		}
+
+		return loi, nil
+	}
+
+	return ol, objects
+}
+
+func etag(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func put(t *testing.T, ol minio.ObjectLayer, object string, content []byte, size int64) {
+	data, err := hash.NewReader(bytes.NewReader(content), size, "", "")
+	assert.NoError(t, err)
+
+	oi, err := ol.PutObject(context.Background(), "bucket", object, data, map[string]string{"X-Amz-Meta-Owner": "someone"}, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), oi.Size)
+}
+
+func get(ol minio.ObjectLayer, object string, offset, length int64) ([]byte, error) {
+	var buf bytes.Buffer
+	err := ol.GetObject(context.Background(), "bucket", object, offset, length, &buf, "", minio.ObjectOptions{})
+
+	return buf.Bytes(), err
+}
+
+func TestEncryptingLayer(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, KeySize)
+
+	// content spans several segments, the last one partial
+	content := make([]byte, 3*segmentSize+100)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Encrypted object is read back",
+			func(t *testing.T) {
+				store, objects := newStore()
+				ol, err := NewEncryptingLayer(store, key)
+				assert.NoError(t, err)
+
+				put(t, ol, "object", content, int64(len(content)))
+
+				assert.Equal(t, 4*16+len(content), len(objects["object"].content))
+				assert.False(t, bytes.Contains(objects["object"].content, content[:100]))
+				assert.Equal(t, Algorithm, metadata.Get(objects["object"].metadata, AlgorithmKey))
+
+				ranges := [][2]int64{
+					{0, int64(len(content))},
+					{10, 20},
+					{segmentSize - 5, 10},
+					{2*segmentSize + 1, segmentSize + 99},
+				}
+
+				for _, r := range ranges {
+					read, err := get(ol, "object", r[0], r[1])
+					assert.NoError(t, err)
+					assert.Equal(t, content[r[0]:r[0]+r[1]], read)
+				}
+
+				read, err := get(ol, "object", 100, -1)
+				assert.NoError(t, err)
+				assert.Equal(t, content[100:], read)
+
+				oi, err := ol.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, int64(len(content)), oi.Size)
+				assert.Equal(t, etag(content), oi.ETag)
+				assert.Equal(t, map[string]string{"X-Amz-Meta-Owner": "someone"}, oi.UserDefined)
+			},
+		},
+		{
+			"Empty object and object of unknown size are encrypted",
+			func(t *testing.T) {
+				store, _ := newStore()
+				ol, err := NewEncryptingLayer(store, key)
+				assert.NoError(t, err)
+
+				put(t, ol, "empty", nil, 0)
+				put(t, ol, "unknown", content[:1000], -1)
+
+				read, err := get(ol, "empty", 0, 0)
+				assert.NoError(t, err)
+				assert.Equal(t, 0, len(read))
+
+				read, err = get(ol, "unknown", 0, 1000)
+				assert.NoError(t, err)
+				assert.Equal(t, content[:1000], read)
+
+				oi, err := ol.GetObjectInfo(context.Background(), "bucket", "unknown", minio.ObjectOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, etag(content[:1000]), oi.ETag)
+			},
+		},
+		{
+			"Unencrypted object is read as is",
+			func(t *testing.T) {
+				store, _ := newStore()
+				put(t, store, "object", content[:10], 10)
+
+				ol, err := NewEncryptingLayer(store, key)
+				assert.NoError(t, err)
+
+				read, err := get(ol, "object", 0, 10)
+				assert.NoError(t, err)
+				assert.Equal(t, content[:10], read)
+			},
+		},
+		{
+			"Wrong key, tampered and truncated content are refused",
+			func(t *testing.T) {
+				store, objects := newStore()
+				ol, err := NewEncryptingLayer(store, key)
+				assert.NoError(t, err)
+
+				put(t, ol, "object", content, int64(len(content)))
+
+				other, err := NewEncryptingLayer(store, bytes.Repeat([]byte{2}, KeySize))
+				assert.NoError(t, err)
+
+				_, err = get(other, "object", 0, 10)
+				assert.Error(t, err)
+
+				objects["object"].content[5] ^= 1
+				_, err = get(ol, "object", 0, 10)
+				assert.Error(t, err)
+
+				// dropping last segment and pretending object is shorter doesn't pass as final segment
+				put(t, ol, "object", content, int64(len(content)))
+				objects["object"].content = objects["object"].content[:3*(segmentSize+16)]
+				objects["object"].metadata = metadata.Set(objects["object"].metadata, SizeKey, "196608")
+
+				_, err = get(ol, "object", 0, 10)
+				assert.NoError(t, err)
+				_, err = get(ol, "object", 2*segmentSize, 10)
+				assert.Error(t, err)
+			},
+		},
+		{
+			"Copy keeps encryption markers",
+			func(t *testing.T) {
+				store, objects := newStore()
+				ol, err := NewEncryptingLayer(store, key)
+				assert.NoError(t, err)
+
+				put(t, ol, "object", content, int64(len(content)))
+
+				srcInfo, err := ol.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+				assert.NoError(t, err)
+
+				_, err = ol.CopyObject(context.Background(), "bucket", "object", "bucket", "copy", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+				assert.NoError(t, err)
+
+				assert.Equal(t, Algorithm, metadata.Get(objects["copy"].metadata, AlgorithmKey))
+
+				read, err := get(ol, "copy", 0, int64(len(content)))
+				assert.NoError(t, err)
+				assert.Equal(t, content, read)
+
+				copyInfo, err := ol.GetObjectInfo(context.Background(), "bucket", "copy", minio.ObjectOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, etag(content), copyInfo.ETag)
+			},
+		},
+		{
+			"Exact listing reports plaintext sizes and ETags",
+			func(t *testing.T) {
+				store, _ := newStore()
+				ol, err := NewEncryptingLayer(store, key)
+				assert.NoError(t, err)
+
+				put(t, ol, "object", content, int64(len(content)))
+
+				loi, err := ol.ListObjects(dcontext.WithExactListing(context.Background()), "bucket", "", "", "", 1000)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, len(loi.Objects))
+				assert.Equal(t, int64(len(content)), loi.Objects[0].Size)
+				assert.Equal(t, etag(content), loi.Objects[0].ETag)
+
+				// objects are listed as stored unless exact listing is asked for
+				loi, err = ol.ListObjects(context.Background(), "bucket", "", "", "", 1000)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, len(loi.Objects))
+				assert.NotEqual(t, int64(len(content)), loi.Objects[0].Size)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+
+	dir, err := ioutil.TempDir("", "ditto-key")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	raw := filepath.Join(dir, "raw")
+	assert.NoError(t, ioutil.WriteFile(raw, key, 0600))
+
+	encoded := filepath.Join(dir, "encoded")
+	assert.NoError(t, ioutil.WriteFile(encoded, []byte(hex.EncodeToString(key)+"\n"), 0600))
+
+	loaded, err := LoadKey(hex.EncodeToString(key), "")
+	assert.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	loaded, err = LoadKey("BwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwc=", "")
+	assert.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	loaded, err = LoadKey("", raw)
+	assert.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	loaded, err = LoadKey("", encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	_, err = LoadKey("short", "")
+	assert.Error(t, err)
+
+	_, err = LoadKey("", "")
+	assert.Error(t, err)
+}
+
+// ETags of reads and If-Match of requests describe plaintext content, backend is asked only for the stored one.
+func TestEncryptingLayerPreconditions(t *testing.T) {
+	srv := test.NewMockS3Server()
+	defer srv.Close()
+
+	backend, err := s3compat.NewS3Compat(srv.URL, "access", "secret")
+	assert.NoError(t, err)
+
+	ol, err := NewEncryptingLayer(backend, bytes.Repeat([]byte{1}, KeySize))
+	assert.NoError(t, err)
+
+	content := bytes.Repeat([]byte("content"), segmentSize)
+	put(t, ol, "object", content, int64(len(content)))
+
+	oi, err := ol.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		testName string
+		etag     string
+		ifMatch  string
+		expected error
+	}{
+		{"ETag of read", oi.ETag, "", nil},
+		{"If-Match of request", "", oi.ETag, nil},
+		{"Other ETag of read", "other", "", minio.PreConditionFailed{}},
+		{"Other If-Match of request", "", "other", minio.PreConditionFailed{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			ctx := dcontext.WithPreconditions(context.Background(), dcontext.Preconditions{IfMatch: c.ifMatch})
+
+			var buf bytes.Buffer
+			err := ol.GetObject(ctx, "bucket", "object", 0, int64(len(content)), &buf, c.etag, minio.ObjectOptions{})
+			assert.Equal(t, c.expected, err)
+
+			if c.expected == nil {
+				assert.Equal(t, content, buf.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package encrypt
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// KeySize is size of AES-256 key.
+const KeySize = 32
+
+// LoadKey returns key encoded as hex or base64, or read from keyFile if key is empty.
+// Key file holds either raw key or its hex or base64 encoding.
+func LoadKey(key, keyFile string) ([]byte, error) {
+	if key == "" && keyFile == "" {
+		return nil, errors.New("encryption key is not set")
+	}
+
+	if key != "" {
+		return decodeKey(key)
+	}
+
+	b, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == KeySize {
+		return b, nil
+	}
+
+	return decodeKey(string(b))
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if b, err := hex.DecodeString(encoded); err == nil && len(b) == KeySize {
+		return b, nil
+	}
+
+	if b, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(b) == KeySize {
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("encryption key must be %d bytes encoded as hex or base64", KeySize)
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package encrypt
+
+import (
+	"errors"
+	"io"
+)
+
+// encryptingReader reads plaintext of size from src and returns it sealed segment by segment.
+type encryptingReader struct {
+	e      *encryptingLayer
+	src    io.Reader
+	prefix []byte
+	size   int64
+
+	index, read int64
+	done        bool
+
+	plain, sealed []byte
+	// pending is part of sealed segment which wasn't read yet
+	pending []byte
+}
+
+func (e *encryptingLayer) newEncryptingReader(src io.Reader, prefix []byte, size int64) *encryptingReader {
+	return &encryptingReader{
+		e:      e,
+		src:    src,
+		prefix: prefix,
+		size:   size,
+		plain:  make([]byte, segmentSize),
+		sealed: make([]byte, 0, segmentSize+e.aead.Overhead()),
+	}
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		if err := r.seal(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
+
+// seal reads next segment of plaintext and seals it.
+func (r *encryptingReader) seal() error {
+	n := r.size - r.read
+	if n > segmentSize {
+		n = segmentSize
+	}
+
+	if _, err := io.ReadFull(r.src, r.plain[:n]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	r.read += n
+	final := r.read == r.size
+
+	if final {
+		// source is read until EOF, so its declared hashes are verified
+		var b [1]byte
+		if n, err := io.ReadFull(r.src, b[:]); n > 0 {
+			return errors.New("content is bigger than its declared size")
+		} else if err != io.EOF {
+			return err
+		}
+	}
+
+	r.pending = r.e.aead.Seal(r.sealed[:0], nonce(r.prefix, r.index, final), r.plain[:n], nil)
+	r.index++
+	r.done = final
+
+	return nil
+}
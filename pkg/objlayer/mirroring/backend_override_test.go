@@ -0,0 +1,68 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestBackendOverride(t *testing.T) {
+	cases := []struct {
+		testName      string
+		backend       dcontext.Backend
+		expectedPrime bool
+		expectedAlter bool
+	}{
+		{"Prime forced, alter not called", dcontext.PRIME, true, false},
+		{"Alter forced, prime not called", dcontext.ALTER, false, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			prime := test.NewProxyObjectLayer()
+			alter := test.NewProxyObjectLayer()
+
+			isPrimeCalled, isAlterCalled := false, false
+
+			prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+				isPrimeCalled = true
+				return minio.ObjectInfo{}, nil
+			}
+
+			alter.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+				isAlterCalled = true
+				return minio.ObjectInfo{}, nil
+			}
+
+			m := MirroringObjectLayer{
+				Prime:  prime,
+				Alter:  alter,
+				Logger: &test.MockLogger{},
+			}
+
+			ctx := dcontext.WithBackend(context.Background(), c.backend)
+
+			_, err := m.GetObjectInfo(ctx, "bucket", "object", minio.ObjectOptions{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedPrime, isPrimeCalled)
+			assert.Equal(t, c.expectedAlter, isAlterCalled)
+		})
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	b, ok := dcontext.ParseBackend(" Alter ")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, dcontext.ALTER, b)
+
+	_, ok = dcontext.ParseBackend("both")
+	assert.Equal(t, false, ok)
+}
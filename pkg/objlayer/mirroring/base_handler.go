@@ -2,11 +2,13 @@ package mirroring
 
 import (
 	"context"
+	"time"
 )
 
 // Base handler with all common fields
 type baseHandler struct {
 	primeErr, alterErr error
+	primeLatency, alterLatency time.Duration
 	ctx context.Context
 	m *MirroringObjectLayer
 }
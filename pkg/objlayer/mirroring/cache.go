@@ -0,0 +1,124 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"io"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+// isKnownMissing returns true if object was recently found missing on both backends.
+func (m *MirroringObjectLayer) isKnownMissing(bucket, object string) bool {
+	return m.Missing != nil && m.Missing.Contains(bucket, object)
+}
+
+// rememberMissing remembers object as missing if all errs report it doesn't exist.
+func (m *MirroringObjectLayer) rememberMissing(bucket, object string, errs ...error) {
+	if m.Missing == nil {
+		return
+	}
+
+	for _, err := range errs {
+		if _, ok := err.(minio.ObjectNotFound); !ok {
+			return
+		}
+	}
+
+	m.Missing.Add(bucket, object)
+}
+
+// readCached writes range of object to writer if it is cached in memory or on disk, returns false if it isn't.
+func (m *MirroringObjectLayer) readCached(bucket, object, etag string, startOffset, length int64, writer io.Writer) (bool, error) {
+	if etag == "" {
+		return false, nil
+	}
+
+	if m.Memory != nil {
+		if data, ok := m.Memory.Get(bucket, object, etag, startOffset, length); ok {
+			_, err := writer.Write(data)
+			return true, err
+		}
+	}
+
+	if m.Disk != nil {
+		return m.Disk.Get(bucket, object, etag, startOffset, length, writer)
+	}
+
+	return false, nil
+}
+
+// cachingWriter returns writer capturing object read from its beginning, and function
+// caching captured content once read succeeded. Reads which can't be cached get writer unchanged.
+func (m *MirroringObjectLayer) cachingWriter(bucket, object, etag string, startOffset, length int64, writer io.Writer) (io.Writer, func(err error)) {
+	if etag == "" || startOffset != 0 {
+		return writer, func(error) {}
+	}
+
+	writers := []io.Writer{writer}
+	var commits []func(err error)
+
+	if m.Memory != nil && m.Memory.Fits(length) {
+		var buf bytes.Buffer
+
+		writers = append(writers, &buf)
+		commits = append(commits, func(err error) {
+			if err == nil && int64(buf.Len()) == length {
+				m.Memory.Add(bucket, object, etag, buf.Bytes())
+			}
+		})
+	}
+
+	if m.Disk != nil && m.Disk.Fits(length) {
+		dw, err := m.Disk.NewWriter(bucket, object, etag)
+		if err != nil {
+			m.logCacheErr(err)
+		} else {
+			writers = append(writers, dw)
+			commits = append(commits, func(err error) {
+				if err != nil || dw.Size() != length {
+					dw.Abort()
+					return
+				}
+
+				if err = dw.Commit(); err != nil {
+					m.logCacheErr(err)
+				}
+			})
+		}
+	}
+
+	if len(commits) == 0 {
+		return writer, func(error) {}
+	}
+
+	return io.MultiWriter(writers...), func(err error) {
+		for _, commit := range commits {
+			commit(err)
+		}
+	}
+}
+
+// logCacheErr logs failure of cache, it never fails the read.
+func (m *MirroringObjectLayer) logCacheErr(err error) {
+	if m.Logger != nil {
+		m.Logger.LogE(err)
+	}
+}
+
+// invalidateObject drops cached state of object which was written or deleted.
+func (m *MirroringObjectLayer) invalidateObject(bucket, object string) {
+	if m.Missing != nil {
+		m.Missing.Invalidate(bucket, object)
+	}
+
+	if m.Memory != nil {
+		m.Memory.Invalidate(bucket, object)
+	}
+
+	if m.Disk != nil {
+		m.Disk.Invalidate(bucket, object)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/cache"
+	"storj.io/ditto/pkg/config"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestNegativeCache(t *testing.T) {
+	newMirror := func(alterErr error) (m *MirroringObjectLayer, calls *int) {
+		prime, alter := test.NewProxyObjectLayer(), test.NewProxyObjectLayer()
+
+		n := 0
+		prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			n++
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		alter.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			return minio.ObjectInfo{}, alterErr
+		}
+
+		m = &MirroringObjectLayer{
+			Prime:   prime,
+			Alter:   alter,
+			Logger:  &test.MockLogger{},
+			Config:  config.NewConfig(),
+			Missing: cache.NewNegative(time.Minute, 10),
+		}
+
+		return m, &n
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Object missing on both backends is remembered",
+			testFunc: func(t *testing.T) {
+				m, calls := newMirror(minio.ObjectNotFound{})
+
+				for i := 0; i < 3; i++ {
+					_, err := m.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+					assert.Equal(t, minio.ObjectNotFound{Bucket: "bucket", Object: "object"}, err)
+				}
+
+				assert.Equal(t, 1, *calls)
+			},
+		},
+		{
+			testName: "Object is not remembered if alter failed",
+			testFunc: func(t *testing.T) {
+				m, calls := newMirror(errors.New("alter is down"))
+
+				for i := 0; i < 3; i++ {
+					_, err := m.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, 3, *calls)
+			},
+		},
+		{
+			testName: "Written object is forgotten",
+			testFunc: func(t *testing.T) {
+				m, calls := newMirror(minio.ObjectNotFound{})
+
+				_, err := m.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+				assert.Error(t, err)
+
+				data, err := hash.NewReader(bytes.NewReader([]byte("test")), 4, "", "")
+				assert.NoError(t, err)
+
+				_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+				assert.NoError(t, err)
+
+				_, err = m.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+				assert.Error(t, err)
+				assert.Equal(t, 2, *calls)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	prime, alter := test.NewProxyObjectLayer(), test.NewProxyObjectLayer()
+
+	obj := []byte("0123456789")
+
+	calls := 0
+	prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		calls++
+		_, err := writer.Write(obj[startOffset : startOffset+length])
+		return err
+	}
+
+	m := &MirroringObjectLayer{
+		Prime:  prime,
+		Alter:  alter,
+		Logger: &test.MockLogger{},
+		Config: config.NewConfig(),
+		Memory: cache.NewMemory(100, 10),
+	}
+
+	read := func(offset, length int64, etag string) []byte {
+		var buf bytes.Buffer
+
+		err := m.GetObject(context.Background(), "bucket", "object", offset, length, &buf, etag, minio.ObjectOptions{})
+		assert.NoError(t, err)
+
+		return buf.Bytes()
+	}
+
+	assert.Equal(t, obj, read(0, 10, "etag"))
+	assert.Equal(t, obj, read(0, 10, "etag"))
+	assert.Equal(t, []byte("345"), read(3, 3, "etag"))
+	assert.Equal(t, 1, calls)
+
+	// object changed since it was cached
+	assert.Equal(t, obj, read(0, 10, "other"))
+	assert.Equal(t, 2, calls)
+
+	assert.NoError(t, m.DeleteObject(context.Background(), "bucket", "object"))
+
+	assert.Equal(t, obj, read(0, 10, "other"))
+	assert.Equal(t, 3, calls)
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	disk, err := cache.NewDisk(dir, 100, 10)
+	assert.NoError(t, err)
+
+	prime, alter := test.NewProxyObjectLayer(), test.NewProxyObjectLayer()
+
+	obj := []byte("0123456789")
+
+	calls := 0
+	prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		calls++
+		_, err := writer.Write(obj[startOffset : startOffset+length])
+		return err
+	}
+
+	m := &MirroringObjectLayer{
+		Prime:  prime,
+		Alter:  alter,
+		Logger: &test.MockLogger{},
+		Config: config.NewConfig(),
+		Disk:   disk,
+	}
+
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+
+		err := m.GetObject(context.Background(), "bucket", "object", 0, 10, &buf, "etag", minio.ObjectOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, obj, buf.Bytes())
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int64(10), disk.Size())
+
+	assert.NoError(t, m.DeleteObject(context.Background(), "bucket", "object"))
+	assert.Equal(t, int64(0), disk.Size())
+}
@@ -0,0 +1,191 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding"
+	stdhash "hash"
+	"io"
+	"strings"
+	"sync"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/buffer"
+	dmetadata "storj.io/ditto/pkg/metadata"
+)
+
+// contentHashMemLimit is size of content kept in memory while it's hashed, unless PutOptions.BufferSize is set.
+// The rest is spilled to temp file.
+const contentHashMemLimit = 1 << 20
+
+// withContentHash computes content hash of data and sets it in metadata. Metadata is written before content,
+// so data is buffered while it's hashed, returned reader replays it and has to be closed once it's written.
+func (m *MirroringObjectLayer) withContentHash(data *hash.Reader, metadata map[string]string) (*hash.Reader, io.Closer, map[string]string, error) {
+	memLimit, spillDir := contentHashMemLimit, ""
+	if opts := m.Config.PutOptions; opts != nil {
+		if opts.BufferSize > 0 {
+			memLimit = opts.BufferSize
+		}
+		spillDir = opts.SpillDir
+	}
+
+	pr, pw := buffer.NewSpillPipe(memLimit, spillDir)
+
+	sum := sha256.New()
+
+	size, err := io.Copy(pw, io.TeeReader(data, sum))
+	pw.CloseWithError(err)
+
+	if err != nil {
+		pr.Close()
+		return nil, nil, nil, err
+	}
+
+	replayed, err := hash.NewReader(pr, size, data.MD5HexString(), data.SHA256HexString())
+	if err != nil {
+		pr.Close()
+		return nil, nil, nil, err
+	}
+
+	return replayed, pr, dmetadata.WithContentHash(metadata, dmetadata.ContentHash(sum.Sum(nil))), nil
+}
+
+// maxCopySize is size of the biggest object S3 copies by single CopyObject. Content hash isn't stored
+// with bigger objects completed by multipart upload, they can't be copied onto themselves.
+const maxCopySize = 5 << 30
+
+// storeContentHash stores content hash in metadata of object completed by multipart upload,
+// which is copied onto itself. Returns info of object with content hash stored.
+func storeContentHash(ctx context.Context, ol minio.ObjectLayer, bucket, object, contentHash string) (minio.ObjectInfo, error) {
+	oi, err := ol.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+	if err != nil {
+		return oi, err
+	}
+
+	oi.UserDefined = dmetadata.WithContentHash(oi.UserDefined, contentHash)
+
+	return ol.CopyObject(ctx, bucket, object, bucket, object, oi, minio.ObjectOptions{}, minio.ObjectOptions{})
+}
+
+// partHashes computes content hashes of multipart uploads from their parts while they are uploaded, so completed
+// objects aren't read back. Parts are hashed in order of their numbers starting at 1, content hash of upload with
+// parts uploaded otherwise, e.g. concurrently or out of order, isn't known. Zero value is ready to use.
+type partHashes struct {
+	mu      sync.Mutex
+	uploads map[string]*partHash
+}
+
+// partHash is sha256 state of parts hashed so far, next is number of the part expected next.
+type partHash struct {
+	state  []byte
+	next   int
+	etags  []string
+	busy   bool
+	broken bool
+}
+
+// start starts hashing parts of upload.
+func (p *partHashes) start(uploadID string) {
+	state, err := sha256.New().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.uploads == nil {
+		p.uploads = map[string]*partHash{}
+	}
+
+	p.uploads[uploadID] = &partHash{state: state, next: 1}
+}
+
+// part returns hash content of part has to be written to, unless the part can't be hashed in order.
+// Hashing of part is finished by done.
+func (p *partHashes) part(uploadID string, partID int) (stdhash.Hash, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ph, ok := p.uploads[uploadID]
+	if !ok || ph.broken {
+		return nil, false
+	}
+
+	if ph.busy || partID != ph.next {
+		ph.broken = true
+		return nil, false
+	}
+
+	sum := sha256.New()
+	if err := sum.(encoding.BinaryUnmarshaler).UnmarshalBinary(ph.state); err != nil {
+		ph.broken = true
+		return nil, false
+	}
+
+	ph.busy = true
+
+	return sum, true
+}
+
+// done finishes hashing of part started by part. Failed part may be uploaded again.
+func (p *partHashes) done(uploadID string, sum stdhash.Hash, etag string, err error) {
+	var state []byte
+	if err == nil {
+		state, err = sum.(encoding.BinaryMarshaler).MarshalBinary()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ph, ok := p.uploads[uploadID]
+	if !ok {
+		return
+	}
+
+	ph.busy = false
+
+	if err == nil {
+		ph.state = state
+		ph.next++
+		ph.etags = append(ph.etags, etag)
+	}
+}
+
+// complete stops hashing parts of completed upload and returns its content hash,
+// unless completed object doesn't consist of hashed parts.
+func (p *partHashes) complete(uploadID string, parts []minio.CompletePart) (string, bool) {
+	p.mu.Lock()
+	ph, ok := p.uploads[uploadID]
+	delete(p.uploads, uploadID)
+	p.mu.Unlock()
+
+	if !ok || ph.broken || ph.busy || len(parts) != len(ph.etags) {
+		return "", false
+	}
+
+	for i, part := range parts {
+		if part.PartNumber != i+1 || strings.Trim(part.ETag, "\"") != strings.Trim(ph.etags[i], "\"") {
+			return "", false
+		}
+	}
+
+	sum := sha256.New()
+	if err := sum.(encoding.BinaryUnmarshaler).UnmarshalBinary(ph.state); err != nil {
+		return "", false
+	}
+
+	return dmetadata.ContentHash(sum.Sum(nil)), true
+}
+
+// abort stops hashing parts of upload.
+func (p *partHashes) abort(uploadID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.uploads, uploadID)
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/minio/minio/pkg/hash"
+	dmetadata "storj.io/ditto/pkg/metadata"
+)
+
+const contentTypeKey = "Content-Type"
+
+// sniffLen is amount of data used by http.DetectContentType.
+const sniffLen = 512
+
+// withContentType sets Content-Type of objects uploaded without it, so both backends store the same type
+// instead of applying their own defaults. Type is detected by extension of the object key,
+// or by first 512 bytes of data. Returned reader replays sniffed bytes.
+func withContentType(object string, data *hash.Reader, metadata map[string]string) (*hash.Reader, map[string]string, error) {
+	if dmetadata.Get(metadata, contentTypeKey) != "" {
+		return data, metadata, nil
+	}
+
+	if ct := mime.TypeByExtension(path.Ext(object)); ct != "" {
+		return data, dmetadata.Set(metadata, contentTypeKey, ct), nil
+	}
+
+	head := make([]byte, sniffLen)
+
+	n, err := io.ReadFull(data, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, nil, err
+	}
+
+	head = head[:n]
+
+	replayed, err := hash.NewReader(io.MultiReader(bytes.NewReader(head), data), data.Size(), data.MD5HexString(), data.SHA256HexString())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return replayed, dmetadata.Set(metadata, contentTypeKey, http.DetectContentType(head)), nil
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	dmetadata "storj.io/ditto/pkg/metadata"
+)
+
+func TestWithContentType(t *testing.T) {
+	cases := []struct {
+		testName, object, content string
+		metadata                  map[string]string
+		contentType               string
+	}{
+		{"Content-Type kept", "object.json", "{}", map[string]string{"content-type": "text/plain"}, "text/plain"},
+		{"Detected by extension", "object.json", "{}", nil, "application/json"},
+		{"Detected by content", "object", "<html><body></body></html>", nil, "text/html; charset=utf-8"},
+		{"Empty object", "object", "", nil, "text/plain; charset=utf-8"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			data, err := hash.NewReader(bytes.NewReader([]byte(c.content)), int64(len(c.content)), "", "")
+			assert.NoError(t, err)
+
+			data, metadata, err := withContentType(c.object, data, c.metadata)
+			assert.NoError(t, err)
+
+			assert.Equal(t, c.contentType, dmetadata.Get(metadata, contentTypeKey))
+
+			// sniffed bytes are replayed
+			read, err := ioutil.ReadAll(data)
+			assert.NoError(t, err)
+			assert.Equal(t, c.content, string(read))
+		})
+	}
+}
@@ -5,7 +5,11 @@ package mirroring
 
 import (
 	"context"
+	"time"
 	minio "github.com/minio/minio/cmd"
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/replication"
+	"storj.io/ditto/pkg/state"
 )
 
 func NewCopyObjectHandler(m 	     *MirroringObjectLayer,
@@ -39,6 +43,8 @@ type copyObjectHandler struct {
 	srcBucket, srcObject, destBucket, destObject string
 	srcInfo minio.ObjectInfo
 	srcOpts, dstOpts minio.ObjectOptions
+	// deferred is set if destination is replicated in background
+	deferred bool
 }
 
 func (h *copyObjectHandler) execPrime() *copyObjectHandler {
@@ -55,17 +61,84 @@ func (h *copyObjectHandler) execAlter() *copyObjectHandler {
 	return h
 }
 
+// execAlterStreamed streams destination object from prime to alter.
+// Used only when source isn't stored in alter, so native copy is impossible.
+// Big objects are mirrored in background.
+func (h *copyObjectHandler) execAlterStreamed() *copyObjectHandler {
+	task := replication.NewPutTask(h.destBucket, h.destObject)
+
+	if h.m.isAsyncPut(h.primeInfo.Size) {
+		h.m.replicate(task)
+		h.deferred = true
+		return h
+	}
+
+	h.alterErr = h.m.newReplicationHandler().Handle(h.ctx, task)
+
+	return h
+}
+
+// execAlterCopy copies object natively on alter, falling back to streaming from prime
+// if source isn't stored in alter, because it's not mirrored or wasn't replicated yet.
+func (h *copyObjectHandler) execAlterCopy() *copyObjectHandler {
+	if !h.m.isMirrored(h.srcObject) {
+		return h.execAlterStreamed()
+	}
+
+	h.execAlter()
+
+	if _, ok := h.alterErr.(minio.ObjectNotFound); ok {
+		h.alterErr = nil
+		return h.execAlterStreamed()
+	}
+
+	return h
+}
+
 func (h *copyObjectHandler) Process () (objInfo minio.ObjectInfo, err error) {
+	start := time.Now()
 	h.execPrime()
+	primeLatency := time.Since(start)
+
+	task := replication.NewCopyTask(h.srcBucket, h.srcObject, h.destBucket, h.destObject)
 
 	if h.primeErr != nil {
+		h.m.emit(h.ctx, task, events.Result(h.primeErr), events.Skipped)
 		return objInfo, h.primeErr
 	}
 
-	h.execAlter()
+	if !h.m.isMirrored(h.destObject) {
+		h.m.emit(h.ctx, task, events.Result(nil), events.Skipped)
+		return h.primeInfo, nil
+	}
+
+	if h.m.isShadowed() {
+		h.m.shadowAlter("COPY", h.destBucket, h.destObject, primeLatency, h.primeInfo.ETag, func() (string, error) {
+			h.execAlter()
+			return h.alterInfo.ETag, h.alterErr
+		})
+
+		h.m.emit(h.ctx, task, events.Result(nil), events.Skipped)
+		return h.primeInfo, nil
+	}
+
+	if h.m.isAlterDeferred() {
+		h.m.replicate(task)
+		h.m.emit(h.ctx, task, events.Result(nil), events.Deferred)
+		return h.primeInfo, nil
+	}
+
+	h.execAlterCopy()
 
 	if h.alterErr != nil {
 		//h.m.Logger.Err = h.alterErr
+		h.m.fail(task, h.alterErr)
+		h.m.emit(h.ctx, task, events.Result(nil), events.Result(h.alterErr))
+	} else if h.deferred {
+		h.m.emit(h.ctx, task, events.Result(nil), events.Deferred)
+	} else {
+		h.m.track(h.destBucket, h.destObject, state.IN_SYNC, nil)
+		h.m.emit(h.ctx, task, events.Result(nil), events.Result(nil))
 	}
 
 	return h.primeInfo, nil
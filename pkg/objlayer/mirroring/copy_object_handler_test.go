@@ -9,7 +9,11 @@ import (
 	"github.com/stretchr/testify/assert"
 		"testing"
 
+	"io"
+	"io/ioutil"
+
 	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
 	test "storj.io/ditto/pkg/utils/testing_utils"
 )
 
@@ -110,3 +114,52 @@ func TestCopyObjectHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyObjectHandlerSourceMissingInAlter(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	m := MirroringObjectLayer{
+		Prime:  prime,
+		Alter:  alter,
+		Logger: &test.MockLogger{},
+	}
+
+	content := []byte("test")
+
+	prime.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{Size: int64(len(content))}, nil
+	}
+
+	prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content))}, nil
+	}
+
+	prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		_, err := writer.Write(content)
+		return err
+	}
+
+	alter.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: srcBucket, Object: srcObject}
+	}
+
+	var streamedTo string
+	var streamed []byte
+
+	alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		streamedTo = object
+		streamed, _ = ioutil.ReadAll(data)
+		return minio.ObjectInfo{}, nil
+	}
+
+	h := NewCopyObjectHandler(&m, context.Background(), "bucket", "src", "bucket", "dst",
+		minio.ObjectInfo{}, minio.ObjectOptions{}, minio.ObjectOptions{})
+
+	_, err := h.Process()
+
+	assert.NoError(t, err)
+	assert.Nil(t, h.alterErr)
+	assert.Equal(t, "dst", streamedTo)
+	assert.Equal(t, content, streamed)
+}
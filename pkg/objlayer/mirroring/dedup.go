@@ -0,0 +1,50 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+
+	minio "github.com/minio/minio/cmd"
+	"storj.io/ditto/pkg/config"
+	dmetadata "storj.io/ditto/pkg/metadata"
+	"storj.io/ditto/pkg/replication"
+)
+
+// IsDeduplicated returns true if writes of objects alter already holds should be skipped, see config.PutOptions.Dedup.
+// Duplicates are recognized by content hash computed by ditto, so it has to be stored with objects.
+func IsDeduplicated(cfg *config.Config) bool {
+	return cfg != nil && cfg.PutOptions != nil && cfg.PutOptions.Dedup && cfg.Metadata != nil && cfg.Metadata.ContentHash
+}
+
+// newReplicationHandler creates handler replaying tasks from prime to alter, deduplicating them if configured.
+func (m *MirroringObjectLayer) newReplicationHandler() replication.Handler {
+	if IsDeduplicated(m.Config) {
+		return NewDedupReplicationHandler(m.Prime, m.Alter)
+	}
+
+	return NewReplicationHandler(m.Prime, m.Alter)
+}
+
+// isDuplicate returns true if ol holds object of size with content hash and metadata equal to metadata being written,
+// which holds content hash computed while it's written, see withContentHash.
+// Negative size means unknown size. Object without content hash is never duplicate,
+// failed lookup, e.g. of missing object, means object has to be written.
+func isDuplicate(ctx context.Context, ol minio.ObjectLayer, bucket, object string, size int64, metadata map[string]string) bool {
+	contentHash := dmetadata.GetContentHash(metadata)
+	if contentHash == "" {
+		return false
+	}
+
+	oi, err := ol.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+	if err != nil {
+		return false
+	}
+
+	if size >= 0 && oi.Size != size {
+		return false
+	}
+
+	return dmetadata.GetContentHash(oi.UserDefined) == contentHash && dmetadata.Equal(metadata, oi.UserDefined)
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	dmetadata "storj.io/ditto/pkg/metadata"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestPutObjectDedup(t *testing.T) {
+	content := []byte("test")
+	sha := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	cfg := &config.Config{
+		PutOptions: &config.PutOptions{Dedup: true},
+		Metadata:   &config.MetadataOptions{ContentHash: true},
+	}
+
+	// put writes object with declared sha and returns content received by prime and alter
+	put := func(t *testing.T, cfg *config.Config, sha string, alterInfo minio.ObjectInfo) (primeData, alterData []byte) {
+		prime := test.NewProxyObjectLayer()
+		alter := test.NewProxyObjectLayer()
+
+		prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			var err error
+			primeData, err = ioutil.ReadAll(data)
+			return minio.ObjectInfo{}, err
+		}
+
+		alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			var err error
+			alterData, err = ioutil.ReadAll(data)
+			return minio.ObjectInfo{}, err
+		}
+
+		alter.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			if alterInfo.Size == 0 {
+				return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+			}
+
+			return alterInfo, nil
+		}
+
+		m := &MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}, Config: cfg}
+
+		data, err := hash.NewReader(bytes.NewReader(content), int64(len(content)), "", sha)
+		assert.NoError(t, err)
+
+		_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+		assert.NoError(t, err)
+
+		return primeData, alterData
+	}
+
+	stored := minio.ObjectInfo{
+		Size:        int64(len(content)),
+		UserDefined: map[string]string{dmetadata.ContentHashKey: "sha256:" + sha},
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Identical object isn't written to alter",
+			func(t *testing.T) {
+				primeData, alterData := put(t, cfg, sha, stored)
+
+				assert.Equal(t, content, primeData)
+				assert.Nil(t, alterData)
+			},
+		},
+		{
+			"Object missing on alter is written",
+			func(t *testing.T) {
+				primeData, alterData := put(t, cfg, sha, minio.ObjectInfo{})
+
+				assert.Equal(t, content, primeData)
+				assert.Equal(t, content, alterData)
+			},
+		},
+		{
+			"Object with different hash is written",
+			func(t *testing.T) {
+				changed := stored
+				changed.UserDefined = map[string]string{dmetadata.ContentHashKey: "sha256:changed"}
+
+				_, alterData := put(t, cfg, sha, changed)
+
+				assert.Equal(t, content, alterData)
+			},
+		},
+		{
+			"Object with different metadata is written",
+			func(t *testing.T) {
+				changed := stored
+				changed.UserDefined = map[string]string{dmetadata.ContentHashKey: "sha256:" + sha, "X-Amz-Meta-Owner": "someone"}
+
+				_, alterData := put(t, cfg, sha, changed)
+
+				assert.Equal(t, content, alterData)
+			},
+		},
+		{
+			"Object without declared hash is compared by computed hash",
+			func(t *testing.T) {
+				_, alterData := put(t, cfg, "", stored)
+
+				assert.Nil(t, alterData)
+			},
+		},
+		{
+			"Dedup disabled writes identical object",
+			func(t *testing.T) {
+				_, alterData := put(t, &config.Config{Metadata: cfg.Metadata}, sha, stored)
+
+				assert.Equal(t, content, alterData)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
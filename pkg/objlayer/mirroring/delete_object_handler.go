@@ -5,6 +5,9 @@ package mirroring
 
 import (
 	"context"
+	"time"
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/replication"
 )
 
 func NewDeleteObjectHandler(m *MirroringObjectLayer, ctx context.Context, bucket, object string) *deleteObjectHandler {
@@ -37,16 +40,45 @@ func (h *deleteObjectHandler) execAlter() *deleteObjectHandler {
 }
 
 func (h *deleteObjectHandler) Process () error {
+	start := time.Now()
 	h.execPrime()
+	primeLatency := time.Since(start)
+
+	task := replication.NewDeleteTask(h.bucket, h.object)
 
 	if h.primeErr != nil {
+		h.m.emit(h.ctx, task, events.Result(h.primeErr), events.Skipped)
 		return  h.primeErr
 	}
 
+	if !h.m.isMirrored(h.object) {
+		h.m.emit(h.ctx, task, events.Result(nil), events.Skipped)
+		return nil
+	}
+
+	if h.m.isShadowed() {
+		h.m.shadowAlter("DELETE", h.bucket, h.object, primeLatency, "", func() (string, error) {
+			return "", h.execAlter().alterErr
+		})
+
+		h.m.emit(h.ctx, task, events.Result(nil), events.Skipped)
+		return nil
+	}
+
+	if h.m.isAlterDeferred() {
+		h.m.replicate(task)
+		h.m.emit(h.ctx, task, events.Result(nil), events.Deferred)
+		return nil
+	}
+
 	h.execAlter()
+	h.m.emit(h.ctx, task, events.Result(nil), events.Result(h.alterErr))
 
 	if h.alterErr != nil {
 		//h.m.Logger.Err = h.alterErr
+		h.m.fail(replication.NewDeleteTask(h.bucket, h.object), h.alterErr)
+	} else {
+		h.m.untrack(h.bucket, h.object)
 	}
 
 	return nil
@@ -9,6 +9,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"testing"
 
+	"storj.io/ditto/pkg/breaker"
+	"storj.io/ditto/pkg/replication"
 	test "storj.io/ditto/pkg/utils/testing_utils"
 )
 
@@ -107,3 +109,78 @@ func TestDeleteObjectHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteObjectHandlerAlterBreakerOpen(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	alterBreaker := breaker.NewBreaker(1, 0, nil)
+	alterBreaker.Report(errors.New("alter is down"))
+
+	queued := make(chan replication.Task, 1)
+	handler := replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		queued <- task
+		return nil
+	})
+
+	m := MirroringObjectLayer{
+		Prime:        prime,
+		Alter:        alter,
+		Logger:       &test.MockLogger{},
+		Replication:  replication.NewQueue(handler, nil, 1, 1),
+		AlterBreaker: alterBreaker,
+	}
+
+	isAlterCalled := false
+	alter.DeleteObjectFunc = func(ctx context.Context, bucket string, object string) (err error) {
+		isAlterCalled = true
+		return nil
+	}
+
+	err := m.DeleteObject(context.Background(), "bucket", "object")
+	assert.NoError(t, err)
+
+	task := <-queued
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	assert.Equal(t, false, isAlterCalled)
+	assert.Equal(t, replication.DELETE, task.Operation)
+}
+
+func TestDeleteObjectHandlerReplicationPaused(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	queued := make(chan replication.Task, 1)
+	handler := replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		queued <- task
+		return nil
+	})
+
+	queue := replication.NewQueue(handler, nil, 1, 1)
+	queue.Pause()
+
+	m := MirroringObjectLayer{
+		Prime:       prime,
+		Alter:       alter,
+		Logger:      &test.MockLogger{},
+		Replication: queue,
+	}
+
+	isAlterCalled := false
+	alter.DeleteObjectFunc = func(ctx context.Context, bucket string, object string) (err error) {
+		isAlterCalled = true
+		return nil
+	}
+
+	err := m.DeleteObject(context.Background(), "bucket", "object")
+	assert.NoError(t, err)
+
+	queue.Resume()
+
+	task := <-queued
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	assert.Equal(t, false, isAlterCalled)
+	assert.Equal(t, replication.DELETE, task.Operation)
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"time"
+
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/objlayer/audit"
+	"storj.io/ditto/pkg/replication"
+)
+
+// emit exports event about mirrored operation executed with ctx with outcomes on both backends,
+// if export is enabled, and reports the outcomes to audit.
+func (m *MirroringObjectLayer) emit(ctx context.Context, task replication.Task, prime, alter events.Outcome) {
+	audit.Report(ctx, prime, alter)
+
+	if m.Events == nil {
+		return
+	}
+
+	m.Events.Emit(events.Event{
+		Time:      time.Now().UTC(),
+		Operation: task.Operation,
+		Bucket:    task.Bucket,
+		Object:    task.Object,
+		SrcBucket: task.SrcBucket,
+		SrcObject: task.SrcObject,
+		Prime:     prime,
+		Alter:     alter,
+	})
+}
+
+// deferredOutcome is outcome of backend written in background after the other one returned err.
+func deferredOutcome(err error) events.Outcome {
+	if err != nil {
+		return events.Skipped
+	}
+
+	return events.Deferred
+}
+
+// EmitDeferredOutcomes exports final outcome of operations deferred to Replication and Backfill queues
+// once they are replicated or dropped. Operations are deferred only after the other backend succeeded,
+// so it's reported OK again.
+func (m *MirroringObjectLayer) EmitDeferredOutcomes() {
+	if m.Events == nil {
+		return
+	}
+
+	if m.Replication != nil {
+		m.Replication.AddObserver(func(task replication.Task, lag time.Duration, err error) {
+			m.emit(context.Background(), task, events.Result(nil), events.Result(err))
+		})
+	}
+
+	if m.Backfill != nil {
+		m.Backfill.AddObserver(func(task replication.Task, lag time.Duration, err error) {
+			m.emit(context.Background(), task, events.Result(err), events.Result(nil))
+		})
+	}
+}
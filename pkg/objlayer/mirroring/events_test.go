@@ -0,0 +1,130 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+type eventsPublisher struct {
+	events []events.Event
+}
+
+func (p *eventsPublisher) Publish(topic, key string, data []byte) error {
+	var e events.Event
+	err := json.Unmarshal(data, &e)
+	p.events = append(p.events, e)
+
+	return err
+}
+
+func (p *eventsPublisher) Close() error {
+	return nil
+}
+
+func TestDeleteObjectEmitted(t *testing.T) {
+	cases := []struct {
+		testName      string
+		primeErr      error
+		alterErr      error
+		expectedPrime string
+		expectedAlter string
+	}{
+		{"Both backends succeeded", nil, nil, events.OK, events.OK},
+		{"Alter failure emitted", nil, errors.New("test error"), events.OK, events.FAILED},
+		{"Alter skipped after prime failure", errors.New("test error"), nil, events.FAILED, events.SKIPPED},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			prime := test.NewProxyObjectLayer()
+			alter := test.NewProxyObjectLayer()
+
+			prime.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+				return c.primeErr
+			}
+
+			alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+				return c.alterErr
+			}
+
+			p := &eventsPublisher{}
+			bus := events.NewBus(p, "topic", &test.MockLogger{})
+
+			m := MirroringObjectLayer{
+				Prime:  prime,
+				Alter:  alter,
+				Logger: &test.MockLogger{},
+				Events: bus,
+			}
+
+			m.DeleteObject(context.Background(), "bucket", "object")
+			assert.NoError(t, bus.Close())
+
+			assert.Equal(t, 1, len(p.events))
+			assert.Equal(t, replication.DELETE, p.events[0].Operation)
+			assert.Equal(t, "object", p.events[0].Object)
+			assert.Equal(t, c.expectedPrime, p.events[0].Prime.Status)
+			assert.Equal(t, c.expectedAlter, p.events[0].Alter.Status)
+		})
+	}
+}
+
+func TestDeferredOutcomeEmitted(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		_, err := ioutil.ReadAll(data)
+		return minio.ObjectInfo{}, err
+	}
+
+	p := &eventsPublisher{}
+	bus := events.NewBus(p, "topic", &test.MockLogger{})
+
+	queue := replication.NewQueue(replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		return errors.New("alter is down")
+	}), nil, 1, 10)
+	queue.SetRetryable(func(err error) bool { return false })
+
+	m := MirroringObjectLayer{
+		Prime:       prime,
+		Alter:       alter,
+		Logger:      &test.MockLogger{},
+		Config:      &config.Config{PutOptions: &config.PutOptions{AsyncSizeThreshold: 1}},
+		Replication: queue,
+		Events:      bus,
+	}
+	m.EmitDeferredOutcomes()
+
+	data, err := hash.NewReader(bytes.NewReader([]byte("test")), 4, "", "")
+	assert.NoError(t, err)
+
+	_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	queue.Close()
+	assert.NoError(t, bus.Close())
+
+	if assert.Equal(t, 2, len(p.events)) {
+		assert.Equal(t, events.DEFERRED, p.events[0].Alter.Status)
+		assert.Equal(t, replication.PUT, p.events[1].Operation)
+		assert.Equal(t, events.OK, p.events[1].Prime.Status)
+		assert.Equal(t, events.FAILED, p.events[1].Alter.Status)
+		assert.Equal(t, "alter is down", p.events[1].Alter.Error)
+	}
+}
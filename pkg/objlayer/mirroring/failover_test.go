@@ -0,0 +1,161 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/failover"
+	"storj.io/ditto/pkg/journal"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestFailover(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	ctrl := failover.NewController(&config.FailoverOptions{Enabled: true, FailureThreshold: 1}, nil)
+	ctrl.ReportPrime(minio.OperationTimedOut{})
+
+	queued := make(chan replication.Task, 1)
+	handler := replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		queued <- task
+		return nil
+	})
+
+	m := MirroringObjectLayer{
+		Prime:    prime,
+		Alter:    alter,
+		Logger:   &test.MockLogger{},
+		Failover: ctrl,
+		Backfill: replication.NewQueue(handler, nil, 1, 1),
+	}
+
+	isPrimeCalled, isAlterCalled := false, false
+
+	prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		isPrimeCalled = true
+		return minio.ObjectInfo{}, nil
+	}
+
+	alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		isAlterCalled = true
+		return minio.ObjectInfo{}, nil
+	}
+
+	prime.GetObjectInfoFunc = getObjectInfoMock("prime", 1)
+	alter.GetObjectInfoFunc = getObjectInfoMock("alter", 1)
+
+	buff := []byte("test")
+	data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+	assert.NoError(t, err)
+
+	_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, false, isPrimeCalled)
+	assert.Equal(t, true, isAlterCalled)
+
+	task := <-queued
+	assert.Equal(t, replication.PUT, task.Operation)
+	assert.Equal(t, "object", task.Object)
+
+	oi, err := m.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "alter", oi.ETag)
+}
+
+func TestFailoverBackfillJournaled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backfill-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	jrnl, err := journal.Open(filepath.Join(dir, "backfill.db"))
+	assert.NoError(t, err)
+
+	ctrl := failover.NewController(&config.FailoverOptions{Enabled: true, FailureThreshold: 1}, nil)
+	ctrl.ReportPrime(minio.OperationTimedOut{})
+
+	// tasks wait for prime to recover, like backfill handler does
+	backfill := replication.NewQueue(failover.NewBackfillHandler(replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		return nil
+	}), ctrl), nil, 1, 1)
+	backfill.SetDropHandler(func(task replication.Task, err error) {
+		assert.NoError(t, jrnl.Append(task))
+	})
+
+	alter := test.NewProxyObjectLayer()
+	alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		return nil
+	}
+
+	m := MirroringObjectLayer{
+		Prime:           test.NewProxyObjectLayer(),
+		Alter:           alter,
+		Logger:          &test.MockLogger{},
+		Failover:        ctrl,
+		Backfill:        backfill,
+		BackfillJournal: jrnl,
+	}
+
+	// queue holds single task besides one waiting in worker, the rest doesn't fit and is journaled
+	for _, object := range []string{"first", "second", "third"} {
+		assert.NoError(t, m.DeleteObject(context.Background(), "bucket", object))
+	}
+
+	// tasks left in backfill are journaled on shutdown while prime is down
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	jrnl, err = journal.Open(filepath.Join(dir, "backfill.db"))
+	assert.NoError(t, err)
+	defer jrnl.Close()
+
+	entries, err := jrnl.Entries(0)
+	assert.NoError(t, err)
+
+	var objects []string
+	for _, e := range entries {
+		objects = append(objects, e.Task.Object)
+	}
+
+	assert.ElementsMatch(t, []string{"first", "second", "third"}, objects)
+}
+
+func TestFailoverBackfillFull(t *testing.T) {
+	ctrl := failover.NewController(&config.FailoverOptions{Enabled: true, FailureThreshold: 1}, nil)
+	ctrl.ReportPrime(minio.OperationTimedOut{})
+
+	backfill := replication.NewQueue(failover.NewBackfillHandler(replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		return nil
+	}), ctrl), nil, 1, 1)
+
+	alter := test.NewProxyObjectLayer()
+	alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		return nil
+	}
+
+	m := MirroringObjectLayer{
+		Prime:    test.NewProxyObjectLayer(),
+		Alter:    alter,
+		Logger:   &test.MockLogger{},
+		Failover: ctrl,
+		Backfill: backfill,
+	}
+
+	// clients don't wait for backfill parked until prime recovers, task which doesn't fit is only logged
+	for _, object := range []string{"first", "second", "third"} {
+		assert.NoError(t, m.DeleteObject(context.Background(), "bucket", object))
+	}
+
+	assert.NoError(t, m.Shutdown(context.Background()))
+}
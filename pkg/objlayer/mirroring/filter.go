@@ -0,0 +1,106 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"regexp"
+	"strings"
+
+	"storj.io/ditto/pkg/config"
+)
+
+// objectFilter decides whether object should be mirrored to alter
+// based on configured key prefixes and regular expressions.
+type objectFilter struct {
+	includePrefixes []string
+	excludePrefixes []string
+	includePatterns []*regexp.Regexp
+	excludePatterns []*regexp.Regexp
+}
+
+// Creates new objectFilter from FilterOptions.
+// Returns error if any of the patterns could not be compiled.
+func newObjectFilter(opts *config.FilterOptions) (*objectFilter, error) {
+	f := &objectFilter{}
+
+	if opts == nil {
+		return f, nil
+	}
+
+	f.includePrefixes = opts.IncludePrefixes
+	f.excludePrefixes = opts.ExcludePrefixes
+
+	var err error
+
+	f.includePatterns, err = compilePatterns(opts.IncludePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	f.excludePatterns, err = compilePatterns(opts.ExcludePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// isMirrored returns true if object passes include rules and is not excluded.
+// Empty include rules means that every object is included.
+func (f *objectFilter) isMirrored(object string) bool {
+	if f == nil {
+		return true
+	}
+
+	included := len(f.includePrefixes) == 0 && len(f.includePatterns) == 0
+
+	if !included {
+		included = hasAnyPrefix(object, f.includePrefixes) || matchesAny(object, f.includePatterns)
+	}
+
+	if !included {
+		return false
+	}
+
+	return !hasAnyPrefix(object, f.excludePrefixes) && !matchesAny(object, f.excludePatterns)
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+func hasAnyPrefix(object string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if p != "" && strings.HasPrefix(object, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAny(object string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(object) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestObjectFilter(t *testing.T) {
+	cases := []struct {
+		testName string
+		opts     *config.FilterOptions
+		object   string
+		expected bool
+	}{
+		{
+			testName: "Nil options, mirrored",
+			opts:     nil,
+			object:   "tmp/object",
+			expected: true,
+		},
+		{
+			testName: "Excluded prefix, not mirrored",
+			opts:     &config.FilterOptions{ExcludePrefixes: []string{"tmp/", "cache/"}},
+			object:   "cache/object",
+			expected: false,
+		},
+		{
+			testName: "Not excluded prefix, mirrored",
+			opts:     &config.FilterOptions{ExcludePrefixes: []string{"tmp/", "cache/"}},
+			object:   "photos/tmp/object",
+			expected: true,
+		},
+		{
+			testName: "Not included prefix, not mirrored",
+			opts:     &config.FilterOptions{IncludePrefixes: []string{"photos/"}},
+			object:   "docs/object",
+			expected: false,
+		},
+		{
+			testName: "Included by pattern, mirrored",
+			opts:     &config.FilterOptions{IncludePatterns: []string{`\.jpg$`}},
+			object:   "docs/object.jpg",
+			expected: true,
+		},
+		{
+			testName: "Included but excluded by pattern, not mirrored",
+			opts:     &config.FilterOptions{IncludePrefixes: []string{"photos/"}, ExcludePatterns: []string{`\.tmp$`}},
+			object:   "photos/object.tmp",
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			f, err := newObjectFilter(c.opts)
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, f.isMirrored(c.object))
+		})
+	}
+}
+
+func TestObjectFilterInvalidPattern(t *testing.T) {
+	f, err := newObjectFilter(&config.FilterOptions{ExcludePatterns: []string{"("}})
+
+	assert.Error(t, err)
+	assert.Nil(t, f)
+}
+
+func TestIsMirroredInvalidFilter(t *testing.T) {
+	lg := &test.MockLogger{}
+	m := MirroringObjectLayer{
+		Logger: lg,
+		Config: config.NewConfig().WithFilterOptions(nil, nil, nil, []string{"("}),
+	}
+
+	assert.Equal(t, false, m.isMirrored("object"))
+	assert.Equal(t, false, m.isMirrored("tmp/object"))
+	assert.Equal(t, 1, lg.LogECount())
+}
+
+func TestDeleteObjectHandlerFiltered(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	m := MirroringObjectLayer{
+		Prime:  prime,
+		Alter:  alter,
+		Logger: &test.MockLogger{},
+		Config: config.NewConfig().WithFilterOptions(nil, []string{"tmp/"}, nil, nil),
+	}
+
+	isAlterCalled := false
+	alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		isAlterCalled = true
+		return nil
+	}
+
+	alter.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		isAlterCalled = true
+		return minio.ObjectInfo{}, nil
+	}
+
+	err := m.DeleteObject(context.Background(), "bucket", "tmp/object")
+	assert.NoError(t, err)
+	assert.Equal(t, false, isAlterCalled)
+
+	_, err = m.CopyObject(context.Background(), "bucket", "object", "bucket", "tmp/object", minio.ObjectInfo{}, minio.ObjectOptions{}, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, false, isAlterCalled)
+
+	err = m.DeleteObject(context.Background(), "bucket", "object")
+	assert.NoError(t, err)
+	assert.Equal(t, true, isAlterCalled)
+}
@@ -5,6 +5,7 @@ package mirroring
 
 import (
 	"context"
+	"time"
 	minio "github.com/minio/minio/cmd"
 )
 
@@ -29,13 +30,17 @@ type getBucketInfoHandler struct {
 }
 
 func (h *getBucketInfoHandler) execPrime() *getBucketInfoHandler {
+	start := time.Now()
 	h.primeInfo, h.primeErr = h.m.Prime.GetBucketInfo(h.ctx, h.bucket)
+	h.primeLatency = time.Since(start)
 
 	return h
 }
 
 func (h *getBucketInfoHandler) execAlter() *getBucketInfoHandler {
+	start := time.Now()
 	h.alterInfo, h.alterErr = h.m.Alter.GetBucketInfo(h.ctx, h.bucket)
+	h.alterLatency = time.Since(start)
 
 	return h
 }
@@ -48,13 +53,13 @@ func (h *getBucketInfoHandler) Process () (objInfo minio.BucketInfo, err error)
 		return h.primeInfo, nil
 	}
 
-	h.m.Logger.LogE(h.primeErr)
+	h.logPrimeErr("GetBucketInfo", h.bucket, "")
 
 	h.execAlter()
 
 	if h.alterErr != nil {
 
-		h.m.Logger.LogE(h.alterErr)
+		h.logAlterErr("GetBucketInfo", h.bucket, "")
 
 		return h.alterInfo, h.primeErr
 	}
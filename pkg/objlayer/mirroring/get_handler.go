@@ -3,7 +3,14 @@ package mirroring
 import (
 	minio "github.com/minio/minio/cmd"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"io"
+	"io/ioutil"
+	"sync"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/utils"
 		)
 
 type getAsyncHandler struct {
@@ -11,7 +18,8 @@ type getAsyncHandler struct {
 }
 
 func(h getAsyncHandler) GetObjectAsync(ctx context.Context, bucket string, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) <-chan error {
-	errc := make(chan error)
+	// buffered, so hedged read may abandon loser without leaking its goroutine
+	errc := make(chan error, 1)
 	getTask := func(errc chan<- error) {
 		err := h.ol.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
 		errc <- err
@@ -24,28 +32,214 @@ func(h getAsyncHandler) GetObjectAsync(ctx context.Context, bucket string, objec
 type getHandler struct {
 	prime, alter getAsyncHandler
 	throwImmediately bool
+
+	// verifyLogger enables read verification, divergence is reported to it.
+	verifyLogger l.Logger
+	// hedged makes handler read from both backends and serve the one which responds first.
+	hedged bool
+	// alterFirst makes handler read alter first and fall back to prime.
+	alterFirst bool
 }
 
 func newGetHandler(prime, alter minio.ObjectLayer, thrImm bool) *getHandler {
-	return &getHandler{getAsyncHandler{prime}, getAsyncHandler{alter}, thrImm}
+	return &getHandler{prime: getAsyncHandler{prime}, alter: getAsyncHandler{alter}, throwImmediately: thrImm}
+}
+
+// withReadVerification makes handler read object from both backends and report divergence to lg.
+func (h *getHandler) withReadVerification(lg l.Logger) *getHandler {
+	h.verifyLogger = lg
+
+	return h
+}
+
+// withAlterFirst makes handler read alter first, e.g. because it has been faster recently.
+func (h *getHandler) withAlterFirst() *getHandler {
+	h.alterFirst = true
+
+	return h
+}
+
+// withHedging makes handler race reads of both backends.
+func (h *getHandler) withHedging() *getHandler {
+	h.hedged = true
+
+	return h
 }
 
 func (h *getHandler) process(ctx context.Context, bucket string, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) (err error) {
+	if h.verifyLogger != nil {
+		return h.processVerified(ctx, bucket, object, startOffset, length, writer, etag, opts)
+	}
+
+	if h.hedged {
+		return h.processHedged(ctx, bucket, object, startOffset, length, writer, etag, opts)
+	}
+
+	// etag describes prime copy, alter copy may have different one
+	first, second := h.prime, h.alter
+	firstETag, secondETag := etag, ""
+	if h.alterFirst {
+		first, second = h.alter, h.prime
+		firstETag, secondETag = "", etag
+	}
+
 	wrtwrap := &writeCounter{w : writer}
 
-	err = <-h.prime.GetObjectAsync(ctx, bucket, object, startOffset, length, wrtwrap, etag, opts)
+	err = <-first.GetObjectAsync(ctx, bucket, object, startOffset, length, wrtwrap, firstETag, opts)
 
 	if h.throwImmediately {
 		return
 	}
 
+	// failed precondition is an answer, not a failure of prime
+	if _, ok := err.(minio.PreConditionFailed); ok {
+		return
+	}
+
 	if err != nil {
-		err = <-h.alter.GetObjectAsync(ctx, bucket, object, startOffset + wrtwrap.bcount, length - wrtwrap.bcount, wrtwrap, etag, opts)
+		err = <-second.GetObjectAsync(ctx, bucket, object, startOffset + wrtwrap.bcount, length - wrtwrap.bcount, wrtwrap, secondETag, opts)
+	}
+
+	return
+}
+
+// processVerified serves prime copy while alter copy is read concurrently,
+// checksums of both copies are compared once reads are finished.
+func (h *getHandler) processVerified(ctx context.Context, bucket string, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) (err error) {
+	ctxa, cancelf := context.WithCancel(ctx)
+	defer cancelf()
+
+	phash, ahash := sha256.New(), sha256.New()
+
+	errAlter := h.alter.GetObjectAsync(ctxa, bucket, object, startOffset, length, io.MultiWriter(ioutil.Discard, ahash), "", opts)
+
+	err = <-h.prime.GetObjectAsync(ctx, bucket, object, startOffset, length, io.MultiWriter(writer, phash), etag, opts)
+	if err != nil {
+		cancelf()
+		<-errAlter
+
+		return
+	}
+
+	if erra := <-errAlter; erra != nil {
+		h.verifyLogger.LogE(erra)
+		return
+	}
+
+	psum, asum := hex.EncodeToString(phash.Sum(nil)), hex.EncodeToString(ahash.Sum(nil))
+	if psum != asum {
+		h.verifyLogger.LogE(ContentMismatch{Bucket: bucket, Object: object, PrimeSHA256: psum, AlterSHA256: asum})
 	}
 
 	return
 }
 
+// processHedged reads object from both backends concurrently. Backend which writes first serves the client
+// and read of the other one is canceled. If the winner fails mid-stream, read is resumed from the other backend.
+// Failed precondition of prime is returned unless alter has already won.
+func (h *getHandler) processHedged(ctx context.Context, bucket string, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	ctxp, cancelp := context.WithCancel(ctx)
+	defer cancelp()
+
+	ctxa, cancela := context.WithCancel(ctx)
+	defer cancela()
+
+	race := &hedgeRace{w: writer}
+	pw, aw := &hedgeWriter{race: race, cancelLoser: cancela}, &hedgeWriter{race: race, cancelLoser: cancelp}
+
+	errp := h.prime.GetObjectAsync(ctxp, bucket, object, startOffset, length, pw, etag, opts)
+	erra := h.alter.GetObjectAsync(ctxa, bucket, object, startOffset, length, aw, "", opts)
+
+	var errs []error
+	for i := 0; i < 2; i++ {
+		var (
+			w     *hedgeWriter
+			other getAsyncHandler
+			err   error
+		)
+
+		select {
+		case err = <-errp:
+			w, other = pw, h.alter
+		case err = <-erra:
+			w, other = aw, h.prime
+		}
+
+		won := race.isWinner(w)
+
+		switch {
+		case err == nil && (won || race.claim(w)):
+			return nil
+
+		case won:
+			otherETag := ""
+			if w == aw {
+				otherETag = etag
+			}
+
+			return <-other.GetObjectAsync(ctx, bucket, object, startOffset+w.n, length-w.n, writer, otherETag, opts)
+
+		case race.isWinner(nil):
+			if _, ok := err.(minio.PreConditionFailed); ok {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return utils.CombineErrors(errs)
+}
+
+// errLostRace is returned to backend which started writing after the other one.
+var errLostRace = errors.New("hedged read lost the race")
+
+// hedgeRace passes writes of the first backend which writes to w.
+type hedgeRace struct {
+	mu     sync.Mutex
+	w      io.Writer
+	winner *hedgeWriter
+}
+
+// claim makes w winner unless there is one already, returns true if w is the winner.
+func (r *hedgeRace) claim(w *hedgeWriter) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.winner == nil {
+		r.winner = w
+		w.cancelLoser()
+	}
+
+	return r.winner == w
+}
+
+// isWinner returns true if w is the winner, isWinner(nil) returns true if there is no winner yet.
+func (r *hedgeRace) isWinner(w *hedgeWriter) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.winner == w
+}
+
+// hedgeWriter is writer of single backend of hedged read, it counts bytes passed to the client.
+type hedgeWriter struct {
+	race        *hedgeRace
+	cancelLoser context.CancelFunc
+	n           int64
+}
+
+func (w *hedgeWriter) Write(b []byte) (int, error) {
+	if !w.race.claim(w) {
+		return 0, errLostRace
+	}
+
+	n, err := w.race.w.Write(b)
+	w.n += int64(n)
+
+	return n, err
+}
+
 type writeCounter struct {
 	w io.Writer
 	bcount int64
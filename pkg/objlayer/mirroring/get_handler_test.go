@@ -9,6 +9,8 @@ import (
 	"io"
 	"bytes"
 	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"time"
 )
 
 type getFunc func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) (err error)
@@ -99,6 +101,181 @@ func TestGetHandler(t *testing.T) {
 				assert.Equal(t, append(obj1[:10], obj2[10:]...), data.Bytes())
 			},
 		},
+		{
+			"Verify reads: divergence logged, prime served",
+			func(t *testing.T) {
+				obj1 := []byte("prime content")
+				obj2 := []byte("alter content")
+
+				prime.GetObjectFunc = getObjectFuncFact(func(writer io.Writer, offset, length int64) error {
+					_, err := writer.Write(obj1)
+					return err
+				})
+
+				alter.GetObjectFunc = getObjectFuncFact(func(writer io.Writer, offset, length int64) error {
+					_, err := writer.Write(obj2)
+					return err
+				})
+
+				lg := &tutils.MockLogger{}
+				mv := MirroringObjectLayer{
+					Prime:  prime,
+					Alter:  alter,
+					Logger: lg,
+					Config: config.NewConfig().WithGetObjectOptions(nil),
+				}
+				mv.Config.GetObjectOptions.VerifyReads = true
+
+				data := bytes.NewBuffer(nil)
+
+				err := mv.GetObject(context.Background(), "bucket", "object", 0, int64(len(obj1)), data, "etag", opts)
+				assert.NoError(t, err)
+				assert.Equal(t, obj1, data.Bytes())
+				assert.Equal(t, 1, lg.LogECount())
+
+				prm, err := lg.GetLastLogEParam()
+				assert.NoError(t, err)
+
+				_, ok := prm.(ContentMismatch)
+				assert.Equal(t, true, ok)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestGetHandlerHedged(t *testing.T) {
+	obj := []byte("abc45678901234567890")
+
+	serve := func(writer io.Writer, offset, length int64) error {
+		_, err := writer.Write(obj[offset : offset+length])
+		return err
+	}
+
+	// serveOnCancel serves object once ctx is canceled and closes canceled, so only the loser blocks
+	serveOnCancel := func(canceled chan struct{}) getFunc {
+		return func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+			<-ctx.Done()
+			defer close(canceled)
+
+			return serve(writer, startOffset, length)
+		}
+	}
+
+	// loser may still be running once case is finished, so every case has its own backends
+	process := func(prime, alter minio.ObjectLayer, writer io.Writer) error {
+		h := newGetHandler(prime, alter, false).withHedging()
+
+		return h.process(context.Background(), "bucket", "object", 0, int64(len(obj)), writer, "", minio.ObjectOptions{})
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(*testing.T)
+	}{
+		{
+			"Faster backend serves, slower is canceled",
+			func(t *testing.T) {
+				prime, alter := tutils.NewProxyObjectLayer(), tutils.NewProxyObjectLayer()
+
+				canceled := make(chan struct{})
+
+				prime.GetObjectFunc = serveOnCancel(canceled)
+				alter.GetObjectFunc = getObjectFuncFact(serve)
+
+				var buf bytes.Buffer
+				err := process(prime, alter, &buf)
+
+				assert.NoError(t, err)
+				assert.Equal(t, obj, buf.Bytes())
+
+				select {
+				case <-canceled:
+				case <-time.After(time.Second):
+					t.Fatal("slower read was not canceled")
+				}
+			},
+		},
+		{
+			"Failed backend is ignored",
+			func(t *testing.T) {
+				prime, alter := tutils.NewProxyObjectLayer(), tutils.NewProxyObjectLayer()
+
+				prime.GetObjectFunc = getObjectFuncFact(serve)
+				alter.GetObjectFunc = getObjectFuncFact(func(writer io.Writer, offset, length int64) error {
+					return errors.New("alter is down")
+				})
+
+				var buf bytes.Buffer
+				err := process(prime, alter, &buf)
+
+				assert.NoError(t, err)
+				assert.Equal(t, obj, buf.Bytes())
+			},
+		},
+		{
+			"Read is resumed from the other backend if winner fails",
+			func(t *testing.T) {
+				prime, alter := tutils.NewProxyObjectLayer(), tutils.NewProxyObjectLayer()
+
+				canceled := make(chan struct{})
+				hedged := serveOnCancel(canceled)
+
+				// resumed read starts where alter failed
+				prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					if startOffset == 0 {
+						return hedged(ctx, bucket, object, startOffset, length, writer, etag, opts)
+					}
+
+					return serve(writer, startOffset, length)
+				}
+				alter.GetObjectFunc = getObjectFuncFact(func(writer io.Writer, offset, length int64) error {
+					writer.Write(obj[:5])
+					return errors.New("connection reset")
+				})
+
+				var buf bytes.Buffer
+				err := process(prime, alter, &buf)
+
+				assert.NoError(t, err)
+				assert.Equal(t, obj, buf.Bytes())
+			},
+		},
+		{
+			"Failed precondition of prime is returned",
+			func(t *testing.T) {
+				prime, alter := tutils.NewProxyObjectLayer(), tutils.NewProxyObjectLayer()
+
+				prime.GetObjectFunc = getObjectFuncFact(func(writer io.Writer, offset, length int64) error {
+					return minio.PreConditionFailed{}
+				})
+				alter.GetObjectFunc = serveOnCancel(make(chan struct{}))
+
+				err := process(prime, alter, &bytes.Buffer{})
+
+				assert.Equal(t, minio.PreConditionFailed{}, err)
+			},
+		},
+		{
+			"Error in both",
+			func(t *testing.T) {
+				prime, alter := tutils.NewProxyObjectLayer(), tutils.NewProxyObjectLayer()
+
+				prime.GetObjectFunc = getObjectFuncFact(func(writer io.Writer, offset, length int64) error {
+					return errors.New("prime is down")
+				})
+				alter.GetObjectFunc = getObjectFuncFact(func(writer io.Writer, offset, length int64) error {
+					return errors.New("alter is down")
+				})
+
+				err := process(prime, alter, &bytes.Buffer{})
+
+				assert.Error(t, err)
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -54,13 +54,13 @@ func (h *getObjectInfoHandler) Process () (objInfo minio.ObjectInfo, err error)
 		return h.primeInfo, nil
 	}
 
-	//h.m.Logger.LogE(h.primeErr)
+	//h.m.logger(h.ctx).LogE(h.primeErr)
 
 	h.execAlter()
 
 	if h.alterErr != nil {
 
-		//h.m.Logger.LogE(h.alterErr)
+		//h.m.logger(h.ctx).LogE(h.alterErr)
 
 		return h.alterInfo, h.primeErr
 	}
@@ -5,6 +5,7 @@ package mirroring
 
 import (
 	"context"
+	"time"
 	"storj.io/ditto/pkg/utils"
 
 	minio "github.com/minio/minio/cmd"
@@ -42,7 +43,9 @@ type listObjectsHandler struct {
 
 func (h *listObjectsHandler) execPrime() *listObjectsHandler {
 
+	start := time.Now()
 	primeInfo, primeErr := h.m.Prime.ListObjects(h.ctx, h.bucket, h.prefix, h.marker, h.delimiter, h.maxKeys)
+	h.primeLatency = time.Since(start)
 
 	h.primeInfo, h.primeErr = &primeInfo, primeErr
 
@@ -50,7 +53,9 @@ func (h *listObjectsHandler) execPrime() *listObjectsHandler {
 }
 
 func (h *listObjectsHandler) execAlter() *listObjectsHandler {
+	start := time.Now()
 	alterInfo, alterErr := h.m.Alter.ListObjects(h.ctx, h.bucket, h.prefix, h.marker, h.delimiter, h.maxKeys)
+	h.alterLatency = time.Since(start)
 
 	h.alterInfo, h.alterErr = &alterInfo, alterErr
 
@@ -59,6 +64,10 @@ func (h *listObjectsHandler) execAlter() *listObjectsHandler {
 
 func (h *listObjectsHandler) Process () (minio.ListObjectsInfo, error) {
 
+	if !h.m.Config.ListOptions.Merge && h.m.isAlterPreferred() {
+		return h.alterFirst()
+	}
+
 	h.execPrime()
 
 	switch {
@@ -72,10 +81,29 @@ func (h *listObjectsHandler) Process () (minio.ListObjectsInfo, error) {
 	return *h.primeInfo, h.primeErr
 }
 
+// alterFirst lists alter, which has been faster recently, and falls back to prime.
+func (h *listObjectsHandler) alterFirst() (minio.ListObjectsInfo, error) {
+	h.execAlter()
+
+	if h.alterErr == nil || h.m.Config.ListOptions.DefaultOptions.ThrowImmediately {
+		return *h.alterInfo, h.alterErr
+	}
+
+	h.logAlterErr("ListObjects", h.bucket, h.prefix)
+
+	h.execPrime()
+
+	if h.primeErr != nil {
+		return minio.ListObjectsInfo{}, utils.CombineErrors([]error{ h.primeErr, h.alterErr })
+	}
+
+	return *h.primeInfo, nil
+}
+
 func (h *listObjectsHandler) retry() (minio.ListObjectsInfo, error) {
 	if h.primeErr != nil {
 
-		h.m.Logger.LogE(h.primeErr)
+		h.logPrimeErr("ListObjects", h.bucket, h.prefix)
 
 		h.execAlter()
 
@@ -95,14 +123,14 @@ func (h *listObjectsHandler) merge() (minio.ListObjectsInfo, error) {
 
 	if h.primeErr != nil && h.alterErr == nil {
 
-		h.m.Logger.LogE(h.primeErr)
+		h.logPrimeErr("ListObjects", h.bucket, h.prefix)
 
 		return *h.alterInfo, nil
 	}
 
 	if h.alterErr != nil && h.primeErr == nil {
 
-		h.m.Logger.LogE(h.alterErr)
+		h.logAlterErr("ListObjects", h.bucket, h.prefix)
 
 		return *h.primeInfo, nil
 	}
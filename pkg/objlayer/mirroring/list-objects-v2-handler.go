@@ -5,6 +5,7 @@ package mirroring
 
 import (
 	"context"
+	"time"
 	"storj.io/ditto/pkg/utils"
 
 	minio "github.com/minio/minio/cmd"
@@ -47,7 +48,9 @@ type listObjectsV2Handler struct {
 
 func (h *listObjectsV2Handler) execPrime() *listObjectsV2Handler {
 
+	start := time.Now()
 	primeInfo, primeErr := h.m.Prime.ListObjectsV2(h.ctx, h.bucket, h.prefix, h.cntnToken, h.delimiter, h.maxKeys, h.fetchOwner, h.startAfter)
+	h.primeLatency = time.Since(start)
 
 	h.primeInfo, h.primeErr = &primeInfo, primeErr
 
@@ -55,7 +58,9 @@ func (h *listObjectsV2Handler) execPrime() *listObjectsV2Handler {
 }
 
 func (h *listObjectsV2Handler) execAlter() *listObjectsV2Handler {
+	start := time.Now()
 	alterInfo, alterErr := h.m.Alter.ListObjectsV2(h.ctx, h.bucket, h.prefix, h.cntnToken, h.delimiter, h.maxKeys, h.fetchOwner, h.startAfter)
+	h.alterLatency = time.Since(start)
 
 	h.alterInfo, h.alterErr = &alterInfo, alterErr
 
@@ -64,6 +69,10 @@ func (h *listObjectsV2Handler) execAlter() *listObjectsV2Handler {
 
 func (h *listObjectsV2Handler) Process () (minio.ListObjectsV2Info, error) {
 
+	if !h.m.Config.ListOptions.Merge && h.m.isAlterPreferred() {
+		return h.alterFirst()
+	}
+
 	h.execPrime()
 
 	switch {
@@ -77,10 +86,29 @@ func (h *listObjectsV2Handler) Process () (minio.ListObjectsV2Info, error) {
 	return *h.primeInfo, h.primeErr
 }
 
+// alterFirst lists alter, which has been faster recently, and falls back to prime.
+func (h *listObjectsV2Handler) alterFirst() (minio.ListObjectsV2Info, error) {
+	h.execAlter()
+
+	if h.alterErr == nil || h.m.Config.ListOptions.DefaultOptions.ThrowImmediately {
+		return *h.alterInfo, h.alterErr
+	}
+
+	h.logAlterErr("ListObjectsV2", h.bucket, h.prefix)
+
+	h.execPrime()
+
+	if h.primeErr != nil {
+		return minio.ListObjectsV2Info{}, utils.CombineErrors([]error{ h.primeErr, h.alterErr })
+	}
+
+	return *h.primeInfo, nil
+}
+
 func (h *listObjectsV2Handler) retry() (minio.ListObjectsV2Info, error) {
 	if h.primeErr != nil {
 
-		h.m.Logger.LogE(h.primeErr)
+		h.logPrimeErr("ListObjectsV2", h.bucket, h.prefix)
 
 		h.execAlter()
 
@@ -100,14 +128,14 @@ func (h *listObjectsV2Handler) merge() (minio.ListObjectsV2Info, error) {
 
 	if h.primeErr != nil && h.alterErr == nil {
 
-		h.m.Logger.LogE(h.primeErr)
+		h.logPrimeErr("ListObjectsV2", h.bucket, h.prefix)
 
 		return *h.alterInfo, nil
 	}
 
 	if h.alterErr != nil && h.primeErr == nil {
 
-		h.m.Logger.LogE(h.alterErr)
+		h.logAlterErr("ListObjectsV2", h.bucket, h.prefix)
 
 		return *h.primeInfo, nil
 	}
@@ -5,6 +5,7 @@ package mirroring
 
 import (
 	"context"
+	"time"
 	"storj.io/ditto/pkg/utils"
 
 	l "storj.io/ditto/pkg/logger"
@@ -30,13 +31,17 @@ type listBucketsHandler struct {
 
 func (h *listBucketsHandler) execPrime() *listBucketsHandler {
 
+	start := time.Now()
 	h.primeBuckets, h.primeErr = h.m.Prime.ListBuckets(h.ctx)
+	h.primeLatency = time.Since(start)
 
 	return h
 }
 
 func (h *listBucketsHandler) execAlter() *listBucketsHandler {
+	start := time.Now()
 	h.alterBuckets, h.alterErr = h.m.Alter.ListBuckets(h.ctx)
+	h.alterLatency = time.Since(start)
 
 	return h
 }
@@ -59,7 +64,7 @@ func (h *listBucketsHandler) Process () ([]minio.BucketInfo, error) {
 func (h *listBucketsHandler) retry() ([]minio.BucketInfo, error) {
 	if h.primeErr != nil {
 
-		h.m.Logger.LogE(h.primeErr)
+		h.logPrimeErr("ListBuckets", "", "")
 
 		h.execAlter()
 
@@ -79,14 +84,14 @@ func (h *listBucketsHandler) merge() ([]minio.BucketInfo, error) {
 
 	if h.primeErr != nil && h.alterErr == nil {
 
-		h.m.Logger.LogE(h.primeErr)
+		h.logPrimeErr("ListBuckets", "", "")
 
 		return h.alterBuckets, nil
 	}
 
 	if h.alterErr != nil && h.primeErr == nil {
 
-		h.m.Logger.LogE(h.alterErr)
+		h.logAlterErr("ListBuckets", "", "")
 
 		return h.primeBuckets, nil
 	}
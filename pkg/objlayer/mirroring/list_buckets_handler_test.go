@@ -7,9 +7,11 @@ import (
 	"context"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/cache"
 	"storj.io/ditto/pkg/config"
 	"storj.io/ditto/pkg/utils"
 	"testing"
+	"time"
 
 	minio "github.com/minio/minio/cmd"
 	test "storj.io/ditto/pkg/utils/testing_utils"
@@ -358,4 +360,38 @@ func TestListBucketsHandler(t *testing.T) {
 			logger.Clear()
 		})
 	}
-}
\ No newline at end of file
+}
+func TestListBucketsCached(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	calls := 0
+	prime.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		calls++
+		return []minio.BucketInfo{{Name: "bucket"}}, nil
+	}
+
+	m := MirroringObjectLayer{
+		Prime:   prime,
+		Alter:   alter,
+		Logger:  &test.MockLogger{},
+		Config:  config.NewConfig().WithListOptions(&config.DefaultOptions{}, false),
+		Buckets: cache.NewBuckets(time.Minute),
+	}
+
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		buckets, err := m.ListBuckets(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []minio.BucketInfo{{Name: "bucket"}}, buckets)
+	}
+
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, m.MakeBucketWithLocation(ctx, "other", ""))
+
+	_, err := m.ListBuckets(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,29 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+
+	"storj.io/ditto/pkg/replication"
+)
+
+// LockedHandler wraps handler so every task holds lock of its object while it's handled.
+// Queued tasks and tasks replicating outside of client requests, e.g. seeding, use it
+// so a concurrent client write can't be overwritten with stale data read before it.
+func (m *MirroringObjectLayer) LockedHandler(h replication.Handler) replication.Handler {
+	return &lockedHandler{h, m}
+}
+
+type lockedHandler struct {
+	handler replication.Handler
+	m       *MirroringObjectLayer
+}
+
+func (h *lockedHandler) Handle(ctx context.Context, task replication.Task) error {
+	unlock := h.m.locks.lock(task.Bucket, task.Object)
+	defer unlock()
+
+	return h.handler.Handle(ctx, task)
+}
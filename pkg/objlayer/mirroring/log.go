@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"time"
+
+	"storj.io/ditto/pkg/errclass"
+	l "storj.io/ditto/pkg/logger"
+)
+
+// Names of backends in log entries.
+const (
+	primeBackend = "prime"
+	alterBackend = "alter"
+)
+
+// logBackendErr logs failure of operation on object (or bucket if object is empty) executed by backend.
+// Structured loggers get operation, bucket, key, backend, duration, error and its class fields,
+// plain loggers just err.
+func logBackendErr(lg l.Logger, operation, backend, bucket, object string, d time.Duration, err error) {
+	if lg == nil || err == nil {
+		return
+	}
+
+	s, ok := lg.(l.Structured)
+	if !ok {
+		lg.LogE(err)
+		return
+	}
+
+	s.Error(operation+" failed",
+		l.F("operation", operation),
+		l.F("bucket", bucket),
+		l.F("key", object),
+		l.F("backend", backend),
+		l.F("duration", d),
+		l.F("error", err),
+		l.F("class", errclass.Classify(err).String()))
+}
+
+// logPrimeErr logs failure of operation executed by prime of handler.
+func (h *baseHandler) logPrimeErr(operation, bucket, object string) {
+	logBackendErr(h.m.logger(h.ctx), operation, primeBackend, bucket, object, h.primeLatency, h.primeErr)
+}
+
+// logAlterErr logs failure of operation executed by alter of handler.
+func (h *baseHandler) logAlterErr(operation, bucket, object string) {
+	logBackendErr(h.m.logger(h.ctx), operation, alterBackend, bucket, object, h.alterLatency, h.alterErr)
+}
@@ -2,11 +2,26 @@ package mirroring
 
 import (
 	"context"
+	"fmt"
 	minio "github.com/minio/minio/cmd"
 	"github.com/minio/minio/pkg/hash"
 	"io"
+	"storj.io/ditto/pkg/alert"
+	"storj.io/ditto/pkg/objlayer/accounting"
+	"storj.io/ditto/pkg/breaker"
+	"storj.io/ditto/pkg/cache"
 	"storj.io/ditto/pkg/config"
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/failover"
+	"storj.io/ditto/pkg/journal"
+	"storj.io/ditto/pkg/notify"
+	"sync"
 	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/replication"
+	"storj.io/ditto/pkg/routing"
+	"storj.io/ditto/pkg/shadow"
+	"storj.io/ditto/pkg/state"
 )
 
 //MirroringObjectLayer is
@@ -16,11 +31,324 @@ type MirroringObjectLayer struct {
 	Alter  minio.ObjectLayer
 	Logger l.Logger
 	Config *config.Config
+	// Replication is a background queue used to mirror big objects asynchronously.
+	// If not set, all objects are mirrored inline.
+	Replication *replication.Queue
+	// Failover promotes alter when prime is unreachable, nil disables failover.
+	Failover *failover.Controller
+	// Backfill replays writes accepted by alter during failover back to prime.
+	Backfill *replication.Queue
+	// BackfillJournal persists writes which couldn't be scheduled for backfill or were left in it on shutdown.
+	// Nil makes scheduling wait for room in Backfill instead.
+	BackfillJournal *journal.Journal
+	// AlterBreaker short-circuits mirroring into Replication queue while alter is failing.
+	AlterBreaker *breaker.Breaker
+	// Journal persists alter operations which failed or couldn't be scheduled, nil disables journaling.
+	Journal *journal.Journal
+	// Shadow runs alter in shadow mode recording comparison of sampled writes, nil disables shadow mode.
+	Shadow *shadow.Recorder
+	// State records replication status of objects, nil disables tracking.
+	State *state.DB
+	// Notifier is notified about alter writes which failed, nil disables notifications.
+	Notifier *notify.Webhook
+	// Events exports every mirrored put, copy and delete to message broker, nil disables export.
+	Events *events.Bus
+	// Alerts watches replication queues and fires alerts about failing replication, nil disables alerting.
+	Alerts *alert.Monitor
+	// Router routes GetObject and ListObjects to backend which has been faster recently, nil reads prime first.
+	Router *routing.Router
+	// Missing remembers objects found missing on both backends, nil disables negative caching.
+	Missing *cache.Negative
+	// Buckets caches list of buckets, nil disables caching.
+	Buckets *cache.Buckets
+	// Memory caches content of small objects read recently, nil disables caching.
+	Memory *cache.Memory
+	// Disk caches content of objects read recently on local disk, it is consulted after Memory.
+	// Nil disables caching.
+	Disk *cache.Disk
+	// Ledger records traffic and storage of buckets per backend, it's persisted on shutdown.
+	// Nil disables accounting.
+	Ledger *accounting.Ledger
+
+	filterOnce sync.Once
+	filter     *objectFilter
+	filterErr  error
+
+	// runtime holds options applied by Reconfigure, nil until the layer is reconfigured
+	runtimeMu sync.RWMutex
+	runtime   *runtimeOptions
+
+	// locks serializes writes of the same object
+	locks nsLock
+
+	// uploads pins multipart uploads to backend which created them
+	uploads uploadPins
+	// partHashes computes content hashes of multipart uploads from their parts
+	partHashes partHashes
+}
+
+// IsMirrored returns true if object is mirrored to alter, see config.FilterOptions.
+func (m *MirroringObjectLayer) IsMirrored(object string) bool {
+	return m.isMirrored(object)
+}
+
+// isMirrored checks object key against configured filters.
+// Filter is built once from Config, invalid filter configuration is logged and mirrors nothing,
+// so objects meant to stay on prime never leak to alter. Config is validated on load, see config.ReadConfig.
+func (m *MirroringObjectLayer) isMirrored(object string) bool {
+	if r := m.reconfigured(); r != nil {
+		return r.filter.isMirrored(object)
+	}
+
+	m.filterOnce.Do(func() {
+		if m.Config == nil {
+			return
+		}
+
+		m.filter, m.filterErr = newObjectFilter(m.Config.FilterOptions)
+		if m.filterErr != nil && m.Logger != nil {
+			m.Logger.LogE(fmt.Errorf("invalid mirroring filter, objects are written only to prime: %s", m.filterErr))
+		}
+	})
+
+	if m.filterErr != nil {
+		return false
+	}
+
+	return m.filter.isMirrored(object)
+}
+
+// isAsyncPut returns true if object of given size should be mirrored in background.
+// Objects of unknown size are always mirrored in background.
+func (m *MirroringObjectLayer) isAsyncPut(size int64) bool {
+	if m.Replication == nil || m.Config == nil || m.Config.PutOptions == nil {
+		return false
+	}
+
+	threshold := m.Config.PutOptions.AsyncSizeThreshold
+
+	return threshold > 0 && (size < 0 || size > threshold)
+}
+
+// isContentHashed returns true if content hash should be stored with written objects.
+func (m *MirroringObjectLayer) isContentHashed() bool {
+	return m.Config != nil && m.Config.Metadata != nil && m.Config.Metadata.ContentHash
+}
+
+// isDetectContentType returns true if Content-Type should be detected for objects uploaded without it.
+func (m *MirroringObjectLayer) isDetectContentType() bool {
+	return m.Config != nil && m.Config.PutOptions != nil && m.Config.PutOptions.DetectContentType
+}
+
+// isVerifyChecksum returns true if mirrored writes should be verified.
+func (m *MirroringObjectLayer) isVerifyChecksum() bool {
+	return m.Config != nil && m.Config.PutOptions != nil && m.Config.PutOptions.VerifyChecksum
+}
+
+// verify compares both copies of object, mismatch is logged, tracked and scheduled for re-replication.
+func (m *MirroringObjectLayer) verify(ctx context.Context, bucket, object string) {
+	err := verifyMirrored(ctx, m.Prime, m.Alter, bucket, object)
+	if err == nil {
+		return
+	}
+
+	if lg := m.logger(ctx); lg != nil {
+		lg.LogE(err)
+	}
+
+	// object stays diverged until re-replication succeeds
+	m.track(bucket, object, state.DIVERGED, err)
+
+	if m.Replication != nil {
+		m.schedule(m.Replication, replication.NewPutTask(bucket, object))
+	}
+}
+
+// isAlterDeferred returns true if alter writes should go to replication queue
+// because alter circuit breaker is open or replication is paused.
+func (m *MirroringObjectLayer) isAlterDeferred() bool {
+	if m.Replication == nil {
+		return false
+	}
+
+	return m.Replication.IsPaused() || m.AlterBreaker != nil && m.AlterBreaker.IsOpen()
+}
+
+// isFailedOver returns true if alter is promoted to serve requests instead of prime.
+func (m *MirroringObjectLayer) isFailedOver() bool {
+	return m.Failover != nil && m.Failover.IsFailedOver()
+}
+
+// readOverride returns backend forced for read operation with dcontext.WithBackend,
+// or alter if prime has failed over. Returns nil if no backend was forced.
+func (m *MirroringObjectLayer) readOverride(ctx context.Context) minio.ObjectLayer {
+	b, ok := dcontext.BackendFromContext(ctx)
+	if !ok {
+		if m.isFailedOver() {
+			return m.Alter
+		}
+
+		return nil
+	}
+
+	if b == dcontext.ALTER {
+		return m.Alter
+	}
+
+	return m.Prime
+}
+
+// isAlterPreferred returns true if read should go to alter first because it has been faster recently.
+func (m *MirroringObjectLayer) isAlterPreferred() bool {
+	if m.Router == nil {
+		return false
+	}
+
+	if r := m.reconfigured(); r != nil && r.readMode != routing.AutoMode {
+		return false
+	}
+
+	return m.Router.Choose() == routing.Alter
+}
+
+// isHedgedRead returns true if GetObject should race both backends.
+func (m *MirroringObjectLayer) isHedgedRead() bool {
+	if r := m.reconfigured(); r != nil {
+		return r.readMode == routing.HedgedMode
+	}
+
+	return m.Config != nil && m.Config.Read != nil && m.Config.Read.Mode == routing.HedgedMode
+}
+
+// invalidateBuckets drops cached list of buckets once bucket was created or deleted.
+func (m *MirroringObjectLayer) invalidateBuckets() {
+	if m.Buckets != nil {
+		m.Buckets.Invalidate()
+	}
+}
+
+// replicate schedules task for background execution, errors are only logged.
+func (m *MirroringObjectLayer) replicate(task replication.Task) {
+	if err := m.schedule(m.Replication, task); err != nil {
+		m.track(task.Bucket, task.Object, state.FAILED, err)
+		return
+	}
+
+	m.track(task.Bucket, task.Object, state.PENDING, nil)
+}
+
+// backfill schedules task for replay to prime once it is back, errors are only logged.
+// Task which doesn't fit in backfill is journaled, clients never wait for backfill, which is parked until prime recovers.
+func (m *MirroringObjectLayer) backfill(task replication.Task) {
+	if m.Backfill == nil {
+		if m.Logger != nil {
+			m.Logger.LogE(fmt.Errorf("unable to schedule %s: backfill is not configured", task))
+		}
+
+		return
+	}
+
+	m.schedule(m.Backfill, task)
+}
+
+// schedule enqueues task, task which can't be scheduled for replication is journaled.
+func (m *MirroringObjectLayer) schedule(q *replication.Queue, task replication.Task) error {
+	err := q.Enqueue(task)
+	if err == nil {
+		return nil
+	}
+
+	if m.Logger != nil {
+		m.Logger.LogE(fmt.Errorf("unable to schedule %s: %s", task, err))
+	}
+
+	if q == m.Replication {
+		m.journal(task)
+	}
+
+	if q == m.Backfill {
+		m.journalBackfill(task)
+	}
+
+	return err
+}
+
+// journalBackfill persists write accepted by alter for later replay to prime, errors are only logged.
+func (m *MirroringObjectLayer) journalBackfill(task replication.Task) {
+	if m.BackfillJournal == nil {
+		return
+	}
+
+	err := m.BackfillJournal.Append(task)
+	if err != nil && m.Logger != nil {
+		m.Logger.LogE(fmt.Errorf("unable to journal backfill of %s: %s", task, err))
+	}
+}
+
+// journal persists failed alter operation for later replay, errors are only logged.
+func (m *MirroringObjectLayer) journal(task replication.Task) {
+	if m.Journal == nil {
+		return
+	}
+
+	err := m.Journal.Append(task)
+	if err != nil && m.Logger != nil {
+		m.Logger.LogE(fmt.Errorf("unable to journal %s: %s", task, err))
+	}
 }
 
 //ObjectLayer interface---------------------------------------------------------------------------------------------------------------------
 
 func (m *MirroringObjectLayer) Shutdown(ctx context.Context) error {
+	if m.Replication != nil {
+		m.Replication.Close()
+	}
+
+	// backfill can't be drained while prime is down, tasks left in it are journaled by its drop handler
+	if m.Backfill != nil {
+		if m.isFailedOver() {
+			m.Backfill.Stop()
+		} else {
+			m.Backfill.Close()
+		}
+	}
+
+	if m.Notifier != nil {
+		m.Notifier.Close()
+	}
+
+	if m.Alerts != nil {
+		m.Alerts.Close()
+	}
+
+	if m.Events != nil {
+		if err := m.Events.Close(); err != nil && m.Logger != nil {
+			m.Logger.LogE(err)
+		}
+	}
+
+	if m.State != nil {
+		if err := m.State.Close(); err != nil && m.Logger != nil {
+			m.Logger.LogE(err)
+		}
+	}
+
+	if m.Ledger != nil {
+		if err := m.Ledger.Flush(); err != nil && m.Logger != nil {
+			m.Logger.LogE(err)
+		}
+	}
+
+	if m.BackfillJournal != nil {
+		if err := m.BackfillJournal.Close(); err != nil && m.Logger != nil {
+			m.Logger.LogE(err)
+		}
+	}
+
+	if m.Journal != nil {
+		return m.Journal.Close()
+	}
+
 	return nil
 }
 
@@ -28,7 +356,9 @@ func (m *MirroringObjectLayer) StorageInfo(ctx context.Context) (storageInfo min
 	return storageInfo
 }
 
-func (m *MirroringObjectLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+func (m *MirroringObjectLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) (err error) {
+	defer m.recoverPanic(ctx, "MakeBucketWithLocation", &err)
+	defer m.invalidateBuckets()
 
 	h := NewMakeBucketHandler(m, ctx, bucket, location)
 
@@ -40,6 +370,11 @@ func (m *MirroringObjectLayer) MakeBucketWithLocation(ctx context.Context, bucke
 // ctx    - current context.
 // bucket - bucket name.
 func (m *MirroringObjectLayer) GetBucketInfo(ctx context.Context, bucket string) (bucketInfo minio.BucketInfo, err error) {
+	defer m.recoverPanic(ctx, "GetBucketInfo", &err)
+
+	if ol := m.readOverride(ctx); ol != nil {
+		return ol.GetBucketInfo(ctx, bucket)
+	}
 
 	h := NewGetBucketInfoHandler(m, ctx, bucket)
 
@@ -50,6 +385,15 @@ func (m *MirroringObjectLayer) GetBucketInfo(ctx context.Context, bucket string)
 // Parameters:
 // ctx - current context.
 func (m *MirroringObjectLayer) ListBuckets(ctx context.Context) (buckets []minio.BucketInfo, err error) {
+	defer m.recoverPanic(ctx, "ListBuckets", &err)
+
+	if ol := m.readOverride(ctx); ol != nil {
+		return ol.ListBuckets(ctx)
+	}
+
+	if m.Buckets != nil {
+		return m.Buckets.Get(NewListBucketsHandler(m, ctx).Process)
+	}
 
 	h := NewListBucketsHandler(m, ctx)
 
@@ -62,7 +406,9 @@ func (m *MirroringObjectLayer) ListBuckets(ctx context.Context) (buckets []minio
 // Parameters:
 // ctx    - current context.
 // bucket - bucket name.
-func (m *MirroringObjectLayer) DeleteBucket(ctx context.Context, bucket string) error {
+func (m *MirroringObjectLayer) DeleteBucket(ctx context.Context, bucket string) (err error) {
+	defer m.recoverPanic(ctx, "DeleteBucket", &err)
+	defer m.invalidateBuckets()
 
 	h := NewDeleteBucketHandler(m, ctx, bucket)
 
@@ -89,7 +435,12 @@ func (m *MirroringObjectLayer) ListObjects(ctx context.Context,
 										   prefix string,
 										   marker string,
 										   delimiter string,
-										   maxKeys int) (minio.ListObjectsInfo, error) {
+										   maxKeys int) (loi minio.ListObjectsInfo, err error) {
+	defer m.recoverPanic(ctx, "ListObjects", &err)
+
+	if ol := m.readOverride(ctx); ol != nil {
+		return ol.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	}
 
 	h := NewListObjectsHandler(m, ctx,bucket, prefix, marker, delimiter, maxKeys)
 
@@ -119,7 +470,12 @@ func (m *MirroringObjectLayer) ListObjectsV2(ctx        context.Context,
 											 delim      string,
 											 maxKeys    int,
 											 fetchOwner bool,
-											 startAfter string) (minio.ListObjectsV2Info, error) {
+											 startAfter string) (loi minio.ListObjectsV2Info, err error) {
+	defer m.recoverPanic(ctx, "ListObjectsV2", &err)
+
+	if ol := m.readOverride(ctx); ol != nil {
+		return ol.ListObjectsV2(ctx, bucket, prefix, cntnTkn, delim, maxKeys, fetchOwner, startAfter)
+	}
 
 	h := NewListObjectsV2Handler(m, ctx, bucket, prefix, cntnTkn, delim, startAfter, maxKeys, fetchOwner)
 
@@ -144,9 +500,41 @@ func (m *MirroringObjectLayer) GetObject(ctx 		 context.Context,
 										 writer 	 io.Writer,
 									     etag 	     string,
 										 opts 		 minio.ObjectOptions) (err error) {
+	defer m.recoverPanic(ctx, "GetObject", &err)
+
+	if ol := m.readOverride(ctx); ol != nil {
+		return ol.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+	}
+
+	if m.isKnownMissing(bucket, object) {
+		return minio.ObjectNotFound{Bucket: bucket, Object: object}
+	}
 
 	h := newGetHandler(m.Prime, m.Alter, false)
-	return h.process(ctx, bucket, object, startOffset, length, writer, etag, opts)
+	if m.Config != nil && m.Config.GetObjectOptions != nil && m.Config.GetObjectOptions.VerifyReads {
+		h.withReadVerification(m.Logger)
+	} else if m.isHedgedRead() {
+		h.withHedging()
+	} else if m.isAlterPreferred() {
+		h.withAlterFirst()
+	}
+
+	// verified reads always reach both backends
+	if h.verifyLogger == nil {
+		if ok, err := m.readCached(bucket, object, etag, startOffset, length, writer); ok {
+			return err
+		}
+	}
+
+	writer, cacheRead := m.cachingWriter(bucket, object, etag, startOffset, length, writer)
+
+	err = h.process(ctx, bucket, object, startOffset, length, writer, etag, opts)
+
+	// error of the backend read last, it is reported only if the other one failed as well
+	m.rememberMissing(bucket, object, err)
+	cacheRead(err)
+
+	return err
 }
 
 // Returns information about object.
@@ -158,10 +546,24 @@ func (m *MirroringObjectLayer) GetObjectInfo(ctx    context.Context,
 											 bucket string,
 											 object string,
 											 opts   minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	defer m.recoverPanic(ctx, "GetObjectInfo", &err)
+
+	if ol := m.readOverride(ctx); ol != nil {
+		return ol.GetObjectInfo(ctx, bucket, object, opts)
+	}
+
+	if m.isKnownMissing(bucket, object) {
+		return objInfo, minio.ObjectNotFound{Bucket: bucket, Object: object}
+	}
 
 	h := NewGetObjectInfoHandler(m, ctx, bucket, object, opts)
 
-	return h.Process()
+	objInfo, err = h.Process()
+	if err != nil {
+		m.rememberMissing(bucket, object, h.primeErr, h.alterErr)
+	}
+
+	return objInfo, err
 }
 
 // PutObject adds an object to a bucket.
@@ -171,9 +573,116 @@ func (m *MirroringObjectLayer) GetObjectInfo(ctx    context.Context,
 // object      - object name.
 // metadata    - A map of metadata to store with the object.
 func (m *MirroringObjectLayer) PutObject(ctx context.Context, bucket string, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	defer m.recoverPanic(ctx, "PutObject", &err)
+
+	unlock := m.locks.lock(bucket, object)
+	defer unlock()
+
+	// reads started before write finished may have remembered object as missing
+	defer m.invalidateObject(bucket, object)
+
+	if err = m.checkWritePreconditions(ctx, bucket, object); err != nil {
+		return objInfo, err
+	}
+
+	if err = m.checkQuota(bucket, object, data.Size()); err != nil {
+		return objInfo, err
+	}
+
+	defer func() {
+		if err == nil {
+			m.recordSize(bucket, object, writtenSize(data.Size(), objInfo))
+		}
+	}()
+
+	if m.isContentHashed() {
+		var buffered io.Closer
+		if data, buffered, metadata, err = m.withContentHash(data, metadata); err != nil {
+			return objInfo, err
+		}
+		defer buffered.Close()
+	}
+
+	if m.isDetectContentType() {
+		data, metadata, err = withContentType(object, data, metadata)
+		if err != nil {
+			return objInfo, err
+		}
+	}
+
+	if m.isFailedOver() {
+		objInfo, err = m.Alter.PutObject(ctx, bucket, object, data, metadata, opts)
+		if err == nil {
+			m.backfill(replication.NewPutTask(bucket, object))
+		}
+
+		m.emit(ctx, replication.NewPutTask(bucket, object), deferredOutcome(err), events.Result(err))
+
+		return objInfo, err
+	}
+
 	//TODO: decide prime and alter based on config
-	h := newPutHandler(m.Prime, m.Alter, m.Logger)
-	return h.process(ctx, bucket, object, data, metadata, opts)
+	h := newPutHandler(m.Prime, m.Alter, m.logger(ctx))
+	if m.Config != nil && m.Config.PutOptions != nil {
+		h.withSpillBuffer(m.Config.PutOptions.BufferSize, m.Config.PutOptions.SpillDir)
+	}
+
+	if !m.isMirrored(object) {
+		objInfo, err = h.processMain(ctx, bucket, object, data, metadata, opts)
+		m.emit(ctx, replication.NewPutTask(bucket, object), events.Result(err), events.Skipped)
+
+		return objInfo, err
+	}
+
+	if m.isShadowed() {
+		objInfo, err = m.shadowPut(ctx, h, bucket, object, data, metadata, opts)
+		m.emit(ctx, replication.NewPutTask(bucket, object), events.Result(err), events.Skipped)
+
+		return objInfo, err
+	}
+
+	if m.isAsyncPut(data.Size()) || m.isAlterDeferred() {
+		objInfo, err = h.processMain(ctx, bucket, object, data, metadata, opts)
+		if err == nil {
+			m.replicate(replication.NewPutTask(bucket, object))
+		}
+
+		m.emit(ctx, replication.NewPutTask(bucket, object), events.Result(err), deferredOutcome(err))
+
+		return objInfo, err
+	}
+
+	// alter already holding the same content is left untouched, only prime is written
+	if IsDeduplicated(m.Config) && isDuplicate(ctx, m.Alter, bucket, object, data.Size(), metadata) {
+		objInfo, err = h.processMain(ctx, bucket, object, data, metadata, opts)
+		if err == nil {
+			m.track(bucket, object, state.IN_SYNC, nil)
+		}
+
+		m.emit(ctx, replication.NewPutTask(bucket, object), events.Result(err), events.Skipped)
+
+		return objInfo, err
+	}
+
+	objInfo, err = h.process(ctx, bucket, object, data, metadata, opts)
+	if err == nil {
+		if h.mirrErr != nil {
+			m.fail(replication.NewPutTask(bucket, object), h.mirrErr)
+		} else {
+			m.track(bucket, object, state.IN_SYNC, nil)
+		}
+
+		m.emit(ctx, replication.NewPutTask(bucket, object), events.Result(nil), events.Result(h.mirrErr))
+	} else {
+		// alter upload is aborted when prime fails
+		m.emit(ctx, replication.NewPutTask(bucket, object), events.Result(err), events.Skipped)
+	}
+
+	if err == nil && m.isVerifyChecksum() {
+		m.verify(ctx, bucket, object)
+	}
+
+	return objInfo, err
 }
 
 // Creates a cp of an object that is already stored in a bucket.
@@ -190,7 +699,38 @@ func (m *MirroringObjectLayer) CopyObject(ctx 		 context.Context,
 										  destObject string,
 										  srcInfo 	 minio.ObjectInfo,
 										  srcOpts 	 minio.ObjectOptions,
-										  destOpts 	 minio.ObjectOptions) (minio.ObjectInfo, error) {
+										  destOpts 	 minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	defer m.recoverPanic(ctx, "CopyObject", &err)
+
+	unlock := m.locks.lock(destBucket, destObject)
+	defer unlock()
+
+	defer m.invalidateObject(destBucket, destObject)
+
+	if err := m.checkWritePreconditions(ctx, destBucket, destObject); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	if err := m.checkQuota(destBucket, destObject, srcInfo.Size); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	defer func() {
+		if err == nil {
+			m.recordSize(destBucket, destObject, writtenSize(srcInfo.Size, objInfo))
+		}
+	}()
+
+	if m.isFailedOver() {
+		objInfo, err := m.Alter.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, destOpts)
+		if err == nil {
+			m.backfill(replication.NewCopyTask(srcBucket, srcObject, destBucket, destObject))
+		}
+
+		m.emit(ctx, replication.NewCopyTask(srcBucket, srcObject, destBucket, destObject), deferredOutcome(err), events.Result(err))
+
+		return objInfo, err
+	}
 
 	h := NewCopyObjectHandler(m, ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, destOpts)
 
@@ -202,7 +742,30 @@ func (m *MirroringObjectLayer) CopyObject(ctx 		 context.Context,
 // ctx    - current context.
 // bucket - bucket name.
 // object - object name
-func (m *MirroringObjectLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+func (m *MirroringObjectLayer) DeleteObject(ctx context.Context, bucket, object string) (err error) {
+	defer m.recoverPanic(ctx, "DeleteObject", &err)
+
+	unlock := m.locks.lock(bucket, object)
+	defer unlock()
+
+	defer m.invalidateObject(bucket, object)
+
+	defer func() {
+		if err == nil {
+			m.removeSize(bucket, object)
+		}
+	}()
+
+	if m.isFailedOver() {
+		err := m.Alter.DeleteObject(ctx, bucket, object)
+		if err == nil {
+			m.backfill(replication.NewDeleteTask(bucket, object))
+		}
+
+		m.emit(ctx, replication.NewDeleteTask(bucket, object), deferredOutcome(err), events.Result(err))
+
+		return err
+	}
 
 	h := NewDeleteObjectHandler(m, ctx, bucket, object)
 
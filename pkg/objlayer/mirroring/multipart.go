@@ -0,0 +1,224 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/events"
+	"storj.io/ditto/pkg/replication"
+	"storj.io/ditto/pkg/state"
+)
+
+// Multipart uploads are written to prime only, or to alter while failed over. Parts can't be mirrored
+// one by one, because alter may transform content of objects, e.g. encrypt it, so completed object
+// is mirrored as a whole like an object above async size threshold: by Replication queue if configured,
+// otherwise inline before CompleteMultipartUpload returns. Upload IDs are known only to backend which created
+// the upload, so every upload is pinned to it until it's completed or aborted, even if failover switches backends.
+
+// multipartLayer returns backend multipart uploads are written to.
+func (m *MirroringObjectLayer) multipartLayer() minio.ObjectLayer {
+	if m.isFailedOver() {
+		return m.Alter
+	}
+
+	return m.Prime
+}
+
+// uploadLayer returns backend upload was created on. Uploads created before gateway started
+// aren't pinned, they are served by current multipart backend.
+func (m *MirroringObjectLayer) uploadLayer(uploadID string) minio.ObjectLayer {
+	if ol, ok := m.uploads.get(uploadID); ok {
+		return ol
+	}
+
+	return m.multipartLayer()
+}
+
+// NewMultipartUpload initiates multipart upload of object, object limit of bucket quota is checked,
+// size of object isn't known until it's completed. While failed over to alter, which doesn't support
+// multipart uploads, upload is refused with minio.BackendDown (503), so client retries it later.
+func (m *MirroringObjectLayer) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (uploadID string, err error) {
+	defer m.recoverPanic(ctx, "NewMultipartUpload", &err)
+
+	if err = m.checkWritePreconditions(ctx, bucket, object); err != nil {
+		return "", err
+	}
+
+	if err = m.checkQuota(bucket, object, -1); err != nil {
+		return "", err
+	}
+
+	ol := m.multipartLayer()
+
+	uploadID, err = ol.NewMultipartUpload(ctx, bucket, object, metadata, opts)
+	if _, ok := err.(minio.NotImplemented); ok && ol == m.Alter {
+		// alter transforming content, e.g. encrypting it, can't take parts, so upload waits for prime
+		return "", minio.BackendDown{}
+	}
+
+	if err == nil {
+		m.uploads.pin(uploadID, ol)
+
+		if m.isContentHashed() {
+			m.partHashes.start(uploadID)
+		}
+	}
+
+	return uploadID, err
+}
+
+// PutObjectPart uploads part to backend of upload, content of part is hashed while it's uploaded if configured.
+func (m *MirroringObjectLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (info minio.PartInfo, err error) {
+	defer m.recoverPanic(ctx, "PutObjectPart", &err)
+
+	ol := m.uploadLayer(uploadID)
+
+	sum, ok := m.partHashes.part(uploadID, partID)
+	if !ok {
+		return ol.PutObjectPart(ctx, bucket, object, uploadID, partID, data, opts)
+	}
+
+	hashed, err := hash.NewReader(io.TeeReader(data, sum), data.Size(), data.MD5HexString(), data.SHA256HexString())
+	if err == nil {
+		info, err = ol.PutObjectPart(ctx, bucket, object, uploadID, partID, hashed, opts)
+	}
+
+	m.partHashes.done(uploadID, sum, info.ETag, err)
+
+	return info, err
+}
+
+func (m *MirroringObjectLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (result minio.ListPartsInfo, err error) {
+	defer m.recoverPanic(ctx, "ListObjectParts", &err)
+
+	return m.uploadLayer(uploadID).ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+func (m *MirroringObjectLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) (err error) {
+	defer m.recoverPanic(ctx, "AbortMultipartUpload", &err)
+
+	err = m.uploadLayer(uploadID).AbortMultipartUpload(ctx, bucket, object, uploadID)
+	if err == nil {
+		m.uploads.unpin(uploadID)
+		m.partHashes.abort(uploadID)
+	}
+
+	return err
+}
+
+// CompleteMultipartUpload completes multipart upload and mirrors completed object to alter.
+// Object which failed to be mirrored inline is tracked, journaled and notified about like failed PutObject.
+func (m *MirroringObjectLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	defer m.recoverPanic(ctx, "CompleteMultipartUpload", &err)
+
+	unlock := m.locks.lock(bucket, object)
+	defer unlock()
+
+	defer m.invalidateObject(bucket, object)
+
+	task := replication.NewPutTask(bucket, object)
+
+	// upload created while failed over is completed on alter and backfilled, even if prime recovered since
+	if m.uploadLayer(uploadID) == m.Alter {
+		objInfo, err = m.Alter.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
+		if err == nil {
+			m.uploads.unpin(uploadID)
+			objInfo = m.hashCompleted(ctx, m.Alter, bucket, object, uploadID, uploadedParts, objInfo)
+			m.recordSize(bucket, object, objInfo.Size)
+			m.backfill(task)
+		}
+
+		m.emit(ctx, task, deferredOutcome(err), events.Result(err))
+
+		return objInfo, err
+	}
+
+	objInfo, err = m.Prime.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
+	if err != nil {
+		m.emit(ctx, task, events.Result(err), events.Skipped)
+		return objInfo, err
+	}
+
+	objInfo = m.hashCompleted(ctx, m.Prime, bucket, object, uploadID, uploadedParts, objInfo)
+	m.uploads.unpin(uploadID)
+	m.recordSize(bucket, object, objInfo.Size)
+
+	switch {
+	case !m.isMirrored(object):
+		m.emit(ctx, task, events.Result(nil), events.Skipped)
+	case m.Replication != nil:
+		m.replicate(task)
+		m.emit(ctx, task, events.Result(nil), events.Deferred)
+	default:
+		mirrErr := m.newReplicationHandler().Handle(ctx, task)
+		if mirrErr != nil {
+			m.fail(task, mirrErr)
+		} else {
+			m.track(bucket, object, state.IN_SYNC, nil)
+		}
+
+		m.emit(ctx, task, events.Result(nil), events.Result(mirrErr))
+	}
+
+	return objInfo, nil
+}
+
+// hashCompleted stores content hash of parts of upload with object completed on ol, if configured, so it's
+// mirrored with the object. Hash isn't stored if parts weren't hashed in order or object is too big to be copied,
+// object is left without content hash then and its copies are compared by ETags.
+func (m *MirroringObjectLayer) hashCompleted(ctx context.Context, ol minio.ObjectLayer, bucket, object, uploadID string, parts []minio.CompletePart, objInfo minio.ObjectInfo) minio.ObjectInfo {
+	contentHash, ok := m.partHashes.complete(uploadID, parts)
+	if !ok || objInfo.Size > maxCopySize {
+		return objInfo
+	}
+
+	hashed, err := storeContentHash(ctx, ol, bucket, object, contentHash)
+	if err != nil {
+		if lg := m.logger(ctx); lg != nil {
+			lg.LogE(err)
+		}
+
+		return objInfo
+	}
+
+	return hashed
+}
+
+// uploadPins remembers backend every multipart upload in progress was created on. Zero value is ready to use.
+type uploadPins struct {
+	mu   sync.Mutex
+	pins map[string]minio.ObjectLayer
+}
+
+func (u *uploadPins) pin(uploadID string, ol minio.ObjectLayer) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.pins == nil {
+		u.pins = map[string]minio.ObjectLayer{}
+	}
+
+	u.pins[uploadID] = ol
+}
+
+func (u *uploadPins) get(uploadID string) (minio.ObjectLayer, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	ol, ok := u.pins[uploadID]
+
+	return ol, ok
+}
+
+func (u *uploadPins) unpin(uploadID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.pins, uploadID)
+}
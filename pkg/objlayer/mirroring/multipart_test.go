@@ -0,0 +1,275 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/failover"
+	dmetadata "storj.io/ditto/pkg/metadata"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestCompleteMultipartUpload(t *testing.T) {
+	content := []byte("multipart")
+
+	// layers returns prime holding completed object and alter storing what it receives into alterData
+	// and alterMetadata, metadata copied by prime onto completed object is returned with its info
+	layers := func(alterErr error) (prime, alter minio.ObjectLayer, alterData *[]byte, alterMetadata *map[string]string) {
+		p := test.NewProxyObjectLayer()
+		a := test.NewProxyObjectLayer()
+
+		data := []byte(nil)
+		primeMd, md := map[string]string(nil), map[string]string(nil)
+
+		p.CompleteMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			if uploadID != "upload" || len(uploadedParts) != 2 {
+				return minio.ObjectInfo{}, minio.InvalidUploadID{Bucket: bucket, Object: object, UploadID: uploadID}
+			}
+
+			return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content))}, nil
+		}
+		p.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content)), UserDefined: primeMd}, nil
+		}
+		p.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			primeMd = srcInfo.UserDefined
+			return srcInfo, nil
+		}
+		p.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+			_, err := writer.Write(content)
+			return err
+		}
+		p.NewMultipartUploadFunc = func(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+			return "upload", nil
+		}
+		p.PutObjectPartFunc = func(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+			if _, err := ioutil.ReadAll(data); err != nil {
+				return minio.PartInfo{}, err
+			}
+
+			return minio.PartInfo{PartNumber: partID, ETag: string(rune('a' + partID - 1))}, nil
+		}
+
+		a.PutObjectFunc = func(ctx context.Context, bucket, object string, r *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			var err error
+			if data, err = ioutil.ReadAll(r); err != nil {
+				return minio.ObjectInfo{}, err
+			}
+
+			md = metadata
+
+			return minio.ObjectInfo{}, alterErr
+		}
+
+		return p, a, &data, &md
+	}
+
+	parts := []minio.CompletePart{{PartNumber: 1, ETag: "a"}, {PartNumber: 2, ETag: "b"}}
+
+	// upload uploads content in parts numbered by order
+	upload := func(t *testing.T, m *MirroringObjectLayer, order ...int) {
+		ctx := context.Background()
+
+		_, err := m.NewMultipartUpload(ctx, "bucket", "object", nil, minio.ObjectOptions{})
+		assert.NoError(t, err)
+
+		chunks := [][]byte{content[:5], content[5:]}
+
+		for _, partID := range order {
+			data, err := hash.NewReader(bytes.NewReader(chunks[partID-1]), int64(len(chunks[partID-1])), "", "")
+			assert.NoError(t, err)
+
+			_, err = m.PutObjectPart(ctx, "bucket", "object", "upload", partID, data, minio.ObjectOptions{})
+			assert.NoError(t, err)
+		}
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Completed object is mirrored to alter",
+			func(t *testing.T) {
+				prime, alter, alterData, _ := layers(nil)
+				m := &MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}}
+
+				oi, err := m.CompleteMultipartUpload(context.Background(), "bucket", "object", "upload", parts, minio.ObjectOptions{})
+
+				assert.NoError(t, err)
+				assert.Equal(t, int64(len(content)), oi.Size)
+				assert.Equal(t, content, *alterData)
+			},
+		},
+		{
+			"Failed mirroring doesn't fail upload",
+			func(t *testing.T) {
+				prime, alter, _, _ := layers(errors.New("alter is down"))
+				m := &MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}}
+
+				_, err := m.CompleteMultipartUpload(context.Background(), "bucket", "object", "upload", parts, minio.ObjectOptions{})
+
+				assert.NoError(t, err)
+			},
+		},
+		{
+			"Failed completion isn't mirrored",
+			func(t *testing.T) {
+				prime, alter, alterData, _ := layers(nil)
+				m := &MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}}
+
+				_, err := m.CompleteMultipartUpload(context.Background(), "bucket", "object", "other", parts, minio.ObjectOptions{})
+
+				assert.Equal(t, minio.InvalidUploadID{Bucket: "bucket", Object: "object", UploadID: "other"}, err)
+				assert.Nil(t, *alterData)
+			},
+		},
+		{
+			"Content hash of completed object is stored and mirrored",
+			func(t *testing.T) {
+				prime, alter, _, alterMetadata := layers(nil)
+				cfg := &config.Config{Metadata: &config.MetadataOptions{ContentHash: true}}
+				m := &MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}, Config: cfg}
+
+				upload(t, m, 1, 2)
+
+				oi, err := m.CompleteMultipartUpload(context.Background(), "bucket", "object", "upload", parts, minio.ObjectOptions{})
+
+				sum := sha256.Sum256(content)
+				assert.NoError(t, err)
+				assert.Equal(t, dmetadata.ContentHash(sum[:]), dmetadata.GetContentHash(oi.UserDefined))
+				assert.Equal(t, dmetadata.ContentHash(sum[:]), dmetadata.GetContentHash(*alterMetadata))
+			},
+		},
+		{
+			"Content hash of parts uploaded out of order isn't stored",
+			func(t *testing.T) {
+				prime, alter, alterData, alterMetadata := layers(nil)
+				cfg := &config.Config{Metadata: &config.MetadataOptions{ContentHash: true}}
+				m := &MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}, Config: cfg}
+
+				upload(t, m, 2, 1)
+
+				oi, err := m.CompleteMultipartUpload(context.Background(), "bucket", "object", "upload", parts, minio.ObjectOptions{})
+
+				assert.NoError(t, err)
+				assert.Equal(t, "", dmetadata.GetContentHash(oi.UserDefined))
+				assert.Equal(t, "", dmetadata.GetContentHash(*alterMetadata))
+				assert.Equal(t, content, *alterData)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestMultipartUploadPinned(t *testing.T) {
+	var calls []string
+
+	// layer creates backend recording its calls, which knows only uploads it created
+	layer := func(name string) minio.ObjectLayer {
+		p := test.NewProxyObjectLayer()
+
+		p.NewMultipartUploadFunc = func(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+			calls = append(calls, name+" new")
+			return name + "-upload", nil
+		}
+		p.PutObjectPartFunc = func(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+			calls = append(calls, name+" part")
+			if uploadID != name+"-upload" {
+				return minio.PartInfo{}, minio.InvalidUploadID{Bucket: bucket, Object: object, UploadID: uploadID}
+			}
+
+			return minio.PartInfo{PartNumber: partID}, nil
+		}
+		p.CompleteMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+			calls = append(calls, name+" complete")
+			if uploadID != name+"-upload" {
+				return minio.ObjectInfo{}, minio.InvalidUploadID{Bucket: bucket, Object: object, UploadID: uploadID}
+			}
+
+			return minio.ObjectInfo{Bucket: bucket, Name: object}, nil
+		}
+
+		return p
+	}
+
+	prime, alter := layer("prime"), layer("alter")
+
+	ctrl := failover.NewController(&config.FailoverOptions{Enabled: true}, nil)
+	ctrl.Override(true)
+
+	queued := make(chan replication.Task, 1)
+	m := &MirroringObjectLayer{
+		Prime:    prime,
+		Alter:    alter,
+		Logger:   &test.MockLogger{},
+		Failover: ctrl,
+		Backfill: replication.NewQueue(replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+			queued <- task
+			return nil
+		}), nil, 1, 1),
+	}
+
+	ctx := context.Background()
+
+	uploadID, err := m.NewMultipartUpload(ctx, "bucket", "object", nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	// prime recovers while upload created on alter is in progress
+	ctrl.Override(false)
+
+	data, err := hash.NewReader(bytes.NewReader([]byte("part")), 4, "", "")
+	assert.NoError(t, err)
+
+	_, err = m.PutObjectPart(ctx, "bucket", "object", uploadID, 1, data, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	_, err = m.CompleteMultipartUpload(ctx, "bucket", "object", uploadID, []minio.CompletePart{{PartNumber: 1}}, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"alter new", "alter part", "alter complete"}, calls)
+
+	task := <-queued
+	assert.Equal(t, replication.PUT, task.Operation)
+	assert.Equal(t, "object", task.Object)
+
+	// completed upload is no longer pinned
+	_, ok := m.uploads.get(uploadID)
+	assert.False(t, ok)
+}
+
+func TestMultipartUploadFailedOverUnsupported(t *testing.T) {
+	ctrl := failover.NewController(&config.FailoverOptions{Enabled: true}, nil)
+	ctrl.Override(true)
+
+	alter := test.NewProxyObjectLayer()
+	alter.NewMultipartUploadFunc = func(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+		return "", minio.NotImplemented{}
+	}
+
+	m := &MirroringObjectLayer{
+		Prime:    test.NewProxyObjectLayer(),
+		Alter:    alter,
+		Logger:   &test.MockLogger{},
+		Failover: ctrl,
+	}
+
+	_, err := m.NewMultipartUpload(context.Background(), "bucket", "object", nil, minio.ObjectOptions{})
+	assert.Equal(t, minio.BackendDown{}, err)
+}
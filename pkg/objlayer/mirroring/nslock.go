@@ -0,0 +1,54 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"path"
+	"sync"
+)
+
+// nsLock serializes mirrored writes per bucket/object, so concurrent writes
+// of the same object are applied in the same order on prime and alter.
+// Zero value is ready to use.
+type nsLock struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock acquires lock of bucket/object and returns function releasing it.
+func (n *nsLock) lock(bucket, object string) (unlock func()) {
+	key := path.Join(bucket, object)
+
+	n.mu.Lock()
+	if n.locks == nil {
+		n.locks = map[string]*keyLock{}
+	}
+
+	kl, ok := n.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		n.locks[key] = kl
+	}
+
+	kl.refs++
+	n.mu.Unlock()
+
+	kl.Lock()
+
+	return func() {
+		kl.Unlock()
+
+		n.mu.Lock()
+		kl.refs--
+		if kl.refs == 0 {
+			delete(n.locks, key)
+		}
+		n.mu.Unlock()
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNsLock(t *testing.T) {
+	var n nsLock
+
+	unlock := n.lock("bucket", "object")
+
+	locked := make(chan struct{})
+	go func() {
+		unlock := n.lock("bucket", "object")
+		close(locked)
+		unlock()
+	}()
+
+	// other keys are not blocked
+	n.lock("bucket", "other")()
+
+	select {
+	case <-locked:
+		t.Fatal("same key locked twice")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	unlock()
+	<-locked
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.lock("bucket", "object")()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 0, len(n.locks))
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+
+	minio "github.com/minio/minio/cmd"
+	dcontext "storj.io/ditto/pkg/context"
+)
+
+// checkWritePreconditions evaluates conditional headers of write request against prime copy of the object,
+// or alter copy while failed over. Backends are written only if preconditions hold for the source of truth,
+// so clients get the same answer regardless of state of the other copy.
+func (m *MirroringObjectLayer) checkWritePreconditions(ctx context.Context, bucket, object string) error {
+	p, ok := dcontext.PreconditionsFromContext(ctx)
+	if !ok || p.IsEmpty() {
+		return nil
+	}
+
+	ol := m.Prime
+	if m.isFailedOver() {
+		ol = m.Alter
+	}
+
+	oi, err := ol.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+	if err != nil {
+		if _, ok := err.(minio.ObjectNotFound); !ok {
+			return err
+		}
+	}
+
+	if !p.CheckWrite(err == nil, oi.ETag, oi.ModTime) {
+		return minio.PreConditionFailed{}
+	}
+
+	return nil
+}
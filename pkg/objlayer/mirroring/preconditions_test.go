@@ -0,0 +1,76 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestPutObjectPreconditions(t *testing.T) {
+	cases := []struct {
+		testName      string
+		preconditions dcontext.Preconditions
+		exists        bool
+		expectedErr   error
+	}{
+		{"If-None-Match * on existing object fails", dcontext.Preconditions{IfNoneMatch: "*"}, true, minio.PreConditionFailed{}},
+		{"If-None-Match * on missing object passes", dcontext.Preconditions{IfNoneMatch: "*"}, false, nil},
+		{"If-Match of current ETag passes", dcontext.Preconditions{IfMatch: "\"etag\""}, true, nil},
+		{"If-Match of other ETag fails", dcontext.Preconditions{IfMatch: "other"}, true, minio.PreConditionFailed{}},
+		{"If-Match on missing object fails", dcontext.Preconditions{IfMatch: "*"}, false, minio.PreConditionFailed{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			prime := test.NewProxyObjectLayer()
+			alter := test.NewProxyObjectLayer()
+
+			isPrimeCalled, isAlterCalled := false, false
+
+			prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+				if !c.exists {
+					return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+				}
+
+				return minio.ObjectInfo{ETag: "etag"}, nil
+			}
+
+			prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+				isPrimeCalled = true
+				return minio.ObjectInfo{}, nil
+			}
+
+			alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+				isAlterCalled = true
+				return minio.ObjectInfo{}, nil
+			}
+
+			m := MirroringObjectLayer{
+				Prime:  prime,
+				Alter:  alter,
+				Logger: &test.MockLogger{},
+			}
+
+			buff := []byte("test")
+			data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+			assert.NoError(t, err)
+
+			ctx := dcontext.WithPreconditions(context.Background(), c.preconditions)
+
+			_, err = m.PutObject(ctx, "bucket", "object", data, nil, minio.ObjectOptions{})
+
+			assert.Equal(t, c.expectedErr, err)
+			assert.Equal(t, c.expectedErr == nil, isPrimeCalled)
+			assert.Equal(t, c.expectedErr == nil, isAlterCalled)
+		})
+	}
+}
@@ -5,6 +5,9 @@ import (
 	minio "github.com/minio/minio/cmd"
 	"github.com/minio/minio/pkg/hash"
 	"io"
+	"runtime/debug"
+	"time"
+	"storj.io/ditto/pkg/buffer"
 	l "storj.io/ditto/pkg/logger"
 )
 
@@ -15,6 +18,13 @@ type asyncHandler struct {
 func (h *asyncHandler) putAsync(ctx context.Context, oi *minio.ObjectInfo, bucket, object string, metadata map[string]string, data *hash.Reader, opts minio.ObjectOptions) (<-chan error) {
 	errc := make(chan error)
 	putTask := func(errc chan<- error) {
+		// panic of backend would crash gateway, since it can't be recovered by caller in another goroutine
+		defer func() {
+			if r := recover(); r != nil {
+				errc <- PanicError{"PutObject", r, debug.Stack()}
+			}
+		}()
+
 		_oi, err := h.ol.PutObject(ctx, bucket, object, data, metadata, opts)
 		_oi.Name = object
 		*oi = _oi
@@ -30,15 +40,64 @@ func (h *asyncHandler) putAsync(ctx context.Context, oi *minio.ObjectInfo, bucke
 type putHandler struct {
 	main, mirr asyncHandler
 	logger l.Logger
+
+	// bufferSize enables spill buffer between main and mirror, see config.PutOptions.
+	bufferSize int
+	spillDir   string
+
+	// mirrErr is set by process if mirror failed while main succeeded.
+	mirrErr error
+	// mirrInfo and latencies of both writes are set by process.
+	mirrInfo                 minio.ObjectInfo
+	mainLatency, mirrLatency time.Duration
+}
+
+type pipeReader interface {
+	io.Reader
+	CloseWithError(error) error
+}
+
+type pipeWriter interface {
+	io.Writer
+	CloseWithError(error) error
 }
 
 func newPutHandler(main, mirr minio.ObjectLayer, lg l.Logger) *putHandler {
-	return &putHandler{asyncHandler{main}, asyncHandler{mirr}, lg}
+	return &putHandler{main: asyncHandler{main}, mirr: asyncHandler{mirr}, logger: lg}
 }
 
+// withSpillBuffer decouples mirror from main with buffer of bufferSize bytes spilled to spillDir.
+// Without buffer main is streamed at the pace of the slower backend.
+func (h *putHandler) withSpillBuffer(bufferSize int, spillDir string) *putHandler {
+	h.bufferSize = bufferSize
+	h.spillDir = spillDir
+
+	return h
+}
+
+// newPipe creates pipe between main and mirror.
+func (h *putHandler) newPipe() (pipeReader, pipeWriter) {
+	if h.bufferSize > 0 {
+		return buffer.NewSpillPipe(h.bufferSize, h.spillDir)
+	}
+
+	return io.Pipe()
+}
+
+// processMain puts object only to main object layer, used for objects excluded from mirroring.
+func (h *putHandler) processMain(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	err = <-h.main.putAsync(ctx, &objInfo, bucket, object, metadata, data, opts)
+	h.logPanic(err)
+
+	return
+}
+
+// process streams data to main and mirror object layers concurrently with a single pass over the data.
+// Data read by main is teed into the pipe consumed by mirror, so no additional buffering is required.
+// Mirror failure is logged and detaches mirror from the stream, main upload continues unaffected.
 func (h *putHandler) process(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
-	pr, pw := io.Pipe()
-	teer := io.TeeReader(data, pw)
+	pr, pw := h.newPipe()
+	teer := io.TeeReader(data, &mirrorWriter{w: pw})
 
 	rmain, err := hash.NewReader(teer, data.Size(), data.MD5HexString(), data.SHA256HexString())
 	if err != nil {
@@ -58,29 +117,68 @@ func (h *putHandler) process(ctx context.Context, bucket, object string, data *h
 	}()
 
 	var moi, mroi minio.ObjectInfo
+	start := time.Now()
 	errMain := h.main.putAsync(ctxm, &moi, bucket, object, metadata, rmain, opts)
 	errMirr := h.mirr.putAsync(ctxmr, &mroi, bucket, object, metadata, rmirr, opts)
 
-	tnum := 2 //if both put operations done we won't handle cancelation
 	done := ctx.Done()
-	for i := 0; i < tnum; i++ {
+	for errMain != nil || errMirr != nil {
 		select {
 		case err = <-errMain:
-			//h.logger.LogE(err)
+			h.mainLatency = time.Since(start)
+			h.logPanic(err)
+			logBackendErr(h.logger, "PutObject", primeBackend, bucket, object, h.mainLatency, err)
 			objInfo = moi
+			errMain = nil
+
+			// signal EOF to mirror, or propagate main error so mirror won't store partial object
+			pw.CloseWithError(err)
 			if err != nil {
-				pr.Close()
-				mrcancelf() //Not sure if we need to call it cause it autocanceled once pipe writer s closed
+				mrcancelf()
 			}
-		case _ = <-errMirr:
-			//h.logger.LogE(errm) //Print error from mirror
+		case errm := <-errMirr:
+			h.mirrErr = errm
+			h.mirrLatency = time.Since(start)
+			h.logPanic(errm)
+			logBackendErr(h.logger, "PutObject", alterBackend, bucket, object, h.mirrLatency, errm)
+			h.mirrInfo = mroi
+			errMirr = nil
+
+			// unblock main if mirror stopped reading before EOF
+			pr.CloseWithError(errm)
 		case <-done:
 			mcancelf()
-			pr.Close()
-			tnum++ //Ensure that we wont try to close pipe again? Will panic? Try to catch all errors.
+			mrcancelf()
+			pw.CloseWithError(ctx.Err())
 			done = nil // dont want to track closed chanel
 		}
 	}
 
 	return
-}
\ No newline at end of file
+}
+
+// logPanic logs stack trace of panic recovered by put.
+func (h *putHandler) logPanic(err error) {
+	if perr, ok := err.(PanicError); ok {
+		logPanic(h.logger, perr)
+	}
+}
+
+// mirrorWriter feeds mirror pipe from main reader.
+// Once mirror fails all subsequent writes are discarded, so main upload is never stalled by mirror.
+type mirrorWriter struct {
+	w      io.Writer
+	failed bool
+}
+
+func (mw *mirrorWriter) Write(p []byte) (int, error) {
+	if mw.failed {
+		return len(p), nil
+	}
+
+	if _, err := mw.w.Write(p); err != nil {
+		mw.failed = true
+	}
+
+	return len(p), nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"bytes"
 	"errors"
+	"io/ioutil"
 	"time"
 )
 
@@ -63,11 +64,7 @@ func TestPutHandler(t *testing.T) {
 
 				_, err = m.PutObject(ctxb, "bucket", "object", data, nil, minio.ObjectOptions{})
 				assert.NoError(t, err)
-				assert.Equal(t, 2, lg.LogECount())
-
-				prm, err := lg.GetLastLogEParam()
-				assert.NoError(t, err)
-				assert.Equal(t, nil, prm)
+				assert.Equal(t, 0, lg.LogECount())
 			},
 
 		},
@@ -85,7 +82,11 @@ func TestPutHandler(t *testing.T) {
 
 				_, err = m.PutObject(ctxb, "bucket", "object", data, nil, minio.ObjectOptions{})
 				assert.Equal(t, testError, err)
-				assert.Equal(t, 2, lg.LogECount())
+				assert.Equal(t, 1, lg.LogECount())
+
+				prm, err := lg.GetLastLogEParam()
+				assert.NoError(t, err)
+				assert.Equal(t, testError, prm)
 			},
 		},
 		{
@@ -105,11 +106,32 @@ func TestPutHandler(t *testing.T) {
 
 				_, err = m.PutObject(ctxc, "bucket", "object", data, nil, minio.ObjectOptions{})
 				assert.NoError(t, err)
-				assert.Equal(t, 2, lg.LogECount())
+				assert.Equal(t, 0, lg.LogECount())
+			},
+		},
+		{
+			testName: "Err mirror, main streamed",
+			testFunc: func (*testing.T) {
+				lg := &tutils.MockLogger{}
+				m.Logger = lg
+
+				var received []byte
+				prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					var err error
+					received, err = ioutil.ReadAll(data)
+					return minio.ObjectInfo{}, err
+				}
+				alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{}, testError
+				}
+
+				data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+				assert.NoError(t, err)
 
-				prm, err := lg.GetLastLogEParam()
+				_, err = m.PutObject(ctxb, "bucket", "object", data, nil, minio.ObjectOptions{})
 				assert.NoError(t, err)
-				assert.Equal(t, nil, prm)
+				assert.Equal(t, buff, received)
+				assert.Equal(t, 1, lg.LogECount())
 			},
 		},
 	}
@@ -0,0 +1,101 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"fmt"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+// QuotaExceeded is returned by writes which would grow bucket beyond its configured quota.
+type QuotaExceeded struct {
+	Bucket string
+	Object string
+	Reason string
+}
+
+func (e QuotaExceeded) Error() string {
+	return fmt.Sprintf("Quota of bucket %s exceeded writing %s: %s", e.Bucket, e.Object, e.Reason)
+}
+
+// isQuotaEnforced returns true if any bucket quota is configured and usage of buckets is tracked.
+func (m *MirroringObjectLayer) isQuotaEnforced() bool {
+	return m.State != nil && m.Config != nil && m.Config.Quota != nil && m.Config.Quota.IsEnabled()
+}
+
+// checkQuota returns QuotaExceeded if writing object of size would exceed quota of bucket.
+// Object replaced by the write is accounted for, object of unknown size is checked only against object limit.
+// Writes are checked against usage of bucket in State, which is the same whichever backend is written.
+func (m *MirroringObjectLayer) checkQuota(bucket, object string, size int64) error {
+	if !m.isQuotaEnforced() {
+		return nil
+	}
+
+	quota := m.Config.Quota.For(bucket)
+	if quota.MaxSize <= 0 && quota.MaxObjects <= 0 {
+		return nil
+	}
+
+	usage, err := m.State.Usage(bucket)
+	if err != nil {
+		return err
+	}
+
+	old, exists, err := m.State.Size(bucket, object)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		usage.Bytes -= old
+	} else {
+		usage.Objects++
+	}
+
+	if size > 0 {
+		usage.Bytes += size
+	}
+
+	if quota.MaxObjects > 0 && usage.Objects > quota.MaxObjects {
+		return QuotaExceeded{Bucket: bucket, Object: object, Reason: fmt.Sprintf("limit of %d objects", quota.MaxObjects)}
+	}
+
+	if quota.MaxSize > 0 && usage.Bytes > quota.MaxSize {
+		return QuotaExceeded{Bucket: bucket, Object: object, Reason: fmt.Sprintf("limit of %d bytes", quota.MaxSize)}
+	}
+
+	return nil
+}
+
+// recordSize accounts written object in usage of its bucket if state tracking is enabled, errors are only logged.
+func (m *MirroringObjectLayer) recordSize(bucket, object string, size int64) {
+	if m.State == nil {
+		return
+	}
+
+	if err := m.State.SetSize(bucket, object, size); err != nil && m.Logger != nil {
+		m.Logger.LogE(fmt.Errorf("unable to record size of %s/%s: %s", bucket, object, err))
+	}
+}
+
+// removeSize removes deleted object from usage of its bucket.
+func (m *MirroringObjectLayer) removeSize(bucket, object string) {
+	if m.State == nil {
+		return
+	}
+
+	if err := m.State.RemoveSize(bucket, object); err != nil && m.Logger != nil {
+		m.Logger.LogE(fmt.Errorf("unable to remove size of %s/%s: %s", bucket, object, err))
+	}
+}
+
+// writtenSize returns size of written object, declared size if known, otherwise size reported by backend.
+func writtenSize(size int64, oi minio.ObjectInfo) int64 {
+	if size >= 0 {
+		return size
+	}
+
+	return oi.Size
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/state"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestPutObjectQuota(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mirroring-quota-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := state.Open(filepath.Join(dir, "state.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	cfg := &config.Config{
+		Quota: &config.QuotaOptions{
+			MaxSize:    10,
+			MaxObjects: 2,
+			Buckets:    map[string]*config.BucketQuota{"unlimited": {}},
+		},
+	}
+
+	m := &MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}, Config: cfg, State: db}
+
+	put := func(bucket, object string, size int) error {
+		data, err := hash.NewReader(bytes.NewReader(make([]byte, size)), int64(size), "", "")
+		assert.NoError(t, err)
+
+		_, err = m.PutObject(context.Background(), bucket, object, data, nil, minio.ObjectOptions{})
+
+		return err
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Writes within quota succeed",
+			func(t *testing.T) {
+				assert.NoError(t, put("bucket", "a", 4))
+				assert.NoError(t, put("bucket", "b", 4))
+
+				// replaced object doesn't count twice
+				assert.NoError(t, put("bucket", "a", 6))
+
+				u, err := db.Usage("bucket")
+				assert.NoError(t, err)
+				assert.Equal(t, state.Usage{Objects: 2, Bytes: 10}, u)
+			},
+		},
+		{
+			"Writes beyond quota are refused",
+			func(t *testing.T) {
+				err := put("bucket", "c", 0)
+				assert.IsType(t, QuotaExceeded{}, err)
+
+				err = put("bucket", "b", 5)
+				assert.IsType(t, QuotaExceeded{}, err)
+			},
+		},
+		{
+			"Deleted object frees quota",
+			func(t *testing.T) {
+				assert.NoError(t, m.DeleteObject(context.Background(), "bucket", "b"))
+				assert.NoError(t, put("bucket", "c", 4))
+			},
+		},
+		{
+			"Bucket quota overrides default quota",
+			func(t *testing.T) {
+				assert.NoError(t, put("unlimited", "a", 100))
+				assert.NoError(t, put("unlimited", "b", 100))
+				assert.NoError(t, put("unlimited", "c", 100))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
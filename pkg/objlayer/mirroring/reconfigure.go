@@ -0,0 +1,54 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"fmt"
+
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/routing"
+)
+
+// runtimeOptions are options which can be changed while the layer serves requests,
+// they take precedence over respective sections of Config.
+type runtimeOptions struct {
+	filter   *objectFilter
+	readMode string
+}
+
+// Reconfigure applies FilterOptions and Read mode of cfg to requests started afterwards,
+// requests in flight finish with options they started with. Auto read mode takes effect only if Router is set.
+// Invalid options are refused and current options are kept.
+func (m *MirroringObjectLayer) Reconfigure(cfg *config.Config) error {
+	f, err := newObjectFilter(cfg.FilterOptions)
+	if err != nil {
+		return err
+	}
+
+	mode := routing.PrimeMode
+	if cfg.Read != nil && cfg.Read.Mode != "" {
+		mode = cfg.Read.Mode
+	}
+
+	switch mode {
+	case routing.PrimeMode, routing.AutoMode, routing.HedgedMode:
+	default:
+		return fmt.Errorf("unknown read mode %q", mode)
+	}
+
+	m.runtimeMu.Lock()
+	defer m.runtimeMu.Unlock()
+
+	m.runtime = &runtimeOptions{filter: f, readMode: mode}
+
+	return nil
+}
+
+// reconfigured returns options applied by Reconfigure, nil if the layer wasn't reconfigured.
+func (m *MirroringObjectLayer) reconfigured() *runtimeOptions {
+	m.runtimeMu.RLock()
+	defer m.runtimeMu.RUnlock()
+
+	return m.runtime
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/routing"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestReconfigure(t *testing.T) {
+	newMirror := func() *MirroringObjectLayer {
+		router := routing.NewRouter(0)
+		router.Observe(routing.Prime, 100*time.Millisecond, nil)
+		router.Observe(routing.Alter, 10*time.Millisecond, nil)
+
+		cfg := config.NewConfig().WithFilterOptions(nil, []string{"tmp/"}, nil, nil)
+		cfg.Read = &config.ReadOptions{Mode: routing.AutoMode}
+
+		return &MirroringObjectLayer{Logger: &test.MockLogger{}, Config: cfg, Router: router}
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Filters are replaced",
+			testFunc: func(t *testing.T) {
+				m := newMirror()
+				assert.False(t, m.isMirrored("tmp/object"))
+
+				cfg := config.NewConfig().WithFilterOptions(nil, []string{"cache/"}, nil, nil)
+				assert.NoError(t, m.Reconfigure(cfg))
+
+				assert.True(t, m.isMirrored("tmp/object"))
+				assert.False(t, m.isMirrored("cache/object"))
+			},
+		},
+		{
+			testName: "Read mode is replaced",
+			testFunc: func(t *testing.T) {
+				m := newMirror()
+				assert.True(t, m.isAlterPreferred())
+				assert.False(t, m.isHedgedRead())
+
+				cfg := config.NewConfig()
+				cfg.Read = &config.ReadOptions{Mode: routing.HedgedMode}
+				assert.NoError(t, m.Reconfigure(cfg))
+
+				assert.False(t, m.isAlterPreferred())
+				assert.True(t, m.isHedgedRead())
+
+				cfg.Read.Mode = routing.AutoMode
+				assert.NoError(t, m.Reconfigure(cfg))
+
+				assert.True(t, m.isAlterPreferred())
+				assert.False(t, m.isHedgedRead())
+			},
+		},
+		{
+			testName: "Invalid options are refused",
+			testFunc: func(t *testing.T) {
+				m := newMirror()
+
+				cfg := config.NewConfig().WithFilterOptions(nil, nil, []string{"("}, nil)
+				assert.Error(t, m.Reconfigure(cfg))
+
+				cfg = config.NewConfig()
+				cfg.Read = &config.ReadOptions{Mode: "fastest"}
+				assert.Error(t, m.Reconfigure(cfg))
+
+				// current options are kept
+				assert.False(t, m.isMirrored("tmp/object"))
+				assert.True(t, m.isAlterPreferred())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
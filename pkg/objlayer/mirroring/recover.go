@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	l "storj.io/ditto/pkg/logger"
+)
+
+// PanicError is returned by operation which panicked instead of crashing the gateway.
+// Gateway responds to it with internal error. Stack is stack trace of the panicking goroutine.
+type PanicError struct {
+	Operation string
+	Value     interface{}
+	Stack     []byte
+}
+
+func (e PanicError) Error() string {
+	return fmt.Sprintf("%s failed with internal error: %v", e.Operation, e.Value)
+}
+
+// recoverPanic recovers from panic of operation executed with ctx, logs it with stack trace
+// and sets err to PanicError. It has to be deferred directly to recover.
+func (m *MirroringObjectLayer) recoverPanic(ctx context.Context, operation string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	perr := PanicError{operation, r, debug.Stack()}
+	*err = perr
+	logPanic(m.logger(ctx), perr)
+}
+
+// logPanic logs recovered panic together with its stack trace.
+func logPanic(lg l.Logger, err PanicError) {
+	if lg == nil {
+		return
+	}
+
+	if s, ok := lg.(l.Structured); ok {
+		s.Error("panic recovered", l.F("operation", err.Operation), l.F("panic", fmt.Sprint(err.Value)), l.F("stack", string(err.Stack)))
+		return
+	}
+
+	lg.LogE(fmt.Errorf("panic recovered in %s: %v\n%s", err.Operation, err.Value, err.Stack))
+}
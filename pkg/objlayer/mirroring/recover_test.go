@@ -0,0 +1,85 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	dcontext "storj.io/ditto/pkg/context"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestPanicRecovered(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "panic of handler",
+			testFunc: func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				prime.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+					panic("test panic")
+				}
+
+				logger := &test.MockLogger{}
+				m := MirroringObjectLayer{
+					Prime:  prime,
+					Alter:  test.NewProxyObjectLayer(),
+					Logger: logger,
+					Config: &config.Config{
+						ListOptions: &config.ListOptions{Merge: true, DefaultOptions: &config.DefaultOptions{}},
+					},
+				}
+
+				ctx := dcontext.WithRequestID(context.Background(), "0123456789abcdef")
+				_, err := m.ListBuckets(ctx)
+
+				perr, ok := err.(PanicError)
+				assert.True(t, ok)
+				assert.Equal(t, "ListBuckets", perr.Operation)
+				assert.Equal(t, "test panic", perr.Value)
+
+				logErr, _ := logger.GetLastLogEParam()
+				assert.Error(t, logErr)
+				assert.Contains(t, logErr.Error(), "[0123456789abcdef] panic recovered in ListBuckets: test panic")
+				assert.Contains(t, logErr.Error(), "goroutine")
+			},
+		},
+		{
+			testName: "panic of mirrored upload",
+			testFunc: func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				alter := test.NewProxyObjectLayer()
+
+				prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					panic("test panic")
+				}
+
+				logger := &test.MockLogger{}
+				h := newPutHandler(prime, alter, logger)
+
+				data, err := hash.NewReader(bytes.NewReader([]byte("data")), 4, "", "")
+				assert.NoError(t, err)
+
+				_, err = h.processMain(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+
+				perr, ok := err.(PanicError)
+				assert.True(t, ok)
+				assert.Equal(t, "PutObject", perr.Operation)
+				assert.Equal(t, 1, logger.LogECount())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
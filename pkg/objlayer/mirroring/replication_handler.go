@@ -0,0 +1,123 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/replication"
+)
+
+// NewReplicationHandler creates replication.Handler that replays tasks from prime to alter.
+func NewReplicationHandler(prime, alter minio.ObjectLayer) replication.Handler {
+	return &replicationHandler{prime: prime, alter: alter}
+}
+
+// NewDedupReplicationHandler creates replication.Handler like NewReplicationHandler,
+// which doesn't stream objects alter already holds with the same content hash and metadata.
+func NewDedupReplicationHandler(prime, alter minio.ObjectLayer) replication.Handler {
+	return &replicationHandler{prime: prime, alter: alter, dedup: true}
+}
+
+type replicationHandler struct {
+	prime, alter minio.ObjectLayer
+	dedup        bool
+}
+
+func (h *replicationHandler) Handle(ctx context.Context, task replication.Task) error {
+	switch task.Operation {
+	case replication.PUT:
+		return h.put(ctx, task)
+	case replication.DELETE:
+		return h.delete(ctx, task)
+	case replication.COPY:
+		return h.copy(ctx, task)
+	default:
+		return fmt.Errorf("unsupported replication operation %s", task.Operation)
+	}
+}
+
+// put streams object from prime to alter.
+// Object which was deleted from prime in the meantime is skipped,
+// as well as object alter already holds if handler deduplicates.
+func (h *replicationHandler) put(ctx context.Context, task replication.Task) error {
+	oi, err := h.prime.GetObjectInfo(ctx, task.Bucket, task.Object, minio.ObjectOptions{})
+	if err != nil {
+		if _, ok := err.(minio.ObjectNotFound); ok {
+			return nil
+		}
+
+		return err
+	}
+
+	if h.dedup && isDuplicate(ctx, h.alter, task.Bucket, task.Object, oi.Size, oi.UserDefined) {
+		return nil
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(h.prime.GetObject(ctx, task.Bucket, task.Object, 0, oi.Size, pw, oi.ETag, minio.ObjectOptions{}))
+	}()
+
+	data, err := hash.NewReader(pr, oi.Size, "", "")
+	if err != nil {
+		pr.CloseWithError(err)
+		return err
+	}
+
+	_, err = h.alter.PutObject(ctx, task.Bucket, task.Object, data, oi.UserDefined, minio.ObjectOptions{})
+	pr.CloseWithError(err)
+
+	return err
+}
+
+// delete removes object from alter unless prime holds it again, e.g. it was deleted and written back
+// before the task was handled. Object alter doesn't hold is already deleted.
+func (h *replicationHandler) delete(ctx context.Context, task replication.Task) error {
+	_, err := h.prime.GetObjectInfo(ctx, task.Bucket, task.Object, minio.ObjectOptions{})
+	if err == nil {
+		return nil
+	}
+
+	if !isMissing(err) {
+		return err
+	}
+
+	if err = h.alter.DeleteObject(ctx, task.Bucket, task.Object); isMissing(err) {
+		return nil
+	}
+
+	return err
+}
+
+// isMissing returns true if err means that object or its bucket doesn't exist.
+func isMissing(err error) bool {
+	switch err.(type) {
+	case minio.ObjectNotFound, minio.BucketNotFound:
+		return true
+	}
+
+	return false
+}
+
+func (h *replicationHandler) copy(ctx context.Context, task replication.Task) error {
+	srcInfo, err := h.alter.GetObjectInfo(ctx, task.SrcBucket, task.SrcObject, minio.ObjectOptions{})
+	if err != nil {
+		if _, ok := err.(minio.ObjectNotFound); ok {
+			// source was never mirrored, fall back to streaming destination from prime
+			return h.put(ctx, task)
+		}
+
+		return err
+	}
+
+	_, err = h.alter.CopyObject(ctx, task.SrcBucket, task.SrcObject, task.Bucket, task.Object, srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+
+	return err
+}
@@ -0,0 +1,230 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	dmetadata "storj.io/ditto/pkg/metadata"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestReplicationHandler(t *testing.T) {
+	content := []byte("replicated content")
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Put streams object from prime to alter",
+			func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				alter := test.NewProxyObjectLayer()
+
+				prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(content))}, nil
+				}
+
+				prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					_, err := writer.Write(content)
+					return err
+				}
+
+				var received []byte
+				alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					var err error
+					received, err = ioutil.ReadAll(data)
+					return minio.ObjectInfo{}, err
+				}
+
+				h := NewReplicationHandler(prime, alter)
+				err := h.Handle(context.Background(), replication.NewPutTask("bucket", "object"))
+
+				assert.NoError(t, err)
+				assert.Equal(t, content, received)
+			},
+		},
+		{
+			"Put of object missing on prime is skipped",
+			func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				alter := test.NewProxyObjectLayer()
+
+				prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+				}
+
+				isAlterCalled := false
+				alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					isAlterCalled = true
+					return minio.ObjectInfo{}, nil
+				}
+
+				h := NewReplicationHandler(prime, alter)
+				err := h.Handle(context.Background(), replication.NewPutTask("bucket", "object"))
+
+				assert.NoError(t, err)
+				assert.Equal(t, false, isAlterCalled)
+			},
+		},
+		{
+			"Delete error returned",
+			func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+				}
+
+				alter := test.NewProxyObjectLayer()
+				alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					return errors.New("alter failed")
+				}
+
+				h := NewReplicationHandler(prime, alter)
+				err := h.Handle(context.Background(), replication.NewDeleteTask("bucket", "object"))
+
+				assert.Error(t, err)
+				assert.Equal(t, "alter failed", err.Error())
+
+				// object alter doesn't hold is already deleted
+				alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					return minio.ObjectNotFound{Bucket: bucket, Object: object}
+				}
+
+				assert.NoError(t, h.Handle(context.Background(), replication.NewDeleteTask("bucket", "object")))
+			},
+		},
+		{
+			"Delete of object prime holds again is skipped",
+			func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{Bucket: bucket, Name: object}, nil
+				}
+
+				isAlterCalled := false
+				alter := test.NewProxyObjectLayer()
+				alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					isAlterCalled = true
+					return nil
+				}
+
+				h := NewReplicationHandler(prime, alter)
+				err := h.Handle(context.Background(), replication.NewDeleteTask("bucket", "object"))
+
+				assert.NoError(t, err)
+				assert.Equal(t, false, isAlterCalled)
+			},
+		},
+		{
+			"Dedup put skips object alter already holds",
+			func(t *testing.T) {
+				stored := minio.ObjectInfo{
+					Size:        int64(len(content)),
+					UserDefined: map[string]string{dmetadata.ContentHashKey: "sha256:hash"},
+				}
+
+				prime := test.NewProxyObjectLayer()
+				alter := test.NewProxyObjectLayer()
+
+				prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return stored, nil
+				}
+
+				alter.GetObjectInfoFunc = prime.GetObjectInfoFunc
+
+				isPrimeRead := false
+				prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					isPrimeRead = true
+					_, err := writer.Write(content)
+					return err
+				}
+
+				isAlterCalled := false
+				alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					isAlterCalled = true
+					_, err := ioutil.ReadAll(data)
+					return minio.ObjectInfo{}, err
+				}
+
+				err := NewDedupReplicationHandler(prime, alter).Handle(context.Background(), replication.NewPutTask("bucket", "object"))
+				assert.NoError(t, err)
+				assert.Equal(t, false, isPrimeRead)
+				assert.Equal(t, false, isAlterCalled)
+
+				// the same object is streamed once alter copy differs
+				alter.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{Size: stored.Size}, nil
+				}
+
+				err = NewDedupReplicationHandler(prime, alter).Handle(context.Background(), replication.NewPutTask("bucket", "object"))
+				assert.NoError(t, err)
+				assert.Equal(t, true, isAlterCalled)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestAsyncPut(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	cfg := config.NewConfig().WithPutOptions(nil, false)
+	cfg.PutOptions.AsyncSizeThreshold = 2
+
+	queued := make(chan replication.Task, 1)
+	handler := replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		queued <- task
+		return nil
+	})
+
+	m := MirroringObjectLayer{
+		Prime:       prime,
+		Alter:       alter,
+		Logger:      &test.MockLogger{},
+		Config:      cfg,
+		Replication: replication.NewQueue(handler, nil, 1, 1),
+	}
+
+	isAlterCalled := false
+	alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		isAlterCalled = true
+		return minio.ObjectInfo{}, nil
+	}
+
+	buff := []byte("big object")
+	data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+	assert.NoError(t, err)
+
+	_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	task := <-queued
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	assert.Equal(t, false, isAlterCalled)
+	assert.Equal(t, replication.PUT, task.Operation)
+	assert.Equal(t, "object", task.Object)
+}
+
+type replicationHandlerFunc func(ctx context.Context, task replication.Task) error
+
+func (f replicationHandlerFunc) Handle(ctx context.Context, task replication.Task) error {
+	return f(ctx, task)
+}
@@ -0,0 +1,26 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+
+	dcontext "storj.io/ditto/pkg/context"
+	l "storj.io/ditto/pkg/logger"
+)
+
+// logger returns logger of operation executed with ctx, which tags messages with ID of request, if any.
+// Structured loggers receive ID as request_id field, others as prefix of message.
+func (m *MirroringObjectLayer) logger(ctx context.Context) l.Logger {
+	id, ok := dcontext.RequestIDFromContext(ctx)
+	if !ok {
+		return m.Logger
+	}
+
+	if s, ok := m.Logger.(l.Structured); ok {
+		return s.With(l.F("request_id", id))
+	}
+
+	return l.WithPrefix(m.Logger, "["+id+"] ")
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	dcontext "storj.io/ditto/pkg/context"
+	l "storj.io/ditto/pkg/logger"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestRequestIDLogged(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	prime.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		return nil, nil
+	}
+
+	alter.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		return nil, errors.New("alter error")
+	}
+
+	logger := &test.MockLogger{}
+	m := MirroringObjectLayer{
+		Prime:  prime,
+		Alter:  alter,
+		Logger: logger,
+		Config: &config.Config{
+			ListOptions: &config.ListOptions{Merge: true, DefaultOptions: &config.DefaultOptions{}},
+		},
+	}
+
+	ctx := dcontext.WithRequestID(context.Background(), "0123456789abcdef")
+	m.ListBuckets(ctx)
+
+	err, _ := logger.GetLastLogEParam()
+	assert.Error(t, err)
+	assert.Equal(t, "[0123456789abcdef] alter error", err.Error())
+}
+
+func TestRequestIDLoggedStructured(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	prime.GetBucketInfoFunc = func(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+		return minio.BucketInfo{}, errors.New("prime error")
+	}
+
+	alter.GetBucketInfoFunc = func(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+		return minio.BucketInfo{Name: bucket}, nil
+	}
+
+	buf := &bytes.Buffer{}
+	logger, err := l.NewStructured(buf, l.InfoLevel, l.JSONFormat)
+	assert.NoError(t, err)
+
+	m := MirroringObjectLayer{Prime: prime, Alter: alter, Logger: logger}
+
+	ctx := dcontext.WithRequestID(context.Background(), "0123456789abcdef")
+	_, err = m.GetBucketInfo(ctx, "bucket")
+	assert.NoError(t, err)
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "0123456789abcdef", entry["request_id"])
+	assert.Equal(t, "GetBucketInfo", entry["operation"])
+	assert.Equal(t, "bucket", entry["bucket"])
+	assert.Equal(t, "", entry["key"])
+	assert.Equal(t, "prime", entry["backend"])
+	assert.Equal(t, "prime error", entry["error"])
+	assert.NotEmpty(t, entry["duration"])
+}
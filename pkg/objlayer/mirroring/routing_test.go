@@ -0,0 +1,140 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/routing"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestReadRouting(t *testing.T) {
+	newMirror := func(primeLatency, alterLatency time.Duration) (m *MirroringObjectLayer, prime, alter *[]string) {
+		router := routing.NewRouter(0)
+		router.Observe(routing.Prime, primeLatency, nil)
+		router.Observe(routing.Alter, alterLatency, nil)
+
+		primeCalls, alterCalls := []string{}, []string{}
+
+		p, a := test.NewProxyObjectLayer(), test.NewProxyObjectLayer()
+
+		p.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+			primeCalls = append(primeCalls, "GetObject")
+			_, err := writer.Write([]byte("prime"))
+			return err
+		}
+
+		a.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+			alterCalls = append(alterCalls, "GetObject")
+			_, err := writer.Write([]byte("alter"))
+			return err
+		}
+
+		p.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+			primeCalls = append(primeCalls, "ListObjects")
+			return minio.ListObjectsInfo{Objects: []minio.ObjectInfo{{Name: "prime"}}}, nil
+		}
+
+		a.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+			alterCalls = append(alterCalls, "ListObjects")
+			return minio.ListObjectsInfo{}, errors.New("alter is down")
+		}
+
+		m = &MirroringObjectLayer{
+			Prime:  p,
+			Alter:  a,
+			Logger: &test.MockLogger{},
+			Config: config.NewConfig().WithListOptions(&config.DefaultOptions{}, false),
+			Router: router,
+		}
+
+		return m, &primeCalls, &alterCalls
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "GetObject is read from faster alter",
+			testFunc: func(t *testing.T) {
+				m, prime, alter := newMirror(100*time.Millisecond, 10*time.Millisecond)
+
+				var buf bytes.Buffer
+				err := m.GetObject(context.Background(), "bucket", "object", 0, 5, &buf, "", minio.ObjectOptions{})
+
+				assert.NoError(t, err)
+				assert.Equal(t, "alter", buf.String())
+				assert.Empty(t, *prime)
+				assert.Equal(t, []string{"GetObject"}, *alter)
+			},
+		},
+		{
+			testName: "GetObject is read from faster prime",
+			testFunc: func(t *testing.T) {
+				m, prime, alter := newMirror(10*time.Millisecond, 100*time.Millisecond)
+
+				var buf bytes.Buffer
+				err := m.GetObject(context.Background(), "bucket", "object", 0, 5, &buf, "", minio.ObjectOptions{})
+
+				assert.NoError(t, err)
+				assert.Equal(t, "prime", buf.String())
+				assert.Equal(t, []string{"GetObject"}, *prime)
+				assert.Empty(t, *alter)
+			},
+		},
+		{
+			testName: "ETag is checked only against prime copy",
+			testFunc: func(t *testing.T) {
+				m, _, _ := newMirror(100*time.Millisecond, 10*time.Millisecond)
+
+				var primeETag, alterETag string
+
+				prime, alter := test.NewProxyObjectLayer(), test.NewProxyObjectLayer()
+				prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					primeETag = etag
+					return nil
+				}
+				alter.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					alterETag = etag
+					return errors.New("alter is down")
+				}
+
+				m.Prime, m.Alter = prime, alter
+
+				err := m.GetObject(context.Background(), "bucket", "object", 0, 5, &bytes.Buffer{}, "etag", minio.ObjectOptions{})
+
+				assert.NoError(t, err)
+				assert.Equal(t, "", alterETag)
+				assert.Equal(t, "etag", primeETag)
+			},
+		},
+		{
+			testName: "ListObjects falls back from faster alter to prime",
+			testFunc: func(t *testing.T) {
+				m, prime, alter := newMirror(100*time.Millisecond, 10*time.Millisecond)
+
+				loi, err := m.ListObjects(context.Background(), "bucket", "", "", "", 1000)
+
+				assert.NoError(t, err)
+				assert.Equal(t, "prime", loi.Objects[0].Name)
+				assert.Equal(t, []string{"ListObjects"}, *alter)
+				assert.Equal(t, []string{"ListObjects"}, *prime)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
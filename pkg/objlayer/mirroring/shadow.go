@@ -0,0 +1,64 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/shadow"
+)
+
+// isShadowed returns true if alter is run in shadow mode.
+func (m *MirroringObjectLayer) isShadowed() bool {
+	return m.Shadow != nil
+}
+
+// shadowPut writes object to prime and, for sampled writes, to alter.
+// Alter result is only recorded, it's never returned to the client nor replicated.
+func (m *MirroringObjectLayer) shadowPut(ctx context.Context, h *putHandler, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if !m.Shadow.Sample() {
+		return h.processMain(ctx, bucket, object, data, metadata, opts)
+	}
+
+	objInfo, err := h.process(ctx, bucket, object, data, metadata, opts)
+
+	m.Shadow.Record(shadow.Result{
+		Operation:    "PUT",
+		Bucket:       bucket,
+		Object:       object,
+		PrimeLatency: h.mainLatency,
+		AlterLatency: h.mirrLatency,
+		PrimeErr:     err,
+		AlterErr:     h.mirrErr,
+		PrimeETag:    objInfo.ETag,
+		AlterETag:    h.mirrInfo.ETag,
+	})
+
+	return objInfo, err
+}
+
+// shadowAlter executes alter operation of sampled writes and records its result against prime one.
+// alter returns ETag of written object, if any.
+func (m *MirroringObjectLayer) shadowAlter(operation, bucket, object string, primeLatency time.Duration, primeETag string, alter func() (string, error)) {
+	if !m.Shadow.Sample() {
+		return
+	}
+
+	start := time.Now()
+	etag, err := alter()
+
+	m.Shadow.Record(shadow.Result{
+		Operation:    operation,
+		Bucket:       bucket,
+		Object:       object,
+		PrimeLatency: primeLatency,
+		AlterLatency: time.Since(start),
+		AlterErr:     err,
+		PrimeETag:    primeETag,
+		AlterETag:    etag,
+	})
+}
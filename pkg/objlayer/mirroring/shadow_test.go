@@ -0,0 +1,128 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/shadow"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestShadow(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Sampled put, alter failure is recorded but not returned",
+			testFunc: func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				alter := test.NewProxyObjectLayer()
+				recorder := shadow.NewRecorder(100, nil)
+
+				m := MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}, Shadow: recorder}
+
+				prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					ioutil.ReadAll(data)
+					return minio.ObjectInfo{ETag: "etag"}, nil
+				}
+
+				alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					ioutil.ReadAll(data)
+					return minio.ObjectInfo{}, errors.New("alter failed")
+				}
+
+				buff := []byte("test")
+				data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+				assert.NoError(t, err)
+
+				oi, err := m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, "etag", oi.ETag)
+
+				s := recorder.Stats()
+				assert.Equal(t, int64(1), s.Total)
+				assert.Equal(t, int64(1), s.AlterErrors)
+				assert.Equal(t, int64(1), s.Mismatches)
+			},
+		},
+		{
+			testName: "Not sampled writes are not sent to alter",
+			testFunc: func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				alter := test.NewProxyObjectLayer()
+				recorder := shadow.NewRecorder(0, nil)
+
+				m := MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}, Shadow: recorder}
+
+				isAlterCalled := false
+
+				prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{}, nil
+				}
+
+				alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					isAlterCalled = true
+					return minio.ObjectInfo{}, nil
+				}
+
+				prime.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					return nil
+				}
+
+				alter.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					isAlterCalled = true
+					return nil
+				}
+
+				buff := []byte("test")
+				data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+				assert.NoError(t, err)
+
+				_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+				assert.NoError(t, err)
+				assert.NoError(t, m.DeleteObject(context.Background(), "bucket", "object"))
+
+				assert.Equal(t, false, isAlterCalled)
+				assert.Equal(t, int64(0), recorder.Stats().Total)
+			},
+		},
+		{
+			testName: "Sampled copy, matching ETags are recorded",
+			testFunc: func(t *testing.T) {
+				prime := test.NewProxyObjectLayer()
+				alter := test.NewProxyObjectLayer()
+				recorder := shadow.NewRecorder(100, nil)
+
+				m := MirroringObjectLayer{Prime: prime, Alter: alter, Logger: &test.MockLogger{}, Shadow: recorder}
+
+				copyFunc := func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{ETag: "etag"}, nil
+				}
+
+				prime.CopyObjectFunc = copyFunc
+				alter.CopyObjectFunc = copyFunc
+
+				_, err := m.CopyObject(context.Background(), "bucket", "src", "bucket", "dst", minio.ObjectInfo{}, minio.ObjectOptions{}, minio.ObjectOptions{})
+				assert.NoError(t, err)
+
+				s := recorder.Stats()
+				assert.Equal(t, int64(1), s.Total)
+				assert.Equal(t, int64(0), s.Mismatches)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"fmt"
+
+	"storj.io/ditto/pkg/replication"
+	"storj.io/ditto/pkg/state"
+)
+
+// track records replication status of object if state tracking is enabled, errors are only logged.
+func (m *MirroringObjectLayer) track(bucket, object string, status state.Status, err error) {
+	if m.State == nil {
+		return
+	}
+
+	if serr := m.State.Set(bucket, object, status, err); serr != nil && m.Logger != nil {
+		m.Logger.LogE(fmt.Errorf("unable to track %s/%s: %s", bucket, object, serr))
+	}
+}
+
+// untrack stops tracking of deleted object.
+func (m *MirroringObjectLayer) untrack(bucket, object string) {
+	if m.State == nil {
+		return
+	}
+
+	if err := m.State.Delete(bucket, object); err != nil && m.Logger != nil {
+		m.Logger.LogE(fmt.Errorf("unable to untrack %s/%s: %s", bucket, object, err))
+	}
+}
+
+// fail records failed alter operation, journals it for later replay and notifies about it.
+// Failed delete leaves tombstone, so copy left on alter can be collected as orphan.
+func (m *MirroringObjectLayer) fail(task replication.Task, err error) {
+	status := state.FAILED
+	if task.Operation == replication.DELETE {
+		status = state.DELETED
+	}
+
+	m.track(task.Bucket, task.Object, status, err)
+	m.journal(task)
+
+	if m.Notifier != nil {
+		m.Notifier.Notify(task, "alter", err, m.Journal != nil)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/state"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestPutObjectTracked(t *testing.T) {
+	cases := []struct {
+		testName       string
+		alterErr       error
+		expectedStatus state.Status
+	}{
+		{"Mirrored object is in sync", nil, state.IN_SYNC},
+		{"Failed alter write is tracked", errors.New("test error"), state.FAILED},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "mirroring-state-test")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			db, err := state.Open(filepath.Join(dir, "state.db"))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			prime := test.NewProxyObjectLayer()
+			alter := test.NewProxyObjectLayer()
+
+			prime.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+				return minio.ObjectInfo{}, nil
+			}
+
+			alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+				return minio.ObjectInfo{}, c.alterErr
+			}
+
+			m := MirroringObjectLayer{
+				Prime:  prime,
+				Alter:  alter,
+				Logger: &test.MockLogger{},
+				State:  db,
+			}
+
+			buff := []byte("test")
+			data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+			assert.NoError(t, err)
+
+			_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+			assert.NoError(t, err)
+
+			r, ok, err := db.Get("bucket", "object")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, c.expectedStatus, r.Status)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	minio "github.com/minio/minio/cmd"
+	"storj.io/ditto/pkg/metadata"
+)
+
+// ChecksumMismatch is returned when prime and alter copies of an object differ.
+// PrimeETag and AlterETag hold content hashes if both copies have one, see metadata.ContentHashKey.
+type ChecksumMismatch struct {
+	Bucket, Object       string
+	PrimeETag, AlterETag string
+	PrimeSize, AlterSize int64
+}
+
+func (e ChecksumMismatch) Error() string {
+	return fmt.Sprintf("checksum mismatch %s/%s: prime etag %q size %d, alter etag %q size %d",
+		e.Bucket, e.Object, e.PrimeETag, e.PrimeSize, e.AlterETag, e.AlterSize)
+}
+
+// ContentMismatch is returned when prime and alter serve different content of an object.
+type ContentMismatch struct {
+	Bucket, Object           string
+	PrimeSHA256, AlterSHA256 string
+}
+
+func (e ContentMismatch) Error() string {
+	return fmt.Sprintf("content mismatch %s/%s: prime sha256 %s, alter sha256 %s",
+		e.Bucket, e.Object, e.PrimeSHA256, e.AlterSHA256)
+}
+
+// MetadataMismatch is returned when prime and alter copies of an object have different metadata.
+type MetadataMismatch struct {
+	Bucket, Object               string
+	PrimeMetadata, AlterMetadata map[string]string
+}
+
+func (e MetadataMismatch) Error() string {
+	return fmt.Sprintf("metadata mismatch %s/%s: prime %v, alter %v",
+		e.Bucket, e.Object, e.PrimeMetadata, e.AlterMetadata)
+}
+
+// verifyMirrored compares checksum, size and normalized metadata of object stored in prime and alter.
+// Content hashes stored by ditto are compared if both copies have them, because ETags of multipart
+// and encrypted objects differ across backends. Otherwise ETags are compared when both backends return them.
+func verifyMirrored(ctx context.Context, prime, alter minio.ObjectLayer, bucket, object string) error {
+	poi, err := prime.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+	if err != nil {
+		return err
+	}
+
+	aoi, err := alter.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+	if err != nil {
+		return err
+	}
+
+	petag, aetag := normalizeETag(poi.ETag), normalizeETag(aoi.ETag)
+
+	phash, ahash := metadata.GetContentHash(poi.UserDefined), metadata.GetContentHash(aoi.UserDefined)
+	if phash != "" && ahash != "" {
+		petag, aetag = phash, ahash
+	}
+
+	if poi.Size != aoi.Size || (petag != "" && aetag != "" && petag != aetag) {
+		return ChecksumMismatch{
+			Bucket:    bucket,
+			Object:    object,
+			PrimeETag: petag,
+			AlterETag: aetag,
+			PrimeSize: poi.Size,
+			AlterSize: aoi.Size,
+		}
+	}
+
+	if !metadata.Equal(poi.UserDefined, aoi.UserDefined) {
+		return MetadataMismatch{
+			Bucket:        bucket,
+			Object:        object,
+			PrimeMetadata: metadata.Normalize(poi.UserDefined),
+			AlterMetadata: metadata.Normalize(aoi.UserDefined),
+		}
+	}
+
+	return nil
+}
+
+func normalizeETag(etag string) string {
+	return strings.ToLower(strings.Trim(etag, "\""))
+}
@@ -0,0 +1,189 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package mirroring
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	"storj.io/ditto/pkg/metadata"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func getObjectInfoMock(etag string, size int64) func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	return func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{Bucket: bucket, Name: object, ETag: etag, Size: size}, nil
+	}
+}
+
+func TestVerifyMirrored(t *testing.T) {
+	cases := []struct {
+		testName   string
+		primeETag  string
+		primeSize  int64
+		alterETag  string
+		alterSize  int64
+		isMismatch bool
+	}{
+		{"Same etag and size", "abc", 3, "\"ABC\"", 3, false},
+		{"Missing alter etag", "abc", 3, "", 3, false},
+		{"Different size", "abc", 3, "abc", 4, true},
+		{"Different etag", "abc", 3, "abd", 3, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			prime := test.NewProxyObjectLayer()
+			alter := test.NewProxyObjectLayer()
+
+			prime.GetObjectInfoFunc = getObjectInfoMock(c.primeETag, c.primeSize)
+			alter.GetObjectInfoFunc = getObjectInfoMock(c.alterETag, c.alterSize)
+
+			err := verifyMirrored(context.Background(), prime, alter, "bucket", "object")
+			if !c.isMismatch {
+				assert.NoError(t, err)
+				return
+			}
+
+			_, ok := err.(ChecksumMismatch)
+			assert.Equal(t, true, ok)
+		})
+	}
+}
+
+func TestVerifyMirroredMetadata(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	primeMetadata := map[string]string{"x-amz-meta-owner": "alice", "Etag": "abc"}
+	alterMetadata := map[string]string{"X-Amz-Meta-Owner": "alice"}
+
+	prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{ETag: "abc", Size: 3, UserDefined: primeMetadata}, nil
+	}
+
+	alter.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{ETag: "abc", Size: 3, UserDefined: alterMetadata}, nil
+	}
+
+	assert.NoError(t, verifyMirrored(context.Background(), prime, alter, "bucket", "object"))
+
+	alterMetadata["X-Amz-Meta-Owner"] = "bob"
+
+	_, ok := verifyMirrored(context.Background(), prime, alter, "bucket", "object").(MetadataMismatch)
+	assert.Equal(t, true, ok)
+}
+
+func TestVerifyMirroredContentHash(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	primeHash, alterHash := "sha256:abc", "sha256:abc"
+
+	// multipart etag of alter differs, but content is the same
+	prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{ETag: "etag", Size: 3, UserDefined: map[string]string{metadata.ContentHashKey: primeHash}}, nil
+	}
+
+	alter.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{ETag: "etag-2", Size: 3, UserDefined: map[string]string{metadata.ContentHashKey: alterHash}}, nil
+	}
+
+	assert.NoError(t, verifyMirrored(context.Background(), prime, alter, "bucket", "object"))
+
+	alterHash = "sha256:abd"
+
+	_, ok := verifyMirrored(context.Background(), prime, alter, "bucket", "object").(ChecksumMismatch)
+	assert.Equal(t, true, ok)
+}
+
+func TestPutObjectVerifyChecksum(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	prime.PutObjectFunc = getPutMockFunc(nil, nil)
+	alter.PutObjectFunc = getPutMockFunc(nil, nil)
+	prime.GetObjectInfoFunc = getObjectInfoMock("abc", 4)
+	alter.GetObjectInfoFunc = getObjectInfoMock("abc", 0)
+
+	cfg := config.NewConfig().WithPutOptions(nil, false)
+	cfg.PutOptions.VerifyChecksum = true
+
+	queued := make(chan replication.Task, 1)
+	handler := replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		queued <- task
+		return nil
+	})
+
+	lg := &test.MockLogger{}
+	m := MirroringObjectLayer{
+		Prime:       prime,
+		Alter:       alter,
+		Logger:      lg,
+		Config:      cfg,
+		Replication: replication.NewQueue(handler, nil, 1, 1),
+	}
+
+	buff := []byte("test")
+	data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+	assert.NoError(t, err)
+
+	_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	task := <-queued
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	assert.Equal(t, replication.PUT, task.Operation)
+
+	prm, err := lg.GetLastLogEParam()
+	assert.NoError(t, err)
+
+	_, ok := prm.(ChecksumMismatch)
+	assert.Equal(t, true, ok)
+}
+
+func TestPutObjectVerifyChecksumWithoutLogger(t *testing.T) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	prime.PutObjectFunc = getPutMockFunc(nil, nil)
+	alter.PutObjectFunc = getPutMockFunc(nil, nil)
+	prime.GetObjectInfoFunc = getObjectInfoMock("abc", 4)
+	alter.GetObjectInfoFunc = getObjectInfoMock("abc", 0)
+
+	cfg := config.NewConfig().WithPutOptions(nil, false)
+	cfg.PutOptions.VerifyChecksum = true
+
+	queued := make(chan replication.Task, 1)
+	handler := replicationHandlerFunc(func(ctx context.Context, task replication.Task) error {
+		queued <- task
+		return nil
+	})
+
+	m := MirroringObjectLayer{
+		Prime:       prime,
+		Alter:       alter,
+		Config:      cfg,
+		Replication: replication.NewQueue(handler, nil, 1, 1),
+	}
+
+	buff := []byte("test")
+	data, err := hash.NewReader(bytes.NewReader(buff), int64(len(buff)), "", "")
+	assert.NoError(t, err)
+
+	_, err = m.PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	task := <-queued
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	assert.Equal(t, replication.PUT, task.Operation)
+}
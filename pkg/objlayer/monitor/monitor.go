@@ -0,0 +1,268 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package monitor
+
+import (
+	"context"
+	"io"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Hooks are called around data operations of monitored object layer.
+type Hooks struct {
+	// Before may refuse operation by returning error, nil Before allows every operation.
+	Before func() error
+	// After receives result of every operation which was allowed by Before.
+	After func(err error)
+	// Observe receives name, duration and transferred bytes of every operation which was allowed by Before.
+	Observe func(call Call)
+}
+
+// Call describes single operation of monitored object layer.
+// Bytes are amount of object data written by PutObject or read by GetObject, zero for other operations.
+// Context is context the operation was called with.
+type Call struct {
+	Context   context.Context
+	Operation string
+	Duration  time.Duration
+	Bytes     int64
+	Err       error
+}
+
+// NewMonitoredLayer wraps object layer and calls hooks around its bucket and object operations.
+func NewMonitoredLayer(ol minio.ObjectLayer, hooks Hooks) minio.ObjectLayer {
+	return &monitoredLayer{ol, hooks}
+}
+
+type monitoredLayer struct {
+	minio.ObjectLayer
+	hooks Hooks
+}
+
+func (m *monitoredLayer) before() error {
+	if m.hooks.Before == nil {
+		return nil
+	}
+
+	return m.hooks.Before()
+}
+
+func (m *monitoredLayer) after(ctx context.Context, operation string, start time.Time, bytes int64, err error) {
+	if m.hooks.After != nil {
+		m.hooks.After(err)
+	}
+
+	if m.hooks.Observe != nil {
+		m.hooks.Observe(Call{Context: ctx, Operation: operation, Duration: time.Since(start), Bytes: bytes, Err: err})
+	}
+}
+
+// countingWriter counts bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+func (m *monitoredLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	if err := m.before(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := m.ObjectLayer.MakeBucketWithLocation(ctx, bucket, location)
+	m.after(ctx, "MakeBucketWithLocation", start, 0, err)
+
+	return err
+}
+
+func (m *monitoredLayer) GetBucketInfo(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+	if err := m.before(); err != nil {
+		return minio.BucketInfo{}, err
+	}
+
+	start := time.Now()
+	bi, err := m.ObjectLayer.GetBucketInfo(ctx, bucket)
+	m.after(ctx, "GetBucketInfo", start, 0, err)
+
+	return bi, err
+}
+
+func (m *monitoredLayer) ListBuckets(ctx context.Context) ([]minio.BucketInfo, error) {
+	if err := m.before(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	buckets, err := m.ObjectLayer.ListBuckets(ctx)
+	m.after(ctx, "ListBuckets", start, 0, err)
+
+	return buckets, err
+}
+
+func (m *monitoredLayer) DeleteBucket(ctx context.Context, bucket string) error {
+	if err := m.before(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := m.ObjectLayer.DeleteBucket(ctx, bucket)
+	m.after(ctx, "DeleteBucket", start, 0, err)
+
+	return err
+}
+
+func (m *monitoredLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	if err := m.before(); err != nil {
+		return minio.ListObjectsInfo{}, err
+	}
+
+	start := time.Now()
+	loi, err := m.ObjectLayer.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	m.after(ctx, "ListObjects", start, 0, err)
+
+	return loi, err
+}
+
+func (m *monitoredLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	if err := m.before(); err != nil {
+		return minio.ListObjectsV2Info{}, err
+	}
+
+	start := time.Now()
+	loi, err := m.ObjectLayer.ListObjectsV2(ctx, bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+	m.after(ctx, "ListObjectsV2", start, 0, err)
+
+	return loi, err
+}
+
+func (m *monitoredLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	if err := m.before(); err != nil {
+		return err
+	}
+
+	cw := &countingWriter{w: writer}
+	start := time.Now()
+	err := m.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, cw, etag, opts)
+	m.after(ctx, "GetObject", start, cw.n, err)
+
+	return err
+}
+
+func (m *monitoredLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := m.before(); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	start := time.Now()
+	oi, err := m.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+	m.after(ctx, "GetObjectInfo", start, 0, err)
+
+	return oi, err
+}
+
+func (m *monitoredLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := m.before(); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	start := time.Now()
+	oi, err := m.ObjectLayer.PutObject(ctx, bucket, object, data, metadata, opts)
+	m.after(ctx, "PutObject", start, oi.Size, err)
+
+	return oi, err
+}
+
+func (m *monitoredLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := m.before(); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	start := time.Now()
+	oi, err := m.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+	m.after(ctx, "CopyObject", start, 0, err)
+
+	return oi, err
+}
+
+func (m *monitoredLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	if err := m.before(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := m.ObjectLayer.DeleteObject(ctx, bucket, object)
+	m.after(ctx, "DeleteObject", start, 0, err)
+
+	return err
+}
+
+func (m *monitoredLayer) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+	if err := m.before(); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	uploadID, err := m.ObjectLayer.NewMultipartUpload(ctx, bucket, object, metadata, opts)
+	m.after(ctx, "NewMultipartUpload", start, 0, err)
+
+	return uploadID, err
+}
+
+func (m *monitoredLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+	if err := m.before(); err != nil {
+		return minio.PartInfo{}, err
+	}
+
+	start := time.Now()
+	info, err := m.ObjectLayer.PutObjectPart(ctx, bucket, object, uploadID, partID, data, opts)
+	m.after(ctx, "PutObjectPart", start, info.Size, err)
+
+	return info, err
+}
+
+func (m *monitoredLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListPartsInfo, error) {
+	if err := m.before(); err != nil {
+		return minio.ListPartsInfo{}, err
+	}
+
+	start := time.Now()
+	result, err := m.ObjectLayer.ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, maxParts)
+	m.after(ctx, "ListObjectParts", start, 0, err)
+
+	return result, err
+}
+
+func (m *monitoredLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error {
+	if err := m.before(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := m.ObjectLayer.AbortMultipartUpload(ctx, bucket, object, uploadID)
+	m.after(ctx, "AbortMultipartUpload", start, 0, err)
+
+	return err
+}
+
+func (m *monitoredLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := m.before(); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	start := time.Now()
+	oi, err := m.ObjectLayer.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
+	m.after(ctx, "CompleteMultipartUpload", start, 0, err)
+
+	return oi, err
+}
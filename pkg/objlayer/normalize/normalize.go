@@ -0,0 +1,43 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package normalize
+
+import (
+	"context"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/metadata"
+)
+
+// NewNormalizingLayer wraps object layer so metadata is canonicalized with metadata.Normalize
+// before it's written and after it's read, so both backends store and report the same metadata.
+func NewNormalizingLayer(ol minio.ObjectLayer) minio.ObjectLayer {
+	return &normalizingLayer{ol}
+}
+
+type normalizingLayer struct {
+	minio.ObjectLayer
+}
+
+func (n *normalizingLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	oi, err := n.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+	if err != nil {
+		return oi, err
+	}
+
+	oi.UserDefined = metadata.Normalize(oi.UserDefined)
+
+	return oi, nil
+}
+
+func (n *normalizingLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, md map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	return n.ObjectLayer.PutObject(ctx, bucket, object, data, metadata.Normalize(md), opts)
+}
+
+func (n *normalizingLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	srcInfo.UserDefined = metadata.Normalize(srcInfo.UserDefined)
+
+	return n.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+}
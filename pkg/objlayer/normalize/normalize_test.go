@@ -0,0 +1,43 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package normalize
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestNormalizingLayer(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+
+	var written map[string]string
+
+	ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		written = metadata
+		return minio.ObjectInfo{}, nil
+	}
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{UserDefined: map[string]string{"x-amz-meta-owner": "alice", "Etag": "abc"}}, nil
+	}
+
+	n := NewNormalizingLayer(ol)
+
+	data, err := hash.NewReader(bytes.NewReader(nil), 0, "", "")
+	assert.NoError(t, err)
+
+	_, err = n.PutObject(context.Background(), "bucket", "object", data, map[string]string{"x-amz-meta-owner": "alice", "X-Amz-Request-Id": "123"}, minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Amz-Meta-Owner": "alice"}, written)
+
+	oi, err := n.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Amz-Meta-Owner": "alice"}, oi.UserDefined)
+}
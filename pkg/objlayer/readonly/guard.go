@@ -0,0 +1,140 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package readonly
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/config"
+	l "storj.io/ditto/pkg/logger"
+)
+
+// Guard decides whether gateway should stop accepting writes because backends diverged too far.
+// Gateway becomes read-only when journal holds more than maxJournalSize entries
+// or alter has been failing for longer than maxAlterDowntime. Zero limits are not checked.
+type Guard struct {
+	maxJournalSize   int
+	maxAlterDowntime time.Duration
+	isFailure        func(error) bool
+	logger           l.Logger
+
+	journalLen func() int
+
+	mu        sync.Mutex
+	downSince time.Time
+	readOnly  bool
+
+	now func() time.Time
+}
+
+// Creates new Guard, isFailure decides which alter errors mean alter is down, nil means every error.
+func NewGuard(opts *config.ReadOnlyOptions, isFailure func(error) bool, logger l.Logger) *Guard {
+	if isFailure == nil {
+		isFailure = func(err error) bool { return err != nil }
+	}
+
+	return &Guard{
+		maxJournalSize:   opts.MaxJournalSize,
+		maxAlterDowntime: opts.MaxAlterDowntime,
+		isFailure:        isFailure,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// WithJournalLen sets function returning amount of operations not yet applied to alter.
+func (g *Guard) WithJournalLen(journalLen func() int) *Guard {
+	g.journalLen = journalLen
+
+	return g
+}
+
+// ReportAlter receives result of alter operation.
+func (g *Guard) ReportAlter(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.isFailure(err) {
+		g.downSince = time.Time{}
+		return
+	}
+
+	if g.downSince.IsZero() {
+		g.downSince = g.now()
+	}
+}
+
+// Run probes alter every interval while it's down until ctx is done, so guard notices alter is back
+// even if no operation reaches it, e.g. while writes are rejected or breaker of alter is open.
+// Probe should call alter directly, its result is reported to guard.
+func (g *Guard) Run(ctx context.Context, probe func(ctx context.Context) error, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if g.isDown() {
+				g.ReportAlter(probe(ctx))
+			}
+		}
+	}
+}
+
+// isDown returns true if the last operation of alter failed.
+func (g *Guard) isDown() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return !g.downSince.IsZero()
+}
+
+// IsReadOnly returns true if writes should be rejected.
+func (g *Guard) IsReadOnly() bool {
+	reason := g.reason()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	readOnly := reason != ""
+	if readOnly != g.readOnly && g.logger != nil {
+		if readOnly {
+			g.logger.LogE(fmt.Errorf("switching to read-only mode: %s", reason))
+		} else {
+			g.logger.Log("leaving read-only mode")
+		}
+	}
+
+	g.readOnly = readOnly
+
+	return readOnly
+}
+
+// reason returns why gateway should be read-only, empty string means it shouldn't.
+func (g *Guard) reason() string {
+	if g.maxJournalSize > 0 && g.journalLen != nil {
+		if n := g.journalLen(); n > g.maxJournalSize {
+			return fmt.Sprintf("journal holds %d operations, limit is %d", n, g.maxJournalSize)
+		}
+	}
+
+	if g.maxAlterDowntime > 0 {
+		g.mu.Lock()
+		downSince := g.downSince
+		g.mu.Unlock()
+
+		if !downSince.IsZero() {
+			if d := g.now().Sub(downSince); d > g.maxAlterDowntime {
+				return fmt.Sprintf("alter is down for %s, limit is %s", d, g.maxAlterDowntime)
+			}
+		}
+	}
+
+	return ""
+}
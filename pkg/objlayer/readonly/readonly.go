@@ -0,0 +1,86 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package readonly
+
+import (
+	"context"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// NewReadOnlyLayer wraps object layer so writes are rejected with minio.BackendDown (503)
+// while guard reports read-only mode. Reads are always passed through.
+func NewReadOnlyLayer(ol minio.ObjectLayer, guard *Guard) minio.ObjectLayer {
+	return &readOnlyLayer{ObjectLayer: ol, guard: guard}
+}
+
+type readOnlyLayer struct {
+	minio.ObjectLayer
+	guard *Guard
+}
+
+func (r *readOnlyLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	if r.guard.IsReadOnly() {
+		return minio.BackendDown{}
+	}
+
+	return r.ObjectLayer.MakeBucketWithLocation(ctx, bucket, location)
+}
+
+func (r *readOnlyLayer) DeleteBucket(ctx context.Context, bucket string) error {
+	if r.guard.IsReadOnly() {
+		return minio.BackendDown{}
+	}
+
+	return r.ObjectLayer.DeleteBucket(ctx, bucket)
+}
+
+func (r *readOnlyLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if r.guard.IsReadOnly() {
+		return minio.ObjectInfo{}, minio.BackendDown{}
+	}
+
+	return r.ObjectLayer.PutObject(ctx, bucket, object, data, metadata, opts)
+}
+
+func (r *readOnlyLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if r.guard.IsReadOnly() {
+		return minio.ObjectInfo{}, minio.BackendDown{}
+	}
+
+	return r.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+}
+
+func (r *readOnlyLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	if r.guard.IsReadOnly() {
+		return minio.BackendDown{}
+	}
+
+	return r.ObjectLayer.DeleteObject(ctx, bucket, object)
+}
+
+func (r *readOnlyLayer) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+	if r.guard.IsReadOnly() {
+		return "", minio.BackendDown{}
+	}
+
+	return r.ObjectLayer.NewMultipartUpload(ctx, bucket, object, metadata, opts)
+}
+
+func (r *readOnlyLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+	if r.guard.IsReadOnly() {
+		return minio.PartInfo{}, minio.BackendDown{}
+	}
+
+	return r.ObjectLayer.PutObjectPart(ctx, bucket, object, uploadID, partID, data, opts)
+}
+
+func (r *readOnlyLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if r.guard.IsReadOnly() {
+		return minio.ObjectInfo{}, minio.BackendDown{}
+	}
+
+	return r.ObjectLayer.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package readonly
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestGuard(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Read-only after alter is down for too long",
+			func(t *testing.T) {
+				now := time.Now()
+
+				g := NewGuard(&config.ReadOnlyOptions{MaxAlterDowntime: time.Minute}, nil, &test.MockLogger{})
+				g.now = func() time.Time { return now }
+
+				g.ReportAlter(errors.New("alter is down"))
+				assert.Equal(t, false, g.IsReadOnly())
+
+				now = now.Add(2 * time.Minute)
+				g.ReportAlter(errors.New("alter is down"))
+				assert.Equal(t, true, g.IsReadOnly())
+
+				g.ReportAlter(nil)
+				assert.Equal(t, false, g.IsReadOnly())
+			},
+		},
+		{
+			"Read-only while journal is too big",
+			func(t *testing.T) {
+				journalLen := 10
+
+				g := NewGuard(&config.ReadOnlyOptions{MaxJournalSize: 10}, nil, nil)
+				g.WithJournalLen(func() int { return journalLen })
+				assert.Equal(t, false, g.IsReadOnly())
+
+				journalLen = 11
+				assert.Equal(t, true, g.IsReadOnly())
+			},
+		},
+		{
+			"Recovers once probe of alter succeeds",
+			func(t *testing.T) {
+				now := time.Now()
+
+				g := NewGuard(&config.ReadOnlyOptions{MaxAlterDowntime: time.Minute}, nil, nil)
+				g.now = func() time.Time { return now }
+
+				g.ReportAlter(errors.New("alter is down"))
+				now = now.Add(2 * time.Minute)
+				assert.Equal(t, true, g.IsReadOnly())
+
+				ctx, cancel := context.WithCancel(context.Background())
+
+				// probe succeeds and stops guard
+				g.Run(ctx, func(ctx context.Context) error {
+					cancel()
+					return nil
+				}, time.Millisecond)
+
+				assert.Equal(t, false, g.IsReadOnly())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestReadOnlyLayer(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+
+	ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		return nil
+	}
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{}, nil
+	}
+
+	journalLen := 0
+
+	g := NewGuard(&config.ReadOnlyOptions{MaxJournalSize: 1}, nil, nil)
+	g.WithJournalLen(func() int { return journalLen })
+
+	r := NewReadOnlyLayer(ol, g)
+
+	assert.NoError(t, r.DeleteObject(context.Background(), "bucket", "object"))
+
+	journalLen = 2
+
+	assert.Equal(t, minio.BackendDown{}, r.DeleteObject(context.Background(), "bucket", "object"))
+
+	_, err := r.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+	assert.NoError(t, err)
+}
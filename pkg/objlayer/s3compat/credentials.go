@@ -0,0 +1,65 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3compat
+
+import (
+	"sync"
+
+	"github.com/minio/minio-go/pkg/credentials"
+)
+
+// Credentials are access keys of backend which may be replaced while backend is in use, e.g. when keys are rotated.
+// Requests started before keys were replaced finish with old keys, later requests are signed with new keys.
+type Credentials struct {
+	mu        sync.Mutex
+	accessKey string
+	secretKey string
+	signer    credentials.SignatureType
+	changed   bool
+}
+
+// NewCredentials creates credentials of accessKey and secretKey.
+func NewCredentials(accessKey, secretKey string) *Credentials {
+	return &Credentials{accessKey: accessKey, secretKey: secretKey, changed: true}
+}
+
+// Set replaces keys, returns false if they are the same as current ones.
+func (c *Credentials) Set(accessKey, secretKey string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessKey == accessKey && c.secretKey == secretKey {
+		return false
+	}
+
+	c.accessKey, c.secretKey, c.changed = accessKey, secretKey, true
+
+	return true
+}
+
+// setSigner sets signature version requests are signed with.
+func (c *Credentials) setSigner(signer credentials.SignatureType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.signer, c.changed = signer, true
+}
+
+// Retrieve implements credentials.Provider, requests of backend without keys are anonymous.
+func (c *Credentials) Retrieve() (credentials.Value, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.changed = false
+
+	return credentials.Value{AccessKeyID: c.accessKey, SecretAccessKey: c.secretKey, SignerType: c.signer}, nil
+}
+
+// IsExpired implements credentials.Provider, credentials expire when they are replaced.
+func (c *Credentials) IsExpired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.changed
+}
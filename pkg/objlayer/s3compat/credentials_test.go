@@ -0,0 +1,32 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3compat
+
+import (
+	"testing"
+
+	"github.com/minio/minio-go/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentials(t *testing.T) {
+	c := NewCredentials("access", "secret")
+	creds := credentials.New(c)
+
+	v, err := creds.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "access", v.AccessKeyID)
+	assert.False(t, c.IsExpired())
+
+	assert.False(t, c.Set("access", "secret"))
+	assert.False(t, c.IsExpired())
+
+	assert.True(t, c.Set("rotated", "rotated-secret"))
+	assert.True(t, c.IsExpired())
+
+	v, err = creds.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated", v.AccessKeyID)
+	assert.Equal(t, "rotated-secret", v.SecretAccessKey)
+}
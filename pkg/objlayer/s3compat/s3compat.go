@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	miniogo "github.com/minio/minio-go"
+	"github.com/minio/minio-go/pkg/credentials"
 	minio "github.com/minio/minio/cmd"
 	"github.com/minio/minio/pkg/hash"
 	"io"
 	"math/rand"
+	"strings"
 	"time"
+
+	dcontext "storj.io/ditto/pkg/context"
 )
 
 const letterBytes = "abcdefghijklmnopqrstuvwxyz01234569"
@@ -45,6 +49,11 @@ type s3Compat struct {
 }
 
 func NewS3Compat(url, accessKey, secretKey string) (*s3Compat, error) {
+	return NewS3CompatWithCredentials(url, NewCredentials(accessKey, secretKey))
+}
+
+// NewS3CompatWithCredentials creates backend signing requests with creds, which may be replaced at runtime.
+func NewS3CompatWithCredentials(url string, creds *Credentials) (*s3Compat, error) {
 	if url == "" {
 		return nil, fmt.Errorf("No url provided for initializing s3compat instance")
 	}
@@ -54,19 +63,17 @@ func NewS3Compat(url, accessKey, secretKey string) (*s3Compat, error) {
 		return nil, err
 	}
 
-	clnt, err := miniogo.NewV4(endpoint, accessKey, secretKey, secure)
+	clnt, err := miniogo.NewWithCredentials(endpoint, credentials.New(creds), secure, "")
 	if err != nil {
 		return nil, err
 	}
 
 	probeBucketName := randString(60, rand.NewSource(time.Now().UnixNano()), "probe-bucket-sign-")
 
-	if _, err = clnt.BucketExists(probeBucketName); err != nil {
-		clnt, err = miniogo.NewV2(endpoint, accessKey, secretKey, secure)
+	creds.setSigner(credentials.SignatureV4)
 
-		if err != nil {
-			return nil, err
-		}
+	if _, err = clnt.BucketExists(probeBucketName); err != nil {
+		creds.setSigner(credentials.SignatureV2)
 
 		if _, err = clnt.BucketExists(probeBucketName); err != nil {
 			return nil, err
@@ -174,6 +181,10 @@ func (s *s3Compat) GetObject(ctx context.Context, bucket, object string, startOf
 		}
 	}
 
+	if err := setPreconditions(ctx, &getObjectOptions, etag); err != nil {
+		return minio.ErrorRespToObjectError(err, bucket, object)
+	}
+
 	reader, _, err := s.Client.GetObject(bucket, object, getObjectOptions)
 	if err != nil {
 		return minio.ErrorRespToObjectError(err, bucket, object)
@@ -185,6 +196,48 @@ func (s *s3Compat) GetObject(ctx context.Context, bucket, object string, startOf
 	return
 }
 
+// setPreconditions makes backend serve object only if it's still the one described by etag,
+// and if conditions of client request stored in ctx hold. Conditions resulting in 304 Not Modified
+// are left to the API handler, which evaluates them with GetObjectInfo.
+func setPreconditions(ctx context.Context, opts *miniogo.GetObjectOptions, etag string) error {
+	p, _ := dcontext.PreconditionsFromContext(ctx)
+
+	if etag != "" {
+		if err := opts.SetMatchETag(strings.Trim(etag, "\"")); err != nil {
+			return err
+		}
+	} else if ifMatch := quoteETags(p.IfMatch); ifMatch != "" {
+		opts.Set("If-Match", ifMatch)
+	}
+
+	if !p.IfUnmodifiedSince.IsZero() {
+		return opts.SetUnmodified(p.IfUnmodifiedSince)
+	}
+
+	return nil
+}
+
+// quoteETags returns list of ETags of If-Match header with every ETag quoted as backends expect.
+// Empty string is returned for empty list or list containing "*", which matches any object.
+func quoteETags(header string) string {
+	var quoted []string
+
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(etag), "W/"), "\"")
+
+		switch etag {
+		case "":
+			continue
+		case "*":
+			return ""
+		}
+
+		quoted = append(quoted, "\""+etag+"\"")
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
 func (s *s3Compat) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
 	oi, err := s.Client.StatObject(bucket, object, miniogo.StatObjectOptions{})
 	if err != nil {
@@ -230,15 +283,59 @@ func (s *s3Compat) DeleteObject(ctx context.Context, bucket, object string) erro
 	return nil
 }
 
+//Multipart operations
+func (s *s3Compat) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (uploadID string, err error) {
+	uploadID, err = s.Client.NewMultipartUpload(bucket, object, miniogo.PutObjectOptions{
+		UserMetadata:         minio.ToMinioClientMetadata(metadata),
+		ServerSideEncryption: opts.ServerSideEncryption,
+	})
+	if err != nil {
+		return uploadID, minio.ErrorRespToObjectError(err, bucket, object)
+	}
+
+	return uploadID, nil
+}
+
+func (s *s3Compat) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (pi minio.PartInfo, err error) {
+	info, err := s.Client.PutObjectPart(bucket, object, uploadID, partID, data, data.Size(), data.MD5Base64String(), data.SHA256HexString(), opts.ServerSideEncryption)
+	if err != nil {
+		return pi, minio.ErrorRespToObjectError(err, bucket, object)
+	}
+
+	return minio.FromMinioClientObjectPart(info), nil
+}
+
+func (s *s3Compat) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (lpi minio.ListPartsInfo, err error) {
+	result, err := s.Client.ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+	if err != nil {
+		return lpi, minio.ErrorRespToObjectError(err, bucket, object)
+	}
+
+	return minio.FromMinioClientListPartsInfo(result), nil
+}
+
+func (s *s3Compat) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error {
+	err := s.Client.AbortMultipartUpload(bucket, object, uploadID)
+	if err != nil {
+		return minio.ErrorRespToObjectError(err, bucket, object)
+	}
+
+	return nil
+}
+
+func (s *s3Compat) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	err = s.Client.CompleteMultipartUpload(bucket, object, uploadID, minio.ToMinioClientCompleteParts(uploadedParts))
+	if err != nil {
+		return objInfo, minio.ErrorRespToObjectError(err, bucket, object)
+	}
+
+	return s.GetObjectInfo(ctx, bucket, object, opts)
+}
+
 //// Multipart operations.
 //ListMultipartUploads(ctx context.Context, bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result ListMultipartsInfo, err error)
-//NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string) (uploadID string, err error)
 //CopyObjectPart(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, uploadID string, partID int,
 //startOffset int64, length int64, srcInfo ObjectInfo) (info PartInfo, err error)
-//PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader) (info PartInfo, err error)
-//ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (result ListPartsInfo, err error)
-//AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error
-//CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []CompletePart) (objInfo ObjectInfo, err error)
 //
 //// Healing operations.
 //ReloadFormat(ctx context.Context, dryRun bool) error
@@ -0,0 +1,38 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3compat
+
+import (
+	"context"
+	"testing"
+
+	miniogo "github.com/minio/minio-go"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+)
+
+func TestSetPreconditions(t *testing.T) {
+	cases := []struct {
+		testName string
+		etag     string
+		ifMatch  string
+		expected string
+	}{
+		{"ETag of read object", "\"etag\"", "other", "\"etag\""},
+		{"Single ETag", "", "etag", "\"etag\""},
+		{"List of ETags", "", "\"a\", W/\"b\",c", "\"a\", \"b\", \"c\""},
+		{"Any ETag", "", "a, *", ""},
+		{"No ETag", "", "", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			ctx := dcontext.WithPreconditions(context.Background(), dcontext.Preconditions{IfMatch: c.ifMatch})
+
+			var opts miniogo.GetObjectOptions
+			assert.NoError(t, setPreconditions(ctx, &opts, c.etag))
+			assert.Equal(t, c.expected, opts.Header().Get("If-Match"))
+		})
+	}
+}
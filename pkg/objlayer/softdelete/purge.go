@@ -0,0 +1,124 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package softdelete
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	l "storj.io/ditto/pkg/logger"
+)
+
+// DefaultPurgeInterval is interval between purges of trash.
+const DefaultPurgeInterval = time.Hour
+
+// purgePageSize is number of trash entries listed at once.
+const purgePageSize = 1000
+
+// Purger removes objects kept in trash longer than retention from every bucket of object layer,
+// which has to be the layer below soft delete layer, since trash is hidden by it.
+type Purger struct {
+	ol          minio.ObjectLayer
+	trashPrefix string
+	retention   time.Duration
+	logger      l.Logger
+
+	now func() time.Time
+}
+
+// NewPurger creates Purger of trash under trashPrefix, empty trashPrefix is DefaultTrashPrefix.
+func NewPurger(ol minio.ObjectLayer, trashPrefix string, retention time.Duration, logger l.Logger) *Purger {
+	if trashPrefix == "" {
+		trashPrefix = DefaultTrashPrefix
+	}
+
+	return &Purger{ol: ol, trashPrefix: trashPrefix, retention: retention, logger: logger, now: time.Now}
+}
+
+// Run purges trash every DefaultPurgeInterval until ctx is done.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(DefaultPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := p.Purge(ctx)
+			if err != nil && p.logger != nil {
+				p.logger.LogE(err)
+			}
+
+			if purged > 0 && p.logger != nil {
+				p.logger.Log(fmt.Sprintf("softdelete: purged %d objects from trash", purged))
+			}
+		}
+	}
+}
+
+// Purge removes expired objects from trash of every bucket and returns number of objects removed.
+func (p *Purger) Purge(ctx context.Context) (int, error) {
+	buckets, err := p.ol.ListBuckets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, b := range buckets {
+		n, err := p.purgeBucket(ctx, b.Name)
+		purged += n
+
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// purgeBucket removes objects deleted before retention from trash of bucket. Trash keys are sorted
+// by time of deletion, so listing stops at the first object which isn't expired.
+func (p *Purger) purgeBucket(ctx context.Context, bucket string) (int, error) {
+	expired := p.now().Add(-p.retention).UTC().Format(timeFormat)
+	purged, marker := 0, ""
+
+	for {
+		loi, err := p.ol.ListObjects(ctx, bucket, p.trashPrefix, marker, "", purgePageSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for _, oi := range loi.Objects {
+			if p.deletedAt(oi.Name) >= expired {
+				return purged, nil
+			}
+
+			if err := p.ol.DeleteObject(ctx, bucket, oi.Name); err != nil {
+				return purged, err
+			}
+
+			purged++
+		}
+
+		marker = nextMarker(loi)
+		if !loi.IsTruncated || marker == "" {
+			return purged, nil
+		}
+	}
+}
+
+// deletedAt returns time of deletion of object kept under trash key, formatted as timeFormat.
+func (p *Purger) deletedAt(trashKey string) string {
+	deletedAt := strings.TrimPrefix(trashKey, p.trashPrefix)
+	if i := strings.Index(deletedAt, "/"); i >= 0 {
+		deletedAt = deletedAt[:i]
+	}
+
+	return deletedAt
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package softdelete
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestPurge(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+
+	ol.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		return []minio.BucketInfo{{Name: "bucket"}}, nil
+	}
+
+	ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		assert.Equal(t, ".trash/", prefix)
+
+		return minio.ListObjectsInfo{Objects: []minio.ObjectInfo{
+			{Name: ".trash/20181001T100000Z/dir/old"},
+			{Name: ".trash/20181001T105900Z/expired"},
+			{Name: ".trash/20181001T113000Z/recent"},
+			{Name: ".trash/20181001T115000Z/recent"},
+		}}, nil
+	}
+
+	var deleted []string
+	ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		deleted = append(deleted, object)
+		return nil
+	}
+
+	p := NewPurger(ol, "", time.Hour, nil)
+	p.now = func() time.Time { return time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC) }
+
+	purged, err := p.Purge(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.Equal(t, []string{".trash/20181001T100000Z/dir/old", ".trash/20181001T105900Z/expired"}, deleted)
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package softdelete
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+const DefaultTrashPrefix = ".trash/"
+
+// timeFormat is sortable, so trash can be purged by age of prefix.
+const timeFormat = "20060102T150405Z"
+
+// NewSoftDeleteLayer wraps object layer so deleted objects are moved to trash prefix of the same bucket
+// instead of being removed. Object deleted at time T is kept as <trashPrefix><T>/<object>.
+// Trash is hidden from listings.
+func NewSoftDeleteLayer(ol minio.ObjectLayer, trashPrefix string) minio.ObjectLayer {
+	if trashPrefix == "" {
+		trashPrefix = DefaultTrashPrefix
+	}
+
+	return &softDeleteLayer{ObjectLayer: ol, trashPrefix: trashPrefix, now: time.Now}
+}
+
+type softDeleteLayer struct {
+	minio.ObjectLayer
+	trashPrefix string
+
+	now func() time.Time
+}
+
+// TrashKey returns key under which object deleted at t is kept.
+func TrashKey(trashPrefix, object string, t time.Time) string {
+	return trashPrefix + path.Join(t.UTC().Format(timeFormat), object)
+}
+
+// DeleteObject moves object to trash. Object which is already missing is deleted, like by S3 DeleteObject.
+func (s *softDeleteLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	if strings.HasPrefix(object, s.trashPrefix) {
+		return s.ObjectLayer.DeleteObject(ctx, bucket, object)
+	}
+
+	oi, err := s.ObjectLayer.GetObjectInfo(ctx, bucket, object, minio.ObjectOptions{})
+	if err != nil {
+		if _, ok := err.(minio.ObjectNotFound); ok {
+			return nil
+		}
+
+		return err
+	}
+
+	if oi.UserDefined == nil {
+		oi.UserDefined = map[string]string{}
+	}
+
+	trashKey := TrashKey(s.trashPrefix, object, s.now())
+
+	_, err = s.ObjectLayer.CopyObject(ctx, bucket, object, bucket, trashKey, oi, minio.ObjectOptions{}, minio.ObjectOptions{})
+	if err != nil {
+		return err
+	}
+
+	return s.ObjectLayer.DeleteObject(ctx, bucket, object)
+}
+
+// ListObjects lists pages of backend until maxKeys entries outside trash are collected,
+// so trash doesn't make listing end early nor return less entries than requested.
+func (s *softDeleteLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	var result minio.ListObjectsInfo
+
+	for {
+		loi, err := s.ObjectLayer.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+		if err != nil {
+			return result, err
+		}
+
+		result.Objects = append(result.Objects, s.filterObjects(loi.Objects)...)
+		result.Prefixes = append(result.Prefixes, s.filterPrefixes(loi.Prefixes)...)
+		result.IsTruncated = loi.IsTruncated
+		result.NextMarker = nextMarker(loi)
+
+		if !result.IsTruncated || result.NextMarker == "" || maxKeys <= 0 || len(result.Objects)+len(result.Prefixes) >= maxKeys {
+			break
+		}
+
+		marker = result.NextMarker
+	}
+
+	return truncate(result, maxKeys), nil
+}
+
+// ListObjectsV2 lists objects like ListObjects, continuation token is the marker of the next page.
+func (s *softDeleteLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	marker := continuationToken
+	if marker == "" {
+		marker = startAfter
+	}
+
+	loi, err := s.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return minio.ListObjectsV2Info{}, err
+	}
+
+	result := minio.ListObjectsV2Info{
+		IsTruncated:       loi.IsTruncated,
+		ContinuationToken: continuationToken,
+		Objects:           loi.Objects,
+		Prefixes:          loi.Prefixes,
+	}
+
+	if loi.IsTruncated {
+		result.NextContinuationToken = loi.NextMarker
+	}
+
+	return result, nil
+}
+
+// nextMarker returns marker of page following loi. Backends set NextMarker only for listings with delimiter,
+// otherwise it's the last entry listed.
+func nextMarker(loi minio.ListObjectsInfo) string {
+	if !loi.IsTruncated || loi.NextMarker != "" {
+		return loi.NextMarker
+	}
+
+	last := ""
+	if n := len(loi.Objects); n > 0 {
+		last = loi.Objects[n-1].Name
+	}
+
+	if n := len(loi.Prefixes); n > 0 && loi.Prefixes[n-1] > last {
+		last = loi.Prefixes[n-1]
+	}
+
+	return last
+}
+
+// truncate keeps first maxKeys entries of loi in key order, the last one kept is the marker of the next page.
+func truncate(loi minio.ListObjectsInfo, maxKeys int) minio.ListObjectsInfo {
+	if maxKeys <= 0 || len(loi.Objects)+len(loi.Prefixes) <= maxKeys {
+		return loi
+	}
+
+	i, j, last := 0, 0, ""
+	for i+j < maxKeys {
+		if j == len(loi.Prefixes) || (i < len(loi.Objects) && loi.Objects[i].Name < loi.Prefixes[j]) {
+			last = loi.Objects[i].Name
+			i++
+		} else {
+			last = loi.Prefixes[j]
+			j++
+		}
+	}
+
+	loi.Objects, loi.Prefixes = loi.Objects[:i], loi.Prefixes[:j]
+	loi.IsTruncated, loi.NextMarker = true, last
+
+	return loi
+}
+
+func (s *softDeleteLayer) filterObjects(objects []minio.ObjectInfo) []minio.ObjectInfo {
+	filtered := objects[:0]
+
+	for _, o := range objects {
+		if !strings.HasPrefix(o.Name, s.trashPrefix) {
+			filtered = append(filtered, o)
+		}
+	}
+
+	return filtered
+}
+
+func (s *softDeleteLayer) filterPrefixes(prefixes []string) []string {
+	filtered := prefixes[:0]
+
+	for _, p := range prefixes {
+		if !strings.HasPrefix(p, s.trashPrefix) {
+			filtered = append(filtered, p)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package softdelete
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestSoftDeleteLayer(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Deleted object moved to trash",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+
+				var copiedTo, deleted string
+
+				ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{Bucket: bucket, Name: object}, nil
+				}
+
+				ol.CopyObjectFunc = func(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					copiedTo = destObject
+					return minio.ObjectInfo{}, nil
+				}
+
+				ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					deleted = object
+					return nil
+				}
+
+				l := NewSoftDeleteLayer(ol, "").(*softDeleteLayer)
+				l.now = func() time.Time { return now }
+
+				err := l.DeleteObject(context.Background(), "bucket", "dir/object")
+
+				assert.NoError(t, err)
+				assert.Equal(t, ".trash/20181001T120000Z/dir/object", copiedTo)
+				assert.Equal(t, "dir/object", deleted)
+			},
+		},
+		{
+			"Missing object is deleted without moving it to trash",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+
+				ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+				}
+
+				isDeleteCalled := false
+				ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					isDeleteCalled = true
+					return nil
+				}
+
+				err := NewSoftDeleteLayer(ol, "").DeleteObject(context.Background(), "bucket", "object")
+
+				assert.NoError(t, err)
+				assert.Equal(t, false, isDeleteCalled)
+			},
+		},
+		{
+			"Trash hidden from listing",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+
+				ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+					return minio.ListObjectsInfo{
+						Objects:  []minio.ObjectInfo{{Name: "object"}, {Name: ".trash/20181001T120000Z/object"}},
+						Prefixes: []string{".trash/", "dir/"},
+					}, nil
+				}
+
+				loi, err := NewSoftDeleteLayer(ol, "").ListObjects(context.Background(), "bucket", "", "", "/", 1000)
+
+				assert.NoError(t, err)
+				assert.Equal(t, 1, len(loi.Objects))
+				assert.Equal(t, "object", loi.Objects[0].Name)
+				assert.Equal(t, []string{"dir/"}, loi.Prefixes)
+			},
+		},
+		{
+			"Trash is filtered before listing is truncated",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+
+				keys := []string{".trash/20181001T120000Z/a", ".trash/20181001T120000Z/b", "a", "b", "c"}
+
+				// backend lists pages of maxKeys keys following marker
+				ol.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+					var loi minio.ListObjectsInfo
+					for _, k := range keys {
+						if k <= marker {
+							continue
+						}
+
+						if len(loi.Objects) == maxKeys {
+							loi.IsTruncated = true
+							break
+						}
+
+						loi.Objects = append(loi.Objects, minio.ObjectInfo{Name: k})
+					}
+
+					return loi, nil
+				}
+
+				l := NewSoftDeleteLayer(ol, "")
+
+				loi, err := l.ListObjects(context.Background(), "bucket", "", "", "", 2)
+
+				assert.NoError(t, err)
+				assert.Equal(t, []minio.ObjectInfo{{Name: "a"}, {Name: "b"}}, loi.Objects)
+				assert.True(t, loi.IsTruncated)
+				assert.Equal(t, "b", loi.NextMarker)
+
+				v2, err := l.ListObjectsV2(context.Background(), "bucket", "", loi.NextMarker, "", 2, false, "")
+
+				assert.NoError(t, err)
+				assert.Equal(t, []minio.ObjectInfo{{Name: "c"}}, v2.Objects)
+				assert.False(t, v2.IsTruncated)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	dcontext "storj.io/ditto/pkg/context"
+)
+
+// Tenant is object layer serving requests signed with one of AccessKeys, or addressing buckets
+// starting with BucketPrefix.
+type Tenant struct {
+	Name         string
+	AccessKeys   []string
+	BucketPrefix string
+	Layer        minio.ObjectLayer
+}
+
+// NewTenantLayer routes every request to object layer of its tenant, requests of no tenant are served by root.
+// Tenant is chosen by access key of request first, then by the longest bucket prefix.
+// Buckets listed by users of no tenant are root buckets and buckets of tenants chosen by bucket prefix.
+func NewTenantLayer(root minio.ObjectLayer, tenants []Tenant) (minio.ObjectLayer, error) {
+	t := &tenantLayer{ObjectLayer: root, users: make(map[string]*Tenant)}
+
+	for i := range tenants {
+		tenant := &tenants[i]
+
+		if len(tenant.AccessKeys) == 0 && tenant.BucketPrefix == "" {
+			return nil, fmt.Errorf("tenant %q has neither access keys nor bucket prefix", tenant.Name)
+		}
+
+		for _, key := range tenant.AccessKeys {
+			if other, ok := t.users[key]; ok {
+				return nil, fmt.Errorf("access key %s belongs to tenants %q and %q", key, other.Name, tenant.Name)
+			}
+
+			t.users[key] = tenant
+		}
+
+		if tenant.BucketPrefix != "" {
+			t.prefixed = append(t.prefixed, tenant)
+		}
+	}
+
+	// the longest prefix is matched first
+	sort.SliceStable(t.prefixed, func(i, j int) bool {
+		return len(t.prefixed[i].BucketPrefix) > len(t.prefixed[j].BucketPrefix)
+	})
+
+	return t, nil
+}
+
+type tenantLayer struct {
+	minio.ObjectLayer
+	users    map[string]*Tenant
+	prefixed []*Tenant
+}
+
+// byUser returns tenant of user issuing request, if any.
+func (t *tenantLayer) byUser(ctx context.Context) (*Tenant, bool) {
+	user, ok := dcontext.UserFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	tenant, ok := t.users[user]
+
+	return tenant, ok
+}
+
+// byBucket returns tenant owning bucket by its prefix, if any.
+func (t *tenantLayer) byBucket(bucket string) (*Tenant, bool) {
+	for _, tenant := range t.prefixed {
+		if strings.HasPrefix(bucket, tenant.BucketPrefix) {
+			return tenant, true
+		}
+	}
+
+	return nil, false
+}
+
+// layer returns object layer serving request for bucket.
+func (t *tenantLayer) layer(ctx context.Context, bucket string) minio.ObjectLayer {
+	if tenant, ok := t.byUser(ctx); ok {
+		return tenant.Layer
+	}
+
+	if tenant, ok := t.byBucket(bucket); ok {
+		return tenant.Layer
+	}
+
+	return t.ObjectLayer
+}
+
+func (t *tenantLayer) Shutdown(ctx context.Context) error {
+	err := t.ObjectLayer.Shutdown(ctx)
+
+	seen := map[*Tenant]bool{}
+	for _, tenant := range t.users {
+		seen[tenant] = true
+	}
+
+	for _, tenant := range t.prefixed {
+		seen[tenant] = true
+	}
+
+	for tenant := range seen {
+		if terr := tenant.Layer.Shutdown(ctx); err == nil {
+			err = terr
+		}
+	}
+
+	return err
+}
+
+func (t *tenantLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	return t.layer(ctx, bucket).MakeBucketWithLocation(ctx, bucket, location)
+}
+
+func (t *tenantLayer) GetBucketInfo(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+	return t.layer(ctx, bucket).GetBucketInfo(ctx, bucket)
+}
+
+func (t *tenantLayer) ListBuckets(ctx context.Context) ([]minio.BucketInfo, error) {
+	if tenant, ok := t.byUser(ctx); ok {
+		return tenant.Layer.ListBuckets(ctx)
+	}
+
+	rootBuckets, err := t.ObjectLayer.ListBuckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []minio.BucketInfo
+
+	// root buckets shadowed by tenant prefix aren't reachable
+	for _, bucket := range rootBuckets {
+		if _, ok := t.byBucket(bucket.Name); !ok {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	for _, tenant := range t.prefixed {
+		tenantBuckets, err := tenant.Layer.ListBuckets(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, bucket := range tenantBuckets {
+			if owner, ok := t.byBucket(bucket.Name); ok && owner == tenant {
+				buckets = append(buckets, bucket)
+			}
+		}
+	}
+
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+
+	return buckets, nil
+}
+
+func (t *tenantLayer) DeleteBucket(ctx context.Context, bucket string) error {
+	return t.layer(ctx, bucket).DeleteBucket(ctx, bucket)
+}
+
+func (t *tenantLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	return t.layer(ctx, bucket).ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+}
+
+func (t *tenantLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	return t.layer(ctx, bucket).ListObjectsV2(ctx, bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+}
+
+func (t *tenantLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	return t.layer(ctx, bucket).GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+}
+
+func (t *tenantLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	return t.layer(ctx, bucket).GetObjectInfo(ctx, bucket, object, opts)
+}
+
+func (t *tenantLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	return t.layer(ctx, bucket).PutObject(ctx, bucket, object, data, metadata, opts)
+}
+
+// CopyObject copies object within backends of a single tenant, copies between tenants aren't supported.
+func (t *tenantLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	ol := t.layer(ctx, destBucket)
+	if t.layer(ctx, srcBucket) != ol {
+		return minio.ObjectInfo{}, minio.NotImplemented{}
+	}
+
+	return ol.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+}
+
+func (t *tenantLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	return t.layer(ctx, bucket).DeleteObject(ctx, bucket, object)
+}
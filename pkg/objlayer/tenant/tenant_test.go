@@ -0,0 +1,111 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	dcontext "storj.io/ditto/pkg/context"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// newLayer creates object layer recording name of bucket info was requested for and owning buckets.
+func newLayer(requested *string, buckets ...string) minio.ObjectLayer {
+	ol := test.NewProxyObjectLayer()
+
+	ol.GetBucketInfoFunc = func(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+		*requested = bucket
+		return minio.BucketInfo{Name: bucket}, nil
+	}
+
+	ol.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		var infos []minio.BucketInfo
+		for _, b := range buckets {
+			infos = append(infos, minio.BucketInfo{Name: b})
+		}
+
+		return infos, nil
+	}
+
+	return ol
+}
+
+func TestTenantLayer(t *testing.T) {
+	var root, team, teamLogs, keyed string
+
+	ol, err := NewTenantLayer(newLayer(&root, "data", "team-shadowed"), []Tenant{
+		{Name: "team", BucketPrefix: "team-", Layer: newLayer(&team, "team-a", "other")},
+		{Name: "team-logs", BucketPrefix: "team-logs-", Layer: newLayer(&teamLogs, "team-logs-a")},
+		{Name: "keyed", AccessKeys: []string{"key"}, Layer: newLayer(&keyed, "private")},
+	})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Requests are routed by bucket prefix",
+			func(t *testing.T) {
+				for _, bucket := range []string{"data", "team-a", "team-logs-a"} {
+					_, err := ol.GetBucketInfo(ctx, bucket)
+					assert.NoError(t, err)
+				}
+
+				assert.Equal(t, "data", root)
+				assert.Equal(t, "team-a", team)
+				assert.Equal(t, "team-logs-a", teamLogs)
+			},
+		},
+		{
+			"Requests are routed by access key first",
+			func(t *testing.T) {
+				_, err := ol.GetBucketInfo(dcontext.WithUser(ctx, "key"), "team-b")
+				assert.NoError(t, err)
+				assert.Equal(t, "team-b", keyed)
+
+				buckets, err := ol.ListBuckets(dcontext.WithUser(ctx, "key"))
+				assert.NoError(t, err)
+				assert.Equal(t, []minio.BucketInfo{{Name: "private"}}, buckets)
+			},
+		},
+		{
+			"Buckets of root and prefixed tenants are listed",
+			func(t *testing.T) {
+				buckets, err := ol.ListBuckets(dcontext.WithUser(ctx, "unknown"))
+				assert.NoError(t, err)
+				assert.Equal(t, []minio.BucketInfo{{Name: "data"}, {Name: "team-a"}, {Name: "team-logs-a"}}, buckets)
+			},
+		},
+		{
+			"Copy between tenants is refused",
+			func(t *testing.T) {
+				_, err := ol.CopyObject(ctx, "data", "object", "team-a", "object", minio.ObjectInfo{}, minio.ObjectOptions{}, minio.ObjectOptions{})
+				assert.Equal(t, minio.NotImplemented{}, err)
+			},
+		},
+		{
+			"Invalid tenants are refused",
+			func(t *testing.T) {
+				_, err := NewTenantLayer(newLayer(&root), []Tenant{{Name: "nobody", Layer: newLayer(&team)}})
+				assert.Error(t, err)
+
+				_, err = NewTenantLayer(newLayer(&root), []Tenant{
+					{Name: "a", AccessKeys: []string{"key"}, Layer: newLayer(&team)},
+					{Name: "b", AccessKeys: []string{"key"}, Layer: newLayer(&team)},
+				})
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
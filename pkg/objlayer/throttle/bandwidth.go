@@ -0,0 +1,45 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package throttle
+
+import (
+	"context"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/ratelimit"
+)
+
+// NewBandwidthLayer wraps object layer so uploaded data is streamed no faster than limiter allows,
+// one token is one byte. Limiter is shared by all concurrent uploads.
+func NewBandwidthLayer(ol minio.ObjectLayer, limiter *ratelimit.Limiter) minio.ObjectLayer {
+	return &bandwidthLayer{ObjectLayer: ol, limiter: limiter}
+}
+
+type bandwidthLayer struct {
+	minio.ObjectLayer
+	limiter *ratelimit.Limiter
+}
+
+func (b *bandwidthLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	r := ratelimit.NewReader(ctx, data, b.limiter)
+
+	throttled, err := hash.NewReader(r, data.Size(), data.MD5HexString(), data.SHA256HexString())
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	return b.ObjectLayer.PutObject(ctx, bucket, object, throttled, metadata, opts)
+}
+
+func (b *bandwidthLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+	r := ratelimit.NewReader(ctx, data, b.limiter)
+
+	throttled, err := hash.NewReader(r, data.Size(), data.MD5HexString(), data.SHA256HexString())
+	if err != nil {
+		return minio.PartInfo{}, err
+	}
+
+	return b.ObjectLayer.PutObjectPart(ctx, bucket, object, uploadID, partID, throttled, opts)
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package throttle
+
+import (
+	"context"
+	"io"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/ratelimit"
+)
+
+// NewRateLimitLayer wraps object layer so every request waits for a token of its limiter.
+// Bucket and object reads take tokens from read limiter, writes and deletes from write limiter.
+// Every call of multipart upload takes a token, listing its parts from read limiter, others from write limiter.
+// Nil limiter doesn't limit requests.
+func NewRateLimitLayer(ol minio.ObjectLayer, read, write *ratelimit.Limiter) minio.ObjectLayer {
+	return &rateLimitLayer{ObjectLayer: ol, read: read, write: write}
+}
+
+type rateLimitLayer struct {
+	minio.ObjectLayer
+	read, write *ratelimit.Limiter
+}
+
+func (r *rateLimitLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	if err := r.write.Wait(ctx); err != nil {
+		return err
+	}
+
+	return r.ObjectLayer.MakeBucketWithLocation(ctx, bucket, location)
+}
+
+func (r *rateLimitLayer) GetBucketInfo(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+	if err := r.read.Wait(ctx); err != nil {
+		return minio.BucketInfo{}, err
+	}
+
+	return r.ObjectLayer.GetBucketInfo(ctx, bucket)
+}
+
+func (r *rateLimitLayer) ListBuckets(ctx context.Context) ([]minio.BucketInfo, error) {
+	if err := r.read.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.ObjectLayer.ListBuckets(ctx)
+}
+
+func (r *rateLimitLayer) DeleteBucket(ctx context.Context, bucket string) error {
+	if err := r.write.Wait(ctx); err != nil {
+		return err
+	}
+
+	return r.ObjectLayer.DeleteBucket(ctx, bucket)
+}
+
+func (r *rateLimitLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	if err := r.read.Wait(ctx); err != nil {
+		return minio.ListObjectsInfo{}, err
+	}
+
+	return r.ObjectLayer.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+}
+
+func (r *rateLimitLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	if err := r.read.Wait(ctx); err != nil {
+		return minio.ListObjectsV2Info{}, err
+	}
+
+	return r.ObjectLayer.ListObjectsV2(ctx, bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+}
+
+func (r *rateLimitLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	if err := r.read.Wait(ctx); err != nil {
+		return err
+	}
+
+	return r.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+}
+
+func (r *rateLimitLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := r.read.Wait(ctx); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	return r.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+}
+
+func (r *rateLimitLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := r.write.Wait(ctx); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	return r.ObjectLayer.PutObject(ctx, bucket, object, data, metadata, opts)
+}
+
+func (r *rateLimitLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := r.write.Wait(ctx); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	return r.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+}
+
+func (r *rateLimitLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	if err := r.write.Wait(ctx); err != nil {
+		return err
+	}
+
+	return r.ObjectLayer.DeleteObject(ctx, bucket, object)
+}
+
+func (r *rateLimitLayer) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+	if err := r.write.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	return r.ObjectLayer.NewMultipartUpload(ctx, bucket, object, metadata, opts)
+}
+
+func (r *rateLimitLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+	if err := r.write.Wait(ctx); err != nil {
+		return minio.PartInfo{}, err
+	}
+
+	return r.ObjectLayer.PutObjectPart(ctx, bucket, object, uploadID, partID, data, opts)
+}
+
+func (r *rateLimitLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListPartsInfo, error) {
+	if err := r.read.Wait(ctx); err != nil {
+		return minio.ListPartsInfo{}, err
+	}
+
+	return r.ObjectLayer.ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+func (r *rateLimitLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error {
+	if err := r.write.Wait(ctx); err != nil {
+		return err
+	}
+
+	return r.ObjectLayer.AbortMultipartUpload(ctx, bucket, object, uploadID)
+}
+
+func (r *rateLimitLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := r.write.Wait(ctx); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	return r.ObjectLayer.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/ratelimit"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestRateLimitLayer(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+
+	ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+		return nil
+	}
+
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{}, nil
+	}
+
+	// single write allowed per hour, reads are unlimited
+	r := NewRateLimitLayer(ol, nil, ratelimit.NewLimiter(1.0/3600, 1))
+
+	assert.NoError(t, r.DeleteObject(context.Background(), "bucket", "object"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, r.DeleteObject(ctx, "bucket", "object"))
+
+	for i := 0; i < 10; i++ {
+		_, err := r.GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+		assert.NoError(t, err)
+	}
+}
+
+func TestRateLimitLayerMultipart(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+
+	ol.NewMultipartUploadFunc = func(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+		return "upload", nil
+	}
+
+	ol.ListObjectPartsFunc = func(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListPartsInfo, error) {
+		return minio.ListPartsInfo{}, nil
+	}
+
+	ol.AbortMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string) error {
+		return nil
+	}
+
+	// single write and read allowed per hour
+	r := NewRateLimitLayer(ol, ratelimit.NewLimiter(1.0/3600, 1), ratelimit.NewLimiter(1.0/3600, 1))
+
+	ctx := context.Background()
+
+	_, err := r.NewMultipartUpload(ctx, "bucket", "object", nil, minio.ObjectOptions{})
+	assert.NoError(t, err)
+
+	_, err = r.ListObjectParts(ctx, "bucket", "object", "upload", 0, 1000)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, r.AbortMultipartUpload(ctx, "bucket", "object", "upload"))
+
+	_, err = r.ListObjectParts(ctx, "bucket", "object", "upload", 0, 1000)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestBandwidthLayerPutObjectPart(t *testing.T) {
+	ol := test.NewProxyObjectLayer()
+
+	ol.PutObjectPartFunc = func(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+		content, err := ioutil.ReadAll(data)
+		return minio.PartInfo{PartNumber: partID, Size: int64(len(content))}, err
+	}
+
+	part := func(ctx context.Context, b minio.ObjectLayer) (minio.PartInfo, error) {
+		data, err := hash.NewReader(bytes.NewReader(make([]byte, 100)), 100, "", "")
+		assert.NoError(t, err)
+
+		return b.PutObjectPart(ctx, "bucket", "object", "upload", 1, data, minio.ObjectOptions{})
+	}
+
+	pi, err := part(context.Background(), NewBandwidthLayer(ol, ratelimit.NewLimiter(1<<20, 1<<10)))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), pi.Size)
+
+	// single byte per second never lets part through
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = part(ctx, NewBandwidthLayer(ol, ratelimit.NewLimiter(1, 1)))
+	assert.Error(t, err)
+}
@@ -0,0 +1,424 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package timeout
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/config"
+)
+
+var (
+	errWriterClosed = errors.New("writer is closed after timeout")
+	errReaderClosed = errors.New("reader is closed after timeout")
+)
+
+// NewTimeoutLayer wraps object layer so every call is bounded by timeout of its operation type.
+// Deadline is propagated with ctx, but since backends may ignore ctx, call is also abandoned
+// once deadline is exceeded and minio.OperationTimedOut is returned. GetObject and PutObject
+// are bounded only while they are idle, so transfers of big objects aren't cut off while data flows.
+func NewTimeoutLayer(ol minio.ObjectLayer, opts *config.TimeoutOptions) minio.ObjectLayer {
+	t := &timeoutLayer{ObjectLayer: ol}
+
+	if opts != nil {
+		t.put, t.get, t.list, t.delete = opts.Put, opts.Get, opts.List, opts.Delete
+	}
+
+	return t
+}
+
+type timeoutLayer struct {
+	minio.ObjectLayer
+	put, get, list, delete time.Duration
+}
+
+// call runs f bounded by timeout d, zero d means no timeout.
+// Returns false if f was abandoned, results written by f must not be used in that case.
+func call(ctx context.Context, d time.Duration, p string, f func(ctx context.Context) error) (bool, error) {
+	return run(ctx, d, p, true, func(ctx context.Context, kick func()) error {
+		return f(ctx)
+	})
+}
+
+// watch runs f until it's idle for d, f calls kick whenever it transfers data.
+// Time to first byte and stalls are bounded, transfer as a whole isn't.
+func watch(ctx context.Context, d time.Duration, p string, f func(ctx context.Context, kick func()) error) (bool, error) {
+	return run(ctx, d, p, false, f)
+}
+
+// run runs f until it isn't kicked for d, with deadline of d propagated with ctx if set.
+// Call is abandoned with minio.OperationTimedOut once it's idle for d, or with error of parent ctx
+// once it's done. ctx of f is cancelled as soon as run returns.
+func run(parent context.Context, d time.Duration, p string, deadline bool, f func(ctx context.Context, kick func()) error) (bool, error) {
+	if d <= 0 {
+		return true, f(parent, func() {})
+	}
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if deadline {
+		ctx, cancel = context.WithTimeout(parent, d)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+	defer cancel()
+
+	kicks := make(chan struct{}, 1)
+	kick := func() {
+		select {
+		case kicks <- struct{}{}:
+		default:
+		}
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- f(ctx, kick)
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case err := <-errc:
+			return true, err
+		case <-kicks:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(d)
+		case <-timer.C:
+			return false, minio.OperationTimedOut{Path: p}
+		case <-parent.Done():
+			return false, parent.Err()
+		}
+	}
+}
+
+func (t *timeoutLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	_, err := call(ctx, t.put, bucket, func(ctx context.Context) error {
+		return t.ObjectLayer.MakeBucketWithLocation(ctx, bucket, location)
+	})
+
+	return err
+}
+
+func (t *timeoutLayer) GetBucketInfo(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+	var bi minio.BucketInfo
+
+	ok, err := call(ctx, t.get, bucket, func(ctx context.Context) (err error) {
+		bi, err = t.ObjectLayer.GetBucketInfo(ctx, bucket)
+		return
+	})
+
+	if !ok {
+		return minio.BucketInfo{}, err
+	}
+
+	return bi, err
+}
+
+func (t *timeoutLayer) ListBuckets(ctx context.Context) ([]minio.BucketInfo, error) {
+	var buckets []minio.BucketInfo
+
+	ok, err := call(ctx, t.list, "", func(ctx context.Context) (err error) {
+		buckets, err = t.ObjectLayer.ListBuckets(ctx)
+		return
+	})
+
+	if !ok {
+		return nil, err
+	}
+
+	return buckets, err
+}
+
+func (t *timeoutLayer) DeleteBucket(ctx context.Context, bucket string) error {
+	_, err := call(ctx, t.delete, bucket, func(ctx context.Context) error {
+		return t.ObjectLayer.DeleteBucket(ctx, bucket)
+	})
+
+	return err
+}
+
+func (t *timeoutLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+	var loi minio.ListObjectsInfo
+
+	ok, err := call(ctx, t.list, path.Join(bucket, prefix), func(ctx context.Context) (err error) {
+		loi, err = t.ObjectLayer.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+		return
+	})
+
+	if !ok {
+		return minio.ListObjectsInfo{}, err
+	}
+
+	return loi, err
+}
+
+func (t *timeoutLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (minio.ListObjectsV2Info, error) {
+	var loi minio.ListObjectsV2Info
+
+	ok, err := call(ctx, t.list, path.Join(bucket, prefix), func(ctx context.Context) (err error) {
+		loi, err = t.ObjectLayer.ListObjectsV2(ctx, bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+		return
+	})
+
+	if !ok {
+		return minio.ListObjectsV2Info{}, err
+	}
+
+	return loi, err
+}
+
+func (t *timeoutLayer) GetObject(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+	if t.get <= 0 {
+		return t.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, writer, etag, opts)
+	}
+
+	// abandoned call must not write to client after we return
+	gw := &guardedWriter{w: writer}
+
+	ok, err := watch(ctx, t.get, path.Join(bucket, object), func(ctx context.Context, kick func()) error {
+		gw.kick = kick
+		return t.ObjectLayer.GetObject(ctx, bucket, object, startOffset, length, gw, etag, opts)
+	})
+
+	if !ok {
+		gw.close()
+	}
+
+	return err
+}
+
+func (t *timeoutLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	var oi minio.ObjectInfo
+
+	ok, err := call(ctx, t.get, path.Join(bucket, object), func(ctx context.Context) (err error) {
+		oi, err = t.ObjectLayer.GetObjectInfo(ctx, bucket, object, opts)
+		return
+	})
+
+	if !ok {
+		return minio.ObjectInfo{}, err
+	}
+
+	return oi, err
+}
+
+// PutObject is abandoned once backend neither reads data nor responds for put timeout. Abandoned call
+// is joined, so data of client isn't read after PutObject returns.
+func (t *timeoutLayer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if t.put <= 0 {
+		return t.ObjectLayer.PutObject(ctx, bucket, object, data, metadata, opts)
+	}
+
+	var oi minio.ObjectInfo
+
+	gr := &guardedReader{r: data}
+	done := make(chan struct{})
+
+	ok, err := watch(ctx, t.put, path.Join(bucket, object), func(ctx context.Context, kick func()) error {
+		defer close(done)
+
+		gr.kick = kick
+
+		watched, err := hash.NewReader(gr, data.Size(), data.MD5HexString(), data.SHA256HexString())
+		if err != nil {
+			return err
+		}
+
+		oi, err = t.ObjectLayer.PutObject(ctx, bucket, object, watched, metadata, opts)
+		return err
+	})
+
+	if !ok {
+		gr.close()
+		<-done
+
+		return minio.ObjectInfo{}, err
+	}
+
+	return oi, err
+}
+
+func (t *timeoutLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	var oi minio.ObjectInfo
+
+	ok, err := call(ctx, t.put, path.Join(destBucket, destObject), func(ctx context.Context) (err error) {
+		oi, err = t.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+		return
+	})
+
+	if !ok {
+		return minio.ObjectInfo{}, err
+	}
+
+	return oi, err
+}
+
+func (t *timeoutLayer) DeleteObject(ctx context.Context, bucket, object string) error {
+	_, err := call(ctx, t.delete, path.Join(bucket, object), func(ctx context.Context) error {
+		return t.ObjectLayer.DeleteObject(ctx, bucket, object)
+	})
+
+	return err
+}
+
+func (t *timeoutLayer) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+	var uploadID string
+
+	ok, err := call(ctx, t.put, path.Join(bucket, object), func(ctx context.Context) (err error) {
+		uploadID, err = t.ObjectLayer.NewMultipartUpload(ctx, bucket, object, metadata, opts)
+		return
+	})
+
+	if !ok {
+		return "", err
+	}
+
+	return uploadID, err
+}
+
+// PutObjectPart is bounded like PutObject, only while backend neither reads data nor responds.
+func (t *timeoutLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+	if t.put <= 0 {
+		return t.ObjectLayer.PutObjectPart(ctx, bucket, object, uploadID, partID, data, opts)
+	}
+
+	var info minio.PartInfo
+
+	gr := &guardedReader{r: data}
+	done := make(chan struct{})
+
+	ok, err := watch(ctx, t.put, path.Join(bucket, object), func(ctx context.Context, kick func()) error {
+		defer close(done)
+
+		gr.kick = kick
+
+		watched, err := hash.NewReader(gr, data.Size(), data.MD5HexString(), data.SHA256HexString())
+		if err != nil {
+			return err
+		}
+
+		info, err = t.ObjectLayer.PutObjectPart(ctx, bucket, object, uploadID, partID, watched, opts)
+		return err
+	})
+
+	if !ok {
+		gr.close()
+		<-done
+
+		return minio.PartInfo{}, err
+	}
+
+	return info, err
+}
+
+func (t *timeoutLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListPartsInfo, error) {
+	var result minio.ListPartsInfo
+
+	ok, err := call(ctx, t.list, path.Join(bucket, object), func(ctx context.Context) (err error) {
+		result, err = t.ObjectLayer.ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, maxParts)
+		return
+	})
+
+	if !ok {
+		return minio.ListPartsInfo{}, err
+	}
+
+	return result, err
+}
+
+func (t *timeoutLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error {
+	_, err := call(ctx, t.delete, path.Join(bucket, object), func(ctx context.Context) error {
+		return t.ObjectLayer.AbortMultipartUpload(ctx, bucket, object, uploadID)
+	})
+
+	return err
+}
+
+func (t *timeoutLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	var oi minio.ObjectInfo
+
+	ok, err := call(ctx, t.put, path.Join(bucket, object), func(ctx context.Context) (err error) {
+		oi, err = t.ObjectLayer.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
+		return
+	})
+
+	if !ok {
+		return minio.ObjectInfo{}, err
+	}
+
+	return oi, err
+}
+
+// guardedWriter rejects writes once closed, close waits for write in progress.
+// Every write kicks watch of the call.
+type guardedWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	kick   func()
+	closed bool
+}
+
+func (g *guardedWriter) Write(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return 0, errWriterClosed
+	}
+
+	g.kick()
+	defer g.kick()
+
+	return g.w.Write(p)
+}
+
+func (g *guardedWriter) close() {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+}
+
+// guardedReader rejects reads once closed, close waits for read in progress.
+// Every read kicks watch of the call.
+type guardedReader struct {
+	mu     sync.Mutex
+	r      io.Reader
+	kick   func()
+	closed bool
+}
+
+func (g *guardedReader) Read(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return 0, errReaderClosed
+	}
+
+	g.kick()
+	defer g.kick()
+
+	return g.r.Read(p)
+}
+
+func (g *guardedReader) close() {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package timeout
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/config"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestTimeoutLayer(t *testing.T) {
+	opts := &config.TimeoutOptions{Get: 20 * time.Millisecond, Delete: time.Second}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Hung call is abandoned",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+				release := make(chan struct{})
+				defer close(release)
+
+				ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					<-release
+					return minio.ObjectInfo{Name: object}, nil
+				}
+
+				_, err := NewTimeoutLayer(ol, opts).GetObjectInfo(context.Background(), "bucket", "object", minio.ObjectOptions{})
+
+				_, ok := err.(minio.OperationTimedOut)
+				assert.Equal(t, true, ok)
+			},
+		},
+		{
+			"Deadline propagated with ctx",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+
+				var hasDeadline bool
+				ol.DeleteObjectFunc = func(ctx context.Context, bucket, object string) error {
+					_, hasDeadline = ctx.Deadline()
+					return nil
+				}
+
+				err := NewTimeoutLayer(ol, opts).DeleteObject(context.Background(), "bucket", "object")
+
+				assert.NoError(t, err)
+				assert.Equal(t, true, hasDeadline)
+			},
+		},
+		{
+			"Abandoned get doesn't write to client",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+				release := make(chan struct{})
+				written := make(chan error)
+
+				ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					<-release
+					_, err := writer.Write([]byte("late"))
+					written <- err
+					return err
+				}
+
+				buf := bytes.NewBuffer(nil)
+				err := NewTimeoutLayer(ol, opts).GetObject(context.Background(), "bucket", "object", 0, 4, buf, "", minio.ObjectOptions{})
+
+				_, ok := err.(minio.OperationTimedOut)
+				assert.Equal(t, true, ok)
+
+				close(release)
+				assert.Equal(t, errWriterClosed, <-written)
+				assert.Equal(t, 0, buf.Len())
+			},
+		},
+		{
+			"Transfer is bounded only while it's idle",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+
+				ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+					for i := 0; i < 5; i++ {
+						time.Sleep(10 * time.Millisecond)
+						if _, err := writer.Write([]byte("data")); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				}
+
+				buf := bytes.NewBuffer(nil)
+				err := NewTimeoutLayer(ol, opts).GetObject(context.Background(), "bucket", "object", 0, 20, buf, "", minio.ObjectOptions{})
+
+				assert.NoError(t, err)
+				assert.Equal(t, 20, buf.Len())
+			},
+		},
+		{
+			"Part upload is abandoned once backend is idle",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+				ol.PutObjectPartFunc = func(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+					<-ctx.Done()
+					return minio.PartInfo{}, ctx.Err()
+				}
+
+				data, err := hash.NewReader(bytes.NewReader([]byte("data")), 4, "", "")
+				assert.NoError(t, err)
+
+				_, err = NewTimeoutLayer(ol, &config.TimeoutOptions{Put: 20 * time.Millisecond}).PutObjectPart(context.Background(), "bucket", "object", "upload", 1, data, minio.ObjectOptions{})
+
+				_, ok := err.(minio.OperationTimedOut)
+				assert.Equal(t, true, ok)
+			},
+		},
+		{
+			"Abandoned put is joined and doesn't read data of client",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+				returned := make(chan struct{})
+
+				var readErr error
+				ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					<-ctx.Done()
+					_, readErr = data.Read(make([]byte, 4))
+					close(returned)
+					return minio.ObjectInfo{}, readErr
+				}
+
+				data, err := hash.NewReader(bytes.NewReader([]byte("data")), 4, "", "")
+				assert.NoError(t, err)
+
+				_, err = NewTimeoutLayer(ol, &config.TimeoutOptions{Put: 20 * time.Millisecond}).PutObject(context.Background(), "bucket", "object", data, nil, minio.ObjectOptions{})
+
+				_, ok := err.(minio.OperationTimedOut)
+				assert.Equal(t, true, ok)
+
+				select {
+				case <-returned:
+				default:
+					t.Fatal("abandoned put wasn't joined")
+				}
+
+				assert.Equal(t, errReaderClosed, readErr)
+			},
+		},
+		{
+			"Cancelled call returns error of ctx",
+			func(t *testing.T) {
+				ol := test.NewProxyObjectLayer()
+				release := make(chan struct{})
+				defer close(release)
+
+				ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					<-release
+					return minio.ObjectInfo{Name: object}, nil
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := NewTimeoutLayer(ol, opts).GetObjectInfo(ctx, "bucket", "object", minio.ObjectOptions{})
+
+				assert.Equal(t, context.Canceled, err)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package progress
+
+import (
+	"context"
+	"io"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/replication"
+)
+
+type transferKey struct{}
+
+// Handler wraps h so every put and copy task is counted as a file. Task which completes without uploading,
+// e.g. because destination already holds the object, is counted as skipped.
+// Bytes are counted if h uploads through object layer wrapped by Layer. Delete tasks aren't counted.
+func (t *Tracker) Handler(h replication.Handler) replication.Handler {
+	return &handler{Handler: h, t: t}
+}
+
+type handler struct {
+	replication.Handler
+	t *Tracker
+}
+
+func (h *handler) Handle(ctx context.Context, task replication.Task) error {
+	if task.Operation == replication.DELETE {
+		return h.Handler.Handle(ctx, task)
+	}
+
+	tr := h.t.Begin(task.Bucket+"/"+task.Object, 0)
+
+	err := h.Handler.Handle(context.WithValue(ctx, transferKey{}, tr), task)
+	if err == nil && !tr.isUploaded() {
+		tr.Skip()
+		return nil
+	}
+
+	tr.End(err)
+
+	return err
+}
+
+// Layer wraps object layer so bytes of uploaded and copied objects are counted.
+// Upload of task handled by Handler is counted as part of the task, any other upload is counted as a file.
+func (t *Tracker) Layer(ol minio.ObjectLayer) minio.ObjectLayer {
+	return &layer{ObjectLayer: ol, t: t}
+}
+
+type layer struct {
+	minio.ObjectLayer
+	t *Tracker
+}
+
+func (l *layer) PutObject(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	tr, end := l.transfer(ctx, bucket, object, data.Size())
+
+	counted, err := hash.NewReader(&reader{r: data, tr: tr}, data.Size(), data.MD5HexString(), data.SHA256HexString())
+	if err != nil {
+		end(err)
+		return minio.ObjectInfo{}, err
+	}
+
+	oi, err := l.ObjectLayer.PutObject(ctx, bucket, object, counted, metadata, opts)
+	end(err)
+
+	return oi, err
+}
+
+// CopyObject counts size of source once copied, copy made by backend doesn't report its progress.
+func (l *layer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	tr, end := l.transfer(ctx, destBucket, destObject, srcInfo.Size)
+
+	oi, err := l.ObjectLayer.CopyObject(ctx, srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, dstOpts)
+	if err == nil {
+		tr.Add(srcInfo.Size)
+	}
+
+	end(err)
+
+	return oi, err
+}
+
+// transfer returns transfer of task ctx belongs to, or begins new one ended by returned function.
+func (l *layer) transfer(ctx context.Context, bucket, object string, size int64) (*Transfer, func(error)) {
+	if tr, ok := ctx.Value(transferKey{}).(*Transfer); ok {
+		tr.upload(size)
+		return tr, func(error) {}
+	}
+
+	tr := l.t.Begin(bucket+"/"+object, size)
+	tr.upload(size)
+
+	return tr, tr.End
+}
+
+type reader struct {
+	r  io.Reader
+	tr *Transfer
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.tr.Add(int64(n))
+	}
+
+	return n, err
+}
@@ -0,0 +1,266 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// renderEvery is interval between redraws of progress line.
+const renderEvery = 200 * time.Millisecond
+
+// Summary describes finished transfers.
+type Summary struct {
+	Copied   int           `json:"copied"`
+	Skipped  int           `json:"skipped"`
+	Failed   int           `json:"failed"`
+	Bytes    int64         `json:"bytes"`
+	Duration time.Duration `json:"duration"`
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("copied %d, skipped %d, failed %d, %s in %s (%s/s)",
+		s.Copied, s.Skipped, s.Failed, formatBytes(s.Bytes), s.Duration.Round(time.Millisecond), formatBytes(rate(s.Bytes, s.Duration)))
+}
+
+// Tracker counts files and bytes transferred and renders progress of the current file
+// and of all files as single line redrawn in place.
+type Tracker struct {
+	out io.Writer
+	now func() time.Time
+
+	mu      sync.Mutex
+	started time.Time
+	total   int64
+	summary Summary
+	current *Transfer
+	width   int
+
+	stop, stopped chan struct{}
+}
+
+// Creates new Tracker rendering progress to out, nil out only counts transfers.
+func NewTracker(out io.Writer) *Tracker {
+	return &Tracker{out: out, now: time.Now}
+}
+
+// SetTotal sets amount of bytes expected to be transferred, so ETA of all files is rendered.
+func (t *Tracker) SetTotal(bytes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.total = bytes
+}
+
+// Start starts measuring duration and rendering progress.
+func (t *Tracker) Start() {
+	t.mu.Lock()
+	t.started = t.now()
+	t.mu.Unlock()
+
+	if t.out == nil {
+		return
+	}
+
+	t.stop, t.stopped = make(chan struct{}), make(chan struct{})
+
+	go func() {
+		defer close(t.stopped)
+
+		ticker := time.NewTicker(renderEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+				t.render()
+			}
+		}
+	}()
+}
+
+// Stop stops rendering, clears progress line and returns summary of transfers.
+func (t *Tracker) Stop() Summary {
+	if t.stop != nil {
+		close(t.stop)
+		<-t.stopped
+		t.stop = nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.out != nil && t.width > 0 {
+		fmt.Fprintf(t.out, "\r%s\r", strings.Repeat(" ", t.width))
+		t.width = 0
+	}
+
+	summary := t.summary
+	if !t.started.IsZero() {
+		summary.Duration = t.now().Sub(t.started)
+	}
+
+	return summary
+}
+
+// Begin begins transfer of file of size bytes, 0 if size is not known yet.
+func (t *Tracker) Begin(name string, size int64) *Transfer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tr := &Transfer{t: t, name: name, size: size, started: t.now()}
+	t.current = tr
+
+	return tr
+}
+
+// render redraws progress line.
+func (t *Tracker) render() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	line := t.line(t.now())
+
+	padding := ""
+	if len(line) < t.width {
+		padding = strings.Repeat(" ", t.width-len(line))
+	}
+
+	fmt.Fprintf(t.out, "\r%s%s", line, padding)
+	t.width = len(line)
+}
+
+// line formats progress at now, e.g.
+// "bucket/a 1.0 MiB/4.0 MiB 25% 512.0 KiB/s ETA 6s | 2 files, 9.0 MiB, 1.5 MiB/s, 6s".
+// Caller holds the lock.
+func (t *Tracker) line(now time.Time) string {
+	var parts []string
+
+	if tr := t.current; tr != nil {
+		parts = append(parts, tr.name, progress(tr.bytes, tr.size, now.Sub(tr.started)))
+		parts = append(parts, "|")
+	}
+
+	elapsed := now.Sub(t.started)
+	files := t.summary.Copied + t.summary.Skipped + t.summary.Failed
+
+	parts = append(parts, fmt.Sprintf("%d files,", files), progress(t.summary.Bytes, t.total, elapsed)+",",
+		elapsed.Round(time.Second).String())
+
+	return strings.Join(parts, " ")
+}
+
+// progress formats bytes of total transferred in elapsed time, with percentage and ETA if total is known.
+func progress(bytes, total int64, elapsed time.Duration) string {
+	r := rate(bytes, elapsed)
+
+	if total <= 0 {
+		return fmt.Sprintf("%s %s/s", formatBytes(bytes), formatBytes(r))
+	}
+
+	eta := "-"
+	if r > 0 && bytes <= total {
+		eta = time.Duration(float64(total-bytes) / float64(r) * float64(time.Second)).Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("%s/%s %d%% %s/s ETA %s", formatBytes(bytes), formatBytes(total), bytes*100/total, formatBytes(r), eta)
+}
+
+// Transfer is transfer of single file.
+type Transfer struct {
+	t       *Tracker
+	name    string
+	size    int64
+	bytes   int64
+	started time.Time
+
+	// uploaded is set once data of file is sent
+	uploaded bool
+}
+
+// Add counts n transferred bytes.
+func (tr *Transfer) Add(n int64) {
+	tr.t.mu.Lock()
+	defer tr.t.mu.Unlock()
+
+	tr.bytes += n
+	tr.t.summary.Bytes += n
+}
+
+// upload marks that data of file of size bytes is being sent.
+func (tr *Transfer) upload(size int64) {
+	tr.t.mu.Lock()
+	defer tr.t.mu.Unlock()
+
+	tr.uploaded = true
+	if size > 0 {
+		tr.size = size
+	}
+}
+
+func (tr *Transfer) isUploaded() bool {
+	tr.t.mu.Lock()
+	defer tr.t.mu.Unlock()
+
+	return tr.uploaded
+}
+
+// End counts file as copied, or failed if err is set.
+func (tr *Transfer) End(err error) {
+	tr.finish(func(s *Summary) {
+		if err != nil {
+			s.Failed++
+		} else {
+			s.Copied++
+		}
+	})
+}
+
+// Skip counts file as skipped, e.g. when destination already holds it.
+func (tr *Transfer) Skip() {
+	tr.finish(func(s *Summary) { s.Skipped++ })
+}
+
+func (tr *Transfer) finish(count func(s *Summary)) {
+	tr.t.mu.Lock()
+	defer tr.t.mu.Unlock()
+
+	count(&tr.t.summary)
+
+	if tr.t.current == tr {
+		tr.t.current = nil
+	}
+}
+
+// rate returns bytes per second.
+func rate(bytes int64, elapsed time.Duration) int64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return int64(float64(bytes) / elapsed.Seconds())
+}
+
+// formatBytes formats bytes with binary unit, e.g. 1.5 MiB.
+func formatBytes(bytes int64) string {
+	const unit = 1024
+
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package progress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/objlayer/mirroring"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker := NewTracker(nil)
+	tracker.now = func() time.Time { return now }
+	tracker.SetTotal(4 << 20)
+	tracker.Start()
+
+	tr := tracker.Begin("bucket/a", 2<<20)
+	now = now.Add(2 * time.Second)
+	tr.Add(1 << 20)
+
+	assert.Equal(t, "bucket/a 1.0 MiB/2.0 MiB 50% 512.0 KiB/s ETA 2s | 0 files, 1.0 MiB/4.0 MiB 25% 512.0 KiB/s ETA 6s, 2s",
+		tracker.line(now))
+
+	tr.Add(1 << 20)
+	tr.End(nil)
+	tracker.Begin("bucket/b", 0).Skip()
+	tracker.Begin("bucket/c", 0).End(errors.New("access denied"))
+
+	assert.Equal(t, "3 files, 2.0 MiB/4.0 MiB 50% 1.0 MiB/s ETA 2s, 2s", tracker.line(now))
+
+	summary := tracker.Stop()
+	assert.Equal(t, Summary{Copied: 1, Skipped: 1, Failed: 1, Bytes: 2 << 20, Duration: 2 * time.Second}, summary)
+	assert.Equal(t, "copied 1, skipped 1, failed 1, 2.0 MiB in 2s (1.0 MiB/s)", summary.String())
+}
+
+func TestRender(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	tracker := NewTracker(out)
+	tracker.Start()
+	tracker.Begin("bucket/a", 0).Add(10)
+
+	time.Sleep(2 * renderEvery)
+	tracker.Stop()
+
+	assert.Contains(t, out.String(), "\rbucket/a 10 B ")
+	assert.True(t, bytes.HasSuffix(out.Bytes(), []byte(" \r")), "progress line isn't cleared: %q", out.String())
+}
+
+func TestHandler(t *testing.T) {
+	content := map[string]string{"a": "123", "c": "12345"}
+
+	prime := test.NewProxyObjectLayer()
+	prime.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		c, ok := content[object]
+		if !ok {
+			return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+		}
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(c))}, nil
+	}
+	prime.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		_, err := io.WriteString(writer, content[object])
+		return err
+	}
+
+	alter := test.NewProxyObjectLayer()
+	alter.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		if _, err := ioutil.ReadAll(data); err != nil {
+			return minio.ObjectInfo{}, err
+		}
+
+		if object == "c" {
+			return minio.ObjectInfo{}, errors.New("access denied")
+		}
+
+		return minio.ObjectInfo{}, nil
+	}
+
+	tracker := NewTracker(nil)
+	tracker.Start()
+
+	handler := tracker.Handler(mirroring.NewReplicationHandler(prime, tracker.Layer(alter)))
+
+	// a is copied, b was deleted from prime in the meantime, c fails once uploaded
+	assert.NoError(t, handler.Handle(context.Background(), replication.NewPutTask("bucket", "a")))
+	assert.NoError(t, handler.Handle(context.Background(), replication.NewPutTask("bucket", "b")))
+	assert.EqualError(t, handler.Handle(context.Background(), replication.NewPutTask("bucket", "c")), "access denied")
+
+	summary := tracker.Stop()
+	assert.Equal(t, 1, summary.Copied)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, int64(8), summary.Bytes)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0 B", formatBytes(0))
+	assert.Equal(t, "1023 B", formatBytes(1023))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "1.0 GiB", formatBytes(1<<30))
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket refilled with rate tokens per second up to burst tokens.
+// Nil Limiter is unlimited.
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	now func() time.Time
+}
+
+// Creates new Limiter, returns nil (unlimited) for non-positive rate.
+// Non-positive burst is replaced with one second worth of tokens.
+func NewLimiter(rate float64, burst int) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	b := burstOf(rate, burst)
+
+	return &Limiter{rate: rate, burst: b, tokens: b, last: time.Now(), now: time.Now}
+}
+
+// NewAdjustableLimiter creates Limiter whose rate can be changed by SetRate.
+// Unlike NewLimiter it never returns nil, non-positive rate doesn't limit requests until rate is set.
+func NewAdjustableLimiter(rate float64, burst int) *Limiter {
+	l := &Limiter{last: time.Now(), now: time.Now}
+	l.SetRate(rate, burst)
+
+	return l
+}
+
+// SetRate replaces rate and burst of l, non-positive rate stops limiting.
+// Tokens refilled so far are kept up to the new burst, waiting callers keep their delays.
+func (l *Limiter) SetRate(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	if l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+	} else {
+		// unlimited bucket starts full
+		l.tokens = burstOf(rate, burst)
+	}
+
+	l.last = now
+	l.rate = rate
+	l.burst = burstOf(rate, burst)
+
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// burstOf returns burst of limiter, non-positive burst is replaced with one second worth of tokens.
+func burstOf(rate float64, burst int) float64 {
+	b := float64(burst)
+	if b <= 0 {
+		b = rate
+	}
+
+	if b < 1 {
+		b = 1
+	}
+
+	return b
+}
+
+// Wait blocks until single token is available or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n tokens are available or ctx is done.
+// n may exceed burst, in which case bucket goes into debt repaid by subsequent callers.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	delay := l.reserve(float64(n))
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(float64(n))
+		return ctx.Err()
+	}
+}
+
+// reserve takes n tokens and returns time to wait until they are refilled.
+func (l *Limiter) reserve(n float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+
+	now := l.now()
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+
+	l.last = now
+	l.tokens -= n
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns tokens of abandoned reservation.
+func (l *Limiter) cancel(n float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens += n
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+
+	l := NewLimiter(10, 2)
+	l.last = now
+	l.now = func() time.Time { return now }
+
+	// burst is available immediately
+	assert.Equal(t, time.Duration(0), l.reserve(1))
+	assert.Equal(t, time.Duration(0), l.reserve(1))
+
+	// bucket is empty, next token is refilled in 1/rate
+	assert.Equal(t, 100*time.Millisecond, l.reserve(1))
+
+	// debt is repaid before new tokens are available
+	now = now.Add(100 * time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, l.reserve(1))
+
+	// refill is capped at burst
+	now = now.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), l.reserve(2))
+	assert.Equal(t, 100*time.Millisecond, l.reserve(1))
+}
+
+func TestLimiterWait(t *testing.T) {
+	var unlimited *Limiter
+	assert.NoError(t, unlimited.Wait(context.Background()))
+	assert.Nil(t, NewLimiter(0, 10))
+
+	l := NewLimiter(1, 1)
+	assert.NoError(t, l.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, l.Wait(ctx))
+}
+
+func TestSetRate(t *testing.T) {
+	now := time.Now()
+
+	l := NewAdjustableLimiter(0, 0)
+	l.last = now
+	l.now = func() time.Time { return now }
+
+	// zero rate doesn't limit
+	assert.Equal(t, time.Duration(0), l.reserve(100))
+
+	// limited bucket starts full
+	l.SetRate(10, 2)
+	assert.Equal(t, time.Duration(0), l.reserve(2))
+	assert.Equal(t, 100*time.Millisecond, l.reserve(1))
+
+	// debt is kept, but repaid with new rate
+	l.SetRate(20, 2)
+	assert.Equal(t, 100*time.Millisecond, l.reserve(1))
+
+	// refilled tokens are capped at new burst
+	now = now.Add(time.Hour)
+	l.SetRate(20, 1)
+	assert.Equal(t, time.Duration(0), l.reserve(1))
+	assert.Equal(t, 50*time.Millisecond, l.reserve(1))
+
+	l.SetRate(0, 0)
+	assert.Equal(t, time.Duration(0), l.reserve(100))
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader(t *testing.T) {
+	data := make([]byte, 3*readChunk)
+
+	// burst covers first chunk, remaining two are read at 1 chunk per 50ms
+	l := NewLimiter(readChunk*20, readChunk)
+
+	start := time.Now()
+	read, err := ioutil.ReadAll(NewReader(context.Background(), bytes.NewReader(data), l))
+	elapsed := time.Since(start)
+
+	assert.NoError(t, err)
+	assert.Equal(t, data, read)
+	assert.True(t, elapsed >= 90*time.Millisecond, "elapsed %s", elapsed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = ioutil.ReadAll(NewReader(ctx, bytes.NewReader(data), NewLimiter(1, 1)))
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package reload
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Watcher triggers reload when the process receives SIGHUP, or when any of watched files is modified.
+type Watcher struct {
+	files    []string
+	interval time.Duration
+
+	// signals receives SIGHUP, it's replaced in tests
+	signals chan os.Signal
+}
+
+// NewWatcher creates watcher checking modification of files every interval.
+// Non-positive interval disables checking of files, so only SIGHUP triggers reload.
+func NewWatcher(interval time.Duration, files ...string) *Watcher {
+	return &Watcher{files: files, interval: interval}
+}
+
+// fileState is modification time and size of a file, zero if file doesn't exist.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (w *Watcher) states() []fileState {
+	states := make([]fileState, len(w.files))
+
+	for i, f := range w.files {
+		if fi, err := os.Stat(f); err == nil {
+			states[i] = fileState{fi.ModTime(), fi.Size()}
+		}
+	}
+
+	return states
+}
+
+// Run calls reload on every trigger until ctx is done.
+func (w *Watcher) Run(ctx context.Context, reload func()) {
+	signals := w.signals
+	if signals == nil {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+		defer signal.Stop(signals)
+	}
+
+	var tick <-chan time.Time
+
+	if w.interval > 0 && len(w.files) > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	last := w.states()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			last = w.states()
+			reload()
+		case <-tick:
+			current := w.states()
+			if !equal(last, current) {
+				last = current
+				reload()
+			}
+		}
+	}
+}
+
+func equal(a, b []fileState) bool {
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package reload
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ditto-reload")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{}"), 0600))
+
+	w := NewWatcher(10*time.Millisecond, path)
+	w.signals = make(chan os.Signal, 1)
+
+	reloads := make(chan struct{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go w.Run(ctx, func() { reloads <- struct{}{} })
+
+	expectReload := func(t *testing.T) {
+		select {
+		case <-reloads:
+		case <-time.After(5 * time.Second):
+			t.Fatal("reload wasn't triggered")
+		}
+	}
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Signal triggers reload",
+			func(t *testing.T) {
+				w.signals <- syscall.SIGHUP
+				expectReload(t)
+			},
+		},
+		{
+			"Modified file triggers reload",
+			func(t *testing.T) {
+				assert.NoError(t, ioutil.WriteFile(path, []byte(`{"Log": {}}`), 0600))
+				expectReload(t)
+			},
+		},
+		{
+			"Unmodified file doesn't trigger reload",
+			func(t *testing.T) {
+				select {
+				case <-reloads:
+					t.Fatal("unexpected reload")
+				case <-time.After(50 * time.Millisecond):
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package replication
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+)
+
+// Status is a state of the queue reported by admin endpoint.
+type Status struct {
+	Paused bool `json:"paused"`
+	Queued int  `json:"queued"`
+}
+
+// ServeHTTP reports queue status on GET, POST to <pattern>/pause and <pattern>/resume
+// pauses and resumes processing of tasks.
+func (q *Queue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		switch path.Base(r.URL.Path) {
+		case "pause":
+			q.Pause()
+		case "resume":
+			q.Resume()
+		default:
+			http.NotFound(w, r)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Status{Paused: q.IsPaused(), Queued: q.Len()})
+}
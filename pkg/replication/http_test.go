@@ -0,0 +1,47 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueServeHTTP(t *testing.T) {
+	q := NewQueue(handlerFunc(func(ctx context.Context, task Task) error { return nil }), nil, 1, 10)
+	defer q.Close()
+
+	serve := func(method, target string) (int, Status) {
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+
+		var status Status
+		if rec.Code == http.StatusOK {
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+		}
+
+		return rec.Code, status
+	}
+
+	code, status := serve(http.MethodPost, "/replication/pause")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, status.Paused)
+	assert.Equal(t, true, q.IsPaused())
+
+	code, status = serve(http.MethodGet, "/replication/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, status.Paused)
+
+	code, status = serve(http.MethodPost, "/replication/resume")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, status.Paused)
+
+	code, _ = serve(http.MethodPost, "/replication/unknown")
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,401 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	l "storj.io/ditto/pkg/logger"
+)
+
+const (
+	DefaultWorkers     = 4
+	DefaultQueueDepth  = 1000
+	DefaultConcurrency = 1
+	DefaultMaxRetries  = 3
+
+	// DefaultRetryBackoff is backoff before the first retry, it doubles with every attempt up to DefaultMaxRetryBackoff.
+	DefaultRetryBackoff    = 100 * time.Millisecond
+	DefaultMaxRetryBackoff = 10 * time.Second
+)
+
+var ErrQueueFull = errors.New("replication queue is full")
+var ErrQueueClosed = errors.New("replication queue is closed")
+var ErrQueuePaused = errors.New("replication queue is closed while paused")
+
+// Handler executes replication tasks against alter.
+type Handler interface {
+	Handle(ctx context.Context, task Task) error
+}
+
+// Queue is a background replication queue.
+// Tasks are processed by a fixed amount of workers, each running up to concurrency tasks at once.
+// Failed tasks are retried up to maxRetries times, unless their error isn't retryable.
+// Retries are delayed by exponential backoff with full jitter, so failing alter isn't hammered by all workers at once.
+type Queue struct {
+	handler    Handler
+	logger     l.Logger
+	tasks      chan queued
+	maxRetries int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+	onDrop func(task Task, err error)
+	onDone []func(task Task, lag time.Duration, err error)
+	// isRetryable decides which errors are worth retrying, nil means every error
+	isRetryable func(err error) bool
+	// backoff before the first retry and its upper bound
+	backoff, maxBackoff time.Duration
+	// lag of the last finished task in nanoseconds
+	lag int64
+
+	// resumed is closed while queue isn't paused, closing is closed by Close.
+	paused  bool
+	resumed chan struct{}
+	closing chan struct{}
+}
+
+// queued is a task waiting in the queue since enqueued.
+type queued struct {
+	task     Task
+	enqueued time.Time
+}
+
+// Creates new Queue and starts its workers, each worker processes single task at once.
+func NewQueue(handler Handler, logger l.Logger, workers, depth int) *Queue {
+	return NewConcurrentQueue(handler, logger, workers, DefaultConcurrency, depth)
+}
+
+// Creates new Queue and starts its workers, each worker processes up to concurrency tasks at once.
+// Zero values are replaced with defaults.
+func NewConcurrentQueue(handler Handler, logger l.Logger, workers, concurrency, depth int) *Queue {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	if depth <= 0 {
+		depth = DefaultQueueDepth
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	resumed := make(chan struct{})
+	close(resumed)
+
+	q := &Queue{
+		handler:    handler,
+		logger:     logger,
+		tasks:      make(chan queued, depth),
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultRetryBackoff,
+		maxBackoff: DefaultMaxRetryBackoff,
+		ctx:        ctx,
+		cancel:     cancel,
+		resumed:    resumed,
+		closing:    make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work(concurrency)
+	}
+
+	return q
+}
+
+// Enqueue adds task to the queue without blocking.
+// Returns ErrQueueFull if queue depth is exceeded.
+func (q *Queue) Enqueue(task Task) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.tasks <- queued{task, time.Now()}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// SetHandler replaces handler of tasks, e.g. once it can be wrapped by layer created after the queue.
+// Tasks already being handled finish with previous handler.
+func (q *Queue) SetHandler(h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handler = h
+}
+
+// SetDropHandler sets function called with tasks that failed all their attempts.
+func (q *Queue) SetDropHandler(f func(task Task, err error)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.onDrop = f
+}
+
+// SetRetryable sets function deciding whether task failed with err should be retried,
+// tasks failed with other errors are dropped immediately. By default every error is retried.
+func (q *Queue) SetRetryable(f func(err error) bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.isRetryable = f
+}
+
+// SetBackoff sets backoff before the first retry, which doubles with every attempt up to max.
+// Zero backoff retries immediately.
+func (q *Queue) SetBackoff(backoff, max time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.backoff, q.maxBackoff = backoff, max
+}
+
+// AddObserver adds function called with every task once it's replicated or dropped.
+// lag is time from enqueueing task until its last attempt finished, err is nil for replicated tasks.
+func (q *Queue) AddObserver(f func(task Task, lag time.Duration, err error)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.onDone = append(q.onDone, f)
+}
+
+// Lag returns time the most recently finished task spent in the queue, including its attempts.
+func (q *Queue) Lag() time.Duration {
+	return time.Duration(atomic.LoadInt64(&q.lag))
+}
+
+// Len returns amount of tasks waiting in the queue.
+func (q *Queue) Len() int {
+	return len(q.tasks)
+}
+
+// Pause stops processing of tasks, tasks are still accepted until queue is full.
+func (q *Queue) Pause() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.paused {
+		q.paused = true
+		q.resumed = make(chan struct{})
+	}
+}
+
+// Resume continues processing of tasks.
+func (q *Queue) Resume() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.paused {
+		q.paused = false
+		close(q.resumed)
+	}
+}
+
+// IsPaused returns true if processing of tasks is paused.
+func (q *Queue) IsPaused() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.paused
+}
+
+// Close stops accepting new tasks and waits until all queued tasks are processed.
+// If queue is paused, remaining tasks are passed to drop handler with ErrQueuePaused instead.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+
+	q.closed = true
+	close(q.tasks)
+	close(q.closing)
+	q.mu.Unlock()
+
+	q.wg.Wait()
+	q.cancel()
+}
+
+// Stop stops processing of tasks without waiting until they are processed. Tasks in flight are canceled,
+// they and remaining tasks are passed to drop handler, e.g. to be journaled.
+func (q *Queue) Stop() {
+	q.Pause()
+	q.cancel()
+	q.Close()
+}
+
+func (q *Queue) work(concurrency int) {
+	defer q.wg.Done()
+
+	if concurrency == 1 {
+		for item := range q.tasks {
+			q.process(item)
+		}
+
+		return
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for item := range q.tasks {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(item queued) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			q.process(item)
+		}(item)
+	}
+
+	wg.Wait()
+}
+
+// waitResumed blocks while queue is paused.
+// Returns false if queue was closed while paused.
+func (q *Queue) waitResumed() bool {
+	q.mu.RLock()
+	resumed := q.resumed
+	q.mu.RUnlock()
+
+	select {
+	case <-resumed:
+		return true
+	case <-q.closing:
+		return !q.IsPaused()
+	case <-q.ctx.Done():
+		return false
+	}
+}
+
+func (q *Queue) process(item queued) {
+	task := item.task
+
+	if !q.waitResumed() {
+		q.logE(fmt.Errorf("%s is not replicated: %s", task, ErrQueuePaused))
+		q.drop(task, ErrQueuePaused)
+		q.done(item, task, ErrQueuePaused)
+		return
+	}
+
+	q.mu.RLock()
+	handler := q.handler
+	q.mu.RUnlock()
+
+	for {
+		task.Attempts++
+
+		err := handler.Handle(q.ctx, task)
+		if err == nil {
+			q.done(item, task, nil)
+			return
+		}
+
+		q.logE(fmt.Errorf("replication of %s failed, attempt %d: %s", task, task.Attempts, err))
+
+		if task.Attempts >= q.maxRetries || !q.retryable(err) || !q.wait(task.Attempts) {
+			q.drop(task, err)
+			q.done(item, task, err)
+			return
+		}
+	}
+}
+
+// wait sleeps for random duration up to backoff of attempt which failed.
+// Returns false if queue is cancelled meanwhile.
+func (q *Queue) wait(attempt int) bool {
+	backoff := q.backoffOf(attempt)
+	if backoff <= 0 {
+		return q.ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoff))))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-q.ctx.Done():
+		return false
+	}
+}
+
+// backoffOf returns backoff after attempt failed, which doubles with every attempt up to max backoff.
+func (q *Queue) backoffOf(attempt int) time.Duration {
+	q.mu.RLock()
+	backoff, max := q.backoff, q.maxBackoff
+	q.mu.RUnlock()
+
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+
+	if max > 0 && backoff > max {
+		backoff = max
+	}
+
+	return backoff
+}
+
+func (q *Queue) retryable(err error) bool {
+	q.mu.RLock()
+	isRetryable := q.isRetryable
+	q.mu.RUnlock()
+
+	return isRetryable == nil || isRetryable(err)
+}
+
+func (q *Queue) done(item queued, task Task, err error) {
+	lag := time.Since(item.enqueued)
+	atomic.StoreInt64(&q.lag, int64(lag))
+
+	q.mu.RLock()
+	onDone := q.onDone
+	q.mu.RUnlock()
+
+	for _, f := range onDone {
+		f(task, lag, err)
+	}
+}
+
+func (q *Queue) drop(task Task, err error) {
+	q.mu.RLock()
+	onDrop := q.onDrop
+	q.mu.RUnlock()
+
+	if onDrop != nil {
+		onDrop(task, err)
+	}
+}
+
+func (q *Queue) logE(err error) {
+	if q.logger != nil {
+		q.logger.LogE(err)
+	}
+}
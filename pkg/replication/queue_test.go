@@ -0,0 +1,334 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package replication
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+type handlerFunc func(ctx context.Context, task Task) error
+
+func (f handlerFunc) Handle(ctx context.Context, task Task) error {
+	return f(ctx, task)
+}
+
+func TestQueue(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"All tasks processed on close",
+			func(t *testing.T) {
+				mu := sync.Mutex{}
+				var handled []Task
+
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					mu.Lock()
+					handled = append(handled, task)
+					mu.Unlock()
+					return nil
+				})
+
+				q := NewQueue(h, nil, 2, 10)
+
+				for i := 0; i < 5; i++ {
+					assert.NoError(t, q.Enqueue(NewPutTask("bucket", "object")))
+				}
+
+				q.Close()
+
+				assert.Equal(t, 5, len(handled))
+				assert.Equal(t, ErrQueueClosed, q.Enqueue(NewPutTask("bucket", "object")))
+			},
+		},
+		{
+			"Failed task retried",
+			func(t *testing.T) {
+				lg := &test.MockLogger{}
+				attempts := 0
+
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					attempts++
+					return errors.New("alter failed")
+				})
+
+				q := NewQueue(h, lg, 1, 10)
+				assert.NoError(t, q.Enqueue(NewDeleteTask("bucket", "object")))
+				q.Close()
+
+				assert.Equal(t, DefaultMaxRetries, attempts)
+				assert.Equal(t, DefaultMaxRetries, lg.LogECount())
+			},
+		},
+		{
+			"Task failed with error which isn't retryable dropped immediately",
+			func(t *testing.T) {
+				permanent := errors.New("access denied")
+				attempts := 0
+
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					attempts++
+					return permanent
+				})
+
+				var dropped []error
+
+				q := NewQueue(h, nil, 1, 10)
+				q.SetRetryable(func(err error) bool { return err != permanent })
+				q.SetDropHandler(func(task Task, err error) {
+					dropped = append(dropped, err)
+				})
+
+				assert.NoError(t, q.Enqueue(NewPutTask("bucket", "object")))
+				q.Close()
+
+				assert.Equal(t, 1, attempts)
+				assert.Equal(t, []error{permanent}, dropped)
+			},
+		},
+		{
+			"Observer receives results and lag",
+			func(t *testing.T) {
+				mu := sync.Mutex{}
+				results := map[Operation]error{}
+
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					if task.Operation == DELETE {
+						return errors.New("alter failed")
+					}
+					return nil
+				})
+
+				q := NewQueue(h, nil, 1, 10)
+				q.AddObserver(func(task Task, lag time.Duration, err error) {
+					mu.Lock()
+					results[task.Operation] = err
+					mu.Unlock()
+
+					assert.True(t, lag > 0)
+				})
+
+				assert.NoError(t, q.Enqueue(NewPutTask("bucket", "object")))
+				assert.NoError(t, q.Enqueue(NewDeleteTask("bucket", "object")))
+				q.Close()
+
+				assert.Equal(t, 2, len(results))
+				assert.NoError(t, results[PUT])
+				assert.Error(t, results[DELETE])
+			},
+		},
+		{
+			"Queue full",
+			func(t *testing.T) {
+				block := make(chan struct{})
+
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					<-block
+					return nil
+				})
+
+				q := NewQueue(h, nil, 1, 1)
+
+				// first task is taken by worker, second one fills the queue
+				assert.NoError(t, q.Enqueue(NewPutTask("bucket", "1")))
+
+				var err error
+				for i := 0; i < 3 && err == nil; i++ {
+					err = q.Enqueue(NewPutTask("bucket", "2"))
+				}
+
+				assert.Equal(t, ErrQueueFull, err)
+
+				close(block)
+				q.Close()
+			},
+		},
+		{
+			"Worker runs tasks concurrently",
+			func(t *testing.T) {
+				started := make(chan struct{}, 3)
+				block := make(chan struct{})
+
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					started <- struct{}{}
+					<-block
+					return nil
+				})
+
+				q := NewConcurrentQueue(h, nil, 1, 3, 10)
+
+				for i := 0; i < 3; i++ {
+					assert.NoError(t, q.Enqueue(NewPutTask("bucket", "object")))
+				}
+
+				// all tasks are started by single worker before any of them finishes
+				for i := 0; i < 3; i++ {
+					<-started
+				}
+
+				close(block)
+				q.Close()
+			},
+		},
+		{
+			"Paused queue holds tasks until resumed",
+			func(t *testing.T) {
+				handled := make(chan Task, 1)
+
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					handled <- task
+					return nil
+				})
+
+				q := NewQueue(h, nil, 1, 10)
+				q.Pause()
+				assert.Equal(t, true, q.IsPaused())
+
+				assert.NoError(t, q.Enqueue(NewPutTask("bucket", "object")))
+
+				select {
+				case <-handled:
+					t.Fatal("task processed while paused")
+				case <-time.After(50 * time.Millisecond):
+				}
+
+				q.Resume()
+				assert.Equal(t, "object", (<-handled).Object)
+				q.Close()
+			},
+		},
+		{
+			"Tasks dropped when closed while paused",
+			func(t *testing.T) {
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					t.Fatal("task processed while paused")
+					return nil
+				})
+
+				var dropped []error
+
+				q := NewQueue(h, nil, 1, 10)
+				q.SetDropHandler(func(task Task, err error) {
+					dropped = append(dropped, err)
+				})
+
+				q.Pause()
+
+				for i := 0; i < 2; i++ {
+					assert.NoError(t, q.Enqueue(NewPutTask("bucket", "object")))
+				}
+
+				q.Close()
+				assert.Equal(t, []error{ErrQueuePaused, ErrQueuePaused}, dropped)
+			},
+		},
+		{
+			"Tasks in flight and waiting dropped when stopped",
+			func(t *testing.T) {
+				started := make(chan struct{})
+
+				// handler blocks like backfill waiting for prime to recover
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					close(started)
+					<-ctx.Done()
+					return ctx.Err()
+				})
+
+				mu := sync.Mutex{}
+				var dropped []string
+
+				q := NewQueue(h, nil, 1, 10)
+				q.SetDropHandler(func(task Task, err error) {
+					mu.Lock()
+					dropped = append(dropped, task.Object)
+					mu.Unlock()
+				})
+
+				assert.NoError(t, q.Enqueue(NewPutTask("bucket", "first")))
+				<-started
+				assert.NoError(t, q.Enqueue(NewPutTask("bucket", "second")))
+
+				q.Stop()
+				assert.Equal(t, []string{"first", "second"}, dropped)
+			},
+		},
+		{
+			"SetHandler replaces handler",
+			func(t *testing.T) {
+				handled := make(chan string, 1)
+
+				q := NewQueue(handlerFunc(func(ctx context.Context, task Task) error {
+					return errors.New("replaced handler called")
+				}), nil, 1, 10)
+				defer q.Close()
+
+				q.SetHandler(handlerFunc(func(ctx context.Context, task Task) error {
+					handled <- task.Object
+					return nil
+				}))
+
+				assert.NoError(t, q.Enqueue(NewPutTask("bucket", "object")))
+				assert.Equal(t, "object", <-handled)
+			},
+		},
+		{
+			"Backoff doubles up to max",
+			func(t *testing.T) {
+				q := NewQueue(handlerFunc(func(ctx context.Context, task Task) error { return nil }), nil, 1, 10)
+				defer q.Close()
+
+				assert.Equal(t, DefaultRetryBackoff, q.backoffOf(1))
+				assert.Equal(t, 2*DefaultRetryBackoff, q.backoffOf(2))
+				assert.Equal(t, 4*DefaultRetryBackoff, q.backoffOf(3))
+				assert.Equal(t, DefaultMaxRetryBackoff, q.backoffOf(20))
+
+				q.SetBackoff(0, 0)
+				assert.Equal(t, time.Duration(0), q.backoffOf(3))
+			},
+		},
+		{
+			"Backoff interrupted by stop",
+			func(t *testing.T) {
+				failed := make(chan struct{})
+
+				h := handlerFunc(func(ctx context.Context, task Task) error {
+					close(failed)
+					return errors.New("alter is down")
+				})
+
+				dropped := make(chan error, 1)
+
+				q := NewQueue(h, nil, 1, 10)
+				q.SetBackoff(time.Hour, time.Hour)
+				q.SetDropHandler(func(task Task, err error) {
+					dropped <- err
+				})
+
+				assert.NoError(t, q.Enqueue(NewPutTask("bucket", "object")))
+				<-failed
+
+				q.Stop()
+
+				select {
+				case err := <-dropped:
+					assert.EqualError(t, err, "alter is down")
+				case <-time.After(time.Second):
+					t.Fatal("task wasn't dropped once queue stopped")
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package replication
+
+import "fmt"
+
+type Operation string
+
+const (
+	PUT    Operation = "put"
+	DELETE Operation = "delete"
+	COPY   Operation = "copy"
+)
+
+// Task describes single operation that should be replayed on alter.
+// SrcBucket and SrcObject are used only by COPY operation.
+type Task struct {
+	Operation Operation
+	Bucket    string
+	Object    string
+	SrcBucket string
+	SrcObject string
+	Attempts  int
+}
+
+func NewPutTask(bucket, object string) Task {
+	return Task{Operation: PUT, Bucket: bucket, Object: object}
+}
+
+func NewDeleteTask(bucket, object string) Task {
+	return Task{Operation: DELETE, Bucket: bucket, Object: object}
+}
+
+func NewCopyTask(srcBucket, srcObject, bucket, object string) Task {
+	return Task{Operation: COPY, Bucket: bucket, Object: object, SrcBucket: srcBucket, SrcObject: srcObject}
+}
+
+func (t Task) String() string {
+	if t.Operation == COPY {
+		return fmt.Sprintf("%s %s/%s -> %s/%s", t.Operation, t.SrcBucket, t.SrcObject, t.Bucket, t.Object)
+	}
+
+	return fmt.Sprintf("%s %s/%s", t.Operation, t.Bucket, t.Object)
+}
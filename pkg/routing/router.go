@@ -0,0 +1,139 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package routing
+
+import (
+	"sync"
+	"time"
+
+	"storj.io/ditto/pkg/errclass"
+	"storj.io/ditto/pkg/objlayer/monitor"
+)
+
+// Read modes, PrimeMode reads prime first and falls back to alter,
+// AutoMode reads first from backend which has been faster recently,
+// HedgedMode reads GetObject from both backends and serves the one which responds first.
+const (
+	PrimeMode  = "prime"
+	AutoMode   = "auto"
+	HedgedMode = "hedged"
+)
+
+// Backends tracked by Router.
+const (
+	Prime = "prime"
+	Alter = "alter"
+)
+
+const (
+	// smoothing is weight of the newest sample in rolling latency.
+	smoothing = 0.2
+	// failurePenalty is recorded instead of duration of failed call,
+	// so backend failing fast doesn't look faster than healthy one.
+	failurePenalty = 5 * time.Second
+)
+
+// routedOperations are read operations whose latency is tracked.
+var routedOperations = map[string]bool{
+	"GetObject":     true,
+	"ListObjects":   true,
+	"ListObjectsV2": true,
+}
+
+// Router tracks exponentially weighted rolling latency of reads per backend and chooses backend to read from.
+// Once per probeInterval slower backend is chosen to refresh its latency, zero probeInterval disables probing.
+type Router struct {
+	mu            sync.Mutex
+	probeInterval time.Duration
+	latency       map[string]time.Duration
+	lastProbe     time.Time
+
+	// now is overridden by tests
+	now func() time.Time
+}
+
+// NewRouter creates router probing slower backend once per probeInterval.
+func NewRouter(probeInterval time.Duration) *Router {
+	return &Router{
+		probeInterval: probeInterval,
+		latency:       make(map[string]time.Duration),
+		lastProbe:     time.Now(),
+		now:           time.Now,
+	}
+}
+
+// SetProbeInterval replaces interval of probing slower backend, zero disables probing.
+func (r *Router) SetProbeInterval(probeInterval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.probeInterval = probeInterval
+}
+
+// Observe records duration of read from backend. Failed reads are recorded as failurePenalty,
+// errors which are answers rather than failures, e.g. missing object, are recorded as duration.
+func (r *Router) Observe(backend string, d time.Duration, err error) {
+	if err != nil && errclass.IsRetryable(err) {
+		d = failurePenalty
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev, ok := r.latency[backend]
+	if !ok {
+		r.latency[backend] = d
+		return
+	}
+
+	r.latency[backend] = prev + time.Duration(smoothing*float64(d-prev))
+}
+
+// Observer returns monitor hook recording latency of reads from backend.
+func (r *Router) Observer(backend string) func(call monitor.Call) {
+	return func(call monitor.Call) {
+		if routedOperations[call.Operation] {
+			r.Observe(backend, call.Duration, call.Err)
+		}
+	}
+}
+
+// Latency returns rolling latency of backend and false if no read from it was observed yet.
+func (r *Router) Latency(backend string) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.latency[backend]
+
+	return d, ok
+}
+
+// Choose returns backend which should be read first, Prime or Alter.
+// Backend without observed reads is chosen to get its first sample.
+func (r *Router) Choose() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	primeLatency, primeOK := r.latency[Prime]
+	alterLatency, alterOK := r.latency[Alter]
+
+	switch {
+	case !primeOK:
+		return Prime
+	case !alterOK:
+		return Alter
+	}
+
+	faster, slower := Prime, Alter
+	if alterLatency < primeLatency {
+		faster, slower = Alter, Prime
+	}
+
+	if r.probeInterval > 0 && r.now().Sub(r.lastProbe) >= r.probeInterval {
+		r.lastProbe = r.now()
+		return slower
+	}
+
+	return faster
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package routing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/objlayer/monitor"
+)
+
+func TestRouter(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Unobserved backends are chosen first",
+			testFunc: func(t *testing.T) {
+				r := NewRouter(0)
+				assert.Equal(t, Prime, r.Choose())
+
+				r.Observe(Prime, time.Millisecond, nil)
+				assert.Equal(t, Alter, r.Choose())
+			},
+		},
+		{
+			testName: "Faster backend is chosen",
+			testFunc: func(t *testing.T) {
+				r := NewRouter(0)
+				r.Observe(Prime, 100*time.Millisecond, nil)
+				r.Observe(Alter, 10*time.Millisecond, nil)
+
+				assert.Equal(t, Alter, r.Choose())
+				assert.Equal(t, Alter, r.Choose())
+			},
+		},
+		{
+			testName: "Latency is rolling average",
+			testFunc: func(t *testing.T) {
+				r := NewRouter(0)
+				r.Observe(Prime, 100*time.Millisecond, nil)
+				r.Observe(Prime, 200*time.Millisecond, nil)
+
+				d, ok := r.Latency(Prime)
+				assert.True(t, ok)
+				assert.Equal(t, 120*time.Millisecond, d)
+
+				_, ok = r.Latency(Alter)
+				assert.False(t, ok)
+			},
+		},
+		{
+			testName: "Failures are penalized, missing objects are not",
+			testFunc: func(t *testing.T) {
+				r := NewRouter(0)
+				r.Observe(Prime, time.Millisecond, errors.New("connection reset"))
+				r.Observe(Alter, time.Millisecond, minio.ObjectNotFound{})
+
+				d, _ := r.Latency(Prime)
+				assert.Equal(t, failurePenalty, d)
+
+				d, _ = r.Latency(Alter)
+				assert.Equal(t, time.Millisecond, d)
+			},
+		},
+		{
+			testName: "Slower backend is probed once per interval",
+			testFunc: func(t *testing.T) {
+				now := time.Now()
+
+				r := NewRouter(time.Minute)
+				r.now = func() time.Time { return now }
+				r.lastProbe = now
+
+				r.Observe(Prime, 100*time.Millisecond, nil)
+				r.Observe(Alter, 10*time.Millisecond, nil)
+				assert.Equal(t, Alter, r.Choose())
+
+				now = now.Add(time.Minute)
+				assert.Equal(t, Prime, r.Choose())
+				assert.Equal(t, Alter, r.Choose())
+			},
+		},
+		{
+			testName: "Observer records only reads",
+			testFunc: func(t *testing.T) {
+				r := NewRouter(0)
+
+				observe := r.Observer(Alter)
+				observe(monitor.Call{Operation: "PutObject", Duration: time.Second})
+
+				_, ok := r.Latency(Alter)
+				assert.False(t, ok)
+
+				observe(monitor.Call{Operation: "GetObject", Duration: time.Second})
+
+				d, ok := r.Latency(Alter)
+				assert.True(t, ok)
+				assert.Equal(t, time.Second, d)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	// Monday
+	now := time.Date(2018, 10, 1, 12, 30, 15, 0, time.UTC)
+
+	cases := []struct {
+		testName, spec string
+		expected       time.Time
+	}{
+		{"Every minute", "* * * * *", time.Date(2018, 10, 1, 12, 31, 0, 0, time.UTC)},
+		{"Nightly at 02:00", "0 2 * * *", time.Date(2018, 10, 2, 2, 0, 0, 0, time.UTC)},
+		{"Every 15 minutes", "*/15 * * * *", time.Date(2018, 10, 1, 12, 45, 0, 0, time.UTC)},
+		{"List and range", "0 9-11,13 * * *", time.Date(2018, 10, 1, 13, 0, 0, 0, time.UTC)},
+		{"Sunday as 7", "0 0 * * 7", time.Date(2018, 10, 7, 0, 0, 0, 0, time.UTC)},
+		{"Day of month or day of week", "0 0 15 * 3", time.Date(2018, 10, 3, 0, 0, 0, 0, time.UTC)},
+		{"Next year", "0 0 1 1 *", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"Descriptor", "@hourly", time.Date(2018, 10, 1, 13, 0, 0, 0, time.UTC)},
+		{"Never", "0 0 30 2 *", time.Time{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			s, err := Parse(c.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, s.Next(now))
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	l "storj.io/ditto/pkg/logger"
+)
+
+// Job is a task run by scheduler.
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	schedule *Schedule
+	job      Job
+
+	mu      sync.Mutex
+	running bool
+}
+
+// Scheduler runs jobs on cron schedules. Activation of a job which is still running is skipped,
+// so long runs never overlap.
+type Scheduler struct {
+	logger  l.Logger
+	entries []*entry
+
+	now func() time.Time
+}
+
+// Creates new Scheduler.
+func NewScheduler(logger l.Logger) *Scheduler {
+	return &Scheduler{logger: logger, now: time.Now}
+}
+
+// Add registers job run on cron schedule spec, see Parse.
+func (s *Scheduler) Add(name, spec string, job Job) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	s.entries = append(s.entries, &entry{name: name, schedule: schedule, job: job})
+
+	return nil
+}
+
+// Run runs registered jobs on their schedules until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, e := range s.entries {
+		wg.Add(1)
+
+		go func(e *entry) {
+			defer wg.Done()
+			s.runEntry(ctx, e)
+		}(e)
+	}
+
+	wg.Wait()
+}
+
+func (s *Scheduler) runEntry(ctx context.Context, e *entry) {
+	for {
+		next := e.schedule.Next(s.now())
+		if next.IsZero() {
+			s.logE(fmt.Errorf("schedule: %s never runs", e.name))
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(s.now()))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			go s.trigger(ctx, e)
+		}
+	}
+}
+
+// trigger runs job unless its previous run is still in progress.
+func (s *Scheduler) trigger(ctx context.Context, e *entry) bool {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		s.log(fmt.Sprintf("schedule: %s skipped, previous run is still in progress", e.name))
+
+		return false
+	}
+
+	e.running = true
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		e.running = false
+		e.mu.Unlock()
+	}()
+
+	if err := e.job(ctx); err != nil {
+		s.logE(fmt.Errorf("schedule: %s failed: %s", e.name, err))
+	}
+
+	return true
+}
+
+func (s *Scheduler) log(msg string) {
+	if s.logger != nil {
+		s.logger.Log(msg)
+	}
+}
+
+func (s *Scheduler) logE(err error) {
+	if s.logger != nil {
+		s.logger.LogE(err)
+	}
+}
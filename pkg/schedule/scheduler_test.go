@@ -0,0 +1,39 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package schedule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestSchedulerOverlap(t *testing.T) {
+	logger := &test.MockLogger{}
+	s := NewScheduler(logger)
+
+	started, release := make(chan struct{}), make(chan struct{})
+
+	err := s.Add("job", "@daily", func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	assert.NoError(t, err)
+
+	e := s.entries[0]
+
+	done := make(chan bool)
+	go func() { done <- s.trigger(context.Background(), e) }()
+
+	<-started
+
+	assert.False(t, s.trigger(context.Background(), e))
+	assert.Equal(t, 1, logger.LogCount())
+
+	close(release)
+	assert.True(t, <-done)
+}
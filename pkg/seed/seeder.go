@@ -0,0 +1,309 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"storj.io/ditto/pkg/checkpoint"
+	"storj.io/ditto/pkg/delta"
+	l "storj.io/ditto/pkg/logger"
+	"storj.io/ditto/pkg/replication"
+)
+
+const (
+	DefaultWorkers = 8
+	listBatch      = 1000
+)
+
+// Progress describes seeding in progress or finished.
+type Progress struct {
+	Bucket   string    `json:"bucket"`
+	Listed   int64     `json:"listed"`
+	Copied   int64     `json:"copied"`
+	Skipped  int64     `json:"skipped"`
+	Failed   int64     `json:"failed"`
+	Bytes    int64     `json:"bytes"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"`
+	Done     bool      `json:"done"`
+}
+
+func (p Progress) String() string {
+	return fmt.Sprintf("bucket %s: listed %d, copied %d (%d bytes), skipped %d, failed %d",
+		p.Bucket, p.Listed, p.Copied, p.Bytes, p.Skipped, p.Failed)
+}
+
+// Seeder copies existing objects of prime to a new alter. Buckets missing on alter are created first.
+// Pages of prime listing are copied by parallel workers, objects which already exist on alter with the same size
+// and ETag are skipped, as well as objects which aren't mirrored.
+// Progress is checkpointed after every page, so seeding resumes where it stopped.
+// Seeding runs alongside live mirroring: handler is expected to serialize copies with client writes,
+// see mirroring.MirroringObjectLayer.LockedHandler.
+type Seeder struct {
+	prime, alter minio.ObjectLayer
+	handler      replication.Handler
+	checkpoint   *checkpoint.Checkpoint
+	workers      int
+	logger       l.Logger
+
+	// drop receives objects which failed to copy, e.g. to journal them.
+	drop func(task replication.Task, err error)
+
+	// mirrored selects objects which are mirrored to alter, nil selects every object.
+	mirrored func(object string) bool
+
+	mu       sync.Mutex
+	progress Progress
+}
+
+// Creates new Seeder, nil checkpoint disables resuming, non-positive workers is replaced with default.
+func NewSeeder(prime, alter minio.ObjectLayer, handler replication.Handler, checkpoint *checkpoint.Checkpoint, workers int, logger l.Logger) *Seeder {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	return &Seeder{
+		prime:      prime,
+		alter:      alter,
+		handler:    handler,
+		checkpoint: checkpoint,
+		workers:    workers,
+		logger:     logger,
+	}
+}
+
+// SetDropHandler sets function called for every object which failed to copy.
+func (s *Seeder) SetDropHandler(f func(task replication.Task, err error)) {
+	s.drop = f
+}
+
+// WithMirrored makes seeder copy only objects for which mirrored returns true, e.g. objects passing filter
+// of mirroring layer. Other objects are counted as skipped.
+func (s *Seeder) WithMirrored(mirrored func(object string) bool) *Seeder {
+	s.mirrored = mirrored
+
+	return s
+}
+
+// Run seeds all buckets of prime.
+func (s *Seeder) Run(ctx context.Context) error {
+	s.update(func(p *Progress) { *p = Progress{Started: time.Now()} })
+
+	buckets, err := s.prime.ListBuckets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range buckets {
+		if err := s.seedBucket(ctx, b.Name); err != nil {
+			return err
+		}
+	}
+
+	s.finish()
+
+	return nil
+}
+
+// RunBucket seeds single bucket of prime.
+func (s *Seeder) RunBucket(ctx context.Context, bucket string) error {
+	s.update(func(p *Progress) { *p = Progress{Bucket: bucket, Started: time.Now()} })
+
+	if err := s.seedBucket(ctx, bucket); err != nil {
+		return err
+	}
+
+	s.finish()
+
+	return nil
+}
+
+func (s *Seeder) finish() {
+	s.update(func(p *Progress) {
+		p.Done = true
+		p.Finished = time.Now()
+	})
+
+	s.log("seed: done, " + s.Progress().String())
+}
+
+func (s *Seeder) seedBucket(ctx context.Context, bucket string) error {
+	marker := ""
+
+	if s.checkpoint != nil {
+		var completed bool
+		var err error
+
+		marker, completed, err = s.checkpoint.Marker(bucket)
+		if err != nil {
+			return err
+		}
+
+		if completed {
+			return nil
+		}
+	}
+
+	s.update(func(p *Progress) { p.Bucket = bucket })
+
+	if err := s.makeBucket(ctx, bucket); err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		loi, err := s.prime.ListObjects(ctx, bucket, "", marker, "", listBatch)
+		if err != nil {
+			return err
+		}
+
+		s.seedPage(ctx, bucket, loi.Objects)
+
+		if len(loi.Objects) > 0 {
+			marker = loi.NextMarker
+			if marker == "" {
+				marker = loi.Objects[len(loi.Objects)-1].Name
+			}
+
+			if err := s.save(bucket, marker); err != nil {
+				return err
+			}
+		}
+
+		s.log("seed: " + s.Progress().String())
+
+		if !loi.IsTruncated || len(loi.Objects) == 0 {
+			break
+		}
+	}
+
+	if s.checkpoint != nil {
+		return s.checkpoint.Complete(bucket)
+	}
+
+	return nil
+}
+
+// makeBucket creates bucket on alter unless it already exists there.
+func (s *Seeder) makeBucket(ctx context.Context, bucket string) error {
+	_, err := s.alter.GetBucketInfo(ctx, bucket)
+	if _, ok := err.(minio.BucketNotFound); !ok {
+		return err
+	}
+
+	switch err := s.alter.MakeBucketWithLocation(ctx, bucket, "").(type) {
+	case nil, minio.BucketExists, minio.BucketAlreadyOwnedByYou:
+		s.log(fmt.Sprintf("seed: bucket %s created on alter", bucket))
+		return nil
+	default:
+		return err
+	}
+}
+
+// seedPage copies objects of a page by parallel workers and waits for all of them.
+func (s *Seeder) seedPage(ctx context.Context, bucket string, objects []minio.ObjectInfo) {
+	sem := make(chan struct{}, s.workers)
+
+	var wg sync.WaitGroup
+
+	for _, oi := range objects {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(oi minio.ObjectInfo) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			s.seedObject(ctx, bucket, oi)
+		}(oi)
+	}
+
+	wg.Wait()
+}
+
+func (s *Seeder) seedObject(ctx context.Context, bucket string, oi minio.ObjectInfo) {
+	s.update(func(p *Progress) { p.Listed++ })
+
+	if s.mirrored != nil && !s.mirrored(oi.Name) {
+		s.update(func(p *Progress) { p.Skipped++ })
+		return
+	}
+
+	aoi, err := s.alter.GetObjectInfo(ctx, bucket, oi.Name, minio.ObjectOptions{})
+	if err == nil && delta.Same(oi, aoi) {
+		s.update(func(p *Progress) { p.Skipped++ })
+		return
+	}
+
+	task := replication.NewPutTask(bucket, oi.Name)
+
+	if err := s.handler.Handle(ctx, task); err != nil {
+		s.update(func(p *Progress) { p.Failed++ })
+		s.logE(fmt.Errorf("seed: %s failed: %s", task, err))
+
+		if s.drop != nil {
+			s.drop(task, err)
+		}
+
+		return
+	}
+
+	s.update(func(p *Progress) {
+		p.Copied++
+		p.Bytes += oi.Size
+	})
+}
+
+func (s *Seeder) save(bucket, marker string) error {
+	if s.checkpoint == nil {
+		return nil
+	}
+
+	return s.checkpoint.Save(bucket, marker)
+}
+
+// Progress returns snapshot of seeding progress.
+func (s *Seeder) Progress() Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.progress
+}
+
+// ServeHTTP responds with seeding progress as JSON.
+func (s *Seeder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Progress())
+}
+
+func (s *Seeder) update(f func(p *Progress)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f(&s.progress)
+}
+
+func (s *Seeder) log(msg string) {
+	if s.logger != nil {
+		s.logger.Log(msg)
+	}
+}
+
+func (s *Seeder) logE(err error) {
+	if s.logger != nil {
+		s.logger.LogE(err)
+	}
+}
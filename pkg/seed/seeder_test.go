@@ -0,0 +1,203 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package seed
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/checkpoint"
+	"storj.io/ditto/pkg/replication"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+type handlerFunc func(ctx context.Context, task replication.Task) error
+
+func (f handlerFunc) Handle(ctx context.Context, task replication.Task) error {
+	return f(ctx, task)
+}
+
+func openTestCheckpoint(t *testing.T) (*checkpoint.Checkpoint, func()) {
+	dir, err := ioutil.TempDir("", "seed-test")
+	assert.NoError(t, err)
+
+	c, err := checkpoint.Open(filepath.Join(dir, "seed.db"))
+	assert.NoError(t, err)
+
+	return c, func() {
+		c.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// newBackends returns prime listing objects in pages of two and alter storing objects in alterObjects.
+func newBackends(primeObjects []string, alterObjects map[string]minio.ObjectInfo) (minio.ObjectLayer, minio.ObjectLayer) {
+	prime := test.NewProxyObjectLayer()
+	alter := test.NewProxyObjectLayer()
+
+	prime.ListBucketsFunc = func(ctx context.Context) ([]minio.BucketInfo, error) {
+		return []minio.BucketInfo{{Name: "bucket"}}, nil
+	}
+
+	prime.ListObjectsFunc = func(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (minio.ListObjectsInfo, error) {
+		var page []minio.ObjectInfo
+
+		for _, o := range primeObjects {
+			if o > marker && len(page) < 2 {
+				page = append(page, minio.ObjectInfo{Bucket: bucket, Name: o, Size: 1, ETag: "etag"})
+			}
+		}
+
+		truncated := len(page) > 0 && page[len(page)-1].Name != primeObjects[len(primeObjects)-1]
+
+		return minio.ListObjectsInfo{Objects: page, IsTruncated: truncated}, nil
+	}
+
+	alter.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		if oi, ok := alterObjects[object]; ok {
+			return oi, nil
+		}
+
+		return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+	}
+
+	return prime, alter
+}
+
+// bucketlessLayer has no buckets until they are made.
+type bucketlessLayer struct {
+	minio.ObjectLayer
+	made []string
+}
+
+func (b *bucketlessLayer) GetBucketInfo(ctx context.Context, bucket string) (minio.BucketInfo, error) {
+	for _, made := range b.made {
+		if made == bucket {
+			return minio.BucketInfo{Name: bucket}, nil
+		}
+	}
+
+	return minio.BucketInfo{}, minio.BucketNotFound{Bucket: bucket}
+}
+
+func (b *bucketlessLayer) MakeBucketWithLocation(ctx context.Context, bucket string, location string) error {
+	b.made = append(b.made, bucket)
+	return nil
+}
+
+func TestSeeder(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Missing objects copied, mirrored skipped, failed dropped",
+			func(t *testing.T) {
+				prime, alter := newBackends([]string{"a", "b", "c", "d", "e"}, map[string]minio.ObjectInfo{
+					"b": {Name: "b", Size: 1, ETag: "etag"},
+					"c": {Name: "c", Size: 2, ETag: "etag"},
+				})
+
+				var mu sync.Mutex
+				var copied, dropped []string
+
+				handler := handlerFunc(func(ctx context.Context, task replication.Task) error {
+					if task.Object == "e" {
+						return errors.New("failed")
+					}
+
+					mu.Lock()
+					copied = append(copied, task.Object)
+					mu.Unlock()
+
+					return nil
+				})
+
+				s := NewSeeder(prime, alter, handler, nil, 2, nil)
+				s.SetDropHandler(func(task replication.Task, err error) {
+					dropped = append(dropped, task.Object)
+				})
+
+				err := s.Run(context.Background())
+				assert.NoError(t, err)
+
+				sort.Strings(copied)
+				assert.Equal(t, []string{"a", "c", "d"}, copied)
+				assert.Equal(t, []string{"e"}, dropped)
+
+				p := s.Progress()
+				assert.Equal(t, int64(5), p.Listed)
+				assert.Equal(t, int64(3), p.Copied)
+				assert.Equal(t, int64(1), p.Skipped)
+				assert.Equal(t, int64(1), p.Failed)
+				assert.True(t, p.Done)
+			},
+		},
+		{
+			"Seeding resumes from checkpoint",
+			func(t *testing.T) {
+				cp, cleanup := openTestCheckpoint(t)
+				defer cleanup()
+
+				assert.NoError(t, cp.Save("bucket", "b"))
+
+				prime, alter := newBackends([]string{"a", "b", "c"}, nil)
+
+				var copied []string
+				handler := handlerFunc(func(ctx context.Context, task replication.Task) error {
+					copied = append(copied, task.Object)
+					return nil
+				})
+
+				s := NewSeeder(prime, alter, handler, cp, 1, nil)
+
+				assert.NoError(t, s.Run(context.Background()))
+				assert.Equal(t, []string{"c"}, copied)
+
+				marker, completed, err := cp.Marker("bucket")
+				assert.NoError(t, err)
+				assert.Equal(t, "c", marker)
+				assert.True(t, completed)
+
+				// completed bucket is not seeded again
+				copied = nil
+				assert.NoError(t, s.Run(context.Background()))
+				assert.Empty(t, copied)
+			},
+		},
+		{
+			"Missing bucket created, objects not mirrored skipped",
+			func(t *testing.T) {
+				prime, alter := newBackends([]string{"a", "logs/b"}, nil)
+				bucketless := &bucketlessLayer{ObjectLayer: alter}
+
+				var copied []string
+				handler := handlerFunc(func(ctx context.Context, task replication.Task) error {
+					copied = append(copied, task.Object)
+					return nil
+				})
+
+				s := NewSeeder(prime, bucketless, handler, nil, 1, nil)
+				s.WithMirrored(func(object string) bool { return object != "logs/b" })
+
+				assert.NoError(t, s.Run(context.Background()))
+				assert.Equal(t, []string{"bucket"}, bucketless.made)
+				assert.Equal(t, []string{"a"}, copied)
+				assert.Equal(t, int64(1), s.Progress().Skipped)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
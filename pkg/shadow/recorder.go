@@ -0,0 +1,146 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package shadow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	l "storj.io/ditto/pkg/logger"
+)
+
+// Result is an outcome of a single write executed on both backends.
+type Result struct {
+	Operation    string
+	Bucket       string
+	Object       string
+	PrimeLatency time.Duration
+	AlterLatency time.Duration
+	PrimeErr     error
+	AlterErr     error
+	PrimeETag    string
+	AlterETag    string
+}
+
+// Match returns true if both backends succeeded or failed alike and returned the same ETag.
+func (r Result) Match() bool {
+	if (r.PrimeErr == nil) != (r.AlterErr == nil) {
+		return false
+	}
+
+	return normalizeETag(r.PrimeETag) == normalizeETag(r.AlterETag)
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("shadow %s %s/%s: match %t, prime %s err %v etag %q, alter %s err %v etag %q",
+		r.Operation, r.Bucket, r.Object, r.Match(),
+		r.PrimeLatency, r.PrimeErr, r.PrimeETag,
+		r.AlterLatency, r.AlterErr, r.AlterETag)
+}
+
+// Stats aggregates recorded results.
+type Stats struct {
+	Total               int64         `json:"total"`
+	Mismatches          int64         `json:"mismatches"`
+	PrimeErrors         int64         `json:"primeErrors"`
+	AlterErrors         int64         `json:"alterErrors"`
+	PrimeLatencyAverage time.Duration `json:"primeLatencyAverage"`
+	AlterLatencyAverage time.Duration `json:"alterLatencyAverage"`
+
+	primeLatencyTotal, alterLatencyTotal time.Duration
+}
+
+// Recorder samples writes which are sent to alter and records comparison of results.
+type Recorder struct {
+	percentage float64
+	logger     l.Logger
+
+	mu    sync.Mutex
+	rnd   *rand.Rand
+	stats Stats
+}
+
+// Creates new Recorder sampling percentage of writes, percentage is clamped to [0, 100].
+func NewRecorder(percentage float64, logger l.Logger) *Recorder {
+	if percentage < 0 {
+		percentage = 0
+	}
+
+	if percentage > 100 {
+		percentage = 100
+	}
+
+	return &Recorder{
+		percentage: percentage,
+		logger:     logger,
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Sample returns true if current write should be sent to alter.
+func (r *Recorder) Sample() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rnd.Float64()*100 < r.percentage
+}
+
+// Record adds result to stats, mismatches are logged as errors.
+func (r *Recorder) Record(res Result) {
+	r.mu.Lock()
+	s := &r.stats
+
+	s.Total++
+	s.primeLatencyTotal += res.PrimeLatency
+	s.alterLatencyTotal += res.AlterLatency
+	s.PrimeLatencyAverage = s.primeLatencyTotal / time.Duration(s.Total)
+	s.AlterLatencyAverage = s.alterLatencyTotal / time.Duration(s.Total)
+
+	if res.PrimeErr != nil {
+		s.PrimeErrors++
+	}
+
+	if res.AlterErr != nil {
+		s.AlterErrors++
+	}
+
+	match := res.Match()
+	if !match {
+		s.Mismatches++
+	}
+	r.mu.Unlock()
+
+	if r.logger == nil {
+		return
+	}
+
+	if match {
+		r.logger.Log(res.String())
+	} else {
+		r.logger.LogE(fmt.Errorf("%s", res))
+	}
+}
+
+// Stats returns copy of aggregated stats.
+func (r *Recorder) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
+
+// ServeHTTP writes aggregated stats as JSON.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r.Stats())
+}
+
+func normalizeETag(etag string) string {
+	return strings.ToLower(strings.Trim(etag, "\""))
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package shadow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestResultMatch(t *testing.T) {
+	assert.True(t, Result{PrimeETag: "\"ABC\"", AlterETag: "abc"}.Match())
+	assert.False(t, Result{PrimeETag: "abc", AlterETag: "abd"}.Match())
+	assert.False(t, Result{AlterErr: errors.New("alter failed")}.Match())
+	assert.True(t, Result{PrimeErr: errors.New("prime failed"), AlterErr: errors.New("alter failed")}.Match())
+}
+
+func TestSample(t *testing.T) {
+	never := NewRecorder(0, nil)
+	always := NewRecorder(150, nil)
+
+	for i := 0; i < 100; i++ {
+		assert.False(t, never.Sample())
+		assert.True(t, always.Sample())
+	}
+}
+
+func TestRecord(t *testing.T) {
+	logger := &test.MockLogger{}
+	r := NewRecorder(100, logger)
+
+	r.Record(Result{PrimeLatency: time.Second, AlterLatency: 3 * time.Second, PrimeETag: "a", AlterETag: "a"})
+	r.Record(Result{PrimeLatency: 3 * time.Second, AlterLatency: time.Second, PrimeETag: "a", AlterErr: errors.New("alter failed")})
+
+	s := r.Stats()
+	assert.Equal(t, int64(2), s.Total)
+	assert.Equal(t, int64(1), s.Mismatches)
+	assert.Equal(t, int64(0), s.PrimeErrors)
+	assert.Equal(t, int64(1), s.AlterErrors)
+	assert.Equal(t, 2*time.Second, s.PrimeLatencyAverage)
+	assert.Equal(t, 2*time.Second, s.AlterLatencyAverage)
+	assert.Equal(t, 1, logger.LogCount())
+	assert.Equal(t, 1, logger.LogECount())
+}
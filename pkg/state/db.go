@@ -0,0 +1,188 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package state
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type Status string
+
+const (
+	// IN_SYNC object is mirrored.
+	IN_SYNC Status = "in-sync"
+	// PENDING object is scheduled for background replication.
+	PENDING Status = "pending"
+	// FAILED replication of object failed, it's journaled if journal is enabled.
+	FAILED Status = "failed"
+	// DIVERGED copies of object differ although replication succeeded.
+	DIVERGED Status = "diverged"
+	// DELETED object was deleted on prime but not on alter, record is kept as tombstone,
+	// so copy left on alter is known to be an orphan.
+	DELETED Status = "deleted"
+)
+
+var objectsBucket = []byte("objects")
+
+// Record is replication status of an object.
+type Record struct {
+	Bucket  string    `json:"bucket"`
+	Object  string    `json:"object"`
+	Status  Status    `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// DB tracks replication status of objects. Deleted objects are tracked only if alter failed to delete them.
+type DB struct {
+	db *bolt.DB
+
+	now func() time.Time
+}
+
+// Open opens state database stored at path, file is created if it doesn't exist.
+func Open(path string) (*DB, error) {
+	return open(path, false)
+}
+
+// OpenReadOnly opens existing state database for queries.
+func OpenReadOnly(path string) (*DB, error) {
+	return open(path, true)
+}
+
+func open(path string, readOnly bool) (*DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, err
+	}
+
+	if !readOnly {
+		err = db.Update(func(tx *bolt.Tx) error {
+			for _, name := range [][]byte{objectsBucket, usageBucket, sizesBucket} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return &DB{db: db, now: time.Now}, nil
+}
+
+// Set records status of object, err describes failure or divergence.
+func (d *DB) Set(bucket, object string, status Status, err error) error {
+	r := Record{Bucket: bucket, Object: object, Status: status, Updated: d.now().UTC()}
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	value, merr := json.Marshal(r)
+	if merr != nil {
+		return merr
+	}
+
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(objectsBucket).Put(key(bucket, object), value)
+	})
+}
+
+// Delete stops tracking of object.
+func (d *DB) Delete(bucket, object string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(objectsBucket).Delete(key(bucket, object))
+	})
+}
+
+// Get returns record of object, false if object is not tracked.
+func (d *DB) Get(bucket, object string) (r Record, ok bool, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(objectsBucket)
+		if b == nil {
+			return nil
+		}
+
+		value := b.Get(key(bucket, object))
+		if value == nil {
+			return nil
+		}
+
+		ok = true
+
+		return json.Unmarshal(value, &r)
+	})
+
+	return
+}
+
+// List returns up to limit records with status in key order, empty status matches all records.
+// Non-positive limit returns all matching records.
+func (d *DB) List(status Status, limit int) ([]Record, error) {
+	var records []Record
+
+	err := d.forEach(func(r Record) bool {
+		if status == "" || r.Status == status {
+			records = append(records, r)
+		}
+
+		return limit <= 0 || len(records) < limit
+	})
+
+	return records, err
+}
+
+// Counts returns amount of tracked objects per status.
+func (d *DB) Counts() (map[Status]int, error) {
+	counts := map[Status]int{}
+
+	err := d.forEach(func(r Record) bool {
+		counts[r.Status]++
+		return true
+	})
+
+	return counts, err
+}
+
+// forEach calls f for records in key order until f returns false.
+func (d *DB) forEach(f func(r Record) bool) error {
+	return d.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(objectsBucket)
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var r Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+
+			if !f(r) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+// Close closes underlying database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+func key(bucket, object string) []byte {
+	return []byte(bucket + "/" + object)
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/replication"
+)
+
+type handlerFunc func(ctx context.Context, task replication.Task) error
+
+func (f handlerFunc) Handle(ctx context.Context, task replication.Task) error {
+	return f(ctx, task)
+}
+
+func openTestDB(t *testing.T) (*DB, func()) {
+	dir, err := ioutil.TempDir("", "state-test")
+	assert.NoError(t, err)
+
+	db, err := Open(filepath.Join(dir, "state.db"))
+	assert.NoError(t, err)
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestDB(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	assert.NoError(t, db.Set("bucket", "a", IN_SYNC, nil))
+	assert.NoError(t, db.Set("bucket", "b", FAILED, errors.New("test error")))
+	assert.NoError(t, db.Set("bucket", "c", FAILED, nil))
+	assert.NoError(t, db.Set("bucket", "d", PENDING, nil))
+	assert.NoError(t, db.Delete("bucket", "d"))
+
+	r, ok, err := db.Get("bucket", "b")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, FAILED, r.Status)
+	assert.Equal(t, "test error", r.Error)
+
+	_, ok, err = db.Get("bucket", "d")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	failed, err := db.List(FAILED, 0)
+	assert.NoError(t, err)
+	assert.Len(t, failed, 2)
+	assert.Equal(t, "b", failed[0].Object)
+
+	limited, err := db.List("", 1)
+	assert.NoError(t, err)
+	assert.Len(t, limited, 1)
+
+	counts, err := db.Counts()
+	assert.NoError(t, err)
+	assert.Equal(t, map[Status]int{IN_SYNC: 1, FAILED: 2}, counts)
+}
+
+func TestHandler(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	testError := errors.New("test error")
+
+	h := NewHandler(handlerFunc(func(ctx context.Context, task replication.Task) error {
+		if task.Object == "failed" {
+			return testError
+		}
+
+		return nil
+	}), db)
+
+	assert.NoError(t, db.Set("bucket", "deleted", PENDING, nil))
+
+	assert.NoError(t, h.Handle(context.Background(), replication.NewPutTask("bucket", "object")))
+	assert.Equal(t, testError, h.Handle(context.Background(), replication.NewPutTask("bucket", "failed")))
+	assert.NoError(t, h.Handle(context.Background(), replication.NewDeleteTask("bucket", "deleted")))
+	assert.Equal(t, testError, h.Handle(context.Background(), replication.NewDeleteTask("bucket", "failed")))
+
+	records, err := db.List("", 0)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, DELETED, records[0].Status)
+	assert.Equal(t, IN_SYNC, records[1].Status)
+}
+
+func TestUsage(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	assert.NoError(t, db.SetSize("bucket", "a", 10))
+	assert.NoError(t, db.SetSize("bucket", "b", 20))
+	assert.NoError(t, db.SetSize("bucket", "a", 5))
+	assert.NoError(t, db.SetSize("other", "a", 100))
+
+	u, err := db.Usage("bucket")
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Objects: 2, Bytes: 25}, u)
+
+	size, ok, err := db.Size("bucket", "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), size)
+
+	assert.NoError(t, db.RemoveSize("bucket", "b"))
+	assert.NoError(t, db.RemoveSize("bucket", "missing"))
+
+	u, err = db.Usage("bucket")
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Objects: 1, Bytes: 5}, u)
+
+	u, err = db.Usage("empty")
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{}, u)
+}
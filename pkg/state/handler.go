@@ -0,0 +1,37 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package state
+
+import (
+	"context"
+
+	"storj.io/ditto/pkg/replication"
+)
+
+// NewHandler wraps replication handler so result of every task is recorded in db.
+func NewHandler(h replication.Handler, db *DB) replication.Handler {
+	return &handler{h, db}
+}
+
+type handler struct {
+	handler replication.Handler
+	db      *DB
+}
+
+func (h *handler) Handle(ctx context.Context, task replication.Task) error {
+	err := h.handler.Handle(ctx, task)
+
+	switch {
+	case err != nil && task.Operation == replication.DELETE:
+		h.db.Set(task.Bucket, task.Object, DELETED, err)
+	case err != nil:
+		h.db.Set(task.Bucket, task.Object, FAILED, err)
+	case task.Operation == replication.DELETE:
+		h.db.Delete(task.Bucket, task.Object)
+	default:
+		h.db.Set(task.Bucket, task.Object, IN_SYNC, nil)
+	}
+
+	return err
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package state
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// ServeHTTP responds with record of the object for ?bucket=b&object=o query, 404 if it's not tracked,
+// with records of a status for ?status=s&limit=n query, or with amount of objects per status
+// if there is no query. Responses are JSON.
+func (d *DB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+
+	var body interface{}
+	var err error
+
+	switch {
+	case q.Get("object") != "":
+		var ok bool
+		body, ok, err = d.Get(q.Get("bucket"), q.Get("object"))
+		if err == nil && !ok {
+			http.Error(w, "object is not tracked", http.StatusNotFound)
+			return
+		}
+	case q.Get("status") != "" || q.Get("limit") != "":
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		body, err = d.List(Status(q.Get("status")), limit)
+	default:
+		body, err = d.Counts()
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
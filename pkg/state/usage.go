@@ -0,0 +1,132 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package state
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	usageBucket = []byte("usage")
+	sizesBucket = []byte("sizes")
+)
+
+// Usage is amount and total size of objects written to a bucket through the gateway.
+type Usage struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// SetSize records size of written object, usage of its bucket accounts for object replaced by it.
+func (d *DB) SetSize(bucket, object string, size int64) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		sizes := tx.Bucket(sizesBucket)
+
+		u, err := usage(tx, bucket)
+		if err != nil {
+			return err
+		}
+
+		if old, ok := getSize(sizes, bucket, object); ok {
+			u.Bytes -= old
+		} else {
+			u.Objects++
+		}
+
+		u.Bytes += size
+
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, uint64(size))
+
+		if err := sizes.Put(key(bucket, object), value); err != nil {
+			return err
+		}
+
+		return putUsage(tx, bucket, u)
+	})
+}
+
+// RemoveSize removes deleted object from usage of its bucket.
+func (d *DB) RemoveSize(bucket, object string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		sizes := tx.Bucket(sizesBucket)
+
+		old, ok := getSize(sizes, bucket, object)
+		if !ok {
+			return nil
+		}
+
+		u, err := usage(tx, bucket)
+		if err != nil {
+			return err
+		}
+
+		u.Objects--
+		u.Bytes -= old
+
+		if err := sizes.Delete(key(bucket, object)); err != nil {
+			return err
+		}
+
+		return putUsage(tx, bucket, u)
+	})
+}
+
+// Size returns recorded size of object, false if its size isn't recorded.
+func (d *DB) Size(bucket, object string) (size int64, ok bool, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		size, ok = getSize(tx.Bucket(sizesBucket), bucket, object)
+		return nil
+	})
+
+	return
+}
+
+// Usage returns usage of bucket, bucket with no recorded objects has zero usage.
+func (d *DB) Usage(bucket string) (u Usage, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		u, err = usage(tx, bucket)
+		return err
+	})
+
+	return
+}
+
+func usage(tx *bolt.Tx, bucket string) (u Usage, err error) {
+	b := tx.Bucket(usageBucket)
+	if b == nil {
+		return u, nil
+	}
+
+	if value := b.Get([]byte(bucket)); value != nil {
+		err = json.Unmarshal(value, &u)
+	}
+
+	return u, err
+}
+
+func putUsage(tx *bolt.Tx, bucket string, u Usage) error {
+	value, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(usageBucket).Put([]byte(bucket), value)
+}
+
+func getSize(sizes *bolt.Bucket, bucket, object string) (int64, bool) {
+	if sizes == nil {
+		return 0, false
+	}
+
+	value := sizes.Get(key(bucket, object))
+	if len(value) != 8 {
+		return 0, false
+	}
+
+	return int64(binary.BigEndian.Uint64(value)), true
+}
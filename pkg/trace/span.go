@@ -0,0 +1,151 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanContext identifies span within a trace, as propagated by W3C traceparent header.
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// ParseTraceparent parses W3C traceparent header, e.g. "00-<trace id>-<span id>-01".
+// Returns false for missing or malformed header.
+func ParseTraceparent(header string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, false
+	}
+
+	traceID, spanID, flags := strings.ToLower(parts[1]), strings.ToLower(parts[2]), parts[3]
+
+	if !isID(traceID, 32) || !isID(spanID, 16) || len(flags) != 2 {
+		return SpanContext{}, false
+	}
+
+	f, err := hex.DecodeString(flags)
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	return SpanContext{TraceID: traceID, SpanID: spanID, Sampled: f[0]&1 == 1}, true
+}
+
+// Traceparent formats span context as W3C traceparent header.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// isID returns true if s is non-zero lowercase hex of length n.
+func isID(s string, n int) bool {
+	if len(s) != n || strings.Trim(s, "0") == "" {
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+
+	return err == nil
+}
+
+func newID(bytes int) string {
+	b := make([]byte, bytes)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// Span is a timed operation within a trace. Unsampled spans are created, but never exported.
+type Span struct {
+	SpanContext
+	ParentID string
+	Name     string
+	Kind     string
+	Start    time.Time
+	Duration time.Duration
+
+	mu       sync.Mutex
+	tags     map[string]string
+	tracer   *Tracer
+	finished bool
+}
+
+// SetTag sets tag of span.
+func (s *Span) SetTag(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tags[key] = value
+}
+
+// Tags returns copy of tags of span.
+func (s *Span) Tags() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tags := make(map[string]string, len(s.tags))
+	for k, v := range s.tags {
+		tags[k] = v
+	}
+
+	return tags
+}
+
+// Finish ends span and exports it if sampled, non-nil err is recorded as error tag.
+// Only first call has effect.
+func (s *Span) Finish(err error) {
+	s.finishAt(time.Now(), err)
+}
+
+func (s *Span) finishAt(end time.Time, err error) {
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+
+	s.finished = true
+	s.Duration = end.Sub(s.Start)
+
+	if err != nil {
+		s.tags["error"] = err.Error()
+	}
+	s.mu.Unlock()
+
+	if s.Sampled && s.tracer != nil {
+		s.tracer.exporter.Export(s)
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns copy of ctx carrying span, so spans started with it become its children.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns span carried by ctx, if any.
+func SpanFromContext(ctx context.Context) (*Span, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	span, ok := ctx.Value(spanKey{}).(*Span)
+
+	return span, ok
+}
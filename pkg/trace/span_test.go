@@ -0,0 +1,49 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	cases := []struct {
+		testName   string
+		header     string
+		expectedOk bool
+		expected   SpanContext
+	}{
+		{
+			"Sampled trace",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			true,
+			SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+		},
+		{
+			"Unsampled trace",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			true,
+			SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"},
+		},
+		{"Missing header", "", false, SpanContext{}},
+		{"Zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, SpanContext{}},
+		{"Short span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01", false, SpanContext{}},
+		{"Invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, SpanContext{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, func(t *testing.T) {
+			sc, ok := ParseTraceparent(c.header)
+
+			assert.Equal(t, c.expectedOk, ok)
+			assert.Equal(t, c.expected, sc)
+
+			if ok {
+				assert.Equal(t, c.header, sc.Traceparent())
+			}
+		})
+	}
+}
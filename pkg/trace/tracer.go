@@ -0,0 +1,150 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trace
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	dcontext "storj.io/ditto/pkg/context"
+	"storj.io/ditto/pkg/objlayer/monitor"
+)
+
+// Span kinds.
+const (
+	SERVER = "SERVER"
+	CLIENT = "CLIENT"
+)
+
+// Exporter sends finished sampled spans to tracing backend.
+type Exporter interface {
+	Export(span *Span)
+}
+
+// Tracer starts spans and exports sampled ones.
+// New traces are sampled with probability sampleRate, traces propagated from callers keep their sampling decision.
+type Tracer struct {
+	exporter   Exporter
+	sampleRate float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Creates new Tracer exporting spans to exporter.
+func NewTracer(exporter Exporter, sampleRate float64) *Tracer {
+	return &Tracer{
+		exporter:   exporter,
+		sampleRate: sampleRate,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (t *Tracer) sample() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rand.Float64() < t.sampleRate
+}
+
+func (t *Tracer) newSpan(parent SpanContext, hasParent bool, name, kind string, start time.Time) *Span {
+	span := &Span{
+		Name:   name,
+		Kind:   kind,
+		Start:  start,
+		tags:   make(map[string]string),
+		tracer: t,
+	}
+
+	span.SpanID = newID(8)
+
+	if hasParent {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+		span.Sampled = parent.Sampled
+	} else {
+		span.TraceID = newID(16)
+		span.Sampled = t.sample()
+	}
+
+	return span
+}
+
+// StartSpan starts span named name as child of span carried by ctx, or as a root of new trace.
+// Returned context carries the new span.
+func (t *Tracer) StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	var parent SpanContext
+
+	p, ok := SpanFromContext(ctx)
+	if ok {
+		parent = p.SpanContext
+	}
+
+	span := t.newSpan(parent, ok, name, "", time.Now())
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// Observer returns monitor hook recording every call of backend as a client span.
+// Only calls made within a traced request are recorded, e.g. background replication is not.
+func (t *Tracer) Observer(backend string) func(call monitor.Call) {
+	return func(call monitor.Call) {
+		parent, ok := SpanFromContext(call.Context)
+		if !ok || !parent.Sampled {
+			return
+		}
+
+		end := time.Now()
+		span := t.newSpan(parent.SpanContext, true, backend+" "+call.Operation, CLIENT, end.Add(-call.Duration))
+		span.SetTag("backend", backend)
+		span.SetTag("operation", call.Operation)
+
+		if id, ok := dcontext.RequestIDFromContext(call.Context); ok {
+			span.SetTag("request_id", id)
+		}
+
+		if call.Bytes > 0 {
+			span.SetTag("bytes", strconv.FormatInt(call.Bytes, 10))
+		}
+
+		span.finishAt(end, call.Err)
+	}
+}
+
+// statusWriter records status code of response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Handler traces every request as a server span, continuing trace of W3C traceparent header if present.
+// Span is carried by request context, so object layer calls made with it become its children.
+func Handler(t *Tracer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parent, ok := ParseTraceparent(r.Header.Get("traceparent"))
+
+		span := t.newSpan(parent, ok, r.Method+" "+r.URL.Path, SERVER, time.Now())
+		span.SetTag("http.method", r.Method)
+		span.SetTag("http.path", r.URL.Path)
+
+		if id, ok := dcontext.RequestIDFromContext(r.Context()); ok {
+			span.SetTag("request_id", id)
+		}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ContextWithSpan(r.Context(), span)))
+
+		span.SetTag("http.status_code", strconv.Itoa(sw.status))
+		span.Finish(nil)
+	})
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/objlayer/monitor"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (e *recordingExporter) Export(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, span)
+}
+
+func TestTracer(t *testing.T) {
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			testName: "Backend calls are children of request span",
+			testFunc: func(t *testing.T) {
+				e := &recordingExporter{}
+				tracer := NewTracer(e, 0)
+				prime, alter := tracer.Observer("prime"), tracer.Observer("alter")
+
+				h := Handler(tracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					prime(monitor.Call{Context: r.Context(), Operation: "PutObject", Duration: time.Millisecond, Bytes: 4})
+					alter(monitor.Call{Context: r.Context(), Operation: "PutObject", Duration: time.Second, Err: errors.New("test error")})
+					w.WriteHeader(http.StatusCreated)
+				}))
+
+				r := httptest.NewRequest("PUT", "/bucket/object", nil)
+				r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+				h.ServeHTTP(httptest.NewRecorder(), r)
+
+				assert.Equal(t, 3, len(e.spans))
+
+				server := e.spans[2]
+				assert.Equal(t, "PUT /bucket/object", server.Name)
+				assert.Equal(t, SERVER, server.Kind)
+				assert.Equal(t, "00f067aa0ba902b7", server.ParentID)
+				assert.Equal(t, "201", server.Tags()["http.status_code"])
+
+				for _, span := range e.spans[:2] {
+					assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+					assert.Equal(t, server.SpanID, span.ParentID)
+					assert.Equal(t, CLIENT, span.Kind)
+				}
+
+				assert.Equal(t, "prime PutObject", e.spans[0].Name)
+				assert.Equal(t, "4", e.spans[0].Tags()["bytes"])
+				assert.Equal(t, "test error", e.spans[1].Tags()["error"])
+				assert.Equal(t, time.Second, e.spans[1].Duration)
+			},
+		},
+		{
+			testName: "Unsampled trace is not exported",
+			testFunc: func(t *testing.T) {
+				e := &recordingExporter{}
+				tracer := NewTracer(e, 1)
+				observe := tracer.Observer("prime")
+
+				h := Handler(tracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					observe(monitor.Call{Context: r.Context(), Operation: "GetObject"})
+				}))
+
+				r := httptest.NewRequest("GET", "/bucket/object", nil)
+				r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+				h.ServeHTTP(httptest.NewRecorder(), r)
+
+				assert.Equal(t, 0, len(e.spans))
+			},
+		},
+		{
+			testName: "Calls outside of traced request are not recorded",
+			testFunc: func(t *testing.T) {
+				e := &recordingExporter{}
+				tracer := NewTracer(e, 1)
+
+				tracer.Observer("alter")(monitor.Call{Context: context.Background(), Operation: "PutObject"})
+
+				assert.Equal(t, 0, len(e.spans))
+			},
+		},
+		{
+			testName: "New trace sampled by rate",
+			testFunc: func(t *testing.T) {
+				e := &recordingExporter{}
+				tracer := NewTracer(e, 1)
+
+				ctx, span := tracer.StartSpan(context.Background(), "root")
+				_, child := tracer.StartSpan(ctx, "child")
+				child.Finish(nil)
+				span.Finish(nil)
+				span.Finish(nil)
+
+				assert.Equal(t, 2, len(e.spans))
+				assert.Equal(t, span.TraceID, child.TraceID)
+				assert.Equal(t, span.SpanID, child.ParentID)
+				assert.Equal(t, "", span.ParentID)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	l "storj.io/ditto/pkg/logger"
+)
+
+const (
+	zipkinBatchSize     = 100
+	zipkinFlushInterval = time.Second
+	zipkinTimeout       = 10 * time.Second
+)
+
+// ZipkinExporter sends spans in batches to Zipkin v2 JSON API,
+// which is also accepted by Jaeger collector, e.g. http://localhost:9411/api/v2/spans.
+// Spans which can't be sent are dropped, failures are only logged.
+type ZipkinExporter struct {
+	url     string
+	service string
+	client  *http.Client
+	logger  l.Logger
+
+	mu      sync.Mutex
+	pending []zipkinSpan
+
+	flush chan struct{}
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind,omitempty"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint zipkinEndpoint    `json:"localEndpoint"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+// Creates new ZipkinExporter sending spans of service to url and starts sending.
+func NewZipkinExporter(url, service string, logger l.Logger) *ZipkinExporter {
+	z := &ZipkinExporter{
+		url:     url,
+		service: service,
+		client:  &http.Client{Timeout: zipkinTimeout},
+		logger:  logger,
+		flush:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+
+	z.wg.Add(1)
+	go z.run()
+
+	return z
+}
+
+// Export queues span for sending.
+func (z *ZipkinExporter) Export(span *Span) {
+	z.mu.Lock()
+	z.pending = append(z.pending, zipkinSpan{
+		TraceID:       span.TraceID,
+		ID:            span.SpanID,
+		ParentID:      span.ParentID,
+		Name:          span.Name,
+		Kind:          span.Kind,
+		Timestamp:     span.Start.UnixNano() / int64(time.Microsecond),
+		Duration:      int64(span.Duration / time.Microsecond),
+		LocalEndpoint: zipkinEndpoint{ServiceName: z.service},
+		Tags:          span.Tags(),
+	})
+	full := len(z.pending) >= zipkinBatchSize
+	z.mu.Unlock()
+
+	if full {
+		select {
+		case z.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close sends pending spans and stops exporter.
+func (z *ZipkinExporter) Close() {
+	close(z.stop)
+	z.wg.Wait()
+}
+
+func (z *ZipkinExporter) run() {
+	defer z.wg.Done()
+
+	ticker := time.NewTicker(zipkinFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-z.flush:
+		case <-z.stop:
+			z.send()
+			return
+		}
+
+		z.send()
+	}
+}
+
+func (z *ZipkinExporter) send() {
+	z.mu.Lock()
+	spans := z.pending
+	z.pending = nil
+	z.mu.Unlock()
+
+	if len(spans) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(spans)
+	if err == nil {
+		err = z.post(body)
+	}
+
+	if err != nil && z.logger != nil {
+		z.logger.LogE(fmt.Errorf("unable to export %d spans: %s", len(spans), err))
+	}
+}
+
+func (z *ZipkinExporter) post(body []byte) error {
+	resp, err := z.client.Post(z.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("zipkin responded %s", resp.Status)
+	}
+
+	return nil
+}
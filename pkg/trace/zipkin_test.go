@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trace
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	test "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+func TestZipkinExporter(t *testing.T) {
+	var received []zipkinSpan
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var spans []zipkinSpan
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&spans))
+		received = append(received, spans...)
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	logger := &test.MockLogger{}
+	z := NewZipkinExporter(srv.URL, "ditto", logger)
+
+	span := &Span{
+		SpanContext: SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+		Name:        "prime PutObject",
+		Kind:        CLIENT,
+		Start:       time.Unix(1, 0),
+		Duration:    time.Millisecond,
+		tags:        map[string]string{"backend": "prime"},
+	}
+
+	z.Export(span)
+	z.Close()
+
+	assert.Equal(t, 0, logger.LogECount())
+	assert.Equal(t, 1, len(received))
+	assert.Equal(t, "00f067aa0ba902b7", received[0].ID)
+	assert.Equal(t, int64(1000000), received[0].Timestamp)
+	assert.Equal(t, int64(1000), received[0].Duration)
+	assert.Equal(t, "ditto", received[0].LocalEndpoint.ServiceName)
+	assert.Equal(t, "prime", received[0].Tags["backend"])
+}
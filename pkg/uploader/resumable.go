@@ -0,0 +1,414 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package uploader
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"storj.io/ditto/pkg/objlayer/checksum"
+)
+
+const (
+	// DefaultPartSize is size of parts of resumable uploads.
+	DefaultPartSize = 64 << 20
+	// MinPartSize is the smallest part S3 accepts, except the last one.
+	MinPartSize = 5 << 20
+	// MaxParts is the most parts S3 accepts in one multipart upload, part size grows for bigger files.
+	MaxParts = 10000
+)
+
+// ResumableUploader uploads local files by parts of multipart upload and records uploaded parts in state file,
+// so upload interrupted e.g. by lost connection or Ctrl+C continues from the last uploaded part once started
+// again with the same state file. State file is removed once upload completes.
+// Parts are uploaded by concurrent workers, so a single file saturates the link.
+type ResumableUploader struct {
+	ol          minio.ObjectLayer
+	partSize    int64
+	concurrency int
+
+	// Progress is called with amount of bytes sent, concurrently by workers. Nil disables reporting.
+	Progress func(n int64)
+	// Resumed is called when interrupted upload is resumed with amount of parts and bytes uploaded before.
+	// Nil disables reporting.
+	Resumed func(parts int, bytes int64)
+
+	// Checksum makes uploader verify uploaded object against hashes of file computed while it's uploaded.
+	// MD5 of every part is recorded in state file, so resumed upload is verified by multipart ETag as well,
+	// object is read back if ETag doesn't match, e.g. if backend encrypts. Mismatch is checksum.MismatchError.
+	Checksum bool
+}
+
+// NewResumableUploader creates uploader of files to ol by parts of partSize, concurrency parts at once.
+// Files up to partSize are put at once.
+func NewResumableUploader(ol minio.ObjectLayer, partSize int64, concurrency int) *ResumableUploader {
+	return &ResumableUploader{ol: ol, partSize: partSize, concurrency: concurrency}
+}
+
+// uploadState is content of state file, file is resumed only if its size and modification time didn't change.
+type uploadState struct {
+	Bucket   string      `json:"bucket"`
+	Object   string      `json:"object"`
+	UploadID string      `json:"uploadId"`
+	Size     int64       `json:"size"`
+	ModTime  time.Time   `json:"modTime"`
+	PartSize int64       `json:"partSize"`
+	Parts    []statePart `json:"parts"`
+}
+
+type statePart struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+	MD5    string `json:"md5,omitempty"`
+}
+
+// PartSize returns size of parts file of size is uploaded by, it's grown if file wouldn't fit MaxParts.
+func (u *ResumableUploader) PartSize(size int64) int64 {
+	partSize := u.partSize
+	if min := (size + MaxParts - 1) / MaxParts; partSize < min {
+		partSize = min
+	}
+
+	return partSize
+}
+
+// Upload uploads file at lpath as object of bucket, resuming upload recorded in state file at statePath.
+// Recorded upload of other object or of file which has changed since is aborted and started from scratch.
+func (u *ResumableUploader) Upload(ctx context.Context, bucket, object, lpath, statePath string) (minio.ObjectInfo, error) {
+	f, err := os.Open(lpath)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	size, partSize := fi.Size(), u.PartSize(fi.Size())
+
+	if size <= partSize {
+		r, sum := u.hashed(u.counted(f))
+
+		data, err := hash.NewReader(r, size, "", "")
+		if err != nil {
+			return minio.ObjectInfo{}, err
+		}
+
+		oi, err := u.ol.PutObject(ctx, bucket, object, data, make(map[string]string), minio.ObjectOptions{})
+		if err != nil || sum == nil {
+			return oi, err
+		}
+
+		return oi, checksum.Verify(ctx, u.ol, bucket, object, oi.ETag, sum.MD5(), sum.SHA256())
+	}
+
+	st, err := u.resume(ctx, bucket, object, fi, partSize, statePath)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	if err := u.uploadParts(ctx, f, size, st, statePath); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	sort.Slice(st.Parts, func(i, j int) bool { return st.Parts[i].Number < st.Parts[j].Number })
+
+	completed := make([]minio.CompletePart, len(st.Parts))
+	for i, p := range st.Parts {
+		completed[i] = minio.CompletePart{PartNumber: p.Number, ETag: p.ETag}
+	}
+
+	oi, err := u.ol.CompleteMultipartUpload(ctx, bucket, object, st.UploadID, completed, minio.ObjectOptions{})
+	if err != nil {
+		return oi, err
+	}
+
+	if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+		return oi, err
+	}
+
+	if !u.Checksum {
+		return oi, nil
+	}
+
+	return oi, u.verify(ctx, f, size, st, oi.ETag)
+}
+
+// verify checks object completed from parts of st stored with etag against f of size. ETag S3 computes
+// from MD5 of parts is trusted if it matches MD5 recorded while parts were uploaded, otherwise object is
+// read back and compared with SHA256 of f.
+func (u *ResumableUploader) verify(ctx context.Context, f *os.File, size int64, st *uploadState, etag string) error {
+	if expected, ok := multipartETag(st.Parts); ok && checksum.ETag(etag) == expected {
+		return nil
+	}
+
+	r := checksum.NewReader(io.NewSectionReader(f, 0, size))
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		return err
+	}
+
+	return checksum.Verify(ctx, u.ol, st.Bucket, st.Object, etag, "", r.SHA256())
+}
+
+// multipartETag returns ETag of object completed from parts as computed by S3, MD5 of MD5s of parts
+// followed by amount of parts. False is returned if MD5 of any part isn't recorded.
+func multipartETag(parts []statePart) (string, bool) {
+	h := md5.New()
+
+	for _, p := range parts {
+		sum, err := hex.DecodeString(p.MD5)
+		if err != nil || len(sum) != md5.Size {
+			return "", false
+		}
+
+		h.Write(sum)
+	}
+
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(h.Sum(nil)), len(parts)), true
+}
+
+// uploadParts uploads parts of f of size missing in st by concurrent workers, every uploaded part is recorded
+// in state file at statePath. First failure stops the upload once parts being uploaded are done,
+// so every part sent completely is recorded and nothing else is sent.
+func (u *ResumableUploader) uploadParts(ctx context.Context, f *os.File, size int64, st *uploadState, statePath string) error {
+	uploaded := make(map[int]bool, len(st.Parts))
+	for _, p := range st.Parts {
+		uploaded[p.Number] = true
+	}
+
+	parts := int((size + st.PartSize - 1) / st.PartSize)
+	numbers := make(chan int)
+
+	var (
+		mu    sync.Mutex
+		first error
+		wg    sync.WaitGroup
+	)
+
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return first != nil
+	}
+
+	for i := 0; i < u.concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for number := range numbers {
+				// parts handed out before failure was noticed are left for next run
+				if failed() {
+					continue
+				}
+
+				part, err := u.uploadPart(ctx, f, size, st, number)
+
+				mu.Lock()
+				if err == nil {
+					st.Parts = append(st.Parts, part)
+					err = saveState(statePath, st)
+				}
+
+				if err != nil && first == nil {
+					first = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for number := 1; number <= parts; number++ {
+		if uploaded[number] {
+			continue
+		}
+
+		if ctx.Err() != nil || failed() {
+			break
+		}
+
+		numbers <- number
+	}
+
+	close(numbers)
+	wg.Wait()
+
+	if first == nil {
+		first = ctx.Err()
+	}
+
+	return first
+}
+
+// uploadPart uploads part number of f of size and returns it as recorded in state file,
+// with MD5 of its content if checksum is verified.
+func (u *ResumableUploader) uploadPart(ctx context.Context, f *os.File, size int64, st *uploadState, number int) (statePart, error) {
+	offset := int64(number-1) * st.PartSize
+	length := st.PartSize
+	if offset+length > size {
+		length = size - offset
+	}
+
+	r, sum := u.hashed(u.counted(io.NewSectionReader(f, offset, length)))
+
+	data, err := hash.NewReader(r, length, "", "")
+	if err != nil {
+		return statePart{}, err
+	}
+
+	pi, err := u.ol.PutObjectPart(ctx, st.Bucket, st.Object, st.UploadID, number, data, minio.ObjectOptions{})
+	if err != nil {
+		return statePart{}, err
+	}
+
+	part := statePart{Number: number, ETag: pi.ETag}
+	if sum != nil {
+		part.MD5 = sum.MD5()
+	}
+
+	return part, nil
+}
+
+// resume returns upload recorded in state file if it's upload of the same file which backend still holds,
+// otherwise new upload is initiated and recorded.
+func (u *ResumableUploader) resume(ctx context.Context, bucket, object string, fi os.FileInfo, partSize int64, statePath string) (*uploadState, error) {
+	st, err := loadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if st != nil {
+		if st.Bucket == bucket && st.Object == object && st.Size == fi.Size() && st.ModTime.Equal(fi.ModTime()) && st.PartSize == partSize {
+			_, err = u.ol.ListObjectParts(ctx, bucket, object, st.UploadID, 0, 1)
+			if err == nil {
+				if u.Resumed != nil {
+					u.Resumed(len(st.Parts), uploadedBytes(st))
+				}
+
+				return st, nil
+			}
+
+			if _, ok := err.(minio.InvalidUploadID); !ok {
+				return nil, err
+			}
+		} else {
+			// parts of recorded upload would be left behind on backend once state file is replaced
+			u.ol.AbortMultipartUpload(ctx, st.Bucket, st.Object, st.UploadID)
+		}
+	}
+
+	uploadID, err := u.ol.NewMultipartUpload(ctx, bucket, object, make(map[string]string), minio.ObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	st = &uploadState{
+		Bucket:   bucket,
+		Object:   object,
+		UploadID: uploadID,
+		Size:     fi.Size(),
+		ModTime:  fi.ModTime(),
+		PartSize: partSize,
+	}
+
+	return st, saveState(statePath, st)
+}
+
+// uploadedBytes returns amount of bytes of parts recorded in st.
+func uploadedBytes(st *uploadState) int64 {
+	bytes := int64(0)
+
+	for _, p := range st.Parts {
+		if offset := int64(p.Number-1) * st.PartSize; offset+st.PartSize > st.Size {
+			bytes += st.Size - offset
+		} else {
+			bytes += st.PartSize
+		}
+	}
+
+	return bytes
+}
+
+// loadState reads state file at path, nil state is returned if it doesn't exist.
+func loadState(path string) (*uploadState, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	st := &uploadState{}
+	if err := json.Unmarshal(content, st); err != nil {
+		return nil, fmt.Errorf("state file %s is corrupted, remove it to start upload from scratch: %s", path, err)
+	}
+
+	return st, nil
+}
+
+// saveState replaces state file at path, so interrupted write doesn't corrupt it.
+func saveState(path string, st *uploadState) error {
+	content, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// hashed returns r hashed by returned checksum reader if checksum is verified, otherwise r and nil.
+func (u *ResumableUploader) hashed(r io.Reader) (io.Reader, *checksum.Reader) {
+	if !u.Checksum {
+		return r, nil
+	}
+
+	sum := checksum.NewReader(r)
+
+	return sum, sum
+}
+
+// counted reports bytes read from r to Progress.
+func (u *ResumableUploader) counted(r io.Reader) io.Reader {
+	if u.Progress == nil {
+		return r
+	}
+
+	return &progressReader{r: r, progress: u.Progress}
+}
+
+type progressReader struct {
+	r        io.Reader
+	progress func(n int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.progress(int64(n))
+	}
+
+	return n, err
+}
@@ -0,0 +1,287 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package uploader
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"storj.io/ditto/pkg/objlayer/checksum"
+	tutils "storj.io/ditto/pkg/utils/testing_utils"
+)
+
+// multipartBackend keeps parts of multipart uploads in memory, put of part failing is refused once.
+// Completed object gets ETag computed as by S3, first byte of every part is lost if corrupt is set.
+type multipartBackend struct {
+	mu        sync.Mutex
+	uploads   int
+	parts     map[string]map[int][]byte
+	failing   int
+	corrupt   bool
+	aborted   []string
+	completed []byte
+	reads     int
+}
+
+func (b *multipartBackend) layer() minio.ObjectLayer {
+	ol := tutils.NewProxyObjectLayer()
+
+	ol.NewMultipartUploadFunc = func(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (string, error) {
+		b.uploads++
+		id := fmt.Sprintf("upload-%d", b.uploads)
+		b.parts[id] = map[int][]byte{}
+
+		return id, nil
+	}
+	ol.PutObjectPartFunc = func(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if partID == b.failing {
+			b.failing = 0
+			return minio.PartInfo{}, errors.New("connection reset")
+		}
+
+		content, err := ioutil.ReadAll(data)
+		if err != nil {
+			return minio.PartInfo{}, err
+		}
+
+		if b.corrupt {
+			content = content[1:]
+		}
+
+		b.parts[uploadID][partID] = content
+
+		return minio.PartInfo{PartNumber: partID, ETag: fmt.Sprintf("etag-%d", partID)}, nil
+	}
+	ol.ListObjectPartsFunc = func(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListPartsInfo, error) {
+		if _, ok := b.parts[uploadID]; !ok {
+			return minio.ListPartsInfo{}, minio.InvalidUploadID{Bucket: bucket, Object: object, UploadID: uploadID}
+		}
+
+		return minio.ListPartsInfo{UploadID: uploadID}, nil
+	}
+	ol.AbortMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string) error {
+		b.aborted = append(b.aborted, uploadID)
+		delete(b.parts, uploadID)
+
+		return nil
+	}
+	ol.CompleteMultipartUploadFunc = func(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		b.completed = nil
+		sums := []byte{}
+
+		for _, p := range uploadedParts {
+			part := b.parts[uploadID][p.PartNumber]
+			sum := md5.Sum(part)
+
+			b.completed = append(b.completed, part...)
+			sums = append(sums, sum[:]...)
+		}
+
+		etag := fmt.Sprintf("\"%x-%d\"", md5.Sum(sums), len(uploadedParts))
+
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(b.completed)), ETag: etag}, nil
+	}
+	ol.GetObjectInfoFunc = func(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+		return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(b.completed))}, nil
+	}
+	ol.GetObjectFunc = func(ctx context.Context, bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, opts minio.ObjectOptions) error {
+		b.reads++
+		_, err := writer.Write(b.completed)
+		return err
+	}
+
+	return ol
+}
+
+func TestResumableUploader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resumable")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lpath := filepath.Join(dir, "file")
+	statePath := lpath + ".state"
+
+	content := []byte("0123456789")
+	assert.NoError(t, ioutil.WriteFile(lpath, content, 0600))
+
+	cases := []struct {
+		testName string
+		testFunc func(t *testing.T)
+	}{
+		{
+			"Interrupted upload is resumed",
+			func(t *testing.T) {
+				backend := &multipartBackend{parts: map[string]map[int][]byte{}, failing: 2}
+				u := NewResumableUploader(backend.layer(), 4, 1)
+
+				_, err := u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.EqualError(t, err, "connection reset")
+
+				st, err := loadState(statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, []statePart{{Number: 1, ETag: "etag-1"}}, st.Parts)
+
+				var resumedParts int
+				var resumedBytes, sent int64
+
+				u.Resumed = func(parts int, bytes int64) { resumedParts, resumedBytes = parts, bytes }
+				u.Progress = func(n int64) { sent += n }
+
+				oi, err := u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, int64(len(content)), oi.Size)
+				assert.Equal(t, content, backend.completed)
+				assert.Equal(t, 1, backend.uploads)
+				assert.Equal(t, 1, resumedParts)
+				assert.Equal(t, int64(4), resumedBytes)
+				assert.Equal(t, int64(6), sent)
+
+				_, err = os.Stat(statePath)
+				assert.True(t, os.IsNotExist(err))
+			},
+		},
+		{
+			"Upload of other object is aborted",
+			func(t *testing.T) {
+				backend := &multipartBackend{parts: map[string]map[int][]byte{}, failing: 3}
+				u := NewResumableUploader(backend.layer(), 4, 1)
+
+				_, err := u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.Error(t, err)
+
+				_, err = u.Upload(context.Background(), "bucket", "other", lpath, statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"upload-1"}, backend.aborted)
+				assert.Equal(t, 2, backend.uploads)
+				assert.Equal(t, content, backend.completed)
+			},
+		},
+		{
+			"Upload missing on backend is started again",
+			func(t *testing.T) {
+				backend := &multipartBackend{parts: map[string]map[int][]byte{}, failing: 2}
+				u := NewResumableUploader(backend.layer(), 4, 1)
+
+				_, err := u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.Error(t, err)
+
+				delete(backend.parts, "upload-1")
+
+				_, err = u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, 2, backend.uploads)
+				assert.Equal(t, content, backend.completed)
+			},
+		},
+		{
+			"Parts are uploaded concurrently",
+			func(t *testing.T) {
+				backend := &multipartBackend{parts: map[string]map[int][]byte{}}
+				u := NewResumableUploader(backend.layer(), 2, 3)
+
+				sent := int64(0)
+				u.Progress = func(n int64) { atomic.AddInt64(&sent, n) }
+
+				_, err := u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, content, backend.completed)
+				assert.Len(t, backend.parts["upload-1"], 5)
+				assert.Equal(t, int64(len(content)), sent)
+			},
+		},
+		{
+			"Failed part stops upload",
+			func(t *testing.T) {
+				backend := &multipartBackend{parts: map[string]map[int][]byte{}, failing: 1}
+				u := NewResumableUploader(backend.layer(), 2, 2)
+
+				_, err := u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.EqualError(t, err, "connection reset")
+
+				// parts uploaded while the first one failed are recorded
+				st, err := loadState(statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, len(backend.parts["upload-1"]), len(st.Parts))
+
+				_, err = u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, backend.uploads)
+				assert.Equal(t, content, backend.completed)
+			},
+		},
+		{
+			"Checksum of resumed upload is verified by ETag",
+			func(t *testing.T) {
+				backend := &multipartBackend{parts: map[string]map[int][]byte{}, failing: 2}
+				u := NewResumableUploader(backend.layer(), 4, 1)
+				u.Checksum = true
+
+				_, err := u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.Error(t, err)
+
+				_, err = u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, 0, backend.reads)
+			},
+		},
+		{
+			"Corrupted upload fails checksum",
+			func(t *testing.T) {
+				backend := &multipartBackend{parts: map[string]map[int][]byte{}, corrupt: true}
+				u := NewResumableUploader(backend.layer(), 4, 2)
+				u.Checksum = true
+
+				_, err := u.Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.IsType(t, checksum.MismatchError{}, err)
+				assert.Equal(t, 1, backend.reads)
+			},
+		},
+		{
+			"Small file is put at once",
+			func(t *testing.T) {
+				var put []byte
+
+				ol := tutils.NewProxyObjectLayer()
+				ol.PutObjectFunc = func(ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+					var err error
+					put, err = ioutil.ReadAll(data)
+					return minio.ObjectInfo{Bucket: bucket, Name: object, Size: data.Size()}, err
+				}
+
+				_, err := NewResumableUploader(ol, 16, 1).Upload(context.Background(), "bucket", "object", lpath, statePath)
+				assert.NoError(t, err)
+				assert.Equal(t, content, put)
+
+				_, err = os.Stat(statePath)
+				assert.True(t, os.IsNotExist(err))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.testName, c.testFunc)
+	}
+}
+
+func TestPartSize(t *testing.T) {
+	u := NewResumableUploader(nil, DefaultPartSize, 1)
+
+	assert.Equal(t, int64(DefaultPartSize), u.PartSize(200<<30))
+	assert.Equal(t, int64(1<<40)/MaxParts+1, u.PartSize(1<<40))
+}
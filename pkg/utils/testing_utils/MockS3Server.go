@@ -0,0 +1,170 @@
+package testing_utils
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockS3Server is in-memory S3 server serving PUT, HEAD and GET of objects,
+// GET honors Range and If-Match headers. Buckets don't need to be created.
+type MockS3Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	objects map[string]mockS3Object
+}
+
+type mockS3Object struct {
+	data    []byte
+	header  http.Header
+	etag    string
+	modTime time.Time
+}
+
+// Creates new MockS3Server listening on random local port, it's stopped by Close.
+func NewMockS3Server() *MockS3Server {
+	s := &MockS3Server{objects: make(map[string]mockS3Object)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+func (s *MockS3Server) serve(w http.ResponseWriter, r *http.Request) {
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+
+	if _, ok := r.URL.Query()["location"]; ok {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`)
+		return
+	}
+
+	// every bucket exists
+	if len(path) < 2 || path[1] == "" {
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+
+		return
+	}
+
+	key := path[0] + "/" + path[1]
+
+	switch r.Method {
+	case http.MethodPut:
+		s.put(w, r, key)
+	case http.MethodHead, http.MethodGet:
+		s.get(w, r, key)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (s *MockS3Server) put(w http.ResponseWriter, r *http.Request, key string) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err == nil && r.Header.Get("X-Amz-Content-Sha256") == "STREAMING-AWS4-HMAC-SHA256-PAYLOAD" {
+		data, err = decodeChunked(data)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	header := http.Header{}
+	for k, v := range r.Header {
+		if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") || k == "Content-Type" {
+			header[k] = v
+		}
+	}
+
+	sum := md5.Sum(data)
+	etag := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	s.objects[key] = mockS3Object{data: data, header: header, etag: etag, modTime: time.Now().UTC()}
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", `"`+etag+`"`)
+}
+
+func (s *MockS3Server) get(w http.ResponseWriter, r *http.Request, key string) {
+	s.mu.Lock()
+	obj, ok := s.objects[key]
+	s.mu.Unlock()
+
+	if !ok {
+		writeS3Error(w, r, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && strings.Trim(ifMatch, `"`) != obj.etag {
+		writeS3Error(w, r, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+
+	data, status := obj.data, http.StatusOK
+
+	var start, end int64
+	if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err == nil {
+		if end >= int64(len(data)) {
+			end = int64(len(data)) - 1
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data, status = data[start:end+1], http.StatusPartialContent
+	}
+
+	for k, v := range obj.header {
+		w.Header()[k] = v
+	}
+
+	w.Header().Set("ETag", `"`+obj.etag+`"`)
+	w.Header().Set("Last-Modified", obj.modTime.Format(http.TimeFormat))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// decodeChunked strips signatures of chunks from body of streaming signed upload.
+func decodeChunked(body []byte) ([]byte, error) {
+	var data []byte
+
+	for {
+		i := bytes.Index(body, []byte("\r\n"))
+		if i < 0 {
+			return nil, fmt.Errorf("malformed chunk")
+		}
+
+		size, err := strconv.ParseInt(strings.SplitN(string(body[:i]), ";", 2)[0], 16, 64)
+		if err != nil || int64(len(body)) < int64(i)+2+size {
+			return nil, fmt.Errorf("malformed chunk")
+		}
+
+		if size == 0 {
+			return data, nil
+		}
+
+		data = append(data, body[i+2:int64(i)+2+size]...)
+		body = bytes.TrimPrefix(body[int64(i)+2+size:], []byte("\r\n"))
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, r *http.Request, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+
+	if r.Method != http.MethodHead {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+	}
+}
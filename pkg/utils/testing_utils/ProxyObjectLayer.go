@@ -59,6 +59,22 @@ func NewProxyObjectLayer() *proxyObjectLayer {
 		return
 	}
 
+	n.NewMultipartUploadFunc = func (ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (uploadID string, err error) {
+		return
+	}
+	n.PutObjectPartFunc = func (ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (info minio.PartInfo, err error) {
+		return
+	}
+	n.ListObjectPartsFunc = func (ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (result minio.ListPartsInfo, err error) {
+		return
+	}
+	n.AbortMultipartUploadFunc = func (ctx context.Context, bucket, object, uploadID string) (err error) {
+		return
+	}
+	n.CompleteMultipartUploadFunc = func (ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+		return
+	}
+
 	return &n
 }
 
@@ -88,6 +104,14 @@ type proxyObjectLayer struct {
 	PutObjectFunc func (ctx context.Context, bucket, object string, data *hash.Reader, metadata map[string]string, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error)
 	CopyObjectFunc func (ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, dstOpts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error)
 	DeleteObjectFunc func (ctx context.Context, bucket, object string) error
+
+	// Multipart operations.
+
+	NewMultipartUploadFunc func (ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (uploadID string, err error)
+	PutObjectPartFunc func (ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (info minio.PartInfo, err error)
+	ListObjectPartsFunc func (ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (result minio.ListPartsInfo, err error)
+	AbortMultipartUploadFunc func (ctx context.Context, bucket, object, uploadID string) error
+	CompleteMultipartUploadFunc func (ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error)
 }
 
 
@@ -142,3 +166,23 @@ func (n *proxyObjectLayer) CopyObject(ctx context.Context, srcBucket, srcObject,
 func (n *proxyObjectLayer) DeleteObject(ctx context.Context, bucket, object string) error {
 	return n.DeleteObjectFunc(ctx, bucket, object)
 }
+
+func (n *proxyObjectLayer) NewMultipartUpload(ctx context.Context, bucket, object string, metadata map[string]string, opts minio.ObjectOptions) (uploadID string, err error) {
+	return n.NewMultipartUploadFunc(ctx, bucket, object, metadata, opts)
+}
+
+func (n *proxyObjectLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *hash.Reader, opts minio.ObjectOptions) (info minio.PartInfo, err error) {
+	return n.PutObjectPartFunc(ctx, bucket, object, uploadID, partID, data, opts)
+}
+
+func (n *proxyObjectLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (result minio.ListPartsInfo, err error) {
+	return n.ListObjectPartsFunc(ctx, bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+func (n *proxyObjectLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error {
+	return n.AbortMultipartUploadFunc(ctx, bucket, object, uploadID)
+}
+
+func (n *proxyObjectLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	return n.CompleteMultipartUploadFunc(ctx, bucket, object, uploadID, uploadedParts, opts)
+}